@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const aliasValue = "!git-doc"
+
+func newInstallAliasCmd() *cobra.Command {
+	var local bool
+	var remove bool
+
+	cmd := &cobra.Command{
+		Use:   "install-alias",
+		Short: "Register a git doc alias so git-doc runs as a git subcommand",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scope := "--global"
+			if local {
+				scope = "--local"
+			}
+
+			if remove {
+				if err := exec.Command("git", "config", scope, "--unset", "alias.doc").Run(); err != nil {
+					return fmt.Errorf("remove alias.doc: %w", err)
+				}
+				fmt.Println("removed git doc alias")
+				return nil
+			}
+
+			binary, err := exec.LookPath("git-doc")
+			if err != nil {
+				hint := "install git-doc into a directory on PATH"
+				if self, selfErr := os.Executable(); selfErr == nil {
+					hint = "add " + filepath.Dir(self) + " to PATH"
+				}
+				return fmt.Errorf("git-doc is not on PATH, so git doc would fail; %s and rerun install-alias", hint)
+			}
+
+			out, err := exec.Command("git", "config", scope, "--get", "alias.doc").Output()
+			if existing := strings.TrimSpace(string(out)); err == nil && existing != aliasValue {
+				return fmt.Errorf("alias.doc is already set to %q; remove it with git config %s --unset alias.doc first", existing, scope)
+			}
+			if output, err := exec.Command("git", "config", scope, "alias.doc", aliasValue).CombinedOutput(); err != nil {
+				return fmt.Errorf("set alias.doc: %s", strings.TrimSpace(string(output)))
+			}
+
+			fmt.Printf("registered git doc (%s) -> %s; try git doc status\n", strings.TrimPrefix(scope, "--"), binary)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&local, "local", false, "Register the alias for this repository only instead of globally")
+	cmd.Flags().BoolVar(&remove, "remove", false, "Remove the alias")
+	return cmd
+}
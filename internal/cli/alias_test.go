@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallAliasRequiresBinaryOnPath(t *testing.T) {
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+	t.Chdir(repo)
+
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not available")
+	}
+	bin := t.TempDir()
+	t.Setenv("PATH", filepath.Dir(gitPath))
+
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"install-alias", "--local"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "not on PATH") {
+		t.Fatalf("expected a PATH error, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(bin, "git-doc"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+filepath.Dir(gitPath))
+
+	cmd = NewRootCmd()
+	cmd.SetArgs([]string{"install-alias", "--local"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("install-alias failed: %v", err)
+	}
+	out, err := exec.Command("git", "config", "--local", "--get", "alias.doc").Output()
+	if err != nil || strings.TrimSpace(string(out)) != "!git-doc" {
+		t.Fatalf("expected alias.doc to run git-doc, got %q (%v)", out, err)
+	}
+}
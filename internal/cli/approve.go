@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newPendingCmd(flags *rootFlags) *cobra.Command {
+	var asJSON bool
+	var show bool
+	var diff bool
+
+	cmd := &cobra.Command{
+		Use:   "pending",
+		Short: "List doc updates waiting for approve or reject",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			updates, err := app.Updater.PendingApprovals()
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				payload := make([]map[string]any, 0, len(updates))
+				for _, update := range updates {
+					payload = append(payload, map[string]any{
+						"id":         update.ID,
+						"commit":     update.CommitHash,
+						"doc_file":   update.DocFile,
+						"section":    update.Section,
+						"strategy":   update.Strategy,
+						"status":     update.Status,
+						"reason":     update.Reason,
+						"content":    update.Content,
+						"diff":       update.DiffPreview,
+						"created_at": update.CreatedAt,
+					})
+				}
+				return printJSON(payload)
+			}
+
+			if len(updates) == 0 {
+				fmt.Println("pending: no doc updates waiting for approval")
+				return nil
+			}
+			for _, update := range updates {
+				fmt.Printf("%d %s %s#%s commit=%s %s\n", update.ID, update.Status, update.DocFile, update.Section, shortCommit(update.CommitHash), update.Reason)
+				if show {
+					fmt.Printf("\n%s\n\n", strings.TrimSpace(update.Content))
+				}
+				if diff && update.DiffPreview != "" {
+					fmt.Printf("\n%s\n\n", update.DiffPreview)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Output pending updates as JSON")
+	cmd.Flags().BoolVar(&show, "show", false, "Print the generated content of each update")
+	cmd.Flags().BoolVar(&diff, "diff", false, "Print how each update changes its section")
+	return cmd
+}
+
+func newApproveCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "approve <id>",
+		Short: "Write and commit a pending doc update",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid update id %q", args[0])
+			}
+
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			lock, err := app.acquireLock()
+			if err != nil {
+				return err
+			}
+			defer lock.Release()
+
+			docCommit, err := app.Updater.ApproveUpdate(id)
+			if err != nil {
+				return err
+			}
+			if docCommit == "" {
+				fmt.Printf("approved update %d\n", id)
+			} else {
+				fmt.Printf("approved update %d in doc commit %s\n", id, shortCommit(docCommit))
+			}
+			return nil
+		},
+	}
+}
+
+func newRejectCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reject <id>",
+		Short: "Discard a pending doc update",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid update id %q", args[0])
+			}
+
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			if err := app.Updater.RejectUpdate(id); err != nil {
+				return err
+			}
+			fmt.Printf("rejected update %d\n", id)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/orchestrator"
+)
+
+func newAuditCmd(flags *rootFlags) *cobra.Command {
+	var asJSON bool
+	var output string
+	var since string
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Report mapped doc sections whose source code changed since they were last updated",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "text" && output != "json" && output != "github" {
+				return fmt.Errorf("unsupported output format %q (use text, json or github)", output)
+			}
+
+			sinceTime, err := parseSince(since, time.Now())
+			if err != nil {
+				return err
+			}
+
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			report, err := app.Updater.Audit(orchestrator.AuditOptions{Since: sinceTime})
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				output = "json"
+			}
+			switch output {
+			case "json":
+				if err := printJSON(auditPayload(report)); err != nil {
+					return err
+				}
+			case "github":
+				if err := writeGitHubAudit(os.Stdout, report); err != nil {
+					return err
+				}
+			default:
+				printAudit(report)
+			}
+
+			if stale := report.StaleCount(); stale > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d of %d doc sections are stale", stale, len(report.Sections))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Output the staleness report as JSON")
+	cmd.Flags().StringVar(&output, "output", "text", "Output format: text, json or github (workflow command annotations)")
+	cmd.Flags().StringVar(&since, "since", "", "Only consider commits newer than a duration (24h, 7d) or date")
+	return cmd
+}
+
+func printAudit(report orchestrator.AuditReport) {
+	for _, section := range report.Sections {
+		if !section.Stale() {
+			fmt.Printf("ok    %s#%s\n", section.DocFile, section.Section)
+			continue
+		}
+		since := "never updated"
+		if section.LastUpdatedCommit != "" {
+			since = "since " + shortCommit(section.LastUpdatedCommit)
+		}
+		fmt.Printf("stale %s#%s: %d commits %s (%s)\n", section.DocFile, section.Section, len(section.StaleCommits), since, strings.Join(section.ChangedFiles, ", "))
+	}
+	fmt.Printf("audit: %d of %d sections stale\n", report.StaleCount(), len(report.Sections))
+}
+
+func auditPayload(report orchestrator.AuditReport) map[string]any {
+	sections := make([]map[string]any, 0, len(report.Sections))
+	for _, section := range report.Sections {
+		entry := map[string]any{
+			"doc_file":      section.DocFile,
+			"section":       section.Section,
+			"stale":         section.Stale(),
+			"stale_commits": nonNil(section.StaleCommits),
+			"changed_files": nonNil(section.ChangedFiles),
+		}
+		if section.Inventoried {
+			entry["anchor"] = section.Anchor
+			entry["word_count"] = section.Words
+			if section.LastModifiedCommit != "" {
+				entry["last_modified_commit"] = section.LastModifiedCommit
+			}
+		}
+		if section.LastUpdatedCommit != "" {
+			entry["last_updated_commit"] = section.LastUpdatedCommit
+			entry["last_updated_at"] = section.LastUpdatedAt.UTC().Format(time.RFC3339)
+		}
+		sections = append(sections, entry)
+	}
+
+	return map[string]any{
+		"generated_at": time.Now().UTC().Format(time.RFC3339),
+		"head":         report.Head,
+		"stale":        report.StaleCount(),
+		"sections":     sections,
+	}
+}
+
+func shortCommit(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/config"
+	"github.com/kowshik24/git-doc/internal/orchestrator"
+)
+
+func newBackfillCmd(flags *rootFlags) *cobra.Command {
+	var since string
+	var maxCommits int
+	var requestsPerMinute int
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "backfill",
+		Short: "Document historical commits, resuming where a previous backfill stopped",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts orchestrator.BackfillOptions
+			if strings.TrimSpace(since) != "" {
+				sinceTime, err := parseSince(since, time.Now())
+				if err != nil {
+					return err
+				}
+				opts.Since = sinceTime
+			}
+			opts.MaxCommits = maxCommits
+
+			app, err := buildAppWithConfig(flags, func(cfg *config.Config) {
+				if requestsPerMinute > 0 {
+					cfg.LLM.RequestsPerMinute = requestsPerMinute
+					for i := range cfg.LLM.Providers {
+						cfg.LLM.Providers[i].RequestsPerMinute = requestsPerMinute
+					}
+				}
+			})
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			lock, err := app.acquireLock()
+			if err != nil {
+				return err
+			}
+			defer lock.Release()
+
+			commits, err := app.Updater.BackfillCommits(opts)
+			if err != nil {
+				return err
+			}
+			if len(commits) == 0 {
+				fmt.Println("backfill: nothing to do")
+				return nil
+			}
+
+			estimate, err := app.Updater.Estimate(cmd.Context(), commits)
+			if err != nil {
+				return err
+			}
+			printEstimate(estimate)
+
+			if !yes && !flags.dryRun {
+				ok, err := confirm(cmd.InOrStdin(), fmt.Sprintf("Process %d commits?", len(commits)))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					fmt.Println("backfill: aborted")
+					return nil
+				}
+			}
+
+			app.Updater.SetTrigger("backfill")
+			summary, err := app.Updater.UpdateCommitList(cmd.Context(), commits, flags.dryRun)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("processed=%d success=%d failed=%d skipped=%d\n", summary.Processed, summary.Success, summary.Failed, summary.Skipped)
+			return interruptedError(cmd, summary)
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "Only backfill commits newer than a duration (90d) or date (2006-01-02, RFC3339)")
+	cmd.Flags().IntVar(&maxCommits, "max-commits", 0, "Maximum number of commits to process in this run (0 for all)")
+	cmd.Flags().IntVar(&requestsPerMinute, "rpm", 0, "Override llm.requests_per_minute for this run")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt after the cost estimate")
+	return cmd
+}
+
+func confirm(in io.Reader, question string) (bool, error) {
+	if f, ok := in.(*os.File); ok {
+		info, err := f.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return false, fmt.Errorf("refusing to continue without a terminal; pass --yes to confirm")
+		}
+	}
+
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// terminalConfirm asks questions on the command's stdin, or returns nil when
+// it is not a terminal.
+func terminalConfirm(cmd *cobra.Command) func(string) (bool, error) {
+	if f, ok := cmd.InOrStdin().(*os.File); ok {
+		info, err := f.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return nil
+		}
+	}
+	return func(question string) (bool, error) {
+		return confirm(cmd.InOrStdin(), question)
+	}
+}
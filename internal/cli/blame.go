@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/orchestrator"
+)
+
+func newBlameCmd(flags *rootFlags) *cobra.Command {
+	var asJSON bool
+	var generatedOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "blame <doc-file>",
+		Short: "Show git blame for a doc file, labeling lines git-doc generated with the code commit they document",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			docFile := filepath.Clean(args[0])
+			if filepath.IsAbs(docFile) {
+				if rel, err := filepath.Rel(app.RepoRoot, docFile); err == nil {
+					docFile = rel
+				}
+			}
+			docFile = filepath.ToSlash(docFile)
+
+			lines, err := app.Updater.Blame(docFile)
+			if err != nil {
+				return err
+			}
+			if generatedOnly {
+				kept := lines[:0]
+				for _, line := range lines {
+					if line.Generated() {
+						kept = append(kept, line)
+					}
+				}
+				lines = kept
+			}
+
+			if asJSON {
+				return printJSON(blamePayload(docFile, lines))
+			}
+			for _, line := range lines {
+				commit := "0000000"
+				if line.Commit != "" {
+					commit = shortCommit(line.Commit)
+				}
+				fmt.Printf("%s %-20.20s %5d) %s\n", commit, blameLabel(line), line.Number, line.Text)
+			}
+			generated, codeCommits := blameTotals(lines)
+			fmt.Printf("blame: %d of %d lines generated by git-doc from %d code commits\n", generated, len(lines), codeCommits)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Output the annotated lines as JSON")
+	cmd.Flags().BoolVar(&generatedOnly, "generated", false, "Only show lines git-doc generated")
+	return cmd
+}
+
+// blameLabel names the source of a line: "git-doc <code commit>" (with +N
+// for a batched update) for generated lines, else the commit author.
+func blameLabel(line orchestrator.DocBlameLine) string {
+	switch {
+	case line.Generated():
+		label := "git-doc " + shortCommit(line.CodeCommits[len(line.CodeCommits)-1])
+		if more := len(line.CodeCommits) - 1; more > 0 {
+			label += fmt.Sprintf("+%d", more)
+		}
+		return label
+	case line.Commit == "":
+		return "not committed"
+	default:
+		return line.Author
+	}
+}
+
+func blameTotals(lines []orchestrator.DocBlameLine) (int, int) {
+	generated := 0
+	commits := map[string]bool{}
+	for _, line := range lines {
+		if !line.Generated() {
+			continue
+		}
+		generated++
+		for _, commit := range line.CodeCommits {
+			commits[commit] = true
+		}
+	}
+	return generated, len(commits)
+}
+
+func blamePayload(docFile string, lines []orchestrator.DocBlameLine) map[string]any {
+	items := make([]map[string]any, 0, len(lines))
+	for _, line := range lines {
+		item := map[string]any{
+			"line":      line.Number,
+			"text":      line.Text,
+			"commit":    line.Commit,
+			"author":    line.Author,
+			"generated": line.Generated(),
+		}
+		if !line.Time.IsZero() {
+			item["committed_at"] = line.Time.UTC().Format(time.RFC3339)
+		}
+		if line.Generated() {
+			item["code_commits"] = line.CodeCommits
+		}
+		items = append(items, item)
+	}
+	generated, codeCommits := blameTotals(lines)
+	return map[string]any{
+		"generated_at":    time.Now().UTC().Format(time.RFC3339),
+		"doc_file":        docFile,
+		"lines":           items,
+		"generated_lines": generated,
+		"code_commits":    codeCommits,
+	}
+}
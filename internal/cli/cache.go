@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+func newCacheCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and invalidate cached LLM responses",
+	}
+	cmd.AddCommand(newCacheStatsCmd(flags))
+	cmd.AddCommand(newCacheListCmd(flags))
+	cmd.AddCommand(newCacheClearCmd(flags))
+	return cmd
+}
+
+func newCacheStatsCmd(flags *rootFlags) *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show cached responses per provider and model",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			local, err := app.State.LLMCacheStats()
+			if err != nil {
+				return err
+			}
+			var shared []state.CacheStat
+			if app.SharedCache != nil {
+				if shared, err = app.SharedCache.Stats(); err != nil {
+					return err
+				}
+			}
+
+			if asJSON {
+				if local == nil {
+					local = []state.CacheStat{}
+				}
+				payload := map[string]any{"local": local}
+				if app.SharedCache != nil {
+					payload["shared"] = shared
+				}
+				return printJSON(payload)
+			}
+
+			printCacheStats("local", local)
+			if app.SharedCache != nil {
+				printCacheStats("shared", shared)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Output stats as JSON")
+	return cmd
+}
+
+func printCacheStats(label string, stats []state.CacheStat) {
+	var entries, size int64
+	for _, stat := range stats {
+		entries += stat.Entries
+		size += stat.Bytes
+	}
+	fmt.Printf("%s: entries=%d size=%s\n", label, entries, formatBytes(size))
+	for _, stat := range stats {
+		fmt.Printf("  %s/%s entries=%d size=%s oldest=%s newest=%s\n",
+			stat.Provider, stat.Model, stat.Entries, formatBytes(stat.Bytes),
+			stat.Oldest.Local().Format("2006-01-02 15:04"), stat.Newest.Local().Format("2006-01-02 15:04"))
+	}
+}
+
+func newCacheListCmd(flags *rootFlags) *cobra.Command {
+	var filter state.CacheFilter
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List cached responses, optionally for one commit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			entries, err := app.State.ListCachedResponses(filter)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				if entries == nil {
+					entries = []state.CachedResponse{}
+				}
+				return printJSON(entries)
+			}
+
+			for _, entry := range entries {
+				fmt.Printf("%s %s#%s %s/%s prompt=%s size=%s created=%s\n",
+					shortCommit(entry.CommitHash), entry.DocFile, entry.SectionID, entry.Provider, entry.Model,
+					shortCommit(entry.PromptHash), formatBytes(entry.Bytes), entry.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&filter.CommitHash, "commit", "", "Only entries for this commit (hash or prefix)")
+	cmd.Flags().StringVar(&filter.Provider, "provider", "", "Only entries from this provider")
+	cmd.Flags().StringVar(&filter.Model, "model", "", "Only entries from this model")
+	cmd.Flags().IntVar(&filter.Limit, "limit", 50, "Maximum number of entries to list (0 for all)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Output entries as JSON")
+	return cmd
+}
+
+func newCacheClearCmd(flags *rootFlags) *cobra.Command {
+	var filter state.CacheFilter
+	var olderThan string
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Delete cached responses by commit, provider, model or age",
+		Long: "Delete cached responses matching every given filter, so the next run regenerates them.\n" +
+			"Entries with the same prompts are also removed from the shared cache.dir cache.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(olderThan) != "" {
+				cutoff, err := parseSince(olderThan, time.Now())
+				if err != nil {
+					return err
+				}
+				filter.Before = cutoff
+			}
+			if filter == (state.CacheFilter{}) && !all {
+				return fmt.Errorf("pass --commit, --provider, --model or --older-than, or --all to clear the whole cache")
+			}
+
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			var prompts []string
+			if app.SharedCache != nil && filter.CommitHash != "" {
+				entries, err := app.State.ListCachedResponses(filter)
+				if err != nil {
+					return err
+				}
+				for _, entry := range entries {
+					prompts = append(prompts, entry.PromptHash)
+				}
+			}
+
+			removed, err := app.State.ClearLLMCache(filter)
+			if err != nil {
+				return err
+			}
+			if app.SharedCache == nil {
+				fmt.Printf("cleared llm_cache=%d\n", removed)
+				return nil
+			}
+
+			var shared int64
+			if filter.CommitHash != "" {
+				shared, err = app.SharedCache.ClearPrompts(prompts)
+			} else {
+				shared, err = app.SharedCache.Clear(filter)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("cleared llm_cache=%d shared=%d\n", removed, shared)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&filter.CommitHash, "commit", "", "Only entries for this commit (hash or prefix)")
+	cmd.Flags().StringVar(&filter.Provider, "provider", "", "Only entries from this provider")
+	cmd.Flags().StringVar(&filter.Model, "model", "", "Only entries from this model")
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Only entries older than a duration (7d, 12h) or date")
+	cmd.Flags().BoolVar(&all, "all", false, "Clear every cached response")
+	return cmd
+}
@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/orchestrator"
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+type ciCommitResult struct {
+	Hash   string
+	Status string
+	Error  string
+}
+
+type ciReport struct {
+	Summary orchestrator.Summary
+	Commits []ciCommitResult
+	Audit   orchestrator.AuditReport
+}
+
+// runCI audits freshness before processing, since processing marks commits
+// completed, then reports both and fails when either found problems.
+func runCI(cmd *cobra.Command, app *appContainer, commits []string, format, reportFile string, dryRun bool) error {
+	if format != "json" && format != "junit" && format != "github" {
+		return fmt.Errorf("unsupported report format %q (use json, junit or github)", format)
+	}
+
+	audit, err := app.Updater.Audit(orchestrator.AuditOptions{})
+	if err != nil {
+		return err
+	}
+
+	summary, err := app.Updater.UpdateCommitList(cmd.Context(), commits, dryRun)
+	if err != nil {
+		return err
+	}
+
+	report, err := buildCIReport(app.State, commits, summary, audit)
+	if err != nil {
+		return err
+	}
+
+	w, closeReport, err := openReportWriter(reportFile)
+	if err != nil {
+		return err
+	}
+	if err := writeCIReport(w, format, report); err != nil {
+		_ = closeReport()
+		return err
+	}
+	if err := closeReport(); err != nil {
+		return err
+	}
+
+	if err := interruptedError(cmd, summary); err != nil {
+		return err
+	}
+	if report.Failed() {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("ci check failed: %d failed commits, %d stale doc sections", summary.Failed, audit.StaleCount())
+	}
+	return nil
+}
+
+func (r ciReport) Failed() bool {
+	return r.Summary.Failed > 0 || r.Audit.StaleCount() > 0
+}
+
+// buildCIReport looks up the final state of every commit the run processed so
+// failures can be reported individually.
+func buildCIReport(store state.Backend, commits []string, summary orchestrator.Summary, audit orchestrator.AuditReport) (ciReport, error) {
+	report := ciReport{Summary: summary, Audit: audit}
+	for _, hash := range commits {
+		result := ciCommitResult{Hash: hash, Status: "unknown"}
+		row, found, err := store.GetProcessedCommit(hash)
+		if err != nil {
+			return ciReport{}, err
+		}
+		if found {
+			result.Status = row.Status
+			if row.Error.Valid {
+				result.Error = row.Error.String
+			}
+		}
+		report.Commits = append(report.Commits, result)
+	}
+	return report, nil
+}
+
+func writeCIReport(w io.Writer, format string, report ciReport) error {
+	switch format {
+	case "json":
+		payload := map[string]any{
+			"generated_at": time.Now().UTC().Format(time.RFC3339),
+			"passed":       !report.Failed(),
+			"run_id":       report.Summary.RunID,
+			"processed":    report.Summary.Processed,
+			"success":      report.Summary.Success,
+			"failed":       report.Summary.Failed,
+			"skipped":      report.Summary.Skipped,
+			"commits":      ciCommitsPayload(report.Commits),
+			"audit":        auditPayload(report.Audit),
+		}
+		return writeJSON(w, payload)
+	case "junit":
+		return writeJUnit(w, report)
+	case "github":
+		return writeGitHubReport(w, report)
+	default:
+		return fmt.Errorf("unsupported report format %q (use json, junit or github)", format)
+	}
+}
+
+func ciCommitsPayload(commits []ciCommitResult) []map[string]any {
+	out := make([]map[string]any, 0, len(commits))
+	for _, commit := range commits {
+		entry := map[string]any{"commit_hash": commit.Hash, "status": commit.Status}
+		if commit.Error != "" {
+			entry["error"] = commit.Error
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+type junitSkipped struct{}
+
+func writeJUnit(w io.Writer, report ciReport) error {
+	commitSuite := junitTestSuite{Name: "git-doc.commits"}
+	for _, commit := range report.Commits {
+		tc := junitTestCase{ClassName: "git-doc.commits", Name: commit.Hash}
+		switch commit.Status {
+		case "success":
+		case "skipped":
+			tc.Skipped = &junitSkipped{}
+			commitSuite.Skipped++
+		default:
+			message := commit.Error
+			if message == "" {
+				message = "commit ended with status " + commit.Status
+			}
+			tc.Failure = &junitFailure{Message: message, Body: message}
+			commitSuite.Failures++
+		}
+		commitSuite.Cases = append(commitSuite.Cases, tc)
+	}
+	commitSuite.Tests = len(commitSuite.Cases)
+
+	docSuite := junitTestSuite{Name: "git-doc.freshness"}
+	for _, section := range report.Audit.Sections {
+		tc := junitTestCase{ClassName: "git-doc.freshness", Name: section.DocFile + "#" + section.Section}
+		if section.Stale() {
+			message := fmt.Sprintf("%d undocumented commits touch %s", len(section.StaleCommits), strings.Join(section.ChangedFiles, ", "))
+			tc.Failure = &junitFailure{Message: message, Body: strings.Join(section.StaleCommits, "\n")}
+			docSuite.Failures++
+		}
+		docSuite.Cases = append(docSuite.Cases, tc)
+	}
+	docSuite.Tests = len(docSuite.Cases)
+
+	out, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{commitSuite, docSuite}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, out)
+	return err
+}
+
+func openReportWriter(path string) (io.Writer, func() error, error) {
+	if strings.TrimSpace(path) == "" || path == "-" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create report file: %w", err)
+	}
+	return file, file.Close, nil
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/kowshik24/git-doc/internal/orchestrator"
+)
+
+func sampleCIReport() ciReport {
+	return ciReport{
+		Summary: orchestrator.Summary{RunID: "run-1", Processed: 2, Success: 1, Failed: 1},
+		Commits: []ciCommitResult{
+			{Hash: "c1", Status: "success"},
+			{Hash: "c2", Status: "failed", Error: "policy violation"},
+		},
+		Audit: orchestrator.AuditReport{Sections: []orchestrator.SectionAudit{
+			{DocFile: "docs/api.md", Section: "API", StaleCommits: []string{"c3"}, ChangedFiles: []string{"src/api/handler.go"}},
+			{DocFile: "README.md", Section: "CLI"},
+		}},
+	}
+}
+
+func TestWriteCIReportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeCIReport(&buf, "json", sampleCIReport()); err != nil {
+		t.Fatalf("write report: %v", err)
+	}
+
+	var payload struct {
+		Passed  bool `json:"passed"`
+		Failed  int  `json:"failed"`
+		Commits []struct {
+			CommitHash string `json:"commit_hash"`
+			Error      string `json:"error"`
+		} `json:"commits"`
+		Audit struct {
+			Stale int `json:"stale"`
+		} `json:"audit"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+		t.Fatalf("report is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if payload.Passed || payload.Failed != 1 || payload.Audit.Stale != 1 {
+		t.Fatalf("unexpected report payload: %+v", payload)
+	}
+	if len(payload.Commits) != 2 || payload.Commits[1].Error != "policy violation" {
+		t.Fatalf("expected per-commit results, got %+v", payload.Commits)
+	}
+}
+
+func TestWriteCIReportJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeCIReport(&buf, "junit", sampleCIReport()); err != nil {
+		t.Fatalf("write report: %v", err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("report is not valid XML: %v\n%s", err, buf.String())
+	}
+	if len(suites.Suites) != 2 {
+		t.Fatalf("expected commit and freshness suites, got %d", len(suites.Suites))
+	}
+	if suites.Suites[0].Tests != 2 || suites.Suites[0].Failures != 1 {
+		t.Fatalf("unexpected commit suite: %+v", suites.Suites[0])
+	}
+	if suites.Suites[1].Failures != 1 || !strings.Contains(suites.Suites[1].Cases[0].Failure.Message, "src/api/handler.go") {
+		t.Fatalf("unexpected freshness suite: %+v", suites.Suites[1])
+	}
+
+	if err := writeCIReport(&buf, "yaml", sampleCIReport()); err == nil {
+		t.Fatalf("expected unsupported format to fail")
+	}
+}
+
+func TestWriteCIReportGitHub(t *testing.T) {
+	report := sampleCIReport()
+	report.Audit.Sections[0].Line = 12
+	report.Commits[1].Error = "line one\nline two"
+
+	var buf bytes.Buffer
+	if err := writeCIReport(&buf, "github", report); err != nil {
+		t.Fatalf("write report: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected commit error, stale section and notice lines, got %q", buf.String())
+	}
+	if lines[0] != "::error title=git-doc failed for commit c2::line one%0Aline two" {
+		t.Fatalf("unexpected commit annotation: %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "::error file=docs/api.md,line=12,title=Stale doc section%3A API::") {
+		t.Fatalf("unexpected stale section annotation: %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "::notice title=git-doc::processed=2") {
+		t.Fatalf("unexpected summary notice: %q", lines[2])
+	}
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+func newConfigValidateCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Check the configuration for unknown keys, unset env vars, bad globs and missing doc files",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repoRoot, configPath, err := resolveConfigPath(flags)
+			if err != nil {
+				return err
+			}
+
+			issues, err := config.Check(configPath, repoRoot)
+			if err != nil {
+				return err
+			}
+
+			for _, issue := range issues {
+				fmt.Println(issue.String())
+			}
+
+			if config.HasErrors(issues) {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("config %s is invalid", configPath)
+			}
+			fmt.Printf("config %s is valid (schema version %d)\n", configPath, config.SchemaVersion)
+			return nil
+		},
+	}
+}
+
+func newConfigGetCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print a configuration value, e.g. git.commit_doc_updates",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, configPath, err := resolveConfigPath(flags)
+			if err != nil {
+				return err
+			}
+
+			value, err := config.Get(configPath, args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(value)
+			return nil
+		},
+	}
+}
+
+func newConfigSetCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a configuration value in place, preserving comments, e.g. llm.provider openai",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, configPath, err := resolveConfigPath(flags)
+			if err != nil {
+				return err
+			}
+
+			if err := config.Set(configPath, args[0], args[1]); err != nil {
+				return err
+			}
+			fmt.Printf("%s updated in %s\n", args[0], configPath)
+			return nil
+		},
+	}
+}
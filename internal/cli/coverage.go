@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/orchestrator"
+)
+
+func newCoverageCmd(flags *rootFlags) *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "coverage",
+		Short: "Report code directories without a mapping and doc sections git-doc never updated",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			report, err := app.Updater.Coverage()
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return printJSON(coveragePayload(report))
+			}
+			printCoverage(report)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Output the coverage report as JSON")
+	return cmd
+}
+
+func printCoverage(report orchestrator.CoverageReport) {
+	for _, dir := range report.Dirs {
+		switch {
+		case dir.Unmapped():
+			fmt.Printf("unmapped  %s  (%d files)\n", dir.Dir, dir.Files)
+		case dir.Mapped < dir.Files:
+			fmt.Printf("partial   %s  (%d of %d files)\n", dir.Dir, dir.Mapped, dir.Files)
+		}
+	}
+	for _, section := range report.NeverUpdated() {
+		routed := "no mapping"
+		if section.Mapped {
+			routed = "mapped"
+		}
+		fmt.Printf("never     %s#%s  (%s)\n", section.DocFile, section.Path, routed)
+	}
+
+	mapped, total := report.MappedFiles()
+	percent := 0
+	if total > 0 {
+		percent = mapped * 100 / total
+	}
+	updated := len(report.Sections) - len(report.NeverUpdated())
+	fmt.Printf("coverage: mappings cover %d of %d source files (%d%%); %d of %d directories unmapped; %d of %d doc sections updated by git-doc\n",
+		mapped, total, percent, len(report.UnmappedDirs()), len(report.Dirs), updated, len(report.Sections))
+}
+
+func coveragePayload(report orchestrator.CoverageReport) map[string]any {
+	dirs := make([]map[string]any, 0, len(report.Dirs))
+	for _, dir := range report.Dirs {
+		dirs = append(dirs, map[string]any{
+			"dir":          dir.Dir,
+			"files":        dir.Files,
+			"mapped_files": dir.Mapped,
+		})
+	}
+	sections := make([]map[string]any, 0, len(report.Sections))
+	for _, section := range report.Sections {
+		item := map[string]any{
+			"doc_file":     section.DocFile,
+			"section":      section.Section,
+			"section_path": section.Path,
+			"mapped":       section.Mapped,
+		}
+		if !section.NeverUpdated() {
+			item["last_updated_commit"] = section.LastUpdatedCommit
+			item["last_updated_at"] = section.LastUpdatedAt.UTC().Format(time.RFC3339)
+		}
+		sections = append(sections, item)
+	}
+	mapped, total := report.MappedFiles()
+	return map[string]any{
+		"generated_at": time.Now().UTC().Format(time.RFC3339),
+		"files":        total,
+		"mapped_files": mapped,
+		"dirs":         dirs,
+		"sections":     sections,
+	}
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/config"
+	"github.com/kowshik24/git-doc/internal/orchestrator"
+	"github.com/kowshik24/git-doc/internal/runlock"
+	"github.com/kowshik24/git-doc/internal/schedule"
+)
+
+func newDaemonCmd(flags *rootFlags) *cobra.Command {
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Keep documenting new commits: in one batch at each runtime.schedule time, or every --interval",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, configPath, err := resolveConfigPath(flags)
+			if err != nil {
+				return err
+			}
+			cfg, err := loadConfig(flags, configPath)
+			if err != nil {
+				return err
+			}
+			scheduled := strings.TrimSpace(cfg.Runtime.Schedule) != ""
+			var cron schedule.Cron
+			if scheduled {
+				if cron, err = schedule.Parse(cfg.Runtime.Schedule); err != nil {
+					return fmt.Errorf("runtime.schedule: %w", err)
+				}
+			} else if interval <= 0 {
+				return fmt.Errorf("--interval must be positive, got %s", interval)
+			}
+
+			ctx := cmd.Context()
+			for {
+				next := time.Now().Add(interval)
+				if scheduled {
+					if next = cron.Next(time.Now()); next.IsZero() {
+						return fmt.Errorf("runtime.schedule %q never fires", cfg.Runtime.Schedule)
+					}
+					fmt.Printf("git-doc daemon: next update at %s\n", next.Format(time.RFC3339))
+				}
+
+				timer := time.NewTimer(time.Until(next))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return nil
+				case <-timer.C:
+				}
+
+				if err := daemonUpdate(cmd, flags, scheduled); err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					fmt.Fprintf(os.Stderr, "git-doc daemon: %v\n", err)
+				}
+			}
+		},
+	}
+
+	cmd.Flags().DurationVar(&interval, "interval", time.Minute, "How often to check for new commits when runtime.schedule is unset")
+	return cmd
+}
+
+// daemonUpdate documents the commits that arrived since the last run. A
+// scheduled update batches them into one doc update per section. When
+// another run holds the lock, it is asked to pick them up.
+func daemonUpdate(cmd *cobra.Command, flags *rootFlags, scheduled bool) error {
+	app, err := buildAppWithConfig(flags, func(cfg *config.Config) {
+		if scheduled {
+			cfg.Runtime.BatchCommits = true
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	commits, err := app.Updater.NewCommits()
+	if err != nil || len(commits) == 0 {
+		return err
+	}
+	if scheduled {
+		app.Updater.SetTrigger("schedule")
+	} else {
+		app.Updater.SetTrigger("daemon")
+	}
+
+	var summary orchestrator.Summary
+	err = runQueued(app, func() error {
+		more, err := app.Updater.UpdateNewCommits(cmd.Context(), flags.dryRun)
+		summary.Processed += more.Processed
+		summary.Success += more.Success
+		summary.Failed += more.Failed
+		summary.Skipped += more.Skipped
+		if err == nil {
+			err = interruptedError(cmd, more)
+		}
+		return err
+	})
+	if runlock.IsAlreadyRunningError(err) {
+		return runlock.MarkPending(app.RepoRoot)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s processed=%d success=%d failed=%d skipped=%d\n", time.Now().Format(time.RFC3339), summary.Processed, summary.Success, summary.Failed, summary.Skipped)
+	return nil
+}
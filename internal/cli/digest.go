@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newDigestCmd(flags *rootFlags) *cobra.Command {
+	var printOnly bool
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "digest",
+		Short: "Email the digest of doc sections updated since the last digest",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			now := time.Now()
+			if printOnly {
+				since, _, err := app.Updater.DigestWindow(now)
+				if err != nil {
+					return err
+				}
+				digest, err := app.Updater.BuildDigest(since, now)
+				if err != nil {
+					return err
+				}
+				subject, body := digest.Render()
+				fmt.Printf("Subject: %s\n\n%s", subject, body)
+				return nil
+			}
+
+			digest, sent, err := app.Updater.SendDigest(now, force)
+			if err != nil {
+				return err
+			}
+			switch {
+			case !sent:
+				since, _, _ := app.Updater.DigestWindow(now)
+				fmt.Printf("digest: not due yet (last digest covered up to %s); use --force to send now\n", since.Local().Format("2006-01-02 15:04"))
+			case digest.Sections() == 0:
+				fmt.Printf("digest: no doc sections updated since %s; nothing sent\n", digest.Since.Local().Format("2006-01-02 15:04"))
+			default:
+				fmt.Printf("digest: sent %d doc sections updated since %s\n", digest.Sections(), digest.Since.Local().Format("2006-01-02 15:04"))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&printOnly, "print", false, "Print the pending digest instead of sending it")
+	cmd.Flags().BoolVar(&force, "force", false, "Send the digest even if the schedule says it is not due yet")
+	return cmd
+}
@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/config"
+	"github.com/kowshik24/git-doc/internal/gitutil"
+	"github.com/kowshik24/git-doc/internal/hooks"
+	"github.com/kowshik24/git-doc/internal/llm"
+	"github.com/kowshik24/git-doc/internal/runlock"
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+// minGitVersion is the first git with core.hooksPath, which hook
+// installation relies on.
+var minGitVersion = [2]int{2, 9}
+
+const pingTimeout = 30 * time.Second
+
+type doctorResult struct {
+	Name   string
+	Status string // ok, warn or fail
+	Detail string
+	Fix    string
+}
+
+func newDoctorCmd(flags *rootFlags) *cobra.Command {
+	var noPing bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check git, hooks, config, the state database, the LLM provider and the run lock",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results := runDoctor(cmd.Context(), flags, !noPing)
+			failed := printDoctor(os.Stdout, results)
+			if failed > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("doctor: %d checks failed", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&noPing, "no-ping", false, "Skip the LLM provider connectivity call")
+	return cmd
+}
+
+func runDoctor(ctx context.Context, flags *rootFlags, ping bool) []doctorResult {
+	results := []doctorResult{checkGit()}
+	if results[0].Status == "fail" {
+		return results
+	}
+
+	repoRoot, err := gitutil.GetRepoRoot()
+	if err != nil {
+		return append(results, doctorResult{Name: "repository", Status: "fail", Detail: err.Error(), Fix: "run git-doc inside a git repository"})
+	}
+	results = append(results, doctorResult{Name: "repository", Status: "ok", Detail: repoRoot})
+	results = append(results, checkHooks(repoRoot))
+
+	_, configPath, err := resolveConfigPath(flags)
+	if err != nil {
+		return append(results, doctorResult{Name: "config", Status: "fail", Detail: err.Error()})
+	}
+	configResult := checkConfig(configPath, repoRoot)
+	results = append(results, configResult)
+	if configResult.Status == "fail" {
+		return append(results, checkLock(repoRoot, config.Default().Runtime.LockTTLMinutes))
+	}
+
+	cfg, err := loadConfig(flags, configPath)
+	if err != nil {
+		return append(results, doctorResult{Name: "config", Status: "fail", Detail: err.Error(), Fix: "git-doc config validate"})
+	}
+	results = append(results, checkState(cfg, repoRoot))
+	if ping {
+		results = append(results, checkProvider(ctx, cfg))
+	}
+	return append(results, checkLock(repoRoot, cfg.Runtime.LockTTLMinutes))
+}
+
+func checkGit() doctorResult {
+	path, err := exec.LookPath("git")
+	if err != nil {
+		return doctorResult{Name: "git", Status: "fail", Detail: "git not found on PATH", Fix: "install git 2.9 or newer"}
+	}
+	out, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		return doctorResult{Name: "git", Status: "fail", Detail: err.Error(), Fix: "check that " + path + " runs"}
+	}
+
+	version := strings.TrimSpace(string(out))
+	major, minor, ok := parseGitVersion(version)
+	if !ok {
+		return doctorResult{Name: "git", Status: "warn", Detail: "could not parse " + strconv.Quote(version)}
+	}
+	if major < minGitVersion[0] || (major == minGitVersion[0] && minor < minGitVersion[1]) {
+		return doctorResult{Name: "git", Status: "fail", Detail: version, Fix: fmt.Sprintf("upgrade git to %d.%d or newer", minGitVersion[0], minGitVersion[1])}
+	}
+	return doctorResult{Name: "git", Status: "ok", Detail: version}
+}
+
+// parseGitVersion reads the major and minor version from git --version
+// output such as "git version 2.43.0" or "git version 2.41.0.windows.1".
+func parseGitVersion(out string) (int, int, bool) {
+	fields := strings.Fields(out)
+	if len(fields) < 3 {
+		return 0, 0, false
+	}
+	parts := strings.Split(fields[2], ".")
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+func checkHooks(repoRoot string) doctorResult {
+	states, err := hooks.NewManager(repoRoot).States()
+	if err != nil {
+		return doctorResult{Name: "hooks", Status: "fail", Detail: err.Error()}
+	}
+
+	var installed, missing, notExecutable []string
+	for _, hs := range states {
+		switch {
+		case hs.Installed && !hs.Executable:
+			notExecutable = append(notExecutable, hs.Path)
+		case hs.Installed:
+			installed = append(installed, hs.Name)
+		case hs.Name != "pre-push":
+			missing = append(missing, hs.Name)
+		}
+	}
+
+	switch {
+	case len(notExecutable) > 0:
+		return doctorResult{Name: "hooks", Status: "fail", Detail: "not executable: " + strings.Join(notExecutable, ", "), Fix: "chmod +x " + strings.Join(notExecutable, " ")}
+	case len(installed) == 0:
+		return doctorResult{Name: "hooks", Status: "warn", Detail: "no git-doc hooks installed", Fix: "git-doc enable-hook"}
+	case len(missing) > 0:
+		return doctorResult{Name: "hooks", Status: "warn", Detail: "installed: " + strings.Join(installed, ", ") + "; missing: " + strings.Join(missing, ", "), Fix: "git-doc enable-hook"}
+	}
+	return doctorResult{Name: "hooks", Status: "ok", Detail: "installed: " + strings.Join(installed, ", ")}
+}
+
+func checkConfig(configPath, repoRoot string) doctorResult {
+	if _, err := os.Stat(configPath); err != nil {
+		return doctorResult{Name: "config", Status: "fail", Detail: err.Error(), Fix: "git-doc init"}
+	}
+	issues, err := config.Check(configPath, repoRoot)
+	if err != nil {
+		return doctorResult{Name: "config", Status: "fail", Detail: err.Error(), Fix: "git-doc config validate"}
+	}
+	if len(issues) == 0 {
+		return doctorResult{Name: "config", Status: "ok", Detail: configPath}
+	}
+
+	lines := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		lines = append(lines, issue.String())
+	}
+	status := "warn"
+	if config.HasErrors(issues) {
+		status = "fail"
+	}
+	return doctorResult{Name: "config", Status: status, Detail: strings.Join(lines, "; "), Fix: "git-doc config validate, then git-doc config --edit"}
+}
+
+func checkState(cfg *config.Config, repoRoot string) doctorResult {
+	statePath := cfg.State.DBPath
+	if !state.IsURL(statePath) && !filepath.IsAbs(statePath) {
+		statePath = filepath.Join(repoRoot, statePath)
+	}
+
+	store, err := state.Open(statePath, cfg.State.AuthToken)
+	if err != nil {
+		return doctorResult{Name: "state", Status: "fail", Detail: err.Error(), Fix: "check state.db_path, or move the database aside to start a new ledger"}
+	}
+	defer store.Close()
+
+	problems, err := store.IntegrityCheck()
+	if err != nil {
+		return doctorResult{Name: "state", Status: "fail", Detail: err.Error()}
+	}
+	if len(problems) > 0 {
+		return doctorResult{Name: "state", Status: "fail", Detail: strings.Join(problems, "; "), Fix: "move " + statePath + " aside, then git-doc state import a snapshot or run git-doc backfill"}
+	}
+	return doctorResult{Name: "state", Status: "ok", Detail: statePath + " passed integrity_check"}
+}
+
+func checkProvider(ctx context.Context, cfg *config.Config) doctorResult {
+	client, err := llm.NewClient(cfg)
+	if err != nil {
+		return doctorResult{Name: "llm", Status: "fail", Detail: err.Error(), Fix: "set llm.provider and llm.api_key (git-doc config set llm.provider ...)"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	missing, err := llm.MissingOllamaModels(ctx, cfg)
+	if err == nil && len(missing) > 0 {
+		return doctorResult{Name: "llm", Status: "fail", Detail: fmt.Sprintf("%d ollama model(s) not installed", len(missing)), Fix: ollamaPullCommands(missing)}
+	}
+	started := time.Now()
+	if _, err := client.Generate(ctx, "Reply with the single word OK."); err != nil {
+		fix := "check network access and llm.base_url"
+		if llm.IsPermanent(err) {
+			fix = "check llm.api_key and llm.model"
+		}
+		return doctorResult{Name: "llm", Status: "fail", Detail: err.Error(), Fix: fix}
+	}
+	return doctorResult{Name: "llm", Status: "ok", Detail: fmt.Sprintf("%s answered in %s", client.Name(), time.Since(started).Round(time.Millisecond))}
+}
+
+func checkLock(repoRoot string, ttlMinutes int) doctorResult {
+	holder, err := runlock.ReadHolder(repoRoot)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && holder.PID == 0) {
+		return doctorResult{Name: "lock", Status: "ok", Detail: "not held"}
+	}
+	if err != nil {
+		return doctorResult{Name: "lock", Status: "warn", Detail: err.Error(), Fix: "git-doc unlock"}
+	}
+	if !holder.Alive {
+		return doctorResult{Name: "lock", Status: "ok", Detail: fmt.Sprintf("not held (left by pid %d, which is not running)", holder.PID)}
+	}
+	held := time.Since(holder.CreatedAt)
+	if ttlMinutes > 0 && !holder.CreatedAt.IsZero() && held > time.Duration(ttlMinutes)*time.Minute {
+		return doctorResult{Name: "lock", Status: "warn", Detail: fmt.Sprintf("held by pid %d for %s", holder.PID, held.Round(time.Minute)), Fix: "if that run is hung, git-doc unlock --force"}
+	}
+	return doctorResult{Name: "lock", Status: "ok", Detail: describeHolder(repoRoot)}
+}
+
+func printDoctor(w io.Writer, results []doctorResult) int {
+	failed := 0
+	for _, r := range results {
+		if r.Status == "fail" {
+			failed++
+		}
+		fmt.Fprintf(w, "%-4s %-10s %s\n", r.Status, r.Name, r.Detail)
+		if r.Fix != "" && r.Status != "ok" {
+			fmt.Fprintf(w, "     %-10s fix: %s\n", "", r.Fix)
+		}
+	}
+	return failed
+}
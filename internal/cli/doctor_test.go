@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kowshik24/git-doc/internal/config"
+	"github.com/kowshik24/git-doc/internal/hooks"
+)
+
+func TestParseGitVersion(t *testing.T) {
+	tests := []struct {
+		out          string
+		major, minor int
+		ok           bool
+	}{
+		{out: "git version 2.43.0", major: 2, minor: 43, ok: true},
+		{out: "git version 2.41.0.windows.1", major: 2, minor: 41, ok: true},
+		{out: "git version 2.39.3 (Apple Git-145)", major: 2, minor: 39, ok: true},
+		{out: "git version", ok: false},
+	}
+	for _, tc := range tests {
+		major, minor, ok := parseGitVersion(tc.out)
+		if major != tc.major || minor != tc.minor || ok != tc.ok {
+			t.Fatalf("parseGitVersion(%q) = %d, %d, %v", tc.out, major, minor, ok)
+		}
+	}
+}
+
+func TestDoctorChecksHooksAndState(t *testing.T) {
+	repoRoot := t.TempDir()
+	if out, err := exec.Command("git", "init", repoRoot).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+
+	if result := checkHooks(repoRoot); result.Status != "warn" || result.Fix != "git-doc enable-hook" {
+		t.Fatalf("expected a warning with an enable-hook fix, got %+v", result)
+	}
+	if err := hooks.NewManager(repoRoot).Enable(); err != nil {
+		t.Fatal(err)
+	}
+	if result := checkHooks(repoRoot); result.Status != "ok" {
+		t.Fatalf("expected installed hooks to pass, got %+v", result)
+	}
+	if err := os.Chmod(filepath.Join(repoRoot, ".git", "hooks", "post-commit"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if result := checkHooks(repoRoot); result.Status != "fail" || !strings.HasPrefix(result.Fix, "chmod +x ") {
+		t.Fatalf("expected a non-executable hook to fail with a chmod fix, got %+v", result)
+	}
+
+	cfg := config.Default()
+	cfg.State.DBPath = filepath.Join(repoRoot, ".git-doc", "state.db")
+	if err := os.MkdirAll(filepath.Dir(cfg.State.DBPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if result := checkState(cfg, repoRoot); result.Status != "ok" {
+		t.Fatalf("expected a fresh database to pass integrity_check, got %+v", result)
+	}
+	if result := checkLock(repoRoot, 60); result.Status != "ok" || result.Detail != "not held" {
+		t.Fatalf("expected the lock to be free, got %+v", result)
+	}
+}
@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/kowshik24/git-doc/internal/orchestrator"
+)
+
+func printEstimate(estimate orchestrator.Estimate) {
+	fmt.Printf("commits=%d prompts=%d reviews=%d skipped=%d unresolved=%d\n", estimate.Commits, estimate.Prompts, estimate.Reviews, estimate.Skipped, estimate.Failed)
+	for i, provider := range estimate.Providers {
+		role := "fallback"
+		switch {
+		case provider.Mapping:
+			role = "mapping"
+		case i == 0:
+			role = "primary"
+		}
+		cost := "unknown"
+		if provider.PriceKnown {
+			cost = fmt.Sprintf("$%.4f", provider.Cost)
+		}
+		fmt.Printf("%s provider=%s model=%s input_tokens~%d output_tokens~%d cost~%s\n",
+			role, provider.Provider, provider.Model, provider.InputTokens, provider.OutputTokens, cost)
+	}
+}
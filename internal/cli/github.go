@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/orchestrator"
+)
+
+var (
+	githubDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// githubCommand formats a GitHub Actions workflow command such as
+// "::error file=docs/api.md,line=3,title=...::message".
+func githubCommand(level string, properties map[string]string, message string) string {
+	keys := make([]string, 0, len(properties))
+	for key, value := range properties {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key+"="+githubPropertyEscaper.Replace(properties[key]))
+	}
+
+	cmd := "::" + level
+	if len(parts) > 0 {
+		cmd += " " + strings.Join(parts, ",")
+	}
+	return cmd + "::" + githubDataEscaper.Replace(message)
+}
+
+func writeGitHubAudit(w io.Writer, report orchestrator.AuditReport) error {
+	for _, section := range report.Sections {
+		if !section.Stale() {
+			continue
+		}
+		properties := map[string]string{
+			"file":  section.DocFile,
+			"title": "Stale doc section: " + section.Section,
+		}
+		if section.Line > 0 {
+			properties["line"] = fmt.Sprint(section.Line)
+		}
+		message := fmt.Sprintf("Section %q has %d undocumented commits touching %s", section.Section, len(section.StaleCommits), strings.Join(section.ChangedFiles, ", "))
+		if _, err := fmt.Fprintln(w, githubCommand("error", properties, message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeGitHubReport(w io.Writer, report ciReport) error {
+	for _, commit := range report.Commits {
+		if commit.Status == "success" || commit.Status == "skipped" {
+			continue
+		}
+		message := commit.Error
+		if message == "" {
+			message = "commit ended with status " + commit.Status
+		}
+		properties := map[string]string{"title": "git-doc failed for commit " + shortCommit(commit.Hash)}
+		if _, err := fmt.Fprintln(w, githubCommand("error", properties, message)); err != nil {
+			return err
+		}
+	}
+
+	if err := writeGitHubAudit(w, report.Audit); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintln(w, githubCommand("notice", map[string]string{"title": "git-doc"}, fmt.Sprintf(
+		"processed=%d success=%d failed=%d skipped=%d stale_sections=%d",
+		report.Summary.Processed, report.Summary.Success, report.Summary.Failed, report.Summary.Skipped, report.Audit.StaleCount())))
+	return err
+}
@@ -0,0 +1,249 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+type wizardProvider struct {
+	name   string
+	model  string
+	keyEnv string
+}
+
+var wizardProviders = []wizardProvider{
+	{"mock", "", ""},
+	{"openai", "gpt-4o-mini", "OPENAI_API_KEY"},
+	{"anthropic", "claude-3-5-haiku-latest", "ANTHROPIC_API_KEY"},
+	{"gemini", "gemini-1.5-flash", "GEMINI_API_KEY"},
+	{"groq", "llama-3.1-8b-instant", "GROQ_API_KEY"},
+	{"mistral", "mistral-small-latest", "MISTRAL_API_KEY"},
+	{"openrouter", "openrouter/auto", "OPENROUTER_API_KEY"},
+	{"ollama", "llama3.1", ""},
+}
+
+const maxSuggestedMappings = 8
+
+var envVarName = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// initWizard asks for the doc files, mappings, provider and API key and
+// returns content with the answers applied, plus whether to install hooks.
+// files are the repository's tracked files.
+func initWizard(in io.Reader, out io.Writer, files []string, content string) (string, bool, error) {
+	w := &wizard{in: bufio.NewReader(in), out: out}
+
+	docFiles := detectDocFiles(files)
+	answer, err := w.ask("Doc files to keep updated (comma-separated, globs allowed)", strings.Join(docFiles, ", "))
+	if err != nil {
+		return "", false, err
+	}
+	if content, err = config.SetValue(content, "doc_files", answer); err != nil {
+		return "", false, err
+	}
+	docFiles = splitAnswer(answer)
+
+	if i := slices.IndexFunc(docFiles, func(f string) bool { return !strings.ContainsAny(f, "*?[{") }); i >= 0 {
+		if mappings := suggestMappings(files, docFiles[i]); len(mappings) > 0 {
+			fmt.Fprintln(out, "Suggested mappings:")
+			for _, mapping := range mappings {
+				fmt.Fprintf(out, "  %s -> %s#%s\n", mapping.CodePattern, mapping.DocFile, mapping.Section)
+			}
+			if ok, err := w.yes("Add these mappings?", true); err != nil {
+				return "", false, err
+			} else if ok {
+				if content, err = config.AddMappings(content, mappings); err != nil {
+					return "", false, err
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(wizardProviders))
+	for _, provider := range wizardProviders {
+		names = append(names, provider.name)
+	}
+	var provider wizardProvider
+	for {
+		answer, err := w.ask("LLM provider ("+strings.Join(names, ", ")+")", "mock")
+		if err != nil {
+			return "", false, err
+		}
+		i := slices.IndexFunc(wizardProviders, func(p wizardProvider) bool { return p.name == strings.ToLower(answer) })
+		if i >= 0 {
+			provider = wizardProviders[i]
+			break
+		}
+		fmt.Fprintf(out, "Unknown provider %q.\n", answer)
+	}
+	if content, err = config.SetValue(content, "llm.provider", provider.name); err != nil {
+		return "", false, err
+	}
+	if provider.model != "" {
+		model, err := w.ask("Model", provider.model)
+		if err != nil {
+			return "", false, err
+		}
+		if content, err = config.SetValue(content, "llm.model", model); err != nil {
+			return "", false, err
+		}
+	}
+	if provider.keyEnv != "" {
+		key, err := w.ask("API key, or the environment variable holding it", provider.keyEnv)
+		if err != nil {
+			return "", false, err
+		}
+		if envVarName.MatchString(key) {
+			key = "${" + key + "}"
+		} else {
+			fmt.Fprintln(out, "The key is stored in plain text in the config file; keep it out of version control.")
+		}
+		if content, err = config.SetValue(content, "llm.api_key", key); err != nil {
+			return "", false, err
+		}
+	}
+
+	hooks, err := w.yes("Install git hooks so docs update after each commit?", true)
+	if err != nil {
+		return "", false, err
+	}
+	return content, hooks, nil
+}
+
+type wizard struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prints question with its default and returns the answer, or the
+// default for an empty line or end of input.
+func (w *wizard) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(w.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", question)
+	}
+	line, err := w.in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+func (w *wizard) yes(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	answer, err := w.ask(question+" ("+hint+")", "")
+	if err != nil || answer == "" {
+		return def, err
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes", nil
+}
+
+func splitAnswer(answer string) []string {
+	var out []string
+	for _, item := range strings.Split(answer, ",") {
+		if item = strings.Trim(strings.TrimSpace(item), `"`); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// detectDocFiles suggests the top-level Markdown docs, leaving out the ones
+// git-doc should not rewrite, and a glob for each docs directory.
+func detectDocFiles(files []string) []string {
+	skip := []string{"changelog.md", "license.md", "code_of_conduct.md", "security.md"}
+	var docs, dirs []string
+	for _, file := range files {
+		if path.Ext(strings.ToLower(file)) != ".md" {
+			continue
+		}
+		dir, _, nested := strings.Cut(file, "/")
+		switch {
+		case !nested && !slices.Contains(skip, strings.ToLower(file)):
+			docs = append(docs, file)
+		case nested && isDocsDir(dir) && !slices.Contains(dirs, dir):
+			dirs = append(dirs, dir)
+		}
+	}
+	slices.Sort(docs)
+	if i := slices.Index(docs, "README.md"); i > 0 {
+		docs = append([]string{"README.md"}, slices.Delete(docs, i, i+1)...)
+	}
+	for _, dir := range dirs {
+		docs = append(docs, dir+"/**/*.md")
+	}
+	if len(docs) == 0 {
+		docs = []string{"README.md"}
+	}
+	return docs
+}
+
+func isDocsDir(dir string) bool {
+	switch strings.ToLower(dir) {
+	case "doc", "docs", "documentation", "wiki":
+		return true
+	}
+	return false
+}
+
+// suggestMappings maps each top-level code directory to a section of
+// docFile. A repository with a single code directory, such as src or
+// internal, is mapped one level down instead.
+func suggestMappings(files []string, docFile string) []config.Mapping {
+	codeDirs := func(prefix string) []string {
+		var dirs []string
+		for _, file := range files {
+			rest, ok := strings.CutPrefix(file, prefix)
+			if !ok {
+				continue
+			}
+			dir, _, nested := strings.Cut(rest, "/")
+			if !nested || strings.HasPrefix(dir, ".") || (prefix == "" && isDocsDir(dir)) || slices.Contains([]string{"vendor", "node_modules", "testdata"}, dir) || path.Ext(file) == ".md" {
+				continue
+			}
+			if !slices.Contains(dirs, prefix+dir) {
+				dirs = append(dirs, prefix+dir)
+			}
+		}
+		slices.Sort(dirs)
+		return dirs
+	}
+
+	dirs := codeDirs("")
+	if len(dirs) == 1 {
+		if nested := codeDirs(dirs[0] + "/"); len(nested) > 1 {
+			dirs = nested
+		}
+	}
+	if len(dirs) > maxSuggestedMappings {
+		dirs = dirs[:maxSuggestedMappings]
+	}
+
+	mappings := make([]config.Mapping, 0, len(dirs))
+	for _, dir := range dirs {
+		mappings = append(mappings, config.Mapping{CodePattern: dir + "/**", DocFile: docFile, Section: sectionTitle(path.Base(dir))})
+	}
+	return mappings
+}
+
+func sectionTitle(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' || r == '.' })
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+func TestInitWizard(t *testing.T) {
+	files := []string{
+		"README.md", "CHANGELOG.md", "go.mod",
+		"docs/guide.md", "docs/api/index.md",
+		"internal/cli/root.go", "internal/state/store.go", "internal/doc/markdown.go",
+		".github/workflows/ci.yml",
+	}
+	if got := detectDocFiles(files); strings.Join(got, ",") != "README.md,docs/**/*.md" {
+		t.Fatalf("unexpected doc files: %v", got)
+	}
+
+	// Accept the doc files and mappings, pick openai with the default model,
+	// name an env var for the key and decline hooks.
+	in := strings.NewReader("\n\nopenai\n\nMY_OPENAI_KEY\nn\n")
+	var out bytes.Buffer
+	content, installHooks, err := initWizard(in, &out, files, config.DefaultToml())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if installHooks {
+		t.Fatalf("expected hooks to be declined")
+	}
+
+	cfg := config.Default()
+	if _, err := toml.Decode(content, cfg); err != nil {
+		t.Fatalf("wizard wrote invalid TOML: %v\n%s", err, content)
+	}
+	if strings.Join(cfg.DocFiles, ",") != "README.md,docs/**/*.md" || cfg.LLM.Provider != "openai" || cfg.LLM.Model != "gpt-4o-mini" || cfg.LLM.APIKey != "${MY_OPENAI_KEY}" {
+		t.Fatalf("unexpected answers applied: doc_files=%v llm=%s/%s key=%s", cfg.DocFiles, cfg.LLM.Provider, cfg.LLM.Model, cfg.LLM.APIKey)
+	}
+	var patterns []string
+	for _, mapping := range cfg.Mappings {
+		if mapping.DocFile != "README.md" {
+			t.Fatalf("expected mappings to target README.md, got %+v", mapping)
+		}
+		patterns = append(patterns, mapping.CodePattern+"#"+mapping.Section)
+	}
+	if strings.Join(patterns, ",") != "internal/cli/**#Cli,internal/doc/**#Doc,internal/state/**#State" {
+		t.Fatalf("unexpected suggested mappings: %v", patterns)
+	}
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/orchestrator"
+)
+
+// watchInterrupts cancels the command context on Ctrl-C or SIGTERM so the
+// orchestrator can finish or roll back the commit in flight. A second signal
+// falls through to the default handler and exits immediately.
+func watchInterrupts(cmd *cobra.Command) {
+	parent := cmd.Context()
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	context.AfterFunc(ctx, stop)
+	cmd.SetContext(ctx)
+}
+
+// interruptedError reports an interrupted or timed-out run with a hint for resuming it;
+// the run lock is released by the caller's deferred Release.
+func interruptedError(cmd *cobra.Command, summary orchestrator.Summary) error {
+	if !summary.Interrupted {
+		return nil
+	}
+	cmd.SilenceUsage = true
+	reason := "interrupted"
+	if summary.DeadlineExceeded {
+		reason = "run deadline exceeded"
+	}
+	return fmt.Errorf("%s: %d commits left pending; run git-doc update to resume", reason, summary.Pending)
+}
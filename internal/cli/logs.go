@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+func newLogsCmd(flags *rootFlags) *cobra.Command {
+	var filter state.RunEventFilter
+	var since string
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show run events recorded in the state database",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(since) != "" {
+				sinceTime, err := parseSince(since, time.Now())
+				if err != nil {
+					return err
+				}
+				filter.Since = sinceTime
+			}
+
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			events, err := app.State.QueryRunEvents(filter)
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				return printJSON(eventsPayload(events))
+			}
+
+			for _, event := range events {
+				fmt.Printf("%s ", event.RunID)
+				printEvent(event)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&filter.RunID, "run", "", "Only show events for this run id")
+	cmd.Flags().StringVar(&filter.CommitHash, "commit", "", "Only show events for this commit (prefix match)")
+	cmd.Flags().StringVar(&filter.Level, "level", "", "Only show events with this level (info, warn, error)")
+	cmd.Flags().StringVar(&filter.Component, "component", "", "Only show events from this component")
+	cmd.Flags().StringVar(&since, "since", "", "Only show events newer than a duration (30m, 24h, 7d) or date (2006-01-02, RFC3339)")
+	cmd.Flags().IntVar(&filter.Limit, "limit", 200, "Maximum number of most recent events (0 for all)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Output events as JSON")
+	return cmd
+}
+
+func parseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if ts, err := time.Parse(time.RFC3339, value); err == nil {
+		return ts, nil
+	}
+	if ts, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return ts, nil
+	}
+
+	d, err := parseDurationWithDays(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use a duration like 24h or 7d, or a date like 2006-01-02", value)
+	}
+	return now.Add(-d), nil
+}
+
+func parseDurationWithDays(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil || days < 0 {
+			return 0, fmt.Errorf("invalid day duration %q", value)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative duration %q", value)
+	}
+	return d, nil
+}
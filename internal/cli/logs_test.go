@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{value: "24h", want: now.Add(-24 * time.Hour)},
+		{value: "7d", want: now.Add(-7 * 24 * time.Hour)},
+		{value: "2024-01-02T03:04:05Z", want: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+
+	for _, tc := range tests {
+		got, err := parseSince(tc.value, now)
+		if err != nil {
+			t.Fatalf("parseSince(%q) returned error: %v", tc.value, err)
+		}
+		if !got.Equal(tc.want) {
+			t.Fatalf("parseSince(%q) = %v, want %v", tc.value, got, tc.want)
+		}
+	}
+
+	if got, err := parseSince("2024-01-02", now); err != nil || got.Year() != 2024 || got.Day() != 2 {
+		t.Fatalf("expected date-only value to parse, got %v err=%v", got, err)
+	}
+
+	if _, err := parseSince("yesterday", now); err == nil {
+		t.Fatalf("expected invalid since value to fail")
+	}
+}
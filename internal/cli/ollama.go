@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/config"
+	"github.com/kowshik24/git-doc/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+// ensureOllamaModels checks that the ollama servers in the provider chain
+// have their models and pulls the missing ones according to
+// llm.ollama.pull. An unreachable server only warns, since failover may
+// still reach another provider.
+func ensureOllamaModels(cmd *cobra.Command, cfg *config.Config) error {
+	missing, err := llm.MissingOllamaModels(cmd.Context(), cfg)
+	if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: could not list ollama models: %v\n", err)
+		return nil
+	}
+
+	for _, m := range missing {
+		pull := cfg.LLM.Ollama.Pull == "always"
+		if cfg.LLM.Ollama.Pull == "prompt" {
+			ok, err := confirm(cmd.InOrStdin(), fmt.Sprintf("Ollama model %s is not installed. Pull it now?", m.Model))
+			pull = err == nil && ok
+		}
+		if !pull {
+			return fmt.Errorf("ollama model %s is not installed; run: ollama pull %s", m.Model, m.Model)
+		}
+
+		fmt.Fprintf(cmd.ErrOrStderr(), "pulling ollama model %s...\n", m.Model)
+		if err := llm.PullOllamaModel(cmd.Context(), m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ollamaPullCommands(missing []llm.OllamaModel) string {
+	commands := make([]string, 0, len(missing))
+	for _, m := range missing {
+		commands = append(commands, "ollama pull "+m.Model)
+	}
+	return strings.Join(commands, " && ")
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+func newExportPromptsCmd(flags *rootFlags) *cobra.Command {
+	var since string
+	var format string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "export-prompts",
+		Short: "Export the prompts sent to LLM providers for compliance review",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "jsonl" && format != "json" {
+				return fmt.Errorf("unsupported export format %q (use jsonl or json)", format)
+			}
+			cutoff, err := parseSince(since, time.Now())
+			if err != nil {
+				return err
+			}
+
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			entries, err := app.State.ListPromptLog(cutoff)
+			if err != nil {
+				return err
+			}
+
+			w, closeWriter, err := openReportWriter(output)
+			if err != nil {
+				return err
+			}
+			defer closeWriter()
+			return writePromptLog(w, entries, format)
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "Only prompts sent after a duration ago (7d, 12h) or a date")
+	cmd.Flags().StringVar(&format, "format", "jsonl", "Export format: jsonl (one prompt per line) or json")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the export to a file (default stdout)")
+	return cmd
+}
+
+type promptRecord struct {
+	Timestamp  string `json:"timestamp"`
+	RunID      string `json:"run_id"`
+	Commit     string `json:"commit"`
+	Purpose    string `json:"purpose"`
+	DocFile    string `json:"doc_file,omitempty"`
+	Section    string `json:"section,omitempty"`
+	Provider   string `json:"provider"`
+	Model      string `json:"model"`
+	PromptHash string `json:"prompt_hash"`
+	Prompt     string `json:"prompt,omitempty"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+func writePromptLog(w io.Writer, entries []state.PromptLogEntry, format string) error {
+	records := make([]promptRecord, 0, len(entries))
+	for _, e := range entries {
+		records = append(records, promptRecord{
+			Timestamp:  e.CreatedAt.UTC().Format(time.RFC3339),
+			RunID:      e.RunID,
+			Commit:     e.CommitHash,
+			Purpose:    e.Purpose,
+			DocFile:    e.DocFile,
+			Section:    e.SectionID,
+			Provider:   e.Provider,
+			Model:      e.Model,
+			PromptHash: e.PromptHash,
+			Prompt:     e.Prompt,
+			Status:     e.Status,
+			Error:      e.Error,
+		})
+	}
+
+	if format == "json" {
+		return writeJSON(w, records)
+	}
+	enc := json.NewEncoder(w)
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/gitutil"
+	"github.com/kowshik24/git-doc/internal/runlock"
+)
+
+func newReconcileCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "reconcile [amend|rebase]",
+		Short:  "Internal: remap state for rewritten commits read from post-rewrite hook stdin",
+		Args:   cobra.MaximumNArgs(1),
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rewriteType := "rebase"
+			if len(args) == 1 {
+				rewriteType = args[0]
+			}
+
+			rewrites, err := gitutil.ParseRewrites(cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+			if len(rewrites) == 0 {
+				return nil
+			}
+
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			lock, err := app.acquireLock()
+			if err != nil {
+				if !runlock.IsAlreadyRunningError(err) {
+					return err
+				}
+				// A running update amends HEAD itself with git.amend_original and
+				// remaps that commit once its state is written.
+				if rewriteType == "amend" {
+					return nil
+				}
+			}
+			defer lock.Release()
+
+			remapped, err := app.Updater.ReconcileRewrites(rewriteType, rewrites)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("rewritten=%d remapped=%d\n", len(rewrites), remapped)
+			return nil
+		},
+	}
+	return cmd
+}
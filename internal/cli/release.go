@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newReleaseCmd(flags *rootFlags) *cobra.Command {
+	var date string
+
+	cmd := &cobra.Command{
+		Use:   "release <version>",
+		Short: "Move the changelog's Unreleased entries into a dated version section",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseDate := time.Now()
+			if date != "" {
+				parsed, err := time.Parse("2006-01-02", date)
+				if err != nil {
+					return fmt.Errorf("invalid --date %q (use YYYY-MM-DD)", date)
+				}
+				releaseDate = parsed
+			}
+
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			lock, err := app.acquireLock()
+			if err != nil {
+				return err
+			}
+			defer lock.Release()
+
+			docCommit, err := app.Updater.ReleaseChangelog(args[0], releaseDate, flags.dryRun)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case flags.dryRun:
+				fmt.Printf("release %s: dry-run, changelog not written\n", args[0])
+			case docCommit != "":
+				fmt.Printf("release %s: changelog committed as %s\n", args[0], shortCommit(docCommit))
+			default:
+				fmt.Printf("release %s: changelog updated\n", args[0])
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&date, "date", "", "Release date (YYYY-MM-DD, default today)")
+	return cmd
+}
@@ -0,0 +1,363 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/llm"
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+func newReportCmd(flags *rootFlags) *cobra.Command {
+	var htmlPath string
+	var runs int
+	var commits int
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Write a standalone HTML report of processed commits, doc mappings, runs, failures and token usage",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if htmlPath == "" {
+				return fmt.Errorf("--html is required")
+			}
+
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			report, err := buildHTMLReport(app.State, app.RepoRoot, runs, commits, time.Now())
+			if err != nil {
+				return err
+			}
+
+			w, closeReport, err := openReportWriter(htmlPath)
+			if err != nil {
+				return err
+			}
+			if err := writeHTMLReport(w, report); err != nil {
+				_ = closeReport()
+				return err
+			}
+			if err := closeReport(); err != nil {
+				return err
+			}
+			if htmlPath != "-" {
+				fmt.Printf("report: wrote %s (%d commits, %d runs)\n", htmlPath, len(report.Commits), len(report.Runs))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&htmlPath, "html", "", "Write the HTML report to this file (- for stdout)")
+	cmd.Flags().IntVar(&runs, "runs", 50, "Maximum number of runs to include")
+	cmd.Flags().IntVar(&commits, "commits", 500, "Maximum number of processed commits to include")
+	return cmd
+}
+
+type htmlReport struct {
+	Repo        string
+	GeneratedAt time.Time
+	Counts      state.StatusCounts
+	Runs        []reportRun
+	Commits     []reportCommit
+	Failures    []reportCommit
+	Sections    []reportSection
+	Usage       []reportUsage
+	Total       reportUsage
+}
+
+type reportRun struct {
+	state.RunRecord
+	Duration time.Duration
+	Tokens   int
+	// Offset and Width place the run on the timeline, in percent.
+	Offset float64
+	Width  float64
+}
+
+type reportCommit struct {
+	state.ProcessedCommitRow
+	Sections []string
+	// Changes are the commit's planned updates that kept a diff preview.
+	Changes []state.PlannedUpdate
+}
+
+type reportSection struct {
+	DocFile string
+	Section string
+	Commits int
+}
+
+type reportUsage struct {
+	Provider     string
+	Model        string
+	Calls        int
+	InputTokens  int
+	OutputTokens int
+	Cost         float64
+	Priced       bool
+}
+
+type llmCallMetadata struct {
+	Provider     string `json:"provider"`
+	Model        string `json:"model"`
+	InputTokens  int    `json:"input_tokens"`
+	OutputTokens int    `json:"output_tokens"`
+}
+
+func buildHTMLReport(store state.Backend, repoRoot string, runLimit, commitLimit int, now time.Time) (htmlReport, error) {
+	report := htmlReport{Repo: repoRoot, GeneratedAt: now}
+
+	var err error
+	if report.Counts, err = store.GetStatusCounts(); err != nil {
+		return report, err
+	}
+
+	snapshot, err := store.Export()
+	if err != nil {
+		return report, err
+	}
+	sectionsByCommit := map[string][]string{}
+	sectionIndex := map[string]int{}
+	for _, m := range snapshot.Mappings {
+		sectionsByCommit[m.CodeCommitHash] = append(sectionsByCommit[m.CodeCommitHash], m.DocFile+"#"+m.Section)
+		key := m.DocFile + "\x00" + m.Section
+		i, ok := sectionIndex[key]
+		if !ok {
+			i = len(report.Sections)
+			sectionIndex[key] = i
+			report.Sections = append(report.Sections, reportSection{DocFile: m.DocFile, Section: m.Section})
+		}
+		report.Sections[i].Commits++
+	}
+	sort.SliceStable(report.Sections, func(i, j int) bool {
+		return report.Sections[i].Commits > report.Sections[j].Commits
+	})
+
+	rows, err := store.ListRecent(commitLimit)
+	if err != nil {
+		return report, err
+	}
+	for _, row := range rows {
+		commit := reportCommit{ProcessedCommitRow: row, Sections: sectionsByCommit[row.CommitHash]}
+		updates, err := store.GetPlannedUpdates(row.CommitHash)
+		if err != nil {
+			return report, err
+		}
+		for _, update := range updates {
+			if update.DiffPreview != "" {
+				commit.Changes = append(commit.Changes, update)
+			}
+		}
+		report.Commits = append(report.Commits, commit)
+		if row.Status == "failed" {
+			report.Failures = append(report.Failures, commit)
+		}
+	}
+
+	tokensByRun, usage, err := llmUsage(store)
+	if err != nil {
+		return report, err
+	}
+	report.Usage = usage
+	report.Total = reportUsage{Priced: true}
+	for _, u := range usage {
+		report.Total.Calls += u.Calls
+		report.Total.InputTokens += u.InputTokens
+		report.Total.OutputTokens += u.OutputTokens
+		report.Total.Cost += u.Cost
+		report.Total.Priced = report.Total.Priced && u.Priced
+	}
+
+	runs, err := store.ListRuns(runLimit)
+	if err != nil {
+		return report, err
+	}
+	report.Runs = timeline(runs, tokensByRun, now)
+	return report, nil
+}
+
+// llmUsage totals the token estimates recorded with each LLM call, per run
+// and per provider and model.
+func llmUsage(store state.Backend) (map[string]int, []reportUsage, error) {
+	events, err := store.QueryRunEvents(state.RunEventFilter{Component: "llm"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byRun := map[string]int{}
+	index := map[string]int{}
+	var usage []reportUsage
+	for _, event := range events {
+		if event.Message != "llm call" {
+			continue
+		}
+		var call llmCallMetadata
+		if err := json.Unmarshal([]byte(event.Metadata), &call); err != nil {
+			continue
+		}
+		byRun[event.RunID] += call.InputTokens + call.OutputTokens
+
+		key := call.Provider + "\x00" + call.Model
+		i, ok := index[key]
+		if !ok {
+			i = len(usage)
+			index[key] = i
+			_, priced := llm.LookupPrice(call.Provider, call.Model)
+			usage = append(usage, reportUsage{Provider: call.Provider, Model: call.Model, Priced: priced})
+		}
+		usage[i].Calls++
+		usage[i].InputTokens += call.InputTokens
+		usage[i].OutputTokens += call.OutputTokens
+	}
+	for i := range usage {
+		if price, ok := llm.LookupPrice(usage[i].Provider, usage[i].Model); ok {
+			usage[i].Cost = price.Cost(usage[i].InputTokens, usage[i].OutputTokens)
+		}
+	}
+	return byRun, usage, nil
+}
+
+// timeline places runs, oldest first, on a shared time axis.
+func timeline(runs []state.RunRecord, tokensByRun map[string]int, now time.Time) []reportRun {
+	out := make([]reportRun, 0, len(runs))
+	var first, last time.Time
+	for i := len(runs) - 1; i >= 0; i-- {
+		run := reportRun{RunRecord: runs[i], Tokens: tokensByRun[runs[i].ID]}
+		end := now
+		if run.FinishedAt.Valid {
+			end = run.FinishedAt.Time
+		}
+		run.Duration = end.Sub(run.StartedAt)
+		if first.IsZero() || run.StartedAt.Before(first) {
+			first = run.StartedAt
+		}
+		if end.After(last) {
+			last = end
+		}
+		out = append(out, run)
+	}
+
+	span := last.Sub(first)
+	for i := range out {
+		if span <= 0 {
+			out[i].Width = 100
+			continue
+		}
+		out[i].Offset = float64(out[i].StartedAt.Sub(first)) / float64(span) * 100
+		out[i].Width = max(float64(out[i].Duration)/float64(span)*100, 0.5)
+		out[i].Offset = min(out[i].Offset, 100-out[i].Width)
+	}
+	return out
+}
+
+func writeHTMLReport(w io.Writer, report htmlReport) error {
+	return reportTemplate.Execute(w, report)
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"short": shortCommit,
+	"when": func(t time.Time) string {
+		return t.Local().Format("2006-01-02 15:04:05")
+	},
+	"duration": func(d time.Duration) string {
+		return d.Round(time.Second).String()
+	},
+	"pct": func(v float64) template.CSS {
+		return template.CSS(fmt.Sprintf("%.2f%%", v))
+	},
+	"usd": func(v float64) string {
+		return fmt.Sprintf("$%.4f", v)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>git-doc report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem; color: #1f2328; }
+h1 { margin-bottom: 0; }
+h2 { margin-top: 2rem; border-bottom: 1px solid #d0d7de; padding-bottom: .3rem; }
+table { border-collapse: collapse; width: 100%; font-size: .9rem; }
+th, td { text-align: left; padding: .35rem .6rem; border-bottom: 1px solid #eaeef2; vertical-align: top; }
+code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; }
+.muted { color: #656d76; }
+.cards { display: flex; gap: 1rem; flex-wrap: wrap; margin-top: 1rem; }
+.card { border: 1px solid #d0d7de; border-radius: 6px; padding: .6rem 1rem; min-width: 7rem; }
+.card b { display: block; font-size: 1.4rem; }
+.status-success { color: #1a7f37; }
+.status-failed { color: #cf222e; }
+.status-skipped, .status-pending, .status-in_progress { color: #656d76; }
+.track { position: relative; height: 1rem; background: #f6f8fa; border-radius: 3px; }
+.bar { position: absolute; top: 0; height: 100%; border-radius: 3px; background: #0969da; }
+.bar.failed { background: #cf222e; }
+.bar.running { background: #bf8700; }
+</style>
+</head>
+<body>
+<h1>git-doc report</h1>
+<p class="muted"><code>{{.Repo}}</code> · generated {{when .GeneratedAt}}</p>
+
+<div class="cards">
+<div class="card"><b>{{.Counts.Total}}</b>commits</div>
+<div class="card"><b class="status-success">{{.Counts.Success}}</b>documented</div>
+<div class="card"><b class="status-failed">{{.Counts.Failed}}</b>failed</div>
+<div class="card"><b>{{.Counts.Skipped}}</b>skipped</div>
+<div class="card"><b>{{.Counts.Pending}}</b>pending</div>
+<div class="card"><b>{{.Total.InputTokens}} / {{.Total.OutputTokens}}</b>tokens in / out</div>
+</div>
+
+<h2>Runs</h2>
+{{if .Runs}}<table>
+<tr><th>Run</th><th>Trigger</th><th>Status</th><th>Started</th><th>Duration</th><th>Processed</th><th>Success</th><th>Failed</th><th>Tokens</th><th style="width:30%">Timeline</th></tr>
+{{range .Runs}}<tr>
+<td><code>{{.ID}}</code>{{if .DryRun}} <span class="muted">dry-run</span>{{end}}</td>
+<td>{{.Trigger}}</td><td>{{.Status}}</td><td>{{when .StartedAt}}</td><td>{{duration .Duration}}</td>
+<td>{{.Processed}}</td><td>{{.Success}}</td><td>{{.Failed}}</td><td>{{.Tokens}}</td>
+<td><div class="track"><div class="bar{{if gt .Failed 0}} failed{{end}}{{if eq .Status "running"}} running{{end}}" style="left: {{pct .Offset}}; width: {{pct .Width}}"></div></div></td>
+</tr>
+{{end}}</table>{{else}}<p class="muted">No runs recorded.</p>{{end}}
+
+<h2>Failures</h2>
+{{if .Failures}}<table>
+<tr><th>Commit</th><th>When</th><th>Class</th><th>Error</th></tr>
+{{range .Failures}}<tr><td><code>{{short .CommitHash}}</code></td><td>{{when .ProcessedAt}}</td><td>{{.ErrorClass.String}}</td><td>{{.Error.String}}</td></tr>
+{{end}}</table>{{else}}<p class="muted">No failed commits.</p>{{end}}
+
+<h2>Doc sections</h2>
+{{if .Sections}}<table>
+<tr><th>Doc file</th><th>Section</th><th>Commits documented</th></tr>
+{{range .Sections}}<tr><td><code>{{.DocFile}}</code></td><td>{{.Section}}</td><td>{{.Commits}}</td></tr>
+{{end}}</table>{{else}}<p class="muted">No doc mappings recorded.</p>{{end}}
+
+<h2>Token usage</h2>
+{{if .Usage}}<table>
+<tr><th>Provider</th><th>Model</th><th>Calls</th><th>Input tokens</th><th>Output tokens</th><th>Estimated cost</th></tr>
+{{range .Usage}}<tr><td>{{.Provider}}</td><td>{{.Model}}</td><td>{{.Calls}}</td><td>{{.InputTokens}}</td><td>{{.OutputTokens}}</td><td>{{if .Priced}}{{usd .Cost}}{{else}}<span class="muted">unknown price</span>{{end}}</td></tr>
+{{end}}<tr><th colspan="2">Total</th><th>{{.Total.Calls}}</th><th>{{.Total.InputTokens}}</th><th>{{.Total.OutputTokens}}</th><th>{{usd .Total.Cost}}{{if not .Total.Priced}}+{{end}}</th></tr>
+</table>
+<p class="muted">Token counts are estimates made when each call was sent; cached responses are not counted.</p>{{else}}<p class="muted">No LLM calls recorded.</p>{{end}}
+
+<h2>Processed commits</h2>
+{{if .Commits}}<table>
+<tr><th>Commit</th><th>Status</th><th>When</th><th>Doc sections</th><th>Doc commit</th></tr>
+{{range .Commits}}<tr>
+<td><code>{{short .CommitHash}}</code></td>
+<td class="status-{{.Status}}">{{.Status}}{{if .RevertedBy.Valid}} <span class="muted">(reverted by <code>{{short .RevertedBy.String}}</code>)</span>{{end}}</td>
+<td>{{when .ProcessedAt}}</td>
+<td>{{range $i, $s := .Sections}}{{if $i}}, {{end}}<code>{{$s}}</code>{{end}}{{range .Changes}}
+<details><summary><code>{{.DocFile}}#{{.Section}}</code> <span class="muted">{{.Status}}</span></summary><pre>{{.DiffPreview}}</pre></details>{{end}}</td>
+<td>{{if .DocCommit.Valid}}<code>{{short .DocCommit.String}}</code>{{end}}</td>
+</tr>
+{{end}}</table>{{else}}<p class="muted">No commits processed yet.</p>{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+func TestHTMLReportRendersSections(t *testing.T) {
+	store, err := state.Open(filepath.Join(t.TempDir(), "state.db"), "")
+	if err != nil {
+		t.Fatalf("open state: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.StartRun("run-1", "manual", false); err != nil {
+		t.Fatalf("start run: %v", err)
+	}
+	if err := store.MarkCommitProcessed("aaaaaaaaaaaa", "success", "", "dddddddddddd", nil); err != nil {
+		t.Fatalf("mark success: %v", err)
+	}
+	if err := store.StoreMapping("aaaaaaaaaaaa", "README.md", "Recent Changes"); err != nil {
+		t.Fatalf("store mapping: %v", err)
+	}
+	if err := store.UpsertPlannedUpdate("aaaaaaaaaaaa", "README.md", "Recent Changes", "inferred", "applied", ""); err != nil {
+		t.Fatalf("upsert planned update: %v", err)
+	}
+	if err := store.PutPlannedContent("aaaaaaaaaaaa", "README.md", "Recent Changes", "- Added retries", "-old\n+- Added retries"); err != nil {
+		t.Fatalf("put planned content: %v", err)
+	}
+	if err := store.MarkCommitFailed("bbbbbbbbbbbb", "section <Usage> not found", state.ErrorClassPermanent); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+	if err := store.LogRunEvent("run-1", "aaaaaaaaaaaa", "info", "llm", "llm call", map[string]any{
+		"provider": "openai", "model": "gpt-4o-mini", "input_tokens": 1200, "output_tokens": 300,
+	}); err != nil {
+		t.Fatalf("log event: %v", err)
+	}
+	if err := store.LogRunEvent("run-1", "aaaaaaaaaaaa", "info", "llm", "llm call", map[string]any{
+		"provider": "openai", "model": "gpt-4o-mini", "input_tokens": 800, "output_tokens": 200,
+	}); err != nil {
+		t.Fatalf("log event: %v", err)
+	}
+	if err := store.FinishRun("run-1", "completed", state.RunCounts{Processed: 2, Success: 1, Failed: 1}); err != nil {
+		t.Fatalf("finish run: %v", err)
+	}
+
+	report, err := buildHTMLReport(store, "/repo", 10, 10, time.Now())
+	if err != nil {
+		t.Fatalf("build report: %v", err)
+	}
+	if len(report.Runs) != 1 || report.Runs[0].Tokens != 2500 {
+		t.Fatalf("expected one run with 2500 tokens, got %+v", report.Runs)
+	}
+	if len(report.Usage) != 1 || report.Usage[0].Calls != 2 || report.Usage[0].InputTokens != 2000 {
+		t.Fatalf("unexpected usage: %+v", report.Usage)
+	}
+
+	var out bytes.Buffer
+	if err := writeHTMLReport(&out, report); err != nil {
+		t.Fatalf("write report: %v", err)
+	}
+	html := out.String()
+	for _, want := range []string{"<h2>Runs</h2>", "run-1", "README.md", "Recent Changes", "gpt-4o-mini", "permanent", "section &lt;Usage&gt; not found", "<pre>-old\n&#43;- Added retries</pre>"} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("report is missing %q", want)
+		}
+	}
+	if strings.Contains(html, "<Usage>") {
+		t.Fatalf("expected error text to be escaped")
+	}
+}
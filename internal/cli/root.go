@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -17,17 +18,27 @@ import (
 	"github.com/kowshik24/git-doc/internal/gitutil"
 	"github.com/kowshik24/git-doc/internal/hooks"
 	"github.com/kowshik24/git-doc/internal/llm"
+	"github.com/kowshik24/git-doc/internal/notify"
 	"github.com/kowshik24/git-doc/internal/orchestrator"
+	"github.com/kowshik24/git-doc/internal/progress"
 	"github.com/kowshik24/git-doc/internal/runlock"
 	"github.com/kowshik24/git-doc/internal/state"
+	"github.com/kowshik24/git-doc/internal/tracing"
 )
 
 var version = "dev"
 
 type rootFlags struct {
 	configPath string
+	profile    string
 	dryRun     bool
+	offline    bool
 	verbose    bool
+	quiet      bool
+
+	// implicitProfile is applied when no profile was requested and the
+	// config defines one with this name, e.g. "hook" for hook-driven runs.
+	implicitProfile string
 }
 
 func NewRootCmd() *cobra.Command {
@@ -35,20 +46,49 @@ func NewRootCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "git-doc",
 		Short: "Automatically update docs based on Git commits",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			watchInterrupts(cmd)
+		},
 	}
 
 	cmd.PersistentFlags().StringVar(&flags.configPath, "config", ".git-doc/config.toml", "Path to config file")
+	cmd.PersistentFlags().StringVar(&flags.profile, "profile", "", "Config profile to apply (default $GIT_DOC_PROFILE)")
 	cmd.PersistentFlags().BoolVar(&flags.dryRun, "dry-run", false, "Preview changes without applying or committing")
+	cmd.PersistentFlags().BoolVar(&flags.offline, "offline", false, "Use only cached responses and local providers; commits needing the network stay pending")
 	cmd.PersistentFlags().BoolVar(&flags.verbose, "verbose", false, "Enable verbose logging")
+	cmd.PersistentFlags().BoolVarP(&flags.quiet, "quiet", "q", false, "Suppress progress output")
 
-	cmd.AddCommand(newInitCmd())
+	cmd.AddCommand(newInitCmd(flags))
 	cmd.AddCommand(newConfigCmd(flags))
 	cmd.AddCommand(newUpdateCmd(flags))
+	cmd.AddCommand(newDaemonCmd(flags))
 	cmd.AddCommand(newEnableHookCmd())
 	cmd.AddCommand(newDisableHookCmd())
+	cmd.AddCommand(newUnlockCmd())
+	cmd.AddCommand(newDoctorCmd(flags))
+	cmd.AddCommand(newInstallAliasCmd())
+	cmd.AddCommand(newReportCmd(flags))
+	cmd.AddCommand(newDigestCmd(flags))
 	cmd.AddCommand(newStatusCmd(flags))
 	cmd.AddCommand(newRetryCmd(flags))
 	cmd.AddCommand(newRevertCmd(flags))
+	cmd.AddCommand(newPendingCmd(flags))
+	cmd.AddCommand(newApproveCmd(flags))
+	cmd.AddCommand(newRejectCmd(flags))
+	cmd.AddCommand(newRunsCmd(flags))
+	cmd.AddCommand(newLogsCmd(flags))
+	cmd.AddCommand(newBackfillCmd(flags))
+	cmd.AddCommand(newAuditCmd(flags))
+	cmd.AddCommand(newScanCmd(flags))
+	cmd.AddCommand(newSuggestMappingsCmd(flags))
+	cmd.AddCommand(newCoverageCmd(flags))
+	cmd.AddCommand(newWhyCmd(flags))
+	cmd.AddCommand(newBlameCmd(flags))
+	cmd.AddCommand(newReleaseCmd(flags))
+	cmd.AddCommand(newReconcileCmd(flags))
+	cmd.AddCommand(newStateCmd(flags))
+	cmd.AddCommand(newCacheCmd(flags))
+	cmd.AddCommand(newExportPromptsCmd(flags))
 	cmd.AddCommand(&cobra.Command{
 		Use:   "version",
 		Short: "Show version",
@@ -68,16 +108,11 @@ func newConfigCmd(flags *rootFlags) *cobra.Command {
 		Use:   "config",
 		Short: "Show or edit git-doc configuration",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			repoRoot, err := gitutil.GetRepoRoot()
+			_, configPath, err := resolveConfigPath(flags)
 			if err != nil {
 				return err
 			}
 
-			configPath := flags.configPath
-			if !filepath.IsAbs(configPath) {
-				configPath = filepath.Join(repoRoot, configPath)
-			}
-
 			if showPath {
 				fmt.Println(configPath)
 				return nil
@@ -112,11 +147,17 @@ func newConfigCmd(flags *rootFlags) *cobra.Command {
 
 	cmd.Flags().BoolVar(&edit, "edit", false, "Open configuration file in editor")
 	cmd.Flags().BoolVar(&showPath, "path", false, "Print resolved configuration file path")
+	cmd.AddCommand(newConfigValidateCmd(flags))
+	cmd.AddCommand(newConfigGetCmd(flags))
+	cmd.AddCommand(newConfigSetCmd(flags))
 	return cmd
 }
 
 func newEnableHookCmd() *cobra.Command {
-	return &cobra.Command{
+	var appendMode bool
+	var prePush bool
+
+	cmd := &cobra.Command{
 		Use:   "enable-hook",
 		Short: "Install git-doc hooks (post-commit, post-merge, post-rewrite)",
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -126,14 +167,37 @@ func newEnableHookCmd() *cobra.Command {
 			}
 
 			mgr := hooks.NewManager(repoRoot)
-			if err := mgr.Enable(); err != nil {
+			hooksDir, _, err := mgr.HooksDir()
+			if err != nil {
+				return err
+			}
+			switch {
+			case prePush:
+				err = mgr.EnablePrePush(appendMode)
+			case appendMode:
+				err = mgr.EnableAppend()
+			default:
+				err = mgr.Enable()
+			}
+			if err != nil {
 				return err
 			}
 
-			fmt.Println("git hooks enabled")
+			if rel, relErr := filepath.Rel(repoRoot, hooksDir); relErr == nil {
+				hooksDir = rel
+			}
+			if prePush {
+				fmt.Printf("pre-push doc check enabled in %s (bypass with %s=1)\n", hooksDir, hooks.SkipPushCheckEnv)
+				return nil
+			}
+			fmt.Printf("git hooks enabled in %s\n", hooksDir)
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&appendMode, "append", false, "Append git-doc commands to existing hook scripts instead of replacing them")
+	cmd.Flags().BoolVar(&prePush, "pre-push", false, "Install only a pre-push hook that refuses pushes while mapped docs are stale")
+	return cmd
 }
 
 func newDisableHookCmd() *cobra.Command {
@@ -157,10 +221,13 @@ func newDisableHookCmd() *cobra.Command {
 	}
 }
 
-func newInitCmd() *cobra.Command {
-	return &cobra.Command{
+func newInitCmd(flags *rootFlags) *cobra.Command {
+	var defaults bool
+
+	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Initialize .git-doc config and state directory",
+		Long:  "Initialize .git-doc config and state directory. On a terminal a wizard detects doc files, suggests mappings, sets the provider and API key, offers to install hooks and runs a test generation with the mock provider.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			repoRoot, err := gitutil.GetRepoRoot()
 			if err != nil {
@@ -173,66 +240,274 @@ func newInitCmd() *cobra.Command {
 			}
 
 			configPath := filepath.Join(gitDocDir, "config.toml")
-			if _, statErr := os.Stat(configPath); errors.Is(statErr, os.ErrNotExist) {
-				if err := os.WriteFile(configPath, []byte(config.DefaultToml()), 0o600); err != nil {
-					return fmt.Errorf("write config: %w", err)
-				}
+			if _, statErr := os.Stat(configPath); !errors.Is(statErr, os.ErrNotExist) {
+				fmt.Printf("Initialized git-doc at %s (kept the existing config)\n", gitDocDir)
+				return nil
 			}
 
+			content := config.DefaultToml()
+			interactive := !defaults && terminalConfirm(cmd) != nil
+			installHooks := false
+			if interactive {
+				files, err := gitutil.NewHelper(repoRoot).ListTrackedFiles()
+				if err != nil {
+					return err
+				}
+				content, installHooks, err = initWizard(cmd.InOrStdin(), cmd.OutOrStdout(), files, content)
+				if err != nil {
+					return err
+				}
+			}
+			if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+				return fmt.Errorf("write config: %w", err)
+			}
 			fmt.Printf("Initialized git-doc at %s\n", gitDocDir)
+
+			if installHooks {
+				if err := hooks.NewManager(repoRoot).Enable(); err != nil {
+					return err
+				}
+				fmt.Println("git hooks enabled")
+			}
+			if interactive {
+				previewInit(cmd, flags, content)
+			}
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&defaults, "defaults", false, "Write the default config without asking questions")
+	return cmd
+}
+
+// previewInit generates the update for HEAD with the new config and the mock
+// provider, so mistakes in doc files and mappings show up before the first
+// real run. Failures are reported, not returned: the config is written.
+func previewInit(cmd *cobra.Command, flags *rootFlags, content string) {
+	// A copy using the mock provider loads even when the API key variable is
+	// not exported yet.
+	content, err := config.SetValue(content, "llm.provider", "mock")
+	if err != nil {
+		fmt.Printf("Test generation skipped: %v\n", err)
+		return
+	}
+	tmp, err := os.CreateTemp("", "git-doc-init-*.toml")
+	if err != nil {
+		fmt.Printf("Test generation skipped: %v\n", err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Printf("Test generation skipped: %v\n", err)
+		return
+	}
+
+	previewFlags := *flags
+	previewFlags.configPath = tmp.Name()
+	app, err := buildAppWithConfig(&previewFlags, func(cfg *config.Config) {
+		cfg.LLM.FallbackProviders = nil
+		cfg.LLM.Providers = nil
+	})
+	if err != nil {
+		fmt.Printf("Test generation skipped: %v\n", err)
+		return
+	}
+	defer app.Close()
+
+	head, err := app.Git.GetCurrentHEAD()
+	if err != nil {
+		fmt.Printf("Test generation skipped: %v\n", err)
+		return
+	}
+	preview, err := app.Updater.PreviewCommit(cmd.Context(), head)
+	switch {
+	case err != nil:
+		fmt.Printf("Test generation for %s failed: %v\n", shortCommit(head), err)
+	case preview.SkipReason != "":
+		fmt.Printf("Test generation: %s would be skipped (%s)\n", shortCommit(head), preview.SkipReason)
+	default:
+		fmt.Printf("Test generation with the mock provider: %s would update %s#%s with:\n%s\n", shortCommit(head), preview.DocFile, preview.Section, preview.Content)
+	}
 }
 
 func newUpdateCmd(flags *rootFlags) *cobra.Command {
 	var fromHook bool
 	var fromHash string
 	var toHash string
+	var estimate bool
+	var ci bool
+	var reportFormat string
+	var reportFile string
+	var noVerify bool
 
 	cmd := &cobra.Command{
 		Use:   "update",
 		Short: "Process new commits and update documentation",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			app, err := buildApp(flags)
+			switch {
+			case ci:
+				flags.implicitProfile = "ci"
+			case fromHook:
+				flags.implicitProfile = "hook"
+			}
+			app, err := buildAppWithConfig(flags, func(cfg *config.Config) {
+				if ci {
+					cfg.Git.CommitDocUpdates = false
+					cfg.Git.AmendOriginal = false
+				}
+				if noVerify {
+					cfg.Git.NoVerify = true
+				}
+			})
 			if err != nil {
 				return err
 			}
+			defer app.Close()
+
+			isRange := strings.TrimSpace(fromHash) != "" || strings.TrimSpace(toHash) != ""
+			if estimate {
+				var commits []string
+				if isRange {
+					commits, err = app.Updater.RangeCommits(fromHash, toHash)
+				} else {
+					commits, err = app.Updater.NewCommits()
+				}
+				if err != nil {
+					return err
+				}
 
-			lock, err := runlock.Acquire(app.RepoRoot)
-			if err != nil {
-				if fromHook && runlock.IsAlreadyRunningError(err) {
+				result, err := app.Updater.Estimate(cmd.Context(), commits)
+				if err != nil {
+					return err
+				}
+				printEstimate(result)
+				return nil
+			}
+
+			if fromHook {
+				if strings.TrimSpace(app.Config.Runtime.Schedule) != "" {
+					// git-doc daemon documents the accumulated commits at the scheduled time.
 					return nil
 				}
+				app.Updater.SetTrigger("hook")
+			} else if !flags.dryRun {
+				if err := ensureOllamaModels(cmd, app.Config); err != nil {
+					return err
+				}
+				if !ci {
+					app.Updater.SetConfirm(terminalConfirm(cmd))
+				}
+			}
+
+			if !isRange && !ci {
+				var summary orchestrator.Summary
+				err := runQueued(app, func() error {
+					more, err := app.Updater.UpdateNewCommits(cmd.Context(), flags.dryRun)
+					summary.Processed += more.Processed
+					summary.Success += more.Success
+					summary.Failed += more.Failed
+					summary.Skipped += more.Skipped
+					if err == nil {
+						err = interruptedError(cmd, more)
+					}
+					return err
+				})
+				if err != nil {
+					if fromHook && runlock.IsAlreadyRunningError(err) {
+						return runlock.MarkPending(app.RepoRoot)
+					}
+					return err
+				}
+				fmt.Printf("processed=%d success=%d failed=%d skipped=%d\n", summary.Processed, summary.Success, summary.Failed, summary.Skipped)
+				return nil
+			}
+
+			lock, err := app.acquireLock()
+			if err != nil {
 				return err
 			}
 			defer lock.Release()
 
-			var summary orchestrator.Summary
-			if strings.TrimSpace(fromHash) != "" || strings.TrimSpace(toHash) != "" {
-				summary, err = app.Updater.UpdateRangeCommits(cmd.Context(), fromHash, toHash, flags.dryRun)
-			} else {
-				summary, err = app.Updater.UpdateNewCommits(cmd.Context(), flags.dryRun)
+			if ci {
+				app.Updater.SetTrigger("ci")
+				var commits []string
+				if isRange {
+					commits, err = app.Updater.RangeCommits(fromHash, toHash)
+				} else {
+					commits, err = app.Updater.NewCommits()
+				}
+				if err != nil {
+					return err
+				}
+				return runCI(cmd, app, commits, reportFormat, reportFile, flags.dryRun)
 			}
+
+			summary, err := app.Updater.UpdateRangeCommits(cmd.Context(), fromHash, toHash, flags.dryRun)
 			if err != nil {
 				return err
 			}
 
 			fmt.Printf("processed=%d success=%d failed=%d skipped=%d\n", summary.Processed, summary.Success, summary.Failed, summary.Skipped)
-			return nil
+			return interruptedError(cmd, summary)
 		},
 	}
 
 	cmd.Flags().BoolVar(&fromHook, "from-hook", false, "Internal: run invoked from git hook")
 	cmd.Flags().StringVar(&fromHash, "from", "", "Start commit (exclusive) for manual range updates")
 	cmd.Flags().StringVar(&toHash, "to", "", "End commit (inclusive, default HEAD) for manual range updates")
+	cmd.Flags().BoolVar(&estimate, "estimate", false, "Print projected token usage and API cost without calling the LLM")
+	cmd.Flags().BoolVar(&ci, "ci", false, "Never commit; report results and exit non-zero on failed commits or stale docs")
+	cmd.Flags().BoolVar(&noVerify, "no-verify", false, "Skip pre-commit and commit-msg hooks when committing doc updates")
+	cmd.Flags().StringVar(&reportFormat, "output", "json", "CI report format: json, junit or github (workflow command annotations)")
+	cmd.Flags().StringVar(&reportFile, "report-file", "", "Write the CI report to a file instead of stdout")
 	_ = cmd.Flags().MarkHidden("from-hook")
 	return cmd
 }
 
+// runQueued runs fn under the run lock, and again for as long as triggers
+// were queued while it ran, so commits arriving during a run are processed
+// by it. When another run takes the lock in between, that run has them.
+func runQueued(app *appContainer, fn func() error) error {
+	for first := true; ; first = false {
+		lock, err := app.acquireLock()
+		if err != nil {
+			if !first && runlock.IsAlreadyRunningError(err) {
+				return nil
+			}
+			return err
+		}
+		if _, err := runlock.TakePending(app.RepoRoot); err != nil {
+			lock.Release()
+			return err
+		}
+
+		err = fn()
+		lock.Release()
+		if err != nil {
+			return err
+		}
+
+		pending, err := runlock.HasPending(app.RepoRoot)
+		if err != nil || !pending {
+			return err
+		}
+	}
+}
+
 func newStatusCmd(flags *rootFlags) *cobra.Command {
 	var asJSON bool
 	var limit int
+	var watch bool
+	var interval time.Duration
+	var statusFilter string
+	var since string
+	var docFile string
+	var grep string
+	var page int
 
 	cmd := &cobra.Command{
 		Use:   "status",
@@ -242,24 +517,77 @@ func newStatusCmd(flags *rootFlags) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			defer app.Close()
 
-			rows, err := app.State.ListRecent(limit)
+			if watch {
+				if !cmd.Flags().Changed("limit") {
+					limit = 10
+				}
+				return runStatusWatch(cmd, app, flags, limit, interval)
+			}
+
+			if limit <= 0 {
+				limit = 25
+			}
+			if page < 1 {
+				return fmt.Errorf("--page must be at least 1, got %d", page)
+			}
+			filter := state.CommitFilter{
+				Status:  strings.TrimSpace(statusFilter),
+				DocFile: filepath.ToSlash(strings.TrimSpace(docFile)),
+				Grep:    grep,
+				Limit:   limit,
+				Offset:  (page - 1) * limit,
+			}
+			switch filter.Status {
+			case "", "pending", "in_progress", "success", "failed", "skipped":
+			default:
+				return fmt.Errorf("--status must be pending, in_progress, success, failed or skipped, got %q", filter.Status)
+			}
+			if filter.Since, err = parseSince(since, time.Now()); err != nil {
+				return err
+			}
+			rows, err := app.State.QueryCommits(filter)
 			if err != nil {
 				return err
 			}
+			matching, err := app.State.CountCommits(filter)
+			if err != nil {
+				return err
+			}
+			pages := max((matching+limit-1)/limit, 1)
 
 			counts, err := app.State.GetStatusCounts()
 			if err != nil {
 				return err
 			}
 
+			inventory, err := app.State.GetDocInventory()
+			if err != nil {
+				return err
+			}
+			queued, err := runlock.HasPending(app.RepoRoot)
+			if err != nil {
+				return err
+			}
+			inventoryFiles := map[string]bool{}
+			var scannedAt time.Time
+			for _, entry := range inventory {
+				inventoryFiles[entry.DocFile] = true
+				if entry.ScannedAt.After(scannedAt) {
+					scannedAt = entry.ScannedAt
+				}
+			}
+
 			if asJSON {
 				type statusRow struct {
 					CommitHash  string `json:"commit_hash"`
 					Status      string `json:"status"`
 					ProcessedAt string `json:"processed_at"`
 					Error       string `json:"error,omitempty"`
+					ErrorClass  string `json:"error_class,omitempty"`
 					DocCommit   string `json:"doc_commit_hash,omitempty"`
+					RevertedBy  string `json:"reverted_by,omitempty"`
 				}
 
 				payloadRows := make([]statusRow, 0, len(rows))
@@ -272,9 +600,15 @@ func newStatusCmd(flags *rootFlags) *cobra.Command {
 					if row.Error.Valid {
 						entry.Error = row.Error.String
 					}
+					if row.ErrorClass.Valid {
+						entry.ErrorClass = row.ErrorClass.String
+					}
 					if row.DocCommit.Valid {
 						entry.DocCommit = row.DocCommit.String
 					}
+					if row.RevertedBy.Valid {
+						entry.RevertedBy = row.RevertedBy.String
+					}
 					payloadRows = append(payloadRows, entry)
 				}
 
@@ -282,6 +616,24 @@ func newStatusCmd(flags *rootFlags) *cobra.Command {
 					"generated_at": time.Now().UTC().Format(time.RFC3339),
 					"counts":       counts,
 					"recent":       payloadRows,
+					"matching":     matching,
+					"page":         page,
+					"pages":        pages,
+					"queued":       queued,
+				}
+				if len(inventory) > 0 {
+					payload["inventory"] = map[string]any{
+						"doc_files":  len(inventoryFiles),
+						"sections":   len(inventory),
+						"scanned_at": scannedAt.UTC().Format(time.RFC3339),
+					}
+				}
+				if flags.verbose {
+					sizes, err := app.State.TableSizes()
+					if err != nil {
+						return err
+					}
+					payload["tables"] = sizes
 				}
 
 				out, err := json.MarshalIndent(payload, "", "  ")
@@ -294,21 +646,63 @@ func newStatusCmd(flags *rootFlags) *cobra.Command {
 
 			fmt.Printf("pending=%d in_progress=%d success=%d failed=%d skipped=%d total=%d\n",
 				counts.Pending, counts.InProgress, counts.Success, counts.Failed, counts.Skipped, counts.Total)
+			if counts.FailedPermanent > 0 {
+				fmt.Printf("%d failed commits hit permanent errors and are skipped by retry (use retry --all after fixing the cause)\n", counts.FailedPermanent)
+			}
+			if queued {
+				fmt.Println("queued: a hook fired during a run; new commits are processed by the next update")
+			}
+			if len(inventory) > 0 {
+				fmt.Printf("doc inventory: %d sections in %d doc files, scanned %s\n", len(inventory), len(inventoryFiles), scannedAt.Local().Format("2006-01-02 15:04:05"))
+			}
 
 			for _, row := range rows {
+				if row.RevertedBy.Valid {
+					fmt.Printf("%s %s %s reverted=%s\n", row.CommitHash, row.Status, row.ProcessedAt.Format("2006-01-02 15:04:05"), row.RevertedBy.String)
+					continue
+				}
+				if row.ErrorClass.Valid {
+					fmt.Printf("%s %s %s class=%s\n", row.CommitHash, row.Status, row.ProcessedAt.Format("2006-01-02 15:04:05"), row.ErrorClass.String)
+					continue
+				}
 				fmt.Printf("%s %s %s\n", row.CommitHash, row.Status, row.ProcessedAt.Format("2006-01-02 15:04:05"))
 			}
+			if matching > len(rows) {
+				fmt.Printf("showing %d of %d matching commits (page %d of %d; --page for more)\n", len(rows), matching, page, pages)
+			}
+
+			if flags.verbose {
+				sizes, err := app.State.TableSizes()
+				if err != nil {
+					return err
+				}
+				var total int64
+				fmt.Println("\nstate tables:")
+				for _, size := range sizes {
+					total += size.Bytes
+					fmt.Printf("  %-18s rows=%-8d size=%s\n", size.Name, size.Rows, formatBytes(size.Bytes))
+				}
+				fmt.Printf("  %-18s size=%s\n", "total", formatBytes(total))
+			}
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVar(&asJSON, "json", false, "Output status as JSON")
-	cmd.Flags().IntVar(&limit, "limit", 25, "Maximum number of recent commit rows")
+	cmd.Flags().IntVar(&limit, "limit", 25, "Maximum number of commit rows per page")
+	cmd.Flags().StringVar(&statusFilter, "status", "", "Only show commits with this status: pending, in_progress, success, failed or skipped")
+	cmd.Flags().StringVar(&since, "since", "", "Only show commits processed after a duration ago (30m, 24h, 7d) or a date (2006-01-02, RFC3339)")
+	cmd.Flags().StringVar(&docFile, "doc-file", "", "Only show commits that updated or were routed to this doc file")
+	cmd.Flags().StringVar(&grep, "grep", "", "Only show commits whose error contains this text (case-insensitive)")
+	cmd.Flags().IntVar(&page, "page", 1, "Page of matching commits to show, --limit rows each")
+	cmd.Flags().BoolVar(&watch, "watch", false, "Show a live dashboard; j/k select a commit, enter inspects it, r retries it, q quits")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "Refresh interval for --watch")
 	return cmd
 }
 
 func newRetryCmd(flags *rootFlags) *cobra.Command {
 	var specificCommit string
+	var all bool
 
 	cmd := &cobra.Command{
 		Use:   "retry",
@@ -318,8 +712,9 @@ func newRetryCmd(flags *rootFlags) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			defer app.Close()
 
-			lock, err := runlock.Acquire(app.RepoRoot)
+			lock, err := app.acquireLock()
 			if err != nil {
 				return err
 			}
@@ -333,104 +728,316 @@ func newRetryCmd(flags *rootFlags) *cobra.Command {
 				if err != nil {
 					return err
 				}
+				if all {
+					failed, err := app.State.GetFailedCommits()
+					if err != nil {
+						return err
+					}
+					for _, hash := range failed {
+						if !slices.Contains(commits, hash) {
+							commits = append(commits, hash)
+						}
+					}
+				}
 			}
 
+			app.Updater.SetTrigger("retry")
 			summary, err := app.Updater.UpdateCommitList(cmd.Context(), commits, flags.dryRun)
 			if err != nil {
 				return err
 			}
 
 			fmt.Printf("retried=%d success=%d failed=%d skipped=%d\n", summary.Processed, summary.Success, summary.Failed, summary.Skipped)
-			return nil
+			return interruptedError(cmd, summary)
 		},
 	}
 
 	cmd.Flags().StringVar(&specificCommit, "commit", "", "Retry specific commit hash")
+	cmd.Flags().BoolVar(&all, "all", false, "Also retry commits that failed with permanent errors")
 	return cmd
 }
 
 func newRevertCmd(flags *rootFlags) *cobra.Command {
-	return &cobra.Command{
-		Use:   "revert <code-commit-hash>",
-		Short: "Revert documentation commit linked to a code commit",
-		Args:  cobra.ExactArgs(1),
+	var cascade bool
+	var runID string
+	var fromHash string
+	var toHash string
+
+	cmd := &cobra.Command{
+		Use:   "revert [code-commit-hash]",
+		Short: "Revert documentation commits linked to code commits",
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			isRange := strings.TrimSpace(fromHash) != "" || strings.TrimSpace(toHash) != ""
+			selectors := 0
+			for _, set := range []bool{len(args) == 1, strings.TrimSpace(runID) != "", isRange} {
+				if set {
+					selectors++
+				}
+			}
+			if selectors != 1 {
+				return fmt.Errorf("specify exactly one of a code commit, --run or --from/--to")
+			}
+			if cascade && len(args) == 0 {
+				return fmt.Errorf("--cascade requires a code commit")
+			}
+
 			app, err := buildApp(flags)
 			if err != nil {
 				return err
 			}
+			defer app.Close()
 
-			codeCommit := args[0]
-			docCommit, err := app.State.GetDocCommitHash(codeCommit)
+			var commits []string
+			switch {
+			case strings.TrimSpace(runID) != "":
+				commits, err = app.Updater.RunCommits(strings.TrimSpace(runID))
+			case isRange:
+				commits, err = app.Updater.RangeCommits(fromHash, toHash)
+			case cascade:
+				commits, err = app.Updater.CascadeCommits(args[0])
+			default:
+				commits = []string{args[0]}
+			}
 			if err != nil {
 				return err
 			}
-			if docCommit == "" {
-				return fmt.Errorf("no documentation commit found for code commit %s", codeCommit)
+
+			targets, err := app.Updater.RevertTargets(commits)
+			if err != nil {
+				return err
+			}
+			if len(targets) == 0 {
+				if len(args) == 1 && !cascade {
+					return fmt.Errorf("no documentation commit found for code commit %s", args[0])
+				}
+				fmt.Println("revert: no documentation commits to revert")
+				return nil
 			}
 
 			if flags.dryRun {
-				fmt.Printf("dry-run: would revert doc commit %s (for code commit %s)\n", docCommit, codeCommit)
+				for _, target := range targets {
+					fmt.Printf("dry-run: would revert doc commit %s (for code commit %s)\n", target.DocCommit, strings.Join(target.CodeCommits, ", "))
+					for _, hash := range target.CodeCommits {
+						updates, err := app.State.GetPlannedUpdates(hash)
+						if err != nil {
+							return err
+						}
+						for _, update := range updates {
+							if update.DiffPreview != "" {
+								fmt.Printf("\n%s#%s:\n%s\n\n", update.DocFile, update.Section, update.DiffPreview)
+							}
+						}
+					}
+				}
 				return nil
 			}
 
-			if err := app.Git.RevertCommit(docCommit); err != nil {
+			lock, err := app.acquireLock()
+			if err != nil {
 				return err
 			}
+			defer lock.Release()
 
-			fmt.Printf("reverted doc commit %s\n", docCommit)
-			return nil
+			summary, err := app.Updater.RevertDocCommits(targets)
+			for _, target := range targets[:summary.Success] {
+				fmt.Printf("reverted doc commit %s\n", target.DocCommit)
+			}
+			return err
 		},
 	}
+
+	cmd.Flags().BoolVar(&cascade, "cascade", false, "Also revert doc commits of every later commit up to HEAD, newest first")
+	cmd.Flags().StringVar(&runID, "run", "", "Revert all doc commits produced by a run")
+	cmd.Flags().StringVar(&fromHash, "from", "", "Start commit (exclusive) of a range to revert")
+	cmd.Flags().StringVar(&toHash, "to", "", "End commit (inclusive, default HEAD) of a range to revert")
+	return cmd
 }
 
 type appContainer struct {
 	Updater  *orchestrator.Updater
-	State    *state.Store
+	State    state.Backend
 	Git      gitutil.Helper
 	RepoRoot string
+	LockTTL  time.Duration
+	Config   *config.Config
+	// SharedCache is the cache.dir response cache, nil when unset.
+	SharedCache *state.SharedCache
+}
+
+func (a *appContainer) acquireLock() (*runlock.Lock, error) {
+	return runlock.AcquireTTL(a.RepoRoot, a.LockTTL)
+}
+
+func (a *appContainer) Close() error {
+	if a.SharedCache != nil {
+		a.SharedCache.Close()
+	}
+	return a.State.Close()
 }
 
 func buildApp(flags *rootFlags) (*appContainer, error) {
+	return buildAppWithConfig(flags, nil)
+}
+
+func resolveConfigPath(flags *rootFlags) (string, string, error) {
 	repoRoot, err := gitutil.GetRepoRoot()
 	if err != nil {
-		return nil, err
+		return "", "", err
 	}
 
 	configPath := flags.configPath
 	if !filepath.IsAbs(configPath) {
 		configPath = filepath.Join(repoRoot, configPath)
 	}
+	return repoRoot, configPath, nil
+}
+
+func loadConfig(flags *rootFlags, configPath string) (*config.Config, error) {
+	profile := strings.TrimSpace(flags.profile)
+	if profile == "" {
+		profile = strings.TrimSpace(os.Getenv("GIT_DOC_PROFILE"))
+	}
+	if profile != "" {
+		return config.LoadProfile(configPath, profile)
+	}
 
 	cfg, err := config.Load(configPath)
+	if err != nil || flags.implicitProfile == "" {
+		return cfg, err
+	}
+	if _, ok := cfg.Profiles[flags.implicitProfile]; !ok {
+		return cfg, nil
+	}
+	return config.LoadProfile(configPath, flags.implicitProfile)
+}
+
+func buildAppWithConfig(flags *rootFlags, adjust func(*config.Config)) (*appContainer, error) {
+	repoRoot, configPath, err := resolveConfigPath(flags)
 	if err != nil {
 		return nil, err
 	}
 
+	cfg, err := loadConfig(flags, configPath)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Runtime.DryRun {
+		flags.dryRun = true
+	}
+	if err := cfg.LoadWorkspaces(repoRoot); err != nil {
+		return nil, err
+	}
+	if flags.offline {
+		cfg.Runtime.Offline = true
+		for _, ws := range cfg.Workspaces {
+			ws.Resolved.Runtime.Offline = true
+		}
+	}
+	if adjust != nil {
+		adjust(cfg)
+		for _, ws := range cfg.Workspaces {
+			adjust(ws.Resolved)
+		}
+	}
+
 	statePath := cfg.State.DBPath
-	if !filepath.IsAbs(statePath) {
+	if !state.IsURL(statePath) && !filepath.IsAbs(statePath) {
 		statePath = filepath.Join(repoRoot, statePath)
 	}
 
-	store, err := state.New(statePath)
+	store, err := state.Open(statePath, cfg.State.AuthToken)
 	if err != nil {
 		return nil, err
 	}
+	if err := store.SetEncryptionKey(cfg.State.EncryptionKey); err != nil {
+		store.Close()
+		return nil, err
+	}
 
 	gitClient := gitutil.NewHelper(repoRoot)
+	gitClient.SetCommitOptions(gitutil.CommitOptions{
+		Name:       cfg.Git.CommitterName,
+		Email:      cfg.Git.CommitterEmail,
+		Sign:       cfg.Git.GPGSign,
+		SigningKey: cfg.Git.SigningKey,
+		NoVerify:   cfg.Git.NoVerify,
+		DirtyTree:  cfg.Git.DirtyTreePolicy,
+	})
+	if cfg.Git.TargetBranch != "" {
+		if err := gitClient.UseTargetBranch(cfg.Git.TargetBranch); err != nil {
+			store.Close()
+			return nil, err
+		}
+	}
 	docUpdater := doc.NewMarkdownUpdater()
 	llmClient, err := llm.NewClient(cfg)
 	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	var planner llm.Client
+	if cfg.LLM.PlanTargets {
+		planner, err = llm.NewClient(cfg.PlannerConfig())
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+	}
+	var embedder llm.Embedder
+	embeddings := cfg.ResolvedEmbeddings()
+	if cfg.Embeddings.Enabled && (!cfg.Runtime.Offline || llm.IsLocalProvider(embeddings.Provider, embeddings.BaseURL)) {
+		embedder, err = llm.NewEmbedder(cfg)
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+	}
+
+	var shared *state.SharedCache
+	cacheDir, err := cfg.Cache.ResolvedDir()
+	if err != nil {
+		store.Close()
 		return nil, err
 	}
+	if cacheDir != "" {
+		if !filepath.IsAbs(cacheDir) {
+			cacheDir = filepath.Join(repoRoot, cacheDir)
+		}
+		shared, err = state.OpenSharedCache(cacheDir)
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+	}
+
+	var reporter progress.Reporter = progress.Nop{}
+	if !flags.quiet {
+		reporter = progress.NewBar(os.Stderr)
+	}
 
 	updater := orchestrator.NewUpdater(orchestrator.Dependencies{
-		Config:     cfg,
-		Git:        gitClient,
-		State:      store,
-		DocUpdater: docUpdater,
-		LLM:        llmClient,
+		Config:      cfg,
+		Git:         gitClient,
+		State:       store,
+		DocUpdater:  docUpdater,
+		LLM:         llmClient,
+		Planner:     planner,
+		Embedder:    embedder,
+		NewLLM:      llm.NewClient,
+		Progress:    reporter,
+		Tracer:      tracing.New(cfg.Tracing),
+		Notifier:    notify.New(cfg.Notifications),
+		Mailer:      notify.NewMailer(cfg.Notifications.Email),
+		SharedCache: shared,
 	})
 
-	return &appContainer{Updater: updater, State: store, Git: gitClient, RepoRoot: repoRoot}, nil
+	// Without a run deadline a healthy run may outlast any TTL, so the lock
+	// is never taken over.
+	lockTTL := time.Duration(cfg.Runtime.LockTTLMinutes) * time.Minute
+	if cfg.Runtime.RunDeadline == 0 {
+		lockTTL = 0
+	}
+
+	return &appContainer{Updater: updater, State: store, Git: gitClient, RepoRoot: repoRoot, LockTTL: lockTTL, Config: cfg, SharedCache: shared}, nil
 }
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+func newRunsCmd(flags *rootFlags) *cobra.Command {
+	var asJSON bool
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "runs [run-id]",
+		Short: "List historical runs or inspect one run's events",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			if len(args) == 1 {
+				return showRun(app.State, args[0], asJSON)
+			}
+
+			runs, err := app.State.ListRuns(limit)
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				payload := make([]map[string]any, 0, len(runs))
+				for _, run := range runs {
+					payload = append(payload, runPayload(run))
+				}
+				return printJSON(payload)
+			}
+
+			for _, run := range runs {
+				fmt.Printf("%s %s trigger=%s dry_run=%t processed=%d success=%d failed=%d skipped=%d started=%s duration=%s\n",
+					run.ID, run.Status, run.Trigger, run.DryRun, run.Processed, run.Success, run.Failed, run.Skipped,
+					run.StartedAt.Format("2006-01-02 15:04:05"), runDuration(run))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Output runs as JSON")
+	cmd.Flags().IntVar(&limit, "limit", 25, "Maximum number of runs to list")
+	return cmd
+}
+
+func showRun(store state.Backend, runID string, asJSON bool) error {
+	run, ok, err := store.GetRun(runID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("run %s not found", runID)
+	}
+
+	events, err := store.ListRunEvents(runID)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		payload := runPayload(run)
+		payload["events"] = eventsPayload(events)
+		return printJSON(payload)
+	}
+
+	fmt.Printf("run %s (%s)\n", run.ID, run.Status)
+	fmt.Printf("trigger=%s dry_run=%t started=%s duration=%s\n", run.Trigger, run.DryRun, run.StartedAt.Format("2006-01-02 15:04:05"), runDuration(run))
+	fmt.Printf("processed=%d success=%d failed=%d skipped=%d\n", run.Processed, run.Success, run.Failed, run.Skipped)
+	for _, event := range events {
+		printEvent(event)
+	}
+	return nil
+}
+
+func runPayload(run state.RunRecord) map[string]any {
+	payload := map[string]any{
+		"id":         run.ID,
+		"status":     run.Status,
+		"trigger":    run.Trigger,
+		"dry_run":    run.DryRun,
+		"started_at": run.StartedAt.UTC().Format(time.RFC3339),
+		"processed":  run.Processed,
+		"success":    run.Success,
+		"failed":     run.Failed,
+		"skipped":    run.Skipped,
+	}
+	if run.FinishedAt.Valid {
+		payload["finished_at"] = run.FinishedAt.Time.UTC().Format(time.RFC3339)
+	}
+	return payload
+}
+
+func eventsPayload(events []state.RunEvent) []map[string]any {
+	out := make([]map[string]any, 0, len(events))
+	for _, event := range events {
+		entry := map[string]any{
+			"id":         event.ID,
+			"run_id":     event.RunID,
+			"level":      event.Level,
+			"component":  event.Component,
+			"message":    event.Message,
+			"created_at": event.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if event.CommitHash != "" {
+			entry["commit_hash"] = event.CommitHash
+		}
+		switch {
+		case event.Metadata == state.EncryptedPlaceholder:
+			entry["metadata"] = event.Metadata
+		case event.Metadata != "":
+			entry["metadata"] = json.RawMessage(event.Metadata)
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func printEvent(event state.RunEvent) {
+	line := fmt.Sprintf("%s [%s] %s: %s", event.CreatedAt.Format("2006-01-02 15:04:05"), event.Level, event.Component, event.Message)
+	if event.CommitHash != "" {
+		line += " commit=" + event.CommitHash
+	}
+	if event.Metadata != "" {
+		line += " " + event.Metadata
+	}
+	fmt.Println(line)
+}
+
+func runDuration(run state.RunRecord) string {
+	if !run.FinishedAt.Valid {
+		return "-"
+	}
+	return run.FinishedAt.Time.Sub(run.StartedAt).Round(time.Second).String()
+}
+
+func printJSON(payload any) error {
+	return writeJSON(os.Stdout, payload)
+}
+
+func writeJSON(w io.Writer, payload any) error {
+	out, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
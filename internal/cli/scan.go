@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+func newScanCmd(flags *rootFlags) *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "scan",
+		Short: "Index the headings of every doc file into the doc inventory",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			entries, err := app.Updater.Scan()
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				return printJSON(inventoryPayload(entries))
+			}
+			files := map[string]bool{}
+			for _, entry := range entries {
+				files[entry.DocFile] = true
+				last := "uncommitted"
+				if entry.LastCommit != "" {
+					last = shortCommit(entry.LastCommit)
+				}
+				fmt.Printf("%s#%s  %s  words=%d  last=%s\n", entry.DocFile, entry.Anchor, entry.Path, entry.Words, last)
+			}
+			fmt.Printf("scan: %d sections in %d doc files\n", len(entries), len(files))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Output the doc inventory as JSON")
+	return cmd
+}
+
+func inventoryPayload(entries []state.DocSection) map[string]any {
+	sections := make([]map[string]any, 0, len(entries))
+	for _, entry := range entries {
+		item := map[string]any{
+			"doc_file":     entry.DocFile,
+			"section":      entry.Section,
+			"section_path": entry.Path,
+			"level":        entry.Level,
+			"line":         entry.Line + 1,
+			"anchor":       entry.Anchor,
+			"word_count":   entry.Words,
+		}
+		if entry.LastCommit != "" {
+			item["last_modified_commit"] = entry.LastCommit
+			item["last_modified_at"] = entry.LastModifiedAt.UTC().Format(time.RFC3339)
+		}
+		sections = append(sections, item)
+	}
+	return map[string]any{
+		"generated_at": time.Now().UTC().Format(time.RFC3339),
+		"sections":     sections,
+	}
+}
@@ -0,0 +1,269 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+func newStateCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Maintain the local state database",
+	}
+	cmd.AddCommand(newStateScrubCmd(flags))
+	cmd.AddCommand(newStatePruneCmd(flags))
+	cmd.AddCommand(newStateVacuumCmd(flags))
+	cmd.AddCommand(newStateExportCmd(flags))
+	cmd.AddCommand(newStateImportCmd(flags))
+	return cmd
+}
+
+func newStateExportCmd(flags *rootFlags) *cobra.Command {
+	var format string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write the processed-commit ledger and mappings to a portable JSON or SQLite snapshot",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "json" && format != "sqlite" {
+				return fmt.Errorf("unsupported snapshot format %q (use json or sqlite)", format)
+			}
+			if format == "sqlite" && output == "" {
+				return fmt.Errorf("--output is required for sqlite snapshots")
+			}
+
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			snapshot, err := app.State.Export()
+			if err != nil {
+				return err
+			}
+
+			if format == "sqlite" {
+				if _, err := os.Stat(output); err == nil {
+					return fmt.Errorf("%s already exists", output)
+				}
+				target, err := state.New(output)
+				if err != nil {
+					return err
+				}
+				defer target.Close()
+				if _, err := target.Import(snapshot, false); err != nil {
+					return err
+				}
+			} else {
+				w, closeWriter, err := openReportWriter(output)
+				if err != nil {
+					return err
+				}
+				defer closeWriter()
+				if err := writeJSON(w, snapshot); err != nil {
+					return err
+				}
+			}
+
+			if output != "" && output != "-" {
+				fmt.Printf("exported commits=%d mappings=%d reverts=%d to %s\n", len(snapshot.ProcessedCommits), len(snapshot.Mappings), len(snapshot.Reverts), output)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "json", "Snapshot format: json or sqlite")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the snapshot to a file (default stdout; required for sqlite)")
+	return cmd
+}
+
+func newStateImportCmd(flags *rootFlags) *cobra.Command {
+	var overwrite bool
+
+	cmd := &cobra.Command{
+		Use:   "import <snapshot>",
+		Short: "Merge a snapshot from state export into the local state database",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snapshot, err := readSnapshot(args[0])
+			if err != nil {
+				return err
+			}
+
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			lock, err := app.acquireLock()
+			if err != nil {
+				return err
+			}
+			defer lock.Release()
+
+			result, err := app.State.Import(snapshot, overwrite)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("imported commits=%d mappings=%d reverts=%d\n", result.Commits, result.Mappings, result.Reverts)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&overwrite, "overwrite", false, "Replace commits already recorded locally instead of keeping them")
+	return cmd
+}
+
+// readSnapshot loads a JSON snapshot, or exports one from a SQLite snapshot
+// or another state database.
+func readSnapshot(path string) (state.Snapshot, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return state.Snapshot{}, err
+	}
+
+	if bytes.HasPrefix(raw, []byte("SQLite format 3\x00")) {
+		source, err := state.New(path)
+		if err != nil {
+			return state.Snapshot{}, err
+		}
+		defer source.Close()
+		return source.Export()
+	}
+
+	var snapshot state.Snapshot
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return state.Snapshot{}, fmt.Errorf("parse snapshot %s: %w", path, err)
+	}
+	return snapshot, nil
+}
+
+func newStatePruneCmd(flags *rootFlags) *cobra.Command {
+	var olderThan string
+	var vacuum bool
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete run events, runs and cached LLM responses older than a cutoff",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(olderThan) == "" {
+				return fmt.Errorf("--older-than is required (e.g. 30d)")
+			}
+			cutoff, err := parseSince(olderThan, time.Now())
+			if err != nil {
+				return err
+			}
+
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			result, err := app.State.Prune(cutoff)
+			if err != nil {
+				return err
+			}
+			if vacuum {
+				if err := app.State.Vacuum(); err != nil {
+					return err
+				}
+			}
+
+			fmt.Printf("pruned run_events=%d runs=%d llm_cache=%d prompt_log=%d\n", result.RunEvents, result.Runs, result.LLMCache, result.PromptLog)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Delete entries older than a duration (30d, 12h) or date")
+	cmd.Flags().BoolVar(&vacuum, "vacuum", false, "Vacuum the database afterwards to reclaim disk space")
+	return cmd
+}
+
+func newStateVacuumCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "vacuum",
+		Short: "Rebuild the state database to reclaim space left by deleted rows",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+			if err := app.State.Vacuum(); err != nil {
+				return err
+			}
+			fmt.Println("state database vacuumed")
+			return nil
+		},
+	}
+}
+
+func newStateScrubCmd(flags *rootFlags) *cobra.Command {
+	var events bool
+
+	cmd := &cobra.Command{
+		Use:   "scrub",
+		Short: "Purge cached LLM responses and logged prompt text (and optionally run event metadata) from the state database",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			cached, err := app.State.PurgeLLMCache()
+			if err != nil {
+				return err
+			}
+
+			prompts, err := app.State.PurgePromptLog()
+			if err != nil {
+				return err
+			}
+
+			var scrubbed int64
+			if events {
+				scrubbed, err = app.State.ScrubRunEventMetadata()
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := app.State.Vacuum(); err != nil {
+				return err
+			}
+
+			fmt.Printf("scrubbed llm_cache=%d prompt_log=%d run_event_metadata=%d\n", cached, prompts, scrubbed)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&events, "events", false, "Also clear run event metadata, which can include error text and diff excerpts")
+	return cmd
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGT"[exp])
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/config"
+	"github.com/kowshik24/git-doc/internal/orchestrator"
+)
+
+func newSuggestMappingsCmd(flags *rootFlags) *cobra.Command {
+	var asJSON bool
+	var apply bool
+	var commits int
+	var minCoChanges int
+	var minConfidence float64
+
+	cmd := &cobra.Command{
+		Use:   "suggest-mappings",
+		Short: "Propose [[mappings]] from the code paths that historically change together with each doc",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if minConfidence < 0 || minConfidence > 1 {
+				return fmt.Errorf("--min-confidence must be between 0 and 1, got %g", minConfidence)
+			}
+
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			suggestions, err := app.Updater.SuggestMappings(commits, minCoChanges, minConfidence)
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				if err := printJSON(suggestionsPayload(suggestions)); err != nil {
+					return err
+				}
+			} else {
+				for _, s := range suggestions {
+					section := s.Section
+					if s.NewSection {
+						section += " (new)"
+					}
+					fmt.Printf("%s -> %s#%s  %d/%d commits (%.0f%%)\n", s.CodePattern, s.DocFile, section, s.CoChanges, s.Commits, s.Confidence()*100)
+				}
+				fmt.Printf("suggest-mappings: %d suggestions\n", len(suggestions))
+			}
+
+			if !apply || len(suggestions) == 0 {
+				return nil
+			}
+			_, configPath, err := resolveConfigPath(flags)
+			if err != nil {
+				return err
+			}
+			mappings := make([]config.Mapping, 0, len(suggestions))
+			for _, s := range suggestions {
+				mappings = append(mappings, s.Mapping)
+			}
+			if err := config.AppendMappings(configPath, mappings); err != nil {
+				return err
+			}
+			if !asJSON {
+				fmt.Printf("%d mappings added to %s\n", len(mappings), configPath)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Output the suggestions as JSON")
+	cmd.Flags().BoolVar(&apply, "apply", false, "Append the suggestions to the config file as [[mappings]]")
+	cmd.Flags().IntVar(&commits, "commits", 1000, "Number of recent commits to analyze (0 for the whole history)")
+	cmd.Flags().IntVar(&minCoChanges, "min-commits", 2, "Minimum commits changing both the code path and the doc")
+	cmd.Flags().Float64Var(&minConfidence, "min-confidence", 0.1, "Minimum share of the code path's commits that also changed the doc")
+	return cmd
+}
+
+func suggestionsPayload(suggestions []orchestrator.MappingSuggestion) map[string]any {
+	items := make([]map[string]any, 0, len(suggestions))
+	for _, s := range suggestions {
+		items = append(items, map[string]any{
+			"code_pattern": s.CodePattern,
+			"doc_file":     s.DocFile,
+			"section":      s.Section,
+			"new_section":  s.NewSection,
+			"co_changes":   s.CoChanges,
+			"commits":      s.Commits,
+			"confidence":   s.Confidence(),
+		})
+	}
+	return map[string]any{
+		"generated_at": time.Now().UTC().Format(time.RFC3339),
+		"suggestions":  items,
+	}
+}
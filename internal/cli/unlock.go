@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/gitutil"
+	"github.com/kowshik24/git-doc/internal/runlock"
+)
+
+func newUnlockCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "unlock",
+		Short: "Clear the run lock; --force clears it even while a run holds it",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repoRoot, err := gitutil.GetRepoRoot()
+			if err != nil {
+				return err
+			}
+
+			holder := describeHolder(repoRoot)
+			held, err := runlock.Unlock(repoRoot, force)
+			if err != nil {
+				if runlock.IsAlreadyRunningError(err) {
+					return fmt.Errorf("%w; %s (use --force to clear it anyway)", err, holder)
+				}
+				return err
+			}
+			if !held {
+				fmt.Println("run lock is not held")
+				return nil
+			}
+			fmt.Printf("run lock cleared; it was %s\n", holder)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Clear the lock even while a run holds it")
+	return cmd
+}
+
+func describeHolder(repoRoot string) string {
+	holder, err := runlock.ReadHolder(repoRoot)
+	if err != nil || holder.PID == 0 {
+		return "holder unknown"
+	}
+	state := "running"
+	if !holder.Alive {
+		state = "not running"
+	}
+	return fmt.Sprintf("held by pid %d (%s) since %s", holder.PID, state, holder.CreatedAt.Local().Format(time.RFC3339))
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/kowshik24/git-doc/internal/config"
@@ -36,6 +37,99 @@ func TestUpdateFromHookNoOpWhenLockHeld(t *testing.T) {
 	if err := cmd.Execute(); err != nil {
 		t.Fatalf("expected update --from-hook to no-op successfully when locked, got: %v", err)
 	}
+	if pending, err := runlock.HasPending(repo); err != nil || !pending {
+		t.Fatalf("expected the hook trigger to be queued, got %v %v", pending, err)
+	}
+}
+
+func TestUpdateFromHookDefersToSchedule(t *testing.T) {
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+	writeDefaultConfig(t, repo)
+	configPath := filepath.Join(repo, ".git-doc", "config.toml")
+	content := strings.Replace(config.DefaultToml(), `# schedule = "0 18 * * *"`, `schedule = "0 18 * * *"`, 1)
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	originalWD, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(originalWD)
+	if err := os.Chdir(repo); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := runlock.Acquire(repo)
+	if err != nil {
+		t.Fatalf("failed to acquire lock for test setup: %v", err)
+	}
+	defer lock.Release()
+
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"update", "--from-hook"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected update --from-hook to defer to the schedule, got: %v", err)
+	}
+	if pending, err := runlock.HasPending(repo); err != nil || pending {
+		t.Fatalf("expected no queued trigger while a schedule is set, got %v %v", pending, err)
+	}
+}
+
+func TestRunQueuedRepeatsForTriggersQueuedDuringRun(t *testing.T) {
+	repo := t.TempDir()
+	app := &appContainer{RepoRoot: repo}
+	if err := runlock.MarkPending(repo); err != nil {
+		t.Fatal(err)
+	}
+
+	runs := 0
+	err := runQueued(app, func() error {
+		runs++
+		if runs == 1 {
+			return runlock.MarkPending(repo)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("run queued failed: %v", err)
+	}
+	if runs != 2 {
+		t.Fatalf("expected one extra run for the trigger queued during the first, got %d runs", runs)
+	}
+	if pending, err := runlock.HasPending(repo); err != nil || pending {
+		t.Fatalf("expected the queue to be drained, got %v %v", pending, err)
+	}
+}
+
+func TestReconcileAmendNoOpWhenLockHeld(t *testing.T) {
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+	writeDefaultConfig(t, repo)
+
+	originalWD, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(originalWD)
+
+	if err := os.Chdir(repo); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := runlock.Acquire(repo)
+	if err != nil {
+		t.Fatalf("failed to acquire lock for test setup: %v", err)
+	}
+	defer lock.Release()
+
+	cmd := NewRootCmd()
+	cmd.SetIn(strings.NewReader("aaa111 bbb222\n"))
+	cmd.SetArgs([]string{"reconcile", "amend"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected reconcile amend to no-op successfully when locked, got: %v", err)
+	}
 }
 
 func initGitRepo(t *testing.T, repo string) {
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/runlock"
+	"github.com/kowshik24/git-doc/internal/state"
+	"github.com/kowshik24/git-doc/internal/tui"
+)
+
+// runStatusWatch shows the live dashboard. It only reads state, so it can
+// watch a run started by another process, such as a long backfill.
+func runStatusWatch(cmd *cobra.Command, app *appContainer, flags *rootFlags, limit int, interval time.Duration) error {
+	dashboard := &tui.Dashboard{
+		Interval: interval,
+		Load: func() (tui.Snapshot, error) {
+			var snapshot tui.Snapshot
+			var err error
+			if snapshot.Counts, err = app.State.GetStatusCounts(); err != nil {
+				return snapshot, err
+			}
+			if snapshot.Recent, err = app.State.ListRecent(limit); err != nil {
+				return snapshot, err
+			}
+			runs, err := app.State.ListRuns(1)
+			if err != nil {
+				return snapshot, err
+			}
+			if len(runs) > 0 {
+				snapshot.Run = &runs[0]
+			}
+			if snapshot.Events, err = app.State.QueryRunEvents(state.RunEventFilter{Limit: 8}); err != nil {
+				return snapshot, err
+			}
+			snapshot.Queued, err = runlock.HasPending(app.RepoRoot)
+			return snapshot, err
+		},
+		Inspect: func(hash string) ([]string, error) {
+			return inspectCommit(app, hash)
+		},
+		Retry: func(hash string) (string, error) {
+			return retryInBackground(app, flags, hash)
+		},
+	}
+	return dashboard.Run(cmd.Context(), os.Stdin, os.Stdout)
+}
+
+func inspectCommit(app *appContainer, hash string) ([]string, error) {
+	row, ok, err := app.State.GetProcessedCommit(hash)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []string{"commit " + hash + " has no state"}, nil
+	}
+
+	lines := []string{fmt.Sprintf("commit %s  %s  %s", row.CommitHash, row.Status, row.ProcessedAt.Local().Format("2006-01-02 15:04:05"))}
+	if row.Error.Valid {
+		line := "error: " + row.Error.String
+		if row.ErrorClass.Valid {
+			line += " (" + row.ErrorClass.String + ")"
+		}
+		lines = append(lines, line)
+	}
+	if row.DocCommit.Valid {
+		lines = append(lines, "doc commit: "+row.DocCommit.String)
+	}
+
+	events, err := app.State.QueryRunEvents(state.RunEventFilter{CommitHash: hash, Limit: 10})
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		lines = append(lines, fmt.Sprintf("%s %-5s %s: %s", event.CreatedAt.Local().Format("15:04:05"), event.Level, event.Component, event.Message))
+	}
+	return lines, nil
+}
+
+// retryInBackground retries a failed commit without tying up the dashboard.
+// While another run holds the lock the commit is reset to pending and a
+// trigger is queued, so that run picks it up when it finishes its commits;
+// otherwise a detached git-doc retry is started.
+func retryInBackground(app *appContainer, flags *rootFlags, hash string) (string, error) {
+	row, ok, err := app.State.GetProcessedCommit(hash)
+	if err != nil {
+		return "", err
+	}
+	if !ok || row.Status != "failed" {
+		return "only failed commits can be retried", nil
+	}
+
+	lock, err := app.acquireLock()
+	if runlock.IsAlreadyRunningError(err) {
+		if err := app.State.MarkCommitProcessed(hash, "pending", "", "", nil); err != nil {
+			return "", err
+		}
+		if err := runlock.MarkPending(app.RepoRoot); err != nil {
+			return "", err
+		}
+		return "queued " + shortCommit(hash) + " for the running update", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	lock.Release()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	args := []string{"retry", "--commit", hash, "--quiet", "--config", flags.configPath}
+	if flags.profile != "" {
+		args = append(args, "--profile", flags.profile)
+	}
+	retry := exec.Command(exe, args...)
+	retry.Dir = app.RepoRoot
+	if err := retry.Start(); err != nil {
+		return "", err
+	}
+	go retry.Wait()
+	return "retrying " + shortCommit(hash) + " in the background", nil
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+func newWhyCmd(flags *rootFlags) *cobra.Command {
+	var section string
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "why <doc-file>",
+		Short: "List the code commits, runs and LLM models that contributed to a doc file or section",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app, err := buildApp(flags)
+			if err != nil {
+				return err
+			}
+			defer app.Close()
+
+			docFile := filepath.Clean(args[0])
+			if filepath.IsAbs(docFile) {
+				if rel, err := filepath.Rel(app.RepoRoot, docFile); err == nil {
+					docFile = rel
+				}
+			}
+			docFile = filepath.ToSlash(docFile)
+			if section, err = inventorySectionTitle(app.State, docFile, section); err != nil {
+				return err
+			}
+
+			contributions, err := app.State.ListContributions(docFile, section)
+			if err != nil {
+				return err
+			}
+			subjects := map[string]string{}
+			for _, c := range contributions {
+				if info, err := app.Git.GetCommitInfo(c.CommitHash); err == nil {
+					subjects[c.CommitHash] = info.Subject
+				}
+			}
+
+			if asJSON {
+				return printJSON(whyPayload(docFile, section, contributions, subjects))
+			}
+
+			target := docFile
+			if section != "" {
+				target += "#" + section
+			}
+			if len(contributions) == 0 {
+				fmt.Printf("no code commits are mapped to %s\n", target)
+				return nil
+			}
+			for _, c := range contributions {
+				fmt.Println(strings.TrimSpace(fmt.Sprintf("%s %s %s  %s", shortCommit(c.CommitHash), c.Status, c.ProcessedAt.Format("2006-01-02 15:04:05"), subjects[c.CommitHash])))
+				fmt.Printf("    section=%s", c.Section)
+				if c.DocCommit != "" {
+					fmt.Printf(" doc_commit=%s", shortCommit(c.DocCommit))
+				}
+				if c.RevertedBy != "" {
+					fmt.Printf(" reverted=%s", shortCommit(c.RevertedBy))
+				}
+				fmt.Printf(" runs=%s models=%s\n", listOrDash(c.Runs), listOrDash(c.Models))
+			}
+			fmt.Printf("why: %d code commits contributed to %s\n", len(contributions), target)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&section, "section", "", "Only list commits mapped to this section (title, #anchor or path like \"Usage > Flags\")")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Output the provenance as JSON")
+	return cmd
+}
+
+// inventorySectionTitle resolves an #anchor or section path of docFile to
+// the heading title mappings are recorded under, using the doc inventory
+// written by git-doc scan. Anything else is taken as a title.
+func inventorySectionTitle(store state.Backend, docFile, section string) (string, error) {
+	section = strings.TrimSpace(section)
+	if section == "" {
+		return "", nil
+	}
+	inventory, err := store.GetDocInventory()
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range inventory {
+		if entry.DocFile != docFile {
+			continue
+		}
+		if "#"+entry.Anchor == section || strings.EqualFold(entry.Path, section) {
+			return entry.Section, nil
+		}
+	}
+	return strings.TrimPrefix(section, "#"), nil
+}
+
+func whyPayload(docFile, section string, contributions []state.Contribution, subjects map[string]string) map[string]any {
+	items := make([]map[string]any, 0, len(contributions))
+	for _, c := range contributions {
+		item := map[string]any{
+			"commit_hash":  c.CommitHash,
+			"subject":      subjects[c.CommitHash],
+			"status":       c.Status,
+			"processed_at": c.ProcessedAt.UTC().Format(time.RFC3339),
+			"doc_file":     c.DocFile,
+			"section":      c.Section,
+			"runs":         nonNil(c.Runs),
+			"models":       nonNil(c.Models),
+		}
+		if c.DocCommit != "" {
+			item["doc_commit_hash"] = c.DocCommit
+		}
+		if c.RevertedBy != "" {
+			item["reverted_by"] = c.RevertedBy
+		}
+		items = append(items, item)
+	}
+	payload := map[string]any{
+		"generated_at":  time.Now().UTC().Format(time.RFC3339),
+		"doc_file":      docFile,
+		"contributions": items,
+	}
+	if section != "" {
+		payload["section"] = section
+	}
+	return payload
+}
+
+func listOrDash(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,58 @@
+package commitclass
+
+import (
+	"regexp"
+	"strings"
+)
+
+type Classification struct {
+	Type         string
+	Scope        string
+	Breaking     bool
+	Description  string
+	Conventional bool
+}
+
+var headerPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9_-]*)(?:\(([^()]*)\))?(!)?:\s*(.*)$`)
+
+func Parse(message string) Classification {
+	trimmed := strings.TrimSpace(message)
+	header, body, _ := strings.Cut(trimmed, "\n")
+	header = strings.TrimSpace(header)
+
+	match := headerPattern.FindStringSubmatch(header)
+	if match == nil {
+		return Classification{Description: header, Breaking: hasBreakingFooter(body)}
+	}
+
+	return Classification{
+		Type:         strings.ToLower(match[1]),
+		Scope:        strings.ToLower(strings.TrimSpace(match[2])),
+		Breaking:     match[3] == "!" || hasBreakingFooter(body),
+		Description:  strings.TrimSpace(match[4]),
+		Conventional: true,
+	}
+}
+
+func (c Classification) Matches(commitType, scope string) bool {
+	commitType = strings.ToLower(strings.TrimSpace(commitType))
+	scope = strings.ToLower(strings.TrimSpace(scope))
+
+	if commitType != "" && commitType != c.Type {
+		return false
+	}
+	if scope != "" && scope != c.Scope {
+		return false
+	}
+	return true
+}
+
+func hasBreakingFooter(body string) bool {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package commitclass
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    Classification
+	}{
+		{
+			name:    "type and scope",
+			message: "feat(API): add pagination",
+			want:    Classification{Type: "feat", Scope: "api", Description: "add pagination", Conventional: true},
+		},
+		{
+			name:    "breaking marker",
+			message: "refactor!: drop v1 endpoints",
+			want:    Classification{Type: "refactor", Breaking: true, Description: "drop v1 endpoints", Conventional: true},
+		},
+		{
+			name:    "breaking footer",
+			message: "fix(cli): rename flag\n\nBREAKING CHANGE: --out is now --output",
+			want:    Classification{Type: "fix", Scope: "cli", Breaking: true, Description: "rename flag", Conventional: true},
+		},
+		{
+			name:    "not conventional",
+			message: "Update readme",
+			want:    Classification{Description: "Update readme"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Parse(tc.message); got != tc.want {
+				t.Fatalf("Parse(%q) = %+v, want %+v", tc.message, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	c := Parse("feat(api): add endpoint")
+
+	if !c.Matches("feat", "api") || !c.Matches("FEAT", "") || !c.Matches("", "api") {
+		t.Fatalf("expected classification to match type/scope filters")
+	}
+	if c.Matches("fix", "") || c.Matches("feat", "cli") {
+		t.Fatalf("expected classification not to match different type/scope")
+	}
+}
@@ -4,61 +4,502 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"text/template"
 
 	"github.com/BurntSushi/toml"
+
+	"github.com/kowshik24/git-doc/internal/schedule"
 )
 
+// SchemaVersion is the config schema this build understands. Configs declare
+// the version they were written for with the top-level version key.
+const SchemaVersion = 1
+
 type Config struct {
+	Version  int            `toml:"version"`
 	LLM      LLMConfig      `toml:"llm"`
 	DocFiles []string       `toml:"doc_files"`
 	Mappings []Mapping      `toml:"mappings"`
 	Git      GitConfig      `toml:"git"`
 	State    StateConfig    `toml:"state"`
+	Cache    CacheConfig    `toml:"cache"`
 	Runtime  RuntimeOptions `toml:"runtime"`
+	Prompts  PromptsConfig  `toml:"prompts"`
+	Diff     DiffConfig     `toml:"diff"`
+
+	Embeddings    EmbeddingsConfig    `toml:"embeddings"`
+	Changelog     ChangelogConfig     `toml:"changelog"`
+	StatusBlock   StatusBlockConfig   `toml:"status_block"`
+	Translations  TranslationsConfig  `toml:"translations"`
+	Ignore        IgnoreConfig        `toml:"ignore"`
+	Policy        PolicyConfig        `toml:"policy"`
+	Links         LinksConfig         `toml:"links"`
+	HumanEdits    HumanEditsConfig    `toml:"human_edits"`
+	Owners        OwnersConfig        `toml:"owners"`
+	Submodules    SubmodulesConfig    `toml:"submodules"`
+	TOC           TOCConfig           `toml:"toc"`
+	Redaction     RedactionConfig     `toml:"redaction"`
+	Tracing       TracingConfig       `toml:"tracing"`
+	Notifications NotificationsConfig `toml:"notifications"`
+
+	Workspaces []Workspace `toml:"workspaces"`
+	// Profiles are partial configs layered over the rest of the file when
+	// selected with LoadProfile.
+	Profiles map[string]toml.Primitive `toml:"profiles"`
+
+	ownMappings []Mapping
 }
 
 type LLMConfig struct {
 	Provider          string   `toml:"provider"`
 	APIKey            string   `toml:"api_key"`
 	Model             string   `toml:"model"`
+	BaseURL           string   `toml:"base_url"`
 	Timeout           int      `toml:"timeout"`
 	MaxRetries        int      `toml:"max_retries"`
 	FailoverEnabled   bool     `toml:"failover_enabled"`
 	FallbackProviders []string `toml:"fallback_providers"`
+	RequestsPerMinute int      `toml:"requests_per_minute"`
+	TokensPerMinute   int      `toml:"tokens_per_minute"`
+	// CircuitBreakerThreshold is the number of consecutive failures that
+	// opens a provider's breaker; a negative value disables it.
+	CircuitBreakerThreshold int              `toml:"circuit_breaker_threshold"`
+	CircuitBreakerCooldown  int              `toml:"circuit_breaker_cooldown"`
+	SystemPrompt            string           `toml:"system_prompt"`
+	Temperature             *float64         `toml:"temperature"`
+	TopP                    *float64         `toml:"top_p"`
+	MaxTokens               int              `toml:"max_tokens"`
+	StructuredOutput        bool             `toml:"structured_output"`
+	MinConfidence           float64          `toml:"min_confidence"`
+	Providers               []ProviderConfig `toml:"providers"`
+	// PlanTargets asks the planner which doc file and section a commit
+	// affects when no mapping matches, instead of using the first doc file.
+	PlanTargets bool `toml:"plan_targets"`
+	// Planner overrides the provider used for planning calls; unset fields
+	// inherit from [llm] as [[llm.providers]] blocks do.
+	Planner ProviderConfig `toml:"planner"`
+	Ollama  OllamaConfig   `toml:"ollama"`
+	// Ensemble lists the providers used by mappings with ensemble = true.
+	Ensemble EnsembleConfig `toml:"ensemble"`
+	// ReviewPasses sends each generated section back with the commit and
+	// diff this many times to check its claims and fix hallucinations.
+	ReviewPasses int `toml:"review_passes"`
+}
+
+// EnsembleConfig makes each candidate write the section and the judge pick
+// or merge the best one. Candidates inherit from [llm] as [[llm.providers]]
+// blocks do; an unset judge is the primary provider.
+type EnsembleConfig struct {
+	Candidates []ProviderConfig `toml:"candidates"`
+	Judge      ProviderConfig   `toml:"judge"`
+}
+
+// OllamaConfig tunes the ollama provider. Chat uses /api/chat instead of
+// /api/generate, NumCtx sets the context window (0 keeps the model default),
+// and Pull decides what happens when the model is not installed: "prompt"
+// asks before pulling, "always" pulls, "never" fails.
+type OllamaConfig struct {
+	Chat   bool   `toml:"chat"`
+	NumCtx int    `toml:"num_ctx"`
+	Pull   string `toml:"pull"`
+}
+
+type ProviderConfig struct {
+	Provider          string `toml:"provider"`
+	Model             string `toml:"model"`
+	APIKey            string `toml:"api_key"`
+	BaseURL           string `toml:"base_url"`
+	Timeout           int    `toml:"timeout"`
+	RequestsPerMinute int    `toml:"requests_per_minute"`
+	TokensPerMinute   int    `toml:"tokens_per_minute"`
 }
 
 type Mapping struct {
-	CodePattern string `toml:"code_pattern"`
-	DocFile     string `toml:"doc_file"`
-	Section     string `toml:"section"`
+	CodePattern    string `toml:"code_pattern"`
+	Type           string `toml:"type"`
+	Scope          string `toml:"scope"`
+	DocFile        string `toml:"doc_file"`
+	Section        string `toml:"section"`
+	PromptTemplate string `toml:"prompt_template"`
+	// CreateDoc creates a missing doc_file from DocTemplate instead of
+	// failing, for mappings whose doc_file names a page per package.
+	CreateDoc   bool   `toml:"create_doc"`
+	DocTemplate string `toml:"doc_template"`
+	// Render switches the section from prose to a generated artifact; only
+	// RenderMermaidArch is supported.
+	Render string `toml:"render"`
+	// SectionLevel, Insert and SectionTemplate shape a section the mapping
+	// creates because the doc lacks it: its heading level (default 2), where
+	// it goes ("end", "top", "alphabetical" or "after:<heading>") and a
+	// template around the generated body with {section} and {content}.
+	SectionLevel    int    `toml:"section_level"`
+	Insert          string `toml:"insert"`
+	SectionTemplate string `toml:"section_template"`
+	// Ensemble generates the section with every [[llm.ensemble.candidates]]
+	// provider and lets the judge choose, for high-visibility docs.
+	Ensemble bool `toml:"ensemble"`
+	// MinLines and MinFiles skip commits whose files matching code_pattern
+	// changed fewer lines, or are fewer files, so small tweaks do not churn
+	// the section; 0 disables each.
+	MinLines int `toml:"min_lines"`
+	MinFiles int `toml:"min_files"`
+	// SquashWindowMinutes coalesces consecutive commits routed to this
+	// mapping, each within that many minutes of the previous, into one
+	// batched update; 0 updates the section once per commit.
+	SquashWindowMinutes int `toml:"squash_window_minutes"`
+	// LLM overrides the provider that writes this mapping's sections, e.g. a
+	// cheap model for changelog entries; unset fields inherit from [llm] as
+	// for [llm.planner].
+	LLM ProviderConfig `toml:"llm"`
 }
 
+const RenderMermaidArch = "mermaid-arch"
+
 type GitConfig struct {
 	CommitDocUpdates bool   `toml:"commit_doc_updates"`
 	AmendOriginal    bool   `toml:"amend_original"`
 	DocCommitMessage string `toml:"doc_commit_message"`
+	MergeStrategy    string `toml:"merge_strategy"`
+	// TargetBranch, when set, makes doc commits land on this branch, checked
+	// out in a worktree, instead of the current branch.
+	TargetBranch string `toml:"target_branch"`
+	// SparseCheckout decides what happens to target docs a sparse checkout
+	// left out: "add" adds them to the sparse checkout, "off" treats them as
+	// missing.
+	SparseCheckout string `toml:"sparse_checkout"`
+	// Deepen is how many commits a shallow clone fetches when it lacks the
+	// last processed commit, before processing only the commits it has.
+	Deepen int `toml:"deepen"`
+
+	// CommitterName and CommitterEmail set user.name and user.email for doc
+	// commits instead of the ambient git identity.
+	CommitterName  string `toml:"committer_name"`
+	CommitterEmail string `toml:"committer_email"`
+	// GPGSign overrides commit.gpgSign for doc commits ("true" or "false");
+	// empty keeps the repository setting. SigningKey overrides
+	// user.signingKey, an SSH key path or GPG key ID.
+	GPGSign    string `toml:"gpg_sign"`
+	SigningKey string `toml:"signing_key"`
+	// NoVerify skips pre-commit and commit-msg hooks for doc commits.
+	NoVerify bool `toml:"no_verify"`
+	// DirtyTreePolicy decides how doc commits treat other uncommitted
+	// changes: "only", "abort" or "worktree".
+	DirtyTreePolicy string `toml:"dirty_tree_policy"`
 }
 
 type StateConfig struct {
 	DBPath string `toml:"db_path"`
+	// AuthToken authenticates a libsql:// db_path; typically
+	// "${TURSO_AUTH_TOKEN}".
+	AuthToken string `toml:"auth_token"`
+	// EncryptionKey enables AES-GCM encryption of cached LLM responses and
+	// run event metadata; typically "${GIT_DOC_STATE_KEY}".
+	EncryptionKey string `toml:"encryption_key"`
+	// RetentionDays prunes run events, runs and cached responses older than
+	// this many days after each update; 0 keeps them forever.
+	RetentionDays int `toml:"retention_days"`
+	// LogPrompts keeps every prompt sent to a provider for git-doc
+	// export-prompts; false records only prompt hashes.
+	LogPrompts bool `toml:"log_prompts"`
+}
+
+// CacheConfig controls reuse of LLM responses beyond the per-commit cache.
+// ContentTTLHours reuses a response for any commit whose prompt is identical
+// (cherry-picks, reverts, repeated formatting commits) when it is younger
+// than this many hours; 0 turns the content-addressed lookup off. Dir names
+// a cache directory shared across repositories, such as ~/.cache/git-doc.
+type CacheConfig struct {
+	ContentTTLHours int    `toml:"content_ttl_hours"`
+	Dir             string `toml:"dir"`
+}
+
+// ResolvedDir returns Dir with a leading ~ expanded to the home directory.
+func (c CacheConfig) ResolvedDir() (string, error) {
+	dir := strings.TrimSpace(c.Dir)
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cache.dir: resolve home directory: %w", err)
+		}
+		dir = filepath.Join(home, strings.TrimPrefix(dir, "~"))
+	}
+	return dir, nil
 }
 
 type RuntimeOptions struct {
 	DefaultSection string `toml:"default_section"`
+	BatchCommits   bool   `toml:"batch_commits"`
+	DryRun         bool   `toml:"dry_run"`
+	// LockTTLMinutes is how long a run may hold the run lock before another
+	// run treats it as hung and takes over; 0 never takes over. It applies
+	// only with a RunDeadline, which keeps healthy runs shorter than it.
+	LockTTLMinutes int `toml:"lock_ttl_minutes"`
+	// CommitTimeout and RunDeadline bound one commit and a whole run in
+	// seconds; 0 disables them.
+	CommitTimeout int `toml:"commit_timeout"`
+	RunDeadline   int `toml:"run_deadline"`
+	// Offline forbids network LLM providers: only cached responses and local
+	// providers are used, and commits that need a network call stay pending.
+	Offline bool `toml:"offline"`
+	// Schedule is a cron expression at which git-doc daemon documents the
+	// commits accumulated since the last run in one batch; hook-triggered
+	// updates are skipped while it is set.
+	Schedule string `toml:"schedule"`
+}
+
+type PromptsConfig struct {
+	Dir                     string `toml:"dir"`
+	DefaultTemplate         string `toml:"default_template"`
+	IncludeExistingSection  bool   `toml:"include_existing_section"`
+	ExistingSectionMaxChars int    `toml:"existing_section_max_chars"`
+	IncludeFullDoc          bool   `toml:"include_full_doc"`
+	FullDocMaxChars         int    `toml:"full_doc_max_chars"`
+	// StyleGuide is appended to every prompt when the file exists.
+	StyleGuide         string `toml:"style_guide"`
+	StyleGuideMaxChars int    `toml:"style_guide_max_chars"`
+	// Examples is how many similar accepted updates to show as few-shot
+	// examples; 0 disables them.
+	Examples        int `toml:"examples"`
+	ExampleMaxChars int `toml:"example_max_chars"`
+}
+
+// DiffConfig bounds the diff context sent to the LLM. Binary files and files
+// matching exclude_paths are listed by name only.
+type DiffConfig struct {
+	MaxChars     int      `toml:"max_chars"`
+	MaxFileChars int      `toml:"max_file_chars"`
+	MaxFileLines int      `toml:"max_file_lines"`
+	ExcludePaths []string `toml:"exclude_paths"`
+}
+
+// EmbeddingsConfig enables semantic retrieval of the target section for
+// commits no mapping routes. Unset provider, api_key and base_url inherit
+// from [llm] when the provider matches.
+type EmbeddingsConfig struct {
+	Enabled       bool    `toml:"enabled"`
+	Provider      string  `toml:"provider"`
+	Model         string  `toml:"model"`
+	APIKey        string  `toml:"api_key"`
+	BaseURL       string  `toml:"base_url"`
+	Timeout       int     `toml:"timeout"`
+	MinSimilarity float64 `toml:"min_similarity"`
+}
+
+// ChangelogConfig maintains a Keep a Changelog file: every update run adds
+// the Conventional Commits it processed under "Unreleased".
+type ChangelogConfig struct {
+	Enabled   bool     `toml:"enabled"`
+	File      string   `toml:"file"`
+	SkipTypes []string `toml:"skip_types"`
+}
+
+// StatusBlockConfig keeps a marker-delimited sync status block in a doc.
+type StatusBlockConfig struct {
+	Enabled bool   `toml:"enabled"`
+	File    string `toml:"file"`
+}
+
+// TranslationsConfig propagates every updated section of a source doc to
+// its translated copies.
+type TranslationsConfig struct {
+	Enabled bool                `toml:"enabled"`
+	Locales []TranslationLocale `toml:"locales"`
+}
+
+// TranslationLocale is one translated copy of Source. Sections maps a source
+// heading to its translated heading; unmapped headings keep their title.
+type TranslationLocale struct {
+	Source   string            `toml:"source"`
+	DocFile  string            `toml:"doc_file"`
+	Language string            `toml:"language"`
+	Sections map[string]string `toml:"sections"`
+}
+
+type PolicyConfig struct {
+	BlockSecrets   bool     `toml:"block_secrets"`
+	BlockProfanity bool     `toml:"block_profanity"`
+	ProfanityWords []string `toml:"profanity_words"`
+	BannedPhrases  []string `toml:"banned_phrases"`
+	// FactCheck looks up the identifiers, flags and file paths a generated
+	// section mentions in the commit, the doc and the repository: "warn"
+	// logs the ones it cannot find, "fail" fails the commit, "off" skips it.
+	FactCheck string `toml:"fact_check"`
+}
+
+// LinksConfig checks, after each doc update, that links into the updated
+// file and links the update added still resolve. Check is "off", "warn"
+// (log broken links), "fail" (fail the update) or "fix" (point links to a
+// renamed heading at its new anchor and fail on the rest).
+type LinksConfig struct {
+	Check string `toml:"check"`
+}
+
+// HumanEditsConfig decides what happens when a section git-doc generated
+// was edited by hand since it last wrote it. Policy is "merge" (merge the
+// hand edits and the new content line by line, appending the new content
+// when they conflict), "append" (keep the edited text and append the new
+// content), "confirm" (ask before overwriting; non-interactive runs fail the
+// commit) or "overwrite".
+type HumanEditsConfig struct {
+	Policy string `toml:"policy"`
+}
+
+// OwnersConfig assigns doc files to owners like CODEOWNERS: the last rule
+// whose path matches a doc file decides its policy, "auto" (write and commit
+// updates), "require-approval" (queue them for approval) or "never" (leave
+// the doc alone). Rules without a policy use DefaultPolicy.
+type OwnersConfig struct {
+	DefaultPolicy string      `toml:"default_policy"`
+	Rules         []OwnerRule `toml:"rules"`
+}
+
+type OwnerRule struct {
+	Path   string   `toml:"path"`
+	Owners []string `toml:"owners"`
+	Policy string   `toml:"policy"`
+}
+
+// SubmodulesConfig lists the submodules, by path, whose updates are
+// documented from their own history: a commit moving one is treated as the
+// files and diff between its old and new commit, under the submodule path.
+// Updates of other submodules are left out of commits and logged.
+type SubmodulesConfig struct {
+	Recurse []string `toml:"recurse"`
+}
+
+// TOCConfig controls the tables of contents git-doc regenerates between
+// <!-- toc --> and <!-- tocstop --> markers in the docs it writes.
+type TOCConfig struct {
+	// MaxLevel is the deepest heading level listed; level 1 never is.
+	MaxLevel int `toml:"max_level"`
+}
+
+// RedactionConfig masks secrets in commit messages and diffs before they are
+// put into a prompt. The built-in secret patterns always apply; Patterns adds
+// regular expressions and Paths withholds the whole diff of matching files.
+// OnMatch "fail" fails the commit instead of redacting.
+type RedactionConfig struct {
+	Enabled  bool     `toml:"enabled"`
+	Patterns []string `toml:"patterns"`
+	Paths    []string `toml:"paths"`
+	OnMatch  string   `toml:"on_match"`
+}
+
+// TracingConfig exports OpenTelemetry spans for each update run over
+// OTLP/HTTP. An empty endpoint falls back to OTEL_EXPORTER_OTLP_ENDPOINT.
+type TracingConfig struct {
+	Enabled     bool              `toml:"enabled"`
+	Endpoint    string            `toml:"endpoint"`
+	ServiceName string            `toml:"service_name"`
+	Headers     map[string]string `toml:"headers"`
+}
+
+// NotificationsConfig posts a summary to each webhook after every run that
+// processed commits, and an alert as soon as FailureThreshold commits fail in
+// a row. CommitURL links doc commits, with {hash} replaced.
+type NotificationsConfig struct {
+	Webhooks         []Webhook   `toml:"webhooks"`
+	FailureThreshold int         `toml:"failure_threshold"`
+	CommitURL        string      `toml:"commit_url"`
+	Email            EmailConfig `toml:"email"`
+}
+
+// EmailConfig sends a daily or weekly digest of the doc sections git-doc
+// updated, with the diffs of their doc commits, over SMTP. Port 465 uses
+// implicit TLS; other ports upgrade with STARTTLS when the server offers it.
+type EmailConfig struct {
+	Enabled      bool     `toml:"enabled"`
+	Schedule     string   `toml:"schedule"`
+	SMTPHost     string   `toml:"smtp_host"`
+	SMTPPort     int      `toml:"smtp_port"`
+	Username     string   `toml:"username"`
+	Password     string   `toml:"password"`
+	From         string   `toml:"from"`
+	To           []string `toml:"to"`
+	MaxDiffLines int      `toml:"max_diff_lines"`
+}
+
+// Webhook is a Slack or Discord incoming webhook, or a generic endpoint that
+// receives the run as JSON.
+type Webhook struct {
+	Kind         string `toml:"kind"`
+	URL          string `toml:"url"`
+	OnlyFailures bool   `toml:"only_failures"`
+}
+
+type IgnoreConfig struct {
+	Paths    []string `toml:"paths"`
+	Authors  []string `toml:"authors"`
+	Messages []string `toml:"messages"`
+	// MinRelevance skips commits whose diff scores below it; tests-only,
+	// comment-only and formatting-only changes score at most 0.15.
+	MinRelevance float64 `toml:"min_relevance"`
+
+	messagePatterns []*regexp.Regexp
+}
+
+// MessagePatterns returns Messages compiled, skipping blank patterns.
+// Validate compiles them once; a config that was never validated compiles
+// them here.
+func (c IgnoreConfig) MessagePatterns() ([]*regexp.Regexp, error) {
+	if c.messagePatterns != nil {
+		return c.messagePatterns, nil
+	}
+	patterns := make([]*regexp.Regexp, 0, len(c.Messages))
+	for _, pattern := range c.Messages {
+		if strings.TrimSpace(pattern) == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore.messages pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, re)
+	}
+	return patterns, nil
 }
 
 func Load(path string) (*Config, error) {
+	return LoadProfile(path, "")
+}
+
+// LoadProfile loads the config at path with the named [profiles.<name>] table
+// applied over it. An empty name loads the base config.
+func LoadProfile(path, profile string) (*Config, error) {
+	return loadProfile(path, profile, true)
+}
+
+// loadProfile is LoadProfile; with runCommands false, exec: and keychain:
+// secrets are only checked for shape and left unresolved, as config
+// validation must not run them.
+func loadProfile(path, profile string, runCommands bool) (*Config, error) {
 	if _, err := os.Stat(path); err != nil {
 		return nil, fmt.Errorf("config file %s not found: %w", path, err)
 	}
 
 	cfg := Default()
-	if _, err := toml.DecodeFile(path, cfg); err != nil {
+	md, err := toml.DecodeFile(path, cfg)
+	if err != nil {
 		return nil, fmt.Errorf("parse config: %w", err)
 	}
+	if profile != "" {
+		if err := cfg.applyProfile(&md, profile); err != nil {
+			return nil, err
+		}
+	}
 
 	cfg.expandEnv()
+	if runCommands {
+		if err := cfg.resolveSecrets(true); err != nil {
+			return nil, err
+		}
+	} else if err := cfg.checkSecrets(); err != nil {
+		return nil, err
+	}
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
@@ -66,27 +507,103 @@ func Load(path string) (*Config, error) {
 	return cfg, nil
 }
 
+func (c *Config) applyProfile(md *toml.MetaData, name string) error {
+	prim, ok := c.Profiles[name]
+	if !ok {
+		defined := c.profileNames()
+		if len(defined) == 0 {
+			return fmt.Errorf("unknown profile %q: config defines no profiles", name)
+		}
+		return fmt.Errorf("unknown profile %q (defined: %s)", name, strings.Join(defined, ", "))
+	}
+	if err := md.PrimitiveDecode(prim, c); err != nil {
+		return fmt.Errorf("profile %s: %w", name, err)
+	}
+	return nil
+}
+
+func (c *Config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func Default() *Config {
 	return &Config{
+		Version: SchemaVersion,
 		LLM: LLMConfig{
-			Provider:        "mock",
-			Model:           "gpt-4o-mini",
-			Timeout:         60,
-			MaxRetries:      3,
-			FailoverEnabled: true,
+			Provider:                "mock",
+			Model:                   "gpt-4o-mini",
+			Timeout:                 60,
+			MaxRetries:              3,
+			FailoverEnabled:         true,
+			CircuitBreakerThreshold: 5,
+			CircuitBreakerCooldown:  300,
+			MinConfidence:           0.5,
+			Ollama:                  OllamaConfig{Pull: "prompt"},
 		},
 		DocFiles: []string{"README.md", "docs/**/*.md"},
 		Git: GitConfig{
 			CommitDocUpdates: true,
 			DocCommitMessage: "docs: auto-update for {hash}",
+			DirtyTreePolicy:  "only",
+			SparseCheckout:   "add",
+			MergeStrategy:    "first-parent",
 		},
 		State:   StateConfig{DBPath: ".git-doc/state.db"},
-		Runtime: RuntimeOptions{DefaultSection: "Recent Changes"},
+		Cache:   CacheConfig{ContentTTLHours: 168},
+		Runtime: RuntimeOptions{DefaultSection: "Recent Changes", LockTTLMinutes: 60, CommitTimeout: 300},
+		Prompts: PromptsConfig{
+			Dir:                     ".git-doc/prompts",
+			IncludeExistingSection:  true,
+			ExistingSectionMaxChars: 4000,
+			FullDocMaxChars:         12000,
+			StyleGuide:              ".git-doc/style.md",
+			StyleGuideMaxChars:      4000,
+			ExampleMaxChars:         1500,
+		},
+		Diff: DiffConfig{
+			MaxChars:     6000,
+			MaxFileChars: 2000,
+			MaxFileLines: 1000,
+			ExcludePaths: defaultDiffExcludes(),
+		},
+		Embeddings: EmbeddingsConfig{MinSimilarity: 0.3},
+		Changelog: ChangelogConfig{
+			File:      "CHANGELOG.md",
+			SkipTypes: []string{"docs", "chore", "test", "ci", "build", "style"},
+		},
+		StatusBlock: StatusBlockConfig{File: "README.md"},
+		Policy:      PolicyConfig{BlockSecrets: true, FactCheck: "warn"},
+		Links:       LinksConfig{Check: "warn"},
+		HumanEdits:  HumanEditsConfig{Policy: "merge"},
+		Owners:      OwnersConfig{DefaultPolicy: "auto"},
+		TOC:         TOCConfig{MaxLevel: 3},
+		Redaction: RedactionConfig{
+			Enabled: true,
+			Paths:   []string{"**/.env", "**/.env.*", "**/*.pem", "**/*.key", "**/secrets/**"},
+			OnMatch: "redact",
+		},
+		Tracing: TracingConfig{ServiceName: "git-doc"},
+		Notifications: NotificationsConfig{
+			FailureThreshold: 3,
+			Email:            EmailConfig{Schedule: "daily", SMTPPort: 587, MaxDiffLines: 200},
+		},
 	}
 }
 
+func defaultDiffExcludes() []string {
+	return []string{"**/go.sum", "**/package-lock.json", "**/yarn.lock", "**/pnpm-lock.yaml", "**/Cargo.lock", "**/*.min.js"}
+}
+
 func DefaultToml() string {
-	return `# Documentation files to manage (glob patterns)
+	return `# Config schema version (see git-doc config validate)
+version = 1
+
+# Documentation files to manage (glob patterns)
 doc_files = ["README.md", "docs/**/*.md"]
 
 # LLM settings
@@ -98,34 +615,328 @@ timeout = 60
 max_retries = 3
 failover_enabled = true
 fallback_providers = []
+# Client-side rate limits per provider (0 disables)
+requests_per_minute = 0
+tokens_per_minute = 0
+# Consecutive failures before a provider is skipped for the cool-down (seconds)
+circuit_breaker_threshold = 5
+circuit_breaker_cooldown = 300
+# Generation parameters passed to every provider (unset uses provider defaults)
+# system_prompt = "You are a technical writer maintaining project documentation."
+# temperature = 0.2
+# top_p = 1.0
+# max_tokens = 4096
+# Ask for {section_markdown, summary, confidence} JSON and reject responses
+# below min_confidence (OpenAI and Gemini enforce the schema natively)
+structured_output = false
+min_confidence = 0.5
+# Critique passes: send each generated section back with the diff to verify
+# its claims and fix hallucinations (each pass is one more LLM call)
+review_passes = 0
+
+# Ask a cheaper planner model which doc file and section a commit affects
+# when no mapping matches; unset planner fields inherit from [llm].
+plan_targets = false
+# [llm.planner]
+# provider = "openai"
+# model = "gpt-4o-mini"
+
+# Ollama: use /api/chat, set the context window, and pull a missing model
+# ("prompt" asks first, "always" pulls, "never" fails).
+# [llm.ollama]
+# chat = true
+# num_ctx = 8192
+# pull = "prompt"
+
+# Ensemble: for mappings with ensemble = true, each candidate writes the
+# section and the judge (the primary provider when unset) picks or merges
+# the best one.
+# [[llm.ensemble.candidates]]
+# provider = "openai"
+# model = "gpt-4o"
+# [[llm.ensemble.candidates]]
+# provider = "anthropic"
+# model = "claude-3-5-sonnet-latest"
+# api_key = "${ANTHROPIC_API_KEY}"
+# [llm.ensemble.judge]
+# model = "gpt-4o"
+
+# Optional explicit provider chain; the first block is primary and the rest are
+# failover targets. Unset model/timeout inherit from [llm]; api_key is only
+# inherited by blocks using the same provider as llm.provider.
+# [[llm.providers]]
+# provider = "openai"
+# model = "gpt-4o-mini"
+# api_key = "${GITDOC_OPENAI_KEY}"
+#
+# [[llm.providers]]
+# provider = "anthropic"
+# model = "claude-3-5-haiku-latest"
+# api_key = "${GITDOC_ANTHROPIC_KEY}"
+# timeout = 90
 
 [git]
 commit_doc_updates = true
 amend_original = false
+# Doc commit message, a Go text/template; {hash} is the source hash
+# (first..last for batches). Fields: .Hash, .ShortHash, .Hashes, .Subject,
+# .Author, .Email, .DocFiles, .Sections, .RunID and .Trailers
+# (Doc-Source-Commit and Doc-Run-Id lines); join is available, e.g.
+# "docs: update {{join .Sections \", \"}}\n\n{{.Trailers}}"
 doc_commit_message = "docs: auto-update for {hash}"
+# Merge commits: "skip", "first-parent" (diff against the first parent) or
+# "summarize" (describe the merged branch's commits as one unit)
+merge_strategy = "first-parent"
+# Commit docs to this branch (e.g. "docs/auto") instead of the current one;
+# it is checked out in a worktree under .git and created from HEAD if missing.
+target_branch = ""
+# Target docs a sparse checkout left out: "add" adds them to the sparse
+# checkout, "off" treats them as missing
+sparse_checkout = "add"
+# Shallow clones (e.g. CI with fetch-depth 1) missing the last processed
+# commit run git fetch --deepen with this many commits first, then process
+# only the commits they have; 0 never fetches.
+deepen = 0
+# Identity and signing of doc commits; empty values keep the repository's
+# user.name, user.email, commit.gpgSign and user.signingKey.
+committer_name = ""
+committer_email = ""
+gpg_sign = ""
+signing_key = ""
+# Skip pre-commit and commit-msg hooks for doc commits.
+no_verify = false
+# Other uncommitted changes: "only" leaves them out of doc commits, "abort"
+# refuses to commit, "worktree" commits through a temporary index
+dirty_tree_policy = "only"
 
 [state]
 db_path = ".git-doc/state.db"
+# Or share one ledger between machines and CI on a libSQL server (sqld or
+# Turso); the token accepts ${ENV_VAR}, file:, exec: and keychain:
+# db_path = "libsql://team-db.turso.io"
+# auth_token = "${TURSO_AUTH_TOKEN}"
+# Keep the full text of every prompt sent to a provider for auditing with
+# git-doc export-prompts; false keeps only prompt hashes.
+log_prompts = false
+
+# Responses are cached per commit. An identical prompt from another commit
+# (cherry-pick, revert, repeated formatting change) reuses a cached response
+# younger than content_ttl_hours instead of calling the provider; 0 disables.
+# dir adds a cache shared by every repository using the same directory, so
+# forks and monorepo splits reuse responses (not with state.encryption_key).
+[cache]
+content_ttl_hours = 168
+# dir = "~/.cache/git-doc"
 
 [runtime]
 default_section = "Recent Changes"
+# Summarize all commits of a run into one LLM call per section and one doc commit
+batch_commits = false
+# A run holding the run lock longer than this is treated as hung and
+# overridden by the next run (0 never overrides). Applies only when
+# run_deadline is set, and must be longer than it.
+lock_ttl_minutes = 60
+# Seconds one commit may take before it is marked failed (retryable with
+# git-doc retry), and seconds a whole run may take before the remaining
+# commits are left pending for the next run. 0 disables either limit.
+commit_timeout = 300
+run_deadline = 0
+# Use only cached responses and local providers (ollama, or a base_url on
+# localhost); commits that would need a network provider stay pending.
+offline = false
+# Cron expression (minute hour day month weekday, local time) for git-doc
+# daemon: commits accumulate and are documented in one batched update at
+# each scheduled time, and the git hooks stop updating docs per commit.
+# schedule = "0 18 * * *"
+
+# Prompt templates (Go text/template). Mappings may set prompt_template
+# to a file name inside dir; default.tmpl in dir overrides the built-in prompt.
+[prompts]
+dir = ".git-doc/prompts"
+default_template = ""
+include_existing_section = true
+existing_section_max_chars = 4000
+include_full_doc = false
+full_doc_max_chars = 12000
+# Writing standards (tone, tense, glossary) appended to every prompt when
+# the file exists; an empty value disables it.
+style_guide = ".git-doc/style.md"
+style_guide_max_chars = 4000
+# Include the N past accepted updates most similar to the commit (by commit
+# message and changed paths) as examples in the prompt. 0 disables them.
+examples = 0
+example_max_chars = 1500
+
+# Diff context sent to the LLM. Hunks with the most changed lines are kept
+# when the diff exceeds max_chars; binary files, files over max_file_lines
+# changed lines and exclude_paths globs are listed by name only.
+[diff]
+max_chars = 6000
+max_file_chars = 2000
+max_file_lines = 1000
+exclude_paths = ["**/go.sum", "**/package-lock.json", "**/yarn.lock", "**/pnpm-lock.yaml", "**/Cargo.lock", "**/*.min.js"]
+
+# Pick the most similar doc section by embeddings when no mapping matches.
+# Section vectors are cached in the state DB and refreshed when a section
+# changes. Unset provider/api_key/base_url inherit from [llm].
+[embeddings]
+enabled = false
+# provider = "openai"       # openai, mistral, ollama or mock
+# model = "text-embedding-3-small"
+min_similarity = 0.3
+
+# Keep a Changelog maintenance: each update adds processed Conventional
+# Commits under "## [Unreleased]" grouped by type (feat: Added, fix: Fixed,
+# refactor/perf: Changed...); "git-doc release <version>" dates them.
+[changelog]
+enabled = false
+file = "CHANGELOG.md"
+skip_types = ["docs", "chore", "test", "ci", "build", "style"]
+
+# Keep a status block (last synced commit, date, share of tracked files
+# covered by mappings) between <!-- git-doc:status:start/end --> markers.
+# Only the text between the markers is rewritten; it is inserted below the
+# title when missing.
+[status_block]
+enabled = false
+file = "README.md"
+
+# Translated docs: after a section of source is updated, the same section of
+# doc_file is regenerated in language and committed with it. sections maps
+# source headings to translated headings.
+[translations]
+enabled = false
+# [[translations.locales]]
+# source = "README.md"
+# doc_file = "README.zh.md"
+# language = "Simplified Chinese"
+# sections = { "Recent Changes" = "最近更新" }
+
+# Commits matching any rule are marked skipped before any LLM call.
+# paths: globs; a commit is skipped only when every changed file matches.
+# authors: author emails. messages: regular expressions on the commit message.
+# min_relevance: skip commits whose diff scores below it (0-1); tests-only,
+# comment-only and formatting-only changes score at most 0.15. 0 disables.
+[ignore]
+paths = []
+authors = []
+messages = ['\[skip docs\]']
+min_relevance = 0.0
+
+# Generated content matching any rule is not written; the planned update is
+# marked failed with the violation. banned_phrases are regular expressions.
+[policy]
+block_secrets = true
+block_profanity = false
+profanity_words = []
+banned_phrases = []
+# Look up identifiers, flags and paths the section mentions in the diff, the
+# doc and the repository: "warn" logs unknown ones, "fail" blocks them.
+fact_check = "warn"
+
+# After each update, check that links into the updated doc and links the
+# update added still resolve, scanning every doc_files document: "warn" logs
+# broken links, "fail" fails the update, "fix" points links to a renamed
+# heading at its new anchor and fails on the rest, "off" skips the check.
+[links]
+check = "warn"
+
+# When a generated section was edited by hand since git-doc last wrote it:
+# "merge" merges the edits with the new content (appending it on conflict),
+# "append" keeps the edited text and appends, "confirm" asks first and fails
+# non-interactive runs, "overwrite" replaces the section anyway.
+[human_edits]
+policy = "merge"
+
+# Doc owners, CODEOWNERS style: the last matching rule decides whether
+# updates to a doc are committed ("auto"), queued for approval
+# ("require-approval") or never made ("never").
+[owners]
+default_policy = "auto"
+# [[owners.rules]]
+# path = "SECURITY.md"
+# owners = ["@security-team"]
+# policy = "require-approval"
+
+# Submodules (by path) whose updates are documented from their own history;
+# their changed files appear under the submodule path, so mappings such as
+# code_pattern = "libs/ui/**" match them. Other submodule updates are skipped.
+[submodules]
+recurse = []
+
+# Docs git-doc writes get the list between <!-- toc --> and <!-- tocstop -->
+# regenerated from their headings (level 2 down to max_level).
+[toc]
+max_level = 3
+
+# API keys, tokens, private keys and secret assignments in commit messages
+# and diffs are replaced with [REDACTED:<rule>] before prompts are built.
+# patterns adds regular expressions; the diff of files matching paths is
+# withheld entirely. on_match = "fail" fails the commit instead of redacting.
+[redaction]
+enabled = true
+patterns = []
+paths = ["**/.env", "**/.env.*", "**/*.pem", "**/*.key", "**/secrets/**"]
+on_match = "redact"
+
+# OpenTelemetry spans for each run (git operations, cache lookup, LLM call,
+# doc write, git commit) are posted to <endpoint>/v1/traces as OTLP/HTTP JSON
+# when the run finishes. An empty endpoint uses OTEL_EXPORTER_OTLP_ENDPOINT.
+[tracing]
+enabled = false
+endpoint = ""               # e.g. "http://localhost:4318"
+service_name = "git-doc"
+# headers = { "x-honeycomb-team" = "${HONEYCOMB_API_KEY}" }
+
+# Post a run summary (counts, doc commits, failures) to each webhook after
+# every run that processed commits, and alert as soon as failure_threshold
+# commits fail in a row (0 disables alerts). kind is slack, discord or
+# webhook (the run as JSON). commit_url links doc commits; {hash} is replaced.
+[notifications]
+failure_threshold = 3
+# commit_url = "https://github.com/OWNER/REPO/commit/{hash}"
+# [[notifications.webhooks]]
+# kind = "slack"
+# url = "${SLACK_WEBHOOK_URL}"
+# only_failures = false
+
+# Daily or weekly email digest of the doc sections git-doc updated, with the
+# diffs of their doc commits. It is sent by the first update after the period
+# ends, or on demand with git-doc digest. password accepts file:, exec: and
+# keychain: references.
+[notifications.email]
+enabled = false
+schedule = "daily"          # daily or weekly
+smtp_host = ""
+smtp_port = 587             # 465 for implicit TLS
+# username = "git-doc@example.com"
+# password = "${SMTP_PASSWORD}"
+# from = "git-doc@example.com"
+# to = ["docs-team@example.com"]
+max_diff_lines = 200
 `
 }
 
 func (c *Config) Validate() error {
+	if c.Version < 1 || c.Version > SchemaVersion {
+		return fmt.Errorf("unsupported config version %d (this git-doc supports version %d)", c.Version, SchemaVersion)
+	}
+
 	if strings.TrimSpace(c.LLM.Provider) == "" {
 		return errors.New("llm.provider is required")
 	}
 
 	provider := strings.ToLower(strings.TrimSpace(c.LLM.Provider))
 	supported := map[string]bool{
-		"mock":      true,
-		"openai":    true,
-		"anthropic": true,
-		"google":    true,
-		"gemini":    true,
-		"groq":      true,
-		"ollama":    true,
+		"mock":       true,
+		"openai":     true,
+		"anthropic":  true,
+		"google":     true,
+		"gemini":     true,
+		"groq":       true,
+		"ollama":     true,
+		"mistral":    true,
+		"openrouter": true,
 	}
 
 	if !supported[provider] {
@@ -142,18 +953,380 @@ func (c *Config) Validate() error {
 		}
 	}
 
-	if (provider == "openai" || provider == "anthropic" || provider == "google" || provider == "gemini" || provider == "groq") && strings.TrimSpace(c.LLM.APIKey) == "" {
-		return fmt.Errorf("llm.api_key is required for %s provider", provider)
+	for i, block := range c.LLM.Providers {
+		name := strings.ToLower(strings.TrimSpace(block.Provider))
+		if name == "" {
+			return fmt.Errorf("llm.providers[%d].provider is required", i)
+		}
+		if !supported[name] {
+			return fmt.Errorf("unsupported llm.providers[%d].provider: %s", i, block.Provider)
+		}
+	}
+
+	for i, resolved := range c.LLM.ResolvedProviders() {
+		field := "llm"
+		if len(c.LLM.Providers) > 0 {
+			field = fmt.Sprintf("llm.providers[%d]", i)
+		} else if i > 0 {
+			break
+		}
+		if err := validateProviderSettings(field, resolved); err != nil {
+			return err
+		}
+	}
+
+	if t := c.LLM.Temperature; t != nil && (*t < 0 || *t > 2) {
+		return fmt.Errorf("llm.temperature must be between 0 and 2, got %g", *t)
+	}
+
+	if p := c.LLM.TopP; p != nil && (*p < 0 || *p > 1) {
+		return fmt.Errorf("llm.top_p must be between 0 and 1, got %g", *p)
+	}
+
+	if c.LLM.MaxTokens < 0 {
+		return fmt.Errorf("llm.max_tokens must not be negative")
+	}
+
+	if c.LLM.ReviewPasses < 0 || c.LLM.ReviewPasses > 3 {
+		return fmt.Errorf("llm.review_passes must be between 0 and 3, got %d", c.LLM.ReviewPasses)
+	}
+
+	if c.LLM.MinConfidence < 0 || c.LLM.MinConfidence > 1 {
+		return fmt.Errorf("llm.min_confidence must be between 0 and 1, got %g", c.LLM.MinConfidence)
+	}
+
+	if name := strings.ToLower(strings.TrimSpace(c.LLM.Planner.Provider)); name != "" && !supported[name] {
+		return fmt.Errorf("unsupported llm.planner.provider: %s", c.LLM.Planner.Provider)
+	}
+	if c.LLM.PlanTargets {
+		if err := validateProviderSettings("llm.planner", c.LLM.ResolvedPlanner()); err != nil {
+			return err
+		}
+	}
+
+	for i, block := range c.LLM.Ensemble.Candidates {
+		field := fmt.Sprintf("llm.ensemble.candidates[%d]", i)
+		name := strings.ToLower(strings.TrimSpace(block.Provider))
+		if name == "" {
+			return fmt.Errorf("%s.provider is required", field)
+		}
+		if !supported[name] {
+			return fmt.Errorf("unsupported %s.provider: %s", field, block.Provider)
+		}
+		if err := validateProviderSettings(field, c.LLM.ResolvedCandidates()[i]); err != nil {
+			return err
+		}
+	}
+	if name := strings.ToLower(strings.TrimSpace(c.LLM.Ensemble.Judge.Provider)); name != "" && !supported[name] {
+		return fmt.Errorf("unsupported llm.ensemble.judge.provider: %s", c.LLM.Ensemble.Judge.Provider)
+	}
+	if len(c.LLM.Ensemble.Candidates) > 0 {
+		if err := validateProviderSettings("llm.ensemble.judge", c.LLM.ResolvedJudge()); err != nil {
+			return err
+		}
+	}
+
+	for i, mapping := range c.Mappings {
+		if mapping.Ensemble && len(c.LLM.Ensemble.Candidates) < 2 {
+			return fmt.Errorf("mappings[%d].ensemble needs at least two [[llm.ensemble.candidates]]", i)
+		}
+		if strings.TrimSpace(mapping.CodePattern) == "" && strings.TrimSpace(mapping.Type) == "" && strings.TrimSpace(mapping.Scope) == "" {
+			return fmt.Errorf("mappings[%d] needs at least one of code_pattern, type or scope", i)
+		}
+		if strings.TrimSpace(mapping.DocFile) == "" {
+			return fmt.Errorf("mappings[%d].doc_file is required", i)
+		}
+		if n := MaxDocFilePlaceholder(mapping.DocFile); n > 0 {
+			if wildcards := PatternWildcards(mapping.CodePattern); n > wildcards {
+				return fmt.Errorf("mappings[%d].doc_file uses {%d} but code_pattern has %d wildcard segments", i, n, wildcards)
+			}
+		}
+		if mapping.SectionLevel < 0 || mapping.SectionLevel > 6 {
+			return fmt.Errorf("mappings[%d].section_level must be between 1 and 6, got %d", i, mapping.SectionLevel)
+		}
+		switch insert := strings.TrimSpace(mapping.Insert); {
+		case insert == "", insert == "end", insert == "top", insert == "alphabetical":
+		case strings.HasPrefix(insert, "after:") && strings.TrimSpace(strings.TrimPrefix(insert, "after:")) != "":
+		default:
+			return fmt.Errorf("mappings[%d].insert must be end, top, alphabetical or after:<heading>, got %q", i, mapping.Insert)
+		}
+		if mapping.Render != "" && mapping.Render != RenderMermaidArch {
+			return fmt.Errorf("mappings[%d].render must be empty or %q", i, RenderMermaidArch)
+		}
+		if mapping.MinLines < 0 || mapping.MinFiles < 0 {
+			return fmt.Errorf("mappings[%d].min_lines and min_files must not be negative", i)
+		}
+		if mapping.SquashWindowMinutes < 0 {
+			return fmt.Errorf("mappings[%d].squash_window_minutes must not be negative, got %d", i, mapping.SquashWindowMinutes)
+		}
+		if mapping.LLM != (ProviderConfig{}) {
+			if mapping.Ensemble {
+				return fmt.Errorf("mappings[%d].llm cannot be combined with ensemble", i)
+			}
+			if name := strings.ToLower(strings.TrimSpace(mapping.LLM.Provider)); name != "" && !supported[name] {
+				return fmt.Errorf("unsupported mappings[%d].llm.provider: %s", i, mapping.LLM.Provider)
+			}
+			if err := validateProviderSettings(fmt.Sprintf("mappings[%d].llm", i), c.LLM.ResolvedMapping(mapping)); err != nil {
+				return err
+			}
+		}
 	}
 
 	if strings.TrimSpace(c.State.DBPath) == "" {
 		return errors.New("state.db_path is required")
 	}
+	if c.Cache.ContentTTLHours < 0 {
+		return fmt.Errorf("cache.content_ttl_hours must not be negative, got %d", c.Cache.ContentTTLHours)
+	}
+	if strings.TrimSpace(c.Cache.Dir) != "" && c.State.EncryptionKey != "" {
+		return errors.New("cache.dir cannot be combined with state.encryption_key: the shared cache stores responses unencrypted")
+	}
+	if c.State.RetentionDays < 0 {
+		return errors.New("state.retention_days must not be negative")
+	}
 
 	if strings.TrimSpace(c.Runtime.DefaultSection) == "" {
 		c.Runtime.DefaultSection = "Recent Changes"
 	}
 
+	switch strings.ToLower(strings.TrimSpace(c.Git.MergeStrategy)) {
+	case "":
+		c.Git.MergeStrategy = "first-parent"
+	case "skip", "first-parent", "summarize":
+		c.Git.MergeStrategy = strings.ToLower(strings.TrimSpace(c.Git.MergeStrategy))
+	default:
+		return fmt.Errorf("unsupported git.merge_strategy: %s (use skip, first-parent or summarize)", c.Git.MergeStrategy)
+	}
+	switch c.Git.SparseCheckout {
+	case "":
+		c.Git.SparseCheckout = "add"
+	case "add", "off":
+	default:
+		return fmt.Errorf("unsupported git.sparse_checkout: %s (use add or off)", c.Git.SparseCheckout)
+	}
+	if c.Git.Deepen < 0 {
+		return fmt.Errorf("git.deepen must be >= 0, got %d", c.Git.Deepen)
+	}
+	switch c.Git.DirtyTreePolicy {
+	case "":
+		c.Git.DirtyTreePolicy = "only"
+	case "only", "abort", "worktree":
+	default:
+		return fmt.Errorf("unsupported git.dirty_tree_policy: %s (use only, abort or worktree)", c.Git.DirtyTreePolicy)
+	}
+	if c.Git.TargetBranch != "" && c.Git.AmendOriginal {
+		return errors.New("git.target_branch cannot be combined with git.amend_original")
+	}
+	switch c.Git.GPGSign {
+	case "", "true", "false":
+	default:
+		return fmt.Errorf("unsupported git.gpg_sign: %s (use true, false or leave empty)", c.Git.GPGSign)
+	}
+	if _, err := template.New("commit").Funcs(template.FuncMap{"join": strings.Join}).Parse(c.Git.DocCommitMessage); err != nil {
+		return fmt.Errorf("invalid git.doc_commit_message: %w", err)
+	}
+
+	if c.TOC.MaxLevel < 2 || c.TOC.MaxLevel > 6 {
+		return fmt.Errorf("toc.max_level must be between 2 and 6, got %d", c.TOC.MaxLevel)
+	}
+
+	switch c.Links.Check {
+	case "off", "warn", "fail", "fix":
+	default:
+		return fmt.Errorf("unsupported links.check: %s (use off, warn, fail or fix)", c.Links.Check)
+	}
+
+	switch c.HumanEdits.Policy {
+	case "merge", "append", "confirm", "overwrite":
+	default:
+		return fmt.Errorf("unsupported human_edits.policy: %s (use merge, append, confirm or overwrite)", c.HumanEdits.Policy)
+	}
+
+	if !validOwnerPolicy(c.Owners.DefaultPolicy) {
+		return fmt.Errorf("unsupported owners.default_policy: %s (use auto, require-approval or never)", c.Owners.DefaultPolicy)
+	}
+	for i, rule := range c.Owners.Rules {
+		if strings.TrimSpace(rule.Path) == "" {
+			return fmt.Errorf("owners.rules[%d].path is required", i)
+		}
+		if rule.Policy != "" && !validOwnerPolicy(rule.Policy) {
+			return fmt.Errorf("unsupported owners.rules[%d].policy: %s (use auto, require-approval or never)", i, rule.Policy)
+		}
+	}
+
+	for i, path := range c.Submodules.Recurse {
+		path = strings.Trim(filepath.ToSlash(strings.TrimSpace(path)), "/")
+		if path == "" {
+			return fmt.Errorf("submodules.recurse[%d] is empty", i)
+		}
+		c.Submodules.Recurse[i] = path
+	}
+
+	switch c.Policy.FactCheck {
+	case "off", "warn", "fail":
+	default:
+		return fmt.Errorf("unsupported policy.fact_check: %s (use off, warn or fail)", c.Policy.FactCheck)
+	}
+
+	for _, pattern := range c.Policy.BannedPhrases {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid policy.banned_phrases pattern %q: %w", pattern, err)
+		}
+	}
+
+	switch c.LLM.Ollama.Pull {
+	case "prompt", "always", "never":
+	default:
+		return fmt.Errorf("unsupported llm.ollama.pull: %s (use prompt, always or never)", c.LLM.Ollama.Pull)
+	}
+	if c.LLM.Ollama.NumCtx < 0 {
+		return fmt.Errorf("llm.ollama.num_ctx must not be negative, got %d", c.LLM.Ollama.NumCtx)
+	}
+
+	switch c.Redaction.OnMatch {
+	case "redact", "fail":
+	default:
+		return fmt.Errorf("unsupported redaction.on_match: %s (use redact or fail)", c.Redaction.OnMatch)
+	}
+	for _, pattern := range c.Redaction.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid redaction.patterns pattern %q: %w", pattern, err)
+		}
+	}
+
+	if c.Embeddings.Enabled {
+		embeddings := c.ResolvedEmbeddings()
+		switch embeddings.Provider {
+		case "openai", "mistral", "ollama", "mock":
+		default:
+			return fmt.Errorf("unsupported embeddings.provider: %s (use openai, mistral, ollama or mock)", embeddings.Provider)
+		}
+		if requiresAPIKey(embeddings.Provider) && strings.TrimSpace(embeddings.APIKey) == "" {
+			return fmt.Errorf("embeddings.api_key is required for %s provider", embeddings.Provider)
+		}
+	}
+	if c.Embeddings.MinSimilarity < -1 || c.Embeddings.MinSimilarity > 1 {
+		return fmt.Errorf("embeddings.min_similarity must be between -1 and 1, got %g", c.Embeddings.MinSimilarity)
+	}
+
+	if c.Changelog.Enabled && strings.TrimSpace(c.Changelog.File) == "" {
+		return errors.New("changelog.file is required when changelog.enabled is set")
+	}
+
+	if c.StatusBlock.Enabled && strings.TrimSpace(c.StatusBlock.File) == "" {
+		return errors.New("status_block.file is required when status_block.enabled is set")
+	}
+
+	if c.Translations.Enabled {
+		for i, locale := range c.Translations.Locales {
+			if strings.TrimSpace(locale.Source) == "" || strings.TrimSpace(locale.DocFile) == "" || strings.TrimSpace(locale.Language) == "" {
+				return fmt.Errorf("translations.locales[%d] needs source, doc_file and language", i)
+			}
+			if locale.Source == locale.DocFile {
+				return fmt.Errorf("translations.locales[%d].doc_file must differ from source", i)
+			}
+		}
+	}
+
+	if c.Tracing.Enabled {
+		if strings.TrimSpace(c.Tracing.Endpoint) == "" {
+			c.Tracing.Endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		}
+		if strings.TrimSpace(c.Tracing.Endpoint) == "" {
+			return errors.New("tracing.endpoint (or OTEL_EXPORTER_OTLP_ENDPOINT) is required when tracing.enabled is set")
+		}
+	}
+
+	for i, hook := range c.Notifications.Webhooks {
+		switch hook.Kind {
+		case "slack", "discord", "webhook":
+		default:
+			return fmt.Errorf("unsupported notifications.webhooks[%d].kind: %q (use slack, discord or webhook)", i, hook.Kind)
+		}
+		if strings.TrimSpace(hook.URL) == "" {
+			return fmt.Errorf("notifications.webhooks[%d].url is required", i)
+		}
+	}
+	if c.Notifications.FailureThreshold < 0 {
+		return fmt.Errorf("notifications.failure_threshold must not be negative, got %d", c.Notifications.FailureThreshold)
+	}
+	if email := c.Notifications.Email; email.Enabled {
+		if email.Schedule != "daily" && email.Schedule != "weekly" {
+			return fmt.Errorf("unsupported notifications.email.schedule: %q (use daily or weekly)", email.Schedule)
+		}
+		if strings.TrimSpace(email.SMTPHost) == "" || strings.TrimSpace(email.From) == "" || len(email.To) == 0 {
+			return errors.New("notifications.email needs smtp_host, from and to when enabled")
+		}
+		if email.SMTPPort <= 0 || email.SMTPPort > 65535 {
+			return fmt.Errorf("notifications.email.smtp_port must be between 1 and 65535, got %d", email.SMTPPort)
+		}
+	}
+	if c.Notifications.Email.MaxDiffLines <= 0 {
+		c.Notifications.Email.MaxDiffLines = 200
+	}
+
+	if c.Ignore.MinRelevance < 0 || c.Ignore.MinRelevance > 1 {
+		return fmt.Errorf("ignore.min_relevance must be between 0 and 1, got %g", c.Ignore.MinRelevance)
+	}
+
+	c.Ignore.messagePatterns = nil
+	patterns, err := c.Ignore.MessagePatterns()
+	if err != nil {
+		return err
+	}
+	c.Ignore.messagePatterns = patterns
+
+	if strings.TrimSpace(c.Prompts.Dir) == "" {
+		c.Prompts.Dir = ".git-doc/prompts"
+	}
+
+	if c.Prompts.ExistingSectionMaxChars <= 0 {
+		c.Prompts.ExistingSectionMaxChars = 4000
+	}
+
+	if c.Prompts.FullDocMaxChars <= 0 {
+		c.Prompts.FullDocMaxChars = 12000
+	}
+
+	if c.Prompts.StyleGuideMaxChars <= 0 {
+		c.Prompts.StyleGuideMaxChars = 4000
+	}
+
+	if c.Runtime.LockTTLMinutes < 0 {
+		return fmt.Errorf("runtime.lock_ttl_minutes must not be negative, got %d", c.Runtime.LockTTLMinutes)
+	}
+	if c.Runtime.CommitTimeout < 0 {
+		return fmt.Errorf("runtime.commit_timeout must not be negative, got %d", c.Runtime.CommitTimeout)
+	}
+	if c.Runtime.RunDeadline < 0 {
+		return fmt.Errorf("runtime.run_deadline must not be negative, got %d", c.Runtime.RunDeadline)
+	}
+	if c.Runtime.LockTTLMinutes > 0 && c.Runtime.RunDeadline > 0 && c.Runtime.LockTTLMinutes*60 <= c.Runtime.RunDeadline {
+		return fmt.Errorf("runtime.lock_ttl_minutes (%d) must be longer than runtime.run_deadline (%d seconds), or a healthy run can be overridden", c.Runtime.LockTTLMinutes, c.Runtime.RunDeadline)
+	}
+	if strings.TrimSpace(c.Runtime.Schedule) != "" {
+		if _, err := schedule.Parse(c.Runtime.Schedule); err != nil {
+			return fmt.Errorf("runtime.schedule: %w", err)
+		}
+	}
+
+	if c.Prompts.Examples < 0 {
+		return fmt.Errorf("prompts.examples must not be negative, got %d", c.Prompts.Examples)
+	}
+	if c.Prompts.ExampleMaxChars <= 0 {
+		c.Prompts.ExampleMaxChars = 1500
+	}
+
+	if c.Diff.MaxChars <= 0 {
+		c.Diff.MaxChars = 6000
+	}
+	if c.Diff.MaxFileChars <= 0 {
+		c.Diff.MaxFileChars = 2000
+	}
+	if c.Diff.MaxFileLines < 0 {
+		return errors.New("diff.max_file_lines must not be negative")
+	}
+
 	if c.LLM.Timeout <= 0 {
 		c.LLM.Timeout = 60
 	}
@@ -162,20 +1335,355 @@ func (c *Config) Validate() error {
 		c.LLM.MaxRetries = 3
 	}
 
+	if c.LLM.CircuitBreakerThreshold == 0 {
+		c.LLM.CircuitBreakerThreshold = 5
+	}
+
+	if c.LLM.CircuitBreakerCooldown <= 0 {
+		c.LLM.CircuitBreakerCooldown = 300
+	}
+
 	return nil
 }
 
-func (c *Config) expandEnv() {
-	c.LLM.APIKey = os.ExpandEnv(c.LLM.APIKey)
-	c.State.DBPath = os.ExpandEnv(c.State.DBPath)
+// ResolvedProviders returns the ordered provider chain. When [[llm.providers]]
+// blocks are configured they define the chain and inherit unset fields from
+// [llm]; otherwise the primary provider is followed by fallback_providers,
+// which share the primary's key and model.
+func (l LLMConfig) ResolvedProviders() []ProviderConfig {
+	base := ProviderConfig{
+		Provider:          strings.ToLower(strings.TrimSpace(l.Provider)),
+		Model:             l.Model,
+		APIKey:            l.APIKey,
+		BaseURL:           l.BaseURL,
+		Timeout:           l.Timeout,
+		RequestsPerMinute: l.RequestsPerMinute,
+		TokensPerMinute:   l.TokensPerMinute,
+	}
 
-	for i := range c.DocFiles {
-		c.DocFiles[i] = os.ExpandEnv(c.DocFiles[i])
+	if len(l.Providers) == 0 {
+		out := []ProviderConfig{base}
+		seen := map[string]bool{base.Provider: true}
+		for _, fallback := range l.FallbackProviders {
+			name := strings.ToLower(strings.TrimSpace(fallback))
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			entry := base
+			entry.Provider = name
+			entry.BaseURL = ""
+			out = append(out, entry)
+		}
+		return out
+	}
+
+	out := make([]ProviderConfig, 0, len(l.Providers))
+	for _, block := range l.Providers {
+		out = append(out, l.inheritBlock(block))
+	}
+	return out
+}
+
+// inheritBlock fills the unset fields of a provider block from [llm].
+func (l LLMConfig) inheritBlock(block ProviderConfig) ProviderConfig {
+	entry := block
+	entry.Provider = strings.ToLower(strings.TrimSpace(block.Provider))
+	if strings.TrimSpace(entry.Model) == "" {
+		entry.Model = l.Model
+	}
+	if strings.TrimSpace(entry.APIKey) == "" && entry.Provider == strings.ToLower(strings.TrimSpace(l.Provider)) {
+		entry.APIKey = l.APIKey
+	}
+	if entry.Timeout <= 0 {
+		entry.Timeout = l.Timeout
+	}
+	if entry.RequestsPerMinute <= 0 {
+		entry.RequestsPerMinute = l.RequestsPerMinute
+	}
+	if entry.TokensPerMinute <= 0 {
+		entry.TokensPerMinute = l.TokensPerMinute
+	}
+	return entry
+}
+
+// ResolvedCandidates returns the [[llm.ensemble.candidates]] blocks with
+// unset fields inherited from [llm].
+func (l LLMConfig) ResolvedCandidates() []ProviderConfig {
+	out := make([]ProviderConfig, 0, len(l.Ensemble.Candidates))
+	for _, block := range l.Ensemble.Candidates {
+		out = append(out, l.inheritBlock(block))
+	}
+	return out
+}
+
+// ResolvedPlanner returns the provider settings for planning calls: the
+// primary provider with the [llm.planner] fields that are set applied over it.
+// The api_key is only inherited when the planner uses the same provider.
+func (l LLMConfig) ResolvedPlanner() ProviderConfig {
+	return l.overlayPrimary(l.Planner)
+}
+
+// ResolvedJudge returns the provider settings for ensemble judging, resolved
+// like the planner.
+func (l LLMConfig) ResolvedJudge() ProviderConfig {
+	return l.overlayPrimary(l.Ensemble.Judge)
+}
+
+// ResolvedMapping returns the provider settings for a mapping with an
+// [mappings.llm] override, resolved like the planner.
+func (l LLMConfig) ResolvedMapping(mapping Mapping) ProviderConfig {
+	return l.overlayPrimary(mapping.LLM)
+}
+
+func (l LLMConfig) overlayPrimary(override ProviderConfig) ProviderConfig {
+	out := l.ResolvedProviders()[0]
+	if name := strings.ToLower(strings.TrimSpace(override.Provider)); name != "" && name != out.Provider {
+		out = ProviderConfig{Provider: name, Model: out.Model, Timeout: out.Timeout}
+	}
+	if override.Model != "" {
+		out.Model = override.Model
+	}
+	if override.APIKey != "" {
+		out.APIKey = override.APIKey
+	}
+	if override.BaseURL != "" {
+		out.BaseURL = override.BaseURL
+	}
+	if override.Timeout > 0 {
+		out.Timeout = override.Timeout
+	}
+	if override.RequestsPerMinute > 0 {
+		out.RequestsPerMinute = override.RequestsPerMinute
+	}
+	if override.TokensPerMinute > 0 {
+		out.TokensPerMinute = override.TokensPerMinute
+	}
+	return out
+}
+
+// ResolvedEmbeddings returns the embeddings settings with unset fields taken
+// from [llm] and the provider's default embedding model.
+func (c *Config) ResolvedEmbeddings() EmbeddingsConfig {
+	out := c.Embeddings
+	out.Provider = strings.ToLower(strings.TrimSpace(out.Provider))
+	primary := strings.ToLower(strings.TrimSpace(c.LLM.Provider))
+	if out.Provider == "" {
+		out.Provider = primary
+	}
+	if out.Provider == primary {
+		if out.APIKey == "" {
+			out.APIKey = c.LLM.APIKey
+		}
+		if out.BaseURL == "" {
+			out.BaseURL = c.LLM.BaseURL
+		}
+	}
+	if out.Timeout <= 0 {
+		out.Timeout = c.LLM.Timeout
+	}
+	if out.Model == "" {
+		switch out.Provider {
+		case "openai":
+			out.Model = "text-embedding-3-small"
+		case "mistral":
+			out.Model = "mistral-embed"
+		case "ollama":
+			out.Model = "nomic-embed-text"
+		case "mock":
+			out.Model = "mock"
+		}
+	}
+	return out
+}
+
+// PlannerConfig returns a copy of c configured for planning calls. Planning
+// answers are small JSON objects, so structured section output is disabled.
+func (c *Config) PlannerConfig() *Config {
+	clone := c.WithProvider(c.LLM.ResolvedPlanner())
+	clone.LLM.StructuredOutput = false
+	return clone
+}
+
+// WithProvider returns a copy of the config whose [llm] settings describe a
+// single provider, so provider clients can be built from it directly.
+func (c *Config) WithProvider(p ProviderConfig) *Config {
+	clone := *c
+	clone.LLM.Provider = p.Provider
+	clone.LLM.Model = p.Model
+	clone.LLM.APIKey = p.APIKey
+	clone.LLM.BaseURL = p.BaseURL
+	if p.Timeout > 0 {
+		clone.LLM.Timeout = p.Timeout
+	}
+	clone.LLM.RequestsPerMinute = p.RequestsPerMinute
+	clone.LLM.TokensPerMinute = p.TokensPerMinute
+	clone.LLM.Providers = nil
+	clone.LLM.FallbackProviders = nil
+	return &clone
+}
+
+func validOwnerPolicy(policy string) bool {
+	switch policy {
+	case "auto", "require-approval", "never":
+		return true
 	}
+	return false
+}
 
+func validateProviderSettings(field string, p ProviderConfig) error {
+	if requiresAPIKey(p.Provider) && strings.TrimSpace(p.APIKey) == "" {
+		return fmt.Errorf("%s.api_key is required for %s provider", field, p.Provider)
+	}
+
+	if p.Provider == "openrouter" {
+		model := strings.TrimPrefix(strings.TrimSpace(p.Model), "openrouter:")
+		if model != "" && !strings.Contains(model, "/") {
+			return fmt.Errorf("%s.model %q for openrouter must include a routing prefix such as \"openai/gpt-4o-mini\"", field, p.Model)
+		}
+	}
+
+	return nil
+}
+
+func requiresAPIKey(provider string) bool {
+	switch provider {
+	case "openai", "anthropic", "google", "gemini", "groq", "mistral", "openrouter":
+		return true
+	default:
+		return false
+	}
+}
+
+type envField struct {
+	key   string
+	value *string
+}
+
+// envFields lists the settings that support ${VAR} expansion.
+func (c *Config) envFields() []envField {
+	fields := []envField{
+		{"llm.api_key", &c.LLM.APIKey},
+		{"llm.base_url", &c.LLM.BaseURL},
+	}
+	for i := range c.LLM.Providers {
+		prefix := fmt.Sprintf("llm.providers[%d].", i)
+		fields = append(fields,
+			envField{prefix + "api_key", &c.LLM.Providers[i].APIKey},
+			envField{prefix + "model", &c.LLM.Providers[i].Model},
+			envField{prefix + "base_url", &c.LLM.Providers[i].BaseURL},
+		)
+	}
+	fields = append(fields,
+		envField{"llm.planner.api_key", &c.LLM.Planner.APIKey},
+		envField{"llm.planner.model", &c.LLM.Planner.Model},
+		envField{"llm.planner.base_url", &c.LLM.Planner.BaseURL},
+	)
+	for i := range c.LLM.Ensemble.Candidates {
+		prefix := fmt.Sprintf("llm.ensemble.candidates[%d].", i)
+		fields = append(fields,
+			envField{prefix + "api_key", &c.LLM.Ensemble.Candidates[i].APIKey},
+			envField{prefix + "model", &c.LLM.Ensemble.Candidates[i].Model},
+			envField{prefix + "base_url", &c.LLM.Ensemble.Candidates[i].BaseURL},
+		)
+	}
+	fields = append(fields,
+		envField{"llm.ensemble.judge.api_key", &c.LLM.Ensemble.Judge.APIKey},
+		envField{"llm.ensemble.judge.model", &c.LLM.Ensemble.Judge.Model},
+		envField{"llm.ensemble.judge.base_url", &c.LLM.Ensemble.Judge.BaseURL},
+		envField{"embeddings.api_key", &c.Embeddings.APIKey},
+		envField{"embeddings.base_url", &c.Embeddings.BaseURL},
+		envField{"state.db_path", &c.State.DBPath},
+		envField{"state.auth_token", &c.State.AuthToken},
+		envField{"state.encryption_key", &c.State.EncryptionKey},
+		envField{"cache.dir", &c.Cache.Dir},
+		envField{"git.committer_name", &c.Git.CommitterName},
+		envField{"git.committer_email", &c.Git.CommitterEmail},
+		envField{"git.signing_key", &c.Git.SigningKey},
+		envField{"prompts.dir", &c.Prompts.Dir},
+		envField{"prompts.default_template", &c.Prompts.DefaultTemplate},
+		envField{"prompts.style_guide", &c.Prompts.StyleGuide},
+		envField{"changelog.file", &c.Changelog.File},
+		envField{"status_block.file", &c.StatusBlock.File},
+		envField{"tracing.endpoint", &c.Tracing.Endpoint},
+		envField{"notifications.commit_url", &c.Notifications.CommitURL},
+		envField{"notifications.email.smtp_host", &c.Notifications.Email.SMTPHost},
+		envField{"notifications.email.username", &c.Notifications.Email.Username},
+		envField{"notifications.email.password", &c.Notifications.Email.Password},
+	)
+	for i := range c.Notifications.Webhooks {
+		fields = append(fields, envField{fmt.Sprintf("notifications.webhooks[%d].url", i), &c.Notifications.Webhooks[i].URL})
+	}
+	for i := range c.Translations.Locales {
+		prefix := fmt.Sprintf("translations.locales[%d].", i)
+		fields = append(fields,
+			envField{prefix + "source", &c.Translations.Locales[i].Source},
+			envField{prefix + "doc_file", &c.Translations.Locales[i].DocFile},
+		)
+	}
+	for i := range c.DocFiles {
+		fields = append(fields, envField{fmt.Sprintf("doc_files[%d]", i), &c.DocFiles[i]})
+	}
 	for i := range c.Mappings {
-		c.Mappings[i].CodePattern = os.ExpandEnv(c.Mappings[i].CodePattern)
-		c.Mappings[i].DocFile = os.ExpandEnv(c.Mappings[i].DocFile)
-		c.Mappings[i].Section = os.ExpandEnv(c.Mappings[i].Section)
+		prefix := fmt.Sprintf("mappings[%d].", i)
+		fields = append(fields,
+			envField{prefix + "code_pattern", &c.Mappings[i].CodePattern},
+			envField{prefix + "doc_file", &c.Mappings[i].DocFile},
+			envField{prefix + "section", &c.Mappings[i].Section},
+			envField{prefix + "prompt_template", &c.Mappings[i].PromptTemplate},
+			envField{prefix + "doc_template", &c.Mappings[i].DocTemplate},
+			envField{prefix + "render", &c.Mappings[i].Render},
+			envField{prefix + "llm.api_key", &c.Mappings[i].LLM.APIKey},
+			envField{prefix + "llm.model", &c.Mappings[i].LLM.Model},
+			envField{prefix + "llm.base_url", &c.Mappings[i].LLM.BaseURL},
+		)
 	}
+	return fields
+}
+
+func (c *Config) expandEnv() {
+	for _, field := range c.envFields() {
+		*field.value = os.ExpandEnv(*field.value)
+	}
+	for key, value := range c.Tracing.Headers {
+		c.Tracing.Headers[key] = os.ExpandEnv(value)
+	}
+}
+
+var docFilePlaceholder = regexp.MustCompile(`\{([1-9])\}`)
+
+// MaxDocFilePlaceholder returns the highest {N} placeholder in a mapping's
+// doc_file, or 0 when it has none.
+func MaxDocFilePlaceholder(docFile string) int {
+	max := 0
+	for _, match := range docFilePlaceholder.FindAllStringSubmatch(docFile, -1) {
+		if n := int(match[1][0] - '0'); n > max {
+			max = n
+		}
+	}
+	return max
+}
+
+// PatternWildcards counts the code_pattern segments other than "**" that
+// contain a wildcard; {1}, {2}... in doc_file name what they matched.
+func PatternWildcards(pattern string) int {
+	count := 0
+	for _, part := range strings.Split(filepath.ToSlash(strings.TrimSpace(pattern)), "/") {
+		if part != "**" && strings.ContainsAny(part, "*?[") {
+			count++
+		}
+	}
+	return count
+}
+
+// ExpandDocFile substitutes {N} placeholders in doc_file with the captures
+// returned for the matched path.
+func ExpandDocFile(docFile string, captures []string) string {
+	return docFilePlaceholder.ReplaceAllStringFunc(docFile, func(m string) string {
+		n := int(m[1] - '0')
+		if n <= len(captures) {
+			return captures[n-1]
+		}
+		return m
+	})
 }
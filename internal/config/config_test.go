@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -92,3 +93,698 @@ func TestDefaultTomlAllowsTopLevelDocFilesOverride(t *testing.T) {
 		t.Fatalf("expected top-level doc_files override to be loaded, got %#v", cfg.DocFiles)
 	}
 }
+
+func TestLoadConfigRejectsInvalidIgnoreMessagePattern(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.toml")
+	content := DefaultToml() + "\n"
+	content = strings.Replace(content, `messages = ['\[skip docs\]']`, `messages = ['([unclosed']`, 1)
+
+	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(configPath); err == nil {
+		t.Fatalf("expected load to fail for invalid ignore.messages regex")
+	}
+}
+
+func TestLoadConfigCompilesIgnoreMessagePatternsOnce(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.toml")
+	if err := os.WriteFile(configPath, []byte(DefaultToml()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := cfg.Ignore.MessagePatterns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := cfg.Ignore.MessagePatterns()
+	if len(first) != 1 || first[0] != second[0] {
+		t.Fatalf("expected the loaded ignore.messages pattern to be compiled once, got %v and %v", first, second)
+	}
+	if !first[0].MatchString("fix: typo [skip docs]") {
+		t.Fatalf("expected %q to match [skip docs]", first[0])
+	}
+}
+
+func TestValidateOpenRouterRequiresRoutingPrefix(t *testing.T) {
+	cfg := Default()
+	cfg.LLM.Provider = "openrouter"
+	cfg.LLM.APIKey = "key"
+	cfg.LLM.Model = "gpt-4o-mini"
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected openrouter model without routing prefix to fail validation")
+	}
+
+	cfg.LLM.Model = "openai/gpt-4o-mini"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected routed openrouter model to validate, got %v", err)
+	}
+}
+
+func TestLoadConfigWithProviderBlocks(t *testing.T) {
+	t.Setenv("GITDOC_TEST_OPENAI", "sk-openai")
+	t.Setenv("GITDOC_TEST_ANTHROPIC", "sk-anthropic")
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.toml")
+	content := `
+[llm]
+provider = "openai"
+api_key = "${GITDOC_TEST_OPENAI}"
+model = "gpt-4o-mini"
+timeout = 30
+failover_enabled = true
+
+[[llm.providers]]
+provider = "openai"
+
+[[llm.providers]]
+provider = "anthropic"
+model = "claude-3-5-haiku-latest"
+api_key = "${GITDOC_TEST_ANTHROPIC}"
+base_url = "https://proxy.example.com/v1"
+timeout = 90
+
+[state]
+db_path = ".git-doc/state.db"
+`
+
+	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("expected config to load, got error: %v", err)
+	}
+
+	chain := cfg.LLM.ResolvedProviders()
+	if len(chain) != 2 {
+		t.Fatalf("expected 2 providers, got %#v", chain)
+	}
+	if chain[0].Provider != "openai" || chain[0].APIKey != "sk-openai" || chain[0].Model != "gpt-4o-mini" || chain[0].Timeout != 30 {
+		t.Fatalf("expected first block to inherit [llm] settings, got %#v", chain[0])
+	}
+	if chain[1].Provider != "anthropic" || chain[1].APIKey != "sk-anthropic" || chain[1].Model != "claude-3-5-haiku-latest" || chain[1].BaseURL != "https://proxy.example.com/v1" || chain[1].Timeout != 90 {
+		t.Fatalf("expected second block to keep its own settings, got %#v", chain[1])
+	}
+}
+
+func TestValidateProviderBlockRequiresOwnAPIKey(t *testing.T) {
+	cfg := Default()
+	cfg.LLM.Provider = "openai"
+	cfg.LLM.APIKey = "root-key"
+	cfg.LLM.APIKey = "sk-openai"
+	cfg.LLM.Providers = []ProviderConfig{{Provider: "openai"}, {Provider: "anthropic"}}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected anthropic block without api_key to fail validation")
+	}
+}
+
+func TestValidateRejectsOutOfRangeGenerationParams(t *testing.T) {
+	cfg := Default()
+	temperature := 2.5
+	cfg.LLM.Temperature = &temperature
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "llm.temperature") {
+		t.Fatalf("expected temperature validation error, got %v", err)
+	}
+
+	cfg = Default()
+	topP := 1.5
+	cfg.LLM.TopP = &topP
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "llm.top_p") {
+		t.Fatalf("expected top_p validation error, got %v", err)
+	}
+}
+
+func TestValidateLockTTLMustOutlastRunDeadline(t *testing.T) {
+	cfg := Default()
+	cfg.Runtime.LockTTLMinutes = 10
+	cfg.Runtime.RunDeadline = 600
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "runtime.lock_ttl_minutes") {
+		t.Fatalf("expected lock ttl validation error, got %v", err)
+	}
+
+	cfg.Runtime.RunDeadline = 540
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected a deadline below the ttl to validate, got %v", err)
+	}
+	cfg.Runtime.RunDeadline = 0
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected no deadline to validate, got %v", err)
+	}
+}
+
+func TestCheckReportsAllProblems(t *testing.T) {
+	repoRoot := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repoRoot, "README.md"), []byte("# Readme\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	configPath := filepath.Join(repoRoot, "config.toml")
+	content := `
+version = 1
+doc_files = ["README.md", "docs/missing.md"]
+
+[llm]
+provider = "mock"
+api_key = "${GITDOC_CHECK_UNSET_KEY}"
+temprature = 0.2
+
+[[mappings]]
+code_pattern = "src/[api/**"
+doc_file = "docs/api.md"
+section = "API"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := Check(configPath, repoRoot)
+	if err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if !HasErrors(issues) {
+		t.Fatalf("expected errors, got %v", issues)
+	}
+
+	want := []string{
+		"error: llm.temprature: unknown key",
+		"warning: llm.api_key: environment variable GITDOC_CHECK_UNSET_KEY is not set",
+		"warning: doc_files[1]: doc file docs/missing.md does not exist",
+		"error: mappings[0].code_pattern: invalid glob pattern",
+		"error: mappings[0].doc_file: doc file docs/api.md does not exist",
+	}
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	joined := strings.Join(got, "\n")
+	for _, w := range want {
+		if !strings.Contains(joined, w) {
+			t.Fatalf("expected issue %q in:\n%s", w, joined)
+		}
+	}
+}
+
+func TestValidateRejectsNewerConfigVersion(t *testing.T) {
+	cfg := Default()
+	cfg.Version = SchemaVersion + 1
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "unsupported config version") {
+		t.Fatalf("expected version error, got %v", err)
+	}
+}
+
+func TestSetValuePreservesComments(t *testing.T) {
+	content := DefaultToml()
+
+	updated, err := SetValue(content, "llm.provider", "openai")
+	if err != nil {
+		t.Fatalf("set provider: %v", err)
+	}
+	updated, err = SetValue(updated, "git.commit_doc_updates", "false")
+	if err != nil {
+		t.Fatalf("set bool: %v", err)
+	}
+	updated, err = SetValue(updated, "llm.temperature", "0.3")
+	if err != nil {
+		t.Fatalf("set commented key: %v", err)
+	}
+	updated, err = SetValue(updated, "ignore.paths", "vendor/**, go.sum")
+	if err != nil {
+		t.Fatalf("set list: %v", err)
+	}
+
+	for _, want := range []string{
+		"provider = \"openai\"",
+		"commit_doc_updates = false",
+		"temperature = 0.3",
+		"paths = [\"vendor/**\", \"go.sum\"]",
+		"# Generation parameters passed to every provider",
+		"# Merge commits: \"skip\"",
+	} {
+		if !strings.Contains(updated, want) {
+			t.Fatalf("expected %q in updated config:\n%s", want, updated)
+		}
+	}
+	if strings.Count(updated, "\n") != strings.Count(content, "\n") {
+		t.Fatalf("expected edits in place without adding lines")
+	}
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"llm.provider":           "openai",
+		"git.commit_doc_updates": "false",
+		"llm.temperature":        "0.3",
+		"ignore.paths":           "[\"vendor/**\", \"go.sum\"]",
+	} {
+		got, err := Get(path, key)
+		if err != nil {
+			t.Fatalf("get %s: %v", key, err)
+		}
+		if got != want {
+			t.Fatalf("get %s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestSetValueRejectsUnknownKeysAndBadValues(t *testing.T) {
+	if _, err := SetValue(DefaultToml(), "llm.nope", "x"); err == nil {
+		t.Fatalf("expected unknown key to fail")
+	}
+	if _, err := SetValue(DefaultToml(), "llm.timeout", "soon"); err == nil {
+		t.Fatalf("expected non-integer timeout to fail")
+	}
+	if _, err := SetValue(DefaultToml(), "llm.providers", "x"); err == nil {
+		t.Fatalf("expected array of tables to be rejected")
+	}
+
+	updated, err := SetValue("[llm]\nprovider = \"mock\" # primary\n", "runtime.batch_commits", "true")
+	if err != nil {
+		t.Fatalf("set in missing table: %v", err)
+	}
+	if !strings.Contains(updated, "[runtime]\nbatch_commits = true") || !strings.Contains(updated, "# primary") {
+		t.Fatalf("expected new table to be appended, got:\n%s", updated)
+	}
+
+	updated, err = SetValue(updated, "llm.provider", "ollama")
+	if err != nil {
+		t.Fatalf("set provider: %v", err)
+	}
+	if !strings.Contains(updated, "provider = \"ollama\" # primary") {
+		t.Fatalf("expected trailing comment to be kept, got:\n%s", updated)
+	}
+}
+
+func TestLoadWorkspacesMergesNestedConfig(t *testing.T) {
+	repoRoot := t.TempDir()
+	wsDir := filepath.Join(repoRoot, "services", "billing", ".git-doc")
+	if err := os.MkdirAll(wsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	nested := `
+[llm]
+model = "billing-model"
+
+[[mappings]]
+code_pattern = "src/**"
+doc_file = "docs/billing.md"
+section = "Billing"
+
+[git]
+commit_doc_updates = false
+`
+	if err := os.WriteFile(filepath.Join(wsDir, "config.toml"), []byte(nested), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Default()
+	cfg.LLM.Provider = "openai"
+	cfg.LLM.APIKey = "root-key"
+	cfg.Mappings = []Mapping{{CodePattern: "cmd/**", DocFile: "README.md"}}
+	cfg.Workspaces = []Workspace{{Path: "./services/billing/"}}
+
+	if err := cfg.LoadWorkspaces(repoRoot); err != nil {
+		t.Fatalf("load workspaces: %v", err)
+	}
+
+	ws := cfg.Workspaces[0]
+	if ws.Path != "services/billing" {
+		t.Fatalf("expected cleaned workspace path, got %q", ws.Path)
+	}
+	if ws.Resolved.LLM.Provider != "openai" || ws.Resolved.LLM.Model != "billing-model" {
+		t.Fatalf("expected nested llm to inherit provider and override model, got %+v", ws.Resolved.LLM)
+	}
+	if ws.Resolved.Git.CommitDocUpdates || !cfg.Git.CommitDocUpdates {
+		t.Fatalf("expected git override to apply only to the workspace")
+	}
+	if len(ws.Resolved.Mappings) != 1 || ws.Resolved.Mappings[0].CodePattern != "services/billing/src/**" || ws.Resolved.Mappings[0].DocFile != "services/billing/docs/billing.md" {
+		t.Fatalf("expected workspace mappings to be prefixed, got %+v", ws.Resolved.Mappings)
+	}
+	if len(cfg.Mappings) != 2 || cfg.Mappings[0].DocFile != "README.md" || cfg.Mappings[1].Section != "Billing" {
+		t.Fatalf("expected workspace mappings appended to root mappings, got %+v", cfg.Mappings)
+	}
+
+	if got := cfg.WorkspaceFor([]string{"services/billing/src/a.go", "services/billing/go.mod"}); got == nil || got.Path != "services/billing" {
+		t.Fatalf("expected billing workspace, got %+v", got)
+	}
+	if got := cfg.WorkspaceFor([]string{"services/billing/src/a.go", "cmd/main.go"}); got != nil {
+		t.Fatalf("expected no workspace for cross-workspace change, got %+v", got)
+	}
+}
+
+func TestLoadWorkspacesDoesNotInheritRootTableFields(t *testing.T) {
+	repoRoot := t.TempDir()
+	wsDir := filepath.Join(repoRoot, "services", "billing", ".git-doc")
+	if err := os.MkdirAll(wsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	nested := `
+[[mappings]]
+code_pattern = "src/**"
+doc_file = "docs/billing.md"
+section = "Billing"
+
+[[llm.providers]]
+provider = "ollama"
+model = "llama3"
+`
+	if err := os.WriteFile(filepath.Join(wsDir, "config.toml"), []byte(nested), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Default()
+	cfg.LLM.Provider = "openai"
+	cfg.LLM.APIKey = "root-key"
+	cfg.Mappings = []Mapping{{CodePattern: "api/**", DocFile: "docs/api.md", Section: "API", Type: "feat", Scope: "api"}}
+	cfg.LLM.Providers = []ProviderConfig{{Provider: "anthropic", Model: "claude", APIKey: "root-anthropic", BaseURL: "https://proxy.example"}}
+	cfg.Workspaces = []Workspace{{Path: "services/billing"}}
+
+	if err := cfg.LoadWorkspaces(repoRoot); err != nil {
+		t.Fatalf("load workspaces: %v", err)
+	}
+
+	ws := cfg.Workspaces[0].Resolved
+	if got := ws.Mappings[0]; got.Type != "" || got.Scope != "" || got.CodePattern != "services/billing/src/**" {
+		t.Fatalf("expected the workspace mapping not to inherit the root mapping's fields, got %+v", got)
+	}
+	if got := cfg.Mappings[1]; got.Type != "" || got.Scope != "" {
+		t.Fatalf("expected the appended workspace mapping to route every commit type, got %+v", got)
+	}
+	if got := ws.LLM.Providers[0]; got.Provider != "ollama" || got.APIKey != "" || got.BaseURL != "" {
+		t.Fatalf("expected the workspace provider not to inherit the root provider's fields, got %+v", got)
+	}
+	if cfg.Mappings[0].Type != "feat" || cfg.LLM.Providers[0].Provider != "anthropic" {
+		t.Fatalf("expected the root config to be unchanged, got %+v and %+v", cfg.Mappings[0], cfg.LLM.Providers[0])
+	}
+}
+
+func TestLoadWorkspacesRejectsMissingConfig(t *testing.T) {
+	cfg := Default()
+	cfg.Workspaces = []Workspace{{Path: "missing"}}
+	if err := cfg.LoadWorkspaces(t.TempDir()); err == nil || !strings.Contains(err.Error(), "workspaces[0] (missing)") {
+		t.Fatalf("expected missing workspace config error, got %v", err)
+	}
+}
+
+func TestLoadWorkspacesRejectsCommandSecrets(t *testing.T) {
+	repoRoot := t.TempDir()
+	marker := filepath.Join(repoRoot, "ran")
+	wsDir := filepath.Join(repoRoot, "services", "billing", ".git-doc")
+	if err := os.MkdirAll(wsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	nested := "[llm]\napi_key = 'exec:touch " + marker + " && echo key'\n"
+	if err := os.WriteFile(filepath.Join(wsDir, "config.toml"), []byte(nested), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Default()
+	cfg.Workspaces = []Workspace{{Path: "services/billing"}}
+	err := cfg.LoadWorkspaces(repoRoot)
+	if err == nil || !strings.Contains(err.Error(), "llm.api_key: exec: secrets that run commands are only allowed in the root config") {
+		t.Fatalf("expected the workspace exec: secret to be rejected, got %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatalf("expected the workspace secret command not to run")
+	}
+}
+
+func TestLoadProfileOverridesBaseConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.toml")
+	content := `
+[llm]
+provider = "mock"
+model = "gpt-4o-mini"
+
+[git]
+commit_doc_updates = true
+
+[profiles.hook.llm]
+model = "local-small"
+
+[profiles.ci.runtime]
+dry_run = true
+
+[profiles.ci.git]
+commit_doc_updates = false
+`
+	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	base, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("load base: %v", err)
+	}
+	if base.LLM.Model != "gpt-4o-mini" || base.Runtime.DryRun || !base.Git.CommitDocUpdates {
+		t.Fatalf("expected base config without profile overrides, got %+v", base)
+	}
+
+	hook, err := LoadProfile(configPath, "hook")
+	if err != nil {
+		t.Fatalf("load hook profile: %v", err)
+	}
+	if hook.LLM.Model != "local-small" || hook.LLM.Provider != "mock" || !hook.Git.CommitDocUpdates {
+		t.Fatalf("expected hook profile to override only the model, got %+v", hook.LLM)
+	}
+
+	ci, err := LoadProfile(configPath, "ci")
+	if err != nil {
+		t.Fatalf("load ci profile: %v", err)
+	}
+	if !ci.Runtime.DryRun || ci.Git.CommitDocUpdates {
+		t.Fatalf("expected ci profile to enable dry-run and disable commits, got %+v %+v", ci.Runtime, ci.Git)
+	}
+
+	if _, err := LoadProfile(configPath, "nightly"); err == nil || !strings.Contains(err.Error(), "defined: ci, hook") {
+		t.Fatalf("expected unknown profile error listing profiles, got %v", err)
+	}
+
+	issues, err := Check(configPath, tmpDir)
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if HasErrors(issues) {
+		t.Fatalf("expected profile keys to validate, got %v", issues)
+	}
+}
+
+func TestLoadConfigResolvesSecretReferences(t *testing.T) {
+	tmpDir := t.TempDir()
+	keyPath := filepath.Join(tmpDir, "key")
+	if err := os.WriteFile(keyPath, []byte("file-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	configPath := filepath.Join(tmpDir, "config.toml")
+	content := `
+[llm]
+provider = "openai"
+api_key = "file:` + keyPath + `"
+model = "gpt-4o-mini"
+fallback_providers = ["anthropic"]
+
+[[llm.providers]]
+provider = "anthropic"
+api_key = "exec:printf 'exec-secret'"
+model = "claude-3-5-haiku-latest"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("expected config to load, got error: %v", err)
+	}
+	if cfg.LLM.APIKey != "file-secret" {
+		t.Fatalf("expected file secret, got %q", cfg.LLM.APIKey)
+	}
+	if cfg.LLM.Providers[0].APIKey != "exec-secret" {
+		t.Fatalf("expected exec secret, got %q", cfg.LLM.Providers[0].APIKey)
+	}
+}
+
+func TestResolveSecretReportsFailures(t *testing.T) {
+	if _, err := ResolveSecret("file:" + filepath.Join(t.TempDir(), "missing")); err == nil || !strings.Contains(err.Error(), "file: read secret") {
+		t.Fatalf("expected missing file error, got %v", err)
+	}
+	if _, err := ResolveSecret("exec:echo oops >&2; exit 3"); err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Fatalf("expected command stderr in error, got %v", err)
+	}
+	if got, err := ResolveSecret("sk-plain"); err != nil || got != "sk-plain" {
+		t.Fatalf("expected plain value unchanged, got %q, %v", got, err)
+	}
+}
+
+func TestCheckDoesNotRunSecretCommands(t *testing.T) {
+	repoRoot := t.TempDir()
+	marker := filepath.Join(repoRoot, "ran")
+	configPath := filepath.Join(repoRoot, "config.toml")
+	content := `
+[llm]
+provider = "openai"
+api_key = 'exec:touch ` + marker + ` && echo key'
+model = "gpt-4o-mini"
+
+[profiles.hook.llm]
+api_key = 'exec:touch ` + marker + ` && echo hook-key'
+
+[embeddings]
+api_key = "exec:"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := Check(configPath, repoRoot)
+	if err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatalf("expected config validation not to run secret commands")
+	}
+	want := Issue{Level: "error", Key: "embeddings.api_key", Message: "exec: secret command is empty"}
+	if !slices.Contains(issues, want) {
+		t.Fatalf("expected %v among %v", want, issues)
+	}
+}
+
+func TestResolvedPlannerInheritsPrimaryProvider(t *testing.T) {
+	cfg := Default()
+	cfg.LLM.Provider = "openai"
+	cfg.LLM.APIKey = "openai-key"
+	cfg.LLM.Model = "gpt-4o"
+	cfg.LLM.PlanTargets = true
+	cfg.LLM.Planner = ProviderConfig{Model: "gpt-4o-mini"}
+
+	planner := cfg.LLM.ResolvedPlanner()
+	if planner.Provider != "openai" || planner.Model != "gpt-4o-mini" || planner.APIKey != "openai-key" {
+		t.Fatalf("unexpected planner settings: %+v", planner)
+	}
+	if got := cfg.PlannerConfig(); got.LLM.Model != "gpt-4o-mini" || got.LLM.StructuredOutput || cfg.LLM.Model != "gpt-4o" {
+		t.Fatalf("unexpected planner config: %+v", got.LLM)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected config to validate, got %v", err)
+	}
+
+	cfg.LLM.Planner = ProviderConfig{Provider: "anthropic", Model: "claude-3-5-haiku-latest"}
+	if planner := cfg.LLM.ResolvedPlanner(); planner.APIKey != "" {
+		t.Fatalf("expected api_key not to be inherited across providers, got %+v", planner)
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "llm.planner.api_key") {
+		t.Fatalf("expected missing planner api_key error, got %v", err)
+	}
+}
+
+func TestValidateDocFilePlaceholders(t *testing.T) {
+	cfg := Default()
+	cfg.Mappings = []Mapping{{CodePattern: "internal/*/**", DocFile: "docs/{1}/{2}.md", CreateDoc: true}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "{2}") {
+		t.Fatalf("expected placeholder without a wildcard to fail validation, got %v", err)
+	}
+
+	cfg.Mappings[0].DocFile = "docs/{1}.md"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid placeholder, got %v", err)
+	}
+	if got := ExpandDocFile(cfg.Mappings[0].DocFile, []string{"billing"}); got != "docs/billing.md" {
+		t.Fatalf("unexpected expansion %q", got)
+	}
+
+	cfg.Mappings[0].Render = "plantuml"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "render") {
+		t.Fatalf("expected unknown render to fail validation, got %v", err)
+	}
+}
+
+func TestValidateTranslations(t *testing.T) {
+	cfg := Default()
+	cfg.Translations = TranslationsConfig{Enabled: true, Locales: []TranslationLocale{{Source: "README.md", DocFile: "README.md", Language: "Spanish"}}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "must differ") {
+		t.Fatalf("expected a locale translating itself to fail validation, got %v", err)
+	}
+
+	cfg.Translations.Locales[0].DocFile = "README.es.md"
+	cfg.Translations.Locales = append(cfg.Translations.Locales, TranslationLocale{Source: "README.md", DocFile: "README.zh.md"})
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "locales[1]") {
+		t.Fatalf("expected a locale without language to fail validation, got %v", err)
+	}
+
+	cfg.Translations.Locales[1].Language = "Simplified Chinese"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid translations, got %v", err)
+	}
+}
+
+func TestValidateEnsembleMappingNeedsCandidates(t *testing.T) {
+	cfg := Default()
+	cfg.LLM.Provider = "openai"
+	cfg.LLM.APIKey = "openai-key"
+	cfg.LLM.Model = "gpt-4o"
+	cfg.Mappings = []Mapping{{CodePattern: "src/**", DocFile: "README.md", Ensemble: true}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "ensemble") {
+		t.Fatalf("expected ensemble without candidates to fail validation, got %v", err)
+	}
+
+	cfg.LLM.Ensemble.Candidates = []ProviderConfig{{Provider: "openai", Model: "gpt-4o-mini"}, {Provider: "anthropic"}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "llm.ensemble.candidates[1].api_key") {
+		t.Fatalf("expected missing candidate api_key error, got %v", err)
+	}
+
+	cfg.LLM.Ensemble.Candidates[1].APIKey = "anthropic-key"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected config to validate, got %v", err)
+	}
+	candidates := cfg.LLM.ResolvedCandidates()
+	if candidates[0].APIKey != "openai-key" || candidates[1].Model != "gpt-4o" {
+		t.Fatalf("expected candidates to inherit from [llm], got %+v", candidates)
+	}
+	if judge := cfg.LLM.ResolvedJudge(); judge.Provider != "openai" || judge.Model != "gpt-4o" {
+		t.Fatalf("expected the judge to default to the primary provider, got %+v", judge)
+	}
+}
+
+func TestValidateMappingLLMOverride(t *testing.T) {
+	cfg := Default()
+	cfg.LLM.Provider = "openai"
+	cfg.LLM.APIKey = "openai-key"
+	cfg.LLM.Model = "gpt-4o-mini"
+	cfg.Mappings = []Mapping{{CodePattern: "internal/**", DocFile: "docs/architecture.md", LLM: ProviderConfig{Model: "gpt-4o"}}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected a model-only override to validate, got %v", err)
+	}
+	if p := cfg.LLM.ResolvedMapping(cfg.Mappings[0]); p.Provider != "openai" || p.Model != "gpt-4o" || p.APIKey != "openai-key" {
+		t.Fatalf("expected the override to inherit from [llm], got %+v", p)
+	}
+
+	cfg.Mappings[0].LLM = ProviderConfig{Provider: "anthropic", Model: "claude-3-5-sonnet-latest"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "mappings[0].llm.api_key") {
+		t.Fatalf("expected missing override api_key error, got %v", err)
+	}
+	cfg.Mappings[0].LLM.Provider = "unknown"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "unsupported mappings[0].llm.provider") {
+		t.Fatalf("expected unsupported provider error, got %v", err)
+	}
+	cfg.Mappings[0].LLM = ProviderConfig{Model: "gpt-4o"}
+	cfg.Mappings[0].Ensemble = true
+	cfg.LLM.Ensemble.Candidates = []ProviderConfig{{Provider: "openai"}, {Provider: "openai", Model: "gpt-4o"}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "cannot be combined with ensemble") {
+		t.Fatalf("expected llm and ensemble to conflict, got %v", err)
+	}
+}
@@ -0,0 +1,373 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+var (
+	tableHeaderPattern = regexp.MustCompile(`^\s*\[\s*([A-Za-z0-9_.-]+)\s*\]\s*(#.*)?$`)
+	arrayHeaderPattern = regexp.MustCompile(`^\s*\[\[\s*([A-Za-z0-9_.-]+)\s*\]\]\s*(#.*)?$`)
+	keyLinePattern     = regexp.MustCompile(`^(\s*)([A-Za-z0-9_-]+)(\s*=\s*)`)
+	commentedKeyLine   = regexp.MustCompile(`^(\s*)#\s*([A-Za-z0-9_-]+)(\s*=\s*)`)
+)
+
+// Get returns the configured value of a dotted key such as "llm.provider",
+// read from the file without environment expansion so secrets referenced as
+// ${VAR} are not printed.
+func Get(path, key string) (string, error) {
+	cfg := Default()
+	if _, err := toml.DecodeFile(path, cfg); err != nil {
+		return "", fmt.Errorf("parse config: %w", err)
+	}
+
+	value, err := lookupField(reflect.ValueOf(cfg).Elem(), key)
+	if err != nil {
+		return "", err
+	}
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return "", nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.String {
+		return value.String(), nil
+	}
+	return encodeValue(value.Interface())
+}
+
+// Set writes value for a dotted key into the config file at path. Only the
+// edited line changes, so comments and layout are preserved; a commented-out
+// "# key = ..." line is reused when the key is not set yet.
+func Set(path, key, value string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("config file %s not found: %w", path, err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	updated, err := SetValue(string(raw), key, value)
+	if err != nil {
+		return err
+	}
+	return writeValidated(path, info.Mode().Perm(), updated, "set "+key)
+}
+
+// AppendMappings adds mappings to the end of the config file at path as
+// [[mappings]] tables; the rest of the file is left as it is.
+func AppendMappings(path string, mappings []Mapping) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("config file %s not found: %w", path, err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	updated, err := AddMappings(string(raw), mappings)
+	if err != nil {
+		return err
+	}
+	return writeValidated(path, info.Mode().Perm(), updated, "add mappings")
+}
+
+// AddMappings returns content with mappings appended as [[mappings]] tables.
+// Only the code_pattern, type, scope, doc_file and section keys are written,
+// and only when set.
+func AddMappings(content string, mappings []Mapping) (string, error) {
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(content, "\n"))
+	b.WriteString("\n")
+	for _, mapping := range mappings {
+		b.WriteString("\n[[mappings]]\n")
+		for _, kv := range [][2]string{
+			{"code_pattern", mapping.CodePattern},
+			{"type", mapping.Type},
+			{"scope", mapping.Scope},
+			{"doc_file", mapping.DocFile},
+			{"section", mapping.Section},
+		} {
+			if kv[1] == "" {
+				continue
+			}
+			literal, err := encodeValue(kv[1])
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&b, "%s = %s\n", kv[0], literal)
+		}
+	}
+	return b.String(), nil
+}
+
+// writeValidated writes content to path after checking it decodes and
+// validates; action prefixes the error otherwise.
+func writeValidated(path string, perm os.FileMode, content, action string) error {
+	cfg := Default()
+	if _, err := toml.Decode(content, cfg); err != nil {
+		return fmt.Errorf("%s: %w", action, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", action, err)
+	}
+	return os.WriteFile(path, []byte(content), perm)
+}
+
+// SetValue returns content with key set to value, encoded according to the
+// key's type in the config schema.
+func SetValue(content, key, value string) (string, error) {
+	field, err := lookupField(reflect.ValueOf(Default()).Elem(), key)
+	if err != nil {
+		return "", err
+	}
+	literal, err := formatValue(field.Type(), value)
+	if err != nil {
+		return "", fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+
+	table, name := "", key
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		table, name = key[:i], key[i+1:]
+	}
+
+	lines := strings.Split(content, "\n")
+	start, end, found := tableBounds(lines, table)
+	if !found {
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+		lines = append(lines, "", "["+table+"]", name+" = "+literal, "")
+		return strings.Join(lines, "\n"), nil
+	}
+
+	commented := -1
+	lastKey := -1
+	for i := start; i < end; i++ {
+		if match := keyLinePattern.FindStringSubmatch(lines[i]); match != nil {
+			lastKey = valueEnd(lines, i)
+			if match[2] != name {
+				continue
+			}
+			last := valueEnd(lines, i)
+			comment := trailingComment(lines[last])
+			lines[i] = match[1] + name + match[3] + literal + comment
+			lines = append(lines[:i+1], lines[last+1:]...)
+			return strings.Join(lines, "\n"), nil
+		}
+		if match := commentedKeyLine.FindStringSubmatch(lines[i]); match != nil && match[2] == name && commented < 0 {
+			commented = i
+		}
+	}
+
+	if commented >= 0 {
+		match := commentedKeyLine.FindStringSubmatch(lines[commented])
+		lines[commented] = match[1] + name + " = " + literal
+		return strings.Join(lines, "\n"), nil
+	}
+
+	insertAt := start
+	if lastKey >= 0 {
+		insertAt = lastKey + 1
+	}
+	lines = append(lines[:insertAt], append([]string{name + " = " + literal}, lines[insertAt:]...)...)
+	return strings.Join(lines, "\n"), nil
+}
+
+// lookupField resolves a dotted key to the Config field with matching toml
+// tags. Arrays of tables such as llm.providers cannot be addressed.
+func lookupField(v reflect.Value, key string) (reflect.Value, error) {
+	parts := strings.Split(key, ".")
+	current := v
+	for i, part := range parts {
+		if current.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("unknown config key %s", key)
+		}
+		next, ok := fieldByTag(current, part)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("unknown config key %s", key)
+		}
+		if next.Kind() == reflect.Slice && next.Type().Elem().Kind() == reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("%s is an array of tables; edit it with config --edit", strings.Join(parts[:i+1], "."))
+		}
+		current = next
+	}
+	if current.Kind() == reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("%s is a table; set one of its keys instead", key)
+	}
+	return current, nil
+}
+
+func fieldByTag(v reflect.Value, tag string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("toml"), ",")
+		if name == tag {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func formatValue(t reflect.Type, value string) (string, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var typed any
+	switch t.Kind() {
+	case reflect.String:
+		typed = value
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return "", err
+		}
+		typed = b
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return "", err
+		}
+		typed = n
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return "", err
+		}
+		typed = f
+	case reflect.Slice:
+		items, err := parseList(value)
+		if err != nil {
+			return "", err
+		}
+		typed = items
+	default:
+		return "", fmt.Errorf("unsupported type %s", t)
+	}
+	return encodeValue(typed)
+}
+
+// parseList accepts a TOML array literal or a comma-separated list.
+func parseList(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "[") {
+		var holder struct {
+			V []string `toml:"v"`
+		}
+		if _, err := toml.Decode("v = "+value, &holder); err != nil {
+			return nil, err
+		}
+		return holder.V, nil
+	}
+
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func encodeValue(v any) (string, error) {
+	out, err := toml.Marshal(map[string]any{"v": v})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(out)), "v = ")), nil
+}
+
+// tableBounds returns the line range holding the keys of a table; the empty
+// table name addresses top-level keys before the first header.
+func tableBounds(lines []string, table string) (int, int, bool) {
+	start, found := 0, table == ""
+	for i, line := range lines {
+		header := ""
+		if match := tableHeaderPattern.FindStringSubmatch(line); match != nil {
+			header = match[1]
+		} else if match := arrayHeaderPattern.FindStringSubmatch(line); match != nil {
+			header = "[[" + match[1] + "]]"
+		} else {
+			continue
+		}
+
+		if found {
+			return start, i, true
+		}
+		if header == table {
+			start, found = i+1, true
+		}
+	}
+	return start, len(lines), found
+}
+
+// valueEnd returns the last line of the value starting on line i, following
+// multi-line arrays until their brackets balance.
+func valueEnd(lines []string, i int) int {
+	depth := 0
+	for j := i; j < len(lines); j++ {
+		line := lines[j]
+		if j == i {
+			_, line, _ = strings.Cut(line, "=")
+		}
+		depth += bracketDepth(line)
+		if depth <= 0 {
+			return j
+		}
+	}
+	return len(lines) - 1
+}
+
+func bracketDepth(line string) int {
+	depth := 0
+	var quote rune
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return depth
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		}
+	}
+	return depth
+}
+
+func trailingComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			j := i
+			for j > 0 && (line[j-1] == ' ' || line[j-1] == '\t') {
+				j--
+			}
+			return line[j:]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const secretCommandTimeout = 30 * time.Second
+
+// secretFields lists the keys whose values may reference an external secret.
+func (c *Config) secretFields() []envField {
+	fields := []envField{{"llm.api_key", &c.LLM.APIKey}}
+	for i := range c.LLM.Providers {
+		fields = append(fields, envField{fmt.Sprintf("llm.providers[%d].api_key", i), &c.LLM.Providers[i].APIKey})
+	}
+	for i := range c.LLM.Ensemble.Candidates {
+		fields = append(fields, envField{fmt.Sprintf("llm.ensemble.candidates[%d].api_key", i), &c.LLM.Ensemble.Candidates[i].APIKey})
+	}
+	fields = append(fields,
+		envField{"llm.planner.api_key", &c.LLM.Planner.APIKey},
+		envField{"llm.ensemble.judge.api_key", &c.LLM.Ensemble.Judge.APIKey},
+		envField{"embeddings.api_key", &c.Embeddings.APIKey},
+		envField{"notifications.email.password", &c.Notifications.Email.Password},
+	)
+	return append(fields,
+		envField{"state.auth_token", &c.State.AuthToken},
+		envField{"state.encryption_key", &c.State.EncryptionKey},
+	)
+}
+
+// resolveSecrets resolves the secret references of c. exec: and keychain:
+// references run commands, so a config that may not run them (a workspace
+// config, which lives in the tree it documents) is rejected instead.
+func (c *Config) resolveSecrets(allowCommands bool) error {
+	var errs []error
+	for _, field := range c.secretFields() {
+		if scheme := commandSecretScheme(*field.value); scheme != "" && !allowCommands {
+			errs = append(errs, fmt.Errorf("%s: %s: secrets that run commands are only allowed in the root config", field.key, scheme))
+			continue
+		}
+		value, err := ResolveSecret(*field.value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field.key, err))
+			continue
+		}
+		*field.value = value
+	}
+	return errors.Join(errs...)
+}
+
+// checkSecrets reports the secret references of c that cannot resolve,
+// without running commands or changing c.
+func (c *Config) checkSecrets() error {
+	var errs []error
+	for _, field := range c.secretFields() {
+		if err := checkSecret(*field.value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field.key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ResolveSecret returns the secret a config value refers to:
+//
+//	file:~/.config/git-doc/key       contents of the file
+//	exec:op read op://vault/key      stdout of the command, run by sh -c (cmd /C on Windows)
+//	keychain:git-doc/openai          OS keychain item (service/account)
+//
+// Any other value is returned unchanged.
+func ResolveSecret(value string) (string, error) {
+	scheme, ref, ok := strings.Cut(value, ":")
+	if !ok {
+		return value, nil
+	}
+	ref = strings.TrimSpace(ref)
+
+	switch scheme {
+	case "file":
+		return readSecretFile(ref)
+	case "exec":
+		if ref == "" {
+			return "", fmt.Errorf("exec: secret command is empty")
+		}
+		name, args := secretShell(ref)
+		return runSecretCommand(name, args...)
+	case "keychain":
+		return readKeychain(ref)
+	default:
+		return value, nil
+	}
+}
+
+// checkSecret reports a secret reference that cannot resolve, without
+// running commands: file: secrets are read, exec: and keychain: references
+// are only checked for shape.
+func checkSecret(value string) error {
+	scheme, ref, _ := strings.Cut(value, ":")
+	ref = strings.TrimSpace(ref)
+	switch commandSecretScheme(value) {
+	case "exec":
+		if ref == "" {
+			return fmt.Errorf("exec: secret command is empty")
+		}
+		return nil
+	case "keychain":
+		return checkKeychainRef(ref)
+	}
+	if scheme == "file" {
+		_, err := readSecretFile(ref)
+		return err
+	}
+	return nil
+}
+
+// commandSecretScheme returns "exec" or "keychain" when value is a secret
+// reference resolved by running a command, else "".
+func commandSecretScheme(value string) string {
+	scheme, _, ok := strings.Cut(value, ":")
+	if ok && (scheme == "exec" || scheme == "keychain") {
+		return scheme
+	}
+	return ""
+}
+
+// secretShell returns the command line that runs an exec: secret command
+// through the platform shell.
+func secretShell(command string) (string, []string) {
+	if runtime.GOOS == "windows" {
+		return "cmd", []string{"/C", command}
+	}
+	return "sh", []string{"-c", command}
+}
+
+func readSecretFile(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("file: secret path is empty")
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("file: resolve home directory: %w", err)
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("file: read secret: %w", err)
+	}
+	secret := strings.TrimSpace(string(raw))
+	if secret == "" {
+		return "", fmt.Errorf("file: secret file %s is empty", path)
+	}
+	return secret, nil
+}
+
+func checkKeychainRef(ref string) error {
+	service, _, _ := strings.Cut(ref, "/")
+	if strings.TrimSpace(service) == "" {
+		return fmt.Errorf("keychain: expected keychain:<service>[/<account>]")
+	}
+	switch runtime.GOOS {
+	case "darwin", "linux", "freebsd", "openbsd":
+		return nil
+	default:
+		return fmt.Errorf("keychain: not supported on %s; use file: or exec:", runtime.GOOS)
+	}
+}
+
+func readKeychain(ref string) (string, error) {
+	if err := checkKeychainRef(ref); err != nil {
+		return "", err
+	}
+	service, account, _ := strings.Cut(ref, "/")
+
+	switch runtime.GOOS {
+	case "darwin":
+		args := []string{"find-generic-password", "-s", service, "-w"}
+		if account != "" {
+			args = append(args, "-a", account)
+		}
+		return runSecretCommand("security", args...)
+	case "linux", "freebsd", "openbsd":
+		args := []string{"lookup", "service", service}
+		if account != "" {
+			args = append(args, "account", account)
+		}
+		return runSecretCommand("secret-tool", args...)
+	default:
+		return "", fmt.Errorf("keychain: not supported on %s; use file: or exec:", runtime.GOOS)
+	}
+}
+
+func runSecretCommand(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretCommandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("%s timed out after %s", name, secretCommandTimeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s failed: %w: %s", name, err, msg)
+		}
+		return "", fmt.Errorf("%s failed: %w", name, err)
+	}
+
+	secret := strings.TrimSpace(stdout.String())
+	if secret == "" {
+		return "", fmt.Errorf("%s returned an empty secret", name)
+	}
+	return secret, nil
+}
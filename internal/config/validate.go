@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+type Issue struct {
+	Level   string
+	Key     string
+	Message string
+}
+
+func (i Issue) String() string {
+	if i.Key == "" {
+		return fmt.Sprintf("%s: %s", i.Level, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.Level, i.Key, i.Message)
+}
+
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// Check validates the config file at path against the schema without stopping
+// at the first problem: unknown keys, unset environment variables referenced
+// by ${...}, unreadable file: secrets and malformed exec: or keychain:
+// references (checked without running them), invalid globs and doc files
+// missing under repoRoot are reported alongside the errors Load would return.
+func Check(path, repoRoot string) ([]Issue, error) {
+	cfg := Default()
+	md, err := toml.DecodeFile(path, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+
+	var issues []Issue
+	for _, name := range cfg.profileNames() {
+		if err := md.PrimitiveDecode(cfg.Profiles[name], Default()); err != nil {
+			issues = append(issues, Issue{Level: "error", Key: "profiles." + name, Message: err.Error()})
+			continue
+		}
+		if _, err := loadProfile(path, name, false); err != nil {
+			issues = append(issues, Issue{Level: "error", Key: "profiles." + name, Message: err.Error()})
+		}
+	}
+	for _, key := range md.Undecoded() {
+		issues = append(issues, Issue{Level: "error", Key: key.String(), Message: "unknown key"})
+	}
+
+	for _, field := range cfg.envFields() {
+		for _, name := range missingEnvVars(*field.value) {
+			issues = append(issues, Issue{Level: "warning", Key: field.key, Message: fmt.Sprintf("environment variable %s is not set", name)})
+		}
+	}
+
+	cfg.expandEnv()
+	for _, field := range cfg.secretFields() {
+		if err := checkSecret(*field.value); err != nil {
+			issues = append(issues, Issue{Level: "error", Key: field.key, Message: err.Error()})
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		issues = append(issues, Issue{Level: "error", Message: err.Error()})
+	}
+
+	for i, pattern := range cfg.DocFiles {
+		key := fmt.Sprintf("doc_files[%d]", i)
+		issues = append(issues, checkGlob(key, pattern)...)
+		if strings.TrimSpace(pattern) != "" && !strings.ContainsAny(pattern, "*?[") {
+			if _, err := os.Stat(filepath.Join(repoRoot, pattern)); err != nil {
+				issues = append(issues, Issue{Level: "warning", Key: key, Message: fmt.Sprintf("doc file %s does not exist", pattern)})
+			}
+		}
+	}
+	for i, mapping := range cfg.Mappings {
+		key := fmt.Sprintf("mappings[%d]", i)
+		if strings.TrimSpace(mapping.CodePattern) != "" {
+			issues = append(issues, checkGlob(key+".code_pattern", mapping.CodePattern)...)
+		}
+		if strings.TrimSpace(mapping.DocFile) != "" && !mapping.CreateDoc && MaxDocFilePlaceholder(mapping.DocFile) == 0 {
+			if _, err := os.Stat(filepath.Join(repoRoot, mapping.DocFile)); err != nil {
+				issues = append(issues, Issue{Level: "error", Key: key + ".doc_file", Message: fmt.Sprintf("doc file %s does not exist", mapping.DocFile)})
+			}
+		}
+		if strings.TrimSpace(mapping.DocTemplate) != "" {
+			if _, err := os.Stat(filepath.Join(repoRoot, mapping.DocTemplate)); err != nil {
+				issues = append(issues, Issue{Level: "error", Key: key + ".doc_template", Message: fmt.Sprintf("doc template %s does not exist", mapping.DocTemplate)})
+			}
+		}
+	}
+	if guide := strings.TrimSpace(cfg.Prompts.StyleGuide); guide != "" && guide != Default().Prompts.StyleGuide {
+		if _, err := os.Stat(filepath.Join(repoRoot, guide)); err != nil {
+			issues = append(issues, Issue{Level: "warning", Key: "prompts.style_guide", Message: fmt.Sprintf("style guide %s does not exist", guide)})
+		}
+	}
+	for i, pattern := range cfg.Ignore.Paths {
+		issues = append(issues, checkGlob(fmt.Sprintf("ignore.paths[%d]", i), pattern)...)
+	}
+
+	return issues, nil
+}
+
+func missingEnvVars(value string) []string {
+	seen := map[string]bool{}
+	var missing []string
+	for _, match := range envRefPattern.FindAllStringSubmatch(value, -1) {
+		name := match[1]
+		if name == "" {
+			name = match[2]
+		}
+		if _, ok := os.LookupEnv(name); ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+func checkGlob(key, pattern string) []Issue {
+	if strings.TrimSpace(pattern) == "" {
+		return []Issue{{Level: "error", Key: key, Message: "empty glob pattern"}}
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return []Issue{{Level: "error", Key: key, Message: fmt.Sprintf("invalid glob pattern %q: %v", pattern, err)}}
+	}
+	return nil
+}
+
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Level == "error" {
+			return true
+		}
+	}
+	return false
+}
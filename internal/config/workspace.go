@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Workspace is a monorepo package with its own config file. The nested config
+// is decoded on top of the root config, so unset keys inherit root values.
+type Workspace struct {
+	Path     string  `toml:"path"`
+	Config   string  `toml:"config"`
+	Resolved *Config `toml:"-"`
+}
+
+// LoadWorkspaces reads each [[workspaces]] config relative to repoRoot.
+// Mappings and doc_files in a nested config are relative to the workspace and
+// are prefixed with its path; the prefixed mappings are also appended to the
+// root mappings so commits spanning several packages still route correctly.
+func (c *Config) LoadWorkspaces(repoRoot string) error {
+	var extraMappings []Mapping
+	for i := range c.Workspaces {
+		ws := &c.Workspaces[i]
+		ws.Path = strings.Trim(path.Clean(filepath.ToSlash(strings.TrimSpace(ws.Path))), "/")
+		if ws.Path == "" || ws.Path == "." || strings.HasPrefix(ws.Path, "..") {
+			return fmt.Errorf("workspaces[%d].path must be a directory inside the repository", i)
+		}
+
+		configPath := strings.TrimSpace(ws.Config)
+		if configPath == "" {
+			configPath = path.Join(ws.Path, ".git-doc", "config.toml")
+		}
+		if !filepath.IsAbs(configPath) {
+			configPath = filepath.Join(repoRoot, configPath)
+		}
+
+		resolved, err := c.loadWorkspaceConfig(ws.Path, configPath)
+		if err != nil {
+			return fmt.Errorf("workspaces[%d] (%s): %w", i, ws.Path, err)
+		}
+		ws.Resolved = resolved
+		extraMappings = append(extraMappings, resolved.ownMappings...)
+	}
+
+	c.Mappings = append(c.Mappings, extraMappings...)
+	return nil
+}
+
+func (c *Config) loadWorkspaceConfig(wsPath, configPath string) (*Config, error) {
+	if _, err := os.Stat(configPath); err != nil {
+		return nil, fmt.Errorf("config file %s not found: %w", configPath, err)
+	}
+
+	// Round-trip the root config so the nested decode cannot write through
+	// slices shared with it.
+	root := *c
+	root.Workspaces = nil
+	root.Profiles = nil
+	base, err := toml.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+	nested := Config{}
+	if _, err := toml.Decode(string(base), &nested); err != nil {
+		return nil, err
+	}
+	// The decoder fills arrays of tables into the elements already there, so
+	// a nested [[mappings]] entry would keep every field it leaves unset from
+	// the root entry at the same index. Arrays of tables start empty and are
+	// inherited only when the nested config does not set them.
+	inherited := nested
+	nested.Mappings = nil
+	nested.LLM.Providers = nil
+	nested.LLM.Ensemble.Candidates = nil
+	nested.Translations.Locales = nil
+	nested.Owners.Rules = nil
+	nested.Notifications.Webhooks = nil
+	md, err := toml.DecodeFile(configPath, &nested)
+	if err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	if !md.IsDefined("mappings") {
+		nested.Mappings = inherited.Mappings
+	}
+	if !md.IsDefined("llm", "providers") {
+		nested.LLM.Providers = inherited.LLM.Providers
+	}
+	if !md.IsDefined("llm", "ensemble", "candidates") {
+		nested.LLM.Ensemble.Candidates = inherited.LLM.Ensemble.Candidates
+	}
+	if !md.IsDefined("translations", "locales") {
+		nested.Translations.Locales = inherited.Translations.Locales
+	}
+	if !md.IsDefined("owners", "rules") {
+		nested.Owners.Rules = inherited.Owners.Rules
+	}
+	if !md.IsDefined("notifications", "webhooks") {
+		nested.Notifications.Webhooks = inherited.Notifications.Webhooks
+	}
+	if md.IsDefined("workspaces") {
+		return nil, fmt.Errorf("nested configs cannot declare workspaces")
+	}
+	if md.IsDefined("profiles") {
+		return nil, fmt.Errorf("nested configs cannot declare profiles")
+	}
+	if md.IsDefined("state") {
+		return nil, fmt.Errorf("state is shared with the root config and cannot be overridden")
+	}
+
+	if md.IsDefined("doc_files") {
+		for i := range nested.DocFiles {
+			nested.DocFiles[i] = path.Join(wsPath, nested.DocFiles[i])
+		}
+	}
+	if md.IsDefined("mappings") {
+		for i := range nested.Mappings {
+			if strings.TrimSpace(nested.Mappings[i].CodePattern) != "" {
+				nested.Mappings[i].CodePattern = path.Join(wsPath, nested.Mappings[i].CodePattern)
+			}
+			nested.Mappings[i].DocFile = path.Join(wsPath, nested.Mappings[i].DocFile)
+		}
+		nested.ownMappings = nested.Mappings
+	}
+
+	nested.expandEnv()
+	if err := nested.resolveSecrets(false); err != nil {
+		return nil, err
+	}
+	if err := nested.Validate(); err != nil {
+		return nil, err
+	}
+	return &nested, nil
+}
+
+// WorkspaceFor returns the workspace containing every file, choosing the
+// deepest matching path, or nil when files fall outside a single workspace.
+func (c *Config) WorkspaceFor(files []string) *Workspace {
+	var match *Workspace
+	for _, file := range files {
+		file = filepath.ToSlash(file)
+		var best *Workspace
+		for i := range c.Workspaces {
+			ws := &c.Workspaces[i]
+			if ws.Resolved == nil || !strings.HasPrefix(file, ws.Path+"/") {
+				continue
+			}
+			if best == nil || len(ws.Path) > len(best.Path) {
+				best = ws
+			}
+		}
+		if best == nil || (match != nil && match != best) {
+			return nil
+		}
+		match = best
+	}
+	return match
+}
@@ -15,9 +15,11 @@ type FileDiff struct {
 	Hunks      []Hunk
 	AddedLines int
 	DelLines   int
+	Binary     bool
 }
 
 type Hunk struct {
+	Header   string
 	OldStart int
 	OldLines int
 	NewStart int
@@ -57,11 +59,15 @@ func ParseUnifiedDiff(raw string) (Diff, error) {
 		switch {
 		case strings.HasPrefix(line, "diff --git "):
 			flushFile()
-			currentFile = &FileDiff{}
-		case strings.HasPrefix(line, "+++ b/"):
+			currentFile = &FileDiff{Path: headerPath(line)}
+		case currentHunk == nil && strings.HasPrefix(line, "+++ b/"):
 			if currentFile != nil {
 				currentFile.Path = strings.TrimPrefix(line, "+++ b/")
 			}
+		case currentHunk == nil && (strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch"):
+			if currentFile != nil {
+				currentFile.Binary = true
+			}
 		case strings.HasPrefix(line, "@@"):
 			flushHunk()
 			h, err := parseHunkHeader(line)
@@ -99,8 +105,15 @@ func BuildSummary(d Diff) string {
 		if strings.TrimSpace(path) == "" {
 			path = "(unknown path)"
 		}
+		if file.Binary {
+			lines = append(lines, fmt.Sprintf("- %s (binary)", path))
+			continue
+		}
 		lines = append(lines, fmt.Sprintf("- %s (hunks=%d, +%d, -%d)", path, len(file.Hunks), file.AddedLines, file.DelLines))
 	}
+	if symbols := ChangedSymbols(d); len(symbols) > 0 {
+		lines = append(lines, formatSymbols(symbols))
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -134,7 +147,17 @@ func parseHunkHeader(header string) (Hunk, error) {
 		return Hunk{}, err
 	}
 
-	return Hunk{OldStart: oldStart, OldLines: oldLines, NewStart: newStart, NewLines: newLines}, nil
+	return Hunk{Header: header, OldStart: oldStart, OldLines: oldLines, NewStart: newStart, NewLines: newLines}, nil
+}
+
+// headerPath returns the new path from a "diff --git a/<old> b/<new>" line.
+// It is replaced by the "+++ b/" path when one follows, but binary files and
+// pure renames have no such line.
+func headerPath(line string) string {
+	if i := strings.LastIndex(line, " b/"); i >= 0 {
+		return line[i+len(" b/"):]
+	}
+	return ""
 }
 
 func parseRange(token string, prefix string) (int, int, error) {
@@ -1,6 +1,10 @@
 package diff
 
-import "testing"
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
 
 func TestParseUnifiedDiff(t *testing.T) {
 	raw := "diff --git a/a.go b/a.go\nindex 1..2 100644\n--- a/a.go\n+++ b/a.go\n@@ -1,2 +1,3 @@\n line1\n-line2\n+line2changed\n+line3\n"
@@ -38,3 +42,167 @@ func TestBuildSummaryAndTruncate(t *testing.T) {
 		t.Fatalf("expected truncated length 10, got %d", len(truncated))
 	}
 }
+
+func TestParseUnifiedDiffDetectsBinaryFiles(t *testing.T) {
+	raw := "diff --git a/logo.png b/logo.png\nnew file mode 100644\nindex 0000000..1111111\nBinary files /dev/null and b/logo.png differ\n" +
+		"diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-old\n+new\n"
+
+	parsed, err := ParseUnifiedDiff(raw)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(parsed.Files) != 2 || parsed.Files[0].Path != "logo.png" || !parsed.Files[0].Binary || parsed.Files[1].Binary {
+		t.Fatalf("unexpected files: %+v", parsed.Files)
+	}
+	if summary := BuildSummary(parsed); !strings.Contains(summary, "- logo.png (binary)") {
+		t.Fatalf("expected binary marker in summary, got %q", summary)
+	}
+}
+
+func TestFilterOmitsBinaryExcludedAndOversizedFiles(t *testing.T) {
+	d := Diff{Files: []FileDiff{
+		{Path: "logo.png", Binary: true},
+		{Path: "go.sum", AddedLines: 2},
+		{Path: "gen.go", AddedLines: 5000},
+		{Path: "main.go", AddedLines: 3},
+	}}
+	opts := DefaultOptions()
+	opts.Exclude = func(path string) bool { return path == "go.sum" }
+
+	kept, omitted := Filter(d, opts)
+	if len(kept.Files) != 1 || kept.Files[0].Path != "main.go" {
+		t.Fatalf("unexpected kept files: %+v", kept.Files)
+	}
+	want := []OmittedFile{{"logo.png", "binary"}, {"go.sum", "excluded"}, {"gen.go", "5000 changed lines"}}
+	if len(omitted) != len(want) {
+		t.Fatalf("unexpected omitted files: %+v", omitted)
+	}
+	for i := range want {
+		if omitted[i] != want[i] {
+			t.Fatalf("omitted[%d] = %+v, want %+v", i, omitted[i], want[i])
+		}
+	}
+}
+
+func TestExcerptKeepsLargestHunksInOrder(t *testing.T) {
+	small := Hunk{Header: "@@ -1 +1 @@", Lines: []string{"-a", "+b"}}
+	large := Hunk{Header: "@@ -10,4 +10,4 @@", Lines: []string{"-c", "-d", "+e", "+f", "+g"}}
+	d := Diff{Files: []FileDiff{{Path: "a.go", Hunks: []Hunk{small, large}}}}
+
+	full := Excerpt(d, Options{})
+	if !strings.Contains(full, "@@ -1 +1 @@") || strings.Index(full, "@@ -1 +1 @@") > strings.Index(full, "@@ -10,4 +10,4 @@") {
+		t.Fatalf("expected both hunks in original order, got %q", full)
+	}
+
+	limited := Excerpt(d, Options{MaxChars: len("--- a.go\n") + len(renderHunk(large))})
+	if strings.Contains(limited, "@@ -1 +1 @@") || !strings.Contains(limited, "+g") {
+		t.Fatalf("expected only the larger hunk, got %q", limited)
+	}
+	if !strings.Contains(limited, "(1 hunks omitted)") {
+		t.Fatalf("expected omitted hunk count, got %q", limited)
+	}
+}
+
+func TestExcerptTruncatesOversizedHunk(t *testing.T) {
+	hunk := Hunk{Header: "@@ -1,60 +1,60 @@"}
+	for i := 0; i < 60; i++ {
+		hunk.Lines = append(hunk.Lines, fmt.Sprintf("+line %02d of a long generated block", i))
+	}
+	d := Diff{Files: []FileDiff{{Path: "big.go", Hunks: []Hunk{hunk}}}}
+
+	excerpt := Excerpt(d, DefaultOptions())
+	if len(excerpt) > DefaultOptions().MaxFileChars {
+		t.Fatalf("expected the excerpt within %d chars, got %d", DefaultOptions().MaxFileChars, len(excerpt))
+	}
+	if !strings.HasPrefix(excerpt, "--- big.go\n@@ -1,60 +1,60 @@\n+line 00") {
+		t.Fatalf("expected the hunk header and first lines, got %q", excerpt)
+	}
+	if strings.Contains(excerpt, "+line 59") || !strings.Contains(excerpt, "more lines)") {
+		t.Fatalf("expected the tail of the hunk to be cut, got %q", excerpt)
+	}
+	if strings.Contains(excerpt, "omitted") {
+		t.Fatalf("expected the truncated hunk not to count as omitted, got %q", excerpt)
+	}
+}
+
+func TestChangedSymbolsGo(t *testing.T) {
+	raw := "diff --git a/store.go b/store.go\n--- a/store.go\n+++ b/store.go\n" +
+		"@@ -10,6 +10,7 @@ func (s *Store[T]) Get(key string) (T, error) {\n \tv := s.m[key]\n-\treturn v, nil\n+\treturn v, s.err\n }\n \n-func Helper() {}\n+func Helper() { log() }\n" +
+		"@@ -40,3 +41,4 @@ type Options struct {\n \tName string\n+\tSize int\n }\n"
+
+	parsed, err := ParseUnifiedDiff(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	symbols := ChangedSymbols(parsed)
+	want := []string{"Store.Get()", "Helper()", "Options"}
+	if strings.Join(symbols, ",") != strings.Join(want, ",") {
+		t.Fatalf("ChangedSymbols = %v, want %v", symbols, want)
+	}
+	if summary := BuildSummary(parsed); !strings.Contains(summary, "Changed symbols: Store.Get(), Helper(), Options") {
+		t.Fatalf("expected symbols in summary, got %q", summary)
+	}
+}
+
+func TestChangedSymbolsGenericFallback(t *testing.T) {
+	raw := "diff --git a/app.py b/app.py\n--- a/app.py\n+++ b/app.py\n" +
+		"@@ -1,4 +1,4 @@ class Client:\n     def get(self):\n-        return 1\n+        return 2\n" +
+		"diff --git a/web.ts b/web.ts\n--- a/web.ts\n+++ b/web.ts\n@@ -1,2 +1,3 @@\n export function render() {\n+  draw();\n }\n"
+
+	parsed, err := ParseUnifiedDiff(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	symbols := ChangedSymbols(parsed)
+	if strings.Join(symbols, ",") != "get(),render()" {
+		t.Fatalf("unexpected symbols: %v", symbols)
+	}
+}
+
+func TestScoreRelevance(t *testing.T) {
+	cases := []struct {
+		name   string
+		raw    string
+		reason string
+		high   bool
+	}{
+		{"tests only", "diff --git a/pkg/a_test.go b/pkg/a_test.go\n--- a/pkg/a_test.go\n+++ b/pkg/a_test.go\n@@ -1 +1 @@\n-a()\n+b()\n", "tests-only change", false},
+		{"comments only", "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-// old\n+// new\n", "comment-only change", false},
+		{"formatting only", "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1,2 +1,3 @@\n-x:=1\n+x := 1\n+\n", "formatting-only change", false},
+		{"markdown heading", "diff --git a/README.md b/README.md\n--- a/README.md\n+++ b/README.md\n@@ -1 +1 @@\n-# Old\n+# New\n", "", true},
+		{"code", "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1,2 @@\n-return 1\n+// explain\n+return 2\n", "", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := ParseUnifiedDiff(tc.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := ScoreRelevance(parsed)
+			if got.Reason != tc.reason || (got.Score >= 0.5) != tc.high {
+				t.Fatalf("ScoreRelevance = %+v, want reason %q high=%v", got, tc.reason, tc.high)
+			}
+		})
+	}
+}
+
+func TestSplitSubmodules(t *testing.T) {
+	code := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-a\n+b"
+	sub := "diff --git a/libs/ui b/libs/ui\nindex 1111111..2222222 160000\n--- a/libs/ui\n+++ b/libs/ui\n@@ -1 +1 @@\n-Subproject commit 1111111\n+Subproject commit 2222222"
+	added := "diff --git a/libs/new b/libs/new\nnew file mode 160000\n--- /dev/null\n+++ b/libs/new\n@@ -0,0 +1 @@\n+Subproject commit 3333333"
+	raw := "commit abc\n\n    feat: x\n\n" + code + "\n" + sub + "\n" + added + "\n"
+
+	rest, updates := SplitSubmodules(raw)
+	if rest != "commit abc\n\n    feat: x\n\n"+code {
+		t.Fatalf("unexpected remaining diff: %q", rest)
+	}
+	want := []SubmoduleUpdate{{Path: "libs/ui", From: "1111111", To: "2222222"}, {Path: "libs/new", To: "3333333"}}
+	if len(updates) != len(want) || updates[0] != want[0] || updates[1] != want[1] {
+		t.Fatalf("unexpected submodule updates: %+v", updates)
+	}
+
+	if rest, updates := SplitSubmodules(code); rest != code || updates != nil {
+		t.Fatalf("expected a diff without submodules unchanged, got %q %+v", rest, updates)
+	}
+}
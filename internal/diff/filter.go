@@ -0,0 +1,178 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Options limits how much of a diff is shown to the LLM.
+type Options struct {
+	// MaxChars bounds the whole rendered diff context.
+	MaxChars int
+	// MaxFileChars bounds the hunks shown for any single file.
+	MaxFileChars int
+	// MaxFileLines omits the hunks of files with more changed lines, which
+	// are usually generated or vendored.
+	MaxFileLines int
+	// Exclude reports paths whose hunks are never shown.
+	Exclude func(path string) bool
+}
+
+func DefaultOptions() Options {
+	return Options{MaxChars: 6000, MaxFileChars: 2000, MaxFileLines: 1000}
+}
+
+type OmittedFile struct {
+	Path   string
+	Reason string
+}
+
+// Filter drops binary, excluded and oversized files from d. The dropped files
+// are returned with the reason so callers can still mention them.
+func Filter(d Diff, opts Options) (Diff, []OmittedFile) {
+	kept := Diff{Files: make([]FileDiff, 0, len(d.Files))}
+	var omitted []OmittedFile
+	for _, file := range d.Files {
+		switch {
+		case file.Binary:
+			omitted = append(omitted, OmittedFile{Path: file.Path, Reason: "binary"})
+		case opts.Exclude != nil && opts.Exclude(file.Path):
+			omitted = append(omitted, OmittedFile{Path: file.Path, Reason: "excluded"})
+		case opts.MaxFileLines > 0 && file.AddedLines+file.DelLines > opts.MaxFileLines:
+			omitted = append(omitted, OmittedFile{Path: file.Path, Reason: fmt.Sprintf("%d changed lines", file.AddedLines+file.DelLines)})
+		default:
+			kept.Files = append(kept.Files, file)
+		}
+	}
+	return kept, omitted
+}
+
+type rankedHunk struct {
+	file    int
+	hunk    int
+	changed int
+	lines   Hunk
+	text    string
+}
+
+// Excerpt renders the hunks of d within opts.MaxChars. When not everything
+// fits, the hunks with the most changed lines are kept and the rest are
+// counted; kept hunks are printed in their original order. A hunk larger
+// than the budget left for it is cut to its header and leading lines.
+func Excerpt(d Diff, opts Options) string {
+	var ranked []rankedHunk
+	for i, file := range d.Files {
+		for j, hunk := range file.Hunks {
+			ranked = append(ranked, rankedHunk{file: i, hunk: j, changed: changedLines(hunk), lines: hunk, text: renderHunk(hunk)})
+		}
+	}
+	if len(ranked) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(ranked, func(a, b int) bool { return ranked[a].changed > ranked[b].changed })
+
+	used := 0
+	fileUsed := map[int]int{}
+	var selected []rankedHunk
+	for _, h := range ranked {
+		header := 0
+		if fileUsed[h.file] == 0 {
+			header = len(d.Files[h.file].Path) + len("--- \n")
+		}
+		budget := -1
+		if opts.MaxFileChars > 0 {
+			budget = opts.MaxFileChars - fileUsed[h.file] - header
+		}
+		if opts.MaxChars > 0 && (budget < 0 || opts.MaxChars-used-header < budget) {
+			budget = opts.MaxChars - used - header
+		}
+		if budget >= 0 && len(h.text) > budget {
+			text, ok := truncateHunk(h.lines, budget)
+			if !ok {
+				continue
+			}
+			h.text = text
+		}
+		size := header + len(h.text)
+		used += size
+		fileUsed[h.file] += size
+		selected = append(selected, h)
+	}
+
+	sort.SliceStable(selected, func(a, b int) bool {
+		if selected[a].file != selected[b].file {
+			return selected[a].file < selected[b].file
+		}
+		return selected[a].hunk < selected[b].hunk
+	})
+
+	var b strings.Builder
+	current := -1
+	for _, h := range selected {
+		if h.file != current {
+			current = h.file
+			fmt.Fprintf(&b, "--- %s\n", d.Files[h.file].Path)
+		}
+		b.WriteString(h.text)
+	}
+	if skipped := len(ranked) - len(selected); skipped > 0 {
+		fmt.Fprintf(&b, "(%d hunks omitted)\n", skipped)
+	}
+	return b.String()
+}
+
+func changedLines(h Hunk) int {
+	n := 0
+	for _, line := range h.Lines {
+		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") {
+			n++
+		}
+	}
+	return n
+}
+
+// truncateHunk renders the header and as many leading lines of h as fit in
+// budget, followed by a count of the lines cut. It reports false when not
+// even the header and one line fit.
+func truncateHunk(h Hunk, budget int) (string, bool) {
+	var lines []string
+	for _, line := range h.Lines {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	var b strings.Builder
+	b.WriteString(h.Header)
+	b.WriteByte('\n')
+	kept := 0
+	for _, line := range lines {
+		marker := fmt.Sprintf("(%d more lines)\n", len(lines)-kept-1)
+		if b.Len()+len(line)+1+len(marker) > budget {
+			break
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+		kept++
+	}
+	if kept == 0 {
+		return "", false
+	}
+	fmt.Fprintf(&b, "(%d more lines)\n", len(lines)-kept)
+	return b.String(), true
+}
+
+func renderHunk(h Hunk) string {
+	var b strings.Builder
+	b.WriteString(h.Header)
+	b.WriteByte('\n')
+	for _, line := range h.Lines {
+		if line == "" {
+			continue
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,137 @@
+package diff
+
+import (
+	"path"
+	"strings"
+	"unicode"
+)
+
+// Relevance estimates how likely a diff is to need a documentation update,
+// from 0 (nothing a reader of the docs would notice) to 1.
+type Relevance struct {
+	Score  float64
+	Reason string
+}
+
+// ScoreRelevance scores tests-only, comment-only and formatting-only changes
+// low. Other diffs score between 0.5 and 1 by the share of changed lines that
+// are neither comments nor whitespace.
+func ScoreRelevance(d Diff) Relevance {
+	if len(d.Files) == 0 {
+		return Relevance{Score: 1}
+	}
+
+	allTests := true
+	var substantive, comments, formatting int
+	for _, file := range d.Files {
+		if IsTestPath(file.Path) {
+			continue
+		}
+		allTests = false
+		if file.Binary {
+			substantive++
+			continue
+		}
+		s, c, f := classifyChangedLines(file)
+		substantive += s
+		comments += c
+		formatting += f
+	}
+
+	switch {
+	case allTests:
+		return Relevance{Score: 0.1, Reason: "tests-only change"}
+	case substantive == 0 && comments > 0:
+		return Relevance{Score: 0.15, Reason: "comment-only change"}
+	case substantive == 0:
+		return Relevance{Score: 0.05, Reason: "formatting-only change"}
+	}
+	total := substantive + comments + formatting
+	return Relevance{Score: 0.5 + 0.5*float64(substantive)/float64(total)}
+}
+
+// IsTestPath reports whether a path looks like a test file or test fixture.
+func IsTestPath(p string) bool {
+	p = strings.ToLower(p)
+	base := path.Base(p)
+	if strings.Contains(base, "_test.") || strings.HasPrefix(base, "test_") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") {
+		return true
+	}
+	for _, segment := range strings.Split(path.Dir(p), "/") {
+		switch segment {
+		case "test", "tests", "__tests__", "testdata", "spec":
+			return true
+		}
+	}
+	return false
+}
+
+// classifyChangedLines splits a file's added and removed lines into
+// substantive, comment and formatting lines. A removed line and an added line
+// that differ only in whitespace are both formatting.
+func classifyChangedLines(file FileDiff) (substantive, comments, formatting int) {
+	// Markdown headings look like shell comments.
+	prose := false
+	switch strings.ToLower(path.Ext(file.Path)) {
+	case ".md", ".markdown", ".rst", ".txt", ".adoc":
+		prose = true
+	}
+
+	removed := map[string]int{}
+	var added []string
+	for _, hunk := range file.Hunks {
+		for _, line := range hunk.Lines {
+			if line == "" || (line[0] != '+' && line[0] != '-') {
+				continue
+			}
+			text := line[1:]
+			switch {
+			case strings.TrimSpace(text) == "":
+				formatting++
+			case !prose && isCommentLine(text):
+				comments++
+			case line[0] == '-':
+				removed[stripSpace(text)]++
+			default:
+				added = append(added, stripSpace(text))
+			}
+		}
+	}
+
+	for _, text := range added {
+		if removed[text] > 0 {
+			removed[text]--
+			formatting += 2
+			continue
+		}
+		substantive++
+	}
+	for _, n := range removed {
+		substantive += n
+	}
+	return substantive, comments, formatting
+}
+
+func isCommentLine(text string) bool {
+	text = strings.TrimSpace(text)
+	for _, prefix := range []string{"//", "/*", "*/", "<!--", "-- ", "\"\"\""} {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	if text == "*" || strings.HasPrefix(text, "* ") {
+		return true
+	}
+	// "# note" is a comment in shell, Python, Ruby and TOML; "#include" and
+	// "#!/bin/sh" are not.
+	return text == "#" || strings.HasPrefix(text, "# ")
+}
+
+func stripSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
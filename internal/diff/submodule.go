@@ -0,0 +1,58 @@
+package diff
+
+import "strings"
+
+// SubmoduleUpdate is a gitlink change: the submodule at Path moved from the
+// From commit to the To commit. From is empty when the submodule was added
+// and To when it was removed.
+type SubmoduleUpdate struct {
+	Path string
+	From string
+	To   string
+}
+
+// SplitSubmodules separates submodule updates from a unified diff and
+// returns the diff without them.
+func SplitSubmodules(raw string) (string, []SubmoduleUpdate) {
+	var kept []string
+	var updates []SubmoduleUpdate
+	for _, chunk := range splitFileChunks(raw) {
+		lines := strings.Split(chunk, "\n")
+		update := SubmoduleUpdate{Path: headerPath(lines[0])}
+		for _, line := range lines[1:] {
+			if from, ok := strings.CutPrefix(line, "-Subproject commit "); ok {
+				update.From = strings.TrimSpace(from)
+			} else if to, ok := strings.CutPrefix(line, "+Subproject commit "); ok {
+				update.To = strings.TrimSpace(to)
+			}
+		}
+		if strings.HasPrefix(lines[0], "diff --git ") && (update.From != "" || update.To != "") {
+			updates = append(updates, update)
+			continue
+		}
+		kept = append(kept, chunk)
+	}
+	if len(updates) == 0 {
+		return raw, nil
+	}
+	return strings.Join(kept, "\n"), updates
+}
+
+// splitFileChunks cuts a diff before every "diff --git" header. Anything
+// before the first header, such as the commit header of git show, is its own
+// chunk.
+func splitFileChunks(raw string) []string {
+	var chunks []string
+	var current []string
+	for _, line := range strings.Split(raw, "\n") {
+		if strings.HasPrefix(line, "diff --git ") && len(current) > 0 {
+			chunks = append(chunks, strings.Join(current, "\n"))
+			current = nil
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, strings.Join(current, "\n"))
+	}
+	return chunks
+}
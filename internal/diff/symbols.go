@@ -0,0 +1,133 @@
+package diff
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const maxSummarizedSymbols = 20
+
+var genericDeclPatterns = []struct {
+	re   *regexp.Regexp
+	call bool
+}{
+	{regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\*?\s+([A-Za-z_$][\w$]*)`), true},
+	{regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?(?:def|fn|func|fun)\s+([A-Za-z_]\w*)`), true},
+	{regexp.MustCompile(`^\s*(?:export\s+)?(?:pub(?:\([^)]*\))?\s+)?(?:public\s+|private\s+|protected\s+|internal\s+)?(?:abstract\s+|final\s+|sealed\s+|data\s+)?(?:class|interface|struct|enum|trait|type|module|impl)\s+([A-Za-z_]\w*)`), false},
+	{regexp.MustCompile(`^\s*(?:public|private|protected|internal)\s+(?:static\s+)?(?:async\s+)?[\w<>\[\],.?]+\s+([A-Za-z_]\w*)\s*\(`), true},
+}
+
+// ChangedSymbols returns the functions, methods and types whose bodies or
+// declarations contain changed lines, in diff order. Go declarations are
+// parsed with go/parser; other languages use declaration patterns. A hunk
+// starts inside the declaration git names in its header.
+func ChangedSymbols(d Diff) []string {
+	var symbols []string
+	seen := map[string]bool{}
+	for _, file := range d.Files {
+		if file.Binary {
+			continue
+		}
+		declName := genericDeclName
+		if path.Ext(file.Path) == ".go" {
+			declName = goDeclName
+		}
+
+		for _, hunk := range file.Hunks {
+			current := declName(hunkContext(hunk.Header))
+			for _, line := range hunk.Lines {
+				if line == "" {
+					continue
+				}
+				if name := declName(line[1:]); name != "" {
+					current = name
+				}
+				if line[0] != '+' && line[0] != '-' {
+					continue
+				}
+				if current != "" && !seen[current] {
+					seen[current] = true
+					symbols = append(symbols, current)
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+func hunkContext(header string) string {
+	parts := strings.SplitN(header, "@@", 3)
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[2]
+}
+
+// goDeclName parses a single Go source line as a declaration, closing an
+// opened body so "func (s *Store) Get() error {" parses on its own.
+func goDeclName(line string) string {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "func ") && !strings.HasPrefix(line, "type ") {
+		return ""
+	}
+
+	for _, candidate := range []string{line, line + "}", line + "{}"} {
+		file, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+candidate, parser.SkipObjectResolution)
+		if err != nil || len(file.Decls) == 0 {
+			continue
+		}
+		switch decl := file.Decls[0].(type) {
+		case *ast.FuncDecl:
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				return receiverName(decl.Recv.List[0].Type) + "." + decl.Name.Name + "()"
+			}
+			return decl.Name.Name + "()"
+		case *ast.GenDecl:
+			if len(decl.Specs) > 0 {
+				if spec, ok := decl.Specs[0].(*ast.TypeSpec); ok {
+					return spec.Name.Name
+				}
+			}
+		}
+	}
+	return genericDeclName(line)
+}
+
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	default:
+		return ""
+	}
+}
+
+func genericDeclName(line string) string {
+	for _, pattern := range genericDeclPatterns {
+		if match := pattern.re.FindStringSubmatch(line); match != nil {
+			if pattern.call {
+				return match[1] + "()"
+			}
+			return match[1]
+		}
+	}
+	return ""
+}
+
+func formatSymbols(symbols []string) string {
+	if len(symbols) <= maxSummarizedSymbols {
+		return "Changed symbols: " + strings.Join(symbols, ", ")
+	}
+	return fmt.Sprintf("Changed symbols: %s and %d more", strings.Join(symbols[:maxSummarizedSymbols], ", "), len(symbols)-maxSummarizedSymbols)
+}
@@ -0,0 +1,202 @@
+package doc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ChangelogCategories are the Keep a Changelog change types in the order
+// they appear within a release.
+var ChangelogCategories = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+const changelogPreamble = "# Changelog\n\nAll notable changes to this project are documented in this file.\n\nThe format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/).\n"
+
+type ChangelogEntry struct {
+	Category string
+	Text     string
+}
+
+var unreleasedLinkPattern = regexp.MustCompile(`(?i)^\[unreleased\]:\s*(\S+)/compare/(\S+)\.\.\.HEAD\s*$`)
+
+// AddChangelogEntries appends entries as bullets under their category in the
+// "Unreleased" section, creating the file header, the section and category
+// headings as needed. Entries already present in the section are skipped.
+func AddChangelogEntries(content string, entries []ChangelogEntry) string {
+	if strings.TrimSpace(content) == "" {
+		content = changelogPreamble
+	}
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+
+	start, end := unreleasedBounds(lines)
+	if start < 0 {
+		insertAt := len(lines)
+		for i, line := range lines {
+			if atxLevel(line) == 2 {
+				insertAt = i
+				break
+			}
+		}
+		block := []string{"## [Unreleased]", ""}
+		if insertAt == len(lines) {
+			block = []string{"", "## [Unreleased]"}
+		}
+		lines = insertLines(lines, insertAt, block...)
+		start, end = unreleasedBounds(lines)
+	}
+
+	for _, entry := range entries {
+		bullet := "- " + strings.TrimSpace(entry.Text)
+		if containsLine(lines[start:end], bullet) {
+			continue
+		}
+		lines = addToCategory(lines, start, end, entry.Category, bullet)
+		start, end = unreleasedBounds(lines)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// ReleaseChangelog moves the "Unreleased" entries into a "## [version] -
+// date" section below an empty "Unreleased" heading, and updates compare
+// links when the file has them.
+func ReleaseChangelog(content, version, date string) (string, error) {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	start, end := unreleasedBounds(lines)
+	if start < 0 {
+		return "", errors.New("changelog has no Unreleased section")
+	}
+	for _, line := range lines {
+		if atxLevel(line) == 2 && strings.EqualFold(releaseVersion(line), version) {
+			return "", fmt.Errorf("changelog already has a %s section", version)
+		}
+	}
+	body := lines[start+1 : end]
+	if strings.TrimSpace(strings.Join(body, "")) == "" {
+		return "", errors.New("nothing to release: the Unreleased section is empty")
+	}
+
+	released := []string{"## [Unreleased]", "", fmt.Sprintf("## [%s] - %s", version, date)}
+	if strings.TrimSpace(body[0]) != "" {
+		released = append(released, "")
+	}
+	out := append(append([]string{}, lines[:start]...), released...)
+	out = append(out, body...)
+	out = append(out, lines[end:]...)
+
+	for i, line := range out {
+		match := unreleasedLinkPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		base, previous := match[1], match[2]
+		out[i] = fmt.Sprintf("[Unreleased]: %s/compare/%s...HEAD", base, version)
+		out = insertLines(out, i+1, fmt.Sprintf("[%s]: %s/compare/%s...%s", version, base, previous, version))
+		break
+	}
+	return strings.Join(out, "\n") + "\n", nil
+}
+
+// unreleasedBounds returns the line of the Unreleased heading and the line
+// where its section ends, or -1, -1.
+func unreleasedBounds(lines []string) (int, int) {
+	start := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		level := atxLevel(trimmed)
+		if start < 0 {
+			if level == 2 && strings.EqualFold(releaseVersion(trimmed), "unreleased") {
+				start = i
+			}
+			continue
+		}
+		if level == 1 || level == 2 || unreleasedLinkPattern.MatchString(trimmed) {
+			return start, trimBlankTail(lines, start, i)
+		}
+	}
+	if start < 0 {
+		return -1, -1
+	}
+	return start, trimBlankTail(lines, start, len(lines))
+}
+
+func trimBlankTail(lines []string, start, end int) int {
+	for end > start+1 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return end
+}
+
+// releaseVersion returns the version of a "## [1.2.0] - 2024-05-01" heading.
+func releaseVersion(heading string) string {
+	title := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(heading), "#"))
+	title, _, _ = strings.Cut(title, " - ")
+	return strings.Trim(strings.TrimSpace(title), "[]")
+}
+
+func addToCategory(lines []string, start, end int, category, bullet string) []string {
+	order := categoryOrder(category)
+	insertHeading := end
+	for i := start + 1; i < end; i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if atxLevel(trimmed) != 3 {
+			continue
+		}
+		title := strings.TrimSpace(trimmed[3:])
+		if strings.EqualFold(title, category) {
+			last := i
+			for j := i + 1; j < end && atxLevel(lines[j]) == 0; j++ {
+				if strings.TrimSpace(lines[j]) != "" {
+					last = j
+				}
+			}
+			if last == i {
+				return insertLines(lines, i+1, "", bullet)
+			}
+			return insertLines(lines, last+1, bullet)
+		}
+		if insertHeading == end && categoryOrder(title) > order {
+			insertHeading = i
+		}
+	}
+
+	if insertHeading == end {
+		return insertLines(lines, end, "", "### "+category, "", bullet)
+	}
+	return insertLines(lines, insertHeading, "### "+category, "", bullet, "")
+}
+
+// atxLevel returns the level of a "## Title" heading line, or 0.
+func atxLevel(line string) int {
+	line = strings.TrimSpace(line)
+	level := headingLevel(line)
+	if level > 6 || len(line) <= level || line[level] != ' ' {
+		return 0
+	}
+	return level
+}
+
+func categoryOrder(category string) int {
+	for i, c := range ChangelogCategories {
+		if strings.EqualFold(c, category) {
+			return i
+		}
+	}
+	return len(ChangelogCategories)
+}
+
+func containsLine(lines []string, want string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func insertLines(lines []string, at int, inserted ...string) []string {
+	out := make([]string, 0, len(lines)+len(inserted))
+	out = append(out, lines[:at]...)
+	out = append(out, inserted...)
+	return append(out, lines[at:]...)
+}
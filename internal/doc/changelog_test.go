@@ -0,0 +1,51 @@
+package doc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddChangelogEntriesCreatesAndGroups(t *testing.T) {
+	out := AddChangelogEntries("", []ChangelogEntry{
+		{Category: "Fixed", Text: "handle empty input (abc1234)"},
+		{Category: "Added", Text: "**api:** add health route (def5678)"},
+		{Category: "Fixed", Text: "handle empty input (abc1234)"},
+	})
+	want := changelogPreamble + "\n## [Unreleased]\n\n### Added\n\n- **api:** add health route (def5678)\n\n### Fixed\n\n- handle empty input (abc1234)\n"
+	if out != want {
+		t.Fatalf("unexpected changelog:\n%s\nwant:\n%s", out, want)
+	}
+
+	out = AddChangelogEntries(out, []ChangelogEntry{{Category: "Added", Text: "second feature (aaa0000)"}})
+	if !strings.Contains(out, "- **api:** add health route (def5678)\n- second feature (aaa0000)\n\n### Fixed") {
+		t.Fatalf("expected the bullet appended to the existing category, got:\n%s", out)
+	}
+}
+
+func TestAddChangelogEntriesInsertsUnreleasedAboveReleases(t *testing.T) {
+	content := "# Changelog\n\n## [1.0.0] - 2024-01-01\n\n### Added\n\n- first release\n"
+	out := AddChangelogEntries(content, []ChangelogEntry{{Category: "Changed", Text: "faster startup"}})
+	want := "# Changelog\n\n## [Unreleased]\n\n### Changed\n\n- faster startup\n\n## [1.0.0] - 2024-01-01\n\n### Added\n\n- first release\n"
+	if out != want {
+		t.Fatalf("unexpected changelog:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestReleaseChangelog(t *testing.T) {
+	content := "# Changelog\n\n## [Unreleased]\n\n### Fixed\n\n- a fix\n\n## [1.0.0] - 2024-01-01\n\n- first\n\n[Unreleased]: https://github.com/o/r/compare/1.0.0...HEAD\n[1.0.0]: https://github.com/o/r/releases/tag/1.0.0\n"
+	out, err := ReleaseChangelog(content, "1.1.0", "2024-06-01")
+	if err != nil {
+		t.Fatalf("release failed: %v", err)
+	}
+	want := "# Changelog\n\n## [Unreleased]\n\n## [1.1.0] - 2024-06-01\n\n### Fixed\n\n- a fix\n\n## [1.0.0] - 2024-01-01\n\n- first\n\n[Unreleased]: https://github.com/o/r/compare/1.1.0...HEAD\n[1.1.0]: https://github.com/o/r/compare/1.0.0...1.1.0\n[1.0.0]: https://github.com/o/r/releases/tag/1.0.0\n"
+	if out != want {
+		t.Fatalf("unexpected release:\n%s\nwant:\n%s", out, want)
+	}
+
+	if _, err := ReleaseChangelog(out, "1.2.0", "2024-07-01"); err == nil || !strings.Contains(err.Error(), "nothing to release") {
+		t.Fatalf("expected an empty Unreleased section to be rejected, got %v", err)
+	}
+	if _, err := ReleaseChangelog(content, "1.0.0", "2024-07-01"); err == nil {
+		t.Fatalf("expected an existing version to be rejected")
+	}
+}
@@ -0,0 +1,64 @@
+package doc
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	previewContext  = 2
+	previewMaxLines = 200
+)
+
+// DiffPreview returns a short line diff from before to after: changed lines
+// prefixed with - and +, two lines of unchanged context around them, and
+// "..." where unchanged lines were left out.
+func DiffPreview(before, after string) string {
+	a, b := mergeLines(before), mergeLines(after)
+	match := matchLines(a, b)
+
+	var ops []string
+	j := 0
+	for i, line := range a {
+		if match[i] < 0 {
+			ops = append(ops, "-"+line)
+			continue
+		}
+		for ; j < match[i]; j++ {
+			ops = append(ops, "+"+b[j])
+		}
+		ops = append(ops, " "+line)
+		j++
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, "+"+b[j])
+	}
+
+	keep := make([]bool, len(ops))
+	for i, op := range ops {
+		if op[0] == ' ' {
+			continue
+		}
+		for k := max(0, i-previewContext); k <= min(len(ops)-1, i+previewContext); k++ {
+			keep[k] = true
+		}
+	}
+
+	var out []string
+	skipped := false
+	for i, op := range ops {
+		if !keep[i] {
+			skipped = true
+			continue
+		}
+		if skipped && len(out) > 0 {
+			out = append(out, "...")
+		}
+		skipped = false
+		out = append(out, op)
+	}
+	if len(out) > previewMaxLines {
+		out = append(out[:previewMaxLines], fmt.Sprintf("... %d more lines", len(out)-previewMaxLines))
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,140 @@
+package doc
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type Heading struct {
+	Level int
+	Title string
+	// Line is the zero-based line index of the heading.
+	Line int
+}
+
+// Headings returns the ATX headings of a markdown document in order. Lines
+// inside fenced code blocks are not headings.
+func Headings(content string) []Heading {
+	var headings []Heading
+	fence := ""
+	for i, raw := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(raw)
+		if fence != "" {
+			if strings.HasPrefix(line, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			fence = line[:3]
+			continue
+		}
+
+		level := headingLevel(line)
+		if level > 6 || (len(line) > level && line[level] != ' ' && line[level] != '\t') {
+			continue
+		}
+		title := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line[level:]), "#"))
+		if title == "" {
+			continue
+		}
+		headings = append(headings, Heading{Level: level, Title: title, Line: i})
+	}
+	return headings
+}
+
+type Section struct {
+	Heading
+	// Path holds the titles of the enclosing headings and this one.
+	Path []string
+	// Anchor is the GitHub-style fragment id of the heading.
+	Anchor string
+	// EndLine is the zero-based line index where the section ends.
+	EndLine int
+	// Words counts the words between the heading and the next heading of any
+	// level, so nested sections are not counted twice.
+	Words int
+	// Content is the heading line and everything up to the next heading of
+	// the same or a higher level.
+	Content string
+}
+
+func Sections(content string) []Section {
+	lines := strings.Split(content, "\n")
+	headings := Headings(content)
+	sections := make([]Section, 0, len(headings))
+	anchors := map[string]int{}
+	var path []string
+	var levels []int
+	for i, heading := range headings {
+		end := len(lines)
+		for _, next := range headings[i+1:] {
+			if next.Level <= heading.Level {
+				end = next.Line
+				break
+			}
+		}
+		bodyEnd := end
+		if i+1 < len(headings) && headings[i+1].Line < bodyEnd {
+			bodyEnd = headings[i+1].Line
+		}
+
+		for len(levels) > 0 && levels[len(levels)-1] >= heading.Level {
+			levels = levels[:len(levels)-1]
+			path = path[:len(path)-1]
+		}
+		levels = append(levels, heading.Level)
+		path = append(path, heading.Title)
+
+		anchor := Anchor(plainTitle(heading.Title))
+		if n := anchors[anchor]; n > 0 {
+			anchors[anchor] = n + 1
+			anchor = fmt.Sprintf("%s-%d", anchor, n)
+		} else {
+			anchors[anchor] = 1
+		}
+
+		sections = append(sections, Section{
+			Heading: heading,
+			Path:    append([]string(nil), path...),
+			Anchor:  anchor,
+			EndLine: end,
+			Words:   len(strings.Fields(strings.Join(lines[heading.Line+1:bodyEnd], "\n"))),
+			Content: strings.TrimSpace(strings.Join(lines[heading.Line:end], "\n")),
+		})
+	}
+	return sections
+}
+
+// Anchor returns the fragment id GitHub generates for a heading title:
+// lowercased, punctuation dropped and spaces turned into hyphens.
+func Anchor(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(title)) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// plainTitle drops the link syntax from a heading title, as GitHub does
+// when it derives the anchor.
+func plainTitle(title string) string {
+	for {
+		open := strings.Index(title, "](")
+		if open < 0 {
+			return title
+		}
+		label := strings.LastIndex(title[:open], "[")
+		closing := strings.Index(title[open:], ")")
+		if label < 0 || closing < 0 {
+			return title
+		}
+		title = title[:label] + title[label+1:open] + title[open+closing+1:]
+	}
+}
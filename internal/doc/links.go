@@ -0,0 +1,111 @@
+package doc
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Link is a markdown link to a file in the repository or to a heading,
+// written as [text](target#fragment) or as a [label]: target definition.
+type Link struct {
+	// Line is the zero-based line index of the link.
+	Line int
+	// Target is the path part, empty for a link within the same document.
+	Target   string
+	Fragment string
+}
+
+var (
+	inlineLinkRe     = regexp.MustCompile(`!?\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+	linkDefinitionRe = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s*<?(\S+?)>?(?:\s+.*)?$`)
+	codeSpanRe       = regexp.MustCompile("`[^`\n]*`")
+	schemeRe         = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:`)
+	htmlAnchorRe     = regexp.MustCompile(`(?i)<a\s+[^>]*(?:name|id)\s*=\s*"([^"]+)"`)
+)
+
+// Links returns the links in content that stay inside the repository. Links
+// in fenced code blocks and code spans are ignored, as are URLs with a scheme.
+func Links(content string) []Link {
+	var links []Link
+	fence := ""
+	for i, raw := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(raw)
+		if fence != "" {
+			if strings.HasPrefix(line, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			fence = line[:3]
+			continue
+		}
+
+		raw = codeSpanRe.ReplaceAllString(raw, "")
+		var targets []string
+		for _, m := range inlineLinkRe.FindAllStringSubmatch(raw, -1) {
+			targets = append(targets, m[1])
+		}
+		if m := linkDefinitionRe.FindStringSubmatch(raw); m != nil {
+			targets = append(targets, m[1])
+		}
+		for _, target := range targets {
+			if schemeRe.MatchString(target) || strings.HasPrefix(target, "//") {
+				continue
+			}
+			path, fragment, _ := strings.Cut(target, "#")
+			if unescaped, err := url.PathUnescape(fragment); err == nil {
+				fragment = unescaped
+			}
+			links = append(links, Link{Line: i, Target: path, Fragment: fragment})
+		}
+	}
+	return links
+}
+
+// Anchors returns the fragment ids a document defines: its heading anchors
+// and explicit <a name> or id attributes.
+func Anchors(content string) map[string]bool {
+	anchors := map[string]bool{}
+	for _, section := range Sections(content) {
+		anchors[section.Anchor] = true
+	}
+	for _, m := range htmlAnchorRe.FindAllStringSubmatch(content, -1) {
+		anchors[strings.ToLower(m[1])] = true
+	}
+	return anchors
+}
+
+// RenamedAnchors maps the heading anchors of before that after no longer
+// has to the anchor of the heading in the same position, when both
+// documents have the same number of headings.
+func RenamedAnchors(before, after string) map[string]string {
+	old, updated := Sections(before), Sections(after)
+	if len(old) != len(updated) {
+		return nil
+	}
+	kept := Anchors(after)
+	renames := map[string]string{}
+	for i := range old {
+		if old[i].Anchor != updated[i].Anchor && !kept[old[i].Anchor] {
+			renames[old[i].Anchor] = updated[i].Anchor
+		}
+	}
+	return renames
+}
+
+// ReplaceFragment rewrites the fragment of the links on one line that point
+// to target#from, so they point to target#to.
+func ReplaceFragment(content string, line int, target, from, to string) string {
+	lines := strings.Split(content, "\n")
+	if line < 0 || line >= len(lines) {
+		return content
+	}
+	lines[line] = strings.ReplaceAll(lines[line], target+"#"+from+")", target+"#"+to+")")
+	lines[line] = strings.ReplaceAll(lines[line], target+"#"+from+" ", target+"#"+to+" ")
+	if strings.HasSuffix(lines[line], target+"#"+from) {
+		lines[line] = strings.TrimSuffix(lines[line], target+"#"+from) + target + "#" + to
+	}
+	return strings.Join(lines, "\n")
+}
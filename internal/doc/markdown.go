@@ -1,12 +1,16 @@
 package doc
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/yuin/goldmark"
 )
 
+// ErrSectionNotFound is wrapped by ExtractSection when the heading is missing.
+var ErrSectionNotFound = errors.New("not found")
+
 type Updater interface {
 	ExtractSection(content, section string) (string, error)
 	ReplaceSection(content, section, newSectionContent string) (string, error)
@@ -24,7 +28,7 @@ func (u *MarkdownUpdater) ExtractSection(content, section string) (string, error
 	lines := strings.Split(content, "\n")
 	start, end, found := findSectionBounds(lines, section)
 	if !found {
-		return "", fmt.Errorf("section %q not found", section)
+		return "", fmt.Errorf("section %q %w", section, ErrSectionNotFound)
 	}
 
 	return strings.Join(lines[start:end], "\n"), nil
@@ -34,17 +38,7 @@ func (u *MarkdownUpdater) ReplaceSection(content, section, newSectionContent str
 	lines := strings.Split(content, "\n")
 	start, end, found := findSectionBounds(lines, section)
 	if !found {
-		builder := strings.Builder{}
-		builder.WriteString(strings.TrimRight(content, "\n"))
-		if !strings.HasSuffix(content, "\n") {
-			builder.WriteString("\n")
-		}
-		builder.WriteString("\n## ")
-		builder.WriteString(section)
-		builder.WriteString("\n\n")
-		builder.WriteString(strings.TrimSpace(newSectionContent))
-		builder.WriteString("\n")
-		return builder.String(), nil
+		return InsertSection(content, section, newSectionContent, Placement{}), nil
 	}
 
 	updated := make([]string, 0, len(lines))
@@ -58,6 +52,84 @@ func (u *MarkdownUpdater) ReplaceSection(content, section, newSectionContent str
 	return strings.Join(updated, "\n"), nil
 }
 
+// Placement says where InsertSection puts a section the document lacks.
+type Placement struct {
+	// Level is the heading level; 0 means 2.
+	Level int
+	// Insert is "end" (the default), "top" (before the first heading after
+	// the title), "alphabetical" (among the headings of the same level) or
+	// "after:<heading>" (after that heading's section, or at the end when it
+	// is missing).
+	Insert string
+}
+
+// InsertSection adds a section with body to content at the placement.
+func InsertSection(content, section, body string, p Placement) string {
+	level := p.Level
+	if level <= 0 {
+		level = 2
+	}
+	heading := strings.Repeat("#", level) + " " + section
+
+	lines := strings.Split(content, "\n")
+	at := -1
+	switch {
+	case p.Insert == "top":
+		for _, h := range Headings(content) {
+			if h.Level > 1 {
+				at = h.Line
+				break
+			}
+		}
+	case p.Insert == "alphabetical":
+		var last *Section
+		sections := Sections(content)
+		for i := range sections {
+			if sections[i].Level != level {
+				continue
+			}
+			if strings.ToLower(sections[i].Title) > strings.ToLower(section) {
+				at = sections[i].Line
+				break
+			}
+			last = &sections[i]
+		}
+		if at < 0 && last != nil && last.EndLine < len(lines) {
+			at = last.EndLine
+		}
+	case strings.HasPrefix(p.Insert, "after:"):
+		target := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(p.Insert, "after:")))
+		for _, s := range Sections(content) {
+			if strings.ToLower(s.Title) == target {
+				if s.EndLine < len(lines) {
+					at = s.EndLine
+				}
+				break
+			}
+		}
+	}
+
+	if at < 0 {
+		builder := strings.Builder{}
+		builder.WriteString(strings.TrimRight(content, "\n"))
+		builder.WriteString("\n\n" + heading + "\n\n")
+		builder.WriteString(strings.TrimSpace(body))
+		builder.WriteString("\n")
+		return builder.String()
+	}
+
+	block := []string{heading, ""}
+	if trimmed := strings.TrimSpace(body); trimmed != "" {
+		block = append(block, strings.Split(trimmed, "\n")...)
+		block = append(block, "")
+	}
+	before := lines[:at]
+	if len(before) > 0 && strings.TrimSpace(before[len(before)-1]) != "" {
+		block = append([]string{""}, block...)
+	}
+	return strings.Join(append(append(append([]string{}, before...), block...), lines[at:]...), "\n")
+}
+
 func findSectionBounds(lines []string, section string) (int, int, bool) {
 	target := strings.ToLower(strings.TrimSpace(section))
 	startHeader := -1
@@ -1,6 +1,9 @@
 package doc
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestReplaceSectionExisting(t *testing.T) {
 	u := NewMarkdownUpdater()
@@ -29,6 +32,57 @@ func TestReplaceSectionAppendWhenMissing(t *testing.T) {
 	}
 }
 
+func TestInsertSectionPlacement(t *testing.T) {
+	input := "# Title\n\n## Intro\nhello\n\n## Usage\nrun it\n"
+	cases := []struct {
+		placement Placement
+		want      string
+	}{
+		{Placement{}, "# Title\n\n## Intro\nhello\n\n## Usage\nrun it\n\n## Install\n\nbody\n"},
+		{Placement{Insert: "top"}, "# Title\n\n## Install\n\nbody\n\n## Intro\nhello\n\n## Usage\nrun it\n"},
+		{Placement{Insert: "alphabetical"}, "# Title\n\n## Install\n\nbody\n\n## Intro\nhello\n\n## Usage\nrun it\n"},
+		{Placement{Level: 3, Insert: "after:intro"}, "# Title\n\n## Intro\nhello\n\n### Install\n\nbody\n\n## Usage\nrun it\n"},
+		{Placement{Insert: "after:Missing"}, "# Title\n\n## Intro\nhello\n\n## Usage\nrun it\n\n## Install\n\nbody\n"},
+	}
+	for _, tc := range cases {
+		if got := InsertSection(input, "Install", "body", tc.placement); got != tc.want {
+			t.Errorf("%+v: got %q, want %q", tc.placement, got, tc.want)
+		}
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	base := "- Added A\n- Added B"
+	cases := []struct {
+		ours, theirs string
+		want         string
+		clean        bool
+	}{
+		{"- Added A (see a.md)\n- Added B", "- Added A\n- Added B\n- Added C", "- Added A (see a.md)\n- Added B\n- Added C", true},
+		{"- Added B", "- Added A\n- Added B\n\n- Added C", "- Added B\n\n- Added C", true},
+		{"- Added A!\n- Added B", "- Added A?\n- Added B", "", false},
+		{"- Added A\r\n- Added B\r\n", "- Added Z\n- Added B", "- Added Z\n- Added B", true},
+	}
+	for _, tc := range cases {
+		got, clean := Merge3(base, tc.ours, tc.theirs)
+		if got != tc.want || clean != tc.clean {
+			t.Errorf("Merge3(%q, %q): got %q clean=%v, want %q clean=%v", tc.ours, tc.theirs, got, clean, tc.want, tc.clean)
+		}
+	}
+}
+
+func TestDiffPreview(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni"
+	after := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj"
+	want := " a\n-b\n+B\n c\n d\n...\n h\n i\n+j"
+	if got := DiffPreview(before, after); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if got := DiffPreview("", "new"); got != "+new" {
+		t.Fatalf("expected a new section to preview as additions, got %q", got)
+	}
+}
+
 func contains(haystack, needle string) bool {
 	return len(haystack) >= len(needle) && (haystack == needle || stringContains(haystack, needle))
 }
@@ -41,3 +95,97 @@ func stringContains(s, sub string) bool {
 	}
 	return false
 }
+
+func TestHeadingsSkipsCodeFences(t *testing.T) {
+	content := "# Title\n\nIntro\n\n## Install ##\n\n```sh\n# not a heading\n```\n\n#hashtag\n### Usage\n"
+	headings := Headings(content)
+	if len(headings) != 3 {
+		t.Fatalf("expected 3 headings, got %+v", headings)
+	}
+	if headings[1] != (Heading{Level: 2, Title: "Install", Line: 4}) || headings[2].Title != "Usage" || headings[2].Level != 3 {
+		t.Fatalf("unexpected headings: %+v", headings)
+	}
+}
+
+func TestSectionsPathsAnchorsAndWords(t *testing.T) {
+	content := "# Title\n\nIntro text here\n\n## Usage\n\nRun the tool.\n\n### Flags & Options\n\n--dry-run\n\n## Usage\n\nAgain.\n"
+	sections := Sections(content)
+	if len(sections) != 4 {
+		t.Fatalf("expected 4 sections, got %+v", sections)
+	}
+	if got := strings.Join(sections[2].Path, " > "); got != "Title > Usage > Flags & Options" {
+		t.Fatalf("unexpected section path %q", got)
+	}
+	if sections[2].Anchor != "flags--options" || sections[3].Anchor != "usage-1" {
+		t.Fatalf("unexpected anchors %q and %q", sections[2].Anchor, sections[3].Anchor)
+	}
+	if sections[0].Words != 3 || sections[1].Words != 3 || sections[1].EndLine != 12 {
+		t.Fatalf("expected words to stop at the next heading, got %+v", sections[:2])
+	}
+	if strings.Join(sections[3].Path, " > ") != "Title > Usage" {
+		t.Fatalf("expected the sibling to replace the previous path, got %v", sections[3].Path)
+	}
+}
+
+func TestReplaceMarkedBlock(t *testing.T) {
+	out := ReplaceMarkedBlock("# Title\n\nIntro\n", "status", "synced")
+	want := "# Title\n\n<!-- git-doc:status:start -->\nsynced\n<!-- git-doc:status:end -->\n\nIntro\n"
+	if out != want {
+		t.Fatalf("unexpected insertion:\n%q\nwant:\n%q", out, want)
+	}
+
+	edited := strings.Replace(out, "synced", "hand edit", 1) + "\nfooter\n"
+	out = ReplaceMarkedBlock(edited, "status", "synced again")
+	if !strings.Contains(out, "<!-- git-doc:status:start -->\nsynced again\n<!-- git-doc:status:end -->\n\nIntro\n\nfooter\n") {
+		t.Fatalf("expected only the marked block to change, got:\n%s", out)
+	}
+
+	if out := ReplaceMarkedBlock("No title\n", "status", "x"); !strings.HasPrefix(out, "<!-- git-doc:status:start -->\nx\n") {
+		t.Fatalf("expected the block at the top without a title, got:\n%s", out)
+	}
+}
+
+func TestLinksAnchorsAndRenames(t *testing.T) {
+	content := "# Title\n\n<a name=\"legacy\"></a>\n## Set up\nSee [install](docs/install.md#from-source), [here](#set-up), [site](https://example.com/#x) and `[code](#nope)`.\n\n```md\n[fenced](#nope)\n```\n\n[ref]: ./guide.md#intro\n"
+
+	links := Links(content)
+	if len(links) != 3 {
+		t.Fatalf("expected three repository links, got %+v", links)
+	}
+	if links[0] != (Link{Line: 4, Target: "docs/install.md", Fragment: "from-source"}) || links[1] != (Link{Line: 4, Fragment: "set-up"}) || links[2] != (Link{Line: 10, Target: "./guide.md", Fragment: "intro"}) {
+		t.Fatalf("unexpected links %+v", links)
+	}
+
+	anchors := Anchors(content)
+	if !anchors["title"] || !anchors["set-up"] || !anchors["legacy"] || anchors["nope"] {
+		t.Fatalf("unexpected anchors %v", anchors)
+	}
+
+	renamed := strings.Replace(content, "## Set up", "## Setting up", 1)
+	renames := RenamedAnchors(content, renamed)
+	if len(renames) != 1 || renames["set-up"] != "setting-up" {
+		t.Fatalf("unexpected renames %v", renames)
+	}
+	if got := ReplaceFragment(renamed, 4, "", "set-up", "setting-up"); !strings.Contains(got, "[here](#setting-up)") {
+		t.Fatalf("expected the fragment to be rewritten, got %q", got)
+	}
+}
+
+func TestRegenerateTOC(t *testing.T) {
+	content := "# Title\n\n<!-- toc -->\n- [Stale](#stale)\n<!-- tocstop -->\n\n## Install\n### From [source](https://example.com)\n#### Deep\n## Usage\n"
+	got := RegenerateTOC(content, 3)
+	want := "# Title\n\n<!-- toc -->\n\n- [Install](#install)\n  - [From source](#from-source)\n- [Usage](#usage)\n\n<!-- tocstop -->\n\n## Install\n### From [source](https://example.com)\n#### Deep\n## Usage\n"
+	if got != want {
+		t.Fatalf("unexpected toc:\n%s", got)
+	}
+	if again := RegenerateTOC(got, 3); again != got {
+		t.Fatalf("expected regeneration to be stable, got:\n%s", again)
+	}
+
+	if got := RegenerateTOC("<!-- toc -->\n\n## Only\n", 3); got != "<!-- toc -->\n\n- [Only](#only)\n\n<!-- tocstop -->\n\n## Only\n" {
+		t.Fatalf("expected a missing end marker to be added, got %q", got)
+	}
+	if plain := "# Title\n\n## A\n"; RegenerateTOC(plain, 3) != plain {
+		t.Fatalf("expected documents without markers to be unchanged")
+	}
+}
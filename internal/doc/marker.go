@@ -0,0 +1,34 @@
+package doc
+
+import (
+	"fmt"
+	"strings"
+)
+
+func markerLines(name string) (string, string) {
+	return fmt.Sprintf("<!-- git-doc:%s:start -->", name), fmt.Sprintf("<!-- git-doc:%s:end -->", name)
+}
+
+// ReplaceMarkedBlock replaces the text between the git-doc start and end
+// markers for name with body. Without markers, the block is inserted below
+// the document's first-level title, or at the top when it has none. Text
+// outside the markers is never touched.
+func ReplaceMarkedBlock(content, name, body string) string {
+	start, end := markerLines(name)
+	block := start + "\n" + strings.TrimSpace(body) + "\n" + end
+
+	if i := strings.Index(content, start); i >= 0 {
+		if j := strings.Index(content[i:], end); j >= 0 {
+			return content[:i] + block + content[i+j+len(end):]
+		}
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if atxLevel(line) == 1 {
+			rest := strings.TrimLeft(strings.Join(lines[i+1:], "\n"), "\n")
+			return strings.Join(lines[:i+1], "\n") + "\n\n" + block + "\n\n" + rest
+		}
+	}
+	return block + "\n\n" + strings.TrimLeft(content, "\n")
+}
@@ -0,0 +1,89 @@
+package doc
+
+import (
+	"slices"
+	"strings"
+)
+
+// Merge3 merges line by line the changes ours and theirs made to base. It
+// reports false when both changed the same lines differently.
+func Merge3(base, ours, theirs string) (string, bool) {
+	o, a, b := mergeLines(base), mergeLines(ours), mergeLines(theirs)
+	ma, mb := matchLines(o, a), matchLines(o, b)
+
+	var out []string
+	i, ja, jb := 0, 0, 0
+	for {
+		// The next base line both sides kept ends the current chunk.
+		k := i
+		for k < len(o) && (ma[k] < 0 || mb[k] < 0) {
+			k++
+		}
+		endA, endB := len(a), len(b)
+		if k < len(o) {
+			endA, endB = ma[k], mb[k]
+		}
+
+		chunkO, chunkA, chunkB := o[i:k], a[ja:endA], b[jb:endB]
+		switch {
+		case slices.Equal(chunkA, chunkO):
+			out = append(out, chunkB...)
+		case slices.Equal(chunkB, chunkO), slices.Equal(chunkA, chunkB):
+			out = append(out, chunkA...)
+		default:
+			return "", false
+		}
+
+		if k == len(o) {
+			return strings.Join(out, "\n"), true
+		}
+		out = append(out, o[k])
+		i, ja, jb = k+1, endA+1, endB+1
+	}
+}
+
+func mergeLines(s string) []string {
+	s = strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// matchLines pairs lines of base with lines of other along a longest common
+// subsequence; unmatched base lines map to -1.
+func matchLines(base, other []string) []int {
+	lcs := make([][]int, len(base)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(other)+1)
+	}
+	for i := len(base) - 1; i >= 0; i-- {
+		for j := len(other) - 1; j >= 0; j-- {
+			if base[i] == other[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	match := make([]int, len(base))
+	i, j := 0, 0
+	for i < len(base) && j < len(other) {
+		switch {
+		case base[i] == other[j]:
+			match[i] = j
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			match[i] = -1
+			i++
+		default:
+			j++
+		}
+	}
+	for ; i < len(base); i++ {
+		match[i] = -1
+	}
+	return match
+}
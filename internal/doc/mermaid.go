@@ -0,0 +1,169 @@
+package doc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	flowchartHeader  = regexp.MustCompile(`^(graph|flowchart)(\s+(TB|TD|BT|RL|LR))?\s*;?$`)
+	flowchartNodeRef = regexp.MustCompile(`^[A-Za-z0-9_][\w.-]*`)
+	otherDiagrams    = []string{"sequenceDiagram", "classDiagram", "stateDiagram", "stateDiagram-v2", "erDiagram", "C4Context", "C4Container", "C4Component", "mindmap", "block-beta", "architecture-beta"}
+	flowchartKeyword = []string{"subgraph", "end", "direction", "classDef", "class", "style", "linkStyle", "click"}
+)
+
+// ExtractMermaid returns the diagram source from an LLM answer: the first
+// ```mermaid block, else the body of a fence around the whole answer, else
+// the trimmed answer.
+func ExtractMermaid(raw string) string {
+	lines := strings.Split(strings.TrimSpace(raw), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "```mermaid" {
+			continue
+		}
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == "```" {
+				return strings.TrimSpace(strings.Join(lines[i+1:j], "\n"))
+			}
+		}
+		return strings.TrimSpace(strings.Join(lines[i+1:], "\n"))
+	}
+	return strings.TrimSpace(unwrapFence(strings.TrimSpace(raw)))
+}
+
+// ValidateMermaid checks a diagram before it is written: a known diagram
+// type on the first line, balanced brackets and quotes on every line, and for
+// flowcharts, statements that start with a node id or keyword and subgraphs
+// that are closed.
+func ValidateMermaid(src string) error {
+	var lines []string
+	for _, line := range strings.Split(src, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "%%") {
+			continue
+		}
+		if strings.Contains(trimmed, "```") {
+			return errors.New("diagram contains a code fence")
+		}
+		lines = append(lines, trimmed)
+	}
+	if len(lines) == 0 {
+		return errors.New("diagram is empty")
+	}
+
+	header := lines[0]
+	flowchart := flowchartHeader.MatchString(header)
+	if !flowchart && !knownDiagram(header) {
+		return fmt.Errorf("unknown diagram type %q", header)
+	}
+
+	depth := 0
+	for n, line := range lines[1:] {
+		if err := checkBalanced(line); err != nil {
+			return fmt.Errorf("line %d: %w", n+2, err)
+		}
+		if !flowchart {
+			continue
+		}
+
+		word := strings.Fields(strings.TrimSuffix(line, ";"))[0]
+		switch {
+		case word == "subgraph":
+			depth++
+		case word == "end":
+			depth--
+			if depth < 0 {
+				return fmt.Errorf("line %d: end without subgraph", n+2)
+			}
+		case isFlowchartKeyword(word):
+		case !flowchartNodeRef.MatchString(line):
+			return fmt.Errorf("line %d: expected a node or edge, got %q", n+2, line)
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("%d subgraph(s) not closed", depth)
+	}
+	return nil
+}
+
+func knownDiagram(header string) bool {
+	word := strings.Fields(header)[0]
+	for _, known := range otherDiagrams {
+		if word == known {
+			return true
+		}
+	}
+	return false
+}
+
+func isFlowchartKeyword(word string) bool {
+	for _, keyword := range flowchartKeyword {
+		if word == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+func checkBalanced(line string) error {
+	pairs := map[rune]rune{')': '(', ']': '[', '}': '{'}
+	var stack []rune
+	quoted := false
+	for _, r := range line {
+		if r == '"' {
+			quoted = !quoted
+			continue
+		}
+		if quoted {
+			continue
+		}
+		switch r {
+		case '(', '[', '{':
+			stack = append(stack, r)
+		case ')', ']', '}':
+			if len(stack) == 0 || stack[len(stack)-1] != pairs[r] {
+				return fmt.Errorf("unbalanced %q", r)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if quoted {
+		return errors.New("unterminated string")
+	}
+	if len(stack) > 0 {
+		return fmt.Errorf("unclosed %q", stack[len(stack)-1])
+	}
+	return nil
+}
+
+// ReplaceMermaidBlock swaps the first ```mermaid block of a section body for
+// diagram, or appends one when the body has none.
+func ReplaceMermaidBlock(body, diagram string) string {
+	block := "```mermaid\n" + strings.TrimSpace(diagram) + "\n```"
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "```mermaid" {
+			continue
+		}
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == "```" {
+				out := append(append(append([]string{}, lines[:i]...), block), lines[j+1:]...)
+				return strings.Join(out, "\n")
+			}
+		}
+	}
+	if strings.TrimSpace(body) == "" {
+		return block
+	}
+	return strings.TrimRight(body, "\n") + "\n\n" + block
+}
+
+// MermaidBlock returns the first ```mermaid block of content, without fences.
+func MermaidBlock(content string) string {
+	if !strings.Contains(content, "```mermaid") {
+		return ""
+	}
+	return ExtractMermaid(content)
+}
@@ -0,0 +1,226 @@
+package doc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+var (
+	preamblePattern    = regexp.MustCompile(`(?i)^(sure|certainly|of course)?[!,.]?\s*(here(?:'s| is| are)\b.*|below is\b.*):\s*$`)
+	brokenLinkPattern  = regexp.MustCompile(`\]\(`)
+	wrappingFenceInfos = map[string]bool{"": true, "markdown": true, "md": true}
+)
+
+// SanitizeSection cleans up LLM output before it replaces a section: it
+// unwraps a fence around the whole answer, drops chatty preambles and a
+// repeated section heading, shifts headings below the section's level, and
+// rejects link or table syntax that did not survive generation.
+func SanitizeSection(content, section string, sectionLevel int) (string, error) {
+	out := strings.TrimSpace(content)
+	out = stripPreamble(out)
+	out = unwrapFence(out)
+	out = stripPreamble(out)
+	out = stripRepeatedHeading(out, section)
+
+	md := goldmark.New()
+	source := []byte(out)
+	root := md.Parser().Parse(text.NewReader(source))
+
+	out = normalizeHeadings(out, root, source, sectionLevel)
+	if err := checkMarkdownSyntax(root, source); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+// SectionLevel returns the heading level of section in content, or 2 when the
+// section does not exist yet since ReplaceSection appends it as "##".
+func SectionLevel(content, section string) int {
+	lines := strings.Split(content, "\n")
+	start, _, found := findSectionBounds(lines, section)
+	if !found {
+		return 2
+	}
+	for i := start - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "#") {
+			return headingLevel(line)
+		}
+	}
+	return 2
+}
+
+// SectionLine returns the 1-based line of section's heading in content, or 0
+// when the section does not exist.
+func SectionLine(content, section string) int {
+	start, _, found := findSectionBounds(strings.Split(content, "\n"), section)
+	if !found {
+		return 0
+	}
+	return start
+}
+
+func stripPreamble(content string) string {
+	first, rest, _ := strings.Cut(content, "\n")
+	if preamblePattern.MatchString(strings.TrimSpace(first)) {
+		return strings.TrimSpace(rest)
+	}
+	return content
+}
+
+func unwrapFence(content string) string {
+	lines := strings.Split(content, "\n")
+	if len(lines) < 2 {
+		return content
+	}
+
+	first := strings.TrimSpace(lines[0])
+	last := strings.TrimSpace(lines[len(lines)-1])
+	if !strings.HasPrefix(first, "```") || last != "```" {
+		return content
+	}
+	if !wrappingFenceInfos[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(first, "```")))] {
+		return content
+	}
+
+	inner := lines[1 : len(lines)-1]
+	for _, line := range inner {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			return content
+		}
+	}
+	return strings.TrimSpace(strings.Join(inner, "\n"))
+}
+
+func stripRepeatedHeading(content, section string) string {
+	first, rest, _ := strings.Cut(content, "\n")
+	first = strings.TrimSpace(first)
+	if !strings.HasPrefix(first, "#") {
+		return content
+	}
+	title := strings.TrimSpace(strings.TrimLeft(first, "#"))
+	if !strings.EqualFold(title, strings.TrimSpace(section)) {
+		return content
+	}
+	return strings.TrimSpace(rest)
+}
+
+func normalizeHeadings(content string, root ast.Node, source []byte, sectionLevel int) string {
+	type headingLine struct {
+		line  int
+		level int
+	}
+
+	var headings []headingLine
+	minLevel := 7
+	_ = ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !entering || !ok || heading.Lines().Len() == 0 {
+			return ast.WalkContinue, nil
+		}
+		offset := heading.Lines().At(0).Start
+		line := strings.Count(string(source[:offset]), "\n")
+		headings = append(headings, headingLine{line: line, level: heading.Level})
+		if heading.Level < minLevel {
+			minLevel = heading.Level
+		}
+		return ast.WalkSkipChildren, nil
+	})
+
+	shift := sectionLevel + 1 - minLevel
+	if len(headings) == 0 || shift <= 0 {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	for _, h := range headings {
+		raw := strings.TrimSpace(lines[h.line])
+		if !strings.HasPrefix(raw, "#") {
+			continue
+		}
+		level := h.level + shift
+		if level > 6 {
+			level = 6
+		}
+		title := strings.TrimSpace(strings.TrimLeft(raw, "#"))
+		lines[h.line] = strings.Repeat("#", level) + " " + title
+	}
+	return strings.Join(lines, "\n")
+}
+
+func checkMarkdownSyntax(root ast.Node, source []byte) error {
+	return ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock, *ast.CodeSpan, *ast.HTMLBlock, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *ast.Link:
+			if len(strings.TrimSpace(string(node.Destination))) == 0 {
+				return ast.WalkStop, fmt.Errorf("generated section has a link with an empty destination")
+			}
+		case *ast.Text:
+			if brokenLinkPattern.Match(node.Segment.Value(source)) {
+				return ast.WalkStop, fmt.Errorf("generated section has broken link syntax near %q", strings.TrimSpace(string(node.Segment.Value(source))))
+			}
+		case *ast.Paragraph:
+			if err := checkTable(node, source); err != nil {
+				return ast.WalkStop, err
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+func checkTable(paragraph *ast.Paragraph, source []byte) error {
+	lines := paragraph.Lines()
+	if lines.Len() == 0 {
+		return nil
+	}
+
+	rows := make([]string, 0, lines.Len())
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		rows = append(rows, strings.TrimSpace(string(segment.Value(source))))
+	}
+	if !strings.HasPrefix(rows[0], "|") {
+		return nil
+	}
+	if len(rows) < 2 || !isTableDelimiter(rows[1]) {
+		return fmt.Errorf("generated section has a table without a header delimiter row")
+	}
+
+	columns := tableCells(rows[0])
+	for i, row := range rows[1:] {
+		if got := tableCells(row); got != columns {
+			return fmt.Errorf("generated section has a table row %d with %d columns, expected %d", i+2, got, columns)
+		}
+	}
+	return nil
+}
+
+func isTableDelimiter(row string) bool {
+	cells := strings.Split(strings.Trim(row, "|"), "|")
+	for _, cell := range cells {
+		cell = strings.TrimSpace(cell)
+		if cell == "" || strings.Trim(cell, ":-") != "" || !strings.Contains(cell, "-") {
+			return false
+		}
+	}
+	return true
+}
+
+func tableCells(row string) int {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	row = strings.TrimSuffix(row, "|")
+	return len(strings.Split(strings.ReplaceAll(row, `\|`, ""), "|"))
+}
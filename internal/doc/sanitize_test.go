@@ -0,0 +1,109 @@
+package doc
+
+import "testing"
+
+func TestSanitizeSectionStripsArtifacts(t *testing.T) {
+	input := "Here is the updated section:\n```markdown\n## Recent Changes\n- added `--json` flag\n```"
+	out, err := SanitizeSection(input, "Recent Changes", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "- added `--json` flag" {
+		t.Fatalf("expected artifacts to be stripped, got %q", out)
+	}
+}
+
+func TestSanitizeSectionKeepsInnerCodeFences(t *testing.T) {
+	input := "Usage:\n\n```bash\ngit-doc update\n```"
+	out, err := SanitizeSection(input, "Usage", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != input {
+		t.Fatalf("expected content to be unchanged, got %q", out)
+	}
+}
+
+func TestSanitizeSectionNormalizesHeadingLevels(t *testing.T) {
+	input := "# Added\n- one\n\n## Details\ntext\n\n```\n# not a heading\n```"
+	out, err := SanitizeSection(input, "Recent Changes", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "### Added\n- one\n\n#### Details\ntext\n\n```\n# not a heading\n```"
+	if out != want {
+		t.Fatalf("expected headings nested under section, got %q", out)
+	}
+}
+
+func TestSanitizeSectionRejectsBrokenSyntax(t *testing.T) {
+	for _, input := range []string{
+		"See [the docs](https://example.com for details",
+		"See [the docs]() for details",
+		"| a | b |\n| 1 | 2 |",
+		"| a | b |\n|---|---|\n| 1 | 2 | 3 |",
+	} {
+		if _, err := SanitizeSection(input, "Recent Changes", 2); err == nil {
+			t.Fatalf("expected %q to be rejected", input)
+		}
+	}
+
+	valid := "| a | b |\n|---|:-:|\n| 1 | 2 |\n\nSee [docs](https://example.com) and `x](y`."
+	if _, err := SanitizeSection(valid, "Recent Changes", 2); err != nil {
+		t.Fatalf("expected valid markdown to pass, got %v", err)
+	}
+}
+
+func TestSectionLevel(t *testing.T) {
+	content := "# Title\n\n### Deep\ntext\n"
+	if got := SectionLevel(content, "Deep"); got != 3 {
+		t.Fatalf("expected level 3, got %d", got)
+	}
+	if got := SectionLevel(content, "Missing"); got != 2 {
+		t.Fatalf("expected default level 2, got %d", got)
+	}
+}
+
+func TestSectionLine(t *testing.T) {
+	content := "# Title\n\nIntro\n\n## API\nbody\n"
+	if got := SectionLine(content, "API"); got != 5 {
+		t.Fatalf("expected API heading on line 5, got %d", got)
+	}
+	if got := SectionLine(content, "Missing"); got != 0 {
+		t.Fatalf("expected 0 for missing section, got %d", got)
+	}
+}
+
+func TestValidateMermaid(t *testing.T) {
+	valid := "flowchart LR\n  cli[CLI] --> orchestrator\n  subgraph core\n    orchestrator --> state[(SQLite)]\n  end\n  %% comment\n  classDef hot fill:#f96"
+	if err := ValidateMermaid(valid); err != nil {
+		t.Fatalf("expected valid diagram, got %v", err)
+	}
+
+	for name, src := range map[string]string{
+		"empty":        "",
+		"unknown type": "pie\n  a --> b",
+		"unbalanced":   "graph TD\n  a[CLI --> b",
+		"open quote":   "graph TD\n  a[\"CLI] --> b",
+		"subgraph":     "graph TD\n  subgraph core\n  a --> b",
+		"prose":        "graph TD\n  a --> b\n  -> this diagram shows",
+	} {
+		if err := ValidateMermaid(src); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestReplaceMermaidBlock(t *testing.T) {
+	body := "Intro.\n\n```mermaid\ngraph TD\n  a --> b\n```\n\nOutro."
+	out := ReplaceMermaidBlock(body, "graph TD\n  a --> c")
+	if out != "Intro.\n\n```mermaid\ngraph TD\n  a --> c\n```\n\nOutro." {
+		t.Fatalf("unexpected replacement:\n%s", out)
+	}
+	if out := ReplaceMermaidBlock("Intro.", "graph TD"); out != "Intro.\n\n```mermaid\ngraph TD\n```" {
+		t.Fatalf("expected block appended, got:\n%s", out)
+	}
+	if got := ExtractMermaid("Here:\n```mermaid\ngraph TD\n  a --> b\n```\nDone"); got != "graph TD\n  a --> b" {
+		t.Fatalf("unexpected extraction %q", got)
+	}
+}
@@ -0,0 +1,66 @@
+package doc
+
+import (
+	"strings"
+)
+
+const (
+	tocStart = "<!-- toc -->"
+	tocEnd   = "<!-- tocstop -->"
+)
+
+// RegenerateTOC rewrites the table of contents between a <!-- toc --> line
+// and the next <!-- tocstop --> (or <!-- /toc -->) line with a nested list
+// of links to the document's headings from level 2 to maxLevel. A start
+// marker without an end marker gets one. Documents without markers are
+// returned unchanged.
+func RegenerateTOC(content string, maxLevel int) string {
+	lines := strings.Split(content, "\n")
+	start, end := -1, -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if start < 0 && trimmed == tocStart {
+			start = i
+			continue
+		}
+		if start >= 0 && (trimmed == tocEnd || trimmed == "<!-- /toc -->") {
+			end = i
+			break
+		}
+	}
+	if start < 0 {
+		return content
+	}
+
+	sections := Sections(content)
+	listed := func(section Section) bool {
+		inBlock := end >= 0 && section.Line > start && section.Line < end
+		return section.Level >= 2 && section.Level <= maxLevel && !inBlock
+	}
+	minLevel := 0
+	for _, section := range sections {
+		if listed(section) && (minLevel == 0 || section.Level < minLevel) {
+			minLevel = section.Level
+		}
+	}
+	var toc []string
+	for _, section := range sections {
+		if listed(section) {
+			indent := strings.Repeat("  ", section.Level-minLevel)
+			toc = append(toc, indent+"- ["+plainTitle(section.Title)+"](#"+section.Anchor+")")
+		}
+	}
+
+	block := []string{lines[start], ""}
+	if len(toc) > 0 {
+		block = append(append(block, toc...), "")
+	}
+	rest := lines[start+1:]
+	if end >= 0 {
+		block = append(block, lines[end])
+		rest = lines[end+1:]
+	} else {
+		block = append(block, tocEnd)
+	}
+	return strings.Join(append(append(append([]string{}, lines[:start]...), block...), rest...), "\n")
+}
@@ -0,0 +1,88 @@
+package doc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type snapshot struct {
+	path    string
+	content []byte
+	perm    os.FileMode
+	existed bool
+}
+
+// Transaction writes doc files atomically while remembering their original
+// content, so a failure in a later step (committing, recording state) can put
+// the working tree back the way it was.
+type Transaction struct {
+	snapshots []snapshot
+	seen      map[string]bool
+}
+
+func NewTransaction() *Transaction {
+	return &Transaction{seen: map[string]bool{}}
+}
+
+func (t *Transaction) Write(path string, content []byte, perm os.FileMode) error {
+	if !t.seen[path] {
+		snap := snapshot{path: path, perm: perm}
+		original, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			snap.content = original
+			snap.existed = true
+			if info, statErr := os.Stat(path); statErr == nil {
+				snap.perm = info.Mode().Perm()
+			}
+		case !errors.Is(err, os.ErrNotExist):
+			return fmt.Errorf("snapshot %s: %w", path, err)
+		}
+		t.snapshots = append(t.snapshots, snap)
+		t.seen[path] = true
+		if !snap.existed {
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+		}
+	}
+
+	return AtomicWriteFile(path, content, perm)
+}
+
+// Files lists the paths that Rollback would restore.
+func (t *Transaction) Files() []string {
+	files := make([]string, 0, len(t.snapshots))
+	for _, snap := range t.snapshots {
+		files = append(files, snap.path)
+	}
+	return files
+}
+
+// Commit keeps the written content; a later Rollback is a no-op.
+func (t *Transaction) Commit() {
+	t.snapshots = nil
+	t.seen = map[string]bool{}
+}
+
+// Rollback restores every written file to its snapshot, newest first, and
+// removes files that did not exist before.
+func (t *Transaction) Rollback() error {
+	var errs []error
+	for i := len(t.snapshots) - 1; i >= 0; i-- {
+		snap := t.snapshots[i]
+		if !snap.existed {
+			if err := os.Remove(snap.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, fmt.Errorf("remove %s: %w", snap.path, err))
+			}
+			continue
+		}
+		if err := AtomicWriteFile(snap.path, snap.content, snap.perm); err != nil {
+			errs = append(errs, fmt.Errorf("restore %s: %w", snap.path, err))
+		}
+	}
+	t.Commit()
+	return errors.Join(errs...)
+}
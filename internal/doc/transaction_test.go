@@ -0,0 +1,70 @@
+package doc
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransactionRollbackRestoresOriginals(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "README.md")
+	created := filepath.Join(dir, "NEW.md")
+	if err := os.WriteFile(existing, []byte("original"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := NewTransaction()
+	if err := tx.Write(existing, []byte("first"), 0o644); err != nil {
+		t.Fatalf("write existing: %v", err)
+	}
+	if err := tx.Write(existing, []byte("second"), 0o644); err != nil {
+		t.Fatalf("rewrite existing: %v", err)
+	}
+	if err := tx.Write(created, []byte("new"), 0o644); err != nil {
+		t.Fatalf("write new file: %v", err)
+	}
+	if got := tx.Files(); len(got) != 2 {
+		t.Fatalf("expected two snapshotted files, got %v", got)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+
+	content, err := os.ReadFile(existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "original" {
+		t.Fatalf("expected original content restored, got %q", string(content))
+	}
+	if _, err := os.Stat(created); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected new file to be removed, got %v", err)
+	}
+}
+
+func TestTransactionCommitKeepsWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "README.md")
+	if err := os.WriteFile(path, []byte("original"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := NewTransaction()
+	if err := tx.Write(path, []byte("updated"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	tx.Commit()
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("rollback after commit: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "updated" {
+		t.Fatalf("expected committed content to stay, got %q", string(content))
+	}
+}
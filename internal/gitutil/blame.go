@@ -0,0 +1,57 @@
+package gitutil
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BlameLine is the commit that last changed one line of a file, with the
+// line's text. Commit is empty for lines not committed yet.
+type BlameLine struct {
+	Commit string
+	Author string
+	Time   time.Time
+	Text   string
+}
+
+// BlameFile returns the last commit for every line of a working tree file, in
+// line order. Paths are relative to GetRepoRoot.
+func (h *CLIHelper) BlameFile(path string) ([]BlameLine, error) {
+	out, err := runIn(h.commitRoot(), nil, "blame", "--line-porcelain", "--", path)
+	if err != nil {
+		return nil, err
+	}
+	return parseLinePorcelain(out), nil
+}
+
+func parseLinePorcelain(out string) []BlameLine {
+	var lines []BlameLine
+	var current BlameLine
+	header := true
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			current.Text = line[1:]
+			lines = append(lines, current)
+			header = true
+		case header:
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			current = BlameLine{Commit: fields[0]}
+			if strings.Trim(current.Commit, "0") == "" {
+				current.Commit = ""
+			}
+			header = false
+		case strings.HasPrefix(line, "author "):
+			current.Author = strings.TrimPrefix(line, "author ")
+		case strings.HasPrefix(line, "committer-time "):
+			if ts, err := strconv.ParseInt(strings.TrimPrefix(line, "committer-time "), 10, 64); err == nil {
+				current.Time = time.Unix(ts, 0)
+			}
+		}
+	}
+	return lines
+}
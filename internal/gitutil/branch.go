@@ -0,0 +1,66 @@
+package gitutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UseTargetBranch makes doc commits land on branch instead of the current
+// branch. The branch is checked out in a worktree under the git directory,
+// created from HEAD when the branch does not exist yet. GetRepoRoot then
+// returns the worktree, so docs are read and written there, while commit
+// history is still read from the current branch.
+func (h *CLIHelper) UseTargetBranch(branch string) error {
+	if _, err := h.run("check-ref-format", "--branch", branch); err != nil {
+		return fmt.Errorf("invalid target branch %q: %w", branch, err)
+	}
+	gitDir, err := h.run("rev-parse", "--git-common-dir")
+	if err != nil {
+		return err
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(h.repoRoot, gitDir)
+	}
+	path := filepath.Join(gitDir, "git-doc", "worktrees", strings.ReplaceAll(branch, "/", "-"))
+
+	if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+		_, _ = h.run("worktree", "prune")
+		args := []string{"worktree", "add", "-b", branch, path, "HEAD"}
+		if _, err := h.run("rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+			args = []string{"worktree", "add", path, branch}
+		}
+		if _, err := h.run(args...); err != nil {
+			return fmt.Errorf("check out target branch %s: %w", branch, err)
+		}
+	}
+	h.docRoot = path
+	return nil
+}
+
+func (h *CLIHelper) commitRoot() string {
+	if h.docRoot != "" {
+		return h.docRoot
+	}
+	return h.repoRoot
+}
+
+// runCommit runs a command that stages or commits docs in the checkout doc
+// commits land on. Hooks are disabled in a target branch worktree, so the
+// repository's git-doc hooks do not run against the doc branch.
+func (h *CLIHelper) runCommit(args ...string) (string, error) {
+	if h.docRoot == "" {
+		return h.run(args...)
+	}
+	return runIn(h.docRoot, nil, append([]string{"-c", "core.hooksPath=" + os.DevNull}, args...)...)
+}
+
+func (h *CLIHelper) commitHEAD() (string, error) {
+	out, err := runIn(h.commitRoot(), nil, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
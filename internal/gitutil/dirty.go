@@ -0,0 +1,93 @@
+package gitutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// onlyArgs builds a commit command that records files and nothing else that
+// happens to be staged.
+func (h *CLIHelper) onlyArgs(files []string, args ...string) []string {
+	out := h.commitArgs(append(args, "--only")...)
+	return append(append(out, "--"), files...)
+}
+
+// checkDirty returns the tracked paths other than files with uncommitted
+// changes, or an error when the dirty tree policy is "abort" and there are
+// any.
+func (h *CLIHelper) checkDirty(files []string) ([]string, error) {
+	out, err := runIn(h.commitRoot(), nil, "diff", "HEAD", "--name-only", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var dirty []string
+	for _, path := range strings.Split(out, "\x00") {
+		if path != "" && !slices.Contains(files, path) {
+			dirty = append(dirty, path)
+		}
+	}
+	if len(dirty) > 0 && h.commit.DirtyTree == "abort" {
+		if len(dirty) > 3 {
+			dirty = append(dirty[:3], fmt.Sprintf("%d more", len(dirty)-3))
+		}
+		return nil, fmt.Errorf("working tree has uncommitted changes (%s); commit or stash them, or change git.dirty_tree_policy", strings.Join(dirty, ", "))
+	}
+	return dirty, nil
+}
+
+// commitInWorktree builds a commit of files on top of HEAD in a temporary
+// index, so neither the real index nor other changes in the working tree are
+// touched and nothing is checked out, then moves the current branch to the
+// new commit. Hooks do not run.
+func (h *CLIHelper) commitInWorktree(files []string, message string) (string, error) {
+	head, err := h.commitHEAD()
+	if err != nil {
+		return "", err
+	}
+	dir, err := os.MkdirTemp("", "git-doc-commit-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	root := h.commitRoot()
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(dir, "index")}
+
+	if _, err := runEnv(root, env, nil, "read-tree", head); err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		blob, err := runIn(root, nil, "hash-object", "-w", "--", file)
+		if err != nil {
+			return "", err
+		}
+		mode := "100644"
+		if entry, err := runIn(root, nil, "ls-tree", head, "--", file); err == nil && strings.HasPrefix(entry, "100755 ") {
+			mode = "100755"
+		}
+		info := mode + "," + strings.TrimSpace(blob) + "," + file
+		if _, err := runEnv(root, env, nil, "update-index", "--add", "--cacheinfo", info); err != nil {
+			return "", err
+		}
+	}
+	tree, err := runEnv(root, env, nil, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	out, err := runIn(root, nil, h.commitArgs("commit-tree", strings.TrimSpace(tree), "-p", head, "-m", message)...)
+	if err != nil {
+		return "", err
+	}
+	commit := strings.TrimSpace(out)
+
+	if _, err := h.runCommit("update-ref", "-m", "git-doc: doc commit", "HEAD", commit, head); err != nil {
+		return "", err
+	}
+	// The index still holds the docs as they were in the old HEAD.
+	if _, err := h.runCommit(append([]string{"reset", "-q", "--"}, files...)...); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
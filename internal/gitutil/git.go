@@ -1,8 +1,11 @@
 package gitutil
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -17,13 +20,24 @@ type CommitInfo struct {
 	Subject   string
 }
 
+// Rewrite is one "<old-sha> <new-sha>" pair git passes to the post-rewrite
+// hook on stdin after an amend or rebase.
+type Rewrite struct {
+	Old string
+	New string
+}
+
 type Helper interface {
 	GetRepoRoot() (string, error)
 	GetCurrentHEAD() (string, error)
 	GetLastProcessedRange(fromHash, toHash string) ([]CommitInfo, error)
 	GetCommitDiff(commit string) (string, error)
 	GetCommitMessage(commit string) (string, error)
+	GetCommitInfo(commit string) (CommitInfo, error)
 	GetChangedFiles(commit string) ([]string, error)
+	GetCommitParents(commit string) ([]string, error)
+	GetChangedFilesBetween(fromHash, toHash string) ([]string, error)
+	GetDiffBetween(fromHash, toHash string) (string, error)
 	StageAndCommit(files []string, message string) (string, error)
 	StageAndAmend(files []string) (string, error)
 	RevertCommit(commit string) error
@@ -31,6 +45,51 @@ type Helper interface {
 
 type CLIHelper struct {
 	repoRoot string
+	// docRoot is the worktree of the target branch doc commits land on;
+	// empty commits to the current branch in repoRoot.
+	docRoot string
+	commit  CommitOptions
+}
+
+// CommitOptions shape the commits CLIHelper creates. Empty fields keep the
+// repository's git configuration.
+type CommitOptions struct {
+	Name  string
+	Email string
+	// Sign overrides commit.gpgSign with "true" or "false".
+	Sign       string
+	SigningKey string
+	// NoVerify skips the pre-commit and commit-msg hooks.
+	NoVerify bool
+	// DirtyTree decides what happens to other uncommitted changes: "only"
+	// (the default) leaves them out of doc commits, "abort" refuses to
+	// commit and "worktree" commits in a temporary worktree.
+	DirtyTree string
+}
+
+func (h *CLIHelper) SetCommitOptions(opts CommitOptions) {
+	h.commit = opts
+}
+
+// commitArgs prefixes a commit-creating git command with the identity and
+// signing overrides.
+func (h *CLIHelper) commitArgs(args ...string) []string {
+	var out []string
+	for _, kv := range [][2]string{
+		{"user.name", h.commit.Name},
+		{"user.email", h.commit.Email},
+		{"commit.gpgSign", h.commit.Sign},
+		{"user.signingKey", h.commit.SigningKey},
+	} {
+		if kv[1] != "" {
+			out = append(out, "-c", kv[0]+"="+kv[1])
+		}
+	}
+	out = append(out, args...)
+	if h.commit.NoVerify && args[0] == "commit" {
+		out = append(out, "--no-verify")
+	}
+	return out
 }
 
 func NewHelper(repoRoot string) *CLIHelper {
@@ -46,8 +105,10 @@ func GetRepoRoot() (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// GetRepoRoot returns the checkout docs are read from and written to: the
+// target branch worktree when one is used, else the repository root.
 func (h *CLIHelper) GetRepoRoot() (string, error) {
-	return h.repoRoot, nil
+	return h.commitRoot(), nil
 }
 
 func (h *CLIHelper) GetCurrentHEAD() (string, error) {
@@ -78,28 +139,55 @@ func (h *CLIHelper) GetLastProcessedRange(fromHash, toHash string) ([]CommitInfo
 	lines := strings.Split(strings.TrimSpace(out), "\n")
 	commits := make([]CommitInfo, 0, len(lines))
 	for _, line := range lines {
-		parts := strings.SplitN(line, "|", 5)
-		if len(parts) != 5 {
-			continue
-		}
-
-		ts, parseErr := parseUnix(parts[3])
+		info, ok, parseErr := parseCommitLine(line)
 		if parseErr != nil {
 			return nil, parseErr
 		}
-
-		commits = append(commits, CommitInfo{
-			Hash:      parts[0],
-			Author:    parts[1],
-			Email:     parts[2],
-			Timestamp: ts,
-			Subject:   parts[4],
-		})
+		if !ok {
+			continue
+		}
+		commits = append(commits, info)
 	}
 
 	return commits, nil
 }
 
+func (h *CLIHelper) GetCommitInfo(commit string) (CommitInfo, error) {
+	out, err := h.run("log", "-1", "--pretty=format:%H|%an|%ae|%at|%s", commit)
+	if err != nil {
+		return CommitInfo{}, err
+	}
+
+	info, ok, err := parseCommitLine(strings.TrimSpace(out))
+	if err != nil {
+		return CommitInfo{}, err
+	}
+	if !ok {
+		return CommitInfo{}, fmt.Errorf("unexpected git log output for %s", commit)
+	}
+	return info, nil
+}
+
+func parseCommitLine(line string) (CommitInfo, bool, error) {
+	parts := strings.SplitN(line, "|", 5)
+	if len(parts) != 5 {
+		return CommitInfo{}, false, nil
+	}
+
+	ts, err := parseUnix(parts[3])
+	if err != nil {
+		return CommitInfo{}, false, err
+	}
+
+	return CommitInfo{
+		Hash:      parts[0],
+		Author:    parts[1],
+		Email:     parts[2],
+		Timestamp: ts,
+		Subject:   parts[4],
+	}, true, nil
+}
+
 func (h *CLIHelper) GetCommitDiff(commit string) (string, error) {
 	return h.run("show", "--unified=3", commit)
 }
@@ -117,16 +205,44 @@ func (h *CLIHelper) GetChangedFiles(commit string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	return splitFileList(out), nil
+}
+
+func (h *CLIHelper) GetCommitParents(commit string) ([]string, error) {
+	out, err := h.run("rev-list", "--parents", "-n", "1", commit)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("unexpected git rev-list output for %s", commit)
+	}
+	return fields[1:], nil
+}
 
+func (h *CLIHelper) GetChangedFilesBetween(fromHash, toHash string) ([]string, error) {
+	out, err := h.run("diff", "--name-only", fromHash, toHash)
+	if err != nil {
+		return nil, err
+	}
+	return splitFileList(out), nil
+}
+
+func (h *CLIHelper) GetDiffBetween(fromHash, toHash string) (string, error) {
+	return h.run("diff", "--unified=3", fromHash, toHash)
+}
+
+func splitFileList(out string) []string {
 	if strings.TrimSpace(out) == "" {
-		return nil, nil
+		return nil
 	}
 
 	lines := strings.Split(strings.TrimSpace(out), "\n")
 	for i := range lines {
 		lines[i] = filepath.ToSlash(strings.TrimSpace(lines[i]))
 	}
-	return lines, nil
+	return lines
 }
 
 func (h *CLIHelper) StageAndCommit(files []string, message string) (string, error) {
@@ -134,16 +250,25 @@ func (h *CLIHelper) StageAndCommit(files []string, message string) (string, erro
 		return "", nil
 	}
 
+	dirty, err := h.checkDirty(files)
+	if err != nil {
+		return "", err
+	}
+	if len(dirty) > 0 && h.commit.DirtyTree == "worktree" {
+		return h.commitInWorktree(files, message)
+	}
+
 	args := append([]string{"add"}, files...)
-	if _, err := h.run(args...); err != nil {
+	if _, err := h.runCommit(args...); err != nil {
 		return "", err
 	}
 
-	if _, err := h.run("commit", "-m", message); err != nil {
+	if _, err := h.runCommit(h.onlyArgs(files, "commit", "-m", message)...); err != nil {
+		h.unstage(files)
 		return "", err
 	}
 
-	return h.GetCurrentHEAD()
+	return h.commitHEAD()
 }
 
 func (h *CLIHelper) StageAndAmend(files []string) (string, error) {
@@ -151,26 +276,74 @@ func (h *CLIHelper) StageAndAmend(files []string) (string, error) {
 		return "", nil
 	}
 
+	// A temporary worktree cannot amend the commit checked out here, so the
+	// worktree policy amends with --only like the default.
+	if _, err := h.checkDirty(files); err != nil {
+		return "", err
+	}
+
 	args := append([]string{"add"}, files...)
-	if _, err := h.run(args...); err != nil {
+	if _, err := h.runCommit(args...); err != nil {
 		return "", err
 	}
 
-	if _, err := h.run("commit", "--amend", "--no-edit"); err != nil {
+	if _, err := h.runCommit(h.onlyArgs(files, "commit", "--amend", "--no-edit")...); err != nil {
+		h.unstage(files)
 		return "", err
 	}
 
-	return h.GetCurrentHEAD()
+	return h.commitHEAD()
+}
+
+func (h *CLIHelper) unstage(files []string) {
+	args := append([]string{"reset", "-q", "--"}, files...)
+	_, _ = h.runCommit(args...)
 }
 
 func (h *CLIHelper) RevertCommit(commit string) error {
-	_, err := h.run("revert", "--no-edit", commit)
+	_, err := h.runCommit(h.commitArgs("revert", "--no-edit", commit)...)
 	return err
 }
 
+func ParseRewrites(r io.Reader) ([]Rewrite, error) {
+	var rewrites []Rewrite
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid rewrite line %q", scanner.Text())
+		}
+		rewrites = append(rewrites, Rewrite{Old: fields[0], New: fields[1]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read rewrites: %w", err)
+	}
+	return rewrites, nil
+}
+
 func (h *CLIHelper) run(args ...string) (string, error) {
+	return h.runWithStdin(nil, args...)
+}
+
+func (h *CLIHelper) runWithStdin(stdin io.Reader, args ...string) (string, error) {
+	return runIn(h.repoRoot, stdin, args...)
+}
+
+func runIn(dir string, stdin io.Reader, args ...string) (string, error) {
+	return runEnv(dir, nil, stdin, args...)
+}
+
+// runEnv is runIn with env added to the environment of git.
+func runEnv(dir string, env []string, stdin io.Reader, args ...string) (string, error) {
 	cmd := exec.Command("git", args...)
-	cmd.Dir = h.repoRoot
+	cmd.Dir = dir
+	cmd.Stdin = stdin
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
@@ -191,3 +364,18 @@ func parseUnix(s string) (time.Time, error) {
 	}
 	return time.Unix(int64(unixInt.Seconds()), 0), nil
 }
+
+// ListTrackedFiles returns the paths of all files in the index.
+func (h *CLIHelper) ListTrackedFiles() ([]string, error) {
+	out, err := h.run("ls-files", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, file := range strings.Split(out, "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -59,6 +60,14 @@ func TestCLIHelperCommitLifecycle(t *testing.T) {
 		t.Fatalf("unexpected commit message: %q", msg)
 	}
 
+	info, err := h.GetCommitInfo(firstHash)
+	if err != nil {
+		t.Fatalf("GetCommitInfo failed: %v", err)
+	}
+	if info.Hash != firstHash || info.Email != "git-doc-test@example.com" || info.Subject != "feat: add a" {
+		t.Fatalf("unexpected commit info: %#v", info)
+	}
+
 	files, err := h.GetChangedFiles(firstHash)
 	if err != nil {
 		t.Fatalf("GetChangedFiles failed: %v", err)
@@ -157,3 +166,426 @@ func runGit(t *testing.T, dir string, args ...string) string {
 	}
 	return string(out)
 }
+
+func TestCLIHelperMergeCommitHelpers(t *testing.T) {
+	repo := initTestRepo(t)
+	h := NewHelper(repo)
+
+	base := strings.TrimSpace(runGit(t, repo, "rev-parse", "HEAD"))
+	runGit(t, repo, "checkout", "-q", "-b", "feature")
+	if err := os.WriteFile(filepath.Join(repo, "feature.txt"), []byte("feature\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	featureHash, err := h.StageAndCommit([]string{"feature.txt"}, "feat: feature work")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runGit(t, repo, "checkout", "-q", "-")
+	if err := os.WriteFile(filepath.Join(repo, "main.txt"), []byte("main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mainHash, err := h.StageAndCommit([]string{"main.txt"}, "feat: main work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "merge", "--no-ff", "-q", "-m", "Merge branch 'feature'", "feature")
+	mergeHash := strings.TrimSpace(runGit(t, repo, "rev-parse", "HEAD"))
+
+	parents, err := h.GetCommitParents(mergeHash)
+	if err != nil {
+		t.Fatalf("GetCommitParents failed: %v", err)
+	}
+	if len(parents) != 2 || parents[0] != mainHash || parents[1] != featureHash {
+		t.Fatalf("unexpected merge parents: %v", parents)
+	}
+
+	if single, err := h.GetCommitParents(mainHash); err != nil || len(single) != 1 || single[0] != base {
+		t.Fatalf("unexpected parents for regular commit: %v err=%v", single, err)
+	}
+
+	if files, err := h.GetChangedFiles(mergeHash); err != nil || len(files) != 0 {
+		t.Fatalf("expected diff-tree to report nothing for merge, got %v err=%v", files, err)
+	}
+
+	files, err := h.GetChangedFilesBetween(parents[0], mergeHash)
+	if err != nil {
+		t.Fatalf("GetChangedFilesBetween failed: %v", err)
+	}
+	if len(files) != 1 || files[0] != "feature.txt" {
+		t.Fatalf("expected first-parent diff to contain feature.txt, got %v", files)
+	}
+
+	diff, err := h.GetDiffBetween(parents[0], mergeHash)
+	if err != nil {
+		t.Fatalf("GetDiffBetween failed: %v", err)
+	}
+	if !strings.Contains(diff, "+feature") {
+		t.Fatalf("expected first-parent diff content, got %q", diff)
+	}
+}
+
+func TestParseRewrites(t *testing.T) {
+	input := "aaa111 bbb222\n\nccc333 ddd444 extra\n"
+	rewrites, err := ParseRewrites(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parse rewrites: %v", err)
+	}
+	if len(rewrites) != 2 || rewrites[0] != (Rewrite{Old: "aaa111", New: "bbb222"}) || rewrites[1] != (Rewrite{Old: "ccc333", New: "ddd444"}) {
+		t.Fatalf("unexpected rewrites: %+v", rewrites)
+	}
+
+	if _, err := ParseRewrites(strings.NewReader("lonely\n")); err == nil {
+		t.Fatalf("expected malformed line to fail")
+	}
+}
+
+func TestCLIHelperGetCommitsMetadata(t *testing.T) {
+	repo := initTestRepo(t)
+	h := NewHelper(repo)
+
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "bin.dat"), []byte{0, 1, 2}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	first, err := h.StageAndCommit([]string{"a.txt", "bin.dat"}, "feat: add files\n\nWith a body.")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runGit(t, repo, "checkout", "-q", "-b", "feature")
+	if err := os.WriteFile(filepath.Join(repo, "feature.txt"), []byte("feature\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.StageAndCommit([]string{"feature.txt"}, "feat: feature work"); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "checkout", "-q", "-")
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	second, err := h.StageAndCommit([]string{"a.txt"}, "fix: trim a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "merge", "--no-ff", "-q", "-m", "Merge branch 'feature'", "feature")
+	merge := strings.TrimSpace(runGit(t, repo, "rev-parse", "HEAD"))
+
+	metadata, err := h.GetCommitsMetadata([]string{first, second, merge})
+	if err != nil {
+		t.Fatalf("GetCommitsMetadata failed: %v", err)
+	}
+	if len(metadata) != 3 {
+		t.Fatalf("expected 3 commits, got %d", len(metadata))
+	}
+
+	m := metadata[first]
+	if m.Subject != "feat: add files" || m.Message != "feat: add files\n\nWith a body." || m.Email != "git-doc-test@example.com" {
+		t.Fatalf("unexpected first commit metadata: %#v", m)
+	}
+	if len(m.Files) != 2 || m.Files[0] != (FileChange{Path: "a.txt", Status: "A", Added: 2}) || m.Files[1] != (FileChange{Path: "bin.dat", Status: "A", Binary: true}) {
+		t.Fatalf("unexpected first commit files: %#v", m.Files)
+	}
+
+	m = metadata[second]
+	if len(m.Parents) != 1 || m.Parents[0] != first || len(m.Files) != 1 || m.Files[0] != (FileChange{Path: "a.txt", Status: "M", Deleted: 1}) {
+		t.Fatalf("unexpected second commit metadata: %#v", m)
+	}
+
+	m = metadata[merge]
+	if len(m.Parents) != 2 || m.Parents[0] != second {
+		t.Fatalf("unexpected merge parents: %v", m.Parents)
+	}
+	if paths := m.FilePaths(); len(paths) != 1 || paths[0] != "feature.txt" {
+		t.Fatalf("expected merge files against first parent, got %v", paths)
+	}
+}
+
+func TestCLIHelperBlameFile(t *testing.T) {
+	repo := initTestRepo(t)
+	h := NewHelper(repo)
+
+	if err := os.WriteFile(filepath.Join(repo, "doc.md"), []byte("# Title\nold\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	first, err := h.StageAndCommit([]string{"doc.md"}, "docs: add")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "doc.md"), []byte("# Title\nnew\nuncommitted\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "add", "doc.md")
+	runGit(t, repo, "commit", "-q", "-m", "docs: edit")
+	second := strings.TrimSpace(runGit(t, repo, "rev-parse", "HEAD"))
+	if err := os.WriteFile(filepath.Join(repo, "doc.md"), []byte("# Title\nnew\nuncommitted\nlocal\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := h.BlameFile("doc.md")
+	if err != nil {
+		t.Fatalf("BlameFile failed: %v", err)
+	}
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 blamed lines, got %+v", lines)
+	}
+	if lines[0].Commit != first || lines[1].Commit != second || lines[3].Commit != "" || lines[1].Time.IsZero() {
+		t.Fatalf("unexpected blame: %+v", lines)
+	}
+	if lines[1].Text != "new" || lines[3].Text != "local" || lines[1].Author == "" {
+		t.Fatalf("expected blame to carry line text and author, got %+v", lines)
+	}
+}
+
+func TestCLIHelperCommitOptions(t *testing.T) {
+	repo := initTestRepo(t)
+	h := NewHelper(repo)
+
+	hook := filepath.Join(repo, ".git", "hooks", "pre-commit")
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\nexit 1\n"), 0o755); err != nil {
+		t.Fatalf("write hook: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := h.StageAndCommit([]string{"a.txt"}, "docs: blocked"); err == nil {
+		t.Fatalf("expected the pre-commit hook to reject the commit")
+	}
+
+	h.SetCommitOptions(CommitOptions{Name: "git-doc bot", Email: "bot@example.com", Sign: "false", NoVerify: true})
+	hash, err := h.StageAndCommit([]string{"a.txt"}, "docs: update a")
+	if err != nil {
+		t.Fatalf("StageAndCommit with NoVerify failed: %v", err)
+	}
+	if got := strings.TrimSpace(runGit(t, repo, "log", "-1", "--format=%an <%ae>|%cn <%ce>", hash)); got != "git-doc bot <bot@example.com>|git-doc bot <bot@example.com>" {
+		t.Fatalf("unexpected identity: %q", got)
+	}
+}
+
+func TestCLIHelperTargetBranch(t *testing.T) {
+	repo := initTestRepo(t)
+	h := NewHelper(repo)
+	head, err := h.GetCurrentHEAD()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.UseTargetBranch("docs/auto"); err != nil {
+		t.Fatalf("UseTargetBranch failed: %v", err)
+	}
+	root, err := h.GetRepoRoot()
+	if err != nil || root == repo {
+		t.Fatalf("expected docs to move to a worktree, got %q err=%v", root, err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "README.md"), []byte("# Docs\n"), 0o644); err != nil {
+		t.Fatalf("write doc: %v", err)
+	}
+	docCommit, err := h.StageAndCommit([]string{"README.md"}, "docs: update readme")
+	if err != nil {
+		t.Fatalf("StageAndCommit on target branch failed: %v", err)
+	}
+
+	if got := strings.TrimSpace(runGit(t, repo, "rev-parse", "docs/auto")); got != docCommit {
+		t.Fatalf("expected docs/auto at %s, got %s", docCommit, got)
+	}
+	if current, _ := h.GetCurrentHEAD(); current != head {
+		t.Fatalf("expected the current branch to stay at %s, got %s", head, current)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "README.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected the main checkout to stay clean, got err=%v", err)
+	}
+
+	reopened := NewHelper(repo)
+	if err := reopened.UseTargetBranch("docs/auto"); err != nil {
+		t.Fatalf("reusing the worktree failed: %v", err)
+	}
+	if again, _ := reopened.GetRepoRoot(); again != root {
+		t.Fatalf("expected the same worktree, got %q want %q", again, root)
+	}
+}
+
+func TestCLIHelperDirtyTreePolicies(t *testing.T) {
+	for _, policy := range []string{"only", "abort", "worktree"} {
+		t.Run(policy, func(t *testing.T) {
+			repo := initTestRepo(t)
+			h := NewHelper(repo)
+			h.SetCommitOptions(CommitOptions{DirtyTree: policy})
+			head, _ := h.GetCurrentHEAD()
+
+			if err := os.WriteFile(filepath.Join(repo, "work.go"), []byte("package work\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			runGit(t, repo, "add", "work.go")
+			if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("# Docs\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			docCommit, err := h.StageAndCommit([]string{"README.md"}, "docs: update readme")
+			if policy == "abort" {
+				if err == nil || !strings.Contains(err.Error(), "work.go") {
+					t.Fatalf("expected abort naming work.go, got %v", err)
+				}
+				if current, _ := h.GetCurrentHEAD(); current != head {
+					t.Fatalf("expected no commit, HEAD moved to %s", current)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("StageAndCommit failed: %v", err)
+			}
+			if current, _ := h.GetCurrentHEAD(); current != docCommit {
+				t.Fatalf("expected HEAD at the doc commit %s, got %s", docCommit, current)
+			}
+			if files := strings.TrimSpace(runGit(t, repo, "show", "--name-only", "--format=", docCommit)); files != "README.md" {
+				t.Fatalf("expected only README.md in the doc commit, got %q", files)
+			}
+			if status := runGit(t, repo, "status", "--porcelain"); status != "A  work.go\n" {
+				t.Fatalf("expected work.go to stay staged and README.md clean, got %q", status)
+			}
+			if worktrees := strings.Count(runGit(t, repo, "worktree", "list"), "\n"); worktrees != 1 {
+				t.Fatalf("expected no extra worktree, got %d", worktrees)
+			}
+		})
+	}
+}
+
+func TestCLIHelperGetSubmoduleChanges(t *testing.T) {
+	lib := initTestRepo(t)
+	from := strings.TrimSpace(runGit(t, lib, "rev-parse", "HEAD"))
+	if err := os.WriteFile(filepath.Join(lib, "button.go"), []byte("package ui\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, lib, "add", "button.go")
+	runGit(t, lib, "commit", "-m", "feat: button")
+	to := strings.TrimSpace(runGit(t, lib, "rev-parse", "HEAD"))
+
+	repo := initTestRepo(t)
+	runGit(t, repo, "-c", "protocol.file.allow=always", "submodule", "add", lib, "libs/ui")
+	h := NewHelper(repo)
+
+	files, diff, err := h.GetSubmoduleChanges("libs/ui", from, to)
+	if err != nil {
+		t.Fatalf("GetSubmoduleChanges failed: %v", err)
+	}
+	if len(files) != 1 || files[0] != "libs/ui/button.go" {
+		t.Fatalf("unexpected submodule files: %v", files)
+	}
+	if !strings.Contains(diff, "diff --git a/libs/ui/button.go b/libs/ui/button.go") {
+		t.Fatalf("expected superproject paths in the diff, got %q", diff)
+	}
+
+	if _, _, err := h.GetSubmoduleChanges("libs/missing", from, to); err == nil {
+		t.Fatalf("expected an error for a submodule that is not checked out")
+	}
+}
+
+func TestCLIHelperShallowHistory(t *testing.T) {
+	origin := initTestRepo(t)
+	first := strings.TrimSpace(runGit(t, origin, "rev-parse", "HEAD"))
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(origin, name), []byte(name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, origin, "add", name)
+		runGit(t, origin, "commit", "-m", "add "+name)
+	}
+
+	clone := filepath.Join(t.TempDir(), "clone")
+	runGit(t, origin, "clone", "--depth", "1", "file://"+origin, clone)
+	h := NewHelper(clone)
+
+	if shallow, err := h.IsShallow(); err != nil || !shallow {
+		t.Fatalf("expected a shallow clone, got %v err=%v", shallow, err)
+	}
+	if h.HasCommit(first) {
+		t.Fatalf("expected %s to be missing from the shallow clone", first)
+	}
+	if err := h.Deepen(2); err != nil {
+		t.Fatalf("Deepen failed: %v", err)
+	}
+	if !h.HasCommit(first) {
+		t.Fatalf("expected %s after deepening", first)
+	}
+	if shallow, _ := NewHelper(origin).IsShallow(); shallow {
+		t.Fatalf("expected the full clone not to be shallow")
+	}
+}
+
+func TestCLIHelperMaterializeFile(t *testing.T) {
+	repo := initTestRepo(t)
+	h := NewHelper(repo)
+	if added, err := h.MaterializeFile("docs/guide.md"); err != nil || added {
+		t.Fatalf("expected nothing to do outside a sparse checkout, got %v err=%v", added, err)
+	}
+
+	for _, dir := range []string{"docs", "src"} {
+		if err := os.MkdirAll(filepath.Join(repo, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repo, "docs", "guide.md"), []byte("# Guide\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "src", "a.go"), []byte("package src\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-m", "add docs and src")
+	runGit(t, repo, "sparse-checkout", "set", "--cone", "src")
+
+	guide := filepath.Join(repo, "docs", "guide.md")
+	if _, err := os.Stat(guide); !os.IsNotExist(err) {
+		t.Fatalf("expected docs/guide.md outside the sparse checkout, got err=%v", err)
+	}
+	added, err := h.MaterializeFile("docs/guide.md")
+	if err != nil || !added {
+		t.Fatalf("MaterializeFile = %v err=%v", added, err)
+	}
+	if raw, err := os.ReadFile(guide); err != nil || string(raw) != "# Guide\n" {
+		t.Fatalf("expected docs/guide.md checked out, got %q err=%v", raw, err)
+	}
+	if added, _ := h.MaterializeFile("docs/missing.md"); added {
+		t.Fatalf("expected untracked paths to be left alone")
+	}
+}
+
+func TestCLIHelperGetChangeSets(t *testing.T) {
+	repo := initTestRepo(t)
+	if err := os.MkdirAll(filepath.Join(repo, "pkg", "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"README.md", "pkg/api/my server.go"} {
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-m", "add api")
+	runGit(t, repo, "checkout", "-q", "-b", "side")
+	if err := os.WriteFile(filepath.Join(repo, "side.txt"), []byte("side\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "add", "side.txt")
+	runGit(t, repo, "commit", "-m", "side")
+	runGit(t, repo, "checkout", "-q", "-")
+	runGit(t, repo, "merge", "--no-ff", "-m", "merge side", "side")
+
+	h := NewHelper(repo)
+	sets, err := h.GetChangeSets(0)
+	if err != nil {
+		t.Fatalf("GetChangeSets failed: %v", err)
+	}
+	var got []string
+	for _, set := range sets {
+		got = append(got, strings.Join(set, ","))
+	}
+	sort.Strings(got)
+	if strings.Join(got, " | ") != ".seed | README.md,pkg/api/my server.go | side.txt" {
+		t.Fatalf("expected one change set per non-merge commit, got %q", sets)
+	}
+	if sets, err = h.GetChangeSets(1); err != nil || len(sets) != 1 {
+		t.Fatalf("expected the limit to apply, got %q err=%v", sets, err)
+	}
+}
@@ -0,0 +1,33 @@
+package gitutil
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// GetChangeSets returns the files changed by each of the last limit non-merge
+// commits reachable from HEAD, newest first. A limit of 0 reads the whole
+// history.
+func (h *CLIHelper) GetChangeSets(limit int) ([][]string, error) {
+	args := []string{"log", "--no-merges", "--no-renames", "--name-only", "-z", "--format=%x1e"}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	out, err := h.run(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var sets [][]string
+	for _, record := range strings.Split(out, "\x1e") {
+		var files []string
+		for _, file := range splitNulTokens(record) {
+			files = append(files, filepath.ToSlash(file))
+		}
+		if len(files) > 0 {
+			sets = append(sets, files)
+		}
+	}
+	return sets, nil
+}
@@ -0,0 +1,155 @@
+package gitutil
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type FileChange struct {
+	Path    string
+	Status  string
+	Added   int
+	Deleted int
+	Binary  bool
+}
+
+// CommitMetadata is everything the orchestrator reads about a commit except
+// its diff. Merge commits list the files changed against their first parent.
+type CommitMetadata struct {
+	CommitInfo
+	Parents []string
+	Message string
+	Files   []FileChange
+}
+
+// FilePaths returns the changed paths in the order git reported them.
+func (m CommitMetadata) FilePaths() []string {
+	if len(m.Files) == 0 {
+		return nil
+	}
+	paths := make([]string, 0, len(m.Files))
+	for _, file := range m.Files {
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
+
+const metadataFormat = "%x1e%H%x00%P%x00%an%x00%ae%x00%at%x00%B%x00"
+
+// GetCommitsMetadata loads messages, parents, authors, name-status and numstat
+// for all commits with two git log invocations instead of several
+// subprocesses per commit. Commit hashes are passed on stdin, so the list
+// length is not limited by the command line.
+func (h *CLIHelper) GetCommitsMetadata(commits []string) (map[string]CommitMetadata, error) {
+	out := make(map[string]CommitMetadata, len(commits))
+	if len(commits) == 0 {
+		return out, nil
+	}
+	input := strings.Join(commits, "\n") + "\n"
+
+	statusOut, err := h.runWithStdin(strings.NewReader(input), "log", "--stdin", "--no-walk=unsorted", "--diff-merges=first-parent", "--no-renames", "--name-status", "-z", "--format="+metadataFormat)
+	if err != nil {
+		return nil, err
+	}
+	if err := parseNameStatusLog(statusOut, out); err != nil {
+		return nil, err
+	}
+
+	numstatOut, err := h.runWithStdin(strings.NewReader(input), "log", "--stdin", "--no-walk=unsorted", "--diff-merges=first-parent", "--no-renames", "--numstat", "-z", "--format=%x1e%H%x00")
+	if err != nil {
+		return nil, err
+	}
+	if err := parseNumstatLog(numstatOut, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func parseNameStatusLog(raw string, out map[string]CommitMetadata) error {
+	for _, record := range strings.Split(raw, "\x1e") {
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, "\x00", 7)
+		if len(fields) < 6 {
+			return fmt.Errorf("unexpected git log output %q", record)
+		}
+
+		ts, err := parseUnix(fields[4])
+		if err != nil {
+			return err
+		}
+		message := strings.TrimSpace(fields[5])
+		subject, _, _ := strings.Cut(message, "\n")
+		meta := CommitMetadata{
+			CommitInfo: CommitInfo{
+				Hash:      fields[0],
+				Author:    fields[2],
+				Email:     fields[3],
+				Timestamp: ts,
+				Subject:   subject,
+			},
+			Parents: strings.Fields(fields[1]),
+			Message: message,
+		}
+
+		if len(fields) == 7 {
+			tokens := splitNulTokens(fields[6])
+			for i := 0; i+1 < len(tokens); i += 2 {
+				meta.Files = append(meta.Files, FileChange{Status: tokens[i], Path: filepath.ToSlash(tokens[i+1])})
+			}
+		}
+		out[meta.Hash] = meta
+	}
+	return nil
+}
+
+func parseNumstatLog(raw string, out map[string]CommitMetadata) error {
+	for _, record := range strings.Split(raw, "\x1e") {
+		if record == "" {
+			continue
+		}
+		hash, rest, _ := strings.Cut(record, "\x00")
+		meta, ok := out[hash]
+		if !ok {
+			continue
+		}
+
+		byPath := make(map[string]int, len(meta.Files))
+		for i, file := range meta.Files {
+			byPath[file.Path] = i
+		}
+		for _, token := range splitNulTokens(rest) {
+			parts := strings.SplitN(token, "\t", 3)
+			if len(parts) != 3 {
+				return fmt.Errorf("unexpected git numstat output %q", token)
+			}
+			i, ok := byPath[filepath.ToSlash(parts[2])]
+			if !ok {
+				continue
+			}
+			if parts[0] == "-" {
+				meta.Files[i].Binary = true
+				continue
+			}
+			meta.Files[i].Added, _ = strconv.Atoi(parts[0])
+			meta.Files[i].Deleted, _ = strconv.Atoi(parts[1])
+		}
+		out[hash] = meta
+	}
+	return nil
+}
+
+func splitNulTokens(s string) []string {
+	var tokens []string
+	for _, token := range strings.Split(s, "\x00") {
+		token = strings.TrimLeft(token, "\n")
+		if token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
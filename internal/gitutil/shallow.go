@@ -0,0 +1,27 @@
+package gitutil
+
+import (
+	"strconv"
+	"strings"
+)
+
+// IsShallow reports whether the repository is a shallow clone.
+func (h *CLIHelper) IsShallow() (bool, error) {
+	out, err := h.run("rev-parse", "--is-shallow-repository")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) == "true", nil
+}
+
+// HasCommit reports whether commit is in the local history.
+func (h *CLIHelper) HasCommit(commit string) bool {
+	_, err := h.run("cat-file", "-e", commit+"^{commit}")
+	return err == nil
+}
+
+// Deepen fetches depth more commits of history into a shallow clone.
+func (h *CLIHelper) Deepen(depth int) error {
+	_, err := h.run("fetch", "--deepen="+strconv.Itoa(depth))
+	return err
+}
@@ -0,0 +1,33 @@
+package gitutil
+
+import (
+	"path"
+	"strings"
+)
+
+// MaterializeFile adds a file that HEAD tracks but a sparse checkout left
+// out to the sparse checkout, so it is written to the working tree. It
+// reports false when the checkout is not sparse or HEAD lacks the file.
+func (h *CLIHelper) MaterializeFile(file string) (bool, error) {
+	root := h.commitRoot()
+	if out, _ := runIn(root, nil, "config", "--bool", "core.sparseCheckout"); strings.TrimSpace(out) != "true" {
+		return false, nil
+	}
+	if _, err := runIn(root, nil, "cat-file", "-e", "HEAD:"+file); err != nil {
+		return false, nil
+	}
+
+	pattern := "/" + file
+	if out, _ := runIn(root, nil, "config", "--bool", "core.sparseCheckoutCone"); strings.TrimSpace(out) == "true" {
+		// Cone patterns are directories; files at the top level are always
+		// checked out.
+		pattern = path.Dir(file)
+		if pattern == "." {
+			return false, nil
+		}
+	}
+	if _, err := runIn(root, nil, "sparse-checkout", "add", pattern); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,30 @@
+package gitutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// GetSubmoduleChanges returns the files changed between two commits of the
+// submodule checked out at path and their diff, with paths relative to the
+// superproject so mappings can match them.
+func (h *CLIHelper) GetSubmoduleChanges(path, fromHash, toHash string) ([]string, string, error) {
+	dir := filepath.Join(h.repoRoot, filepath.FromSlash(path))
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return nil, "", fmt.Errorf("submodule %s is not checked out", path)
+	}
+	out, err := runIn(dir, nil, "diff", "--name-only", fromHash, toHash)
+	if err != nil {
+		return nil, "", fmt.Errorf("read submodule %s: %w", path, err)
+	}
+	files := splitFileList(out)
+	for i, file := range files {
+		files[i] = path + "/" + file
+	}
+	diff, err := runIn(dir, nil, "diff", "--unified=3", "--src-prefix=a/"+path+"/", "--dst-prefix=b/"+path+"/", fromHash, toHash)
+	if err != nil {
+		return nil, "", fmt.Errorf("read submodule %s: %w", path, err)
+	}
+	return files, diff, nil
+}
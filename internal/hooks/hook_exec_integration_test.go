@@ -72,3 +72,91 @@ func initGitRepo(t *testing.T, repo string) {
 		t.Fatalf("git init failed: %v (%s)", err, string(out))
 	}
 }
+
+func TestPrePushHookBlocksStaleDocs(t *testing.T) {
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+
+	mgr := NewManager(repo)
+	if err := mgr.EnablePrePush(false); err != nil {
+		t.Fatalf("enable pre-push failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".git", "hooks", "post-commit")); !os.IsNotExist(err) {
+		t.Fatalf("expected only the pre-push hook to be installed, got %v", err)
+	}
+
+	binDir := filepath.Join(repo, "test-bin")
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	run := func(auditExit string, env ...string) error {
+		script := "#!/bin/sh\nexit " + auditExit + "\n"
+		if err := os.WriteFile(filepath.Join(binDir, "git-doc"), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		cmd := exec.Command("sh", filepath.Join(repo, ".git", "hooks", "pre-push"), "origin", "https://example.com/repo.git")
+		cmd.Dir = repo
+		cmd.Env = append(append(os.Environ(), "PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH")), env...)
+		return cmd.Run()
+	}
+
+	if err := run("1"); err == nil {
+		t.Fatalf("expected the push to be refused while docs are stale")
+	}
+	if err := run("1", SkipPushCheckEnv+"=1"); err != nil {
+		t.Fatalf("expected %s to bypass the check, got %v", SkipPushCheckEnv, err)
+	}
+	if err := run("0"); err != nil {
+		t.Fatalf("expected the push to pass with fresh docs, got %v", err)
+	}
+
+	if err := mgr.Disable(); err != nil {
+		t.Fatalf("disable failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".git", "hooks", "pre-push")); !os.IsNotExist(err) {
+		t.Fatalf("expected disable to remove the pre-push hook, got %v", err)
+	}
+}
+
+func TestInstalledHookStartsDetachedUpdateOnGitForWindows(t *testing.T) {
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+	if err := NewManager(repo).Enable(); err != nil {
+		t.Fatalf("enable hooks failed: %v", err)
+	}
+
+	binDir := filepath.Join(repo, "test-bin")
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(repo, "powershell.log")
+	fakes := map[string]string{
+		"uname":          "#!/bin/sh\necho MINGW64_NT-10.0-19045\n",
+		"powershell.exe": "#!/bin/sh\necho \"$@\" >> \"$GIT_DOC_TEST_LOG\"\n",
+		"git-doc":        "#!/bin/sh\necho direct >> \"$GIT_DOC_TEST_LOG\"\n",
+	}
+	for name, script := range fakes {
+		if err := os.WriteFile(filepath.Join(binDir, name), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command("sh", filepath.Join(repo, ".git", "hooks", "post-commit"))
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(),
+		"PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"GIT_DOC_TEST_LOG="+logPath,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("running hook failed: %v (%s)", err, string(out))
+	}
+
+	b, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("expected powershell to be invoked: %v", err)
+	}
+	line := strings.TrimSpace(string(b))
+	if !strings.Contains(line, "Start-Process") || !strings.Contains(line, "'update','--from-hook'") || strings.Contains(line, "direct") {
+		t.Fatalf("expected a detached update through PowerShell only, got %q", line)
+	}
+}
@@ -4,12 +4,26 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 )
 
 var supportedHooks = []string{"post-commit", "post-merge", "post-rewrite"}
 
+// prePushHook blocks pushes while mapped docs are stale. It is installed on
+// its own by EnablePrePush and removed by Disable with the others.
+const prePushHook = "pre-push"
+
+// SkipPushCheckEnv bypasses the pre-push check when set to a non-empty value.
+const SkipPushCheckEnv = "GIT_DOC_SKIP_PUSH_CHECK"
+
+const (
+	blockStart = "# >>> git-doc >>>"
+	blockEnd   = "# <<< git-doc <<<"
+)
+
 type Manager struct {
 	repoRoot string
 }
@@ -18,36 +32,112 @@ func NewManager(repoRoot string) *Manager {
 	return &Manager{repoRoot: repoRoot}
 }
 
+// HooksDir returns the directory git runs hooks from, honouring
+// core.hooksPath, and whether hook scripts there belong to a hook manager
+// (husky) and must be appended to rather than replaced. For husky 9, whose
+// hooksPath is the generated .husky/_, the user scripts in .husky are used.
+func (m *Manager) HooksDir() (string, bool, error) {
+	out, err := exec.Command("git", "-C", m.repoRoot, "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		dir := filepath.Join(m.repoRoot, ".git", "hooks")
+		if _, statErr := os.Stat(dir); statErr != nil {
+			return "", false, fmt.Errorf("git hooks directory not found: %w", statErr)
+		}
+		return dir, false, nil
+	}
+
+	dir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(m.repoRoot, dir)
+	}
+	dir = filepath.Clean(dir)
+
+	managed := false
+	switch {
+	case filepath.Base(dir) == "_" && filepath.Base(filepath.Dir(dir)) == ".husky":
+		dir = filepath.Dir(dir)
+		managed = true
+	case filepath.Base(dir) == ".husky":
+		managed = true
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", false, fmt.Errorf("create hooks directory: %w", err)
+	}
+	return dir, managed, nil
+}
+
+// Enable installs the git-doc hooks. Existing hooks are backed up and
+// replaced, except in husky directories and lefthook-generated scripts,
+// where the git-doc commands are appended instead.
 func (m *Manager) Enable() error {
-	hooksDir := filepath.Join(m.repoRoot, ".git", "hooks")
-	if _, err := os.Stat(hooksDir); err != nil {
-		return fmt.Errorf("git hooks directory not found: %w", err)
+	return m.enable(false)
+}
+
+// EnableAppend installs the git-doc hooks by appending a marked block to
+// existing hook scripts, creating the scripts that are missing.
+func (m *Manager) EnableAppend() error {
+	return m.enable(true)
+}
+
+// EnablePrePush installs only the pre-push hook, which runs git-doc audit
+// and refuses the push when mapped docs are stale.
+func (m *Manager) EnablePrePush(appendMode bool) error {
+	hooksDir, managed, err := m.HooksDir()
+	if err != nil {
+		return err
+	}
+	return m.install(hooksDir, prePushHook, appendMode || managed)
+}
+
+func (m *Manager) enable(appendMode bool) error {
+	hooksDir, managed, err := m.HooksDir()
+	if err != nil {
+		return err
 	}
 
 	for _, hook := range supportedHooks {
-		hookPath := filepath.Join(hooksDir, hook)
-		if err := m.backupHookIfNeeded(hookPath); err != nil {
+		if err := m.install(hooksDir, hook, appendMode || managed); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		if err := os.WriteFile(hookPath, []byte(hookScript()), 0o600); err != nil {
-			return fmt.Errorf("write hook %s: %w", hook, err)
-		}
-		if err := os.Chmod(hookPath, 0o755); err != nil {
-			return fmt.Errorf("set hook mode %s: %w", hook, err)
+func (m *Manager) install(hooksDir, hook string, appendMode bool) error {
+	hookPath := filepath.Join(hooksDir, hook)
+	content, err := os.ReadFile(hookPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read existing hook: %w", err)
+	}
+
+	var script string
+	existing := string(content)
+	switch {
+	case appendMode || strings.Contains(existing, blockStart) || strings.Contains(existing, "lefthook"):
+		script = appendBlock(existing, hook)
+	default:
+		if err := m.backupHookIfNeeded(hookPath); err != nil {
+			return err
 		}
+		script = hookScript(hook)
 	}
 
+	if err := os.WriteFile(hookPath, []byte(script), 0o600); err != nil {
+		return fmt.Errorf("write hook %s: %w", hook, err)
+	}
+	if err := os.Chmod(hookPath, 0o755); err != nil {
+		return fmt.Errorf("set hook mode %s: %w", hook, err)
+	}
 	return nil
 }
 
 func (m *Manager) Disable() error {
-	hooksDir := filepath.Join(m.repoRoot, ".git", "hooks")
-	if _, err := os.Stat(hooksDir); err != nil {
-		return fmt.Errorf("git hooks directory not found: %w", err)
+	hooksDir, _, err := m.HooksDir()
+	if err != nil {
+		return err
 	}
 
-	for _, hook := range supportedHooks {
+	for _, hook := range append(supportedHooks, prePushHook) {
 		hookPath := filepath.Join(hooksDir, hook)
 		backupPath := m.backupPath(hookPath)
 
@@ -69,7 +159,21 @@ func (m *Manager) Disable() error {
 			return fmt.Errorf("read hook %s: %w", hook, err)
 		}
 
-		if strings.Contains(string(content), "git-doc update") {
+		if strings.Contains(string(content), blockStart) {
+			rest := removeBlock(string(content))
+			if strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), "#!/bin/sh")) == "" {
+				if err := os.Remove(hookPath); err != nil {
+					return fmt.Errorf("remove hook %s: %w", hook, err)
+				}
+				continue
+			}
+			if err := os.WriteFile(hookPath, []byte(rest), 0o755); err != nil {
+				return fmt.Errorf("write hook %s: %w", hook, err)
+			}
+			continue
+		}
+
+		if isGitDocHook(string(content)) {
 			if err := os.Remove(hookPath); err != nil {
 				return fmt.Errorf("remove hook %s: %w", hook, err)
 			}
@@ -79,6 +183,41 @@ func (m *Manager) Disable() error {
 	return nil
 }
 
+// HookState describes one hook git-doc can install.
+type HookState struct {
+	Name       string
+	Path       string
+	Installed  bool
+	Executable bool
+}
+
+// States reports, for each hook git-doc installs, whether the script in the
+// hooks directory runs git-doc and is executable.
+func (m *Manager) States() ([]HookState, error) {
+	hooksDir, _, err := m.HooksDir()
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]HookState, 0, len(supportedHooks)+1)
+	for _, hook := range append(supportedHooks, prePushHook) {
+		hs := HookState{Name: hook, Path: filepath.Join(hooksDir, hook)}
+		content, err := os.ReadFile(hs.Path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("read hook %s: %w", hook, err)
+		}
+		if err == nil {
+			hs.Installed = isGitDocHook(string(content))
+			// Windows has no executable bit; Git for Windows runs any hook.
+			if info, statErr := os.Stat(hs.Path); statErr == nil {
+				hs.Executable = runtime.GOOS == "windows" || info.Mode()&0o111 != 0
+			}
+		}
+		states = append(states, hs)
+	}
+	return states, nil
+}
+
 func (m *Manager) backupHookIfNeeded(hookPath string) error {
 	content, err := os.ReadFile(hookPath)
 	if err != nil {
@@ -88,7 +227,7 @@ func (m *Manager) backupHookIfNeeded(hookPath string) error {
 		return fmt.Errorf("read existing hook: %w", err)
 	}
 
-	if strings.Contains(string(content), "git-doc update") {
+	if isGitDocHook(string(content)) {
 		return nil
 	}
 
@@ -108,6 +247,66 @@ func (m *Manager) backupPath(hookPath string) string {
 	return hookPath + ".git-doc.bak"
 }
 
-func hookScript() string {
-	return "#!/bin/sh\ngit-doc update --from-hook > /dev/null 2>&1 &\n"
+func hookScript(hook string) string {
+	return "#!/bin/sh\n" + hookCommands(hook)
+}
+
+func isGitDocHook(content string) bool {
+	return strings.Contains(content, "git-doc update") || strings.Contains(content, "git-doc audit")
+}
+
+func hookCommands(hook string) string {
+	if hook == prePushHook {
+		return `if [ -z "$` + SkipPushCheckEnv + `" ] && command -v git-doc > /dev/null 2>&1; then
+	if ! git-doc audit; then
+		echo "git-doc: mapped docs are stale; run git-doc update or set ` + SkipPushCheckEnv + `=1 to push anyway" >&2
+		exit 1
+	fi
+fi
+`
+	}
+	if hook == "post-rewrite" {
+		return "git-doc reconcile \"$1\" > /dev/null 2>&1\n" + backgroundUpdate
+	}
+	return backgroundUpdate
+}
+
+// backgroundUpdate starts git-doc update without holding up git. Git for
+// Windows runs hooks with its bundled sh, where a job started with & keeps
+// the hook attached to the console, so there the update is started as a
+// detached process through PowerShell instead.
+const backgroundUpdate = `case "$(uname -s 2>/dev/null)" in
+MINGW* | MSYS* | CYGWIN*)
+	powershell.exe -NoProfile -NonInteractive -Command "Start-Process -WindowStyle Hidden -FilePath git-doc -ArgumentList 'update','--from-hook'" > /dev/null 2>&1
+	;;
+*)
+	git-doc update --from-hook > /dev/null 2>&1 &
+	;;
+esac
+`
+
+// appendBlock adds the git-doc commands to an existing script between
+// marker comments, replacing an earlier block. A missing script gets a
+// shebang.
+func appendBlock(content, hook string) string {
+	content = removeBlock(content)
+	if strings.TrimSpace(content) == "" {
+		content = "#!/bin/sh\n"
+	}
+	content = strings.TrimRight(content, "\n") + "\n\n"
+	return content + blockStart + "\n" + hookCommands(hook) + blockEnd + "\n"
+}
+
+func removeBlock(content string) string {
+	start := strings.Index(content, blockStart)
+	if start < 0 {
+		return content
+	}
+	end := strings.Index(content[start:], blockEnd)
+	if end < 0 {
+		return content
+	}
+	end += start + len(blockEnd)
+	rest := strings.TrimRight(content[:start], "\n") + "\n" + strings.TrimLeft(content[end:], "\n")
+	return strings.TrimRight(rest, "\n") + "\n"
 }
@@ -2,7 +2,9 @@ package hooks
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -28,7 +30,7 @@ func TestEnableDisableWithBackupRestore(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if string(enabledContent) != hookScript() {
+	if string(enabledContent) != hookScript("post-commit") {
 		t.Fatalf("expected hook script to be installed")
 	}
 
@@ -49,3 +51,147 @@ func TestEnableDisableWithBackupRestore(t *testing.T) {
 		t.Fatalf("expected original hook to be restored")
 	}
 }
+
+func TestPostRewriteHookReconcilesBeforeUpdate(t *testing.T) {
+	repo := t.TempDir()
+	hooksDir := filepath.Join(repo, ".git", "hooks")
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewManager(repo).Enable(); err != nil {
+		t.Fatalf("enable failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(hooksDir, "post-rewrite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := string(content)
+	reconcile := strings.Index(script, `git-doc reconcile "$1"`)
+	update := strings.Index(script, "git-doc update --from-hook")
+	if reconcile < 0 || update < 0 || reconcile > update {
+		t.Fatalf("expected post-rewrite hook to reconcile before updating, got %q", script)
+	}
+}
+
+func TestEnableAppendKeepsExistingHook(t *testing.T) {
+	repo := t.TempDir()
+	hooksDir := filepath.Join(repo, ".git", "hooks")
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	existing := filepath.Join(hooksDir, "post-commit")
+	original := "#!/bin/sh\necho original\n"
+	if err := os.WriteFile(existing, []byte(original), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr := NewManager(repo)
+	for i := 0; i < 2; i++ {
+		if err := mgr.EnableAppend(); err != nil {
+			t.Fatalf("enable failed: %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := string(content)
+	if !strings.HasPrefix(script, original) || strings.Count(script, blockStart) != 1 || !strings.Contains(script, "git-doc update --from-hook") {
+		t.Fatalf("expected one git-doc block after the original script, got %q", script)
+	}
+	if _, err := os.Stat(existing + ".git-doc.bak"); err == nil {
+		t.Fatalf("expected no backup in append mode")
+	}
+
+	if err := mgr.Disable(); err != nil {
+		t.Fatalf("disable failed: %v", err)
+	}
+	restored, err := os.ReadFile(existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(restored) != original {
+		t.Fatalf("expected the original script back, got %q", restored)
+	}
+	if _, err := os.Stat(filepath.Join(hooksDir, "post-merge")); !os.IsNotExist(err) {
+		t.Fatalf("expected hooks created by append mode to be removed, got %v", err)
+	}
+}
+
+func TestEnableHonoursHooksPath(t *testing.T) {
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+	gitConfig(t, repo, "core.hooksPath", "tools/hooks")
+
+	if err := NewManager(repo).Enable(); err != nil {
+		t.Fatalf("enable failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "tools", "hooks", "post-commit")); err != nil {
+		t.Fatalf("expected hook in core.hooksPath: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".git", "hooks", "post-commit")); !os.IsNotExist(err) {
+		t.Fatalf("expected no hook in .git/hooks, got %v", err)
+	}
+}
+
+func TestEnableAppendsToHuskyAndLefthookScripts(t *testing.T) {
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+	if err := os.MkdirAll(filepath.Join(repo, ".husky", "_"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	husky := "npx lint-staged\n"
+	if err := os.WriteFile(filepath.Join(repo, ".husky", "post-merge"), []byte(husky), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitConfig(t, repo, "core.hooksPath", ".husky/_")
+
+	mgr := NewManager(repo)
+	dir, managed, err := mgr.HooksDir()
+	if err != nil || !managed || dir != filepath.Join(repo, ".husky") {
+		t.Fatalf("expected .husky as a managed hooks dir, got %q %v %v", dir, managed, err)
+	}
+	if err := mgr.Enable(); err != nil {
+		t.Fatalf("enable failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(repo, ".husky", "post-merge"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), husky) || !strings.Contains(string(content), blockStart) {
+		t.Fatalf("expected the husky script to be kept and extended, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".husky", "_", "post-commit")); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing written to the generated .husky/_ directory, got %v", err)
+	}
+
+	lefthookRepo := t.TempDir()
+	initGitRepo(t, lefthookRepo)
+	lefthook := "#!/bin/sh\nlefthook run post-commit \"$@\"\n"
+	hookPath := filepath.Join(lefthookRepo, ".git", "hooks", "post-commit")
+	if err := os.WriteFile(hookPath, []byte(lefthook), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewManager(lefthookRepo).Enable(); err != nil {
+		t.Fatalf("enable failed: %v", err)
+	}
+	content, err = os.ReadFile(hookPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), lefthook) || !strings.Contains(string(content), blockStart) {
+		t.Fatalf("expected the lefthook script to be extended, got %q", content)
+	}
+}
+
+func gitConfig(t *testing.T, repo, key, value string) {
+	t.Helper()
+	cmd := exec.Command("git", "config", key, value)
+	cmd.Dir = repo
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git config failed: %v (%s)", err, string(out))
+	}
+}
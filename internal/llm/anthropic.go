@@ -16,6 +16,7 @@ import (
 type AnthropicClient struct {
 	apiKey string
 	model  string
+	params generationParams
 	http   *http.Client
 	url    string
 }
@@ -24,10 +25,11 @@ func NewAnthropicClient(cfg *config.Config) *AnthropicClient {
 	return &AnthropicClient{
 		apiKey: cfg.LLM.APIKey,
 		model:  cfg.LLM.Model,
+		params: newGenerationParams(cfg),
 		http: &http.Client{
 			Timeout: time.Duration(cfg.LLM.Timeout) * time.Second,
 		},
-		url: "https://api.anthropic.com/v1/messages",
+		url: endpointURL(cfg.LLM.BaseURL, "https://api.anthropic.com/v1/messages", "/messages"),
 	}
 }
 
@@ -36,13 +38,27 @@ func (a *AnthropicClient) Name() string {
 }
 
 func (a *AnthropicClient) Generate(ctx context.Context, prompt string) (string, error) {
+	maxTokens := a.params.maxTokens
+	if maxTokens <= 0 {
+		maxTokens = anthropicDefaultMaxTokens
+	}
+
 	requestBody := map[string]any{
 		"model":      a.model,
-		"max_tokens": 1024,
+		"max_tokens": maxTokens,
 		"messages": []map[string]string{
 			{"role": "user", "content": prompt},
 		},
 	}
+	if a.params.system != "" {
+		requestBody["system"] = a.params.system
+	}
+	if a.params.temperature != nil {
+		requestBody["temperature"] = *a.params.temperature
+	}
+	if a.params.topP != nil {
+		requestBody["top_p"] = *a.params.topP
+	}
 
 	b, err := json.Marshal(requestBody)
 	if err != nil {
@@ -69,7 +85,7 @@ func (a *AnthropicClient) Generate(ctx context.Context, prompt string) (string,
 	}
 
 	if resp.StatusCode >= 300 {
-		return "", fmt.Errorf("anthropic request failed: %s", strings.TrimSpace(string(body)))
+		return "", newHTTPError("anthropic", resp, body)
 	}
 
 	var parsed struct {
@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"testing"
 
@@ -47,3 +48,59 @@ func TestAnthropicGenerate_HTTPError(t *testing.T) {
 	_, err := client.Generate(context.Background(), "prompt")
 	assertErrorContains(t, err, "anthropic request failed")
 }
+
+func TestAnthropicGenerate_SendsGenerationParams(t *testing.T) {
+	server := newJSONTestServer(t, http.StatusOK, `{"content":[{"type":"text","text":"ok"}]}`, func(t *testing.T, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if body["system"] != "be terse" || body["max_tokens"] != float64(2048) || body["temperature"] != 0.3 {
+			t.Fatalf("unexpected request body: %v", body)
+		}
+		if _, ok := body["top_p"]; ok {
+			t.Fatalf("expected top_p to be omitted when unset")
+		}
+	})
+	defer server.Close()
+
+	temperature := 0.3
+	cfg := config.Default()
+	cfg.LLM.APIKey = "test-key"
+	cfg.LLM.SystemPrompt = "be terse"
+	cfg.LLM.Temperature = &temperature
+	cfg.LLM.MaxTokens = 2048
+
+	client := NewAnthropicClient(cfg)
+	client.url = server.URL
+
+	if _, err := client.Generate(context.Background(), "prompt"); err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+}
+
+func TestAnthropicGenerate_DefaultsMaxTokens(t *testing.T) {
+	server := newJSONTestServer(t, http.StatusOK, `{"content":[{"type":"text","text":"ok"}]}`, func(t *testing.T, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if body["max_tokens"] != float64(anthropicDefaultMaxTokens) {
+			t.Fatalf("expected default max_tokens, got %v", body["max_tokens"])
+		}
+		if _, ok := body["system"]; ok {
+			t.Fatalf("expected system to be omitted when unset")
+		}
+	})
+	defer server.Close()
+
+	cfg := config.Default()
+	cfg.LLM.APIKey = "test-key"
+
+	client := NewAnthropicClient(cfg)
+	client.url = server.URL
+
+	if _, err := client.Generate(context.Background(), "prompt"); err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package llm
+
+import "time"
+
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+type BreakerEvent struct {
+	Provider string
+	State    string
+	Failures int
+	Until    time.Time
+	Err      string
+}
+
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	state     string
+	failures  int
+	openUntil time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, state: BreakerClosed}
+}
+
+// allow reports whether a call may go through. An open breaker moves to
+// half-open once its cool-down has elapsed so a single trial call can probe
+// the provider.
+func (b *circuitBreaker) allow(now time.Time) (bool, bool) {
+	if b.state != BreakerOpen {
+		return true, false
+	}
+	if now.Before(b.openUntil) {
+		return false, false
+	}
+	b.state = BreakerHalfOpen
+	return true, true
+}
+
+func (b *circuitBreaker) recordSuccess() bool {
+	changed := b.state != BreakerClosed
+	b.state = BreakerClosed
+	b.failures = 0
+	return changed
+}
+
+func (b *circuitBreaker) recordFailure(now time.Time) bool {
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openUntil = now.Add(b.cooldown)
+		return true
+	}
+	return false
+}
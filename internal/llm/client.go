@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/kowshik24/git-doc/internal/config"
 )
@@ -14,28 +15,27 @@ type Client interface {
 }
 
 func NewClient(cfg *config.Config) (Client, error) {
-	primary := strings.ToLower(strings.TrimSpace(cfg.LLM.Provider))
-	if primary == "" {
-		primary = "mock"
+	chain := cfg.LLM.ResolvedProviders()
+	if chain[0].Provider == "" {
+		chain[0].Provider = "mock"
 	}
-
-	providers := []string{primary}
-	if cfg.LLM.FailoverEnabled {
-		for _, fallback := range cfg.LLM.FallbackProviders {
-			fallbackProvider := strings.ToLower(strings.TrimSpace(fallback))
-			if fallbackProvider == "" || containsProvider(providers, fallbackProvider) {
-				continue
-			}
-			providers = append(providers, fallbackProvider)
-		}
+	if !cfg.LLM.FailoverEnabled {
+		chain = chain[:1]
 	}
 
-	clients := make([]Client, 0, len(providers))
-	for _, provider := range providers {
-		client, err := buildProviderClient(provider, cfg)
+	clients := make([]Client, 0, len(chain))
+	for _, provider := range chain {
+		client, err := buildProviderClient(provider.Provider, cfg.WithProvider(provider))
 		if err != nil {
 			return nil, err
 		}
+		if cfg.Runtime.Offline && !IsLocalProvider(provider.Provider, provider.BaseURL) {
+			clients = append(clients, offlineClient{name: client.Name()})
+			continue
+		}
+		if provider.RequestsPerMinute > 0 || provider.TokensPerMinute > 0 {
+			client = NewRateLimitedClient(client, NewRateLimiter(provider.RequestsPerMinute, provider.TokensPerMinute))
+		}
 		clients = append(clients, client)
 	}
 
@@ -43,13 +43,15 @@ func NewClient(cfg *config.Config) (Client, error) {
 		return clients[0], nil
 	}
 
-	return NewResilientClient(clients, cfg.LLM.MaxRetries), nil
+	resilient := NewResilientClient(clients, cfg.LLM.MaxRetries)
+	resilient.SetCircuitBreaker(cfg.LLM.CircuitBreakerThreshold, time.Duration(cfg.LLM.CircuitBreakerCooldown)*time.Second)
+	return resilient, nil
 }
 
 func buildProviderClient(provider string, cfg *config.Config) (Client, error) {
 	switch provider {
 	case "mock":
-		return NewMockClient(), nil
+		return &MockClient{structured: cfg.LLM.StructuredOutput}, nil
 	case "openai":
 		return NewOpenAIClient(cfg), nil
 	case "anthropic":
@@ -60,16 +62,19 @@ func buildProviderClient(provider string, cfg *config.Config) (Client, error) {
 		return NewGroqClient(cfg), nil
 	case "ollama":
 		return NewOllamaClient(cfg), nil
+	case "mistral":
+		return NewMistralClient(cfg), nil
+	case "openrouter":
+		return NewOpenRouterClient(cfg), nil
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", provider)
 	}
 }
 
-func containsProvider(providers []string, candidate string) bool {
-	for _, provider := range providers {
-		if provider == candidate {
-			return true
-		}
+func endpointURL(baseURL, defaultURL, path string) string {
+	baseURL = strings.TrimSpace(baseURL)
+	if baseURL == "" {
+		return defaultURL
 	}
-	return false
+	return strings.TrimRight(baseURL, "/") + path
 }
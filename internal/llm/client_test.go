@@ -1,13 +1,16 @@
 package llm
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"testing"
 
 	"github.com/kowshik24/git-doc/internal/config"
 )
 
 func TestNewClientSupportsAdditionalProviders(t *testing.T) {
-	providers := []string{"anthropic", "gemini", "google", "groq", "ollama"}
+	providers := []string{"anthropic", "gemini", "google", "groq", "ollama", "mistral", "openrouter"}
 	for _, provider := range providers {
 		cfg := config.Default()
 		cfg.LLM.Provider = provider
@@ -22,3 +25,79 @@ func TestNewClientSupportsAdditionalProviders(t *testing.T) {
 		}
 	}
 }
+
+func TestNewClientUsesPerProviderSettings(t *testing.T) {
+	server := newJSONTestServer(t, http.StatusOK, `{"content":[{"type":"text","text":"from fallback"}]}`, func(t *testing.T, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Fatalf("expected base_url to be used, got path %q", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "anthropic-key" {
+			t.Fatalf("expected fallback provider to use its own api key, got %q", r.Header.Get("x-api-key"))
+		}
+	})
+	defer server.Close()
+
+	cfg := config.Default()
+	cfg.LLM.Provider = "openai"
+	cfg.LLM.APIKey = "openai-key"
+	cfg.LLM.MaxRetries = 1
+	cfg.LLM.Providers = []config.ProviderConfig{
+		{Provider: "openai", BaseURL: "http://127.0.0.1:1"},
+		{Provider: "anthropic", APIKey: "anthropic-key", Model: "claude-test", BaseURL: server.URL + "/v1"},
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if client.Name() != "resilient(openai->anthropic)" {
+		t.Fatalf("unexpected client chain: %s", client.Name())
+	}
+
+	out, err := client.Generate(context.Background(), "prompt")
+	if err != nil {
+		t.Fatalf("expected fallback success, got %v", err)
+	}
+	if out != "from fallback" {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestNewClientOfflineUsesOnlyLocalProviders(t *testing.T) {
+	server := newJSONTestServer(t, http.StatusOK, `{"content":[{"type":"text","text":"from local"}]}`, nil)
+	defer server.Close()
+
+	cfg := config.Default()
+	cfg.Runtime.Offline = true
+	cfg.LLM.MaxRetries = 2
+	cfg.LLM.Providers = []config.ProviderConfig{
+		{Provider: "openai", APIKey: "openai-key"},
+		{Provider: "anthropic", APIKey: "local-key", Model: "claude-test", BaseURL: server.URL + "/v1"},
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if client.Name() != "resilient(openai->anthropic)" {
+		t.Fatalf("expected offline mode to keep the chain name for cache keys, got %s", client.Name())
+	}
+	if out, err := client.Generate(context.Background(), "prompt"); err != nil || out != "from local" {
+		t.Fatalf("expected the loopback provider to answer, got %q err=%v", out, err)
+	}
+
+	cfg.LLM.Providers = nil
+	cfg.LLM.Provider = "openai"
+	cfg.LLM.MaxRetries = 0
+	client, err = NewClient(cfg)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if _, err := client.Generate(context.Background(), "prompt"); !errors.Is(err, ErrOffline) || client.Name() != "openai" {
+		t.Fatalf("expected a network provider to be refused offline, got %s err=%v", client.Name(), err)
+	}
+
+	if !IsLocalProvider("ollama", "http://gpu-box:11434") || !IsLocalProvider("openai", "http://localhost:8080/v1") || IsLocalProvider("openai", "") {
+		t.Fatalf("unexpected local provider classification")
+	}
+}
@@ -0,0 +1,212 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+// Embedder turns texts into vectors whose cosine similarity reflects how
+// related the texts are.
+type Embedder interface {
+	// Name identifies the provider and model; vectors from different names
+	// are not comparable.
+	Name() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+func NewEmbedder(cfg *config.Config) (Embedder, error) {
+	resolved := cfg.ResolvedEmbeddings()
+	client := &http.Client{Timeout: time.Duration(resolved.Timeout) * time.Second}
+
+	switch resolved.Provider {
+	case "openai":
+		return &openAIEmbedder{
+			provider: "openai",
+			model:    resolved.Model,
+			apiKey:   resolved.APIKey,
+			http:     client,
+			url:      endpointURL(resolved.BaseURL, "https://api.openai.com/v1/embeddings", "/embeddings"),
+		}, nil
+	case "mistral":
+		return &openAIEmbedder{
+			provider: "mistral",
+			model:    resolved.Model,
+			apiKey:   resolved.APIKey,
+			http:     client,
+			url:      endpointURL(resolved.BaseURL, "https://api.mistral.ai/v1/embeddings", "/embeddings"),
+		}, nil
+	case "ollama":
+		return &ollamaEmbedder{
+			model: resolved.Model,
+			http:  client,
+			url:   endpointURL(resolved.BaseURL, "http://localhost:11434/api/embed", "/api/embed"),
+		}, nil
+	case "mock":
+		return MockEmbedder{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported embeddings provider: %s", resolved.Provider)
+	}
+}
+
+// openAIEmbedder speaks the OpenAI /embeddings API, which Mistral also serves.
+type openAIEmbedder struct {
+	provider string
+	model    string
+	apiKey   string
+	http     *http.Client
+	url      string
+}
+
+func (o *openAIEmbedder) Name() string {
+	return o.provider + "/" + o.model
+}
+
+func (o *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := postEmbeddingRequest(ctx, o.http, o.url, o.apiKey, o.provider, map[string]any{
+		"model": o.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, err
+	}
+	if len(parsed.Data) != len(texts) {
+		return nil, fmt.Errorf("%s returned %d embeddings for %d inputs", o.Name(), len(parsed.Data), len(texts))
+	}
+
+	out := make([][]float32, len(texts))
+	for _, item := range parsed.Data {
+		if item.Index < 0 || item.Index >= len(out) {
+			return nil, fmt.Errorf("%s returned embedding index %d out of range", o.Name(), item.Index)
+		}
+		out[item.Index] = item.Embedding
+	}
+	return out, nil
+}
+
+type ollamaEmbedder struct {
+	model string
+	http  *http.Client
+	url   string
+}
+
+func (o *ollamaEmbedder) Name() string {
+	return "ollama/" + o.model
+}
+
+func (o *ollamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := postEmbeddingRequest(ctx, o.http, o.url, "", "ollama", map[string]any{
+		"model": o.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, err
+	}
+	if len(parsed.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("ollama returned %d embeddings for %d inputs", len(parsed.Embeddings), len(texts))
+	}
+	return parsed.Embeddings, nil
+}
+
+func postEmbeddingRequest(ctx context.Context, client *http.Client, url, apiKey, provider string, requestBody map[string]any) ([]byte, error) {
+	b, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, newHTTPError(provider, resp, body)
+	}
+	return body, nil
+}
+
+const mockEmbeddingDims = 256
+
+// MockEmbedder hashes words into a fixed-size bag-of-words vector, so texts
+// sharing words are similar. It needs no network and suits tests and demos.
+type MockEmbedder struct{}
+
+func (MockEmbedder) Name() string {
+	return "mock"
+}
+
+func (MockEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, mockEmbeddingDims)
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, word := range words {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(word))
+			vector[h.Sum32()%mockEmbeddingDims]++
+		}
+		out[i] = vector
+	}
+	return out, nil
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b, or 0 when
+// either is empty or their lengths differ.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+func TestOpenAIEmbedderOrdersVectorsByIndex(t *testing.T) {
+	server := newJSONTestServer(t, http.StatusOK, `{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`, func(t *testing.T, r *http.Request) {
+		var body struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if body.Model != "text-embedding-3-small" || len(body.Input) != 2 {
+			t.Fatalf("unexpected request: %+v", body)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			t.Fatalf("expected bearer token, got %q", r.Header.Get("Authorization"))
+		}
+	})
+	defer server.Close()
+
+	cfg := config.Default()
+	cfg.LLM.Provider = "openai"
+	cfg.LLM.APIKey = "test-key"
+	cfg.Embeddings.Enabled = true
+	cfg.Embeddings.BaseURL = server.URL
+
+	embedder, err := NewEmbedder(cfg)
+	if err != nil {
+		t.Fatalf("new embedder: %v", err)
+	}
+	if embedder.Name() != "openai/text-embedding-3-small" {
+		t.Fatalf("unexpected embedder name %q", embedder.Name())
+	}
+	vectors, err := embedder.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("embed: %v", err)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Fatalf("expected vectors in input order, got %v", vectors)
+	}
+}
+
+func TestMockEmbedderSimilarity(t *testing.T) {
+	vectors, err := MockEmbedder{}.Embed(context.Background(), []string{
+		"add health endpoint to the HTTP server",
+		"Endpoints: the HTTP server exposes a health endpoint",
+		"configure the database connection pool",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	related := CosineSimilarity(vectors[0], vectors[1])
+	unrelated := CosineSimilarity(vectors[0], vectors[2])
+	if related <= unrelated {
+		t.Fatalf("expected related texts to score higher: related=%f unrelated=%f", related, unrelated)
+	}
+	if CosineSimilarity(vectors[0], nil) != 0 {
+		t.Fatalf("expected mismatched lengths to score 0")
+	}
+}
@@ -17,6 +17,7 @@ import (
 type GeminiClient struct {
 	apiKey string
 	model  string
+	params generationParams
 	http   *http.Client
 	base   string
 }
@@ -25,10 +26,11 @@ func NewGeminiClient(cfg *config.Config) *GeminiClient {
 	return &GeminiClient{
 		apiKey: cfg.LLM.APIKey,
 		model:  cfg.LLM.Model,
+		params: newGenerationParams(cfg),
 		http: &http.Client{
 			Timeout: time.Duration(cfg.LLM.Timeout) * time.Second,
 		},
-		base: "https://generativelanguage.googleapis.com/v1beta/models",
+		base: endpointURL(cfg.LLM.BaseURL, "https://generativelanguage.googleapis.com/v1beta/models", "/models"),
 	}
 }
 
@@ -44,6 +46,29 @@ func (g *GeminiClient) Generate(ctx context.Context, prompt string) (string, err
 			},
 		},
 	}
+	if g.params.system != "" {
+		requestBody["systemInstruction"] = map[string]any{
+			"parts": []map[string]string{{"text": g.params.system}},
+		}
+	}
+
+	generationConfig := map[string]any{}
+	if g.params.temperature != nil {
+		generationConfig["temperature"] = *g.params.temperature
+	}
+	if g.params.topP != nil {
+		generationConfig["topP"] = *g.params.topP
+	}
+	if g.params.maxTokens > 0 {
+		generationConfig["maxOutputTokens"] = g.params.maxTokens
+	}
+	if g.params.structured {
+		generationConfig["responseMimeType"] = "application/json"
+		generationConfig["responseSchema"] = geminiResponseSchema()
+	}
+	if len(generationConfig) > 0 {
+		requestBody["generationConfig"] = generationConfig
+	}
 
 	b, err := json.Marshal(requestBody)
 	if err != nil {
@@ -69,7 +94,7 @@ func (g *GeminiClient) Generate(ctx context.Context, prompt string) (string, err
 	}
 
 	if resp.StatusCode >= 300 {
-		return "", fmt.Errorf("gemini request failed: %s", strings.TrimSpace(string(body)))
+		return "", newHTTPError("gemini", resp, body)
 	}
 
 	var parsed struct {
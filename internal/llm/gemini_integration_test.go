@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"testing"
 
@@ -43,3 +44,34 @@ func TestGeminiGenerate_HTTPError(t *testing.T) {
 	_, err := client.Generate(context.Background(), "prompt")
 	assertErrorContains(t, err, "gemini request failed")
 }
+
+func TestGeminiGenerate_SendsGenerationConfig(t *testing.T) {
+	server := newJSONTestServer(t, http.StatusOK, `{"candidates":[{"content":{"parts":[{"text":"ok"}]}}]}`, func(t *testing.T, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		generationConfig, ok := body["generationConfig"].(map[string]any)
+		if !ok || generationConfig["maxOutputTokens"] != float64(1024) || generationConfig["temperature"] != float64(0) {
+			t.Fatalf("unexpected generationConfig: %v", body["generationConfig"])
+		}
+		if _, ok := body["systemInstruction"]; !ok {
+			t.Fatalf("expected systemInstruction to be set")
+		}
+	})
+	defer server.Close()
+
+	temperature := 0.0
+	cfg := config.Default()
+	cfg.LLM.APIKey = "test-key"
+	cfg.LLM.SystemPrompt = "be terse"
+	cfg.LLM.Temperature = &temperature
+	cfg.LLM.MaxTokens = 1024
+
+	client := NewGeminiClient(cfg)
+	client.base = server.URL
+
+	if _, err := client.Generate(context.Background(), "prompt"); err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+}
@@ -16,6 +16,7 @@ import (
 type GroqClient struct {
 	apiKey string
 	model  string
+	params generationParams
 	http   *http.Client
 	url    string
 }
@@ -24,10 +25,11 @@ func NewGroqClient(cfg *config.Config) *GroqClient {
 	return &GroqClient{
 		apiKey: cfg.LLM.APIKey,
 		model:  cfg.LLM.Model,
+		params: newGenerationParams(cfg),
 		http: &http.Client{
 			Timeout: time.Duration(cfg.LLM.Timeout) * time.Second,
 		},
-		url: "https://api.groq.com/openai/v1/chat/completions",
+		url: endpointURL(cfg.LLM.BaseURL, "https://api.groq.com/openai/v1/chat/completions", "/chat/completions"),
 	}
 }
 
@@ -36,12 +38,7 @@ func (g *GroqClient) Name() string {
 }
 
 func (g *GroqClient) Generate(ctx context.Context, prompt string) (string, error) {
-	requestBody := map[string]any{
-		"model": g.model,
-		"messages": []map[string]string{
-			{"role": "user", "content": prompt},
-		},
-	}
+	requestBody := g.params.chatRequest(g.model, prompt)
 
 	b, err := json.Marshal(requestBody)
 	if err != nil {
@@ -67,7 +64,7 @@ func (g *GroqClient) Generate(ctx context.Context, prompt string) (string, error
 	}
 
 	if resp.StatusCode >= 300 {
-		return "", fmt.Errorf("groq request failed: %s", strings.TrimSpace(string(body)))
+		return "", newHTTPError("groq", resp, body)
 	}
 
 	var parsed struct {
@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"testing"
 
@@ -47,3 +48,37 @@ func TestGroqGenerate_HTTPError(t *testing.T) {
 	_, err := client.Generate(context.Background(), "prompt")
 	assertErrorContains(t, err, "groq request failed")
 }
+
+func TestGroqGenerate_SendsSystemPromptAndParams(t *testing.T) {
+	server := newJSONTestServer(t, http.StatusOK, `{"choices":[{"message":{"content":"ok"}}]}`, func(t *testing.T, r *http.Request) {
+		var body struct {
+			Messages []map[string]string `json:"messages"`
+			TopP     float64             `json:"top_p"`
+			Max      int                 `json:"max_tokens"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if len(body.Messages) != 2 || body.Messages[0]["role"] != "system" || body.Messages[0]["content"] != "be terse" {
+			t.Fatalf("expected system message first, got %v", body.Messages)
+		}
+		if body.TopP != 0.9 || body.Max != 512 {
+			t.Fatalf("unexpected params: top_p=%v max_tokens=%d", body.TopP, body.Max)
+		}
+	})
+	defer server.Close()
+
+	topP := 0.9
+	cfg := config.Default()
+	cfg.LLM.APIKey = "test-key"
+	cfg.LLM.SystemPrompt = "be terse"
+	cfg.LLM.TopP = &topP
+	cfg.LLM.MaxTokens = 512
+
+	client := NewGroqClient(cfg)
+	client.url = server.URL
+
+	if _, err := client.Generate(context.Background(), "prompt"); err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+type MistralClient struct {
+	apiKey string
+	model  string
+	params generationParams
+	http   *http.Client
+	url    string
+}
+
+func NewMistralClient(cfg *config.Config) *MistralClient {
+	return &MistralClient{
+		apiKey: cfg.LLM.APIKey,
+		model:  cfg.LLM.Model,
+		params: newGenerationParams(cfg),
+		http: &http.Client{
+			Timeout: time.Duration(cfg.LLM.Timeout) * time.Second,
+		},
+		url: endpointURL(cfg.LLM.BaseURL, "https://api.mistral.ai/v1/chat/completions", "/chat/completions"),
+	}
+}
+
+func (m *MistralClient) Name() string {
+	return "mistral"
+}
+
+func (m *MistralClient) Generate(ctx context.Context, prompt string) (string, error) {
+	requestBody := m.params.chatRequest(m.model, prompt)
+
+	b, err := json.Marshal(requestBody)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.url, bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	req.Header.Set("content-type", "application/json")
+
+	resp, err := m.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode >= 300 {
+		return "", newHTTPError("mistral", resp, body)
+	}
+
+	var parsed struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return "", err
+	}
+
+	if len(parsed.Choices) == 0 || strings.TrimSpace(parsed.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("mistral response has no choices")
+	}
+
+	return strings.TrimSpace(parsed.Choices[0].Message.Content), nil
+}
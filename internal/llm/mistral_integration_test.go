@@ -0,0 +1,49 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+func TestMistralGenerate_Success(t *testing.T) {
+	server := newJSONTestServer(t, http.StatusOK, `{"choices":[{"message":{"content":"  mistral output  "}}]}`, func(t *testing.T, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			t.Fatalf("expected Authorization header to be set")
+		}
+	})
+	defer server.Close()
+
+	cfg := config.Default()
+	cfg.LLM.Provider = "mistral"
+	cfg.LLM.APIKey = "test-key"
+	cfg.LLM.Model = "mistral-small-latest"
+
+	client := NewMistralClient(cfg)
+	client.url = server.URL
+
+	out, err := client.Generate(context.Background(), "prompt")
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if out != "mistral output" {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestMistralGenerate_HTTPError(t *testing.T) {
+	server := newJSONTestServer(t, http.StatusTooManyRequests, `rate limited`, nil)
+	defer server.Close()
+
+	cfg := config.Default()
+	cfg.LLM.Provider = "mistral"
+	cfg.LLM.APIKey = "test-key"
+
+	client := NewMistralClient(cfg)
+	client.url = server.URL
+
+	_, err := client.Generate(context.Background(), "prompt")
+	assertErrorContains(t, err, "mistral request failed")
+}
@@ -2,10 +2,13 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 )
 
-type MockClient struct{}
+type MockClient struct {
+	structured bool
+}
 
 func NewMockClient() *MockClient {
 	return &MockClient{}
@@ -26,5 +29,14 @@ func (m *MockClient) Generate(ctx context.Context, prompt string) (string, error
 		line = line[:180]
 	}
 
-	return "- Auto-generated update\n\n" + line, nil
+	section := "- Auto-generated update\n\n" + line
+	if !m.structured {
+		return section, nil
+	}
+
+	out, err := json.Marshal(SectionResponse{SectionMarkdown: section, Summary: "Auto-generated update", Confidence: 1})
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
 }
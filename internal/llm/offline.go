@@ -0,0 +1,50 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ErrOffline is returned instead of calling a network provider when
+// runtime.offline is set.
+var ErrOffline = errors.New("offline mode forbids network providers")
+
+// IsLocalProvider reports whether a provider may be used offline: ollama and
+// mock always, and any other provider whose base_url points at a loopback
+// address (a local OpenAI-compatible server).
+func IsLocalProvider(provider, baseURL string) bool {
+	switch provider {
+	case "", "mock", "ollama":
+		return true
+	}
+	return isLoopbackURL(baseURL)
+}
+
+func isLoopbackURL(raw string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || parsed.Hostname() == "" {
+		return false
+	}
+	host := parsed.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// offlineClient stands in for a network provider in offline mode. It keeps
+// the provider's name so cache keys match responses cached while online.
+type offlineClient struct {
+	name string
+}
+
+func (c offlineClient) Name() string { return c.name }
+
+func (c offlineClient) Generate(context.Context, string) (string, error) {
+	return "", fmt.Errorf("%w: %s needs the network", ErrOffline, c.name)
+}
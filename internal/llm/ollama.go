@@ -13,19 +13,31 @@ import (
 	"github.com/kowshik24/git-doc/internal/config"
 )
 
+const ollamaDefaultURL = "http://localhost:11434"
+
 type OllamaClient struct {
-	model string
-	http  *http.Client
-	url   string
+	model  string
+	params generationParams
+	chat   bool
+	numCtx int
+	http   *http.Client
+	url    string
 }
 
 func NewOllamaClient(cfg *config.Config) *OllamaClient {
+	path := "/api/generate"
+	if cfg.LLM.Ollama.Chat {
+		path = "/api/chat"
+	}
 	return &OllamaClient{
-		model: cfg.LLM.Model,
+		model:  cfg.LLM.Model,
+		params: newGenerationParams(cfg),
+		chat:   cfg.LLM.Ollama.Chat,
+		numCtx: cfg.LLM.Ollama.NumCtx,
 		http: &http.Client{
 			Timeout: time.Duration(cfg.LLM.Timeout) * time.Second,
 		},
-		url: "http://localhost:11434/api/generate",
+		url: endpointURL(cfg.LLM.BaseURL, ollamaDefaultURL+path, path),
 	}
 }
 
@@ -36,47 +48,194 @@ func (o *OllamaClient) Name() string {
 func (o *OllamaClient) Generate(ctx context.Context, prompt string) (string, error) {
 	requestBody := map[string]any{
 		"model":  o.model,
-		"prompt": prompt,
 		"stream": false,
 	}
+	if o.chat {
+		messages := make([]map[string]string, 0, 2)
+		if o.params.system != "" {
+			messages = append(messages, map[string]string{"role": "system", "content": o.params.system})
+		}
+		requestBody["messages"] = append(messages, map[string]string{"role": "user", "content": prompt})
+	} else {
+		requestBody["prompt"] = prompt
+		if o.params.system != "" {
+			requestBody["system"] = o.params.system
+		}
+	}
 
-	b, err := json.Marshal(requestBody)
-	if err != nil {
-		return "", err
+	options := map[string]any{}
+	if o.params.temperature != nil {
+		options["temperature"] = *o.params.temperature
+	}
+	if o.params.topP != nil {
+		options["top_p"] = *o.params.topP
+	}
+	if o.params.maxTokens > 0 {
+		options["num_predict"] = o.params.maxTokens
+	}
+	if o.numCtx > 0 {
+		options["num_ctx"] = o.numCtx
+	}
+	if len(options) > 0 {
+		requestBody["options"] = options
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.url, bytes.NewReader(b))
+	body, err := ollamaRequest(ctx, o.http, http.MethodPost, o.url, requestBody)
 	if err != nil {
 		return "", err
 	}
-	req.Header.Set("content-type", "application/json")
 
-	resp, err := o.http.Do(req)
-	if err != nil {
+	var parsed struct {
+		Response string `json:"response"`
+		Message  struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
+	out := parsed.Response
+	if o.chat {
+		out = parsed.Message.Content
+	}
+	if strings.TrimSpace(out) == "" {
+		return "", fmt.Errorf("ollama response is empty")
 	}
+	return strings.TrimSpace(out), nil
+}
 
-	if resp.StatusCode >= 300 {
-		return "", fmt.Errorf("ollama request failed: %s", strings.TrimSpace(string(body)))
+// OllamaModel is a model an ollama provider block uses, with the server that
+// should have it.
+type OllamaModel struct {
+	Model   string
+	BaseURL string
+}
+
+func (m OllamaModel) server() string {
+	if strings.TrimSpace(m.BaseURL) == "" {
+		return ollamaDefaultURL
+	}
+	return strings.TrimRight(strings.TrimSpace(m.BaseURL), "/")
+}
+
+// MissingOllamaModels lists the models of the ollama providers cfg would
+// call (the provider chain, the planner and the ensemble) that their servers have not
+// pulled, using GET /api/tags.
+func MissingOllamaModels(ctx context.Context, cfg *config.Config) ([]OllamaModel, error) {
+	chain := cfg.LLM.ResolvedProviders()
+	if !cfg.LLM.FailoverEnabled {
+		chain = chain[:1]
+	}
+	if cfg.LLM.PlanTargets {
+		chain = append(chain, cfg.LLM.ResolvedPlanner())
+	}
+	if len(cfg.LLM.Ensemble.Candidates) > 0 {
+		chain = append(chain, cfg.LLM.ResolvedCandidates()...)
+		chain = append(chain, cfg.LLM.ResolvedJudge())
+	}
+
+	client := &http.Client{Timeout: time.Duration(cfg.LLM.Timeout) * time.Second}
+	installed := map[string]map[string]bool{}
+	var missing []OllamaModel
+	for _, provider := range chain {
+		if provider.Provider != "ollama" {
+			continue
+		}
+		model := OllamaModel{Model: provider.Model, BaseURL: provider.BaseURL}
+		names, ok := installed[model.server()]
+		if !ok {
+			var err error
+			if names, err = ollamaTags(ctx, client, model.server()); err != nil {
+				return nil, err
+			}
+			installed[model.server()] = names
+		}
+		if !names[model.Model] && !names[model.Model+":latest"] {
+			missing = append(missing, model)
+			names[model.Model] = true
+		}
+	}
+	return missing, nil
+}
+
+func ollamaTags(ctx context.Context, client *http.Client, server string) (map[string]bool, error) {
+	body, err := ollamaRequest(ctx, client, http.MethodGet, server+"/api/tags", nil)
+	if err != nil {
+		return nil, err
 	}
 
 	var parsed struct {
-		Response string `json:"response"`
+		Models []struct {
+			Name  string `json:"name"`
+			Model string `json:"model"`
+		} `json:"models"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("parse ollama tags: %w", err)
+	}
+	names := map[string]bool{}
+	for _, m := range parsed.Models {
+		names[m.Name] = true
+		names[m.Model] = true
+	}
+	return names, nil
+}
+
+// PullOllamaModel downloads a model with POST /api/pull and waits until the
+// server has it. Pulls can take minutes, so only ctx bounds it.
+func PullOllamaModel(ctx context.Context, m OllamaModel) error {
+	body, err := ollamaRequest(ctx, &http.Client{}, http.MethodPost, m.server()+"/api/pull", map[string]any{"model": m.Model, "stream": false})
+	if err != nil {
+		return err
 	}
 
+	var parsed struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	}
 	if err := json.Unmarshal(body, &parsed); err != nil {
-		return "", err
+		return fmt.Errorf("parse ollama pull: %w", err)
+	}
+	if parsed.Error != "" {
+		return fmt.Errorf("ollama pull %s: %s", m.Model, parsed.Error)
+	}
+	if parsed.Status != "success" {
+		return fmt.Errorf("ollama pull %s ended with status %q", m.Model, parsed.Status)
 	}
+	return nil
+}
 
-	if strings.TrimSpace(parsed.Response) == "" {
-		return "", fmt.Errorf("ollama response is empty")
+func ollamaRequest(ctx context.Context, client *http.Client, method, url string, payload any) ([]byte, error) {
+	var reader io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		req.Header.Set("content-type", "application/json")
 	}
 
-	return strings.TrimSpace(parsed.Response), nil
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, newHTTPError("ollama", resp, body)
+	}
+	return body, nil
 }
@@ -2,7 +2,9 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/kowshik24/git-doc/internal/config"
@@ -41,3 +43,90 @@ func TestOllamaGenerate_HTTPError(t *testing.T) {
 	_, err := client.Generate(context.Background(), "prompt")
 	assertErrorContains(t, err, "ollama request failed")
 }
+
+func TestOllamaGenerate_ChatSendsOptions(t *testing.T) {
+	server := newJSONTestServer(t, http.StatusOK, `{"message":{"role":"assistant","content":" chat output "}}`, func(t *testing.T, r *http.Request) {
+		var body struct {
+			Messages []map[string]string `json:"messages"`
+			Options  map[string]float64  `json:"options"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if len(body.Messages) != 1 || body.Messages[0]["role"] != "user" || body.Messages[0]["content"] != "prompt" {
+			t.Errorf("unexpected messages %v", body.Messages)
+		}
+		if body.Options["num_ctx"] != 8192 || body.Options["temperature"] != 0.2 {
+			t.Errorf("unexpected options %v", body.Options)
+		}
+	})
+	defer server.Close()
+
+	temperature := 0.2
+	cfg := config.Default()
+	cfg.LLM.Provider = "ollama"
+	cfg.LLM.Model = "llama3"
+	cfg.LLM.Temperature = &temperature
+	cfg.LLM.BaseURL = server.URL
+	cfg.LLM.Ollama.Chat = true
+	cfg.LLM.Ollama.NumCtx = 8192
+
+	client := NewOllamaClient(cfg)
+	if client.url != server.URL+"/api/chat" {
+		t.Fatalf("unexpected chat url %q", client.url)
+	}
+	out, err := client.Generate(context.Background(), "prompt")
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if out != "chat output" {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestMissingOllamaModelsAndPull(t *testing.T) {
+	var pulled string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			_, _ = w.Write([]byte(`{"models":[{"name":"llama3:latest","model":"llama3:latest"}]}`))
+		case "/api/pull":
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			pulled, _ = body["model"].(string)
+			_, _ = w.Write([]byte(`{"status":"success"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cfg := config.Default()
+	cfg.LLM.Provider = "ollama"
+	cfg.LLM.Model = "llama3"
+	cfg.LLM.BaseURL = server.URL
+
+	missing, err := MissingOllamaModels(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("list models: %v", err)
+	}
+	if len(missing) != 0 {
+		t.Fatalf("expected llama3 to match llama3:latest, got %v", missing)
+	}
+
+	cfg.LLM.Model = "qwen2.5-coder:7b"
+	missing, err = MissingOllamaModels(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("list models: %v", err)
+	}
+	if len(missing) != 1 || missing[0].Model != "qwen2.5-coder:7b" {
+		t.Fatalf("expected qwen2.5-coder:7b to be missing, got %v", missing)
+	}
+
+	if err := PullOllamaModel(context.Background(), missing[0]); err != nil {
+		t.Fatalf("pull: %v", err)
+	}
+	if pulled != "qwen2.5-coder:7b" {
+		t.Fatalf("unexpected pulled model %q", pulled)
+	}
+}
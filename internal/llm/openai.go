@@ -16,16 +16,20 @@ import (
 type OpenAIClient struct {
 	apiKey string
 	model  string
+	params generationParams
 	http   *http.Client
+	url    string
 }
 
 func NewOpenAIClient(cfg *config.Config) *OpenAIClient {
 	return &OpenAIClient{
 		apiKey: cfg.LLM.APIKey,
 		model:  cfg.LLM.Model,
+		params: newGenerationParams(cfg),
 		http: &http.Client{
 			Timeout: time.Duration(cfg.LLM.Timeout) * time.Second,
 		},
+		url: endpointURL(cfg.LLM.BaseURL, "https://api.openai.com/v1/chat/completions", "/chat/completions"),
 	}
 }
 
@@ -34,11 +38,9 @@ func (o *OpenAIClient) Name() string {
 }
 
 func (o *OpenAIClient) Generate(ctx context.Context, prompt string) (string, error) {
-	requestBody := map[string]any{
-		"model": o.model,
-		"messages": []map[string]string{
-			{"role": "user", "content": prompt},
-		},
+	requestBody := o.params.chatRequest(o.model, prompt)
+	if o.params.structured {
+		requestBody["response_format"] = openAIResponseFormat()
 	}
 
 	b, err := json.Marshal(requestBody)
@@ -46,7 +48,7 @@ func (o *OpenAIClient) Generate(ctx context.Context, prompt string) (string, err
 		return "", err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.openai.com/v1/chat/completions", bytes.NewReader(b))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.url, bytes.NewReader(b))
 	if err != nil {
 		return "", err
 	}
@@ -65,7 +67,7 @@ func (o *OpenAIClient) Generate(ctx context.Context, prompt string) (string, err
 	}
 
 	if resp.StatusCode >= 300 {
-		return "", fmt.Errorf("openai request failed: %s", strings.TrimSpace(string(body)))
+		return "", newHTTPError("openai", resp, body)
 	}
 
 	var parsed struct {
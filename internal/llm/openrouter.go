@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+const openRouterDefaultModel = "openrouter/auto"
+
+type OpenRouterClient struct {
+	apiKey string
+	model  string
+	params generationParams
+	http   *http.Client
+	url    string
+}
+
+func NewOpenRouterClient(cfg *config.Config) *OpenRouterClient {
+	return &OpenRouterClient{
+		apiKey: cfg.LLM.APIKey,
+		model:  NormalizeOpenRouterModel(cfg.LLM.Model),
+		params: newGenerationParams(cfg),
+		http: &http.Client{
+			Timeout: time.Duration(cfg.LLM.Timeout) * time.Second,
+		},
+		url: endpointURL(cfg.LLM.BaseURL, "https://openrouter.ai/api/v1/chat/completions", "/chat/completions"),
+	}
+}
+
+// NormalizeOpenRouterModel strips an optional "openrouter:" prefix and
+// defaults to OpenRouter's auto router. Models keep their vendor routing
+// prefix (e.g. "anthropic/claude-3.5-sonnet") and variant suffixes (":free").
+func NormalizeOpenRouterModel(model string) string {
+	model = strings.TrimSpace(model)
+	model = strings.TrimPrefix(model, "openrouter:")
+	if model == "" {
+		return openRouterDefaultModel
+	}
+	return model
+}
+
+func (o *OpenRouterClient) Name() string {
+	return "openrouter"
+}
+
+func (o *OpenRouterClient) Generate(ctx context.Context, prompt string) (string, error) {
+	requestBody := o.params.chatRequest(o.model, prompt)
+
+	b, err := json.Marshal(requestBody)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.url, bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("HTTP-Referer", "https://github.com/kowshik24/git-doc")
+	req.Header.Set("X-Title", "git-doc")
+
+	resp, err := o.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode >= 300 {
+		return "", newHTTPError("openrouter", resp, body)
+	}
+
+	var parsed struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return "", err
+	}
+
+	if parsed.Error != nil && parsed.Error.Message != "" {
+		return "", fmt.Errorf("openrouter request failed: %s", parsed.Error.Message)
+	}
+
+	if len(parsed.Choices) == 0 || strings.TrimSpace(parsed.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("openrouter response has no choices")
+	}
+
+	return strings.TrimSpace(parsed.Choices[0].Message.Content), nil
+}
@@ -0,0 +1,68 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+func TestOpenRouterGenerate_SuccessUsesRoutedModel(t *testing.T) {
+	server := newJSONTestServer(t, http.StatusOK, `{"choices":[{"message":{"content":"  routed output  "}}]}`, func(t *testing.T, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			t.Fatalf("expected Authorization header to be set")
+		}
+		var body struct {
+			Model string `json:"model"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if body.Model != "anthropic/claude-3.5-sonnet" {
+			t.Fatalf("expected routing prefix to be preserved, got %q", body.Model)
+		}
+	})
+	defer server.Close()
+
+	cfg := config.Default()
+	cfg.LLM.Provider = "openrouter"
+	cfg.LLM.APIKey = "test-key"
+	cfg.LLM.Model = "openrouter:anthropic/claude-3.5-sonnet"
+
+	client := NewOpenRouterClient(cfg)
+	client.url = server.URL
+
+	out, err := client.Generate(context.Background(), "prompt")
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if out != "routed output" {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestOpenRouterGenerate_HTTPError(t *testing.T) {
+	server := newJSONTestServer(t, http.StatusPaymentRequired, `insufficient credits`, nil)
+	defer server.Close()
+
+	cfg := config.Default()
+	cfg.LLM.Provider = "openrouter"
+	cfg.LLM.APIKey = "test-key"
+
+	client := NewOpenRouterClient(cfg)
+	client.url = server.URL
+
+	_, err := client.Generate(context.Background(), "prompt")
+	assertErrorContains(t, err, "openrouter request failed")
+}
+
+func TestNormalizeOpenRouterModel(t *testing.T) {
+	if got := NormalizeOpenRouterModel(""); got != "openrouter/auto" {
+		t.Fatalf("expected auto router default, got %q", got)
+	}
+	if got := NormalizeOpenRouterModel("openrouter:meta-llama/llama-3.1-8b-instruct:free"); got != "meta-llama/llama-3.1-8b-instruct:free" {
+		t.Fatalf("unexpected normalized model: %q", got)
+	}
+}
@@ -0,0 +1,48 @@
+package llm
+
+import "github.com/kowshik24/git-doc/internal/config"
+
+const anthropicDefaultMaxTokens = 4096
+
+type generationParams struct {
+	system      string
+	temperature *float64
+	topP        *float64
+	maxTokens   int
+	structured  bool
+}
+
+func newGenerationParams(cfg *config.Config) generationParams {
+	return generationParams{
+		system:      cfg.LLM.SystemPrompt,
+		temperature: cfg.LLM.Temperature,
+		topP:        cfg.LLM.TopP,
+		maxTokens:   cfg.LLM.MaxTokens,
+		structured:  cfg.LLM.StructuredOutput,
+	}
+}
+
+// chatRequest builds an OpenAI-compatible chat completion body, which is
+// shared by OpenAI, Groq, Mistral and OpenRouter.
+func (p generationParams) chatRequest(model, prompt string) map[string]any {
+	messages := make([]map[string]string, 0, 2)
+	if p.system != "" {
+		messages = append(messages, map[string]string{"role": "system", "content": p.system})
+	}
+	messages = append(messages, map[string]string{"role": "user", "content": prompt})
+
+	body := map[string]any{
+		"model":    model,
+		"messages": messages,
+	}
+	if p.temperature != nil {
+		body["temperature"] = *p.temperature
+	}
+	if p.topP != nil {
+		body["top_p"] = *p.topP
+	}
+	if p.maxTokens > 0 {
+		body["max_tokens"] = p.maxTokens
+	}
+	return body
+}
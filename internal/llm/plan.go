@@ -0,0 +1,32 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const PlanInstructions = `Respond with a single JSON object and nothing else, using exactly these fields:
+- "doc_file": the documentation file to update, exactly as listed in the inventory
+- "section": the heading title to update; use an existing heading when one fits, otherwise a short new heading title
+- "reason": one sentence explaining the choice`
+
+// TargetPlan is a planner's answer to which doc section a commit affects.
+type TargetPlan struct {
+	DocFile string `json:"doc_file"`
+	Section string `json:"section"`
+	Reason  string `json:"reason"`
+}
+
+func ParseTargetPlan(raw string) (TargetPlan, error) {
+	var plan TargetPlan
+	if err := json.Unmarshal([]byte(stripJSONFence(raw)), &plan); err != nil {
+		return TargetPlan{}, fmt.Errorf("malformed plan response: %w", err)
+	}
+	plan.DocFile = strings.TrimSpace(plan.DocFile)
+	plan.Section = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(plan.Section), "#"))
+	if plan.DocFile == "" || plan.Section == "" {
+		return TargetPlan{}, fmt.Errorf("malformed plan response: doc_file and section are required")
+	}
+	return plan, nil
+}
@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"sort"
+	"strings"
+)
+
+// ModelPrice is the list price in USD per million tokens.
+type ModelPrice struct {
+	InputPerMTok  float64
+	OutputPerMTok float64
+}
+
+func (p ModelPrice) Cost(inputTokens, outputTokens int) float64 {
+	return float64(inputTokens)/1e6*p.InputPerMTok + float64(outputTokens)/1e6*p.OutputPerMTok
+}
+
+var modelPrices = map[string]map[string]ModelPrice{
+	"openai": {
+		"gpt-4o-mini":  {InputPerMTok: 0.15, OutputPerMTok: 0.60},
+		"gpt-4o":       {InputPerMTok: 2.50, OutputPerMTok: 10.00},
+		"gpt-4.1-nano": {InputPerMTok: 0.10, OutputPerMTok: 0.40},
+		"gpt-4.1-mini": {InputPerMTok: 0.40, OutputPerMTok: 1.60},
+		"gpt-4.1":      {InputPerMTok: 2.00, OutputPerMTok: 8.00},
+		"o3-mini":      {InputPerMTok: 1.10, OutputPerMTok: 4.40},
+	},
+	"anthropic": {
+		"claude-3-5-haiku":  {InputPerMTok: 0.80, OutputPerMTok: 4.00},
+		"claude-3-haiku":    {InputPerMTok: 0.25, OutputPerMTok: 1.25},
+		"claude-3-5-sonnet": {InputPerMTok: 3.00, OutputPerMTok: 15.00},
+		"claude-3-7-sonnet": {InputPerMTok: 3.00, OutputPerMTok: 15.00},
+		"claude-sonnet-4":   {InputPerMTok: 3.00, OutputPerMTok: 15.00},
+		"claude-3-opus":     {InputPerMTok: 15.00, OutputPerMTok: 75.00},
+		"claude-opus-4":     {InputPerMTok: 15.00, OutputPerMTok: 75.00},
+	},
+	"gemini": {
+		"gemini-1.5-flash": {InputPerMTok: 0.075, OutputPerMTok: 0.30},
+		"gemini-1.5-pro":   {InputPerMTok: 1.25, OutputPerMTok: 5.00},
+		"gemini-2.0-flash": {InputPerMTok: 0.10, OutputPerMTok: 0.40},
+		"gemini-2.5-flash": {InputPerMTok: 0.30, OutputPerMTok: 2.50},
+		"gemini-2.5-pro":   {InputPerMTok: 1.25, OutputPerMTok: 10.00},
+	},
+	"groq": {
+		"llama-3.1-8b-instant":    {InputPerMTok: 0.05, OutputPerMTok: 0.08},
+		"llama-3.3-70b-versatile": {InputPerMTok: 0.59, OutputPerMTok: 0.79},
+	},
+	"mistral": {
+		"mistral-small":  {InputPerMTok: 0.20, OutputPerMTok: 0.60},
+		"mistral-medium": {InputPerMTok: 0.40, OutputPerMTok: 2.00},
+		"mistral-large":  {InputPerMTok: 2.00, OutputPerMTok: 6.00},
+		"codestral":      {InputPerMTok: 0.30, OutputPerMTok: 0.90},
+	},
+}
+
+// LookupPrice returns the list price for a provider/model pair, matching the
+// longest known model prefix so dated snapshots resolve to their family.
+// Local and mock providers are free.
+func LookupPrice(provider, model string) (ModelPrice, bool) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	model = strings.ToLower(strings.TrimSpace(model))
+
+	switch provider {
+	case "", "mock", "ollama":
+		return ModelPrice{}, true
+	case "google":
+		provider = "gemini"
+	case "openrouter":
+		vendor, name, ok := strings.Cut(NormalizeOpenRouterModel(model), "/")
+		if !ok {
+			return ModelPrice{}, false
+		}
+		provider, model = vendor, name
+		switch vendor {
+		case "google":
+			provider = "gemini"
+		case "anthropic":
+			model = strings.ReplaceAll(name, ".", "-")
+		}
+	}
+
+	prices, ok := modelPrices[provider]
+	if !ok {
+		return ModelPrice{}, false
+	}
+
+	prefixes := make([]string, 0, len(prices))
+	for prefix := range prices {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(model, prefix) {
+			return prices[prefix], true
+		}
+	}
+	return ModelPrice{}, false
+}
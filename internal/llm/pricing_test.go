@@ -0,0 +1,30 @@
+package llm
+
+import "testing"
+
+func TestLookupPrice(t *testing.T) {
+	tests := []struct {
+		provider string
+		model    string
+		want     ModelPrice
+		ok       bool
+	}{
+		{provider: "openai", model: "gpt-4o-mini-2024-07-18", want: ModelPrice{InputPerMTok: 0.15, OutputPerMTok: 0.60}, ok: true},
+		{provider: "openai", model: "gpt-4o", want: ModelPrice{InputPerMTok: 2.50, OutputPerMTok: 10.00}, ok: true},
+		{provider: "anthropic", model: "claude-3-5-haiku-latest", want: ModelPrice{InputPerMTok: 0.80, OutputPerMTok: 4.00}, ok: true},
+		{provider: "openrouter", model: "anthropic/claude-3.5-sonnet", want: ModelPrice{InputPerMTok: 3.00, OutputPerMTok: 15.00}, ok: true},
+		{provider: "ollama", model: "llama3", ok: true},
+		{provider: "openai", model: "unknown-model", ok: false},
+	}
+
+	for _, tc := range tests {
+		got, ok := LookupPrice(tc.provider, tc.model)
+		if ok != tc.ok || got != tc.want {
+			t.Fatalf("LookupPrice(%q, %q) = %+v, %v; want %+v, %v", tc.provider, tc.model, got, ok, tc.want, tc.ok)
+		}
+	}
+
+	if cost := (ModelPrice{InputPerMTok: 1, OutputPerMTok: 2}).Cost(500000, 250000); cost != 1.0 {
+		t.Fatalf("expected cost 1.0, got %v", cost)
+	}
+}
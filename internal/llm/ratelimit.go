@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type RateLimiter struct {
+	rpm int
+	tpm int
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries []rateEntry
+}
+
+type rateEntry struct {
+	at     time.Time
+	tokens int
+}
+
+func NewRateLimiter(requestsPerMinute, tokensPerMinute int) *RateLimiter {
+	return &RateLimiter{rpm: requestsPerMinute, tpm: tokensPerMinute, now: time.Now}
+}
+
+// Wait blocks until a request of the given token size fits inside the
+// sliding one-minute window, or the context is cancelled.
+func (r *RateLimiter) Wait(ctx context.Context, tokens int) error {
+	for {
+		delay := r.reserve(tokens)
+		if delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (r *RateLimiter) reserve(tokens int) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	windowStart := now.Add(-time.Minute)
+	kept := r.entries[:0]
+	usedTokens := 0
+	for _, entry := range r.entries {
+		if entry.at.After(windowStart) {
+			kept = append(kept, entry)
+			usedTokens += entry.tokens
+		}
+	}
+	r.entries = kept
+
+	if r.rpm > 0 && len(r.entries) >= r.rpm {
+		return r.entries[len(r.entries)-r.rpm].at.Sub(windowStart)
+	}
+
+	if r.tpm > 0 && len(r.entries) > 0 && usedTokens+tokens > r.tpm {
+		freed := 0
+		for _, entry := range r.entries {
+			freed += entry.tokens
+			if usedTokens-freed+tokens <= r.tpm {
+				return entry.at.Sub(windowStart)
+			}
+		}
+		return r.entries[len(r.entries)-1].at.Sub(windowStart)
+	}
+
+	r.entries = append(r.entries, rateEntry{at: now, tokens: tokens})
+	return 0
+}
+
+type RateLimitedClient struct {
+	inner   Client
+	limiter *RateLimiter
+}
+
+func NewRateLimitedClient(inner Client, limiter *RateLimiter) *RateLimitedClient {
+	return &RateLimitedClient{inner: inner, limiter: limiter}
+}
+
+func (c *RateLimitedClient) Name() string {
+	return c.inner.Name()
+}
+
+func (c *RateLimitedClient) Generate(ctx context.Context, prompt string) (string, error) {
+	if err := c.limiter.Wait(ctx, EstimateTokensFor(c.inner.Name(), prompt)); err != nil {
+		return "", err
+	}
+	return c.inner.Generate(ctx, prompt)
+}
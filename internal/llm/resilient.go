@@ -2,21 +2,48 @@ package llm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 )
 
 type ResilientClient struct {
 	clients    []Client
 	maxRetries int
+	now        func() time.Time
+
+	mu       sync.Mutex
+	breakers []*circuitBreaker
+	observer func(BreakerEvent)
 }
 
 func NewResilientClient(clients []Client, maxRetries int) *ResilientClient {
 	if maxRetries < 0 {
 		maxRetries = 0
 	}
-	return &ResilientClient{clients: clients, maxRetries: maxRetries}
+	return &ResilientClient{clients: clients, maxRetries: maxRetries, now: time.Now}
+}
+
+func (c *ResilientClient) SetCircuitBreaker(threshold int, cooldown time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if threshold <= 0 {
+		c.breakers = nil
+		return
+	}
+	c.breakers = make([]*circuitBreaker, len(c.clients))
+	for i := range c.clients {
+		c.breakers[i] = newCircuitBreaker(threshold, cooldown)
+	}
+}
+
+func (c *ResilientClient) OnBreakerChange(fn func(BreakerEvent)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.observer = fn
 }
 
 func (c *ResilientClient) Name() string {
@@ -33,7 +60,12 @@ func (c *ResilientClient) Generate(ctx context.Context, prompt string) (string,
 	}
 
 	var lastErr error
-	for _, provider := range c.clients {
+	for i, provider := range c.clients {
+		if !c.allow(i) {
+			lastErr = fmt.Errorf("provider %s skipped: circuit breaker open", provider.Name())
+			continue
+		}
+
 		for attempt := 0; attempt <= c.maxRetries; attempt++ {
 			if ctx.Err() != nil {
 				return "", ctx.Err()
@@ -41,12 +73,20 @@ func (c *ResilientClient) Generate(ctx context.Context, prompt string) (string,
 
 			result, err := provider.Generate(ctx, prompt)
 			if err == nil {
+				c.recordSuccess(i)
 				return result, nil
 			}
 			lastErr = fmt.Errorf("provider %s attempt %d failed: %w", provider.Name(), attempt+1, err)
+			if errors.Is(err, ErrOffline) {
+				break
+			}
+
+			if c.recordFailure(i, err) || IsPermanent(err) {
+				break
+			}
 
 			if attempt < c.maxRetries {
-				delay := time.Duration(1<<attempt) * 150 * time.Millisecond
+				delay := retryDelay(attempt, err)
 				select {
 				case <-ctx.Done():
 					return "", ctx.Err()
@@ -61,3 +101,79 @@ func (c *ResilientClient) Generate(ctx context.Context, prompt string) (string,
 	}
 	return "", lastErr
 }
+
+func (c *ResilientClient) allow(i int) bool {
+	c.mu.Lock()
+	if c.breakers == nil {
+		c.mu.Unlock()
+		return true
+	}
+	breaker := c.breakers[i]
+	allowed, probing := breaker.allow(c.now())
+	observer := c.observer
+	event := BreakerEvent{Provider: c.clients[i].Name(), State: breaker.state, Failures: breaker.failures}
+	c.mu.Unlock()
+
+	if probing && observer != nil {
+		observer(event)
+	}
+	return allowed
+}
+
+func (c *ResilientClient) recordSuccess(i int) {
+	c.mu.Lock()
+	if c.breakers == nil {
+		c.mu.Unlock()
+		return
+	}
+	changed := c.breakers[i].recordSuccess()
+	observer := c.observer
+	c.mu.Unlock()
+
+	if changed && observer != nil {
+		observer(BreakerEvent{Provider: c.clients[i].Name(), State: BreakerClosed})
+	}
+}
+
+// recordFailure reports whether the failure tripped the provider's breaker,
+// in which case remaining retries are skipped in favour of fallbacks.
+func (c *ResilientClient) recordFailure(i int, err error) bool {
+	c.mu.Lock()
+	if c.breakers == nil {
+		c.mu.Unlock()
+		return false
+	}
+	breaker := c.breakers[i]
+	tripped := breaker.recordFailure(c.now())
+	observer := c.observer
+	event := BreakerEvent{
+		Provider: c.clients[i].Name(),
+		State:    breaker.state,
+		Failures: breaker.failures,
+		Until:    breaker.openUntil,
+		Err:      err.Error(),
+	}
+	c.mu.Unlock()
+
+	if tripped && observer != nil {
+		observer(event)
+	}
+	return tripped
+}
+
+const maxRetryAfter = 2 * time.Minute
+
+func retryDelay(attempt int, err error) time.Duration {
+	backoff := time.Duration(1<<attempt) * 150 * time.Millisecond
+	retryAfter, throttled := retryAfterFromError(err)
+	if retryAfter > 0 {
+		if retryAfter > maxRetryAfter {
+			return maxRetryAfter
+		}
+		return retryAfter
+	}
+	if throttled {
+		return backoff * 4
+	}
+	return backoff
+}
@@ -3,7 +3,10 @@ package llm
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
 	"testing"
+	"time"
 )
 
 type flakyClient struct {
@@ -58,3 +61,176 @@ func TestResilientClientFallsBack(t *testing.T) {
 		t.Fatalf("expected fallback provider to be called")
 	}
 }
+
+type rejectingClient struct {
+	status int
+	called int
+}
+
+func (r *rejectingClient) Name() string {
+	return "rejecting"
+}
+
+func (r *rejectingClient) Generate(ctx context.Context, prompt string) (string, error) {
+	r.called++
+	return "", &HTTPError{Provider: "rejecting", StatusCode: r.status, Body: "rejected"}
+}
+
+func TestResilientClientSkipsRetriesOnPermanentErrors(t *testing.T) {
+	unauthorized := &rejectingClient{status: http.StatusUnauthorized}
+	fallback := &flakyClient{name: "fallback"}
+	client := NewResilientClient([]Client{unauthorized, fallback}, 3)
+
+	if _, err := client.Generate(context.Background(), "prompt"); err != nil {
+		t.Fatalf("expected fallback success, got err: %v", err)
+	}
+	if unauthorized.called != 1 {
+		t.Fatalf("expected a 401 not to be retried, got %d calls", unauthorized.called)
+	}
+
+	if IsPermanent(&HTTPError{StatusCode: http.StatusTooManyRequests}) || !IsPermanent(fmt.Errorf("wrapped: %w", &HTTPError{StatusCode: http.StatusNotFound})) {
+		t.Fatalf("expected 429 to be transient and a wrapped 404 permanent")
+	}
+}
+
+func TestRetryDelayHonorsRetryAfter(t *testing.T) {
+	throttled := &HTTPError{Provider: "openai", StatusCode: 429, RetryAfter: 3 * time.Second}
+	if got := retryDelay(0, throttled); got != 3*time.Second {
+		t.Fatalf("expected Retry-After delay of 3s, got %s", got)
+	}
+
+	capped := &HTTPError{Provider: "openai", StatusCode: 429, RetryAfter: time.Hour}
+	if got := retryDelay(0, capped); got != maxRetryAfter {
+		t.Fatalf("expected delay capped at %s, got %s", maxRetryAfter, got)
+	}
+
+	noHeader := &HTTPError{Provider: "openai", StatusCode: 503}
+	if got := retryDelay(0, noHeader); got != 600*time.Millisecond {
+		t.Fatalf("expected throttled backoff of 600ms, got %s", got)
+	}
+
+	if got := retryDelay(1, errors.New("boom")); got != 300*time.Millisecond {
+		t.Fatalf("expected exponential backoff of 300ms, got %s", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	header := http.Header{}
+	header.Set("Retry-After", "2")
+	if got := parseRetryAfter(header, now); got != 2*time.Second {
+		t.Fatalf("expected 2s, got %s", got)
+	}
+
+	header = http.Header{}
+	header.Set("Retry-After", now.Add(5*time.Second).Format(http.TimeFormat))
+	if got := parseRetryAfter(header, now); got != 5*time.Second {
+		t.Fatalf("expected 5s from HTTP date, got %s", got)
+	}
+
+	header = http.Header{}
+	header.Set("retry-after-ms", "250")
+	if got := parseRetryAfter(header, now); got != 250*time.Millisecond {
+		t.Fatalf("expected 250ms, got %s", got)
+	}
+
+	if got := parseRetryAfter(http.Header{}, now); got != 0 {
+		t.Fatalf("expected no delay without header, got %s", got)
+	}
+}
+
+func TestRateLimiterEnforcesRequestsPerMinute(t *testing.T) {
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(2, 0)
+	limiter.now = func() time.Time { return current }
+
+	if d := limiter.reserve(10); d != 0 {
+		t.Fatalf("expected first request to pass, got wait %s", d)
+	}
+	current = current.Add(10 * time.Second)
+	if d := limiter.reserve(10); d != 0 {
+		t.Fatalf("expected second request to pass, got wait %s", d)
+	}
+	if d := limiter.reserve(10); d != 50*time.Second {
+		t.Fatalf("expected third request to wait 50s, got %s", d)
+	}
+	current = current.Add(50 * time.Second)
+	if d := limiter.reserve(10); d != 0 {
+		t.Fatalf("expected request to pass once window slides, got wait %s", d)
+	}
+}
+
+func TestRateLimiterEnforcesTokensPerMinute(t *testing.T) {
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(0, 100)
+	limiter.now = func() time.Time { return current }
+
+	if d := limiter.reserve(80); d != 0 {
+		t.Fatalf("expected first request to pass, got wait %s", d)
+	}
+	current = current.Add(30 * time.Second)
+	if d := limiter.reserve(40); d != 30*time.Second {
+		t.Fatalf("expected token budget wait of 30s, got %s", d)
+	}
+}
+
+func TestRateLimitedClientStopsOnCancelledContext(t *testing.T) {
+	limiter := NewRateLimiter(1, 0)
+	inner := &flakyClient{name: "primary"}
+	client := NewRateLimitedClient(inner, limiter)
+
+	if _, err := client.Generate(context.Background(), "prompt"); err != nil {
+		t.Fatalf("expected first call to pass, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.Generate(ctx, "prompt"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context cancellation, got %v", err)
+	}
+	if inner.called != 1 {
+		t.Fatalf("expected limited call not to reach provider, got %d calls", inner.called)
+	}
+}
+
+func TestResilientClientCircuitBreakerSkipsOpenProvider(t *testing.T) {
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	primary := &flakyClient{name: "primary", failCount: 2}
+	fallback := &flakyClient{name: "fallback"}
+	client := NewResilientClient([]Client{primary, fallback}, 3)
+	client.now = func() time.Time { return current }
+	client.SetCircuitBreaker(2, time.Minute)
+
+	var events []BreakerEvent
+	client.OnBreakerChange(func(event BreakerEvent) { events = append(events, event) })
+
+	if _, err := client.Generate(context.Background(), "prompt"); err != nil {
+		t.Fatalf("expected fallback success, got err: %v", err)
+	}
+	if primary.called != 2 {
+		t.Fatalf("expected breaker to stop retries after 2 failures, got %d calls", primary.called)
+	}
+	if len(events) != 1 || events[0].State != BreakerOpen || events[0].Provider != "primary" {
+		t.Fatalf("expected open event for primary, got %+v", events)
+	}
+
+	if _, err := client.Generate(context.Background(), "prompt"); err != nil {
+		t.Fatalf("expected fallback success, got err: %v", err)
+	}
+	if primary.called != 2 {
+		t.Fatalf("expected open breaker to skip primary, got %d calls", primary.called)
+	}
+
+	current = current.Add(time.Minute)
+	out, err := client.Generate(context.Background(), "prompt")
+	if err != nil || out != "ok" {
+		t.Fatalf("expected half-open probe to succeed, got %q err=%v", out, err)
+	}
+	if primary.called != 3 {
+		t.Fatalf("expected probe call to primary, got %d calls", primary.called)
+	}
+	if len(events) != 3 || events[1].State != BreakerHalfOpen || events[2].State != BreakerClosed {
+		t.Fatalf("expected half_open then closed events, got %+v", events)
+	}
+}
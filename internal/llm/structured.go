@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const StructuredOutputInstructions = `Respond with a single JSON object and nothing else, using exactly these fields:
+- "section_markdown": the full replacement markdown for the section
+- "summary": one sentence describing what changed
+- "confidence": a number between 0 and 1 for how confident you are that the update is accurate`
+
+type SectionResponse struct {
+	SectionMarkdown string  `json:"section_markdown"`
+	Summary         string  `json:"summary"`
+	Confidence      float64 `json:"confidence"`
+}
+
+func sectionResponseSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"section_markdown": map[string]any{"type": "string"},
+			"summary":          map[string]any{"type": "string"},
+			"confidence":       map[string]any{"type": "number"},
+		},
+		"required":             []string{"section_markdown", "summary", "confidence"},
+		"additionalProperties": false,
+	}
+}
+
+func openAIResponseFormat() map[string]any {
+	return map[string]any{
+		"type": "json_schema",
+		"json_schema": map[string]any{
+			"name":   "doc_section",
+			"strict": true,
+			"schema": sectionResponseSchema(),
+		},
+	}
+}
+
+func geminiResponseSchema() map[string]any {
+	return map[string]any{
+		"type": "OBJECT",
+		"properties": map[string]any{
+			"section_markdown": map[string]any{"type": "STRING"},
+			"summary":          map[string]any{"type": "STRING"},
+			"confidence":       map[string]any{"type": "NUMBER"},
+		},
+		"required": []string{"section_markdown", "summary", "confidence"},
+	}
+}
+
+// ParseSectionResponse decodes a structured section response. Providers
+// without native structured output sometimes wrap the object in a code
+// fence, so that is stripped before decoding.
+func ParseSectionResponse(raw string) (SectionResponse, error) {
+	decoder := json.NewDecoder(strings.NewReader(stripJSONFence(raw)))
+	decoder.DisallowUnknownFields()
+
+	var out struct {
+		SectionMarkdown *string  `json:"section_markdown"`
+		Summary         string   `json:"summary"`
+		Confidence      *float64 `json:"confidence"`
+	}
+	if err := decoder.Decode(&out); err != nil {
+		return SectionResponse{}, fmt.Errorf("malformed structured response: %w", err)
+	}
+	if out.SectionMarkdown == nil || strings.TrimSpace(*out.SectionMarkdown) == "" {
+		return SectionResponse{}, fmt.Errorf("malformed structured response: section_markdown is missing")
+	}
+	if out.Confidence == nil {
+		return SectionResponse{}, fmt.Errorf("malformed structured response: confidence is missing")
+	}
+	if math.IsNaN(*out.Confidence) || *out.Confidence < 0 || *out.Confidence > 1 {
+		return SectionResponse{}, fmt.Errorf("malformed structured response: confidence %g is outside 0..1", *out.Confidence)
+	}
+
+	return SectionResponse{
+		SectionMarkdown: strings.TrimSpace(*out.SectionMarkdown),
+		Summary:         strings.TrimSpace(out.Summary),
+		Confidence:      *out.Confidence,
+	}, nil
+}
+
+func stripJSONFence(raw string) string {
+	text := strings.TrimSpace(raw)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```json")
+		text = strings.TrimPrefix(text, "```")
+		text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+	}
+	return text
+}
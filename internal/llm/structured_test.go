@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+func TestParseSectionResponse(t *testing.T) {
+	got, err := ParseSectionResponse("```json\n{\"section_markdown\":\"## API\\n- new\",\"summary\":\"adds api\",\"confidence\":0.8}\n```")
+	if err != nil {
+		t.Fatalf("expected fenced response to parse, got %v", err)
+	}
+	if got.SectionMarkdown != "## API\n- new" || got.Summary != "adds api" || got.Confidence != 0.8 {
+		t.Fatalf("unexpected response: %+v", got)
+	}
+
+	for _, raw := range []string{
+		"plain markdown",
+		`{"summary":"x","confidence":0.9}`,
+		`{"section_markdown":"x","summary":"x"}`,
+		`{"section_markdown":"x","summary":"x","confidence":1.5}`,
+		`{"section_markdown":"x","summary":"x","confidence":0.9,"extra":true}`,
+	} {
+		if _, err := ParseSectionResponse(raw); err == nil {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+}
+
+func TestOpenAIGenerate_RequestsJSONSchema(t *testing.T) {
+	server := newJSONTestServer(t, http.StatusOK, `{"choices":[{"message":{"content":"{}"}}]}`, func(t *testing.T, r *http.Request) {
+		var body struct {
+			ResponseFormat struct {
+				Type       string `json:"type"`
+				JSONSchema struct {
+					Name   string         `json:"name"`
+					Schema map[string]any `json:"schema"`
+				} `json:"json_schema"`
+			} `json:"response_format"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if body.ResponseFormat.Type != "json_schema" || body.ResponseFormat.JSONSchema.Schema["type"] != "object" {
+			t.Fatalf("expected json_schema response format, got %+v", body.ResponseFormat)
+		}
+	})
+	defer server.Close()
+
+	cfg := config.Default()
+	cfg.LLM.APIKey = "test-key"
+	cfg.LLM.StructuredOutput = true
+
+	client := NewOpenAIClient(cfg)
+	client.url = server.URL
+
+	if _, err := client.Generate(context.Background(), "prompt"); err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+}
+
+func TestMockClientStructuredOutputParses(t *testing.T) {
+	client := &MockClient{structured: true}
+	out, err := client.Generate(context.Background(), "prompt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseSectionResponse(out); err != nil {
+		t.Fatalf("expected mock structured output to parse, got %v", err)
+	}
+}
+
+func TestParseTargetPlan(t *testing.T) {
+	plan, err := ParseTargetPlan("```json\n{\"doc_file\":\"docs/api.md\",\"section\":\"## Endpoints\",\"reason\":\"new route\"}\n```")
+	if err != nil {
+		t.Fatalf("expected plan to parse, got %v", err)
+	}
+	if plan != (TargetPlan{DocFile: "docs/api.md", Section: "Endpoints", Reason: "new route"}) {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+
+	for _, raw := range []string{"README.md / Usage", `{"doc_file":"README.md"}`, `{"section":"Usage"}`} {
+		if _, err := ParseTargetPlan(raw); err == nil {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// charsPerToken approximates how many characters of a word each provider's
+// tokenizer packs into one token. Punctuation runs and non-ASCII text are
+// counted separately because every BPE vocabulary splits them aggressively.
+var charsPerToken = map[string]float64{
+	"openai":     4.0,
+	"openrouter": 4.0,
+	"anthropic":  3.5,
+	"gemini":     4.0,
+	"google":     4.0,
+	"groq":       3.7,
+	"ollama":     3.7,
+	"mistral":    3.6,
+}
+
+func EstimateTokens(text string) int {
+	return EstimateTokensFor("", text)
+}
+
+func EstimateTokensFor(provider, text string) int {
+	ratio, ok := charsPerToken[strings.ToLower(strings.TrimSpace(provider))]
+	if !ok {
+		ratio = 4.0
+	}
+
+	tokens := 0.0
+	wordLen := 0
+	punctLen := 0
+	flush := func() {
+		if wordLen > 0 {
+			tokens += math.Ceil(float64(wordLen) / ratio)
+			wordLen = 0
+		}
+		if punctLen > 0 {
+			tokens += math.Ceil(float64(punctLen) / 2)
+			punctLen = 0
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case r > unicode.MaxASCII && !unicode.IsSpace(r):
+			flush()
+			tokens++
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			if punctLen > 0 {
+				flush()
+			}
+			wordLen++
+		case unicode.IsSpace(r):
+			flush()
+			if r == '\n' {
+				tokens += 0.5
+			}
+		default:
+			if wordLen > 0 {
+				flush()
+			}
+			punctLen++
+		}
+	}
+	flush()
+
+	return int(math.Ceil(tokens))
+}
@@ -0,0 +1,26 @@
+package llm
+
+import "testing"
+
+func TestEstimateTokensFor(t *testing.T) {
+	if got := EstimateTokensFor("openai", ""); got != 0 {
+		t.Fatalf("expected 0 tokens for empty text, got %d", got)
+	}
+	if got := EstimateTokensFor("openai", "update docs"); got != 3 {
+		t.Fatalf("expected 3 tokens, got %d", got)
+	}
+
+	code := "func main() {\n\tfmt.Println(\"hello, world\")\n}\n"
+	openai := EstimateTokensFor("openai", code)
+	anthropic := EstimateTokensFor("anthropic", code)
+	if openai <= len(code)/6 || openai >= len(code) {
+		t.Fatalf("expected plausible token count for code, got %d for %d chars", openai, len(code))
+	}
+	if anthropic < openai {
+		t.Fatalf("expected anthropic heuristic to count at least as many tokens as openai, got %d < %d", anthropic, openai)
+	}
+
+	if got := EstimateTokensFor("unknown", "update docs"); got != EstimateTokens("update docs") {
+		t.Fatalf("expected unknown provider to use default heuristic")
+	}
+}
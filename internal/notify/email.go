@@ -0,0 +1,159 @@
+package notify
+
+import (
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+// Digest lists the doc sections updated in one digest period.
+type Digest struct {
+	Repo    string
+	Since   time.Time
+	Until   time.Time
+	Entries []DigestEntry
+}
+
+// DigestEntry is one doc commit, or one uncommitted update when DocCommit
+// is empty, with the sections it changed.
+type DigestEntry struct {
+	DocCommit   string
+	URL         string
+	Sections    []string
+	CodeCommits []string
+	Diff        string
+}
+
+// Sections counts the sections across all entries.
+func (d Digest) Sections() int {
+	n := 0
+	for _, entry := range d.Entries {
+		n += len(entry.Sections)
+	}
+	return n
+}
+
+// Render returns the subject and plain-text body of the digest email.
+func (d Digest) Render() (string, string) {
+	subject := fmt.Sprintf("[git-doc] %s: %d doc sections updated", d.Repo, d.Sections())
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "git-doc updated %d doc sections in %s between %s and %s.\n",
+		d.Sections(), d.Repo, d.Since.Local().Format("2006-01-02 15:04"), d.Until.Local().Format("2006-01-02 15:04"))
+	for _, entry := range d.Entries {
+		fmt.Fprintf(&b, "\n== %s\n", strings.Join(entry.Sections, ", "))
+		fmt.Fprintf(&b, "Code commits: %s\n", strings.Join(shortAll(entry.CodeCommits), ", "))
+		switch {
+		case entry.DocCommit == "":
+			b.WriteString("Doc commit: none (written without committing)\n")
+		case entry.URL != "":
+			fmt.Fprintf(&b, "Doc commit: %s %s\n", short(entry.DocCommit), entry.URL)
+		default:
+			fmt.Fprintf(&b, "Doc commit: %s\n", short(entry.DocCommit))
+		}
+		if entry.Diff != "" {
+			b.WriteString("\n" + strings.TrimRight(entry.Diff, "\n") + "\n")
+		}
+	}
+	return subject, b.String()
+}
+
+// Mailer sends digests over SMTP. A nil Mailer sends nothing.
+type Mailer struct {
+	cfg config.EmailConfig
+}
+
+// NewMailer returns a Mailer for cfg, or nil when email is disabled.
+func NewMailer(cfg config.EmailConfig) *Mailer {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &Mailer{cfg: cfg}
+}
+
+// Send delivers one message to every recipient.
+func (m *Mailer) Send(subject, body string) error {
+	if m == nil {
+		return nil
+	}
+	addr := net.JoinHostPort(m.cfg.SMTPHost, strconv.Itoa(m.cfg.SMTPPort))
+	msg := m.message(subject, body, time.Now())
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.SMTPHost)
+	}
+
+	if m.cfg.SMTPPort == 465 {
+		return m.sendTLS(addr, auth, msg)
+	}
+	if err := smtp.SendMail(addr, auth, m.cfg.From, m.cfg.To, msg); err != nil {
+		return fmt.Errorf("send digest: %w", err)
+	}
+	return nil
+}
+
+func (m *Mailer) sendTLS(addr string, auth smtp.Auth, msg []byte) error {
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: m.cfg.SMTPHost})
+	if err != nil {
+		return fmt.Errorf("send digest: %w", err)
+	}
+	client, err := smtp.NewClient(conn, m.cfg.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("send digest: %w", err)
+	}
+	defer client.Close()
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("send digest: %w", err)
+		}
+	}
+	if err := client.Mail(m.cfg.From); err != nil {
+		return fmt.Errorf("send digest: %w", err)
+	}
+	for _, to := range m.cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("send digest: %w", err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("send digest: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("send digest: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("send digest: %w", err)
+	}
+	return client.Quit()
+}
+
+func (m *Mailer) message(subject, body string, now time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", m.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(m.cfg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+func shortAll(hashes []string) []string {
+	out := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		out = append(out, short(hash))
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+// fakeSMTP accepts one message and sends its DATA on the returned channel.
+func fakeSMTP(t *testing.T) (string, int, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	data := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.Fields(line)[0]); cmd {
+			case "EHLO", "HELO", "MAIL", "RCPT":
+				reply("250 ok")
+			case "DATA":
+				reply("354 go ahead")
+				var b strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					b.WriteString(l)
+				}
+				data <- b.String()
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	n, _ := strconv.Atoi(port)
+	return host, n, data
+}
+
+func TestMailerSendsDigest(t *testing.T) {
+	host, port, data := fakeSMTP(t)
+	mailer := NewMailer(config.EmailConfig{Enabled: true, SMTPHost: host, SMTPPort: port, From: "git-doc@example.com", To: []string{"docs@example.com"}})
+
+	digest := Digest{Repo: "demo", Entries: []DigestEntry{{
+		DocCommit:   "dddddddddd",
+		Sections:    []string{"README.md#API"},
+		CodeCommits: []string{"aaaaaaaaaa"},
+		Diff:        "diff --git a/README.md b/README.md\n+new line",
+	}}}
+	if err := mailer.Send(digest.Render()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	msg := <-data
+	for _, want := range []string{"Subject: [git-doc] demo: 1 doc sections updated\r\n", "To: docs@example.com\r\n", "== README.md#API\r\n", "Doc commit: ddddddd\r\n", "+new line\r\n"} {
+		if !strings.Contains(msg, want) {
+			t.Fatalf("message is missing %q:\n%s", want, msg)
+		}
+	}
+
+	if NewMailer(config.EmailConfig{}) != nil {
+		t.Fatalf("expected no mailer when email is disabled")
+	}
+}
@@ -0,0 +1,220 @@
+// Package notify posts run summaries and failure alerts to Slack, Discord or
+// generic JSON webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+// Webhook kinds accepted in [[notifications.webhooks]].
+const (
+	KindSlack   = "slack"
+	KindDiscord = "discord"
+	KindWebhook = "webhook"
+)
+
+// Run is the outcome of one update run.
+type Run struct {
+	RunID       string      `json:"run_id"`
+	Repo        string      `json:"repo"`
+	Trigger     string      `json:"trigger"`
+	Processed   int         `json:"processed"`
+	Success     int         `json:"success"`
+	Failed      int         `json:"failed"`
+	Skipped     int         `json:"skipped"`
+	Pending     int         `json:"pending"`
+	Interrupted bool        `json:"interrupted"`
+	DocCommits  []DocCommit `json:"doc_commits"`
+	Failures    []Failure   `json:"failures"`
+}
+
+// DocCommit links a documented code commit to the doc commit written for it.
+type DocCommit struct {
+	CodeCommit string `json:"code_commit"`
+	DocCommit  string `json:"doc_commit"`
+	URL        string `json:"url,omitempty"`
+}
+
+type Failure struct {
+	Commit string `json:"commit"`
+	Error  string `json:"error"`
+}
+
+// Notifier sends to every configured webhook. A nil Notifier sends nothing.
+type Notifier struct {
+	webhooks []config.Webhook
+	client   *http.Client
+}
+
+// New returns a Notifier for cfg, or nil when no webhooks are configured.
+func New(cfg config.NotificationsConfig) *Notifier {
+	if len(cfg.Webhooks) == 0 {
+		return nil
+	}
+	return &Notifier{webhooks: cfg.Webhooks, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// RunFinished posts the run summary to webhooks that want it.
+func (n *Notifier) RunFinished(ctx context.Context, run Run) error {
+	if n == nil {
+		return nil
+	}
+	var errs []string
+	for _, hook := range n.webhooks {
+		if hook.OnlyFailures && run.Failed == 0 {
+			continue
+		}
+		if err := n.post(ctx, hook, runPayload(hook.Kind, run)); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	return joinErrors(errs)
+}
+
+// RepeatedFailures alerts every webhook that the last failures happened in
+// a row, while the run is still going.
+func (n *Notifier) RepeatedFailures(ctx context.Context, runID, repo string, failures []Failure) error {
+	if n == nil {
+		return nil
+	}
+	var errs []string
+	for _, hook := range n.webhooks {
+		if err := n.post(ctx, hook, alertPayload(hook.Kind, runID, repo, failures)); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	return joinErrors(errs)
+}
+
+func (n *Notifier) post(ctx context.Context, hook config.Webhook, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s webhook: %w", hook.Kind, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s webhook: %w", hook.Kind, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s webhook: %s: %s", hook.Kind, resp.Status, strings.TrimSpace(string(text)))
+	}
+	return nil
+}
+
+func runPayload(kind string, run Run) any {
+	if kind == KindWebhook {
+		return map[string]any{"event": "run_finished", "run": run}
+	}
+
+	var b strings.Builder
+	status := "finished"
+	if run.Interrupted {
+		status = "interrupted"
+	}
+	fmt.Fprintf(&b, "%s run %s %s in %s: processed %d, success %d, failed %d, skipped %d",
+		bold(kind, "git-doc"), run.RunID, status, run.Repo, run.Processed, run.Success, run.Failed, run.Skipped)
+	if run.Pending > 0 {
+		fmt.Fprintf(&b, ", pending %d", run.Pending)
+	}
+	if len(run.DocCommits) > 0 {
+		b.WriteString("\nDoc commits:")
+		for _, c := range run.DocCommits {
+			fmt.Fprintf(&b, "\n• %s for %s", link(kind, short(c.DocCommit), c.URL), short(c.CodeCommit))
+		}
+	}
+	writeFailures(&b, kind, run.Failures)
+	return textPayload(kind, b.String())
+}
+
+func alertPayload(kind, runID, repo string, failures []Failure) any {
+	if kind == KindWebhook {
+		return map[string]any{"event": "repeated_failures", "run_id": runID, "repo": repo, "failures": failures}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s run %s in %s: %d commits failed in a row", bold(kind, "git-doc alert"), runID, repo, len(failures))
+	writeFailures(&b, kind, failures)
+	return textPayload(kind, b.String())
+}
+
+func writeFailures(b *strings.Builder, kind string, failures []Failure) {
+	if len(failures) == 0 {
+		return
+	}
+	b.WriteString("\nFailures:")
+	for _, f := range failures {
+		fmt.Fprintf(b, "\n• %s: %s", code(short(f.Commit)), firstLine(f.Error))
+	}
+}
+
+func textPayload(kind, text string) any {
+	if kind == KindDiscord {
+		// Discord rejects content longer than 2000 characters.
+		if len(text) > 2000 {
+			text = text[:1997] + "..."
+		}
+		return map[string]string{"content": text}
+	}
+	return map[string]string{"text": text}
+}
+
+func bold(kind, text string) string {
+	if kind == KindSlack {
+		return "*" + text + "*"
+	}
+	return "**" + text + "**"
+}
+
+func code(text string) string {
+	return "`" + text + "`"
+}
+
+func link(kind, text, url string) string {
+	switch {
+	case url == "":
+		return code(text)
+	case kind == KindSlack:
+		return "<" + url + "|" + text + ">"
+	default:
+		return "[" + text + "](" + url + ")"
+	}
+}
+
+func short(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	if len(line) > 200 {
+		line = line[:197] + "..."
+	}
+	return line
+}
+
+func joinErrors(errs []string) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("notify: %s", strings.Join(errs, "; "))
+}
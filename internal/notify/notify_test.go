@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+func TestRunFinishedFormatsPerKind(t *testing.T) {
+	bodies := map[string]map[string]any{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		bodies[r.URL.Path] = body
+	}))
+	defer server.Close()
+
+	notifier := New(config.NotificationsConfig{Webhooks: []config.Webhook{
+		{Kind: KindSlack, URL: server.URL + "/slack"},
+		{Kind: KindDiscord, URL: server.URL + "/discord"},
+		{Kind: KindWebhook, URL: server.URL + "/hook"},
+		{Kind: KindSlack, URL: server.URL + "/failures-only", OnlyFailures: true},
+	}})
+
+	run := Run{
+		RunID: "run-1", Repo: "demo", Processed: 2, Success: 2,
+		DocCommits: []DocCommit{{CodeCommit: "aaaaaaaaaa", DocCommit: "dddddddddd", URL: "https://example.com/commit/dddddddddd"}},
+	}
+	if err := notifier.RunFinished(context.Background(), run); err != nil {
+		t.Fatalf("run finished: %v", err)
+	}
+
+	slack, _ := bodies["/slack"]["text"].(string)
+	if !strings.Contains(slack, "<https://example.com/commit/dddddddddd|ddddddd> for aaaaaaa") || !strings.Contains(slack, "success 2") {
+		t.Fatalf("unexpected slack text %q", slack)
+	}
+	discord, _ := bodies["/discord"]["content"].(string)
+	if !strings.Contains(discord, "[ddddddd](https://example.com/commit/dddddddddd)") {
+		t.Fatalf("unexpected discord content %q", discord)
+	}
+	if bodies["/hook"]["event"] != "run_finished" || bodies["/hook"]["run"].(map[string]any)["run_id"] != "run-1" {
+		t.Fatalf("unexpected webhook body %v", bodies["/hook"])
+	}
+	if _, ok := bodies["/failures-only"]; ok {
+		t.Fatalf("expected only_failures webhook to skip a clean run")
+	}
+}
+
+func TestPostReportsRejectedWebhook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	notifier := New(config.NotificationsConfig{Webhooks: []config.Webhook{{Kind: KindSlack, URL: server.URL}}})
+	err := notifier.RepeatedFailures(context.Background(), "run-1", "demo", []Failure{{Commit: "abc", Error: "provider down"}})
+	if err == nil || !strings.Contains(err.Error(), "invalid_token") {
+		t.Fatalf("expected rejected webhook error, got %v", err)
+	}
+
+	if New(config.NotificationsConfig{}) != nil {
+		t.Fatalf("expected no notifier without webhooks")
+	}
+}
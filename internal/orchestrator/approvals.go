@@ -0,0 +1,183 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kowshik24/git-doc/internal/config"
+	"github.com/kowshik24/git-doc/internal/doc"
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+// keepPlannedContent stores the section body from updated, and a preview of
+// how it differs from the one in before, on the planned update, so approve,
+// reports and reverts see exactly what was generated and checked.
+func (u *Updater) keepPlannedContent(hash, docFile, section, before, updated string) error {
+	body, err := u.deps.DocUpdater.ExtractSection(updated, section)
+	if err != nil {
+		return err
+	}
+	previous, _ := u.deps.DocUpdater.ExtractSection(before, section)
+	return u.deps.State.PutPlannedContent(hash, docFile, section, body, doc.DiffPreview(previous, body))
+}
+
+// recordPlannedContent is keepPlannedContent for updates that are already
+// settled; failing to store the content never fails them.
+func (u *Updater) recordPlannedContent(runID, hash, docFile, section, before, updated string) {
+	if err := u.keepPlannedContent(hash, docFile, section, before, updated); err != nil {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "state", "failed to store planned update content", map[string]any{"error": err.Error()})
+	}
+}
+
+// PendingApprovals lists the updates queued by a require-approval owners
+// policy or produced by dry runs.
+func (u *Updater) PendingApprovals() ([]state.PlannedUpdate, error) {
+	return u.deps.State.ListApprovals()
+}
+
+// ApproveUpdate writes the stored content of a pending update to its doc and
+// commits it like a regular update. The other commits of the same batch,
+// queued with the same content, are completed with it.
+func (u *Updater) ApproveUpdate(id int64) (string, error) {
+	updates, err := u.approvalGroup(id)
+	if err != nil {
+		return "", err
+	}
+
+	runID := fmt.Sprintf("run-%d", time.Now().UnixNano())
+	if err := u.deps.State.StartRun(runID, "approve", false); err != nil {
+		return "", err
+	}
+	docCommit, err := u.applyApproved(runID, updates)
+	counts := state.RunCounts{Processed: len(updates), Success: len(updates)}
+	status := "finished"
+	if err != nil {
+		counts = state.RunCounts{Processed: len(updates), Failed: len(updates)}
+		status = "failed"
+		_ = u.deps.State.LogRunEvent(runID, updates[0].CommitHash, "error", "orchestrator", "approved update failed", map[string]any{"id": id, "error": err.Error()})
+	}
+	_ = u.deps.State.FinishRun(runID, status, counts)
+	return docCommit, err
+}
+
+// RejectUpdate discards a pending update and the rest of its batch. Their
+// commits keep their status, so they are not generated again.
+func (u *Updater) RejectUpdate(id int64) error {
+	updates, err := u.approvalGroup(id)
+	if err != nil {
+		return err
+	}
+	for _, update := range updates {
+		if err := u.deps.State.SetPlannedUpdateStatus(update.ID, "rejected", "rejected"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// approvalGroup returns the pending update id followed by the other pending
+// updates of the same section with the same status and content.
+func (u *Updater) approvalGroup(id int64) ([]state.PlannedUpdate, error) {
+	update, ok, err := u.deps.State.GetPlannedUpdate(id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("planned update %d not found", id)
+	}
+	if update.Status != state.PlannedAwaitingApproval && update.Status != state.PlannedDryRun {
+		return nil, fmt.Errorf("planned update %d is %s, not pending approval", id, update.Status)
+	}
+	if update.Content == "" {
+		return nil, fmt.Errorf("planned update %d has no stored content", id)
+	}
+
+	pending, err := u.deps.State.ListApprovals()
+	if err != nil {
+		return nil, err
+	}
+	group := []state.PlannedUpdate{update}
+	for _, other := range pending {
+		if other.ID != update.ID && other.DocFile == update.DocFile && other.Section == update.Section && other.Status == update.Status && other.Content == update.Content {
+			group = append(group, other)
+		}
+	}
+	return group, nil
+}
+
+func (u *Updater) applyApproved(runID string, updates []state.PlannedUpdate) (string, error) {
+	update := updates[0]
+	repoRoot, err := u.deps.Git.GetRepoRoot()
+	if err != nil {
+		return "", err
+	}
+	docPath := filepath.Join(repoRoot, filepath.FromSlash(update.DocFile))
+	raw, err := os.ReadFile(docPath)
+	if err != nil {
+		return "", err
+	}
+
+	updated, err := u.replaceSection(string(raw), update.Section, update.Content, u.sectionMapping(update.DocFile, update.Section))
+	if err != nil {
+		return "", err
+	}
+	updated = doc.RegenerateTOC(updated, u.deps.Config.TOC.MaxLevel)
+	updated = doc.NormalizeLineEndings(updated, doc.DetectLineEnding(string(raw)))
+
+	tx := doc.NewTransaction()
+	docCommit := ""
+	if strings.TrimSpace(updated) != strings.TrimSpace(string(raw)) {
+		if err := tx.Write(docPath, []byte(updated), 0o644); err != nil {
+			u.rollbackDocs(runID, update.CommitHash, tx)
+			return "", err
+		}
+		hashes := make([]string, 0, len(updates))
+		for _, approved := range updates {
+			hashes = append(hashes, approved.CommitHash)
+		}
+		if docCommit, err = u.commitDocFiles(runID, []string{update.DocFile}, []string{update.Section}, hashes); err != nil {
+			u.rollbackDocs(runID, update.CommitHash, tx)
+			return "", err
+		}
+	}
+
+	for _, approved := range updates {
+		if err := u.deps.State.CompleteCommit(state.CompletedCommit{
+			CommitHash: approved.CommitHash,
+			DocCommit:  docCommit,
+			DocFiles:   []string{update.DocFile},
+			DocFile:    update.DocFile,
+			Section:    update.Section,
+			Strategy:   approved.Strategy,
+			Reason:     "approved",
+			Mapped:     true,
+		}); err != nil {
+			u.rollbackDocs(runID, approved.CommitHash, tx)
+			return docCommit, err
+		}
+		u.recordPlannedContent(runID, approved.CommitHash, update.DocFile, update.Section, string(raw), updated)
+	}
+	tx.Commit()
+	u.recordGenerated(runID, update.CommitHash, update.DocFile, updated, update.Section)
+	_ = u.deps.State.LogRunEvent(runID, update.CommitHash, "info", "orchestrator", "queued update approved", map[string]any{
+		"doc_file":   update.DocFile,
+		"section":    update.Section,
+		"doc_commit": docCommit,
+		"commits":    len(updates),
+	})
+	return docCommit, nil
+}
+
+// sectionMapping returns the mapping that targets docFile and section, so an
+// approved update lands where the mapping places new sections.
+func (u *Updater) sectionMapping(docFile, section string) config.Mapping {
+	for _, mapping := range u.deps.Config.Mappings {
+		if mapping.DocFile == docFile && strings.EqualFold(mapping.Section, section) {
+			return mapping
+		}
+	}
+	return config.Mapping{}
+}
@@ -0,0 +1,192 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kowshik24/git-doc/internal/commitclass"
+	"github.com/kowshik24/git-doc/internal/doc"
+	"github.com/kowshik24/git-doc/internal/gitutil"
+)
+
+type AuditOptions struct {
+	Since time.Time
+}
+
+type SectionAudit struct {
+	DocFile           string
+	Section           string
+	Line              int
+	LastUpdatedCommit string
+	LastUpdatedAt     time.Time
+	StaleCommits      []string
+	ChangedFiles      []string
+	// Inventoried is set when the section was found by the last scan; Anchor,
+	// Words and LastModifiedCommit come from that scan.
+	Inventoried        bool
+	Anchor             string
+	Words              int
+	LastModifiedCommit string
+}
+
+func (s SectionAudit) Stale() bool {
+	return len(s.StaleCommits) > 0
+}
+
+type AuditReport struct {
+	Head     string
+	Sections []SectionAudit
+}
+
+func (r AuditReport) StaleCount() int {
+	count := 0
+	for _, section := range r.Sections {
+		if section.Stale() {
+			count++
+		}
+	}
+	return count
+}
+
+type auditedCommit struct {
+	target string
+	files  []string
+}
+
+// Audit reports, for every mapped doc section, the commits since its last
+// successful update that route to it but have not been documented yet.
+func (u *Updater) Audit(opts AuditOptions) (AuditReport, error) {
+	head, err := u.deps.Git.GetCurrentHEAD()
+	if err != nil {
+		return AuditReport{}, err
+	}
+
+	completed, err := u.deps.State.GetCompletedCommits()
+	if err != nil {
+		return AuditReport{}, err
+	}
+
+	repoRoot, err := u.deps.Git.GetRepoRoot()
+	if err != nil {
+		return AuditReport{}, err
+	}
+
+	inventory, err := u.deps.State.GetDocInventory()
+	if err != nil {
+		return AuditReport{}, err
+	}
+
+	report := AuditReport{Head: head}
+	ranges := map[string][]gitutil.CommitInfo{}
+	routed := map[string]auditedCommit{}
+	seen := map[string]bool{}
+
+	for _, mapping := range u.deps.Config.Mappings {
+		section := u.inventorySection(mapping.DocFile, mapping.Section)
+		key := sectionKey(mapping.DocFile, section)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		audit := SectionAudit{DocFile: mapping.DocFile, Section: section}
+		for _, entry := range inventory {
+			if entry.DocFile == audit.DocFile && entry.Section == audit.Section {
+				audit.Inventoried = true
+				audit.Anchor = entry.Anchor
+				audit.Words = entry.Words
+				audit.LastModifiedCommit = entry.LastCommit
+				break
+			}
+		}
+		if content, err := os.ReadFile(filepath.Join(repoRoot, mapping.DocFile)); err == nil {
+			audit.Line = doc.SectionLine(string(content), audit.Section)
+		}
+		audit.LastUpdatedCommit, audit.LastUpdatedAt, err = u.deps.State.GetLastSectionUpdate(audit.DocFile, audit.Section)
+		if err != nil {
+			return AuditReport{}, err
+		}
+
+		commits, ok := ranges[audit.LastUpdatedCommit]
+		if !ok {
+			commits, err = u.deps.Git.GetLastProcessedRange(audit.LastUpdatedCommit, head)
+			if err != nil {
+				return AuditReport{}, err
+			}
+			ranges[audit.LastUpdatedCommit] = commits
+		}
+
+		files := map[string]bool{}
+		for _, commit := range commits {
+			if completed[commit.Hash] {
+				continue
+			}
+			if !opts.Since.IsZero() && commit.Timestamp.Before(opts.Since) {
+				continue
+			}
+
+			result, ok := routed[commit.Hash]
+			if !ok {
+				result, err = u.auditCommit(commit.Hash)
+				if err != nil {
+					return AuditReport{}, err
+				}
+				routed[commit.Hash] = result
+			}
+			if result.target != key {
+				continue
+			}
+
+			audit.StaleCommits = append(audit.StaleCommits, commit.Hash)
+			for _, file := range result.files {
+				if !files[file] {
+					files[file] = true
+					audit.ChangedFiles = append(audit.ChangedFiles, file)
+				}
+			}
+		}
+
+		report.Sections = append(report.Sections, audit)
+	}
+
+	return report, nil
+}
+
+// auditCommit resolves which mapped section a commit would update, applying
+// the same merge and ignore rules as processing.
+func (u *Updater) auditCommit(hash string) (auditedCommit, error) {
+	changedFiles, _, skipReason, err := u.commitFiles(hash)
+	if err != nil || skipReason != "" || len(changedFiles) == 0 {
+		return auditedCommit{}, err
+	}
+
+	message, err := u.commitMessage(hash)
+	if err != nil {
+		return auditedCommit{}, err
+	}
+
+	skipReason, err = u.ignoreReason(hash, message, changedFiles)
+	if err != nil || skipReason != "" {
+		return auditedCommit{}, err
+	}
+	changedFiles = u.ignore.relevantFiles(changedFiles)
+
+	mapping, ok := u.matchMapping(changedFiles, commitclass.Parse(message))
+	if !ok {
+		return auditedCommit{}, nil
+	}
+
+	var matched []string
+	for _, file := range changedFiles {
+		if strings.TrimSpace(mapping.CodePattern) == "" || matchCodePattern(mapping.CodePattern, file) {
+			matched = append(matched, file)
+		}
+	}
+	return auditedCommit{target: sectionKey(mapping.DocFile, u.inventorySection(mapping.DocFile, mapping.Section)), files: matched}, nil
+}
+
+func sectionKey(docFile, section string) string {
+	return docFile + "\x00" + section
+}
@@ -0,0 +1,42 @@
+package orchestrator
+
+import "time"
+
+type BackfillOptions struct {
+	Since      time.Time
+	MaxCommits int
+}
+
+// BackfillCommits lists history oldest first, leaving out commits that already
+// finished so an interrupted backfill resumes where it stopped.
+func (u *Updater) BackfillCommits(opts BackfillOptions) ([]string, error) {
+	head, err := u.deps.Git.GetCurrentHEAD()
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := u.deps.Git.GetLastProcessedRange("", head)
+	if err != nil {
+		return nil, err
+	}
+
+	completed, err := u.deps.State.GetCompletedCommits()
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		if !opts.Since.IsZero() && commit.Timestamp.Before(opts.Since) {
+			continue
+		}
+		if completed[commit.Hash] {
+			continue
+		}
+		hashes = append(hashes, commit.Hash)
+		if opts.MaxCommits > 0 && len(hashes) >= opts.MaxCommits {
+			break
+		}
+	}
+	return hashes, nil
+}
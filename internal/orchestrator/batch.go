@@ -0,0 +1,490 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/commitclass"
+	"github.com/kowshik24/git-doc/internal/config"
+	"github.com/kowshik24/git-doc/internal/doc"
+	"github.com/kowshik24/git-doc/internal/llm"
+	"github.com/kowshik24/git-doc/internal/progress"
+	"github.com/kowshik24/git-doc/internal/prompts"
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+type batchCommit struct {
+	hash       string
+	message    string
+	diff       string
+	irrelevant string
+}
+
+type batchGroup struct {
+	docFile string
+	section string
+	mapping config.Mapping
+	commits []batchCommit
+}
+
+func (g *batchGroup) hashes() []string {
+	out := make([]string, 0, len(g.commits))
+	for _, c := range g.commits {
+		out = append(out, c.hash)
+	}
+	return out
+}
+
+func (u *Updater) processBatch(ctx context.Context, runID string, commitHashes []string, dryRun bool) Summary {
+	summary := Summary{}
+
+	groups := make([]*batchGroup, 0)
+	groupIndex := make(map[string]*batchGroup)
+
+	for i, hash := range commitHashes {
+		if ctx.Err() != nil {
+			summary.Interrupted = true
+			summary.Pending += u.leavePending(runID, commitHashes[i:])
+			break
+		}
+
+		summary.Processed++
+		if err := u.deps.State.MarkCommitProcessed(hash, "in_progress", "", "", nil); err != nil {
+			summary.Failed++
+			_ = u.deps.State.LogRunEvent(runID, hash, "error", "state", "failed to mark in progress", map[string]any{"error": err.Error()})
+			continue
+		}
+
+		commit, changedFiles, skipReason, err := u.loadBatchCommit(runID, hash)
+		if err != nil {
+			summary.Failed++
+			u.markFailed(runID, hash, "commit processing failed", err)
+			continue
+		}
+
+		if len(changedFiles) == 0 || skipReason != "" {
+			if skipReason != "" {
+				_ = u.deps.State.LogRunEvent(runID, hash, "info", "orchestrator", "commit skipped by ignore rule", map[string]any{"reason": skipReason})
+			}
+			summary.Skipped++
+			_ = u.deps.State.MarkCommitProcessed(hash, "skipped", "", "", nil)
+			continue
+		}
+
+		class := commitclass.Parse(commit.message)
+		docFile, section := u.resolveTarget(changedFiles, class)
+		if commit.irrelevant == "" && (u.deps.Planner != nil || u.deps.Embedder != nil) {
+			if repoRoot, err := u.deps.Git.GetRepoRoot(); err == nil {
+				docFile, section = u.targetFor(ctx, runID, hash, repoRoot, commit.message, changedFiles, commit.diff, class)
+			}
+		}
+		if commit.irrelevant != "" {
+			_ = u.deps.State.UpsertPlannedUpdate(hash, docFile, section, "batched", "skipped", commit.irrelevant)
+			_ = u.deps.State.LogRunEvent(runID, hash, "info", "orchestrator", "commit skipped as irrelevant to docs", map[string]any{"reason": commit.irrelevant})
+			summary.Skipped++
+			_ = u.deps.State.MarkCommitProcessed(hash, "skipped", "", "", nil)
+			continue
+		}
+		if reason := u.neverReason(docFile); reason != "" {
+			_ = u.deps.State.UpsertPlannedUpdate(hash, docFile, section, "batched", "skipped", reason)
+			_ = u.deps.State.LogRunEvent(runID, hash, "info", "orchestrator", "commit skipped by owners policy", map[string]any{"reason": reason})
+			summary.Skipped++
+			_ = u.deps.State.MarkCommitProcessed(hash, "skipped", "", "", nil)
+			continue
+		}
+		mapping, _ := u.matchMapping(changedFiles, class)
+		if reason := thresholdReason(mapping, changedFiles, commit.diff); reason != "" {
+			_ = u.deps.State.UpsertPlannedUpdate(hash, docFile, section, "batched", "skipped", reason)
+			_ = u.deps.State.LogRunEvent(runID, hash, "info", "orchestrator", "commit skipped below mapping threshold", map[string]any{"reason": reason})
+			summary.Skipped++
+			_ = u.deps.State.MarkCommitProcessed(hash, "skipped", "", "", nil)
+			continue
+		}
+		key := docFile + "\x00" + section
+		group, ok := groupIndex[key]
+		if !ok {
+			group = &batchGroup{docFile: docFile, section: section, mapping: mapping}
+			groupIndex[key] = group
+			groups = append(groups, group)
+		}
+		group.commits = append(group.commits, commit)
+	}
+
+	if len(groups) == 0 {
+		return summary
+	}
+
+	repoRoot, err := u.deps.Git.GetRepoRoot()
+	if err != nil {
+		for _, group := range groups {
+			summary.Failed += u.failBatchGroup(runID, group, err)
+		}
+		return summary
+	}
+
+	docContents := make(map[string]string)
+	originals := make(map[string]string)
+	docOrder := make([]string, 0)
+	applied := make([]*batchGroup, 0, len(groups))
+
+	reported := summary.Skipped + summary.Failed
+	for _, group := range groups {
+		u.llmLatency = 0
+		reported += len(group.commits)
+		if ctx.Err() != nil {
+			summary.Interrupted = true
+			summary.Pending += u.leavePending(runID, group.hashes())
+			continue
+		}
+		if _, ok := docContents[group.docFile]; !ok {
+			raw, readErr := u.readTargetDoc(repoRoot, group.docFile, group.section, group.mapping)
+			if readErr != nil {
+				summary.Failed += u.failBatchGroup(runID, group, readErr)
+				continue
+			}
+			docContents[group.docFile] = string(raw)
+			originals[group.docFile] = string(raw)
+			docOrder = append(docOrder, group.docFile)
+		}
+
+		groupCtx, cancel := u.withCommitTimeout(ctx)
+		updated, genErr := u.generateBatchGroup(groupCtx, runID, repoRoot, group, docContents[group.docFile])
+		genErr = u.timeoutError(groupCtx, ctx, genErr)
+		cancel()
+		status := "generated"
+		if errors.Is(genErr, llm.ErrOffline) {
+			status = "pending"
+		} else if genErr != nil {
+			status = "failed"
+		}
+		u.deps.Progress.Update(progress.Event{
+			Done:       reported,
+			Total:      len(commitHashes),
+			Commit:     group.commits[len(group.commits)-1].hash,
+			Status:     status,
+			LLMLatency: u.llmLatency,
+		})
+		if genErr != nil && ctx.Err() != nil {
+			summary.Interrupted = true
+			summary.Pending += u.leavePending(runID, group.hashes())
+			continue
+		}
+		if errors.Is(genErr, llm.ErrOffline) {
+			for _, hash := range group.hashes() {
+				_ = u.deps.State.UpsertPlannedUpdate(hash, group.docFile, group.section, "batched", "pending", genErr.Error())
+				u.leaveOfflinePending(runID, hash, genErr)
+			}
+			summary.Pending += len(group.commits)
+			continue
+		}
+		if genErr != nil {
+			summary.Failed += u.failBatchGroup(runID, group, genErr)
+			continue
+		}
+
+		hashes := group.hashes()
+		linkFixes, linkErr := u.checkLinks(runID, hashes[len(hashes)-1], repoRoot, group.docFile, docContents[group.docFile], updated, docContents)
+		if linkErr != nil {
+			summary.Failed += u.failBatchGroup(runID, group, linkErr)
+			continue
+		}
+		if _, policy := u.docOwners(group.docFile); policy == "require-approval" && !dryRun {
+			for _, hash := range hashes {
+				if _, err := u.queueForApproval(runID, hash, group.docFile, group.section, "batched", docContents[group.docFile], updated); err != nil {
+					summary.Failed++
+					u.markFailed(runID, hash, "commit processing failed", err)
+					continue
+				}
+				summary.Skipped++
+			}
+			continue
+		}
+		docContents[group.docFile] = updated
+		for _, file := range mapKeys(linkFixes) {
+			if _, ok := originals[file]; !ok {
+				raw, err := os.ReadFile(filepath.Join(repoRoot, filepath.FromSlash(file)))
+				if err != nil {
+					continue
+				}
+				originals[file] = string(raw)
+				docOrder = append(docOrder, file)
+			}
+			docContents[file] = linkFixes[file]
+		}
+		applied = append(applied, group)
+	}
+
+	changedDocs := make([]string, 0, len(docOrder))
+	for _, docFile := range docOrder {
+		if strings.TrimSpace(docContents[docFile]) != strings.TrimSpace(originals[docFile]) {
+			changedDocs = append(changedDocs, docFile)
+		}
+	}
+
+	if len(changedDocs) == 0 {
+		for _, group := range applied {
+			for _, hash := range group.hashes() {
+				_ = u.deps.State.UpsertPlannedUpdate(hash, group.docFile, group.section, "batched", "unchanged", "no document delta")
+				_ = u.deps.State.MarkCommitProcessed(hash, "skipped", "", "", []string{})
+				summary.Skipped++
+			}
+		}
+		return summary
+	}
+
+	docCommitHash := ""
+	translations := map[*batchGroup][]state.TranslatedSection{}
+	if !dryRun {
+		var writeErr error
+		tx := doc.NewTransaction()
+		for _, docFile := range changedDocs {
+			content := doc.NormalizeLineEndings(docContents[docFile], doc.DetectLineEnding(originals[docFile]))
+			if writeErr = tx.Write(filepath.Join(repoRoot, docFile), []byte(content), 0o644); writeErr != nil {
+				break
+			}
+		}
+		if writeErr == nil {
+			for _, group := range applied {
+				if group.mapping.Render != "" {
+					continue
+				}
+				body, err := u.deps.DocUpdater.ExtractSection(docContents[group.docFile], group.section)
+				if err != nil {
+					continue
+				}
+				hashes := group.hashes()
+				translations[group] = u.translateSection(ctx, runID, hashes[len(hashes)-1], repoRoot, group.docFile, group.section, body, tx)
+				for _, file := range translatedFiles(translations[group]) {
+					if !slices.Contains(changedDocs, file) {
+						changedDocs = append(changedDocs, file)
+					}
+				}
+			}
+			var sections []string
+			for _, group := range applied {
+				if !slices.Contains(sections, group.section) {
+					sections = append(sections, group.section)
+				}
+			}
+			docCommitHash, writeErr = u.commitDocFiles(runID, changedDocs, sections, appliedHashes(commitHashes, applied))
+		}
+		if writeErr != nil {
+			u.rollbackDocs(runID, "", tx)
+			for _, group := range applied {
+				summary.Failed += u.failBatchGroup(runID, group, writeErr)
+			}
+			return summary
+		}
+	}
+
+	for _, group := range applied {
+		if !dryRun {
+			hashes := group.hashes()
+			u.recordGenerated(runID, hashes[len(hashes)-1], group.docFile, docContents[group.docFile], group.section)
+		}
+		reason := ""
+		if dryRun {
+			reason = "dry-run"
+		} else if group.mapping.Render == "" {
+			if body, err := u.deps.DocUpdater.ExtractSection(docContents[group.docFile], group.section); err == nil {
+				hashes := group.hashes()
+				messages := make([]string, 0, len(group.commits))
+				for _, c := range group.commits {
+					messages = append(messages, firstLine(c.message))
+				}
+				u.recordExemplar(runID, hashes[len(hashes)-1], group.docFile, group.section, strings.Join(messages, "\n"), nil, body)
+			}
+		}
+		for _, hash := range group.hashes() {
+			if err := u.deps.State.CompleteCommit(state.CompletedCommit{
+				CommitHash:   hash,
+				DocCommit:    docCommitHash,
+				DocFiles:     changedDocs,
+				DocFile:      group.docFile,
+				Section:      group.section,
+				Strategy:     "batched",
+				Reason:       reason,
+				Mapped:       !dryRun,
+				Translations: translations[group],
+			}); err != nil {
+				summary.Failed++
+				continue
+			}
+			if dryRun {
+				_ = u.deps.State.UpsertPlannedUpdate(hash, group.docFile, group.section, "batched", state.PlannedDryRun, reason)
+			}
+			u.recordPlannedContent(runID, hash, group.docFile, group.section, originals[group.docFile], docContents[group.docFile])
+			summary.Success++
+			u.failureStreak = nil
+		}
+	}
+
+	u.applyAmendRewrite(runID)
+
+	_ = u.deps.State.LogRunEvent(runID, "", "info", "orchestrator", "batch applied", map[string]any{
+		"doc_files":  changedDocs,
+		"doc_commit": docCommitHash,
+		"groups":     len(applied),
+	})
+
+	return summary
+}
+
+func (u *Updater) loadBatchCommit(runID, hash string) (batchCommit, []string, string, error) {
+	changedFiles, parents, skipReason, err := u.commitFiles(hash)
+	if err != nil || skipReason != "" {
+		return batchCommit{hash: hash}, nil, skipReason, err
+	}
+	if len(changedFiles) == 0 {
+		return batchCommit{hash: hash}, nil, "", nil
+	}
+
+	message, err := u.commitMessage(hash)
+	if err != nil {
+		return batchCommit{}, nil, "", err
+	}
+
+	skipReason, err = u.ignoreReason(hash, message, changedFiles)
+	if err != nil || skipReason != "" {
+		return batchCommit{hash: hash}, nil, skipReason, err
+	}
+
+	diffContent, err := u.commitDiff(hash, parents)
+	if err != nil {
+		return batchCommit{}, nil, "", err
+	}
+	if changedFiles, diffContent, skipReason = u.expandSubmodules(runID, hash, changedFiles, diffContent); skipReason != "" {
+		return batchCommit{hash: hash}, nil, "", nil
+	}
+	message, diffContent, err = u.redactInputs(runID, hash, message, diffContent)
+	if err != nil {
+		return batchCommit{}, nil, "", err
+	}
+
+	return batchCommit{hash: hash, message: message, diff: diffContent, irrelevant: u.relevanceReason(diffContent)}, u.ignore.relevantFiles(changedFiles), "", nil
+}
+
+func (u *Updater) generateBatchGroup(ctx context.Context, runID, repoRoot string, group *batchGroup, docContent string) (string, error) {
+	hashes := group.hashes()
+	for _, hash := range hashes {
+		_ = u.deps.State.UpsertPlannedUpdate(hash, group.docFile, group.section, "batched", "planned", "")
+	}
+
+	tmpl, err := u.loadPromptTemplate(repoRoot, group.mapping.PromptTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	messages := make([]string, 0, len(group.commits))
+	summaries := make([]string, 0, len(group.commits))
+	diffOpts := u.sharedDiffOptions(len(group.commits))
+	for _, c := range group.commits {
+		messages = append(messages, fmt.Sprintf("- %s %s", shortHash(c.hash), firstLine(c.message)))
+		summaries = append(summaries, fmt.Sprintf("Commit %s:\n%s", shortHash(c.hash), summarizeDiff(c.diff, diffOpts)))
+	}
+
+	lastHash := hashes[len(hashes)-1]
+	if group.mapping.Render == config.RenderMermaidArch {
+		prompt := u.diagramPrompt(group.mapping, group.docFile, group.section, docContent, strings.Join(messages, "\n"), nil, strings.Join(summaries, "\n\n"))
+		return u.applyBatchSection(ctx, runID, lastHash, group, docContent, prompt)
+	}
+
+	existingSection, fullDoc := u.promptDocContext(docContent, group.section)
+	prompt, err := prompts.Render(tmpl, prompts.Data{
+		CommitHash:      lastHash,
+		ShortHash:       batchHashLabel(hashes),
+		CommitMessage:   fmt.Sprintf("%d commits\n%s", len(group.commits), strings.Join(messages, "\n")),
+		DiffSummary:     strings.Join(summaries, "\n\n"),
+		DocFile:         group.docFile,
+		Section:         group.section,
+		ExistingSection: existingSection,
+		FullDoc:         fullDoc,
+		Examples:        u.similarExamples(lastHash, group.docFile, group.section, strings.Join(messages, "\n"), nil),
+		Repo:            prompts.RepoInfo{Name: filepath.Base(repoRoot), Root: repoRoot},
+	})
+	if err != nil {
+		return "", err
+	}
+	return u.applyBatchSection(ctx, runID, lastHash, group, docContent, prompt)
+}
+
+func (u *Updater) applyBatchSection(ctx context.Context, runID, lastHash string, group *batchGroup, docContent, prompt string) (string, error) {
+	newSection, err := u.generateFor(ctx, runID, lastHash, group.docFile, group.section, prompt, group.mapping)
+	if err != nil {
+		return "", err
+	}
+
+	newSection, err = u.finalizeSection(newSection, docContent, group.section, group.mapping)
+	if err != nil {
+		return "", err
+	}
+
+	if err := u.checkPolicy(runID, lastHash, newSection); err != nil {
+		return "", err
+	}
+	if group.mapping.Render == "" {
+		sources := []string{docContent}
+		for _, c := range group.commits {
+			sources = append(sources, c.message, c.diff)
+		}
+		repoRoot, err := u.deps.Git.GetRepoRoot()
+		if err != nil {
+			return "", err
+		}
+		if err := u.factCheck(runID, lastHash, repoRoot, newSection, sources...); err != nil {
+			return "", err
+		}
+	}
+
+	updated, err := u.placeSection(runID, lastHash, group.docFile, docContent, group.section, newSection, group.mapping)
+	if err != nil {
+		return "", err
+	}
+	return doc.RegenerateTOC(updated, u.deps.Config.TOC.MaxLevel), nil
+}
+
+func (u *Updater) failBatchGroup(runID string, group *batchGroup, err error) int {
+	for _, hash := range group.hashes() {
+		_ = u.deps.State.UpsertPlannedUpdate(hash, group.docFile, group.section, "batched", "failed", err.Error())
+		u.markFailed(runID, hash, "commit processing failed", err)
+	}
+	return len(group.commits)
+}
+
+// appliedHashes returns the commits of commitHashes that the applied groups
+// document, in order, leaving out skipped and failed commits.
+func appliedHashes(commitHashes []string, applied []*batchGroup) []string {
+	documented := map[string]bool{}
+	for _, group := range applied {
+		for _, hash := range group.hashes() {
+			documented[hash] = true
+		}
+	}
+	out := make([]string, 0, len(documented))
+	for _, hash := range commitHashes {
+		if documented[hash] {
+			out = append(out, hash)
+		}
+	}
+	return out
+}
+
+func batchHashLabel(hashes []string) string {
+	if len(hashes) == 0 {
+		return ""
+	}
+	if len(hashes) == 1 {
+		return shortHash(hashes[0])
+	}
+	return shortHash(hashes[0]) + ".." + shortHash(hashes[len(hashes)-1])
+}
+
+func firstLine(message string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return strings.TrimSpace(line)
+}
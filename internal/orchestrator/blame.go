@@ -0,0 +1,44 @@
+package orchestrator
+
+import (
+	"fmt"
+
+	"github.com/kowshik24/git-doc/internal/gitutil"
+)
+
+// DocBlameLine is one line of a doc file with the commit that last changed
+// it. CodeCommits are the code commits git-doc documented in that commit;
+// they are empty for lines written by hand.
+type DocBlameLine struct {
+	gitutil.BlameLine
+	Number      int
+	CodeCommits []string
+}
+
+func (l DocBlameLine) Generated() bool {
+	return len(l.CodeCommits) > 0
+}
+
+// Blame attributes every line of docFile to its last commit and marks the
+// lines whose last commit is a doc commit git-doc made, using the doc commit
+// hashes recorded for processed commits.
+func (u *Updater) Blame(docFile string) ([]DocBlameLine, error) {
+	blamer, ok := u.deps.Git.(fileBlamer)
+	if !ok {
+		return nil, fmt.Errorf("git helper cannot blame files")
+	}
+	lines, err := blamer.BlameFile(docFile)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := u.deps.State.DocCommitSources()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]DocBlameLine, 0, len(lines))
+	for i, line := range lines {
+		out = append(out, DocBlameLine{BlameLine: line, Number: i + 1, CodeCommits: sources[line.Commit]})
+	}
+	return out, nil
+}
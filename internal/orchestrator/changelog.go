@@ -0,0 +1,135 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kowshik24/git-doc/internal/commitclass"
+	"github.com/kowshik24/git-doc/internal/doc"
+)
+
+// changelogCategory maps a Conventional Commit to a Keep a Changelog
+// category; ok is false for commits the changelog leaves out.
+func (u *Updater) changelogCategory(class commitclass.Classification) (string, bool) {
+	if !class.Conventional {
+		return "", false
+	}
+	if !class.Breaking {
+		for _, skipped := range u.deps.Config.Changelog.SkipTypes {
+			if strings.EqualFold(strings.TrimSpace(skipped), class.Type) {
+				return "", false
+			}
+		}
+	}
+
+	switch {
+	case class.Type == "security" || class.Scope == "security":
+		return "Security", true
+	case class.Type == "feat":
+		return "Added", true
+	case class.Type == "fix":
+		return "Fixed", true
+	case class.Type == "deprecate" || class.Type == "deprecated":
+		return "Deprecated", true
+	case class.Type == "remove" || class.Type == "removed":
+		return "Removed", true
+	default:
+		return "Changed", true
+	}
+}
+
+func changelogText(hash string, class commitclass.Classification) string {
+	var b strings.Builder
+	if class.Breaking {
+		b.WriteString("**BREAKING:** ")
+	}
+	if class.Scope != "" {
+		fmt.Fprintf(&b, "**%s:** ", class.Scope)
+	}
+	fmt.Fprintf(&b, "%s (%s)", class.Description, shortHash(hash))
+	return b.String()
+}
+
+// changelogEntries returns entries for the run's commits that did not fail
+// and that no ignore rule skips. Failed commits are added when a retry
+// succeeds.
+func (u *Updater) changelogEntries(commitHashes []string) []doc.ChangelogEntry {
+	var entries []doc.ChangelogEntry
+	for _, hash := range commitHashes {
+		row, ok, err := u.deps.State.GetProcessedCommit(hash)
+		if err != nil || !ok || row.Status == "failed" {
+			continue
+		}
+		changedFiles, _, skipReason, err := u.commitFiles(hash)
+		if err != nil || skipReason != "" {
+			continue
+		}
+		message, err := u.commitMessage(hash)
+		if err != nil {
+			continue
+		}
+		if reason, err := u.ignoreReason(hash, message, changedFiles); err != nil || reason != "" {
+			continue
+		}
+
+		class := commitclass.Parse(message)
+		if category, ok := u.changelogCategory(class); ok {
+			entries = append(entries, doc.ChangelogEntry{Category: category, Text: changelogText(hash, class)})
+		}
+	}
+	return entries
+}
+
+// ReleaseChangelog rolls the Unreleased entries of changelog.file into a
+// section for version dated date, and commits the file when
+// git.commit_doc_updates is set. It returns the doc commit hash, if any.
+func (u *Updater) ReleaseChangelog(version string, date time.Time, dryRun bool) (string, error) {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return "", errors.New("release version is required")
+	}
+	repoRoot, err := u.deps.Git.GetRepoRoot()
+	if err != nil {
+		return "", err
+	}
+	file := u.deps.Config.Changelog.File
+	if strings.TrimSpace(file) == "" {
+		file = "CHANGELOG.md"
+	}
+	path := filepath.Join(repoRoot, file)
+
+	original, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("changelog file not found: %s", file)
+		}
+		return "", err
+	}
+	released, err := doc.ReleaseChangelog(string(original), version, date.Format("2006-01-02"))
+	if err != nil {
+		return "", err
+	}
+	if dryRun {
+		return "", nil
+	}
+
+	tx := doc.NewTransaction()
+	if err := tx.Write(path, []byte(doc.NormalizeLineEndings(released, doc.DetectLineEnding(string(original)))), 0o644); err != nil {
+		return "", err
+	}
+	if !u.deps.Config.Git.CommitDocUpdates {
+		tx.Commit()
+		return "", nil
+	}
+	hash, err := u.deps.Git.StageAndCommit([]string{file}, "docs: release "+version)
+	if err != nil {
+		_ = tx.Rollback()
+		return "", err
+	}
+	tx.Commit()
+	return hash, nil
+}
@@ -0,0 +1,70 @@
+package orchestrator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// commitMessageFuncs are the functions git.doc_commit_message templates can
+// call besides the text/template builtins; config.Validate parses with the
+// same names.
+var commitMessageFuncs = template.FuncMap{"join": strings.Join}
+
+// commitMessageData is what git.doc_commit_message renders. Subject, Author
+// and Email describe the last source commit.
+type commitMessageData struct {
+	Hash      string
+	ShortHash string
+	Hashes    []string
+	Subject   string
+	Author    string
+	Email     string
+	DocFiles  []string
+	Sections  []string
+	RunID     string
+	Trailers  string
+}
+
+// docCommitMessage renders git.doc_commit_message for a doc commit made for
+// the source commits hashes. {hash} is still replaced by the full hash of a
+// single commit, or the first..last range of a batch.
+func (u *Updater) docCommitMessage(runID string, docFiles, sections, hashes []string) (string, error) {
+	data := commitMessageData{
+		ShortHash: batchHashLabel(hashes),
+		Hashes:    hashes,
+		DocFiles:  docFiles,
+		Sections:  sections,
+		RunID:     runID,
+	}
+	var trailers []string
+	for _, hash := range hashes {
+		trailers = append(trailers, "Doc-Source-Commit: "+hash)
+	}
+	if len(hashes) > 0 {
+		data.Hash = hashes[len(hashes)-1]
+		if info, err := u.deps.Git.GetCommitInfo(data.Hash); err == nil {
+			data.Subject, data.Author, data.Email = info.Subject, info.Author, info.Email
+		}
+	}
+	if runID != "" {
+		trailers = append(trailers, "Doc-Run-Id: "+runID)
+	}
+	data.Trailers = strings.Join(trailers, "\n")
+
+	legacyHash := data.ShortHash
+	if len(hashes) == 1 {
+		legacyHash = hashes[0]
+	}
+	source := strings.ReplaceAll(u.deps.Config.Git.DocCommitMessage, "{hash}", legacyHash)
+	tmpl, err := template.New("commit").Funcs(commitMessageFuncs).Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("parse git.doc_commit_message: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render git.doc_commit_message: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,158 @@
+package orchestrator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DirCoverage counts the source files of a code directory and how many of
+// them a mapping's code_pattern matches.
+type DirCoverage struct {
+	Dir    string
+	Files  int
+	Mapped int
+}
+
+func (d DirCoverage) Unmapped() bool {
+	return d.Mapped == 0
+}
+
+// SectionCoverage is a doc section from the doc inventory with the last code
+// commit git-doc documented in it. Mapped is set when a mapping or the
+// default target routes updates to the section.
+type SectionCoverage struct {
+	DocFile           string
+	Section           string
+	Path              string
+	Mapped            bool
+	LastUpdatedCommit string
+	LastUpdatedAt     time.Time
+}
+
+func (s SectionCoverage) NeverUpdated() bool {
+	return s.LastUpdatedCommit == ""
+}
+
+type CoverageReport struct {
+	Dirs     []DirCoverage
+	Sections []SectionCoverage
+}
+
+// MappedFiles returns the mapped and total source file counts.
+func (r CoverageReport) MappedFiles() (int, int) {
+	mapped, total := 0, 0
+	for _, dir := range r.Dirs {
+		mapped += dir.Mapped
+		total += dir.Files
+	}
+	return mapped, total
+}
+
+func (r CoverageReport) UnmappedDirs() []DirCoverage {
+	var out []DirCoverage
+	for _, dir := range r.Dirs {
+		if dir.Unmapped() {
+			out = append(out, dir)
+		}
+	}
+	return out
+}
+
+func (r CoverageReport) NeverUpdated() []SectionCoverage {
+	var out []SectionCoverage
+	for _, section := range r.Sections {
+		if section.NeverUpdated() {
+			out = append(out, section)
+		}
+	}
+	return out
+}
+
+// Coverage groups the tracked source files outside doc_files and
+// ignore.paths by directory, as suggest-mappings does, counting the files
+// mappings cover, and lists every doc_files section with the last update
+// git-doc made to it.
+func (u *Updater) Coverage() (CoverageReport, error) {
+	lister, ok := u.deps.Git.(trackedFileLister)
+	if !ok {
+		return CoverageReport{}, fmt.Errorf("git helper cannot list tracked files")
+	}
+	files, err := lister.ListTrackedFiles()
+	if err != nil {
+		return CoverageReport{}, err
+	}
+	rules, err := u.ignoreRules()
+	if err != nil {
+		return CoverageReport{}, err
+	}
+
+	var report CoverageReport
+	dirs := map[string]*DirCoverage{}
+	for _, file := range rules.relevantFiles(files) {
+		dir, ok := codeDir(file)
+		if !ok || u.isDocFile(file) {
+			continue
+		}
+		if dirs[dir] == nil {
+			dirs[dir] = &DirCoverage{Dir: dir}
+		}
+		dirs[dir].Files++
+		if u.mappedPath(file) {
+			dirs[dir].Mapped++
+		}
+	}
+	for _, dir := range dirs {
+		report.Dirs = append(report.Dirs, *dir)
+	}
+	sort.Slice(report.Dirs, func(i, j int) bool { return report.Dirs[i].Dir < report.Dirs[j].Dir })
+
+	repoRoot, err := u.deps.Git.GetRepoRoot()
+	if err != nil {
+		return CoverageReport{}, err
+	}
+	inventory, err := u.docInventory(repoRoot)
+	if err != nil {
+		return CoverageReport{}, err
+	}
+	for _, entry := range inventory {
+		for _, section := range entry.Sections {
+			c := SectionCoverage{
+				DocFile: entry.File,
+				Section: section.Title,
+				Path:    strings.Join(section.Path, sectionPathSeparator),
+				Mapped:  u.routedSection(entry.File, section.Title),
+			}
+			c.LastUpdatedCommit, c.LastUpdatedAt, err = u.deps.State.GetLastSectionUpdate(c.DocFile, c.Section)
+			if err != nil {
+				return CoverageReport{}, err
+			}
+			report.Sections = append(report.Sections, c)
+		}
+	}
+	return report, nil
+}
+
+func (u *Updater) isDocFile(file string) bool {
+	for _, pattern := range u.deps.Config.DocFiles {
+		if matchCodePattern(pattern, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// routedSection reports whether a mapping or the default target writes to
+// section of docFile.
+func (u *Updater) routedSection(docFile, section string) bool {
+	if docFile == u.defaultTarget() && strings.EqualFold(section, u.deps.Config.Runtime.DefaultSection) {
+		return true
+	}
+	for _, mapping := range u.deps.Config.Mappings {
+		if mapping.DocFile == docFile && strings.EqualFold(u.inventorySection(docFile, mapping.Section), section) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package orchestrator
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/config"
+	"github.com/kowshik24/git-doc/internal/doc"
+)
+
+const maxDiagramModules = 80
+
+// diagramPrompt asks for an updated mermaid diagram of the modules a
+// render = "mermaid-arch" mapping covers, instead of a prose section.
+func (u *Updater) diagramPrompt(mapping config.Mapping, docFile, section, docContent, commits string, changedFiles []string, diffSummary string) string {
+	current := ""
+	if body, err := u.deps.DocUpdater.ExtractSection(docContent, section); err == nil {
+		current = doc.MermaidBlock(body)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "You maintain the architecture diagram in section %q of %s.\n", section, docFile)
+	b.WriteString("Update the Mermaid diagram so it shows the modules below and how they depend on each other after these changes.\n\n")
+	fmt.Fprintf(&b, "Commits:\n%s\n\n", strings.TrimSpace(commits))
+	if len(changedFiles) > 0 {
+		fmt.Fprintf(&b, "Changed files:\n- %s\n\n", strings.Join(changedFiles, "\n- "))
+	}
+	if modules := u.diagramModules(mapping, changedFiles); len(modules) > 0 {
+		fmt.Fprintf(&b, "Modules:\n- %s\n\n", strings.Join(modules, "\n- "))
+	}
+	fmt.Fprintf(&b, "Changes:\n%s\n\n", strings.TrimSpace(diffSummary))
+	if current != "" {
+		fmt.Fprintf(&b, "Current diagram:\n```mermaid\n%s\n```\n\n", current)
+	} else {
+		b.WriteString("There is no diagram yet; create one.\n\n")
+	}
+	b.WriteString("Return only the complete diagram in a single ```mermaid code block. Use flowchart syntax, keep existing node ids, and do not add prose.\n")
+	return b.String()
+}
+
+// diagramModules lists the directories holding files the mapping covers,
+// from the tracked files when the git helper can list them and from the
+// changed files otherwise.
+func (u *Updater) diagramModules(mapping config.Mapping, changedFiles []string) []string {
+	files := changedFiles
+	if lister, ok := u.deps.Git.(trackedFileLister); ok {
+		if tracked, err := lister.ListTrackedFiles(); err == nil {
+			files = tracked
+		}
+	}
+
+	seen := map[string]bool{}
+	var modules []string
+	for _, file := range files {
+		if strings.TrimSpace(mapping.CodePattern) != "" && !matchCodePattern(mapping.CodePattern, file) {
+			continue
+		}
+		dir := path.Dir(file)
+		if dir == "." || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		modules = append(modules, dir)
+	}
+	sort.Strings(modules)
+	if len(modules) > maxDiagramModules {
+		modules = modules[:maxDiagramModules]
+	}
+	return modules
+}
+
+// finalizeSection turns raw LLM output into the section body to write. For
+// diagram mappings the mermaid block is validated and swapped into the
+// existing section so surrounding prose is kept.
+func (u *Updater) finalizeSection(raw, docContent, section string, mapping config.Mapping) (string, error) {
+	if mapping.Render != config.RenderMermaidArch {
+		return sanitizeGeneratedSection(raw, section, newSectionLevel(docContent, section, mapping))
+	}
+
+	diagram := doc.ExtractMermaid(raw)
+	if err := doc.ValidateMermaid(diagram); err != nil {
+		return "", fmt.Errorf("generated mermaid diagram is invalid: %w", err)
+	}
+	body, err := u.deps.DocUpdater.ExtractSection(docContent, section)
+	if err != nil {
+		body = ""
+	}
+	return doc.ReplaceMermaidBlock(body, diagram), nil
+}
@@ -0,0 +1,127 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kowshik24/git-doc/internal/notify"
+)
+
+// DigestWindow returns the start of the next digest period and whether a
+// full period has passed since the last digest. Before the first digest the
+// window is the last period and it is due.
+func (u *Updater) DigestWindow(now time.Time) (time.Time, bool, error) {
+	period := 24 * time.Hour
+	if u.deps.Config.Notifications.Email.Schedule == "weekly" {
+		period = 7 * 24 * time.Hour
+	}
+
+	last, ok, err := u.deps.State.LastDigest()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if !ok {
+		return now.Add(-period), true, nil
+	}
+	return last, now.Sub(last) >= period, nil
+}
+
+// BuildDigest groups the sections updated in [since, until) by doc commit
+// and attaches each doc commit's diff.
+func (u *Updater) BuildDigest(since, until time.Time) (notify.Digest, error) {
+	digest := notify.Digest{Repo: u.repoName(), Since: since, Until: until}
+	updates, err := u.deps.State.ListSectionUpdates(since, until)
+	if err != nil {
+		return digest, err
+	}
+
+	index := map[string]int{}
+	for _, update := range updates {
+		// Uncommitted updates have no doc commit to share, so each stays
+		// its own entry.
+		key := update.DocCommit
+		if key == "" {
+			key = "code:" + update.CommitHash
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(digest.Entries)
+			index[key] = i
+			entry := notify.DigestEntry{DocCommit: update.DocCommit}
+			if update.DocCommit != "" {
+				entry.URL = u.commitURL(update.DocCommit)
+				entry.Diff = u.digestDiff(update.DocCommit)
+			}
+			digest.Entries = append(digest.Entries, entry)
+		}
+		entry := &digest.Entries[i]
+		section := update.DocFile + "#" + update.Section
+		if !slices.Contains(entry.Sections, section) {
+			entry.Sections = append(entry.Sections, section)
+		}
+		if !slices.Contains(entry.CodeCommits, update.CommitHash) {
+			entry.CodeCommits = append(entry.CodeCommits, update.CommitHash)
+		}
+	}
+	return digest, nil
+}
+
+// digestDiff returns the diff part of a doc commit, cut to
+// notifications.email.max_diff_lines lines.
+func (u *Updater) digestDiff(docCommit string) string {
+	out, err := u.deps.Git.GetCommitDiff(docCommit)
+	if err != nil {
+		return "(diff unavailable: " + err.Error() + ")"
+	}
+	if i := strings.Index(out, "diff --git"); i >= 0 {
+		out = out[i:]
+	}
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if limit := u.deps.Config.Notifications.Email.MaxDiffLines; limit > 0 && len(lines) > limit {
+		lines = append(lines[:limit], fmt.Sprintf("... %d more lines", len(lines)-limit))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SendDigest emails the digest for the period since the last one, unless it
+// is not due yet and force is unset. A period without updates sends nothing
+// but still counts as a digest, so the next one starts after it.
+func (u *Updater) SendDigest(now time.Time, force bool) (notify.Digest, bool, error) {
+	if u.deps.Mailer == nil {
+		return notify.Digest{}, false, errors.New("notifications.email is not enabled")
+	}
+	since, due, err := u.DigestWindow(now)
+	if err != nil || (!due && !force) {
+		return notify.Digest{}, false, err
+	}
+
+	digest, err := u.BuildDigest(since, now)
+	if err != nil {
+		return digest, false, err
+	}
+	if digest.Sections() > 0 {
+		if err := u.deps.Mailer.Send(digest.Render()); err != nil {
+			return digest, false, err
+		}
+	}
+	return digest, true, u.deps.State.RecordDigest(since, now, digest.Sections())
+}
+
+// sendDigestIfDue sends the scheduled digest after a run. Failures are logged
+// and retried after the next run.
+func (u *Updater) sendDigestIfDue(runID string) {
+	if u.deps.Mailer == nil {
+		return
+	}
+	digest, sent, err := u.SendDigest(time.Now(), false)
+	if err != nil {
+		_ = u.deps.State.LogRunEvent(runID, "", "warn", "notify", "digest email failed", map[string]any{"error": err.Error()})
+		return
+	}
+	if sent {
+		_ = u.deps.State.LogRunEvent(runID, "", "info", "notify", "digest email sent", map[string]any{"sections": digest.Sections()})
+	}
+}
@@ -0,0 +1,135 @@
+package orchestrator
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/doc"
+)
+
+// expandDocFiles resolves doc_files against the repository in config order.
+// Literal paths are kept even when missing; globs expand to the matching
+// files, sorted, walking only below the glob's literal directory prefix.
+func (u *Updater) expandDocFiles(repoRoot string) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	add := func(rel string) {
+		if !seen[rel] {
+			seen[rel] = true
+			out = append(out, rel)
+		}
+	}
+
+	for _, pattern := range u.deps.Config.DocFiles {
+		pattern = filepath.ToSlash(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if !strings.ContainsAny(pattern, "*?[") {
+			add(path.Clean(pattern))
+			continue
+		}
+
+		root := globRoot(pattern)
+		var matches []string
+		err := filepath.WalkDir(filepath.Join(repoRoot, filepath.FromSlash(root)), func(p string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if entry.IsDir() {
+				switch entry.Name() {
+				case ".git", ".git-doc", "node_modules", "vendor":
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			rel, err := filepath.Rel(repoRoot, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if matchCodePattern(pattern, rel) {
+				matches = append(matches, rel)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			add(match)
+		}
+	}
+	return out, nil
+}
+
+// globRoot returns the directories of a glob before its first wildcard
+// segment, or "." when the glob starts with one.
+func globRoot(pattern string) string {
+	parts := strings.Split(pattern, "/")
+	var literal []string
+	for _, part := range parts[:len(parts)-1] {
+		if strings.ContainsAny(part, "*?[") {
+			break
+		}
+		literal = append(literal, part)
+	}
+	if len(literal) == 0 {
+		return "."
+	}
+	return strings.Join(literal, "/")
+}
+
+// defaultTarget picks the doc for commits no mapping routes: the first
+// doc_files match that already has the default section, else the first
+// existing match, else the first literal doc file or README.md.
+func (u *Updater) defaultTarget() string {
+	section := u.deps.Config.Runtime.DefaultSection
+	fallback := "README.md"
+	for _, pattern := range u.deps.Config.DocFiles {
+		if p := strings.TrimSpace(pattern); p != "" && !strings.ContainsAny(p, "*?[") {
+			fallback = p
+			break
+		}
+	}
+
+	if u.deps.Git == nil {
+		return fallback
+	}
+	repoRoot, err := u.deps.Git.GetRepoRoot()
+	if err != nil {
+		return fallback
+	}
+	candidates, err := u.expandDocFiles(repoRoot)
+	if err != nil {
+		return fallback
+	}
+
+	first := ""
+	for _, candidate := range candidates {
+		raw, err := os.ReadFile(filepath.Join(repoRoot, filepath.FromSlash(candidate)))
+		if err != nil {
+			continue
+		}
+		if first == "" {
+			first = candidate
+		}
+		for _, heading := range doc.Headings(string(raw)) {
+			if strings.EqualFold(heading.Title, section) {
+				return candidate
+			}
+		}
+	}
+	if first != "" {
+		return first
+	}
+	return fallback
+}
@@ -0,0 +1,170 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+// generateFor generates a mapping's section, with the mapping's own provider
+// when it sets [mappings.llm] or through the ensemble when it sets
+// ensemble = true, and reviews prose sections.
+func (u *Updater) generateFor(ctx context.Context, runID, hash, docFile, section, prompt string, mapping config.Mapping) (string, error) {
+	if mapping.LLM != (config.ProviderConfig{}) {
+		writer, err := u.mappingWriter(mapping)
+		if err != nil {
+			return "", err
+		}
+		mapping.LLM = config.ProviderConfig{}
+		generated, err := writer.generateFor(ctx, runID, hash, docFile, section, prompt, mapping)
+		u.llmLatency = writer.llmLatency
+		return generated, err
+	}
+
+	var generated string
+	var err error
+	if mapping.Ensemble && len(u.deps.Config.LLM.Ensemble.Candidates) > 0 {
+		generated, err = u.generateEnsemble(ctx, runID, hash, docFile, section, prompt)
+	} else {
+		generated, err = u.generateSection(ctx, runID, hash, docFile, section, prompt)
+	}
+	if err != nil || mapping.Render != "" {
+		return generated, err
+	}
+	return u.reviewSection(ctx, runID, hash, docFile, section, prompt, generated)
+}
+
+// generateEnsemble asks every candidate provider for the section and lets
+// the judge pick or merge the best one. Failed candidates are dropped; with
+// a single answer, or identical ones, the judge is not called.
+func (u *Updater) generateEnsemble(ctx context.Context, runID, hash, docFile, section, prompt string) (string, error) {
+	members, judge, err := u.ensembleMembers()
+	if err != nil {
+		return "", err
+	}
+
+	var candidates []string
+	var firstErr error
+	for _, member := range members {
+		text, err := member.generateSection(ctx, runID, hash, docFile, section, prompt)
+		if err != nil {
+			_ = u.deps.State.LogRunEvent(runID, hash, "warn", "llm", "ensemble candidate failed", map[string]any{
+				"provider": member.deps.LLM.Name(),
+				"model":    member.deps.Config.LLM.Model,
+				"error":    err.Error(),
+			})
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		candidates = append(candidates, text)
+	}
+	if len(candidates) == 0 {
+		return "", firstErr
+	}
+	if allEqual(candidates) {
+		return candidates[0], nil
+	}
+
+	chosen, err := judge.generate(ctx, runID, hash, "judge", docFile, section, judgePrompt(docFile, section, prompt, candidates))
+	if err != nil {
+		return "", fmt.Errorf("ensemble judge: %w", err)
+	}
+	_ = u.deps.State.LogRunEvent(runID, hash, "info", "llm", "ensemble judged", map[string]any{
+		"candidates": len(candidates),
+		"judge":      judge.deps.LLM.Name(),
+		"model":      judge.deps.Config.LLM.Model,
+	})
+	return chosen, nil
+}
+
+// ensembleMembers builds an updater per candidate provider, and one for the
+// judge unless it is the primary provider, on first use.
+func (u *Updater) ensembleMembers() ([]*Updater, *Updater, error) {
+	if u.ensemble != nil {
+		return u.ensemble, u.judge, nil
+	}
+	if u.deps.NewLLM == nil {
+		return nil, nil, errors.New("ensemble mappings need per-provider LLM clients")
+	}
+
+	cfg := u.deps.Config
+	members := make([]*Updater, 0, len(cfg.LLM.Ensemble.Candidates))
+	for _, candidate := range cfg.LLM.ResolvedCandidates() {
+		member, err := u.withProvider(cfg.WithProvider(candidate))
+		if err != nil {
+			return nil, nil, err
+		}
+		members = append(members, member)
+	}
+
+	judge := u
+	if cfg.LLM.Ensemble.Judge != (config.ProviderConfig{}) {
+		var err error
+		if judge, err = u.withProvider(cfg.WithProvider(cfg.LLM.ResolvedJudge())); err != nil {
+			return nil, nil, err
+		}
+	}
+	u.ensemble, u.judge = members, judge
+	return members, judge, nil
+}
+
+// mappingWriter returns the updater generating with a mapping's [mappings.llm]
+// provider, built on first use and shared by mappings resolving to the same
+// settings.
+func (u *Updater) mappingWriter(mapping config.Mapping) (*Updater, error) {
+	provider := u.deps.Config.LLM.ResolvedMapping(mapping)
+	if writer, ok := u.writers[provider]; ok {
+		return writer, nil
+	}
+	if u.deps.NewLLM == nil {
+		return nil, errors.New("mappings with an llm override need per-provider LLM clients")
+	}
+	writer, err := u.withProvider(u.deps.Config.WithProvider(provider))
+	if err != nil {
+		return nil, err
+	}
+	if u.writers == nil {
+		u.writers = map[config.ProviderConfig]*Updater{}
+	}
+	u.writers[provider] = writer
+	return writer, nil
+}
+
+// withProvider returns an updater sharing u's state and git but generating
+// with the provider cfg describes.
+func (u *Updater) withProvider(cfg *config.Config) (*Updater, error) {
+	client, err := u.deps.NewLLM(cfg)
+	if err != nil {
+		return nil, err
+	}
+	deps := u.deps
+	deps.Config = cfg
+	deps.LLM = client
+	return &Updater{deps: deps, trigger: u.trigger, metadata: u.metadata}, nil
+}
+
+func judgePrompt(docFile, section, task string, candidates []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Several writers drafted the section %q of %s for the same task. Pick the best draft, or merge the best parts of several into one.\n", section, docFile)
+	b.WriteString("Prefer drafts that are accurate to the task's commit and diff, complete, concise and consistent with the existing document.\n\n")
+	fmt.Fprintf(&b, "Task given to the writers:\n%s\n\n", strings.TrimSpace(task))
+	for i, candidate := range candidates {
+		fmt.Fprintf(&b, "Draft %c:\n%s\n\n", 'A'+i, strings.TrimSpace(candidate))
+	}
+	b.WriteString("Return only the final section body, without the heading and without commenting on the drafts.\n")
+	return b.String()
+}
+
+func allEqual(texts []string) bool {
+	for _, text := range texts[1:] {
+		if strings.TrimSpace(text) != strings.TrimSpace(texts[0]) {
+			return false
+		}
+	}
+	return true
+}
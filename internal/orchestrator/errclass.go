@@ -0,0 +1,47 @@
+package orchestrator
+
+import (
+	"errors"
+
+	"github.com/kowshik24/git-doc/internal/doc"
+	"github.com/kowshik24/git-doc/internal/llm"
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+// permanentError marks a failure that retrying cannot fix until the repo or
+// config changes, such as a missing doc file.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+func permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// errorClass sorts a commit failure into state.ErrorClassPermanent (missing
+// doc files or sections, rejected credentials, unknown models) or
+// state.ErrorClassTransient (rate limits, server errors, timeouts and
+// anything unrecognised), which retry picks up again.
+func errorClass(err error) string {
+	var perm permanentError
+	if errors.As(err, &perm) || errors.Is(err, doc.ErrSectionNotFound) || llm.IsPermanent(err) {
+		return state.ErrorClassPermanent
+	}
+	return state.ErrorClassTransient
+}
+
+// markFailed records a failed commit with its error class and logs why.
+func (u *Updater) markFailed(runID, hash, message string, err error) {
+	class := errorClass(err)
+	_ = u.deps.State.MarkCommitFailed(hash, err.Error(), class)
+	_ = u.deps.State.LogRunEvent(runID, hash, "error", "orchestrator", message, map[string]any{"error": err.Error(), "error_class": class})
+	u.noteFailure(runID, hash, err)
+}
@@ -0,0 +1,135 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/config"
+	"github.com/kowshik24/git-doc/internal/llm"
+)
+
+const estimatedOutputTokens = 400
+
+type Estimate struct {
+	Commits int
+	Prompts int
+	// Reviews counts the llm.review_passes calls included in the totals.
+	Reviews      int
+	Skipped      int
+	Failed       int
+	InputTokens  int
+	OutputTokens int
+	Provider     string
+	Model        string
+	Cost         float64
+	PriceKnown   bool
+	Providers    []ProviderEstimate
+}
+
+// ProviderEstimate projects the usage of one provider. Mapping is set for
+// providers that only [mappings.llm] overrides use; the prompts of those
+// mappings are counted there instead of in the failover chain.
+type ProviderEstimate struct {
+	Provider     string
+	Model        string
+	Mapping      bool
+	InputTokens  int
+	OutputTokens int
+	Cost         float64
+	PriceKnown   bool
+}
+
+// Estimate renders the prompt for every commit without calling the LLM and
+// projects token usage and cost for each provider in the failover chain. The
+// top-level totals describe the primary provider.
+func (u *Updater) Estimate(ctx context.Context, commitHashes []string) (Estimate, error) {
+	chain := u.deps.Config.LLM.ResolvedProviders()
+	if !u.deps.Config.LLM.FailoverEnabled {
+		chain = chain[:1]
+	}
+
+	estimate := Estimate{Commits: len(commitHashes)}
+	estimate.Providers = make([]ProviderEstimate, len(chain))
+	for i, provider := range chain {
+		estimate.Providers[i] = ProviderEstimate{Provider: provider.Provider, Model: provider.Model}
+	}
+
+	outputTokens := estimatedOutputTokens
+	if maxTokens := u.deps.Config.LLM.MaxTokens; maxTokens > 0 && maxTokens < outputTokens {
+		outputTokens = maxTokens
+	}
+
+	for _, hash := range commitHashes {
+		if err := ctx.Err(); err != nil {
+			return estimate, err
+		}
+
+		prepared, err := u.prepareCommit(ctx, "", hash, false)
+		if err != nil {
+			estimate.Failed++
+			continue
+		}
+		if prepared.skipReason != "" {
+			estimate.Skipped++
+			continue
+		}
+
+		prompt, err := u.withStyleGuide(prepared.prompt)
+		if err != nil {
+			return estimate, err
+		}
+		if u.deps.Config.LLM.StructuredOutput {
+			prompt = strings.TrimRight(prompt, "\n") + "\n\n" + llm.StructuredOutputInstructions
+		}
+		if system := u.deps.Config.LLM.SystemPrompt; system != "" {
+			prompt = system + "\n" + prompt
+		}
+
+		// A review pass resends the prompt with the draft section.
+		reviews := u.deps.Config.LLM.ReviewPasses
+		if prepared.mapping.Render != "" {
+			reviews = 0
+		}
+		estimate.Prompts++
+		estimate.Reviews += reviews
+		targets := estimate.Providers[:len(chain)]
+		if prepared.mapping.LLM != (config.ProviderConfig{}) {
+			targets = estimate.mappingProvider(u.deps.Config.LLM.ResolvedMapping(prepared.mapping))
+		}
+		for i := range targets {
+			provider := &targets[i]
+			provider.InputTokens += (1+reviews)*llm.EstimateTokensFor(provider.Provider, prompt) + reviews*outputTokens
+			provider.OutputTokens += (1 + reviews) * outputTokens
+		}
+	}
+
+	for i := range estimate.Providers {
+		provider := &estimate.Providers[i]
+		price, ok := llm.LookupPrice(provider.Provider, provider.Model)
+		provider.PriceKnown = ok
+		if ok {
+			provider.Cost = price.Cost(provider.InputTokens, provider.OutputTokens)
+		}
+	}
+
+	primary := estimate.Providers[0]
+	estimate.Provider = primary.Provider
+	estimate.Model = primary.Model
+	estimate.InputTokens = primary.InputTokens
+	estimate.OutputTokens = primary.OutputTokens
+	estimate.Cost = primary.Cost
+	estimate.PriceKnown = primary.PriceKnown
+	return estimate, nil
+}
+
+// mappingProvider returns the entry of a [mappings.llm] provider as a
+// one-element slice of Providers, adding it on first use.
+func (e *Estimate) mappingProvider(p config.ProviderConfig) []ProviderEstimate {
+	for i, provider := range e.Providers {
+		if provider.Mapping && provider.Provider == p.Provider && provider.Model == p.Model {
+			return e.Providers[i : i+1]
+		}
+	}
+	e.Providers = append(e.Providers, ProviderEstimate{Provider: p.Provider, Model: p.Model, Mapping: true})
+	return e.Providers[len(e.Providers)-1:]
+}
@@ -0,0 +1,113 @@
+package orchestrator
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	diffanalyzer "github.com/kowshik24/git-doc/internal/diff"
+	"github.com/kowshik24/git-doc/internal/prompts"
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+const exemplarCandidates = 500
+
+// similarExamples returns up to prompts.examples accepted updates ranked by
+// word overlap of their commit message and changed paths with this commit,
+// preferring updates to the same section.
+func (u *Updater) similarExamples(hash, docFile, section, message string, files []string) []prompts.Example {
+	limit := u.deps.Config.Prompts.Examples
+	if limit <= 0 {
+		return nil
+	}
+	exemplars, err := u.deps.State.GetExemplars(exemplarCandidates)
+	if err != nil {
+		return nil
+	}
+
+	query := exemplarTerms(message, files)
+	type scored struct {
+		exemplar state.Exemplar
+		score    float64
+	}
+	var ranked []scored
+	for _, e := range exemplars {
+		if e.CommitHash == hash {
+			continue
+		}
+		score := termOverlap(query, exemplarTerms(e.Message, e.Files))
+		if score == 0 {
+			continue
+		}
+		if e.DocFile == docFile && e.Section == section {
+			score += 0.5
+		}
+		ranked = append(ranked, scored{exemplar: e, score: score})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	out := make([]prompts.Example, 0, len(ranked))
+	for _, r := range ranked {
+		out = append(out, prompts.Example{
+			ShortHash:     shortHash(r.exemplar.CommitHash),
+			CommitMessage: firstLine(r.exemplar.Message),
+			DocFile:       r.exemplar.DocFile,
+			Section:       r.exemplar.Section,
+			Content:       diffanalyzer.TruncateText(r.exemplar.Content, u.deps.Config.Prompts.ExampleMaxChars),
+		})
+	}
+	return out
+}
+
+// recordExemplar keeps an applied section update for later prompts. Failing
+// to store it never fails the commit.
+func (u *Updater) recordExemplar(runID, hash, docFile, section, message string, files []string, content string) {
+	if strings.TrimSpace(content) == "" {
+		return
+	}
+	if err := u.deps.State.PutExemplar(state.Exemplar{
+		CommitHash: hash,
+		DocFile:    docFile,
+		Section:    section,
+		Message:    message,
+		Files:      files,
+		Content:    strings.TrimSpace(content),
+	}); err != nil {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "state", "failed to store exemplar", map[string]any{"error": err.Error()})
+	}
+}
+
+func exemplarTerms(message string, files []string) map[string]bool {
+	terms := map[string]bool{}
+	add := func(text string) {
+		for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if len(word) > 2 {
+				terms[word] = true
+			}
+		}
+	}
+	add(message)
+	for _, file := range files {
+		add(file)
+	}
+	return terms
+}
+
+// termOverlap is the Jaccard index of two term sets.
+func termOverlap(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for term := range a {
+		if b[term] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
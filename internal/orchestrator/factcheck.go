@@ -0,0 +1,85 @@
+package orchestrator
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/policy"
+)
+
+const factCheckMaxFileBytes = 1 << 20
+
+// factCheck looks up the identifiers, flags and paths a generated section
+// mentions in sources (the commit message, diff and doc) and then in the
+// repository, and reports the ones it cannot find according to
+// policy.fact_check.
+func (u *Updater) factCheck(runID, hash, repoRoot, content string, sources ...string) error {
+	mode := u.deps.Config.Policy.FactCheck
+	if mode == "" || mode == "off" {
+		return nil
+	}
+
+	unsupported := policy.Unsupported(policy.ExtractClaims(content), sources...)
+	unsupported = unsupportedInRepo(repoRoot, unsupported)
+	if len(unsupported) == 0 {
+		return nil
+	}
+
+	claims := make([]string, 0, len(unsupported))
+	for _, claim := range unsupported {
+		claims = append(claims, fmt.Sprintf("%s %q", claim.Kind, claim.Text))
+	}
+	if mode == "fail" {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "policy", "generated content failed fact check", map[string]any{"claims": claims})
+		return fmt.Errorf("fact check: %s not found in the commit, doc or repository", strings.Join(claims, ", "))
+	}
+	_ = u.deps.State.LogRunEvent(runID, hash, "warn", "policy", "unverified claims in generated section", map[string]any{"claims": claims})
+	return nil
+}
+
+// unsupportedInRepo drops the claims that name an existing path or appear in
+// a repository file, walking the tree only while some remain.
+func unsupportedInRepo(repoRoot string, claims []policy.Claim) []policy.Claim {
+	var remaining []policy.Claim
+	for _, claim := range claims {
+		if claim.Kind == "path" {
+			if _, err := os.Stat(filepath.Join(repoRoot, filepath.FromSlash(claim.Text))); err == nil {
+				continue
+			}
+		}
+		remaining = append(remaining, claim)
+	}
+	if len(remaining) == 0 || repoRoot == "" {
+		return remaining
+	}
+
+	_ = filepath.WalkDir(repoRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", ".git-doc", "node_modules", "vendor":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info, err := d.Info(); err != nil || !info.Mode().IsRegular() || info.Size() > factCheckMaxFileBytes {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || bytes.IndexByte(data, 0) >= 0 {
+			return nil
+		}
+		remaining = policy.Unsupported(remaining, string(data))
+		if len(remaining) == 0 {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return remaining
+}
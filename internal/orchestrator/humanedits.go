@@ -0,0 +1,78 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/config"
+	"github.com/kowshik24/git-doc/internal/doc"
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+// SetConfirm lets the human_edits "confirm" policy ask before overwriting a
+// section edited by hand. Without it such updates fail.
+func (u *Updater) SetConfirm(confirm func(question string) (bool, error)) {
+	u.deps.Confirm = confirm
+}
+
+// placeSection writes a generated body into content like replaceSection,
+// unless the section was edited by hand since git-doc last wrote it; then
+// human_edits.policy decides how the edits are kept.
+func (u *Updater) placeSection(runID, hash, docFile, content, section, body string, mapping config.Mapping) (string, error) {
+	policy := u.deps.Config.HumanEdits.Policy
+	current, err := u.deps.DocUpdater.ExtractSection(content, section)
+	if policy == "overwrite" || errors.Is(err, doc.ErrSectionNotFound) {
+		return u.replaceSection(content, section, body, mapping)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	last, ok, err := u.deps.State.GetGeneratedSection(docFile, section)
+	if err != nil {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "state", "failed to read generated section", map[string]any{"error": err.Error()})
+	}
+	if !ok || state.SectionHash(current) == last.ContentHash {
+		return u.replaceSection(content, section, body, mapping)
+	}
+
+	meta := map[string]any{"doc_file": docFile, "section": section, "policy": policy}
+	switch policy {
+	case "merge":
+		if merged, clean := doc.Merge3(last.Content, current, body); clean && last.Content != "" {
+			_ = u.deps.State.LogRunEvent(runID, hash, "info", "orchestrator", "merged hand edits", meta)
+			return u.deps.DocUpdater.ReplaceSection(content, section, merged)
+		}
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "orchestrator", "hand edits conflict with update; appending", meta)
+	case "confirm":
+		question := fmt.Sprintf("%s#%s was edited by hand since git-doc last wrote it. Overwrite it?", docFile, section)
+		if u.deps.Confirm == nil {
+			return "", permanent(fmt.Errorf("%s#%s was edited by hand; rerun interactively or change human_edits.policy", docFile, section))
+		}
+		yes, err := u.deps.Confirm(question)
+		if err != nil {
+			return "", err
+		}
+		if !yes {
+			return "", permanent(fmt.Errorf("overwriting hand edits to %s#%s was declined", docFile, section))
+		}
+		_ = u.deps.State.LogRunEvent(runID, hash, "info", "orchestrator", "overwrote hand edits", meta)
+		return u.replaceSection(content, section, body, mapping)
+	default:
+		_ = u.deps.State.LogRunEvent(runID, hash, "info", "orchestrator", "kept hand edits; appending", meta)
+	}
+	return u.deps.DocUpdater.ReplaceSection(content, section, strings.TrimSpace(current)+"\n\n"+strings.TrimSpace(body))
+}
+
+// recordGenerated remembers the section body as written, so the next update
+// can detect hand edits. Failing to store it never fails the commit.
+func (u *Updater) recordGenerated(runID, hash, docFile, content, section string) {
+	body, err := u.deps.DocUpdater.ExtractSection(content, section)
+	if err == nil {
+		err = u.deps.State.PutGeneratedSection(state.GeneratedSection{DocFile: docFile, Section: section, Content: body, CommitHash: hash})
+	}
+	if err != nil {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "state", "failed to record generated section", map[string]any{"error": err.Error()})
+	}
+}
@@ -0,0 +1,121 @@
+package orchestrator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	diffanalyzer "github.com/kowshik24/git-doc/internal/diff"
+)
+
+type ignoreRules struct {
+	paths    []string
+	authors  map[string]struct{}
+	messages []*regexp.Regexp
+}
+
+func (u *Updater) ignoreRules() (*ignoreRules, error) {
+	if u.ignore != nil {
+		return u.ignore, nil
+	}
+
+	cfg := u.deps.Config.Ignore
+	rules := &ignoreRules{authors: make(map[string]struct{}, len(cfg.Authors))}
+
+	for _, pattern := range cfg.Paths {
+		if trimmed := strings.TrimSpace(pattern); trimmed != "" {
+			rules.paths = append(rules.paths, trimmed)
+		}
+	}
+
+	for _, author := range cfg.Authors {
+		if trimmed := strings.ToLower(strings.TrimSpace(author)); trimmed != "" {
+			rules.authors[trimmed] = struct{}{}
+		}
+	}
+
+	messages, err := cfg.MessagePatterns()
+	if err != nil {
+		return nil, err
+	}
+	rules.messages = messages
+
+	u.ignore = rules
+	return rules, nil
+}
+
+// ignoreReason returns a non-empty reason when the commit matches an ignore
+// rule. Author lookups only happen when author rules are configured.
+func (u *Updater) ignoreReason(hash, message string, changedFiles []string) (string, error) {
+	rules, err := u.ignoreRules()
+	if err != nil {
+		return "", err
+	}
+
+	for _, re := range rules.messages {
+		if re.MatchString(message) {
+			return fmt.Sprintf("commit message matches ignore pattern %q", re.String()), nil
+		}
+	}
+
+	if len(rules.authors) > 0 {
+		info, err := u.commitInfo(hash)
+		if err != nil {
+			return "", err
+		}
+		if _, ok := rules.authors[strings.ToLower(strings.TrimSpace(info.Email))]; ok {
+			return fmt.Sprintf("author %s is ignored", info.Email), nil
+		}
+	}
+
+	if len(rules.paths) > 0 && len(changedFiles) > 0 && len(rules.relevantFiles(changedFiles)) == 0 {
+		return "all changed paths match ignore.paths", nil
+	}
+
+	return "", nil
+}
+
+// relevanceReason returns a non-empty reason when ignore.min_relevance is set
+// and the commit's diff scores below it.
+func (u *Updater) relevanceReason(diff string) string {
+	threshold := u.deps.Config.Ignore.MinRelevance
+	if threshold <= 0 {
+		return ""
+	}
+
+	parsed, err := diffanalyzer.ParseUnifiedDiff(diff)
+	if err != nil {
+		return ""
+	}
+	relevance := diffanalyzer.ScoreRelevance(parsed)
+	if relevance.Score >= threshold {
+		return ""
+	}
+
+	reason := relevance.Reason
+	if reason == "" {
+		reason = "low documentation relevance"
+	}
+	return fmt.Sprintf("%s (relevance %.2f < %.2f)", reason, relevance.Score, threshold)
+}
+
+func (r *ignoreRules) relevantFiles(changedFiles []string) []string {
+	if len(r.paths) == 0 {
+		return changedFiles
+	}
+
+	out := make([]string, 0, len(changedFiles))
+	for _, changed := range changedFiles {
+		ignored := false
+		for _, pattern := range r.paths {
+			if matchCodePattern(pattern, changed) {
+				ignored = true
+				break
+			}
+		}
+		if !ignored {
+			out = append(out, changed)
+		}
+	}
+	return out
+}
@@ -0,0 +1,165 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/doc"
+)
+
+// brokenLink is a link a doc update broke: one into docFile whose anchor the
+// update removed, or one the update added that does not resolve.
+type brokenLink struct {
+	file   string
+	link   doc.Link
+	rename string
+}
+
+func (b brokenLink) String() string {
+	target := b.link.Target
+	if b.link.Fragment != "" {
+		target += "#" + b.link.Fragment
+	}
+	return fmt.Sprintf("%s:%d -> %s", b.file, b.link.Line+1, target)
+}
+
+// checkLinks verifies the links affected by changing docFile from before to
+// after, scanning every doc_files document, according to links.check. In
+// fix mode, links to a renamed heading are pointed at its new anchor; the
+// fixed documents are returned by path, docFile included. pending holds
+// documents changed in memory but not yet written.
+func (u *Updater) checkLinks(runID, hash, repoRoot, docFile, before, after string, pending map[string]string) (map[string]string, error) {
+	mode := u.deps.Config.Links.Check
+	if mode == "" || mode == "off" {
+		return nil, nil
+	}
+
+	files, err := u.expandDocFiles(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(files, docFile) {
+		files = append(files, docFile)
+	}
+
+	removed := map[string]bool{}
+	newAnchors := doc.Anchors(after)
+	for anchor := range doc.Anchors(before) {
+		if !newAnchors[anchor] {
+			removed[anchor] = true
+		}
+	}
+	renames := doc.RenamedAnchors(before, after)
+	existing := map[string]bool{}
+	for _, link := range doc.Links(before) {
+		existing[link.Target+"#"+link.Fragment] = true
+	}
+
+	contents := map[string]string{}
+	var broken []brokenLink
+	for _, file := range files {
+		content, ok := pending[file]
+		if file == docFile {
+			content, ok = after, true
+		}
+		if !ok {
+			raw, err := os.ReadFile(filepath.Join(repoRoot, filepath.FromSlash(file)))
+			if err != nil {
+				continue
+			}
+			content = string(raw)
+		}
+		contents[file] = content
+
+		for _, link := range doc.Links(content) {
+			resolved := resolveLink(file, link.Target)
+			fragment := strings.ToLower(link.Fragment)
+			switch {
+			case link.Target == "" && fragment == "":
+			case resolved == docFile && fragment != "" && removed[fragment]:
+				broken = append(broken, brokenLink{file: file, link: link, rename: renames[fragment]})
+			case file == docFile && !existing[link.Target+"#"+link.Fragment] && !u.linkResolves(repoRoot, resolved, fragment, contents, pending):
+				broken = append(broken, brokenLink{file: file, link: link})
+			}
+		}
+	}
+	if len(broken) == 0 {
+		return nil, nil
+	}
+
+	fixed := map[string]string{}
+	var unfixed []string
+	for _, b := range broken {
+		if mode != "fix" || b.rename == "" {
+			unfixed = append(unfixed, b.String())
+			continue
+		}
+		content, ok := fixed[b.file]
+		if !ok {
+			content = contents[b.file]
+		}
+		fixed[b.file] = doc.ReplaceFragment(content, b.link.Line, b.link.Target, b.link.Fragment, b.rename)
+	}
+	if len(fixed) > 0 {
+		_ = u.deps.State.LogRunEvent(runID, hash, "info", "doc", "link fragments fixed", map[string]any{"doc_file": docFile, "files": mapKeys(fixed)})
+	}
+	if len(unfixed) == 0 {
+		return fixed, nil
+	}
+	if mode == "warn" {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "doc", "update breaks links", map[string]any{"doc_file": docFile, "links": unfixed})
+		return fixed, nil
+	}
+	_ = u.deps.State.LogRunEvent(runID, hash, "warn", "doc", "update blocked by broken links", map[string]any{"doc_file": docFile, "links": unfixed})
+	return nil, fmt.Errorf("update breaks links: %s", strings.Join(unfixed, ", "))
+}
+
+// resolveLink returns the repository path a link target in file points to;
+// a leading slash is relative to the repository root.
+func resolveLink(file, target string) string {
+	switch {
+	case target == "":
+		return file
+	case strings.HasPrefix(target, "/"):
+		return path.Clean(strings.TrimPrefix(target, "/"))
+	default:
+		return path.Clean(path.Join(path.Dir(file), target))
+	}
+}
+
+// linkResolves reports whether file exists and, when it is a markdown file
+// and fragment is set, defines that anchor.
+func (u *Updater) linkResolves(repoRoot, file, fragment string, contents, pending map[string]string) bool {
+	content, ok := contents[file]
+	if !ok {
+		content, ok = pending[file]
+	}
+	if !ok {
+		full := filepath.Join(repoRoot, filepath.FromSlash(file))
+		if _, err := os.Stat(full); err != nil {
+			return false
+		}
+		if fragment == "" || !strings.HasSuffix(strings.ToLower(file), ".md") {
+			return true
+		}
+		raw, err := os.ReadFile(full)
+		if err != nil {
+			return false
+		}
+		content = string(raw)
+	}
+	return fragment == "" || !strings.HasSuffix(strings.ToLower(file), ".md") || doc.Anchors(content)[fragment]
+}
+
+func mapKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,70 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+)
+
+const maxSummarizedMergeCommits = 50
+
+// commitFiles returns the files a commit changed along with its parents. Merge
+// commits are diffed against their first parent, since diff-tree reports
+// nothing for them, unless git.merge_strategy is "skip".
+func (u *Updater) commitFiles(hash string) ([]string, []string, string, error) {
+	if meta, ok := u.metadata[hash]; ok {
+		if len(meta.Parents) >= 2 && u.deps.Config.Git.MergeStrategy == "skip" {
+			return nil, meta.Parents, "merge commit", nil
+		}
+		return meta.FilePaths(), meta.Parents, "", nil
+	}
+
+	parents, err := u.deps.Git.GetCommitParents(hash)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	if len(parents) < 2 {
+		files, err := u.deps.Git.GetChangedFiles(hash)
+		return files, parents, "", err
+	}
+
+	if u.deps.Config.Git.MergeStrategy == "skip" {
+		return nil, parents, "merge commit", nil
+	}
+
+	files, err := u.deps.Git.GetChangedFilesBetween(parents[0], hash)
+	return files, parents, "", err
+}
+
+func (u *Updater) commitDiff(hash string, parents []string) (string, error) {
+	if len(parents) < 2 {
+		return u.deps.Git.GetCommitDiff(hash)
+	}
+
+	if u.deps.Config.Git.MergeStrategy != "summarize" {
+		return u.deps.Git.GetDiffBetween(parents[0], hash)
+	}
+
+	merged, err := u.deps.Git.GetLastProcessedRange(parents[0], parents[1])
+	if err != nil {
+		return "", err
+	}
+	if len(merged) == 0 {
+		return u.deps.Git.GetDiffBetween(parents[0], hash)
+	}
+	if len(merged) > maxSummarizedMergeCommits {
+		merged = merged[len(merged)-maxSummarizedMergeCommits:]
+	}
+
+	diffOpts := u.sharedDiffOptions(len(merged))
+	parts := make([]string, 0, len(merged)+1)
+	parts = append(parts, fmt.Sprintf("Merge of %d commits:", len(merged)))
+	for _, commit := range merged {
+		diff, err := u.deps.Git.GetCommitDiff(commit.Hash)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, fmt.Sprintf("Commit %s %s:\n%s", shortHash(commit.Hash), commit.Subject, summarizeDiff(diff, diffOpts)))
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
@@ -0,0 +1,40 @@
+package orchestrator
+
+import "github.com/kowshik24/git-doc/internal/gitutil"
+
+// metadataPrefetcher is implemented by git helpers that can read the message,
+// parents and changed files of many commits in a few subprocesses.
+type metadataPrefetcher interface {
+	GetCommitsMetadata(commits []string) (map[string]gitutil.CommitMetadata, error)
+}
+
+// prefetchMetadata loads metadata for the whole run up front. Commits missing
+// from the cache fall back to per-commit git calls.
+func (u *Updater) prefetchMetadata(runID string, hashes []string) {
+	u.metadata = nil
+	fetcher, ok := u.deps.Git.(metadataPrefetcher)
+	if !ok || len(hashes) == 0 {
+		return
+	}
+
+	metadata, err := fetcher.GetCommitsMetadata(hashes)
+	if err != nil {
+		_ = u.deps.State.LogRunEvent(runID, "", "warn", "git", "metadata prefetch failed", map[string]any{"error": err.Error()})
+		return
+	}
+	u.metadata = metadata
+}
+
+func (u *Updater) commitMessage(hash string) (string, error) {
+	if meta, ok := u.metadata[hash]; ok {
+		return meta.Message, nil
+	}
+	return u.deps.Git.GetCommitMessage(hash)
+}
+
+func (u *Updater) commitInfo(hash string) (gitutil.CommitInfo, error) {
+	if meta, ok := u.metadata[hash]; ok {
+		return meta.CommitInfo, nil
+	}
+	return u.deps.Git.GetCommitInfo(hash)
+}
@@ -0,0 +1,142 @@
+package orchestrator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+const defaultDocTemplate = "# {{.Title}}\n\n## {{.Section}}\n"
+
+type newDocData struct {
+	Title   string
+	Name    string
+	DocFile string
+	Section string
+}
+
+// mappingDocFile fills {N} placeholders in a mapping's doc_file from the
+// first changed file its code_pattern matches.
+func (u *Updater) mappingDocFile(mapping config.Mapping, changedFiles []string) string {
+	if config.MaxDocFilePlaceholder(mapping.DocFile) == 0 {
+		return mapping.DocFile
+	}
+	for _, changed := range changedFiles {
+		if captures, ok := capturePattern(mapping.CodePattern, changed); ok {
+			return config.ExpandDocFile(mapping.DocFile, captures)
+		}
+	}
+	return mapping.DocFile
+}
+
+// sparseCheckout is implemented by git helpers that can add a file left out
+// of a sparse checkout back to the working tree.
+type sparseCheckout interface {
+	MaterializeFile(file string) (bool, error)
+}
+
+// readTargetDoc reads a target doc. A missing doc routed by a create_doc
+// mapping starts from the mapping's doc_template (or a title and the target
+// section) and is written by the first update.
+func (u *Updater) readTargetDoc(repoRoot, docFile, section string, mapping config.Mapping) ([]byte, error) {
+	raw, err := os.ReadFile(filepath.Join(repoRoot, docFile))
+	if errors.Is(err, os.ErrNotExist) {
+		if sparse, ok := u.deps.Git.(sparseCheckout); ok && u.deps.Config.Git.SparseCheckout == "add" {
+			added, addErr := sparse.MaterializeFile(filepath.ToSlash(docFile))
+			if addErr != nil {
+				return nil, fmt.Errorf("add %s to the sparse checkout: %w", docFile, addErr)
+			}
+			if added {
+				raw, err = os.ReadFile(filepath.Join(repoRoot, docFile))
+			}
+		}
+	}
+	if err == nil {
+		return raw, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if !mapping.CreateDoc {
+		return nil, permanent(fmt.Errorf("target doc file not found: %s", docFile))
+	}
+
+	source := defaultDocTemplate
+	if strings.TrimSpace(mapping.DocTemplate) != "" {
+		b, err := os.ReadFile(filepath.Join(repoRoot, mapping.DocTemplate))
+		if err != nil {
+			return nil, fmt.Errorf("read doc template: %w", err)
+		}
+		source = string(b)
+	}
+	tmpl, err := template.New("doc").Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("parse doc template: %w", err)
+	}
+
+	name := strings.TrimSuffix(path.Base(filepath.ToSlash(docFile)), path.Ext(docFile))
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, newDocData{Title: docTitle(name), Name: name, DocFile: docFile, Section: section}); err != nil {
+		return nil, fmt.Errorf("render doc template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func docTitle(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || unicode.IsSpace(r)
+	})
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// capturePattern matches like matchCodePattern and also returns the path
+// segments matched by each wildcard segment other than "**", in order.
+func capturePattern(pattern, changedPath string) ([]string, bool) {
+	pattern = filepath.ToSlash(strings.TrimSpace(pattern))
+	changedPath = strings.TrimSpace(filepath.ToSlash(changedPath))
+	if pattern == "" || changedPath == "" {
+		return nil, false
+	}
+	return captureSegments(strings.Split(pattern, "/"), strings.Split(changedPath, "/"), nil)
+}
+
+func captureSegments(patternParts, pathParts, captures []string) ([]string, bool) {
+	if len(patternParts) == 0 {
+		return captures, len(pathParts) == 0
+	}
+
+	head := patternParts[0]
+	if head == "**" {
+		for i := 0; i <= len(pathParts); i++ {
+			if out, ok := captureSegments(patternParts[1:], pathParts[i:], captures); ok {
+				return out, true
+			}
+		}
+		return nil, false
+	}
+	if len(pathParts) == 0 {
+		return nil, false
+	}
+
+	ok, err := path.Match(head, pathParts[0])
+	if err != nil || !ok {
+		return nil, false
+	}
+	if strings.ContainsAny(head, "*?[") {
+		captures = append(captures[:len(captures):len(captures)], pathParts[0])
+	}
+	return captureSegments(patternParts[1:], pathParts[1:], captures)
+}
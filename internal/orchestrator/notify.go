@@ -0,0 +1,89 @@
+package orchestrator
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kowshik24/git-doc/internal/notify"
+)
+
+const notifyTimeout = 15 * time.Second
+
+// notifyRun posts the run summary with the doc commits it wrote and the
+// commits that failed. Delivery failures are logged but never fail the run.
+func (u *Updater) notifyRun(ctx context.Context, runID string, commitHashes []string, summary Summary) {
+	if u.deps.Notifier == nil || summary.Processed == 0 {
+		return
+	}
+
+	run := notify.Run{
+		RunID:       runID,
+		Repo:        u.repoName(),
+		Trigger:     u.trigger,
+		Processed:   summary.Processed,
+		Success:     summary.Success,
+		Failed:      summary.Failed,
+		Skipped:     summary.Skipped,
+		Pending:     summary.Pending,
+		Interrupted: summary.Interrupted,
+	}
+	seen := map[string]bool{}
+	for _, hash := range commitHashes {
+		row, ok, err := u.deps.State.GetProcessedCommit(hash)
+		if err != nil || !ok {
+			continue
+		}
+		switch {
+		case row.Status == "failed" && row.Error.Valid:
+			run.Failures = append(run.Failures, notify.Failure{Commit: hash, Error: row.Error.String})
+		case row.Status == "success" && row.DocCommit.Valid && !seen[row.DocCommit.String]:
+			seen[row.DocCommit.String] = true
+			run.DocCommits = append(run.DocCommits, notify.DocCommit{CodeCommit: hash, DocCommit: row.DocCommit.String, URL: u.commitURL(row.DocCommit.String)})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notifyTimeout)
+	defer cancel()
+	if err := u.deps.Notifier.RunFinished(ctx, run); err != nil {
+		_ = u.deps.State.LogRunEvent(runID, "", "warn", "notify", "run notification failed", map[string]any{"error": err.Error()})
+	}
+}
+
+// noteFailure tracks consecutive failed commits and alerts once when the
+// streak reaches notifications.failure_threshold.
+func (u *Updater) noteFailure(runID, hash string, err error) {
+	threshold := u.deps.Config.Notifications.FailureThreshold
+	if u.deps.Notifier == nil || threshold <= 0 {
+		return
+	}
+	u.failureStreak = append(u.failureStreak, notify.Failure{Commit: hash, Error: err.Error()})
+	if len(u.failureStreak) != threshold {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	if err := u.deps.Notifier.RepeatedFailures(ctx, runID, u.repoName(), u.failureStreak); err != nil {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "notify", "failure alert failed", map[string]any{"error": err.Error()})
+		return
+	}
+	_ = u.deps.State.LogRunEvent(runID, hash, "warn", "notify", "repeated failure alert sent", map[string]any{"failures": threshold})
+}
+
+func (u *Updater) repoName() string {
+	repoRoot, err := u.deps.Git.GetRepoRoot()
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(repoRoot)
+}
+
+func (u *Updater) commitURL(hash string) string {
+	template := u.deps.Config.Notifications.CommitURL
+	if template == "" {
+		return ""
+	}
+	return strings.ReplaceAll(template, "{hash}", hash)
+}
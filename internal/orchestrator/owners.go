@@ -0,0 +1,62 @@
+package orchestrator
+
+import (
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/config"
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+// docOwners returns the last [[owners.rules]] entry matching docFile and the
+// policy that applies to it.
+func (u *Updater) docOwners(docFile string) (config.OwnerRule, string) {
+	owners := u.deps.Config.Owners
+	var match config.OwnerRule
+	for _, rule := range owners.Rules {
+		if matchCodePattern(strings.TrimPrefix(rule.Path, "/"), docFile) {
+			match = rule
+		}
+	}
+	if match.Policy != "" {
+		return match, match.Policy
+	}
+	if owners.DefaultPolicy == "" {
+		return match, "auto"
+	}
+	return match, owners.DefaultPolicy
+}
+
+func ownedBy(rule config.OwnerRule) string {
+	if len(rule.Owners) == 0 {
+		return ""
+	}
+	return " (owners: " + strings.Join(rule.Owners, ", ") + ")"
+}
+
+// neverReason is non-empty when docFile's owners policy is "never", so
+// commits targeting it are skipped before the doc is even read.
+func (u *Updater) neverReason(docFile string) string {
+	rule, policy := u.docOwners(docFile)
+	if policy != "never" {
+		return ""
+	}
+	return "owners policy for " + docFile + " is never" + ownedBy(rule)
+}
+
+// queueForApproval leaves a generated update unwritten and records it, with
+// its content, as awaiting approval when its doc's owners policy requires it.
+func (u *Updater) queueForApproval(runID, hash, docFile, section, strategy, before, updated string) (bool, error) {
+	rule, policy := u.docOwners(docFile)
+	if policy != "require-approval" {
+		return false, nil
+	}
+	reason := "awaiting approval" + ownedBy(rule)
+	if err := u.deps.State.UpsertPlannedUpdate(hash, docFile, section, strategy, state.PlannedAwaitingApproval, reason); err != nil {
+		return false, err
+	}
+	if err := u.keepPlannedContent(hash, docFile, section, before, updated); err != nil {
+		return false, err
+	}
+	_ = u.deps.State.LogRunEvent(runID, hash, "info", "orchestrator", "update queued for approval", map[string]any{"doc_file": docFile, "section": section, "owners": rule.Owners})
+	return true, u.deps.State.MarkCommitProcessed(hash, "skipped", "", "", nil)
+}
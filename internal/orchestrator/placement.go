@@ -0,0 +1,32 @@
+package orchestrator
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/config"
+	"github.com/kowshik24/git-doc/internal/doc"
+)
+
+// replaceSection replaces section in content, or creates it where the
+// mapping's section_level, insert and section_template say when the doc
+// does not have it yet.
+func (u *Updater) replaceSection(content, section, body string, mapping config.Mapping) (string, error) {
+	if _, err := u.deps.DocUpdater.ExtractSection(content, section); !errors.Is(err, doc.ErrSectionNotFound) {
+		return u.deps.DocUpdater.ReplaceSection(content, section, body)
+	}
+	if mapping.SectionTemplate != "" {
+		body = strings.NewReplacer("{section}", section, "{content}", strings.TrimSpace(body)).Replace(mapping.SectionTemplate)
+	}
+	return doc.InsertSection(content, section, body, doc.Placement{Level: mapping.SectionLevel, Insert: strings.TrimSpace(mapping.Insert)}), nil
+}
+
+// newSectionLevel is the heading level generated content is nested under:
+// the section's own level, or the mapping's section_level for a section
+// that does not exist yet.
+func newSectionLevel(content, section string, mapping config.Mapping) int {
+	if mapping.SectionLevel > 0 && doc.SectionLine(content, section) == 0 {
+		return mapping.SectionLevel
+	}
+	return doc.SectionLevel(content, section)
+}
@@ -0,0 +1,147 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/commitclass"
+	diffanalyzer "github.com/kowshik24/git-doc/internal/diff"
+	"github.com/kowshik24/git-doc/internal/doc"
+	"github.com/kowshik24/git-doc/internal/llm"
+)
+
+const maxInventorySections = 40
+
+type inventoryDoc struct {
+	File     string
+	Sections []doc.Section
+}
+
+// docInventory lists the doc files matching doc_files with their sections.
+func (u *Updater) docInventory(repoRoot string) ([]inventoryDoc, error) {
+	files, err := u.expandDocFiles(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []inventoryDoc
+	for _, rel := range files {
+		raw, err := os.ReadFile(filepath.Join(repoRoot, filepath.FromSlash(rel)))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		docs = append(docs, inventoryDoc{File: rel, Sections: doc.Sections(string(raw))})
+	}
+	return docs, nil
+}
+
+// targetFor resolves the doc section for a commit: a matching mapping wins,
+// then the planner when llm.plan_targets is set, then the most similar
+// section when embeddings are enabled, then the default target.
+func (u *Updater) targetFor(ctx context.Context, runID, hash, repoRoot, message string, changedFiles []string, diff string, class commitclass.Classification) (string, string) {
+	if _, ok := u.matchMapping(changedFiles, class); !ok {
+		if docFile, section, ok := u.planTarget(ctx, runID, hash, repoRoot, message, changedFiles, diff); ok {
+			return docFile, section
+		}
+		if docFile, section, ok := u.retrieveTarget(ctx, runID, hash, repoRoot, message, diff); ok {
+			return docFile, section
+		}
+	}
+	return u.resolveTarget(changedFiles, class)
+}
+
+// planTarget asks the planner model which doc section a commit affects. It
+// reports false when planning is off or fails, or when the answer names a doc
+// file outside the inventory; callers then keep the heuristic target.
+func (u *Updater) planTarget(ctx context.Context, runID, hash, repoRoot, message string, changedFiles []string, diff string) (string, string, bool) {
+	if !u.deps.Config.LLM.PlanTargets || u.deps.Planner == nil {
+		return "", "", false
+	}
+
+	inventory, err := u.docInventory(repoRoot)
+	if err != nil || len(inventory) == 0 {
+		if err != nil {
+			_ = u.deps.State.LogRunEvent(runID, hash, "warn", "planner", "doc inventory failed", map[string]any{"error": err.Error()})
+		}
+		return "", "", false
+	}
+
+	prompt := buildPlanPrompt(message, changedFiles, diff, inventory)
+	raw, err := u.deps.Planner.Generate(ctx, prompt)
+	u.logPrompt(runID, hash, "plan", "", "", u.deps.Planner.Name(), u.deps.Config.LLM.ResolvedPlanner().Model, prompt, err)
+	if errors.Is(err, llm.ErrOffline) {
+		return "", "", false
+	}
+	if err != nil {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "planner", "planning call failed", map[string]any{"error": err.Error()})
+		return "", "", false
+	}
+	plan, err := llm.ParseTargetPlan(raw)
+	if err != nil {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "planner", "plan rejected", map[string]any{"error": err.Error()})
+		return "", "", false
+	}
+
+	known := false
+	for _, entry := range inventory {
+		if entry.File == plan.DocFile {
+			known = true
+			break
+		}
+	}
+	if !known {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "planner", "plan rejected", map[string]any{"error": fmt.Sprintf("doc file %s is not in the inventory", plan.DocFile)})
+		return "", "", false
+	}
+
+	_ = u.deps.State.LogRunEvent(runID, hash, "info", "planner", "target planned", map[string]any{
+		"doc_file": plan.DocFile,
+		"section":  plan.Section,
+		"reason":   plan.Reason,
+		"planner":  u.deps.Planner.Name(),
+	})
+	return plan.DocFile, plan.Section, true
+}
+
+func buildPlanPrompt(message string, changedFiles []string, diff string, inventory []inventoryDoc) string {
+	var b strings.Builder
+	b.WriteString("You maintain a repository's documentation. Decide which documentation section a code change should update.\n\n")
+	fmt.Fprintf(&b, "Commit message:\n%s\n\n", strings.TrimSpace(message))
+	fmt.Fprintf(&b, "Changed files:\n- %s\n\n", strings.Join(changedFiles, "\n- "))
+	if parsed, err := diffanalyzer.ParseUnifiedDiff(diff); err == nil && len(parsed.Files) > 0 {
+		fmt.Fprintf(&b, "Diff summary:\n%s\n\n", diffanalyzer.TruncateText(diffanalyzer.BuildSummary(parsed), 2000))
+	}
+
+	b.WriteString("Documentation inventory:\n")
+	for _, entry := range inventory {
+		titles := make([]string, 0, len(entry.Sections))
+		for _, section := range entry.Sections {
+			titles = append(titles, section.Title)
+		}
+		more := 0
+		if len(titles) > maxInventorySections {
+			more = len(titles) - maxInventorySections
+			titles = titles[:maxInventorySections]
+		}
+		fmt.Fprintf(&b, "- %s", entry.File)
+		if len(titles) > 0 {
+			fmt.Fprintf(&b, ": %s", strings.Join(titles, "; "))
+		}
+		if more > 0 {
+			fmt.Fprintf(&b, " (and %d more)", more)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(llm.PlanInstructions)
+	b.WriteString("\n")
+	return b.String()
+}
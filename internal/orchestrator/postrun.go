@@ -0,0 +1,93 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/doc"
+)
+
+// postRunDocs applies the run-level doc updates, changelog entries and the
+// status block, and records them in one doc commit. Failures are logged but
+// never fail the run.
+func (u *Updater) postRunDocs(runID string, commitHashes []string, dryRun bool) {
+	if !u.deps.Config.Changelog.Enabled && !u.deps.Config.StatusBlock.Enabled {
+		return
+	}
+	repoRoot, err := u.deps.Git.GetRepoRoot()
+	if err != nil {
+		_ = u.deps.State.LogRunEvent(runID, "", "error", "orchestrator", "run docs update failed", map[string]any{"error": err.Error()})
+		return
+	}
+
+	originals := map[string]string{}
+	contents := map[string]string{}
+	var order []string
+	load := func(file string) (string, error) {
+		if content, ok := contents[file]; ok {
+			return content, nil
+		}
+		raw, err := os.ReadFile(filepath.Join(repoRoot, file))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		originals[file] = string(raw)
+		order = append(order, file)
+		return string(raw), nil
+	}
+
+	if cfg := u.deps.Config.Changelog; cfg.Enabled {
+		if entries := u.changelogEntries(commitHashes); len(entries) > 0 {
+			content, err := load(cfg.File)
+			if err != nil {
+				_ = u.deps.State.LogRunEvent(runID, "", "error", "changelog", "changelog update failed", map[string]any{"error": err.Error()})
+			} else {
+				contents[cfg.File] = doc.AddChangelogEntries(content, entries)
+				_ = u.deps.State.LogRunEvent(runID, "", "info", "changelog", "changelog updated", map[string]any{"file": cfg.File, "entries": len(entries), "dry_run": dryRun})
+			}
+		}
+	}
+
+	if cfg := u.deps.Config.StatusBlock; cfg.Enabled {
+		content, err := load(cfg.File)
+		if err == nil {
+			var body string
+			body, err = u.statusBlock()
+			if err == nil {
+				contents[cfg.File] = doc.ReplaceMarkedBlock(content, statusBlockName, body)
+			}
+		}
+		if err != nil {
+			_ = u.deps.State.LogRunEvent(runID, "", "error", "status_block", "status block update failed", map[string]any{"error": err.Error()})
+		}
+	}
+
+	var changed []string
+	for _, file := range order {
+		if content, ok := contents[file]; ok && content != originals[file] {
+			changed = append(changed, file)
+		}
+	}
+	if len(changed) == 0 || dryRun {
+		return
+	}
+
+	tx := doc.NewTransaction()
+	for _, file := range changed {
+		content := doc.NormalizeLineEndings(contents[file], doc.DetectLineEnding(originals[file]))
+		if err := tx.Write(filepath.Join(repoRoot, file), []byte(content), 0o644); err != nil {
+			u.rollbackDocs(runID, "", tx)
+			_ = u.deps.State.LogRunEvent(runID, "", "error", "orchestrator", "run docs update failed", map[string]any{"error": err.Error()})
+			return
+		}
+	}
+	if _, err := u.commitDocFiles(runID, changed, nil, commitHashes); err != nil {
+		u.rollbackDocs(runID, "", tx)
+		_ = u.deps.State.LogRunEvent(runID, "", "error", "orchestrator", "run docs update failed", map[string]any{"files": strings.Join(changed, ", "), "error": err.Error()})
+		return
+	}
+	tx.Commit()
+	u.applyAmendRewrite(runID)
+}
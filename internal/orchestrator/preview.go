@@ -0,0 +1,29 @@
+package orchestrator
+
+import "context"
+
+// Preview is what the provider generates for a commit's target section.
+// SkipReason is set instead of Content when the commit needs no update.
+type Preview struct {
+	CommitHash string
+	DocFile    string
+	Section    string
+	SkipReason string
+	Content    string
+}
+
+// PreviewCommit generates the section update for a commit without writing
+// docs, caches or state, e.g. to check a new configuration.
+func (u *Updater) PreviewCommit(ctx context.Context, hash string) (Preview, error) {
+	prepared, err := u.prepareCommit(ctx, "", hash, false)
+	preview := Preview{CommitHash: hash, DocFile: prepared.docFile, Section: prepared.section, SkipReason: prepared.skipReason}
+	if err != nil || prepared.skipReason != "" {
+		return preview, err
+	}
+	prompt, err := u.withStyleGuide(prepared.prompt)
+	if err != nil {
+		return preview, err
+	}
+	preview.Content, err = u.deps.LLM.Generate(ctx, prompt)
+	return preview, err
+}
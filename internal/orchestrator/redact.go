@@ -0,0 +1,100 @@
+package orchestrator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/policy"
+)
+
+// redactInputs masks secrets in a commit's message and diff before they
+// reach a prompt, and withholds the diff of files matching redaction.paths.
+// With redaction.on_match = "fail" any match fails the commit instead.
+func (u *Updater) redactInputs(runID, hash, message, diff string) (string, string, error) {
+	cfg := u.deps.Config.Redaction
+	if !cfg.Enabled {
+		return message, diff, nil
+	}
+	if u.redactor == nil {
+		redactor, err := policy.NewRedactor(cfg)
+		if err != nil {
+			return "", "", err
+		}
+		u.redactor = redactor
+	}
+
+	diff, withheld := withholdPaths(diff, func(path string) bool {
+		for _, pattern := range cfg.Paths {
+			if matchCodePattern(pattern, path) {
+				return true
+			}
+		}
+		return false
+	})
+	message, messageRules := u.redactor.Redact(message)
+	diff, diffRules := u.redactor.Redact(diff)
+
+	rules := messageRules
+	for _, rule := range diffRules {
+		if !slices.Contains(rules, rule) {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 && len(withheld) == 0 {
+		return message, diff, nil
+	}
+
+	metadata := map[string]any{"rules": rules, "paths": withheld}
+	if cfg.OnMatch == "fail" {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "redaction", "commit blocked by redaction rules", metadata)
+		var reasons []string
+		if len(rules) > 0 {
+			reasons = append(reasons, "secrets matched "+strings.Join(rules, ", "))
+		}
+		if len(withheld) > 0 {
+			reasons = append(reasons, "secret paths changed "+strings.Join(withheld, ", "))
+		}
+		return "", "", fmt.Errorf("redaction: %s", strings.Join(reasons, "; "))
+	}
+	_ = u.deps.State.LogRunEvent(runID, hash, "info", "redaction", "inputs redacted", metadata)
+	return message, diff, nil
+}
+
+// withholdPaths replaces the body of every file section in a unified diff
+// whose path matches with a placeholder, keeping its "diff --git" header so
+// the file is still listed. It returns the withheld paths.
+func withholdPaths(diff string, match func(path string) bool) (string, []string) {
+	lines := strings.SplitAfter(diff, "\n")
+	var b strings.Builder
+	var withheld []string
+	skipping := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "diff --git ") {
+			path := diffGitPath(line)
+			skipping = match(path)
+			b.WriteString(line)
+			if skipping {
+				withheld = append(withheld, path)
+				b.WriteString("[REDACTED: diff of " + path + " withheld]\n")
+			}
+			continue
+		}
+		if !skipping {
+			b.WriteString(line)
+		}
+	}
+	if len(withheld) == 0 {
+		return diff, nil
+	}
+	return b.String(), withheld
+}
+
+// diffGitPath returns the new path of a "diff --git a/<old> b/<new>" line.
+func diffGitPath(line string) string {
+	line = strings.TrimRight(strings.TrimPrefix(line, "diff --git "), "\r\n")
+	if i := strings.LastIndex(line, " b/"); i >= 0 {
+		return line[i+len(" b/"):]
+	}
+	return strings.TrimPrefix(line, "a/")
+}
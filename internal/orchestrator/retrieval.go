@@ -0,0 +1,124 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	diffanalyzer "github.com/kowshik24/git-doc/internal/diff"
+	"github.com/kowshik24/git-doc/internal/llm"
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+const maxEmbeddedSectionChars = 4000
+
+type indexedSection struct {
+	docFile string
+	section string
+	vector  []float32
+}
+
+// retrieveTarget embeds the commit message and diff summary and returns the
+// doc section most similar to them, if any scores at least
+// embeddings.min_similarity.
+func (u *Updater) retrieveTarget(ctx context.Context, runID, hash, repoRoot, message, diff string) (string, string, bool) {
+	if !u.deps.Config.Embeddings.Enabled || u.deps.Embedder == nil {
+		return "", "", false
+	}
+
+	index, err := u.sectionIndex(ctx, repoRoot)
+	if err != nil {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "embeddings", "section index failed", map[string]any{"error": err.Error()})
+		return "", "", false
+	}
+	if len(index) == 0 {
+		return "", "", false
+	}
+
+	query := strings.TrimSpace(message)
+	if parsed, err := diffanalyzer.ParseUnifiedDiff(diff); err == nil && len(parsed.Files) > 0 {
+		query += "\n\n" + diffanalyzer.BuildSummary(parsed)
+	}
+	vectors, err := u.deps.Embedder.Embed(ctx, []string{query})
+	if err != nil || len(vectors) != 1 {
+		if err != nil {
+			_ = u.deps.State.LogRunEvent(runID, hash, "warn", "embeddings", "commit embedding failed", map[string]any{"error": err.Error()})
+		}
+		return "", "", false
+	}
+
+	best, bestScore := -1, -2.0
+	for i, entry := range index {
+		if score := llm.CosineSimilarity(vectors[0], entry.vector); score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 || bestScore < u.deps.Config.Embeddings.MinSimilarity {
+		_ = u.deps.State.LogRunEvent(runID, hash, "debug", "embeddings", "no similar section", map[string]any{"best_similarity": bestScore})
+		return "", "", false
+	}
+
+	_ = u.deps.State.LogRunEvent(runID, hash, "info", "embeddings", "target retrieved", map[string]any{
+		"doc_file":   index[best].docFile,
+		"section":    index[best].section,
+		"similarity": bestScore,
+	})
+	return index[best].docFile, index[best].section, true
+}
+
+// sectionIndex returns a vector for every section of the doc inventory,
+// embedding only sections that are new or changed since they were stored.
+func (u *Updater) sectionIndex(ctx context.Context, repoRoot string) ([]indexedSection, error) {
+	inventory, err := u.docInventory(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	model := u.deps.Embedder.Name()
+	stored, err := u.deps.State.GetSectionEmbeddings(model)
+	if err != nil {
+		return nil, err
+	}
+	cached := make(map[string]state.SectionEmbedding, len(stored))
+	for _, e := range stored {
+		cached[e.DocFile+"\x00"+e.Section] = e
+	}
+
+	var index []indexedSection
+	var pending []state.SectionEmbedding
+	var texts []string
+	for _, entry := range inventory {
+		for _, section := range entry.Sections {
+			text := diffanalyzer.TruncateText(section.Content, maxEmbeddedSectionChars)
+			sum := sha256.Sum256([]byte(entry.File + "\x00" + text))
+			contentHash := hex.EncodeToString(sum[:])
+
+			if e, ok := cached[entry.File+"\x00"+section.Title]; ok && e.ContentHash == contentHash {
+				index = append(index, indexedSection{docFile: entry.File, section: section.Title, vector: e.Vector})
+				continue
+			}
+			pending = append(pending, state.SectionEmbedding{DocFile: entry.File, Section: section.Title, Model: model, ContentHash: contentHash})
+			texts = append(texts, entry.File+": "+text)
+		}
+	}
+	if len(texts) == 0 {
+		return index, nil
+	}
+
+	vectors, err := u.deps.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pending {
+		if i >= len(vectors) {
+			break
+		}
+		pending[i].Vector = vectors[i]
+		if err := u.deps.State.PutSectionEmbedding(pending[i]); err != nil {
+			return nil, err
+		}
+		index = append(index, indexedSection{docFile: pending[i].DocFile, section: pending[i].Section, vector: vectors[i]})
+	}
+	return index, nil
+}
@@ -0,0 +1,135 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+type RevertTarget struct {
+	DocCommit   string
+	CodeCommits []string
+}
+
+// RevertTargets groups code commits (oldest first) by the doc commit that
+// documented them and orders the groups newest first, so later doc commits
+// are reverted before the ones they build on. Commits without a doc commit or
+// already reverted are left out.
+func (u *Updater) RevertTargets(codeCommits []string) ([]RevertTarget, error) {
+	reverted, err := u.deps.State.GetRevertedCommits()
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []RevertTarget
+	index := map[string]int{}
+	for i := len(codeCommits) - 1; i >= 0; i-- {
+		hash := codeCommits[i]
+		if reverted[hash] {
+			continue
+		}
+		docCommit, err := u.deps.State.GetDocCommitHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		if docCommit == "" {
+			continue
+		}
+		if pos, ok := index[docCommit]; ok {
+			targets[pos].CodeCommits = append(targets[pos].CodeCommits, hash)
+			continue
+		}
+		index[docCommit] = len(targets)
+		targets = append(targets, RevertTarget{DocCommit: docCommit, CodeCommits: []string{hash}})
+	}
+	return targets, nil
+}
+
+// CascadeCommits returns codeCommit followed by every commit after it up to
+// HEAD, whose doc updates may depend on the one being reverted.
+func (u *Updater) CascadeCommits(codeCommit string) ([]string, error) {
+	head, err := u.deps.Git.GetCurrentHEAD()
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := u.deps.Git.GetLastProcessedRange(codeCommit, head)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := []string{codeCommit}
+	for _, commit := range commits {
+		hashes = append(hashes, commit.Hash)
+	}
+	return hashes, nil
+}
+
+func (u *Updater) RunCommits(runID string) ([]string, error) {
+	if _, found, err := u.deps.State.GetRun(runID); err != nil {
+		return nil, err
+	} else if !found {
+		return nil, fmt.Errorf("run not found: %s", runID)
+	}
+	return u.deps.State.GetRunCommits(runID)
+}
+
+// RevertDocCommits reverts targets in order and records which revert commit
+// undid each code commit's documentation. It stops at the first failure.
+func (u *Updater) RevertDocCommits(targets []RevertTarget) (Summary, error) {
+	runID := fmt.Sprintf("run-%d", time.Now().UnixNano())
+	if err := u.deps.State.StartRun(runID, "revert", false); err != nil {
+		return Summary{}, err
+	}
+
+	summary := Summary{RunID: runID}
+	var revertErr error
+	for _, target := range targets {
+		summary.Processed++
+		revertCommit, err := u.revertDocCommit(target.DocCommit)
+		if err != nil {
+			summary.Failed++
+			revertErr = fmt.Errorf("revert doc commit %s: %w", target.DocCommit, err)
+			_ = u.deps.State.LogRunEvent(runID, target.CodeCommits[0], "error", "git", "doc commit revert failed", map[string]any{
+				"doc_commit": target.DocCommit,
+				"error":      err.Error(),
+			})
+			break
+		}
+
+		for _, hash := range target.CodeCommits {
+			if err := u.deps.State.RecordRevert(hash, target.DocCommit, revertCommit, runID); err != nil {
+				revertErr = err
+			}
+		}
+		summary.Success++
+		_ = u.deps.State.LogRunEvent(runID, target.CodeCommits[0], "info", "git", "doc commit reverted", map[string]any{
+			"doc_commit":    target.DocCommit,
+			"revert_commit": revertCommit,
+			"code_commits":  strings.Join(target.CodeCommits, ","),
+		})
+		if revertErr != nil {
+			break
+		}
+	}
+
+	status := "finished"
+	if revertErr != nil {
+		status = "failed"
+	}
+	_ = u.deps.State.FinishRun(runID, status, state.RunCounts{
+		Processed: summary.Processed,
+		Success:   summary.Success,
+		Failed:    summary.Failed,
+	})
+	return summary, revertErr
+}
+
+func (u *Updater) revertDocCommit(docCommit string) (string, error) {
+	if err := u.deps.Git.RevertCommit(docCommit); err != nil {
+		return "", err
+	}
+	return u.deps.Git.GetCurrentHEAD()
+}
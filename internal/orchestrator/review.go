@@ -0,0 +1,41 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// reviewSection runs llm.review_passes critique passes over a generated
+// section. Each pass sends the section back with the original request,
+// which holds the commit and its diff, and takes the corrected section. A
+// failed pass keeps the section from the previous one.
+func (u *Updater) reviewSection(ctx context.Context, runID, hash, docFile, section, prompt, generated string) (string, error) {
+	for pass := 1; pass <= u.deps.Config.LLM.ReviewPasses; pass++ {
+		reviewed, err := u.generate(ctx, runID, hash, "review", docFile, section, reviewPrompt(docFile, section, prompt, generated))
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if err != nil {
+			_ = u.deps.State.LogRunEvent(runID, hash, "warn", "llm", "review pass failed", map[string]any{"pass": pass, "error": err.Error()})
+			return generated, nil
+		}
+		changed := strings.TrimSpace(reviewed) != strings.TrimSpace(generated)
+		_ = u.deps.State.LogRunEvent(runID, hash, "info", "llm", "review pass", map[string]any{"pass": pass, "changed": changed})
+		if !changed {
+			break
+		}
+		generated = reviewed
+	}
+	return generated, nil
+}
+
+func reviewPrompt(docFile, section, task, generated string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Review a draft of the section %q of %s against the request it was written for, which includes the commit and its diff.\n", section, docFile)
+	b.WriteString("Check every factual claim: function names, flags, file paths, config keys and behaviour must be supported by the commit, the diff or the existing document. Remove or correct anything that is not, and keep everything that is.\n\n")
+	fmt.Fprintf(&b, "Request:\n%s\n\n", strings.TrimSpace(task))
+	fmt.Fprintf(&b, "Draft:\n%s\n\n", strings.TrimSpace(generated))
+	b.WriteString("Return only the corrected section body, without the heading and without commenting on the changes. Return the draft unchanged if it is accurate.\n")
+	return b.String()
+}
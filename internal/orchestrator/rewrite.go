@@ -0,0 +1,58 @@
+package orchestrator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kowshik24/git-doc/internal/gitutil"
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+// ReconcileRewrites moves recorded state from rewritten commits to their new
+// hashes so amended or rebased commits are not documented a second time. It
+// returns how many rewrites touched existing state.
+func (u *Updater) ReconcileRewrites(rewriteType string, rewrites []gitutil.Rewrite) (int, error) {
+	runID := fmt.Sprintf("run-%d", time.Now().UnixNano())
+	if err := u.deps.State.StartRun(runID, "rewrite", false); err != nil {
+		return 0, err
+	}
+
+	remapped, failed := 0, 0
+	var firstErr error
+	for _, rewrite := range rewrites {
+		if rewrite.Old == rewrite.New {
+			continue
+		}
+		ok, err := u.deps.State.RemapCommit(rewrite.Old, rewrite.New)
+		if err != nil {
+			_ = u.deps.State.LogRunEvent(runID, rewrite.Old, "error", "state", "failed to remap rewritten commit", map[string]any{"new_hash": rewrite.New, "error": err.Error()})
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if ok {
+			remapped++
+			_ = u.deps.State.LogRunEvent(runID, rewrite.New, "info", "orchestrator", "rewritten commit remapped", map[string]any{"old_hash": rewrite.Old, "type": rewriteType})
+		}
+	}
+
+	status := "finished"
+	if firstErr != nil {
+		status = "failed"
+	}
+	_ = u.deps.State.FinishRun(runID, status, state.RunCounts{Processed: len(rewrites), Success: remapped, Failed: failed})
+	return remapped, firstErr
+}
+
+func (u *Updater) applyAmendRewrite(runID string) {
+	rewrite := u.amended
+	u.amended = gitutil.Rewrite{}
+	if rewrite.Old == "" || rewrite.Old == rewrite.New {
+		return
+	}
+	if _, err := u.deps.State.RemapCommit(rewrite.Old, rewrite.New); err != nil {
+		_ = u.deps.State.LogRunEvent(runID, rewrite.Old, "warn", "state", "failed to remap amended commit", map[string]any{"new_hash": rewrite.New, "error": err.Error()})
+	}
+}
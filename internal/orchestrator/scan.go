@@ -0,0 +1,94 @@
+package orchestrator
+
+import (
+	"strings"
+	"time"
+
+	"github.com/kowshik24/git-doc/internal/gitutil"
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+// fileBlamer is implemented by git helpers that can attribute each line of a
+// file to the commit that last changed it.
+type fileBlamer interface {
+	BlameFile(path string) ([]gitutil.BlameLine, error)
+}
+
+const sectionPathSeparator = " > "
+
+// Scan walks the doc_files globs, records every heading with its word count
+// and the newest commit touching its lines, and replaces the stored doc
+// inventory with the result.
+func (u *Updater) Scan() ([]state.DocSection, error) {
+	repoRoot, err := u.deps.Git.GetRepoRoot()
+	if err != nil {
+		return nil, err
+	}
+	inventory, err := u.docInventory(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	blamer, _ := u.deps.Git.(fileBlamer)
+	entries := make([]state.DocSection, 0)
+	for _, entry := range inventory {
+		var blame []gitutil.BlameLine
+		if blamer != nil {
+			// Untracked docs have no history yet; they are still inventoried.
+			blame, _ = blamer.BlameFile(entry.File)
+		}
+
+		for _, section := range entry.Sections {
+			e := state.DocSection{
+				DocFile: entry.File,
+				Path:    strings.Join(section.Path, sectionPathSeparator),
+				Section: section.Title,
+				Level:   section.Level,
+				Line:    section.Line,
+				Anchor:  section.Anchor,
+				Words:   section.Words,
+			}
+			e.LastCommit, e.LastModifiedAt = newestBlame(blame, section.Line, section.EndLine)
+			entries = append(entries, e)
+		}
+	}
+
+	if err := u.deps.State.ReplaceDocInventory(entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func newestBlame(blame []gitutil.BlameLine, start, end int) (string, time.Time) {
+	var commit string
+	var at time.Time
+	for i := start; i < end && i < len(blame); i++ {
+		if blame[i].Commit != "" && blame[i].Time.After(at) {
+			commit, at = blame[i].Commit, blame[i].Time
+		}
+	}
+	return commit, at
+}
+
+// inventorySection maps a section written as a "#anchor" or a heading path
+// ("Usage > Flags") to its heading title using the stored doc inventory.
+// Plain titles and unknown sections are returned unchanged.
+func (u *Updater) inventorySection(docFile, section string) string {
+	if !strings.HasPrefix(section, "#") && !strings.Contains(section, sectionPathSeparator) {
+		return section
+	}
+	entries, err := u.deps.State.GetDocInventory()
+	if err != nil {
+		return section
+	}
+	anchor := strings.TrimPrefix(section, "#")
+	for _, entry := range entries {
+		if entry.DocFile != docFile {
+			continue
+		}
+		if entry.Path == section || (strings.HasPrefix(section, "#") && entry.Anchor == anchor) {
+			return entry.Section
+		}
+	}
+	return section
+}
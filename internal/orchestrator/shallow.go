@@ -0,0 +1,36 @@
+package orchestrator
+
+// shallowHistory is implemented by git helpers that can tell whether a
+// commit is missing from a shallow clone and fetch more history.
+type shallowHistory interface {
+	IsShallow() (bool, error)
+	HasCommit(commit string) bool
+	Deepen(depth int) error
+}
+
+// historyFrom returns from when the local history has it. A shallow clone
+// missing it first fetches git.deepen more commits; when from is still
+// missing, "" is returned so only the available commits are processed.
+// Outside shallow clones from is returned as is and the range fails.
+func (u *Updater) historyFrom(from string) (string, error) {
+	history, ok := u.deps.Git.(shallowHistory)
+	if from == "" || !ok || history.HasCommit(from) {
+		return from, nil
+	}
+	shallow, err := history.IsShallow()
+	if err != nil || !shallow {
+		return from, err
+	}
+
+	meta := map[string]any{"from": from, "deepen": u.deps.Config.Git.Deepen}
+	if depth := u.deps.Config.Git.Deepen; depth > 0 {
+		if err := history.Deepen(depth); err != nil {
+			_ = u.deps.State.LogRunEvent("", "", "warn", "git", "deepening shallow clone failed", map[string]any{"depth": depth, "error": err.Error()})
+		} else if history.HasCommit(from) {
+			_ = u.deps.State.LogRunEvent("", "", "info", "git", "deepened shallow clone", meta)
+			return from, nil
+		}
+	}
+	_ = u.deps.State.LogRunEvent("", "", "warn", "git", "shallow clone is missing the start commit; processing only the available commits", meta)
+	return "", nil
+}
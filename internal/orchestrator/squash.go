@@ -0,0 +1,163 @@
+package orchestrator
+
+import (
+	"context"
+	"time"
+
+	"github.com/kowshik24/git-doc/internal/commitclass"
+	"github.com/kowshik24/git-doc/internal/config"
+	"github.com/kowshik24/git-doc/internal/progress"
+)
+
+// squashSegment is a run of consecutive commits processed together: one
+// commit on its own, or commits coalesced by a mapping's squash window into
+// one batched update by updater, the commits' workspace updater.
+type squashSegment struct {
+	updater *Updater
+	hashes  []string
+	squash  bool
+}
+
+// offsetProgress reports the progress of one segment as part of the run.
+type offsetProgress struct {
+	progress.Reporter
+	done  int
+	total int
+}
+
+func (p offsetProgress) Update(event progress.Event) {
+	event.Done += p.done
+	event.Total = p.total
+	p.Reporter.Update(event)
+}
+
+// processSquashed processes commits one at a time, except that consecutive
+// commits matching the same mapping with squash_window_minutes, each
+// committed within that many minutes of the previous one, are coalesced
+// into one batched update, so a burst of fixups makes one doc edit.
+func (u *Updater) processSquashed(ctx context.Context, runID string, commitHashes []string, dryRun bool) Summary {
+	if !u.squashConfigured() {
+		return u.processSequential(ctx, runID, commitHashes, dryRun)
+	}
+	segments := u.squashSegments(runID, commitHashes)
+	if len(segments) == 1 && !segments[0].squash {
+		return u.processSequential(ctx, runID, commitHashes, dryRun)
+	}
+
+	var summary Summary
+	done := 0
+	for _, segment := range segments {
+		runner := u
+		if segment.squash {
+			runner = segment.updater
+			_ = u.deps.State.LogRunEvent(runID, segment.hashes[len(segment.hashes)-1], "info", "orchestrator", "commits squashed into one update", map[string]any{"commits": segment.hashes})
+		}
+		reporter := runner.deps.Progress
+		runner.deps.Progress = offsetProgress{Reporter: reporter, done: done, total: len(commitHashes)}
+		var part Summary
+		if segment.squash {
+			part = runner.processBatch(ctx, runID, segment.hashes, dryRun)
+		} else {
+			part = runner.processSequential(ctx, runID, segment.hashes, dryRun)
+		}
+		runner.deps.Progress = reporter
+		done += len(segment.hashes)
+
+		summary.Processed += part.Processed
+		summary.Success += part.Success
+		summary.Failed += part.Failed
+		summary.Skipped += part.Skipped
+		summary.Pending += part.Pending
+		summary.Interrupted = summary.Interrupted || part.Interrupted
+	}
+	return summary
+}
+
+// squashConfigured reports whether any mapping, of the root config or a
+// workspace, sets squash_window_minutes, so runs without one skip reading
+// every commit up front.
+func (u *Updater) squashConfigured() bool {
+	for _, mapping := range u.deps.Config.Mappings {
+		if mapping.SquashWindowMinutes > 0 {
+			return true
+		}
+	}
+	for _, ws := range u.deps.Config.Workspaces {
+		if ws.Resolved == nil {
+			continue
+		}
+		for _, mapping := range ws.Resolved.Mappings {
+			if mapping.SquashWindowMinutes > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// squashSegments splits commitHashes into squash windows and runs of
+// commits processed one at a time, keeping their order.
+func (u *Updater) squashSegments(runID string, commitHashes []string) []squashSegment {
+	type squashKey struct {
+		updater *Updater
+		mapping config.Mapping
+	}
+	var segments []squashSegment
+	var prev squashKey
+	var prevAt time.Time
+	prevOK := false
+	for _, hash := range commitHashes {
+		key, at, ok := squashKey{}, time.Time{}, false
+		if target, err := u.forCommit(runID, hash); err == nil {
+			key.updater = target
+			key.mapping, at, ok = target.squashMapping(hash)
+		}
+
+		if n := len(segments); ok && prevOK && key == prev && absDuration(at.Sub(prevAt)) <= time.Duration(key.mapping.SquashWindowMinutes)*time.Minute {
+			segments[n-1].hashes = append(segments[n-1].hashes, hash)
+			segments[n-1].squash = true
+		} else {
+			segments = append(segments, squashSegment{updater: key.updater, hashes: []string{hash}})
+		}
+		prev, prevAt, prevOK = key, at, ok
+	}
+
+	merged := segments[:0]
+	for _, segment := range segments {
+		if n := len(merged); n > 0 && !segment.squash && !merged[n-1].squash {
+			merged[n-1].hashes = append(merged[n-1].hashes, segment.hashes...)
+			continue
+		}
+		merged = append(merged, segment)
+	}
+	return merged
+}
+
+// squashMapping returns the mapping a commit routes to and its commit time
+// when the mapping has a squash window.
+func (u *Updater) squashMapping(hash string) (config.Mapping, time.Time, bool) {
+	files, _, skipReason, err := u.commitFiles(hash)
+	if err != nil || skipReason != "" || len(files) == 0 {
+		return config.Mapping{}, time.Time{}, false
+	}
+	message, err := u.commitMessage(hash)
+	if err != nil {
+		return config.Mapping{}, time.Time{}, false
+	}
+	mapping, ok := u.matchMapping(files, commitclass.Parse(message))
+	if !ok || mapping.SquashWindowMinutes <= 0 {
+		return config.Mapping{}, time.Time{}, false
+	}
+	info, err := u.commitInfo(hash)
+	if err != nil {
+		return config.Mapping{}, time.Time{}, false
+	}
+	return mapping, info.Timestamp, true
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
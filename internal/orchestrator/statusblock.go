@@ -0,0 +1,66 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+)
+
+const statusBlockName = "status"
+
+// trackedFileLister is implemented by git helpers that can list the files in
+// the index, which the status block needs for mapping coverage.
+type trackedFileLister interface {
+	ListTrackedFiles() ([]string, error)
+}
+
+// statusBlock renders the status block body: the last code commit whose docs
+// were synced, when, and how many tracked source files mappings cover.
+func (u *Updater) statusBlock() (string, error) {
+	last, err := u.deps.State.GetLastProcessedCommit()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if last == "" {
+		b.WriteString("_Docs not synced by git-doc yet._")
+	} else {
+		row, _, err := u.deps.State.GetProcessedCommit(last)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "_Docs synced by git-doc through `%s` on %s._", shortHash(last), row.ProcessedAt.UTC().Format("2006-01-02"))
+	}
+
+	if lister, ok := u.deps.Git.(trackedFileLister); ok && len(u.deps.Config.Mappings) > 0 {
+		files, err := lister.ListTrackedFiles()
+		if err != nil {
+			return "", err
+		}
+		rules, err := u.ignoreRules()
+		if err != nil {
+			return "", err
+		}
+		mapped, total := u.mappingCoverage(rules.relevantFiles(files))
+		if total > 0 {
+			fmt.Fprintf(&b, " _Mappings cover %d of %d tracked source files (%d%%)._", mapped, total, mapped*100/total)
+		}
+	}
+	return b.String(), nil
+}
+
+// mappingCoverage counts the files that are not doc files, and how many of
+// them a mapping's code_pattern matches.
+func (u *Updater) mappingCoverage(files []string) (int, int) {
+	mapped, total := 0, 0
+	for _, file := range files {
+		if u.isDocFile(file) {
+			continue
+		}
+		total++
+		if u.mappedPath(file) {
+			mapped++
+		}
+	}
+	return mapped, total
+}
@@ -0,0 +1,48 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	diffanalyzer "github.com/kowshik24/git-doc/internal/diff"
+)
+
+// styleGuide returns the configured style guide, read once per updater. A
+// missing file means no guide.
+func (u *Updater) styleGuide() (string, error) {
+	if u.styleGuideRead {
+		return u.styleGuideText, nil
+	}
+
+	name := strings.TrimSpace(u.deps.Config.Prompts.StyleGuide)
+	if name != "" {
+		path := name
+		if !filepath.IsAbs(path) {
+			repoRoot, err := u.deps.Git.GetRepoRoot()
+			if err != nil {
+				return "", err
+			}
+			path = filepath.Join(repoRoot, path)
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("read style guide %s: %w", name, err)
+		}
+		u.styleGuideText = diffanalyzer.TruncateText(strings.TrimSpace(string(raw)), u.deps.Config.Prompts.StyleGuideMaxChars)
+	}
+	u.styleGuideRead = true
+	return u.styleGuideText, nil
+}
+
+// withStyleGuide appends the style guide to a rendered prompt, so templates
+// do not need to repeat it.
+func (u *Updater) withStyleGuide(prompt string) (string, error) {
+	guide, err := u.styleGuide()
+	if err != nil || guide == "" {
+		return prompt, err
+	}
+	return strings.TrimRight(prompt, "\n") + "\n\nFollow this style guide (tone, tense, terminology):\n" + guide + "\n", nil
+}
@@ -0,0 +1,60 @@
+package orchestrator
+
+import (
+	"slices"
+	"strings"
+
+	diffanalyzer "github.com/kowshik24/git-doc/internal/diff"
+)
+
+// submoduleReader is implemented by git helpers that can read the history of
+// a checked-out submodule.
+type submoduleReader interface {
+	GetSubmoduleChanges(path, fromHash, toHash string) ([]string, string, error)
+}
+
+// expandSubmodules replaces submodule updates in a commit's files and diff.
+// Submodules listed in submodules.recurse contribute the files and diff
+// between their old and new commit; other updates are dropped and logged
+// with the reason. A commit left with no files gets a skip reason.
+func (u *Updater) expandSubmodules(runID, hash string, files []string, diffContent string) ([]string, string, string) {
+	rest, updates := diffanalyzer.SplitSubmodules(diffContent)
+	if len(updates) == 0 {
+		return files, diffContent, ""
+	}
+
+	isSubmodule := func(file string) bool {
+		return slices.ContainsFunc(updates, func(update diffanalyzer.SubmoduleUpdate) bool { return update.Path == file })
+	}
+	kept := slices.DeleteFunc(slices.Clone(files), isSubmodule)
+	diffs := []string{rest}
+	var skipped []string
+	for _, update := range updates {
+		reason := "not listed in submodules.recurse"
+		if slices.Contains(u.deps.Config.Submodules.Recurse, update.Path) {
+			reader, ok := u.deps.Git.(submoduleReader)
+			switch {
+			case !ok:
+				reason = "git helper cannot read submodules"
+			case update.From == "" || update.To == "":
+				reason = "submodule added or removed"
+			default:
+				innerFiles, innerDiff, err := reader.GetSubmoduleChanges(update.Path, update.From, update.To)
+				if err == nil {
+					kept = append(kept, innerFiles...)
+					diffs = append(diffs, innerDiff)
+					_ = u.deps.State.LogRunEvent(runID, hash, "info", "orchestrator", "submodule update expanded", map[string]any{"path": update.Path, "from": update.From, "to": update.To, "files": len(innerFiles)})
+					continue
+				}
+				reason = err.Error()
+			}
+		}
+		skipped = append(skipped, update.Path)
+		_ = u.deps.State.LogRunEvent(runID, hash, "info", "orchestrator", "submodule update skipped", map[string]any{"path": update.Path, "reason": reason})
+	}
+
+	if len(kept) == 0 {
+		return nil, "", "only submodule updates (" + strings.Join(skipped, ", ") + ")"
+	}
+	return kept, strings.Join(slices.DeleteFunc(diffs, func(d string) bool { return strings.TrimSpace(d) == "" }), "\n"), ""
+}
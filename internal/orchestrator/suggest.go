@@ -0,0 +1,171 @@
+package orchestrator
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/config"
+	"github.com/kowshik24/git-doc/internal/doc"
+)
+
+// changeSetLister is implemented by git helpers that can list the files
+// changed by each commit of the history in one call.
+type changeSetLister interface {
+	GetChangeSets(limit int) ([][]string, error)
+}
+
+// suggestDirDepth is how many leading directories of a code path make up a
+// suggested code_pattern or a coverage directory, so internal/cli/root.go suggests internal/cli/**.
+const suggestDirDepth = 2
+
+// MappingSuggestion is a proposed [[mappings]] entry with the history behind
+// it: CoChanges of the Commits touching the code pattern also changed the
+// doc file. NewSection is set when the doc has no matching section yet.
+type MappingSuggestion struct {
+	config.Mapping
+	CoChanges  int
+	Commits    int
+	NewSection bool
+}
+
+// Confidence is the share of the code pattern's commits that changed the doc.
+func (s MappingSuggestion) Confidence() float64 {
+	if s.Commits == 0 {
+		return 0
+	}
+	return float64(s.CoChanges) / float64(s.Commits)
+}
+
+// SuggestMappings reads the last limit commits and proposes a mapping for
+// each code directory that changed together with one doc of the inventory
+// in at least minCoChanges commits and at least minConfidence of its
+// commits. Directories an existing mapping or ignore.paths already covers
+// are left out. The section is the doc heading named like the directory,
+// or a new section titled after it.
+func (u *Updater) SuggestMappings(limit, minCoChanges int, minConfidence float64) ([]MappingSuggestion, error) {
+	lister, ok := u.deps.Git.(changeSetLister)
+	if !ok {
+		return nil, fmt.Errorf("git helper cannot list commit history")
+	}
+	repoRoot, err := u.deps.Git.GetRepoRoot()
+	if err != nil {
+		return nil, err
+	}
+	inventory, err := u.docInventory(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	sections := make(map[string][]doc.Section, len(inventory))
+	for _, entry := range inventory {
+		sections[entry.File] = entry.Sections
+	}
+	rules, err := u.ignoreRules()
+	if err != nil {
+		return nil, err
+	}
+	sets, err := lister.GetChangeSets(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := map[string]int{}
+	coChanges := map[string]map[string]int{}
+	for _, files := range sets {
+		var docs []string
+		dirs := map[string]bool{}
+		for _, file := range rules.relevantFiles(files) {
+			if _, ok := sections[file]; ok {
+				docs = append(docs, file)
+				continue
+			}
+			if dir, ok := codeDir(file); ok && dir != "." && !u.mappedPath(file) {
+				dirs[dir] = true
+			}
+		}
+		for dir := range dirs {
+			commits[dir]++
+			for _, docFile := range docs {
+				if coChanges[dir] == nil {
+					coChanges[dir] = map[string]int{}
+				}
+				coChanges[dir][docFile]++
+			}
+		}
+	}
+
+	var out []MappingSuggestion
+	for dir, docs := range coChanges {
+		var best MappingSuggestion
+		for docFile, count := range docs {
+			if count > best.CoChanges || (count == best.CoChanges && docFile < best.DocFile) {
+				best = MappingSuggestion{Mapping: config.Mapping{CodePattern: dir + "/**", DocFile: docFile}, CoChanges: count, Commits: commits[dir]}
+			}
+		}
+		if best.CoChanges < minCoChanges || best.Confidence() < minConfidence {
+			continue
+		}
+		best.Section, best.NewSection = suggestSection(sections[best.DocFile], path.Base(dir))
+		out = append(out, best)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CoChanges != out[j].CoChanges {
+			return out[i].CoChanges > out[j].CoChanges
+		}
+		return out[i].CodePattern < out[j].CodePattern
+	})
+	return out, nil
+}
+
+// codeDir returns the leading directories of file that a suggested
+// code_pattern covers, or "." for files at the repository root. Prose and
+// files in hidden directories are not code.
+func codeDir(file string) (string, bool) {
+	switch strings.ToLower(path.Ext(file)) {
+	case ".md", ".markdown", ".rst", ".txt", ".adoc":
+		return "", false
+	}
+	parts := strings.Split(file, "/")
+	if strings.HasPrefix(parts[0], ".") {
+		return "", false
+	}
+	if len(parts) == 1 {
+		return ".", true
+	}
+	parts = parts[:len(parts)-1]
+	if len(parts) > suggestDirDepth {
+		parts = parts[:suggestDirDepth]
+	}
+	return strings.Join(parts, "/"), true
+}
+
+func (u *Updater) mappedPath(file string) bool {
+	for _, mapping := range u.deps.Config.Mappings {
+		if strings.TrimSpace(mapping.CodePattern) != "" && matchCodePattern(mapping.CodePattern, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestSection returns the heading of sections named after dir, or whose
+// first word is dir ("API reference" for api), preferring the shallowest;
+// else a new title made from dir.
+func suggestSection(sections []doc.Section, dir string) (string, bool) {
+	name := strings.ToLower(dir)
+	var found *doc.Section
+	for i, section := range sections {
+		words := strings.Fields(strings.ToLower(section.Title))
+		if section.Anchor != name && (len(words) == 0 || words[0] != name) {
+			continue
+		}
+		if found == nil || section.Level < found.Level {
+			found = &sections[i]
+		}
+	}
+	if found != nil {
+		return found.Title, false
+	}
+	return docTitle(dir), true
+}
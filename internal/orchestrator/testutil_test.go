@@ -1,6 +1,7 @@
 package orchestrator
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -15,9 +16,11 @@ import (
 
 type fakeGitHelper struct {
 	repoRoot    string
+	authors     map[string]string
 	head        string
 	commitRange []gitutil.CommitInfo
 	changed     map[string][]string
+	parents     map[string][]string
 	messages    map[string]string
 	diffs       map[string]string
 	stageCalled int
@@ -25,6 +28,9 @@ type fakeGitHelper struct {
 	rangeFrom   string
 	rangeTo     string
 	seenDiffFor []string
+	reverted    []string
+	commitErr   error
+	committed   []string
 }
 
 func (f *fakeGitHelper) GetRepoRoot() (string, error) {
@@ -50,13 +56,36 @@ func (f *fakeGitHelper) GetCommitMessage(commit string) (string, error) {
 	return f.messages[commit], nil
 }
 
+func (f *fakeGitHelper) GetCommitInfo(commit string) (gitutil.CommitInfo, error) {
+	return gitutil.CommitInfo{
+		Hash:    commit,
+		Author:  "bot",
+		Email:   f.authors[commit],
+		Subject: f.messages[commit],
+	}, nil
+}
+
 func (f *fakeGitHelper) GetChangedFiles(commit string) ([]string, error) {
 	return f.changed[commit], nil
 }
 
+func (f *fakeGitHelper) GetCommitParents(commit string) ([]string, error) {
+	return f.parents[commit], nil
+}
+
+func (f *fakeGitHelper) GetChangedFilesBetween(fromHash, toHash string) ([]string, error) {
+	return f.changed[toHash], nil
+}
+
+func (f *fakeGitHelper) GetDiffBetween(fromHash, toHash string) (string, error) {
+	f.seenDiffFor = append(f.seenDiffFor, fromHash+".."+toHash)
+	return f.diffs[toHash], nil
+}
+
 func (f *fakeGitHelper) StageAndCommit(files []string, message string) (string, error) {
 	f.stageCalled++
-	return "", nil
+	f.committed = append(f.committed, message)
+	return "", f.commitErr
 }
 
 func (f *fakeGitHelper) StageAndAmend(files []string) (string, error) {
@@ -65,6 +94,7 @@ func (f *fakeGitHelper) StageAndAmend(files []string) (string, error) {
 }
 
 func (f *fakeGitHelper) RevertCommit(commit string) error {
+	f.reverted = append(f.reverted, commit)
 	return nil
 }
 
@@ -108,3 +138,21 @@ func sampleRangeCommit(hash string) []gitutil.CommitInfo {
 		Subject:   "range commit",
 	}}
 }
+
+type recordingLLM struct {
+	prompts  []string
+	response string
+}
+
+func (r *recordingLLM) Name() string {
+	return "recording"
+}
+
+func (r *recordingLLM) Generate(ctx context.Context, prompt string) (string, error) {
+	_ = ctx
+	r.prompts = append(r.prompts, prompt)
+	if r.response == "" {
+		return "- recorded update", nil
+	}
+	return r.response, nil
+}
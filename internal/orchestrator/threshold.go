@@ -0,0 +1,47 @@
+package orchestrator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/config"
+	diffanalyzer "github.com/kowshik24/git-doc/internal/diff"
+)
+
+// thresholdReason returns a "below threshold" skip reason when the files a
+// mapping's code_pattern matches changed fewer lines than its min_lines, or
+// are fewer than its min_files. Mappings matching on type or scope alone
+// count every changed file. An unparsable diff is never below threshold.
+func thresholdReason(mapping config.Mapping, changedFiles []string, diff string) string {
+	if mapping.MinLines <= 0 && mapping.MinFiles <= 0 {
+		return ""
+	}
+
+	var files []string
+	for _, file := range changedFiles {
+		if strings.TrimSpace(mapping.CodePattern) == "" || matchCodePattern(mapping.CodePattern, file) {
+			files = append(files, file)
+		}
+	}
+	lines := 0
+	if mapping.MinLines > 0 {
+		parsed, err := diffanalyzer.ParseUnifiedDiff(diff)
+		if err != nil {
+			return ""
+		}
+		for _, file := range parsed.Files {
+			if slices.Contains(files, file.Path) {
+				lines += file.AddedLines + file.DelLines
+			}
+		}
+	}
+
+	switch {
+	case len(files) < mapping.MinFiles:
+		return fmt.Sprintf("below threshold: %d changed files, mapping min_files is %d", len(files), mapping.MinFiles)
+	case lines < mapping.MinLines:
+		return fmt.Sprintf("below threshold: %d changed lines, mapping min_lines is %d", lines, mapping.MinLines)
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/config"
+	"github.com/kowshik24/git-doc/internal/doc"
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+// translateSection regenerates section of every locale translating docFile
+// from the updated source body and writes the result through tx. A locale
+// that fails is logged and left as it was; the source update still goes
+// through. It returns the translated sections written.
+func (u *Updater) translateSection(ctx context.Context, runID, hash, repoRoot, docFile, section, body string, tx *doc.Transaction) []state.TranslatedSection {
+	if !u.deps.Config.Translations.Enabled {
+		return nil
+	}
+
+	var out []state.TranslatedSection
+	for _, locale := range u.deps.Config.Translations.Locales {
+		if locale.Source != docFile {
+			continue
+		}
+		translated, err := u.translateLocale(ctx, runID, hash, repoRoot, locale, section, body, tx)
+		if err != nil {
+			_ = u.deps.State.LogRunEvent(runID, hash, "warn", "translations", "translation failed", map[string]any{"doc_file": locale.DocFile, "section": translated, "error": err.Error()})
+			continue
+		}
+		out = append(out, state.TranslatedSection{DocFile: locale.DocFile, Section: translated})
+		_ = u.deps.State.LogRunEvent(runID, hash, "info", "translations", "section translated", map[string]any{"doc_file": locale.DocFile, "section": translated, "language": locale.Language})
+	}
+	return out
+}
+
+func (u *Updater) translateLocale(ctx context.Context, runID, hash, repoRoot string, locale config.TranslationLocale, section, body string, tx *doc.Transaction) (string, error) {
+	target := section
+	if title, ok := locale.Sections[section]; ok && strings.TrimSpace(title) != "" {
+		target = title
+	}
+
+	path := filepath.Join(repoRoot, locale.DocFile)
+	raw, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return target, err
+	}
+	content := string(raw)
+
+	existing := ""
+	if current, err := u.deps.DocUpdater.ExtractSection(content, target); err == nil {
+		existing = strings.TrimSpace(current)
+	}
+
+	generated, err := u.generateSection(ctx, runID, hash, locale.DocFile, target, translationPrompt(locale, section, target, body, existing))
+	if err != nil {
+		return target, err
+	}
+	generated, err = sanitizeGeneratedSection(generated, target, doc.SectionLevel(content, target))
+	if err != nil {
+		return target, err
+	}
+	if err := u.checkPolicy(runID, hash, generated); err != nil {
+		return target, err
+	}
+
+	updated, err := u.deps.DocUpdater.ReplaceSection(content, target, generated)
+	if err != nil {
+		return target, err
+	}
+	updated = doc.RegenerateTOC(updated, u.deps.Config.TOC.MaxLevel)
+	if content == "" {
+		updated = strings.TrimLeft(updated, "\n")
+	} else {
+		updated = doc.NormalizeLineEndings(updated, doc.DetectLineEnding(content))
+	}
+	return target, tx.Write(path, []byte(updated), 0o644)
+}
+
+func translationPrompt(locale config.TranslationLocale, section, target, body, existing string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Translate the Markdown section %q of %s into %s for the section %q of %s.\n", section, locale.Source, locale.Language, target, locale.DocFile)
+	b.WriteString("Keep the Markdown structure, code blocks, inline code, links, commit hashes and identifiers unchanged.\n\n")
+	fmt.Fprintf(&b, "Source section:\n%s\n\n", strings.TrimSpace(body))
+	if existing != "" {
+		fmt.Fprintf(&b, "Current translation (reuse its terminology):\n%s\n\n", existing)
+	}
+	b.WriteString("Return only the translated section body, without the heading.\n")
+	return b.String()
+}
+
+func translatedFiles(sections []state.TranslatedSection) []string {
+	files := make([]string, 0, len(sections))
+	for _, s := range sections {
+		files = append(files, s.DocFile)
+	}
+	return files
+}
@@ -5,62 +5,150 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
-	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"text/template"
 	"time"
 
+	"github.com/kowshik24/git-doc/internal/commitclass"
 	"github.com/kowshik24/git-doc/internal/config"
 	diffanalyzer "github.com/kowshik24/git-doc/internal/diff"
 	"github.com/kowshik24/git-doc/internal/doc"
 	"github.com/kowshik24/git-doc/internal/gitutil"
 	"github.com/kowshik24/git-doc/internal/llm"
+	"github.com/kowshik24/git-doc/internal/notify"
+	"github.com/kowshik24/git-doc/internal/policy"
+	"github.com/kowshik24/git-doc/internal/progress"
+	"github.com/kowshik24/git-doc/internal/prompts"
 	"github.com/kowshik24/git-doc/internal/state"
+	"github.com/kowshik24/git-doc/internal/tracing"
 )
 
 type Dependencies struct {
 	Config     *config.Config
 	Git        gitutil.Helper
-	State      *state.Store
+	State      state.Backend
 	DocUpdater doc.Updater
 	LLM        llm.Client
+	// Planner answers target planning calls when llm.plan_targets is set;
+	// typically a cheaper model than LLM.
+	Planner llm.Client
+	// Embedder enables section retrieval when embeddings.enabled is set.
+	Embedder llm.Embedder
+	// NewLLM builds clients for workspaces that override the llm settings;
+	// when nil they share LLM.
+	NewLLM   func(*config.Config) (llm.Client, error)
+	Progress progress.Reporter
+	// Tracer records spans for each run; nil disables tracing.
+	Tracer *tracing.Tracer
+	// Notifier posts run summaries and failure alerts; nil disables them.
+	Notifier *notify.Notifier
+	// Mailer sends the scheduled email digest; nil disables it.
+	Mailer *notify.Mailer
+	// SharedCache is the cross-repository response cache from cache.dir;
+	// nil when unset.
+	SharedCache *state.SharedCache
+	// Confirm asks a yes/no question for human_edits.policy = "confirm";
+	// nil in non-interactive runs.
+	Confirm func(question string) (bool, error)
 }
 
 type Updater struct {
-	deps Dependencies
+	deps         Dependencies
+	promptLoader *prompts.Loader
+	ignore       *ignoreRules
+	policy       *policy.Engine
+	redactor     *policy.Redactor
+	trigger      string
+	llmLatency   time.Duration
+	amended      gitutil.Rewrite
+	workspaces   map[string]*Updater
+	ensemble     []*Updater
+	judge        *Updater
+	writers      map[config.ProviderConfig]*Updater
+	metadata     map[string]gitutil.CommitMetadata
+	// failureStreak holds the consecutive failures of the current run.
+	failureStreak []notify.Failure
+
+	styleGuideRead bool
+	styleGuideText string
 }
 
 type Summary struct {
+	RunID     string
 	Processed int
 	Success   int
 	Failed    int
 	Skipped   int
+	// Pending counts commits left pending because the run was interrupted;
+	// they are picked up again by the next update.
+	Pending          int
+	Interrupted      bool
+	DeadlineExceeded bool
+}
+
+type breakerObservable interface {
+	OnBreakerChange(fn func(llm.BreakerEvent))
 }
 
 func NewUpdater(deps Dependencies) *Updater {
-	return &Updater{deps: deps}
+	if deps.Progress == nil {
+		deps.Progress = progress.Nop{}
+	}
+	return &Updater{deps: deps, trigger: "manual"}
+}
+
+func (u *Updater) SetTrigger(trigger string) {
+	if strings.TrimSpace(trigger) == "" {
+		trigger = "manual"
+	}
+	u.trigger = trigger
 }
 
 func (u *Updater) UpdateNewCommits(ctx context.Context, dryRun bool) (Summary, error) {
-	resumableCommits, err := u.deps.State.GetResumableCommits()
+	commitHashes, err := u.NewCommits()
 	if err != nil {
 		return Summary{}, err
 	}
 
-	last, err := u.deps.State.GetLastProcessedCommit()
+	return u.UpdateCommitList(ctx, commitHashes, dryRun)
+}
+
+func (u *Updater) UpdateRangeCommits(ctx context.Context, fromHash, toHash string, dryRun bool) (Summary, error) {
+	commitHashes, err := u.RangeCommits(fromHash, toHash)
 	if err != nil {
 		return Summary{}, err
 	}
 
+	return u.UpdateCommitList(ctx, commitHashes, dryRun)
+}
+
+// NewCommits returns resumable commits followed by commits after the last
+// processed one, which is what UpdateNewCommits would process.
+func (u *Updater) NewCommits() ([]string, error) {
+	resumableCommits, err := u.deps.State.GetResumableCommits()
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := u.deps.State.GetLastProcessedCommit()
+	if err != nil {
+		return nil, err
+	}
+
 	head, err := u.deps.Git.GetCurrentHEAD()
 	if err != nil {
-		return Summary{}, err
+		return nil, err
 	}
 
+	last, err = u.historyFrom(last)
+	if err != nil {
+		return nil, err
+	}
 	commits, err := u.deps.Git.GetLastProcessedRange(last, head)
 	if err != nil {
-		return Summary{}, err
+		return nil, err
 	}
 
 	commitHashes := make([]string, 0, len(commits))
@@ -68,24 +156,26 @@ func (u *Updater) UpdateNewCommits(ctx context.Context, dryRun bool) (Summary, e
 		commitHashes = append(commitHashes, c.Hash)
 	}
 
-	commitHashes = mergeUnique(resumableCommits, commitHashes)
-
-	return u.UpdateCommitList(ctx, commitHashes, dryRun)
+	return mergeUnique(resumableCommits, commitHashes), nil
 }
 
-func (u *Updater) UpdateRangeCommits(ctx context.Context, fromHash, toHash string, dryRun bool) (Summary, error) {
+func (u *Updater) RangeCommits(fromHash, toHash string) ([]string, error) {
 	toCommit := strings.TrimSpace(toHash)
 	if toCommit == "" {
 		head, err := u.deps.Git.GetCurrentHEAD()
 		if err != nil {
-			return Summary{}, err
+			return nil, err
 		}
 		toCommit = head
 	}
 
-	commits, err := u.deps.Git.GetLastProcessedRange(strings.TrimSpace(fromHash), toCommit)
+	fromCommit, err := u.historyFrom(strings.TrimSpace(fromHash))
 	if err != nil {
-		return Summary{}, err
+		return nil, err
+	}
+	commits, err := u.deps.Git.GetLastProcessedRange(fromCommit, toCommit)
+	if err != nil {
+		return nil, err
 	}
 
 	commitHashes := make([]string, 0, len(commits))
@@ -93,15 +183,127 @@ func (u *Updater) UpdateRangeCommits(ctx context.Context, fromHash, toHash strin
 		commitHashes = append(commitHashes, commit.Hash)
 	}
 
-	return u.UpdateCommitList(ctx, commitHashes, dryRun)
+	return commitHashes, nil
 }
 
 func (u *Updater) UpdateCommitList(ctx context.Context, commitHashes []string, dryRun bool) (Summary, error) {
-	summary := Summary{}
 	runID := fmt.Sprintf("run-%d", time.Now().UnixNano())
+	if err := u.deps.State.StartRun(runID, u.trigger, dryRun); err != nil {
+		return Summary{}, err
+	}
 	_ = u.deps.State.LogRunEvent(runID, "", "info", "orchestrator", "update loop started", map[string]any{"commits": len(commitHashes)})
 
-	for _, hash := range commitHashes {
+	if observable, ok := u.deps.LLM.(breakerObservable); ok {
+		observable.OnBreakerChange(func(event llm.BreakerEvent) {
+			u.logBreakerEvent(runID, event)
+		})
+		defer observable.OnBreakerChange(nil)
+	}
+
+	u.prefetchMetadata(runID, commitHashes)
+	defer func() { u.metadata = nil }()
+	u.failureStreak = nil
+
+	runCtx, runSpan := u.deps.Tracer.Start(ctx, "update_run")
+	runSpan.SetAttr("git_doc.run_id", runID)
+	runSpan.SetAttr("git_doc.trigger", u.trigger)
+	runSpan.SetAttr("git_doc.commits", len(commitHashes))
+	if seconds := u.deps.Config.Runtime.RunDeadline; seconds > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(runCtx, time.Duration(seconds)*time.Second)
+		defer cancel()
+	}
+
+	u.deps.Progress.Start(len(commitHashes))
+	var summary Summary
+	if u.deps.Config.Runtime.BatchCommits && len(commitHashes) > 1 {
+		summary = u.processBatch(runCtx, runID, commitHashes, dryRun)
+	} else {
+		summary = u.processSquashed(runCtx, runID, commitHashes, dryRun)
+	}
+	summary.DeadlineExceeded = summary.Interrupted && ctx.Err() == nil
+	u.postRunDocs(runID, commitHashes, dryRun)
+	u.deps.Progress.Finish()
+	summary.RunID = runID
+
+	_ = u.deps.State.LogRunEvent(runID, "", "info", "orchestrator", "update loop finished", map[string]any{
+		"processed": summary.Processed,
+		"success":   summary.Success,
+		"failed":    summary.Failed,
+		"skipped":   summary.Skipped,
+	})
+	u.applyRetention(runID)
+	status := "finished"
+	switch {
+	case summary.DeadlineExceeded:
+		status = "deadline_exceeded"
+		_ = u.deps.State.LogRunEvent(runID, "", "warn", "orchestrator", "run deadline exceeded", map[string]any{"pending": summary.Pending, "deadline_seconds": u.deps.Config.Runtime.RunDeadline})
+	case summary.Interrupted:
+		status = "interrupted"
+		_ = u.deps.State.LogRunEvent(runID, "", "warn", "orchestrator", "update loop interrupted", map[string]any{"pending": summary.Pending})
+	}
+	_ = u.deps.State.FinishRun(runID, status, state.RunCounts{
+		Processed: summary.Processed,
+		Success:   summary.Success,
+		Failed:    summary.Failed,
+		Skipped:   summary.Skipped,
+	})
+	runSpan.SetAttr("git_doc.status", status)
+	runSpan.SetAttr("git_doc.success", summary.Success)
+	runSpan.SetAttr("git_doc.failed", summary.Failed)
+	runSpan.End(nil)
+	u.flushTraces(ctx, runID)
+	if !dryRun {
+		u.notifyRun(ctx, runID, commitHashes, summary)
+		u.sendDigestIfDue(runID)
+	}
+
+	return summary, nil
+}
+
+// flushTraces exports the run's spans. It runs after an interrupt too, so it
+// gets its own short deadline instead of the run's context.
+func (u *Updater) flushTraces(ctx context.Context, runID string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if err := u.deps.Tracer.Flush(ctx); err != nil {
+		_ = u.deps.State.LogRunEvent(runID, "", "warn", "tracing", "span export failed", map[string]any{"error": err.Error()})
+	}
+}
+
+// applyRetention prunes state older than state.retention_days. Failures are
+// logged but never fail the run.
+func (u *Updater) applyRetention(runID string) {
+	days := u.deps.Config.State.RetentionDays
+	if days <= 0 {
+		return
+	}
+
+	result, err := u.deps.State.Prune(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		_ = u.deps.State.LogRunEvent(runID, "", "warn", "state", "retention prune failed", map[string]any{"error": err.Error()})
+		return
+	}
+	if result.RunEvents+result.Runs+result.LLMCache+result.PromptLog > 0 {
+		_ = u.deps.State.LogRunEvent(runID, "", "info", "state", "retention prune finished", map[string]any{
+			"run_events": result.RunEvents,
+			"runs":       result.Runs,
+			"llm_cache":  result.LLMCache,
+			"prompt_log": result.PromptLog,
+		})
+	}
+}
+
+func (u *Updater) processSequential(ctx context.Context, runID string, commitHashes []string, dryRun bool) Summary {
+	summary := Summary{}
+
+	for i, hash := range commitHashes {
+		if ctx.Err() != nil {
+			summary.Interrupted = true
+			summary.Pending += u.leavePending(runID, commitHashes[i:])
+			break
+		}
+
 		summary.Processed++
 		if err := u.deps.State.MarkCommitProcessed(hash, "pending", "", "", nil); err != nil {
 			summary.Failed++
@@ -109,17 +311,49 @@ func (u *Updater) UpdateCommitList(ctx context.Context, commitHashes []string, d
 			continue
 		}
 
-		status, err := u.processSingleCommit(ctx, runID, hash, dryRun)
+		target, err := u.forCommit(runID, hash)
 		if err != nil {
 			summary.Failed++
-			_ = u.deps.State.MarkCommitProcessed(hash, "failed", err.Error(), "", nil)
-			_ = u.deps.State.LogRunEvent(runID, hash, "error", "orchestrator", "commit processing failed", map[string]any{"error": err.Error()})
+			u.markFailed(runID, hash, "workspace config failed", err)
+			continue
+		}
+
+		target.llmLatency = 0
+		commitCtx, cancel := u.withCommitTimeout(ctx)
+		commitCtx, span := u.deps.Tracer.Start(commitCtx, "process_commit")
+		span.SetAttr("git.commit", hash)
+		status, err := target.processSingleCommit(commitCtx, runID, hash, dryRun)
+		err = u.timeoutError(commitCtx, ctx, err)
+		span.SetAttr("git_doc.status", status)
+		span.End(err)
+		cancel()
+		u.deps.Progress.Update(progress.Event{
+			Done:       summary.Processed,
+			Total:      len(commitHashes),
+			Commit:     hash,
+			Status:     status,
+			LLMLatency: target.llmLatency,
+		})
+		if err != nil && ctx.Err() != nil {
+			summary.Interrupted = true
+			summary.Pending += u.leavePending(runID, []string{hash})
+			continue
+		}
+		if errors.Is(err, llm.ErrOffline) {
+			summary.Pending++
+			u.leaveOfflinePending(runID, hash, err)
+			continue
+		}
+		if err != nil {
+			summary.Failed++
+			u.markFailed(runID, hash, "commit processing failed", err)
 			continue
 		}
 
 		switch status {
 		case "success":
 			summary.Success++
+			u.failureStreak = nil
 		case "skipped":
 			summary.Skipped++
 		default:
@@ -127,14 +361,42 @@ func (u *Updater) UpdateCommitList(ctx context.Context, commitHashes []string, d
 		}
 	}
 
-	_ = u.deps.State.LogRunEvent(runID, "", "info", "orchestrator", "update loop finished", map[string]any{
-		"processed": summary.Processed,
-		"success":   summary.Success,
-		"failed":    summary.Failed,
-		"skipped":   summary.Skipped,
-	})
+	return summary
+}
 
-	return summary, nil
+// withCommitTimeout bounds one commit, or one batch group, by
+// runtime.commit_timeout.
+func (u *Updater) withCommitTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if seconds := u.deps.Config.Runtime.CommitTimeout; seconds > 0 {
+		return context.WithTimeout(ctx, time.Duration(seconds)*time.Second)
+	}
+	return context.WithCancel(ctx)
+}
+
+// timeoutError names the timeout when err came from the commit's own
+// deadline rather than the run being cancelled, so the failure reason says so.
+func (u *Updater) timeoutError(commitCtx, runCtx context.Context, err error) error {
+	if err == nil || runCtx.Err() != nil || !errors.Is(commitCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("commit timed out after %ds: %w", u.deps.Config.Runtime.CommitTimeout, err)
+}
+
+// leaveOfflinePending keeps a commit that needs a network provider pending
+// in offline mode, with the reason, so the next online run processes it.
+func (u *Updater) leaveOfflinePending(runID, hash string, err error) {
+	_ = u.deps.State.MarkCommitProcessed(hash, "pending", err.Error(), "", nil)
+	_ = u.deps.State.LogRunEvent(runID, hash, "info", "orchestrator", "commit left pending: offline", map[string]any{"reason": err.Error()})
+}
+
+// leavePending resets commits an interrupted run did not finish to pending,
+// so the next update resumes them instead of reporting them as failed.
+func (u *Updater) leavePending(runID string, hashes []string) int {
+	for _, hash := range hashes {
+		_ = u.deps.State.MarkCommitProcessed(hash, "pending", "", "", nil)
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "orchestrator", "commit left pending after interrupt", nil)
+	}
+	return len(hashes)
 }
 
 func (u *Updater) processSingleCommit(ctx context.Context, runID, hash string, dryRun bool) (string, error) {
@@ -142,88 +404,81 @@ func (u *Updater) processSingleCommit(ctx context.Context, runID, hash string, d
 		return "failed", err
 	}
 
-	changedFiles, err := u.deps.Git.GetChangedFiles(hash)
+	_, span := u.deps.Tracer.Start(ctx, "git.prepare")
+	prepared, err := u.prepareCommit(ctx, runID, hash, true)
+	span.SetAttr("git.changed_files", len(prepared.changedFiles))
+	span.SetAttr("git_doc.doc_file", prepared.docFile)
+	span.SetAttr("git_doc.section", prepared.section)
+	span.End(err)
 	if err != nil {
+		if prepared.docRaw != nil {
+			_ = u.deps.State.UpsertPlannedUpdate(hash, prepared.docFile, prepared.section, "inferred", "failed", err.Error())
+		}
 		return "failed", err
 	}
 
-	if len(changedFiles) == 0 {
+	if prepared.skipReason != "" {
+		if prepared.irrelevant {
+			_ = u.deps.State.UpsertPlannedUpdate(hash, prepared.docFile, prepared.section, "inferred", "skipped", prepared.skipReason)
+			_ = u.deps.State.LogRunEvent(runID, hash, "info", "orchestrator", "commit skipped as irrelevant to docs", map[string]any{"reason": prepared.skipReason})
+		} else if prepared.ownerSkip {
+			_ = u.deps.State.UpsertPlannedUpdate(hash, prepared.docFile, prepared.section, "inferred", "skipped", prepared.skipReason)
+			_ = u.deps.State.LogRunEvent(runID, hash, "info", "orchestrator", "commit skipped by owners policy", map[string]any{"reason": prepared.skipReason})
+		} else if prepared.belowThreshold {
+			_ = u.deps.State.UpsertPlannedUpdate(hash, prepared.docFile, prepared.section, "inferred", "skipped", prepared.skipReason)
+			_ = u.deps.State.LogRunEvent(runID, hash, "info", "orchestrator", "commit skipped below mapping threshold", map[string]any{"reason": prepared.skipReason})
+		} else if len(prepared.changedFiles) > 0 {
+			_ = u.deps.State.LogRunEvent(runID, hash, "info", "orchestrator", "commit skipped by ignore rule", map[string]any{"reason": prepared.skipReason})
+		}
 		if err := u.deps.State.MarkCommitProcessed(hash, "skipped", "", "", nil); err != nil {
 			return "failed", err
 		}
 		return "skipped", nil
 	}
 
-	commitMessage, err := u.deps.Git.GetCommitMessage(hash)
-	if err != nil {
-		return "failed", err
-	}
+	targetDocFile, targetSection := prepared.docFile, prepared.section
+	docPath := filepath.Join(prepared.repoRoot, targetDocFile)
+	docRaw := prepared.docRaw
+	prompt := prepared.prompt
 
-	diffContent, err := u.deps.Git.GetCommitDiff(hash)
-	if err != nil {
-		return "failed", err
+	if err := u.deps.State.UpsertPlannedUpdate(hash, targetDocFile, targetSection, "inferred", "planned", ""); err != nil {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "state", "failed to persist planned update", map[string]any{"error": err.Error()})
 	}
 
-	targetDocFile, targetSection := u.resolveTarget(changedFiles)
-	repoRoot, err := u.deps.Git.GetRepoRoot()
+	newSection, err := u.generateFor(ctx, runID, hash, targetDocFile, targetSection, prompt, prepared.mapping)
+	if errors.Is(err, llm.ErrOffline) {
+		_ = u.deps.State.UpsertPlannedUpdate(hash, targetDocFile, targetSection, "inferred", "pending", err.Error())
+		return "pending", err
+	}
 	if err != nil {
+		_ = u.deps.State.UpsertPlannedUpdate(hash, targetDocFile, targetSection, "inferred", "failed", err.Error())
 		return "failed", err
 	}
 
-	docPath := filepath.Join(repoRoot, targetDocFile)
-	docRaw, err := os.ReadFile(docPath)
+	newSection, err = u.finalizeSection(newSection, string(docRaw), targetSection, prepared.mapping)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return "failed", fmt.Errorf("target doc file not found: %s", targetDocFile)
-		}
+		_ = u.deps.State.UpsertPlannedUpdate(hash, targetDocFile, targetSection, "inferred", "failed", err.Error())
 		return "failed", err
 	}
 
-	if err := u.deps.State.UpsertPlannedUpdate(hash, targetDocFile, targetSection, "inferred", "planned", ""); err != nil {
-		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "state", "failed to persist planned update", map[string]any{"error": err.Error()})
-	}
-
-	prompt := buildPrompt(commitMessage, diffContent)
-	providerName := u.deps.LLM.Name()
-	modelName := u.deps.Config.LLM.Model
-	promptHash := hashPrompt(prompt)
-
-	newSection, cached, cacheErr := u.deps.State.GetCachedLLMResponse(hash, targetDocFile, targetSection, providerName, modelName, prompt)
-	if cacheErr != nil {
-		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "state", "failed to read llm cache", map[string]any{"error": cacheErr.Error()})
+	if err := u.checkPolicy(runID, hash, newSection); err != nil {
+		_ = u.deps.State.UpsertPlannedUpdate(hash, targetDocFile, targetSection, "inferred", "failed", err.Error())
+		return "failed", err
 	}
-
-	if !cached {
-		newSection, err = u.deps.LLM.Generate(ctx, prompt)
-		if err != nil {
+	if prepared.mapping.Render == "" {
+		if err := u.factCheck(runID, hash, prepared.repoRoot, newSection, prepared.message, prepared.diff, string(docRaw)); err != nil {
 			_ = u.deps.State.UpsertPlannedUpdate(hash, targetDocFile, targetSection, "inferred", "failed", err.Error())
 			return "failed", err
 		}
-
-		_ = u.deps.State.PutCachedLLMResponse(state.LLMCacheEntry{
-			CommitHash: hash,
-			DocFile:    targetDocFile,
-			SectionID:  targetSection,
-			Provider:   providerName,
-			Model:      modelName,
-			PromptHash: promptHash,
-			Response:   newSection,
-		})
-	} else {
-		_ = u.deps.State.LogRunEvent(runID, hash, "info", "llm", "cache hit", map[string]any{"doc_file": targetDocFile, "section": targetSection})
-	}
-
-	if err := validateGeneratedSection(newSection); err != nil {
-		_ = u.deps.State.UpsertPlannedUpdate(hash, targetDocFile, targetSection, "inferred", "failed", err.Error())
-		return "failed", err
 	}
 
-	updated, err := u.deps.DocUpdater.ReplaceSection(string(docRaw), targetSection, newSection)
+	updated, err := u.placeSection(runID, hash, targetDocFile, string(docRaw), targetSection, newSection, prepared.mapping)
 	if err != nil {
 		_ = u.deps.State.UpsertPlannedUpdate(hash, targetDocFile, targetSection, "inferred", "failed", err.Error())
 		return "failed", err
 	}
 
+	updated = doc.RegenerateTOC(updated, u.deps.Config.TOC.MaxLevel)
 	lineEnding := doc.DetectLineEnding(string(docRaw))
 	updated = doc.NormalizeLineEndings(updated, lineEnding)
 
@@ -235,59 +490,532 @@ func (u *Updater) processSingleCommit(ctx context.Context, runID, hash string, d
 		return "skipped", nil
 	}
 
+	linkFixes, err := u.checkLinks(runID, hash, prepared.repoRoot, targetDocFile, string(docRaw), updated, nil)
+	if err != nil {
+		_ = u.deps.State.UpsertPlannedUpdate(hash, targetDocFile, targetSection, "inferred", "failed", err.Error())
+		return "failed", err
+	}
+	if fixed, ok := linkFixes[targetDocFile]; ok {
+		updated = fixed
+		delete(linkFixes, targetDocFile)
+	}
+
 	if dryRun {
-		_ = u.deps.State.UpsertPlannedUpdate(hash, targetDocFile, targetSection, "inferred", "applied", "dry-run")
+		_ = u.deps.State.UpsertPlannedUpdate(hash, targetDocFile, targetSection, "inferred", state.PlannedDryRun, "dry-run")
+		u.recordPlannedContent(runID, hash, targetDocFile, targetSection, string(docRaw), updated)
 		if err := u.deps.State.MarkCommitProcessed(hash, "success", "", "", []string{targetDocFile}); err != nil {
 			return "failed", err
 		}
 		return "success", nil
 	}
 
-	if err := doc.AtomicWriteFile(docPath, []byte(updated), 0o644); err != nil {
-		_ = u.deps.State.UpsertPlannedUpdate(hash, targetDocFile, targetSection, "inferred", "failed", err.Error())
+	queued, err := u.queueForApproval(runID, hash, targetDocFile, targetSection, "inferred", string(docRaw), updated)
+	if err != nil {
 		return "failed", err
 	}
+	if queued {
+		return "skipped", nil
+	}
 
-	docCommitHash := ""
-	if u.deps.Config.Git.CommitDocUpdates {
-		if u.deps.Config.Git.AmendOriginal {
-			docCommitHash, err = u.deps.Git.StageAndAmend([]string{targetDocFile})
-		} else {
-			msg := strings.ReplaceAll(u.deps.Config.Git.DocCommitMessage, "{hash}", hash)
-			docCommitHash, err = u.deps.Git.StageAndCommit([]string{targetDocFile}, msg)
-		}
-		if err != nil {
-			return "failed", err
+	tx := doc.NewTransaction()
+	_, span = u.deps.Tracer.Start(ctx, "doc.write")
+	span.SetAttr("git_doc.doc_file", targetDocFile)
+	err = tx.Write(docPath, []byte(updated), 0o644)
+	for _, file := range mapKeys(linkFixes) {
+		if err == nil {
+			err = tx.Write(filepath.Join(prepared.repoRoot, filepath.FromSlash(file)), []byte(linkFixes[file]), 0o644)
 		}
 	}
+	span.End(err)
+	if err != nil {
+		u.rollbackDocs(runID, hash, tx)
+		_ = u.deps.State.UpsertPlannedUpdate(hash, targetDocFile, targetSection, "inferred", "failed", err.Error())
+		return "failed", err
+	}
+
+	var translations []state.TranslatedSection
+	if prepared.mapping.Render == "" {
+		translations = u.translateSection(ctx, runID, hash, prepared.repoRoot, targetDocFile, targetSection, newSection, tx)
+	}
+	docFiles := append(append([]string{targetDocFile}, mapKeys(linkFixes)...), translatedFiles(translations)...)
 
-	if err := u.deps.State.MarkCommitProcessed(hash, "success", "", docCommitHash, []string{targetDocFile}); err != nil {
+	_, span = u.deps.Tracer.Start(ctx, "git.commit")
+	docCommitHash, err := u.commitDocFiles(runID, docFiles, []string{targetSection}, []string{hash})
+	span.SetAttr("git_doc.doc_commit", docCommitHash)
+	span.End(err)
+	if err != nil {
+		u.rollbackDocs(runID, hash, tx)
+		_ = u.deps.State.UpsertPlannedUpdate(hash, targetDocFile, targetSection, "inferred", "failed", err.Error())
 		return "failed", err
 	}
+	if docCommitHash != "" {
+		tx.Commit()
+	}
 
-	if err := u.deps.State.StoreMapping(hash, targetDocFile, targetSection); err != nil {
+	if err := u.deps.State.CompleteCommit(state.CompletedCommit{
+		CommitHash:   hash,
+		DocCommit:    docCommitHash,
+		DocFiles:     docFiles,
+		DocFile:      targetDocFile,
+		Section:      targetSection,
+		Strategy:     "inferred",
+		Mapped:       true,
+		Translations: translations,
+	}); err != nil {
+		u.rollbackDocs(runID, hash, tx)
 		return "failed", err
 	}
 
-	_ = u.deps.State.UpsertPlannedUpdate(hash, targetDocFile, targetSection, "inferred", "applied", "")
+	tx.Commit()
+	u.recordPlannedContent(runID, hash, targetDocFile, targetSection, string(docRaw), updated)
+	u.recordGenerated(runID, hash, targetDocFile, updated, targetSection)
+	if prepared.mapping.Render == "" {
+		u.recordExemplar(runID, hash, targetDocFile, targetSection, prepared.message, prepared.changedFiles, newSection)
+	}
+	u.applyAmendRewrite(runID)
 
 	return "success", nil
 }
 
-func (u *Updater) resolveTarget(changedFiles []string) (string, string) {
+type preparedCommit struct {
+	changedFiles   []string
+	skipReason     string
+	irrelevant     bool
+	ownerSkip      bool
+	belowThreshold bool
+	repoRoot       string
+	docFile        string
+	section        string
+	docRaw         []byte
+	prompt         string
+	mapping        config.Mapping
+	message        string
+	diff           string
+}
+
+// prepareCommit resolves the target section and renders the prompt for a
+// commit without writing any state, so updates and estimates share it. A
+// non-empty skipReason means the commit needs no LLM call. plan allows a
+// planner call to pick the target; estimates pass false.
+func (u *Updater) prepareCommit(ctx context.Context, runID, hash string, plan bool) (preparedCommit, error) {
+	var prepared preparedCommit
+
+	changedFiles, parents, skipReason, err := u.commitFiles(hash)
+	if err != nil {
+		return prepared, err
+	}
+	if skipReason != "" {
+		prepared.skipReason = skipReason
+		return prepared, nil
+	}
+
+	if len(changedFiles) == 0 {
+		prepared.skipReason = "no changed files"
+		return prepared, nil
+	}
+	prepared.changedFiles = changedFiles
+
+	commitMessage, err := u.commitMessage(hash)
+	if err != nil {
+		return prepared, err
+	}
+	prepared.message = commitMessage
+
+	skipReason, err = u.ignoreReason(hash, commitMessage, changedFiles)
+	if err != nil {
+		return prepared, err
+	}
+	if skipReason != "" {
+		prepared.skipReason = skipReason
+		return prepared, nil
+	}
+	changedFiles = u.ignore.relevantFiles(changedFiles)
+
+	diffContent, err := u.commitDiff(hash, parents)
+	if err != nil {
+		return prepared, err
+	}
+	changedFiles, diffContent, skipReason = u.expandSubmodules(runID, hash, changedFiles, diffContent)
+	if skipReason != "" {
+		prepared.changedFiles = nil
+		prepared.skipReason = skipReason
+		return prepared, nil
+	}
+	changedFiles = u.ignore.relevantFiles(changedFiles)
+	prepared.changedFiles = changedFiles
+	commitMessage, diffContent, err = u.redactInputs(runID, hash, commitMessage, diffContent)
+	if err != nil {
+		return prepared, err
+	}
+	prepared.message = commitMessage
+	prepared.diff = diffContent
+
+	class := commitclass.Parse(commitMessage)
+	prepared.repoRoot, err = u.deps.Git.GetRepoRoot()
+	if err != nil {
+		return prepared, err
+	}
+	if reason := u.relevanceReason(diffContent); reason != "" {
+		prepared.docFile, prepared.section = u.resolveTarget(changedFiles, class)
+		prepared.skipReason = reason
+		prepared.irrelevant = true
+		return prepared, nil
+	}
+	if plan {
+		prepared.docFile, prepared.section = u.targetFor(ctx, runID, hash, prepared.repoRoot, commitMessage, changedFiles, diffContent, class)
+	} else {
+		prepared.docFile, prepared.section = u.resolveTarget(changedFiles, class)
+	}
+	if reason := u.neverReason(prepared.docFile); reason != "" {
+		prepared.skipReason = reason
+		prepared.ownerSkip = true
+		return prepared, nil
+	}
+
+	mapping, _ := u.matchMapping(changedFiles, class)
+	if reason := thresholdReason(mapping, changedFiles, diffContent); reason != "" {
+		prepared.skipReason = reason
+		prepared.belowThreshold = true
+		return prepared, nil
+	}
+	docRaw, err := u.readTargetDoc(prepared.repoRoot, prepared.docFile, prepared.section, mapping)
+	if err != nil {
+		return prepared, err
+	}
+	prepared.docRaw = docRaw
+	prepared.mapping = mapping
+
+	if mapping.Render == config.RenderMermaidArch {
+		commits := fmt.Sprintf("- %s %s", shortHash(hash), firstLine(commitMessage))
+		prepared.prompt = u.diagramPrompt(mapping, prepared.docFile, prepared.section, string(docRaw), commits, changedFiles, summarizeDiff(diffContent, u.diffOptions()))
+		return prepared, nil
+	}
+
+	existingSection, fullDoc := u.promptDocContext(string(docRaw), prepared.section)
+	tmpl, err := u.loadPromptTemplate(prepared.repoRoot, mapping.PromptTemplate)
+	if err != nil {
+		return prepared, err
+	}
+
+	prepared.prompt, err = buildPrompt(tmpl, prompts.Data{
+		CommitHash:      hash,
+		ShortHash:       shortHash(hash),
+		CommitMessage:   commitMessage,
+		CommitType:      class.Type,
+		CommitScope:     class.Scope,
+		Breaking:        class.Breaking,
+		DocFile:         prepared.docFile,
+		Section:         prepared.section,
+		ExistingSection: existingSection,
+		FullDoc:         fullDoc,
+		Examples:        u.similarExamples(hash, prepared.docFile, prepared.section, commitMessage, changedFiles),
+		Repo:            prompts.RepoInfo{Name: filepath.Base(prepared.repoRoot), Root: prepared.repoRoot},
+	}, diffContent, u.diffOptions())
+	return prepared, err
+}
+
+// rollbackDocs restores doc files written by tx after a later step failed, so
+// the working tree holds no changes that state does not record.
+func (u *Updater) rollbackDocs(runID, hash string, tx *doc.Transaction) {
+	files := tx.Files()
+	if len(files) == 0 {
+		return
+	}
+	if err := tx.Rollback(); err != nil {
+		_ = u.deps.State.LogRunEvent(runID, hash, "error", "doc", "doc rollback failed", map[string]any{"files": files, "error": err.Error()})
+		return
+	}
+	_ = u.deps.State.LogRunEvent(runID, hash, "warn", "doc", "doc changes rolled back", map[string]any{"files": files})
+}
+
+// commitDocFiles commits docFiles, which update sections for the source
+// commits hashes, or amends them into HEAD.
+func (u *Updater) commitDocFiles(runID string, docFiles, sections, hashes []string) (string, error) {
+	if !u.deps.Config.Git.CommitDocUpdates {
+		return "", nil
+	}
+	if u.deps.Config.Git.AmendOriginal {
+		head, err := u.deps.Git.GetCurrentHEAD()
+		if err != nil {
+			return "", err
+		}
+		amended, err := u.deps.Git.StageAndAmend(docFiles)
+		if err != nil {
+			return "", err
+		}
+		u.amended = gitutil.Rewrite{Old: head, New: amended}
+		return amended, nil
+	}
+	msg, err := u.docCommitMessage(runID, docFiles, sections, hashes)
+	if err != nil {
+		return "", err
+	}
+	return u.deps.Git.StageAndCommit(docFiles, msg)
+}
+
+func (u *Updater) generateSection(ctx context.Context, runID, hash, docFile, section, prompt string) (string, error) {
+	return u.generate(ctx, runID, hash, "generate", docFile, section, prompt)
+}
+
+// generate sends a section prompt to the provider, through the caches.
+// purpose labels the call in the llm call event and the prompt log.
+func (u *Updater) generate(ctx context.Context, runID, hash, purpose, docFile, section, prompt string) (string, error) {
+	prompt, err := u.withStyleGuide(prompt)
+	if err != nil {
+		return "", err
+	}
+
+	structured := u.deps.Config.LLM.StructuredOutput
+	if structured {
+		prompt = strings.TrimRight(prompt, "\n") + "\n\n" + llm.StructuredOutputInstructions
+	}
+
+	providerName := u.deps.LLM.Name()
+	modelName := u.deps.Config.LLM.Model
+
+	_, span := u.deps.Tracer.Start(ctx, "llm.cache_lookup")
+	newSection, cached, cacheErr := u.deps.State.GetCachedLLMResponse(hash, docFile, section, providerName, modelName, prompt)
+	if cacheErr != nil {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "state", "failed to read llm cache", map[string]any{"error": cacheErr.Error()})
+	}
+
+	if cached {
+		span.SetAttr("git_doc.cache_hit", "commit")
+		span.End(nil)
+		_ = u.deps.State.LogRunEvent(runID, hash, "info", "llm", "cache hit", map[string]any{"doc_file": docFile, "section": section})
+		return newSection, nil
+	}
+
+	if newSection, source := u.contentCached(runID, hash, providerName, modelName, prompt); source != "" {
+		span.SetAttr("git_doc.cache_hit", source)
+		span.End(nil)
+		_ = u.deps.State.LogRunEvent(runID, hash, "info", "llm", source+" cache hit", map[string]any{"doc_file": docFile, "section": section})
+		u.cacheResponse(hash, docFile, section, providerName, modelName, prompt, newSection)
+		return newSection, nil
+	}
+	span.SetAttr("git_doc.cache_hit", "miss")
+	span.End(nil)
+
+	_, span = u.deps.Tracer.Start(ctx, "llm.generate")
+	span.SetAttr("llm.provider", providerName)
+	span.SetAttr("llm.model", modelName)
+	started := time.Now()
+	newSection, err = u.deps.LLM.Generate(ctx, prompt)
+	u.llmLatency = time.Since(started)
+	u.logPrompt(runID, hash, purpose, docFile, section, providerName, modelName, prompt, err)
+	if err != nil {
+		span.End(err)
+		return "", err
+	}
+
+	provider := u.deps.Config.LLM.Provider
+	inputTokens, outputTokens := llm.EstimateTokensFor(provider, prompt), llm.EstimateTokensFor(provider, newSection)
+	span.SetAttr("llm.input_tokens", inputTokens)
+	span.SetAttr("llm.output_tokens", outputTokens)
+	span.End(nil)
+	_ = u.deps.State.LogRunEvent(runID, hash, "info", "llm", "llm call", map[string]any{
+		"purpose":       purpose,
+		"provider":      provider,
+		"model":         modelName,
+		"input_tokens":  inputTokens,
+		"output_tokens": outputTokens,
+		"latency_ms":    u.llmLatency.Milliseconds(),
+	})
+
+	if structured {
+		newSection, err = u.decodeStructuredSection(runID, hash, newSection)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	u.cacheResponse(hash, docFile, section, providerName, modelName, prompt, newSection)
+	if u.deps.SharedCache != nil {
+		if err := u.deps.SharedCache.Put(u.sharedNamespace(providerName, modelName), prompt, newSection); err != nil {
+			_ = u.deps.State.LogRunEvent(runID, hash, "warn", "state", "failed to write shared cache", map[string]any{"error": err.Error()})
+		}
+	}
+	return newSection, nil
+}
+
+// contentCached looks up a response cached for an identical prompt by any
+// commit, first in the state DB and then in the shared cache, and names the
+// cache that answered ("content" or "shared"), or "" on a miss.
+func (u *Updater) contentCached(runID, hash, provider, model, prompt string) (string, string) {
+	hours := u.deps.Config.Cache.ContentTTLHours
+	if hours <= 0 {
+		return "", ""
+	}
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+
+	response, ok, err := u.deps.State.GetCachedResponseByPrompt(provider, model, prompt, since)
+	if err != nil {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "state", "failed to read llm cache", map[string]any{"error": err.Error()})
+	}
+	if ok {
+		return response, "content"
+	}
+
+	if u.deps.SharedCache == nil {
+		return "", ""
+	}
+	response, ok, err = u.deps.SharedCache.Get(u.sharedNamespace(provider, model), prompt, since)
+	if err != nil {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "state", "failed to read shared cache", map[string]any{"error": err.Error()})
+	}
+	if ok {
+		return response, "shared"
+	}
+	return "", ""
+}
+
+// sharedNamespace keys shared cache entries by provider, model and endpoint,
+// so repositories using different models or servers never share responses.
+func (u *Updater) sharedNamespace(provider, model string) string {
+	return provider + "|" + model + "|" + u.deps.Config.LLM.BaseURL
+}
+
+// logPrompt records a prompt sent to a provider for export-prompts, with its
+// text only when state.log_prompts is on.
+func (u *Updater) logPrompt(runID, hash, purpose, docFile, section, provider, model, prompt string, callErr error) {
+	if errors.Is(callErr, llm.ErrOffline) {
+		return
+	}
+	entry := state.PromptLogEntry{
+		RunID:      runID,
+		CommitHash: hash,
+		Purpose:    purpose,
+		DocFile:    docFile,
+		SectionID:  section,
+		Provider:   provider,
+		Model:      model,
+		Status:     "ok",
+	}
+	if u.deps.Config.State.LogPrompts {
+		entry.Prompt = prompt
+	} else {
+		entry.PromptHash = hashPrompt(prompt)
+	}
+	if callErr != nil {
+		entry.Status, entry.Error = "error", callErr.Error()
+	}
+	if err := u.deps.State.LogPrompt(entry); err != nil {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "state", "failed to log prompt", map[string]any{"error": err.Error()})
+	}
+}
+
+func (u *Updater) cacheResponse(hash, docFile, section, provider, model, prompt, response string) {
+	_ = u.deps.State.PutCachedLLMResponse(state.LLMCacheEntry{
+		CommitHash: hash,
+		DocFile:    docFile,
+		SectionID:  section,
+		Provider:   provider,
+		Model:      model,
+		PromptHash: hashPrompt(prompt),
+		Response:   response,
+	})
+}
+
+func (u *Updater) decodeStructuredSection(runID, hash, raw string) (string, error) {
+	response, err := llm.ParseSectionResponse(raw)
+	if err != nil {
+		return "", err
+	}
+
+	metadata := map[string]any{"summary": response.Summary, "confidence": response.Confidence}
+	if response.Confidence < u.deps.Config.LLM.MinConfidence {
+		_ = u.deps.State.LogRunEvent(runID, hash, "warn", "llm", "structured response rejected", metadata)
+		return "", fmt.Errorf("llm confidence %.2f is below min_confidence %.2f", response.Confidence, u.deps.Config.LLM.MinConfidence)
+	}
+
+	_ = u.deps.State.LogRunEvent(runID, hash, "info", "llm", "structured response accepted", metadata)
+	return response.SectionMarkdown, nil
+}
+
+func (u *Updater) logBreakerEvent(runID string, event llm.BreakerEvent) {
+	level := "info"
+	if event.State == llm.BreakerOpen {
+		level = "warn"
+	}
+
+	metadata := map[string]any{"provider": event.Provider, "failures": event.Failures}
+	if !event.Until.IsZero() {
+		metadata["until"] = event.Until.UTC().Format(time.RFC3339)
+	}
+	if event.Err != "" {
+		metadata["error"] = event.Err
+	}
+	_ = u.deps.State.LogRunEvent(runID, "", level, "llm", "circuit breaker "+event.State, metadata)
+}
+
+func (u *Updater) resolveTarget(changedFiles []string, class commitclass.Classification) (string, string) {
+	if mapping, ok := u.matchMapping(changedFiles, class); ok {
+		docFile := u.mappingDocFile(mapping, changedFiles)
+		return docFile, u.inventorySection(docFile, mapping.Section)
+	}
+
+	return u.defaultTarget(), u.deps.Config.Runtime.DefaultSection
+}
+
+// matchMapping prefers path-based mappings (optionally narrowed by type and
+// scope) and falls back to mappings that route on commit classification only.
+func (u *Updater) matchMapping(changedFiles []string, class commitclass.Classification) (config.Mapping, bool) {
 	for _, changed := range changedFiles {
 		for _, mapping := range u.deps.Config.Mappings {
-			if matchCodePattern(mapping.CodePattern, changed) {
-				return mapping.DocFile, mapping.Section
+			if strings.TrimSpace(mapping.CodePattern) == "" {
+				continue
+			}
+			if matchCodePattern(mapping.CodePattern, changed) && class.Matches(mapping.Type, mapping.Scope) {
+				return mapping, true
 			}
 		}
 	}
 
-	if len(u.deps.Config.DocFiles) > 0 {
-		return u.deps.Config.DocFiles[0], u.deps.Config.Runtime.DefaultSection
+	for _, mapping := range u.deps.Config.Mappings {
+		if strings.TrimSpace(mapping.CodePattern) != "" {
+			continue
+		}
+		if strings.TrimSpace(mapping.Type) == "" && strings.TrimSpace(mapping.Scope) == "" {
+			continue
+		}
+		if class.Matches(mapping.Type, mapping.Scope) {
+			return mapping, true
+		}
 	}
 
-	return "README.md", u.deps.Config.Runtime.DefaultSection
+	return config.Mapping{}, false
+}
+
+func (u *Updater) loadPromptTemplate(repoRoot, name string) (*template.Template, error) {
+	if u.promptLoader == nil {
+		dir := u.deps.Config.Prompts.Dir
+		if dir == "" {
+			dir = ".git-doc/prompts"
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(repoRoot, dir)
+		}
+		u.promptLoader = prompts.NewLoader(dir, u.deps.Config.Prompts.DefaultTemplate)
+	}
+	return u.promptLoader.Load(name)
+}
+
+func (u *Updater) promptDocContext(docContent, section string) (string, string) {
+	opts := u.deps.Config.Prompts
+
+	existingSection := ""
+	if opts.IncludeExistingSection {
+		if extracted, err := u.deps.DocUpdater.ExtractSection(docContent, section); err == nil {
+			existingSection = diffanalyzer.TruncateText(strings.TrimSpace(extracted), opts.ExistingSectionMaxChars)
+		}
+	}
+
+	fullDoc := ""
+	if opts.IncludeFullDoc {
+		fullDoc = diffanalyzer.TruncateText(strings.TrimSpace(docContent), opts.FullDocMaxChars)
+	}
+
+	return existingSection, fullDoc
 }
 
 func matchCodePattern(pattern, changedPath string) bool {
@@ -333,21 +1061,85 @@ func matchPathSegments(patternParts, pathParts []string) bool {
 	return matchPathSegments(patternParts[1:], pathParts[1:])
 }
 
-func buildPrompt(commitMessage, diff string) string {
-	diffContext := ""
+func buildPrompt(tmpl *template.Template, data prompts.Data, diff string, opts diffanalyzer.Options) (string, error) {
+	data.DiffSummary = summarizeDiff(diff, opts)
+	if tmpl == nil {
+		return prompts.RenderDefault(data)
+	}
+	return prompts.Render(tmpl, data)
+}
+
+// summarizeDiff describes a diff for the prompt: per-file stats, the files
+// left out of the excerpt, then the largest hunks that fit opts.MaxChars.
+func summarizeDiff(diff string, opts diffanalyzer.Options) string {
 	parsed, err := diffanalyzer.ParseUnifiedDiff(diff)
-	if err == nil && len(parsed.Files) > 0 {
-		diffContext = diffanalyzer.BuildSummary(parsed)
-		diffContext = diffanalyzer.TruncateText(diffContext, 3000)
-	} else {
-		diffContext = diffanalyzer.TruncateText(diff, 3000)
+	if err != nil || len(parsed.Files) == 0 {
+		return diffanalyzer.TruncateText(diff, opts.MaxChars)
+	}
+
+	parts := []string{diffanalyzer.BuildSummary(parsed)}
+	kept, omitted := diffanalyzer.Filter(parsed, opts)
+	if len(omitted) > 0 {
+		names := make([]string, 0, len(omitted))
+		for _, file := range omitted {
+			names = append(names, fmt.Sprintf("%s (%s)", file.Path, file.Reason))
+		}
+		parts = append(parts, "Hunks omitted for: "+strings.Join(names, ", "))
+	}
+	summary := diffanalyzer.TruncateText(strings.Join(parts, "\n\n"), opts.MaxChars)
+
+	remaining := opts
+	remaining.MaxChars = opts.MaxChars - len(summary)
+	if remaining.MaxChars <= 0 {
+		return summary
+	}
+	if excerpt := diffanalyzer.Excerpt(kept, remaining); excerpt != "" {
+		summary += "\n\nChanged hunks:\n" + excerpt
+	}
+	return summary
+}
+
+// diffOptions returns the [diff] limits, with exclude_paths matched like
+// mapping code patterns.
+func (u *Updater) diffOptions() diffanalyzer.Options {
+	cfg := u.deps.Config.Diff
+	opts := diffanalyzer.Options{
+		MaxChars:     cfg.MaxChars,
+		MaxFileChars: cfg.MaxFileChars,
+		MaxFileLines: cfg.MaxFileLines,
+	}
+	if opts.MaxChars <= 0 {
+		opts = diffanalyzer.DefaultOptions()
+	}
+	if len(cfg.ExcludePaths) > 0 {
+		patterns := cfg.ExcludePaths
+		opts.Exclude = func(path string) bool {
+			for _, pattern := range patterns {
+				if matchCodePattern(pattern, path) {
+					return true
+				}
+			}
+			return false
+		}
 	}
+	return opts
+}
 
-	return fmt.Sprintf(
-		"Update docs for this commit.\nCommit message: %s\nDiff:\n%s\nOutput updated section content only.",
-		commitMessage,
-		diffContext,
-	)
+// sharedDiffOptions splits the diff budget between n commits summarized in
+// one prompt.
+func (u *Updater) sharedDiffOptions(n int) diffanalyzer.Options {
+	opts := u.diffOptions()
+	if n > 1 {
+		opts.MaxChars /= n
+	}
+	return opts
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
 }
 
 func mergeUnique(first []string, second []string) []string {
@@ -379,6 +1171,39 @@ func mergeUnique(first []string, second []string) []string {
 	return out
 }
 
+func (u *Updater) checkPolicy(runID, hash, content string) error {
+	if u.policy == nil {
+		engine, err := policy.New(u.deps.Config.Policy)
+		if err != nil {
+			return err
+		}
+		u.policy = engine
+	}
+
+	violations := u.policy.Check(content)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	rules := make([]string, 0, len(violations))
+	for _, v := range violations {
+		rules = append(rules, v.Rule)
+	}
+	_ = u.deps.State.LogRunEvent(runID, hash, "warn", "policy", "generated content blocked", map[string]any{"rules": rules})
+	return errors.New(policy.Describe(violations))
+}
+
+func sanitizeGeneratedSection(content, section string, level int) (string, error) {
+	sanitized, err := doc.SanitizeSection(content, section, level)
+	if err != nil {
+		return "", err
+	}
+	if err := validateGeneratedSection(sanitized); err != nil {
+		return "", err
+	}
+	return sanitized, nil
+}
+
 func validateGeneratedSection(content string) error {
 	trimmed := strings.TrimSpace(content)
 	if trimmed == "" {
@@ -3,12 +3,18 @@ package orchestrator
 import (
 	"testing"
 
+	"github.com/kowshik24/git-doc/internal/commitclass"
 	"github.com/kowshik24/git-doc/internal/config"
+	diffanalyzer "github.com/kowshik24/git-doc/internal/diff"
+	"github.com/kowshik24/git-doc/internal/prompts"
 )
 
 func TestBuildPromptUsesDiffSummaryWhenParseable(t *testing.T) {
 	diff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1,1 +1,2 @@\n-line1\n+line1\n+line2\n"
-	prompt := buildPrompt("feat: update", diff)
+	prompt, err := buildPrompt(nil, prompts.Data{CommitMessage: "feat: update"}, diff, diffanalyzer.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if !contains(prompt, "Files changed:") {
 		t.Fatalf("expected prompt to include parsed diff summary, got: %s", prompt)
@@ -17,13 +23,38 @@ func TestBuildPromptUsesDiffSummaryWhenParseable(t *testing.T) {
 
 func TestBuildPromptFallsBackToRawDiff(t *testing.T) {
 	diff := "this-is-not-a-unified-diff"
-	prompt := buildPrompt("feat: update", diff)
+	prompt, err := buildPrompt(nil, prompts.Data{CommitMessage: "feat: update"}, diff, diffanalyzer.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if !contains(prompt, diff) {
 		t.Fatalf("expected prompt to include raw diff fallback")
 	}
 }
 
+func TestBuildPromptOmitsExcludedAndBinaryHunks(t *testing.T) {
+	diff := "diff --git a/go.sum b/go.sum\n--- a/go.sum\n+++ b/go.sum\n@@ -1 +1 @@\n-old-sum\n+new-sum\n" +
+		"diff --git a/logo.png b/logo.png\nBinary files a/logo.png and b/logo.png differ\n" +
+		"diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@ func main()\n-println(1)\n+println(2)\n"
+
+	u := NewUpdater(Dependencies{Config: config.Default()})
+	prompt, err := buildPrompt(nil, prompts.Data{CommitMessage: "feat: update"}, diff, u.diffOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !contains(prompt, "+println(2)") {
+		t.Fatalf("expected main.go hunk in prompt, got: %s", prompt)
+	}
+	if contains(prompt, "new-sum") {
+		t.Fatalf("expected go.sum hunk to be excluded, got: %s", prompt)
+	}
+	if !contains(prompt, "go.sum (excluded), logo.png (binary)") {
+		t.Fatalf("expected omitted files to be listed, got: %s", prompt)
+	}
+}
+
 func TestMatchCodePattern_Globs(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -84,12 +115,46 @@ func TestResolveTarget_UsesFirstMatchingMapping(t *testing.T) {
 		},
 	}
 
-	docFile, section := u.resolveTarget([]string{"src/api/v2/payments/client.py"})
+	docFile, section := u.resolveTarget([]string{"src/api/v2/payments/client.py"}, commitclass.Classification{})
 	if docFile != "docs/api.md" || section != "API Reference" {
 		t.Fatalf("resolveTarget() = (%q, %q), want (%q, %q)", docFile, section, "docs/api.md", "API Reference")
 	}
 }
 
+func TestResolveTarget_RoutesOnConventionalCommitTypeAndScope(t *testing.T) {
+	u := &Updater{
+		deps: Dependencies{
+			Config: &config.Config{
+				DocFiles: []string{"README.md"},
+				Mappings: []config.Mapping{
+					{CodePattern: "src/**", Type: "fix", DocFile: "docs/fixes.md", Section: "Fixes"},
+					{Type: "feat", Scope: "api", DocFile: "docs/api.md", Section: "API"},
+					{Type: "feat", DocFile: "docs/features.md", Section: "Features"},
+				},
+				Runtime: config.RuntimeOptions{DefaultSection: "Recent Changes"},
+			},
+		},
+	}
+
+	tests := []struct {
+		message string
+		docFile string
+		section string
+	}{
+		{message: "feat(api): add endpoint", docFile: "docs/api.md", section: "API"},
+		{message: "feat(cli): add flag", docFile: "docs/features.md", section: "Features"},
+		{message: "fix: patch bug", docFile: "docs/fixes.md", section: "Fixes"},
+		{message: "chore: tidy", docFile: "README.md", section: "Recent Changes"},
+	}
+
+	for _, tc := range tests {
+		docFile, section := u.resolveTarget([]string{"src/main.go"}, commitclass.Parse(tc.message))
+		if docFile != tc.docFile || section != tc.section {
+			t.Fatalf("resolveTarget(%q) = (%q, %q), want (%q, %q)", tc.message, docFile, section, tc.docFile, tc.section)
+		}
+	}
+}
+
 func contains(s, sub string) bool {
 	for i := 0; i+len(sub) <= len(s); i++ {
 		if s[i:i+len(sub)] == sub {
@@ -0,0 +1,50 @@
+package orchestrator
+
+// forCommit returns the updater for the workspace containing every file the
+// commit changed, or u itself when the commit spans workspaces or the root.
+// Workspace updaters share state and git with u but carry their own config,
+// LLM client, prompt loader, ignore rules and policy.
+func (u *Updater) forCommit(runID, hash string) (*Updater, error) {
+	if len(u.deps.Config.Workspaces) == 0 {
+		return u, nil
+	}
+
+	files, _, _, err := u.commitFiles(hash)
+	if err != nil || len(files) == 0 {
+		return u, nil
+	}
+
+	ws := u.deps.Config.WorkspaceFor(files)
+	if ws == nil {
+		return u, nil
+	}
+
+	child, ok := u.workspaces[ws.Path]
+	if !ok {
+		deps := u.deps
+		deps.Config = ws.Resolved
+		if deps.NewLLM != nil {
+			client, err := deps.NewLLM(ws.Resolved)
+			if err != nil {
+				return nil, err
+			}
+			deps.LLM = client
+			if ws.Resolved.LLM.PlanTargets {
+				planner, err := deps.NewLLM(ws.Resolved.PlannerConfig())
+				if err != nil {
+					return nil, err
+				}
+				deps.Planner = planner
+			}
+		}
+		child = &Updater{deps: deps, trigger: u.trigger}
+		if u.workspaces == nil {
+			u.workspaces = map[string]*Updater{}
+		}
+		u.workspaces[ws.Path] = child
+	}
+
+	child.metadata = u.metadata
+	_ = u.deps.State.LogRunEvent(runID, hash, "debug", "orchestrator", "using workspace config", map[string]any{"workspace": ws.Path})
+	return child, nil
+}
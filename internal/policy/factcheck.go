@@ -0,0 +1,106 @@
+package policy
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Claim is an identifier, command-line flag or file path that generated
+// content mentions and that should exist in the code it documents.
+type Claim struct {
+	Kind string
+	Text string
+}
+
+var (
+	fencedBlockRe = regexp.MustCompile("(?ms)^\\s*(```|~~~).*?^\\s*(```|~~~)[^\\n]*$")
+	inlineCodeRe  = regexp.MustCompile("`([^`\\n]+)`")
+	flagRe        = regexp.MustCompile(`(?:^|[\s(\[])(--?[A-Za-z][\w-]*)`)
+	callRe        = regexp.MustCompile(`\b([A-Za-z_][\w]*(?:\.[A-Za-z_]\w*)*)\(\)`)
+	identifierRe  = regexp.MustCompile(`^[A-Za-z_]\w*(?:\.[A-Za-z_]\w*)*(?:\(\))?$`)
+	pathRe        = regexp.MustCompile(`^\.{0,2}/?[\w.-]+(?:/[\w.-]+)*/?$`)
+)
+
+var fileExtensions = map[string]bool{
+	".go": true, ".md": true, ".toml": true, ".yaml": true, ".yml": true, ".json": true,
+	".js": true, ".ts": true, ".py": true, ".rs": true, ".sh": true, ".sql": true, ".proto": true,
+}
+
+// ExtractClaims returns the flags, function calls, identifiers and file
+// paths in content, once each. Fenced code blocks are skipped; inline code
+// spans and bare flags or calls in prose are checked.
+func ExtractClaims(content string) []Claim {
+	content = fencedBlockRe.ReplaceAllString(content, "")
+
+	var claims []Claim
+	seen := map[Claim]bool{}
+	add := func(kind, text string) {
+		claim := Claim{Kind: kind, Text: text}
+		if len(text) < 3 || seen[claim] {
+			return
+		}
+		seen[claim] = true
+		claims = append(claims, claim)
+	}
+
+	for _, m := range inlineCodeRe.FindAllStringSubmatch(content, -1) {
+		span := strings.Trim(strings.TrimSpace(m[1]), `"'`)
+		switch {
+		case span == "" || strings.Contains(span, "://") || strings.ContainsAny(span, "*${}<>"):
+		case strings.ContainsAny(span, " \t") || strings.HasPrefix(span, "-"):
+			for _, f := range flagRe.FindAllStringSubmatch(span, -1) {
+				add("flag", f[1])
+			}
+		case strings.Contains(span, "/") || fileExtensions[path.Ext(span)]:
+			if pathRe.MatchString(span) {
+				add("path", strings.TrimPrefix(span, "./"))
+			}
+		case identifierRe.MatchString(span):
+			add("identifier", strings.TrimSuffix(span, "()"))
+		}
+	}
+
+	prose := inlineCodeRe.ReplaceAllString(content, "")
+	for _, f := range flagRe.FindAllStringSubmatch(prose, -1) {
+		if strings.HasPrefix(f[1], "--") {
+			add("flag", f[1])
+		}
+	}
+	for _, c := range callRe.FindAllStringSubmatch(prose, -1) {
+		add("identifier", c[1])
+	}
+	return claims
+}
+
+// Supported reports whether a claim appears in text. A dotted identifier
+// such as config.Default also matches its last segment.
+func (c Claim) Supported(text string) bool {
+	if strings.Contains(text, c.Text) {
+		return true
+	}
+	if c.Kind == "identifier" {
+		if i := strings.LastIndex(c.Text, "."); i >= 0 {
+			return strings.Contains(text, c.Text[i+1:])
+		}
+	}
+	return false
+}
+
+// Unsupported returns the claims that appear in none of the sources.
+func Unsupported(claims []Claim, sources ...string) []Claim {
+	var out []Claim
+	for _, claim := range claims {
+		supported := false
+		for _, source := range sources {
+			if claim.Supported(source) {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			out = append(out, claim)
+		}
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+type Violation struct {
+	Rule  string
+	Match string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s (%q)", v.Rule, v.Match)
+}
+
+type pattern struct {
+	rule string
+	re   *regexp.Regexp
+}
+
+type Engine struct {
+	patterns []pattern
+}
+
+var secretPatterns = []pattern{
+	{rule: "secret:aws_access_key", re: regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{rule: "secret:github_token", re: regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{rule: "secret:openai_key", re: regexp.MustCompile(`\bsk-(proj-|ant-)?[A-Za-z0-9_-]{20,}`)},
+	{rule: "secret:slack_token", re: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{rule: "secret:google_api_key", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{rule: "secret:private_key", re: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{rule: "secret:assignment", re: regexp.MustCompile(`(?i)\b(api[_-]?key|secret|token|passw(or)?d)\b\s*[:=]\s*["']?[A-Za-z0-9/+_\-]{16,}`)},
+}
+
+var defaultProfanity = []string{"fuck", "shit", "bitch", "bastard", "asshole", "cunt", "dickhead", "motherfucker"}
+
+func New(cfg config.PolicyConfig) (*Engine, error) {
+	engine := &Engine{}
+
+	if cfg.BlockSecrets {
+		engine.patterns = append(engine.patterns, secretPatterns...)
+	}
+
+	if cfg.BlockProfanity {
+		words := append([]string{}, defaultProfanity...)
+		words = append(words, cfg.ProfanityWords...)
+		quoted := make([]string, 0, len(words))
+		for _, word := range words {
+			if trimmed := strings.TrimSpace(word); trimmed != "" {
+				quoted = append(quoted, regexp.QuoteMeta(trimmed))
+			}
+		}
+		if len(quoted) > 0 {
+			re := regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\w*`)
+			engine.patterns = append(engine.patterns, pattern{rule: "profanity", re: re})
+		}
+	}
+
+	for _, phrase := range cfg.BannedPhrases {
+		if strings.TrimSpace(phrase) == "" {
+			continue
+		}
+		re, err := regexp.Compile(phrase)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy.banned_phrases pattern %q: %w", phrase, err)
+		}
+		engine.patterns = append(engine.patterns, pattern{rule: "banned_phrase", re: re})
+	}
+
+	return engine, nil
+}
+
+func (e *Engine) Check(content string) []Violation {
+	var violations []Violation
+	for _, p := range e.patterns {
+		if match := p.re.FindString(content); match != "" {
+			violations = append(violations, Violation{Rule: p.rule, Match: redact(p.rule, match)})
+		}
+	}
+	return violations
+}
+
+// redact keeps secrets out of planned_updates and run_events; only a short
+// prefix is recorded so the offending value can still be located.
+func redact(rule, match string) string {
+	if !strings.HasPrefix(rule, "secret:") || len(match) <= 8 {
+		return match
+	}
+	return match[:6] + "…"
+}
+
+func Describe(violations []Violation) string {
+	parts := make([]string, 0, len(violations))
+	for _, v := range violations {
+		parts = append(parts, v.String())
+	}
+	return "content policy violation: " + strings.Join(parts, ", ")
+}
@@ -0,0 +1,55 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+// Redactor masks secrets in commit messages and diffs before they are put
+// into a prompt.
+type Redactor struct {
+	patterns []pattern
+}
+
+// NewRedactor returns a Redactor for cfg, or nil when redaction is disabled.
+func NewRedactor(cfg config.RedactionConfig) (*Redactor, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	r := &Redactor{patterns: append([]pattern{}, secretPatterns...)}
+	for _, expr := range cfg.Patterns {
+		if strings.TrimSpace(expr) == "" {
+			continue
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction.patterns pattern %q: %w", expr, err)
+		}
+		r.patterns = append(r.patterns, pattern{rule: "custom", re: re})
+	}
+	return r, nil
+}
+
+// Redact replaces every match with a [REDACTED:<rule>] placeholder and
+// returns the rules that matched, once each. A nil Redactor returns text
+// unchanged.
+func (r *Redactor) Redact(text string) (string, []string) {
+	if r == nil {
+		return text, nil
+	}
+
+	var rules []string
+	for _, p := range r.patterns {
+		if !p.re.MatchString(text) {
+			continue
+		}
+		rule := strings.TrimPrefix(p.rule, "secret:")
+		text = p.re.ReplaceAllLiteralString(text, "[REDACTED:"+rule+"]")
+		rules = append(rules, rule)
+	}
+	return text, rules
+}
@@ -0,0 +1,128 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+type Event struct {
+	Done       int
+	Total      int
+	Commit     string
+	Status     string
+	LLMLatency time.Duration
+}
+
+type Reporter interface {
+	Start(total int)
+	Update(event Event)
+	Finish()
+}
+
+type Nop struct{}
+
+func (Nop) Start(int)    {}
+func (Nop) Update(Event) {}
+func (Nop) Finish()      {}
+
+type Bar struct {
+	out     io.Writer
+	tty     bool
+	width   int
+	now     func() time.Time
+	started time.Time
+	total   int
+	lastLen int
+}
+
+func NewBar(out io.Writer) *Bar {
+	return &Bar{out: out, tty: isTerminal(out), width: 30, now: time.Now}
+}
+
+func (b *Bar) Start(total int) {
+	b.started = b.now()
+	b.total = total
+	b.lastLen = 0
+}
+
+func (b *Bar) Update(event Event) {
+	if event.Total <= 0 {
+		event.Total = b.total
+	}
+	line := b.render(event)
+	if !b.tty {
+		fmt.Fprintln(b.out, line)
+		return
+	}
+
+	padding := ""
+	if len(line) < b.lastLen {
+		padding = strings.Repeat(" ", b.lastLen-len(line))
+	}
+	b.lastLen = len(line)
+	fmt.Fprintf(b.out, "\r%s%s", line, padding)
+}
+
+func (b *Bar) Finish() {
+	if b.tty && b.lastLen > 0 {
+		fmt.Fprintln(b.out)
+	}
+	b.lastLen = 0
+}
+
+func (b *Bar) render(event Event) string {
+	elapsed := b.now().Sub(b.started)
+	eta := EstimateRemaining(elapsed, event.Done, event.Total)
+
+	filled := 0
+	if event.Total > 0 {
+		filled = event.Done * b.width / event.Total
+	}
+	if filled > b.width {
+		filled = b.width
+	}
+
+	commit := event.Commit
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+
+	line := fmt.Sprintf("[%s%s] %d/%d %s %s elapsed=%s eta=%s",
+		strings.Repeat("#", filled), strings.Repeat("-", b.width-filled),
+		event.Done, event.Total, commit, event.Status,
+		elapsed.Round(time.Second), formatETA(eta))
+	if event.LLMLatency > 0 {
+		line += fmt.Sprintf(" llm=%s", event.LLMLatency.Round(time.Millisecond))
+	}
+	return line
+}
+
+func EstimateRemaining(elapsed time.Duration, done, total int) time.Duration {
+	if done <= 0 || total <= done {
+		return 0
+	}
+	perItem := elapsed / time.Duration(done)
+	return perItem * time.Duration(total-done)
+}
+
+func formatETA(eta time.Duration) string {
+	if eta <= 0 {
+		return "-"
+	}
+	return eta.Round(time.Second).String()
+}
+
+func isTerminal(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
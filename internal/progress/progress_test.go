@@ -0,0 +1,43 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEstimateRemaining(t *testing.T) {
+	if got := EstimateRemaining(10*time.Second, 2, 10); got != 40*time.Second {
+		t.Fatalf("expected 40s remaining, got %s", got)
+	}
+	if got := EstimateRemaining(10*time.Second, 0, 10); got != 0 {
+		t.Fatalf("expected no estimate before first item, got %s", got)
+	}
+	if got := EstimateRemaining(10*time.Second, 10, 10); got != 0 {
+		t.Fatalf("expected no remaining time when done, got %s", got)
+	}
+}
+
+func TestBarWritesLinesForNonTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	bar := NewBar(&buf)
+
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bar.now = func() time.Time { return current }
+
+	bar.Start(4)
+	current = current.Add(2 * time.Second)
+	bar.Update(Event{Done: 1, Commit: "0123456789abcdef", Status: "success", LLMLatency: 1500 * time.Millisecond})
+	bar.Finish()
+
+	out := buf.String()
+	for _, want := range []string{"1/4", "0123456", "success", "elapsed=2s", "eta=6s", "llm=1.5s"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected progress output to contain %q, got %q", want, out)
+		}
+	}
+	if strings.Contains(out, "\r") {
+		t.Fatalf("expected no carriage returns for non-terminal output")
+	}
+}
@@ -0,0 +1,182 @@
+package prompts
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+const DefaultTemplateName = "default.tmpl"
+
+const DefaultTemplate = `Update docs for this commit.
+Commit message: {{.CommitMessage}}
+Diff:
+{{.DiffSummary}}
+{{- if .ExistingSection}}
+Current content of section "{{.Section}}" in {{.DocFile}}:
+{{.ExistingSection}}
+Revise this content incrementally; keep accurate existing text.
+{{- end}}
+{{- if .FullDoc}}
+Full document {{.DocFile}} for context:
+{{.FullDoc}}
+{{- end}}
+{{- if .Examples}}
+Accepted updates for similar commits, as examples of the expected style:
+{{- range .Examples}}
+---
+Commit message: {{.CommitMessage}}
+Section "{{.Section}}" in {{.DocFile}}:
+{{.Content}}
+{{- end}}
+---
+{{- end}}
+Output updated section content only.`
+
+type Data struct {
+	CommitHash      string
+	ShortHash       string
+	CommitMessage   string
+	CommitType      string
+	CommitScope     string
+	Breaking        bool
+	DiffSummary     string
+	DocFile         string
+	Section         string
+	ExistingSection string
+	FullDoc         string
+	Examples        []Example
+	Repo            RepoInfo
+}
+
+// Example is a previously accepted section update shown as a few-shot
+// example.
+type Example struct {
+	ShortHash     string
+	CommitMessage string
+	DocFile       string
+	Section       string
+	Content       string
+}
+
+type RepoInfo struct {
+	Name string
+	Root string
+}
+
+type Loader struct {
+	dir             string
+	defaultTemplate string
+
+	mu    sync.Mutex
+	cache map[string]*template.Template
+}
+
+func NewLoader(dir, defaultTemplate string) *Loader {
+	return &Loader{
+		dir:             dir,
+		defaultTemplate: strings.TrimSpace(defaultTemplate),
+		cache:           make(map[string]*template.Template),
+	}
+}
+
+// Load resolves a template by name. An empty name falls back to the global
+// default template from config, then to default.tmpl in the prompts
+// directory, and finally to the built-in template.
+func (l *Loader) Load(name string) (*template.Template, error) {
+	name = strings.TrimSpace(name)
+	explicit := name != ""
+	if !explicit {
+		name = l.defaultTemplate
+		explicit = name != ""
+	}
+	if name == "" {
+		name = DefaultTemplateName
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if tmpl, ok := l.cache[name]; ok {
+		return tmpl, nil
+	}
+
+	tmplPath, err := l.templatePath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := os.ReadFile(tmplPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !explicit {
+			tmpl, parseErr := Parse("builtin", DefaultTemplate)
+			if parseErr != nil {
+				return nil, parseErr
+			}
+			l.cache[name] = tmpl
+			return tmpl, nil
+		}
+		return nil, fmt.Errorf("read prompt template %s: %w", name, err)
+	}
+
+	tmpl, err := Parse(name, string(raw))
+	if err != nil {
+		return nil, err
+	}
+	l.cache[name] = tmpl
+	return tmpl, nil
+}
+
+// templatePath joins name to the prompts directory, rejecting absolute
+// names and names that climb out of it.
+func (l *Loader) templatePath(name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if !filepath.IsLocal(clean) {
+		return "", fmt.Errorf("prompt template %s must be a path inside the prompts directory", name)
+	}
+	return filepath.Join(l.dir, clean), nil
+}
+
+func Parse(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(funcMap()).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse prompt template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+func Render(tmpl *template.Template, data Data) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render prompt template %s: %w", tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
+
+func RenderDefault(data Data) (string, error) {
+	tmpl, err := Parse("builtin", DefaultTemplate)
+	if err != nil {
+		return "", err
+	}
+	return Render(tmpl, data)
+}
+
+func funcMap() template.FuncMap {
+	return template.FuncMap{
+		"truncate": func(maxLen int, s string) string {
+			runes := []rune(s)
+			if maxLen <= 0 || len(runes) <= maxLen {
+				return s
+			}
+			return string(runes[:maxLen])
+		},
+		"trim":  strings.TrimSpace,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+}
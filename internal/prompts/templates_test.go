@@ -0,0 +1,115 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoaderFallsBackToBuiltinTemplate(t *testing.T) {
+	loader := NewLoader(t.TempDir(), "")
+
+	tmpl, err := loader.Load("")
+	if err != nil {
+		t.Fatalf("load default template: %v", err)
+	}
+
+	out, err := Render(tmpl, Data{CommitMessage: "feat: x", DiffSummary: "Files changed: 1"})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !strings.Contains(out, "Commit message: feat: x") || !strings.Contains(out, "Files changed: 1") {
+		t.Fatalf("unexpected builtin render: %q", out)
+	}
+}
+
+func TestLoaderUsesMappingAndDirectoryDefaultTemplates(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, DefaultTemplateName), []byte("global {{.Repo.Name}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "api.tmpl"), []byte("api {{.Section}}: {{.ExistingSection | trim}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewLoader(dir, "")
+	data := Data{Section: "API", ExistingSection: "  old text\n", Repo: RepoInfo{Name: "demo"}}
+
+	globalTmpl, err := loader.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := Render(globalTmpl, data); out != "global demo" {
+		t.Fatalf("unexpected global render: %q", out)
+	}
+
+	apiTmpl, err := loader.Load("api.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := Render(apiTmpl, data); out != "api API: old text" {
+		t.Fatalf("unexpected mapping render: %q", out)
+	}
+}
+
+func TestLoaderMissingExplicitTemplateFails(t *testing.T) {
+	loader := NewLoader(t.TempDir(), "")
+	if _, err := loader.Load("missing.tmpl"); err == nil {
+		t.Fatalf("expected missing explicit template to fail")
+	}
+}
+
+func TestDefaultTemplateOmitsEmptyContextBlocks(t *testing.T) {
+	out, err := RenderDefault(Data{CommitMessage: "fix: y", DiffSummary: "summary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Update docs for this commit.\nCommit message: fix: y\nDiff:\nsummary\nOutput updated section content only."
+	if out != want {
+		t.Fatalf("unexpected render:\n got: %q\nwant: %q", out, want)
+	}
+}
+
+func TestLoaderRejectsTemplatesOutsideDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "prompts")
+	if err := os.MkdirAll(filepath.Join(dir, "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(root, "secret.tmpl")
+	for path, text := range map[string]string{outside: "secret", filepath.Join(dir, "api", "v2.tmpl"): "v2"} {
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loader := NewLoader(dir, "")
+	for _, name := range []string{outside, "../secret.tmpl", "api/../../secret.tmpl"} {
+		if _, err := loader.Load(name); err == nil || !strings.Contains(err.Error(), "inside the prompts directory") {
+			t.Fatalf("expected %q to be rejected, got %v", name, err)
+		}
+	}
+	tmpl, err := loader.Load("api/./v2.tmpl")
+	if err != nil {
+		t.Fatalf("expected a nested template to load, got %v", err)
+	}
+	if out, _ := Render(tmpl, Data{}); out != "v2" {
+		t.Fatalf("unexpected nested template render: %q", out)
+	}
+}
+
+func TestTruncateKeepsRunesWhole(t *testing.T) {
+	tmpl, err := Parse("truncate", `{{truncate 4 .CommitMessage}}|{{truncate 9 .CommitMessage}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := Render(tmpl, Data{CommitMessage: "héllo wörld"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "héll|héllo wör" {
+		t.Fatalf("unexpected truncation: %q", out)
+	}
+}
@@ -6,16 +6,21 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
-	"strings"
-	"syscall"
 	"time"
 )
 
 var ErrAlreadyRunning = errors.New("git-doc is already running")
 
+// DefaultTTL is how long a run may hold the lock before another run treats
+// it as hung and takes over.
+const DefaultTTL = time.Hour
+
+// Lock is an advisory lock (flock, or LockFileEx on Windows) on
+// .git-doc/run.lock. The kernel drops it when the holder exits, so a crashed
+// run never blocks later ones.
 type Lock struct {
 	path string
+	file *os.File
 }
 
 type lockPayload struct {
@@ -23,83 +28,159 @@ type lockPayload struct {
 	CreatedAt string `json:"created_at"`
 }
 
+// Holder describes the run recorded in the lock file.
+type Holder struct {
+	PID       int
+	CreatedAt time.Time
+	Alive     bool
+}
+
+func lockPath(repoRoot string) string {
+	return filepath.Join(repoRoot, ".git-doc", "run.lock")
+}
+
 func Acquire(repoRoot string) (*Lock, error) {
-	lockPath := filepath.Join(repoRoot, ".git-doc", "run.lock")
-	if err := os.MkdirAll(filepath.Dir(lockPath), 0o700); err != nil {
+	return AcquireTTL(repoRoot, DefaultTTL)
+}
+
+// AcquireTTL takes the run lock. A lock held for longer than ttl is
+// considered hung: its file is replaced so this run can proceed while the
+// old holder keeps a lock on the orphaned file. ttl <= 0 never overrides.
+func AcquireTTL(repoRoot string, ttl time.Duration) (*Lock, error) {
+	path := lockPath(repoRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
 		return nil, fmt.Errorf("create lock directory: %w", err)
 	}
 
-	if _, err := os.Stat(lockPath); err == nil {
-		pid, parseErr := readPID(lockPath)
-		if parseErr == nil && processAlive(pid) {
-			return nil, fmt.Errorf("%w (pid=%d)", ErrAlreadyRunning, pid)
+	for attempt := 0; attempt < 3; attempt++ {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("open lock file: %w", err)
 		}
 
-		if rmErr := os.Remove(lockPath); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) {
-			return nil, fmt.Errorf("remove stale lock: %w", rmErr)
+		if err := lockFile(file); err != nil {
+			file.Close()
+			if !errors.Is(err, errLocked) {
+				return nil, fmt.Errorf("lock %s: %w", path, err)
+			}
+			holder, readErr := ReadHolder(repoRoot)
+			if readErr == nil && ttl > 0 && !holder.CreatedAt.IsZero() && time.Since(holder.CreatedAt) > ttl {
+				if rmErr := os.Remove(path); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) {
+					return nil, fmt.Errorf("remove stale lock: %w", rmErr)
+				}
+				continue
+			}
+			if readErr == nil && holder.PID > 0 {
+				return nil, fmt.Errorf("%w (pid=%d)", ErrAlreadyRunning, holder.PID)
+			}
+			return nil, ErrAlreadyRunning
 		}
-	}
-
-	payload := lockPayload{PID: os.Getpid(), CreatedAt: time.Now().UTC().Format(time.RFC3339)}
-	b, err := json.Marshal(payload)
-	if err != nil {
-		return nil, err
-	}
 
-	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
-	if err != nil {
-		return nil, fmt.Errorf("create lock file: %w", err)
-	}
-	defer file.Close()
+		// The file may have been replaced between open and lock by a stale
+		// override or a forced unlock; only the file at path counts.
+		if !sameFile(file, path) {
+			unlockFile(file)
+			file.Close()
+			continue
+		}
 
-	if _, err := file.Write(b); err != nil {
-		return nil, fmt.Errorf("write lock file: %w", err)
+		if err := writePayload(file); err != nil {
+			unlockFile(file)
+			file.Close()
+			return nil, err
+		}
+		return &Lock{path: path, file: file}, nil
 	}
-
-	return &Lock{path: lockPath}, nil
+	return nil, ErrAlreadyRunning
 }
 
 func IsAlreadyRunningError(err error) bool {
 	return errors.Is(err, ErrAlreadyRunning)
 }
 
+// Release clears and unlocks the lock file. The file itself stays, so runs
+// never race on creating it.
 func (l *Lock) Release() error {
-	if l == nil || l.path == "" {
+	if l == nil || l.file == nil {
 		return nil
 	}
-	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
-		return err
+	var err error
+	if sameFile(l.file, l.path) {
+		err = l.file.Truncate(0)
 	}
-	return nil
+	unlockFile(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	return err
 }
 
-func readPID(lockPath string) (int, error) {
-	b, err := os.ReadFile(lockPath)
+// ReadHolder returns the run recorded in the lock file. It says nothing about
+// whether that run still holds the lock.
+func ReadHolder(repoRoot string) (Holder, error) {
+	b, err := os.ReadFile(lockPath(repoRoot))
 	if err != nil {
-		return 0, err
+		return Holder{}, err
 	}
 
 	var payload lockPayload
-	if err := json.Unmarshal(b, &payload); err == nil && payload.PID > 0 {
-		return payload.PID, nil
+	if err := json.Unmarshal(b, &payload); err != nil {
+		return Holder{}, fmt.Errorf("parse lock file: %w", err)
+	}
+	holder := Holder{PID: payload.PID, Alive: processAlive(payload.PID)}
+	if payload.CreatedAt != "" {
+		holder.CreatedAt, _ = time.Parse(time.RFC3339, payload.CreatedAt)
 	}
+	return holder, nil
+}
 
-	trimmed := strings.TrimSpace(string(b))
-	if trimmed == "" {
-		return 0, fmt.Errorf("empty lock file")
+// Unlock removes the lock file when no run holds it. With force it is
+// removed even while held, so the next run starts regardless of the holder.
+// It reports whether a run was holding the lock.
+func Unlock(repoRoot string, force bool) (bool, error) {
+	lock, err := AcquireTTL(repoRoot, 0)
+	if err == nil {
+		return false, lock.Release()
+	}
+	if !IsAlreadyRunningError(err) {
+		return false, err
+	}
+	if !force {
+		return true, err
 	}
+	if err := os.Remove(lockPath(repoRoot)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return true, fmt.Errorf("remove lock file: %w", err)
+	}
+	return true, nil
+}
 
-	pid, err := strconv.Atoi(trimmed)
+func writePayload(file *os.File) error {
+	payload := lockPayload{PID: os.Getpid(), CreatedAt: time.Now().UTC().Format(time.RFC3339)}
+	b, err := json.Marshal(payload)
 	if err != nil {
-		return 0, err
+		return err
 	}
-	return pid, nil
+	if err := file.Truncate(0); err != nil {
+		return fmt.Errorf("write lock file: %w", err)
+	}
+	if _, err := file.WriteAt(b, 0); err != nil {
+		return fmt.Errorf("write lock file: %w", err)
+	}
+	return nil
 }
 
-func processAlive(pid int) bool {
-	if pid <= 0 {
+func sameFile(file *os.File, path string) bool {
+	if file == nil {
+		return false
+	}
+	held, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	if err != nil {
 		return false
 	}
-	err := syscall.Kill(pid, 0)
-	return err == nil
+	return os.SameFile(held, current)
 }
@@ -1,6 +1,12 @@
 package runlock
 
-import "testing"
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
 
 func TestAcquireRelease(t *testing.T) {
 	repo := t.TempDir()
@@ -30,3 +36,117 @@ func TestAcquireRelease(t *testing.T) {
 		t.Fatalf("second release failed: %v", err)
 	}
 }
+
+func TestProcessAlive(t *testing.T) {
+	if !processAlive(os.Getpid()) {
+		t.Fatalf("expected the current process to be alive")
+	}
+	if processAlive(0) || processAlive(-1) {
+		t.Fatalf("expected non-positive pids to be treated as dead")
+	}
+}
+
+func TestAcquireIgnoresLockFileOfDeadRun(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, ".git-doc"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	// A crashed run leaves its payload behind; the pid may since belong to
+	// another live process, here this test.
+	payload := fmt.Sprintf(`{"pid":%d,"created_at":"%s"}`, os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+	if err := os.WriteFile(lockPath(repo), []byte(payload), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := Acquire(repo)
+	if err != nil {
+		t.Fatalf("expected an unlocked lock file to be taken over, got %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquireOverridesHungRunAfterTTL(t *testing.T) {
+	repo := t.TempDir()
+	hung, err := Acquire(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hung.Release()
+
+	if _, err := AcquireTTL(repo, time.Hour); !IsAlreadyRunningError(err) {
+		t.Fatalf("expected a fresh lock to block, got %v", err)
+	}
+
+	old := fmt.Sprintf(`{"pid":%d,"created_at":"%s"}`, os.Getpid(), time.Now().Add(-2*time.Hour).UTC().Format(time.RFC3339))
+	if _, err := hung.file.WriteAt([]byte(old), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AcquireTTL(repo, 0); !IsAlreadyRunningError(err) {
+		t.Fatalf("expected ttl 0 to never override, got %v", err)
+	}
+
+	lock, err := AcquireTTL(repo, time.Hour)
+	if err != nil {
+		t.Fatalf("expected the hung run to be overridden, got %v", err)
+	}
+	defer lock.Release()
+
+	if err := hung.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Acquire(repo); !IsAlreadyRunningError(err) {
+		t.Fatalf("expected releasing the hung run to leave the new lock in place, got %v", err)
+	}
+}
+
+func TestUnlock(t *testing.T) {
+	repo := t.TempDir()
+	if held, err := Unlock(repo, false); err != nil || held {
+		t.Fatalf("expected an unheld lock to unlock quietly, got %v %v", held, err)
+	}
+
+	lock, err := Acquire(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Release()
+
+	if held, err := Unlock(repo, false); !held || !IsAlreadyRunningError(err) {
+		t.Fatalf("expected unlock without force to refuse a held lock, got %v %v", held, err)
+	}
+	if held, err := Unlock(repo, true); !held || err != nil {
+		t.Fatalf("expected forced unlock to clear a held lock, got %v %v", held, err)
+	}
+
+	next, err := Acquire(repo)
+	if err != nil {
+		t.Fatalf("expected acquire after forced unlock, got %v", err)
+	}
+	if err := next.Release(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPendingTrigger(t *testing.T) {
+	repo := t.TempDir()
+	if pending, err := TakePending(repo); err != nil || pending {
+		t.Fatalf("expected no queued trigger, got %v %v", pending, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := MarkPending(repo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pending, err := HasPending(repo); err != nil || !pending {
+		t.Fatalf("expected a queued trigger, got %v %v", pending, err)
+	}
+	if pending, err := TakePending(repo); err != nil || !pending {
+		t.Fatalf("expected to take the queued trigger, got %v %v", pending, err)
+	}
+	if pending, err := HasPending(repo); err != nil || pending {
+		t.Fatalf("expected triggers to collapse into one, got %v %v", pending, err)
+	}
+}
@@ -0,0 +1,41 @@
+package runlock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func pendingPath(repoRoot string) string {
+	return filepath.Join(repoRoot, ".git-doc", "run.pending")
+}
+
+// MarkPending records that a trigger arrived while another run held the
+// lock, so that run, or the next one, processes the new commits.
+func MarkPending(repoRoot string) error {
+	path := pendingPath(repoRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create lock directory: %w", err)
+	}
+	return os.WriteFile(path, []byte(time.Now().UTC().Format(time.RFC3339)+"\n"), 0o600)
+}
+
+// HasPending reports whether a trigger is queued.
+func HasPending(repoRoot string) (bool, error) {
+	_, err := os.Stat(pendingPath(repoRoot))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// TakePending clears the queued trigger and reports whether there was one.
+func TakePending(repoRoot string) (bool, error) {
+	err := os.Remove(pendingPath(repoRoot))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,114 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Backend is the processed-commit ledger, run history and LLM cache used by
+// the orchestrator and CLI. Store implements it over SQLite, either a local
+// file or a libSQL server that lets several machines and CI jobs work from
+// one ledger.
+type Backend interface {
+	GetLastProcessedCommit() (string, error)
+	MarkCommitProcessed(commitHash, status, errText, docCommit string, filesChanged []string) error
+	MarkCommitFailed(commitHash, errText, errorClass string) error
+	GetFailedCommits() ([]string, error)
+	GetRetryableCommits() ([]string, error)
+	GetResumableCommits() ([]string, error)
+	GetCompletedCommits() (map[string]bool, error)
+	GetProcessedCommit(commitHash string) (ProcessedCommitRow, bool, error)
+	ListRecent(limit int) ([]ProcessedCommitRow, error)
+	QueryCommits(filter CommitFilter) ([]ProcessedCommitRow, error)
+	CountCommits(filter CommitFilter) (int, error)
+	GetStatusCounts() (StatusCounts, error)
+	RemapCommit(oldHash, newHash string) (bool, error)
+	CompleteCommit(c CompletedCommit) error
+
+	StoreMapping(commitHash, docFile, section string) error
+	GetDocCommitHash(codeCommitHash string) (string, error)
+	GetLastSectionUpdate(docFile, section string) (string, time.Time, error)
+	ListSectionUpdates(since, until time.Time) ([]SectionUpdate, error)
+	ListContributions(docFile, section string) ([]Contribution, error)
+	DocCommitSources() (map[string][]string, error)
+	UpsertPlannedUpdate(commitHash, docFile, sectionID, strategy, status, reason string) error
+	PutPlannedContent(commitHash, docFile, sectionID, content, diffPreview string) error
+	ListApprovals() ([]PlannedUpdate, error)
+	GetPlannedUpdates(commitHash string) ([]PlannedUpdate, error)
+	GetPlannedUpdate(id int64) (PlannedUpdate, bool, error)
+	SetPlannedUpdateStatus(id int64, status, reason string) error
+
+	RecordRevert(codeCommitHash, docCommitHash, revertCommitHash, runID string) error
+	GetRevertedCommits() (map[string]bool, error)
+
+	GetCachedLLMResponse(commitHash, docFile, sectionID, provider, model, prompt string) (string, bool, error)
+	GetCachedResponseByPrompt(provider, model, prompt string, since time.Time) (string, bool, error)
+	PutCachedLLMResponse(entry LLMCacheEntry) error
+	PurgeLLMCache() (int64, error)
+	LLMCacheStats() ([]CacheStat, error)
+	ListCachedResponses(filter CacheFilter) ([]CachedResponse, error)
+	ClearLLMCache(filter CacheFilter) (int64, error)
+
+	GetSectionEmbeddings(model string) ([]SectionEmbedding, error)
+	PutSectionEmbedding(e SectionEmbedding) error
+	ReplaceDocInventory(entries []DocSection) error
+	GetDocInventory() ([]DocSection, error)
+	PutExemplar(e Exemplar) error
+	PutGeneratedSection(g GeneratedSection) error
+	GetGeneratedSection(docFile, section string) (GeneratedSection, bool, error)
+	GetExemplars(limit int) ([]Exemplar, error)
+
+	StartRun(runID, trigger string, dryRun bool) error
+	FinishRun(runID, status string, counts RunCounts) error
+	ListRuns(limit int) ([]RunRecord, error)
+	GetRun(runID string) (RunRecord, bool, error)
+	GetRunCommits(runID string) ([]string, error)
+	RecordDigest(periodStart, periodEnd time.Time, sections int) error
+	LastDigest() (time.Time, bool, error)
+	LogRunEvent(runID, commitHash, level, component, message string, metadata map[string]any) error
+	LogPrompt(entry PromptLogEntry) error
+	ListPromptLog(since time.Time) ([]PromptLogEntry, error)
+	PurgePromptLog() (int64, error)
+	ListRunEvents(runID string) ([]RunEvent, error)
+	QueryRunEvents(filter RunEventFilter) ([]RunEvent, error)
+	ScrubRunEventMetadata() (int64, error)
+
+	SetEncryptionKey(key string) error
+	Prune(before time.Time) (PruneResult, error)
+	Vacuum() error
+	IntegrityCheck() ([]string, error)
+	TableSizes() ([]TableSize, error)
+	Export() (Snapshot, error)
+	Import(snapshot Snapshot, overwrite bool) (ImportResult, error)
+	Close() error
+}
+
+var _ Backend = (*Store)(nil)
+
+// Open returns the backend for a state location: a SQLite file path, or a
+// URL whose scheme names the backend ("sqlite:///abs/path/state.db",
+// "libsql://team-db.turso.io"). authToken authenticates libsql:// servers.
+func Open(location, authToken string) (Backend, error) {
+	scheme, rest, ok := strings.Cut(location, "://")
+	if !ok {
+		return New(location)
+	}
+
+	switch scheme {
+	case "sqlite", "file":
+		return New(rest)
+	case "libsql":
+		return OpenLibSQL(location, authToken)
+	case "postgres", "postgresql":
+		return nil, fmt.Errorf("state backend %q is not available in this build; use a SQLite db_path or a libsql:// URL", scheme)
+	default:
+		return nil, fmt.Errorf("unsupported state backend %q", scheme)
+	}
+}
+
+// IsURL reports whether a state location names a backend by URL rather than
+// a SQLite path relative to the repository.
+func IsURL(location string) bool {
+	return strings.Contains(location, "://")
+}
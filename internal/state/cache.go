@@ -0,0 +1,140 @@
+package state
+
+import (
+	"strings"
+	"time"
+)
+
+// CacheFilter selects cached LLM responses. Empty fields match everything.
+type CacheFilter struct {
+	CommitHash string
+	Provider   string
+	Model      string
+	Before     time.Time
+	Limit      int
+}
+
+// CacheStat summarizes the cached responses of one provider and model.
+type CacheStat struct {
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Entries  int64     `json:"entries"`
+	Bytes    int64     `json:"bytes"`
+	Oldest   time.Time `json:"oldest"`
+	Newest   time.Time `json:"newest"`
+}
+
+// CachedResponse describes one llm_cache row without its response text.
+type CachedResponse struct {
+	ID         int64     `json:"id"`
+	CommitHash string    `json:"commit_hash"`
+	DocFile    string    `json:"doc_file"`
+	SectionID  string    `json:"section_id"`
+	Provider   string    `json:"provider"`
+	Model      string    `json:"model"`
+	PromptHash string    `json:"prompt_hash"`
+	Bytes      int64     `json:"bytes"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// LLMCacheStats groups the cache by provider and model.
+func (s *Store) LLMCacheStats() ([]CacheStat, error) {
+	rows, err := s.db.Query(`
+		SELECT provider, model, COUNT(*), COALESCE(SUM(LENGTH(response_text)), 0), MIN(created_at), MAX(created_at)
+		FROM llm_cache
+		GROUP BY provider, model
+		ORDER BY provider, model
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []CacheStat
+	for rows.Next() {
+		var stat CacheStat
+		var oldest, newest string
+		if err := rows.Scan(&stat.Provider, &stat.Model, &stat.Entries, &stat.Bytes, &oldest, &newest); err != nil {
+			return nil, err
+		}
+		stat.Oldest, stat.Newest = parseTimestamp(oldest), parseTimestamp(newest)
+		out = append(out, stat)
+	}
+	return out, rows.Err()
+}
+
+// ListCachedResponses returns the entries matching filter, newest first.
+func (s *Store) ListCachedResponses(filter CacheFilter) ([]CachedResponse, error) {
+	where, args := filter.where()
+	query := `
+		SELECT id, commit_hash, doc_file, section_id, provider, model, prompt_hash, LENGTH(response_text), created_at
+		FROM llm_cache` + where + `
+		ORDER BY created_at DESC, id DESC`
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []CachedResponse
+	for rows.Next() {
+		var entry CachedResponse
+		if err := rows.Scan(&entry.ID, &entry.CommitHash, &entry.DocFile, &entry.SectionID, &entry.Provider, &entry.Model, &entry.PromptHash, &entry.Bytes, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, entry)
+	}
+	return out, rows.Err()
+}
+
+// ClearLLMCache deletes the entries matching filter and returns how many
+// rows were removed. Limit is ignored.
+func (s *Store) ClearLLMCache(filter CacheFilter) (int64, error) {
+	where, args := filter.where()
+	res, err := s.db.Exec(`DELETE FROM llm_cache`+where, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (f CacheFilter) where() (string, []any) {
+	var clauses []string
+	var args []any
+	if f.CommitHash != "" {
+		clauses = append(clauses, "commit_hash LIKE ?")
+		args = append(args, f.CommitHash+"%")
+	}
+	if f.Provider != "" {
+		clauses = append(clauses, "provider = ?")
+		args = append(args, f.Provider)
+	}
+	if f.Model != "" {
+		clauses = append(clauses, "model = ?")
+		args = append(args, f.Model)
+	}
+	if !f.Before.IsZero() {
+		clauses = append(clauses, "created_at < ?")
+		args = append(args, formatTimestamp(f.Before))
+	}
+	if len(clauses) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(clauses, " AND "), args
+}
+
+// parseTimestamp reads a timestamp SQLite returned as text, as aggregates
+// over DATETIME columns do.
+func parseTimestamp(value string) time.Time {
+	for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339Nano} {
+		if ts, err := time.Parse(layout, value); err == nil {
+			return ts
+		}
+	}
+	return time.Time{}
+}
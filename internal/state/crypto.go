@@ -0,0 +1,80 @@
+package state
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	encryptedPrefix = "enc:v1:"
+	// EncryptedPlaceholder replaces run event metadata that cannot be
+	// decrypted because no key, or a different key, is configured.
+	EncryptedPlaceholder = "[encrypted]"
+)
+
+var errNoEncryptionKey = errors.New("state value is encrypted but no state.encryption_key is configured")
+
+// SetEncryptionKey enables at-rest encryption of cached LLM responses and run
+// event metadata. The key is any secret string; AES-256-GCM keys are derived
+// from it with SHA-256. Rows written before encryption was enabled stay
+// readable.
+func (s *Store) SetEncryptionKey(key string) error {
+	if key == "" {
+		s.aead = nil
+		return nil
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	s.aead = aead
+	return nil
+}
+
+func (s *Store) encrypt(plaintext string) (string, error) {
+	if s.aead == nil || plaintext == "" {
+		return plaintext, nil
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *Store) decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+	if s.aead == nil {
+		return "", errNoEncryptionKey
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("decode encrypted value: %w", err)
+	}
+	if len(sealed) < s.aead.NonceSize() {
+		return "", fmt.Errorf("encrypted value is truncated")
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt state value: wrong state.encryption_key?")
+	}
+	return string(plaintext), nil
+}
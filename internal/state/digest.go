@@ -0,0 +1,62 @@
+package state
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SectionUpdate is one doc section written for a code commit.
+type SectionUpdate struct {
+	CommitHash  string
+	DocCommit   string
+	DocFile     string
+	Section     string
+	ProcessedAt time.Time
+}
+
+// ListSectionUpdates returns the sections updated by successful commits
+// processed in [since, until), oldest first.
+func (s *Store) ListSectionUpdates(since, until time.Time) ([]SectionUpdate, error) {
+	rows, err := s.db.Query(`
+		SELECT p.commit_hash, COALESCE(p.doc_commit_hash, ''), m.doc_file, m.section, p.processed_at
+		FROM mappings m
+		JOIN processed_commits p ON p.commit_hash = m.code_commit_hash
+		WHERE p.status = 'success' AND p.processed_at >= ? AND p.processed_at < ?
+		ORDER BY p.processed_at ASC, m.id ASC
+	`, formatTimestamp(since), formatTimestamp(until))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []SectionUpdate
+	for rows.Next() {
+		var u SectionUpdate
+		if err := rows.Scan(&u.CommitHash, &u.DocCommit, &u.DocFile, &u.Section, &u.ProcessedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+// RecordDigest notes that a digest covering [periodStart, periodEnd) was
+// sent, so the next one starts where it ended.
+func (s *Store) RecordDigest(periodStart, periodEnd time.Time, sections int) error {
+	_, err := s.db.Exec(`INSERT INTO digests (period_start, period_end, sections) VALUES (?, ?, ?)`,
+		formatTimestamp(periodStart), formatTimestamp(periodEnd), sections)
+	return err
+}
+
+// LastDigest returns the end of the period covered by the newest digest.
+func (s *Store) LastDigest() (time.Time, bool, error) {
+	var end time.Time
+	err := s.db.QueryRow(`SELECT period_end FROM digests ORDER BY id DESC LIMIT 1`).Scan(&end)
+	if err == sql.ErrNoRows {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return end, true, nil
+}
@@ -0,0 +1,71 @@
+package state
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// SectionEmbedding is the cached vector of a doc section. ContentHash lets
+// callers re-embed only sections whose text changed.
+type SectionEmbedding struct {
+	DocFile     string
+	Section     string
+	Model       string
+	ContentHash string
+	Vector      []float32
+}
+
+func (s *Store) GetSectionEmbeddings(model string) ([]SectionEmbedding, error) {
+	rows, err := s.db.Query(`SELECT doc_file, section, model, content_hash, vector FROM section_embeddings WHERE model = ? ORDER BY doc_file, section`, model)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []SectionEmbedding
+	for rows.Next() {
+		var e SectionEmbedding
+		var blob []byte
+		if err := rows.Scan(&e.DocFile, &e.Section, &e.Model, &e.ContentHash, &blob); err != nil {
+			return nil, err
+		}
+		e.Vector, err = decodeVector(blob)
+		if err != nil {
+			return nil, fmt.Errorf("embedding for %s#%s: %w", e.DocFile, e.Section, err)
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) PutSectionEmbedding(e SectionEmbedding) error {
+	_, err := s.db.Exec(`
+	INSERT INTO section_embeddings (doc_file, section, model, content_hash, vector, updated_at)
+	VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	ON CONFLICT(doc_file, section, model) DO UPDATE SET
+		content_hash = excluded.content_hash,
+		vector = excluded.vector,
+		updated_at = CURRENT_TIMESTAMP
+	`, e.DocFile, e.Section, e.Model, e.ContentHash, encodeVector(e.Vector))
+	return err
+}
+
+func encodeVector(vector []float32) []byte {
+	out := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(out[4*i:], math.Float32bits(v))
+	}
+	return out
+}
+
+func decodeVector(blob []byte) ([]float32, error) {
+	if len(blob)%4 != 0 {
+		return nil, fmt.Errorf("vector has %d bytes, not a multiple of 4", len(blob))
+	}
+	out := make([]float32, len(blob)/4)
+	for i := range out {
+		out[i] = math.Float32frombits(binary.LittleEndian.Uint32(blob[4*i:]))
+	}
+	return out, nil
+}
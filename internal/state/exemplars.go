@@ -0,0 +1,79 @@
+package state
+
+import (
+	"strings"
+	"time"
+)
+
+// Exemplar is an accepted section update kept as a few-shot example for
+// later prompts. Message and Content are encrypted like cached responses.
+type Exemplar struct {
+	CommitHash string
+	DocFile    string
+	Section    string
+	Message    string
+	Files      []string
+	Content    string
+	CreatedAt  time.Time
+}
+
+func (s *Store) PutExemplar(e Exemplar) error {
+	message, err := s.encrypt(e.Message)
+	if err != nil {
+		return err
+	}
+	content, err := s.encrypt(e.Content)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+	INSERT INTO exemplars (commit_hash, doc_file, section, commit_message, changed_files, content)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT(commit_hash, doc_file, section) DO UPDATE SET
+		commit_message = excluded.commit_message,
+		changed_files = excluded.changed_files,
+		content = excluded.content,
+		created_at = CURRENT_TIMESTAMP
+	`, e.CommitHash, e.DocFile, e.Section, message, strings.Join(e.Files, "\n"), content)
+	return err
+}
+
+// GetExemplars returns up to limit of the newest exemplars. Entries sealed
+// with another encryption key are skipped.
+func (s *Store) GetExemplars(limit int) ([]Exemplar, error) {
+	if limit <= 0 {
+		limit = 500
+	}
+
+	rows, err := s.db.Query(`
+		SELECT commit_hash, doc_file, section, commit_message, changed_files, content, created_at
+		FROM exemplars
+		ORDER BY created_at DESC, rowid DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Exemplar
+	for rows.Next() {
+		var e Exemplar
+		var files string
+		if err := rows.Scan(&e.CommitHash, &e.DocFile, &e.Section, &e.Message, &files, &e.Content, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		var msgErr, contentErr error
+		e.Message, msgErr = s.decrypt(e.Message)
+		e.Content, contentErr = s.decrypt(e.Content)
+		if msgErr != nil || contentErr != nil {
+			continue
+		}
+		if files != "" {
+			e.Files = strings.Split(files, "\n")
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,67 @@
+package state
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// GeneratedSection is the body git-doc last wrote to a doc section, kept so
+// the next update can tell whether someone edited it by hand since. Content
+// is encrypted like cached responses; ContentHash is not, so edits are still
+// detected when the content was sealed with another key.
+type GeneratedSection struct {
+	DocFile     string
+	Section     string
+	ContentHash string
+	Content     string
+	CommitHash  string
+	UpdatedAt   time.Time
+}
+
+// SectionHash is the hash generated sections are compared by. It ignores
+// line endings and surrounding whitespace.
+func SectionHash(content string) string {
+	return hashPrompt(strings.TrimSpace(strings.ReplaceAll(content, "\r\n", "\n")))
+}
+
+func (s *Store) PutGeneratedSection(g GeneratedSection) error {
+	content, err := s.encrypt(g.Content)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+	INSERT INTO generated_sections (doc_file, section, content_hash, content, commit_hash)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT(doc_file, section) DO UPDATE SET
+		content_hash = excluded.content_hash,
+		content = excluded.content,
+		commit_hash = excluded.commit_hash,
+		updated_at = CURRENT_TIMESTAMP
+	`, g.DocFile, g.Section, SectionHash(g.Content), content, nullIfEmpty(g.CommitHash))
+	return err
+}
+
+// GetGeneratedSection returns what git-doc last wrote to a section. Content
+// is empty when it was encrypted with another key.
+func (s *Store) GetGeneratedSection(docFile, section string) (GeneratedSection, bool, error) {
+	g := GeneratedSection{DocFile: docFile, Section: section}
+	var commit sql.NullString
+	err := s.db.QueryRow(`
+		SELECT content_hash, content, commit_hash, updated_at
+		FROM generated_sections
+		WHERE doc_file = ? AND section = ?
+	`, docFile, section).Scan(&g.ContentHash, &g.Content, &commit, &g.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return GeneratedSection{}, false, nil
+	}
+	if err != nil {
+		return GeneratedSection{}, false, err
+	}
+	g.CommitHash = commit.String
+	if g.Content, err = s.decrypt(g.Content); err != nil {
+		g.Content = ""
+	}
+	return g, true, nil
+}
@@ -0,0 +1,76 @@
+package state
+
+import (
+	"database/sql"
+	"time"
+)
+
+// DocSection is one heading of a scanned doc file. Path joins the titles of
+// the enclosing headings and this one with " > ".
+type DocSection struct {
+	DocFile        string
+	Path           string
+	Section        string
+	Level          int
+	Line           int
+	Anchor         string
+	Words          int
+	LastCommit     string
+	LastModifiedAt time.Time
+	ScannedAt      time.Time
+}
+
+// ReplaceDocInventory swaps the stored inventory for entries in one
+// transaction, so sections removed from the docs disappear from it.
+func (s *Store) ReplaceDocInventory(entries []DocSection) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM doc_inventory`); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		var modified any
+		if !e.LastModifiedAt.IsZero() {
+			modified = e.LastModifiedAt.UTC()
+		}
+		if _, err := tx.Exec(`
+		INSERT OR REPLACE INTO doc_inventory (doc_file, section_path, section, level, line, anchor, word_count, last_commit, last_modified_at, scanned_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		`, e.DocFile, e.Path, e.Section, e.Level, e.Line, e.Anchor, e.Words, nullIfEmpty(e.LastCommit), modified); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) GetDocInventory() ([]DocSection, error) {
+	rows, err := s.db.Query(`
+		SELECT doc_file, section_path, section, level, line, anchor, word_count, last_commit, last_modified_at, scanned_at
+		FROM doc_inventory
+		ORDER BY doc_file, line
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []DocSection
+	for rows.Next() {
+		var e DocSection
+		var lastCommit sql.NullString
+		var modified sql.NullTime
+		if err := rows.Scan(&e.DocFile, &e.Path, &e.Section, &e.Level, &e.Line, &e.Anchor, &e.Words, &lastCommit, &modified, &e.ScannedAt); err != nil {
+			return nil, err
+		}
+		e.LastCommit = lastCommit.String
+		if modified.Valid {
+			e.LastModifiedAt = modified.Time
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,37 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	_ "github.com/tursodatabase/libsql-client-go/libsql"
+)
+
+// OpenLibSQL opens a state store on a libSQL server (sqld or Turso) at a
+// libsql:// URL, so several machines and CI jobs share one ledger. libSQL
+// speaks the SQLite dialect, so the store and its migrations are the ones
+// the SQLite backend uses. authToken, when set, authenticates the
+// connection; "?tls=0" with an explicit port connects over plain HTTP.
+func OpenLibSQL(location, authToken string) (*Store, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("parse libsql url: %w", err)
+	}
+	if authToken != "" {
+		query := u.Query()
+		query.Set("authToken", authToken)
+		u.RawQuery = query.Encode()
+	}
+
+	db, err := sql.Open("libsql", u.String())
+	if err != nil {
+		return nil, fmt.Errorf("open libsql: %w", err)
+	}
+	// The driver does not implement Ping, so a query checks the server.
+	if _, err := db.Exec(`SELECT 1`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect to libsql server %s: %w", u.Host, err)
+	}
+	return newStore(db)
+}
@@ -0,0 +1,353 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// hranaServer is a minimal Hrana v2 HTTP server over a SQLite database,
+// standing in for sqld so the libSQL backend runs end to end.
+type hranaServer struct {
+	db *sql.DB
+
+	mu      sync.Mutex
+	streams map[string]*sql.Conn
+	batons  int
+	auth    []string
+}
+
+type hranaValue struct {
+	Type   string  `json:"type"`
+	Value  any     `json:"value,omitempty"`
+	Base64 *string `json:"base64,omitempty"`
+}
+
+type hranaStmt struct {
+	SQL       string       `json:"sql"`
+	Args      []hranaValue `json:"args"`
+	NamedArgs []struct {
+		Name  string     `json:"name"`
+		Value hranaValue `json:"value"`
+	} `json:"named_args"`
+	WantRows bool `json:"want_rows"`
+}
+
+type hranaCondition struct {
+	Type  string           `json:"type"`
+	Step  int              `json:"step"`
+	Cond  *hranaCondition  `json:"cond"`
+	Conds []hranaCondition `json:"conds"`
+}
+
+type hranaRequest struct {
+	Type  string     `json:"type"`
+	Stmt  *hranaStmt `json:"stmt"`
+	Batch *struct {
+		Steps []struct {
+			Stmt      hranaStmt       `json:"stmt"`
+			Condition *hranaCondition `json:"condition"`
+		} `json:"steps"`
+	} `json:"batch"`
+}
+
+type hranaColumn struct {
+	Name     string  `json:"name"`
+	DeclType *string `json:"decltype"`
+}
+
+type hranaResult struct {
+	Cols             []hranaColumn  `json:"cols"`
+	Rows             [][]hranaValue `json:"rows"`
+	AffectedRowCount int64          `json:"affected_row_count"`
+	LastInsertRowID  *string        `json:"last_insert_rowid"`
+}
+
+type hranaError struct {
+	Message string `json:"message"`
+}
+
+func newHranaServer(t *testing.T) *hranaServer {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "remote.db") + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(10000)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &hranaServer{db: db, streams: map[string]*sql.Conn{}}
+}
+
+func (s *hranaServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/v2/pipeline" {
+		http.NotFound(w, r)
+		return
+	}
+	var body struct {
+		Baton    string         `json:"baton"`
+		Requests []hranaRequest `json:"requests"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.auth = append(s.auth, r.Header.Get("Authorization"))
+	conn, ok := s.streams[body.Baton]
+	delete(s.streams, body.Baton)
+	s.mu.Unlock()
+	if !ok {
+		if body.Baton != "" {
+			http.Error(w, `{"error":"unknown baton"}`, http.StatusBadRequest)
+			return
+		}
+		var err error
+		if conn, err = s.db.Conn(r.Context()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	ctx := context.Background()
+	results := make([]map[string]any, 0, len(body.Requests))
+	for _, req := range body.Requests {
+		switch req.Type {
+		case "execute":
+			res, err := s.execute(ctx, conn, *req.Stmt)
+			if err != nil {
+				results = append(results, map[string]any{"type": "error", "error": hranaError{err.Error()}})
+				continue
+			}
+			results = append(results, map[string]any{"type": "ok", "response": map[string]any{"type": "execute", "result": res}})
+		case "batch":
+			stepResults := make([]*hranaResult, len(req.Batch.Steps))
+			stepErrors := make([]*hranaError, len(req.Batch.Steps))
+			for i, step := range req.Batch.Steps {
+				if step.Condition != nil && !hranaHolds(*step.Condition, stepResults, stepErrors) {
+					continue
+				}
+				res, err := s.execute(ctx, conn, step.Stmt)
+				if err != nil {
+					stepErrors[i] = &hranaError{err.Error()}
+					continue
+				}
+				stepResults[i] = res
+			}
+			results = append(results, map[string]any{"type": "ok", "response": map[string]any{"type": "batch", "result": map[string]any{"step_results": stepResults, "step_errors": stepErrors}}})
+		case "close":
+			conn.Close()
+			conn = nil
+			results = append(results, map[string]any{"type": "ok", "response": map[string]any{"type": "close"}})
+		default:
+			results = append(results, map[string]any{"type": "error", "error": hranaError{"unsupported request " + req.Type}})
+		}
+	}
+
+	baton := ""
+	if conn != nil {
+		s.mu.Lock()
+		s.batons++
+		baton = fmt.Sprintf("baton-%d", s.batons)
+		s.streams[baton] = conn
+		s.mu.Unlock()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"baton": baton, "results": results})
+}
+
+func (s *hranaServer) execute(ctx context.Context, conn *sql.Conn, stmt hranaStmt) (*hranaResult, error) {
+	args := make([]any, 0, len(stmt.Args)+len(stmt.NamedArgs))
+	for _, arg := range stmt.Args {
+		args = append(args, hranaArg(arg))
+	}
+	for _, arg := range stmt.NamedArgs {
+		args = append(args, sql.Named(strings.TrimLeft(arg.Name, ":@$"), hranaArg(arg.Value)))
+	}
+
+	if !stmt.WantRows {
+		res, err := conn.ExecContext(ctx, stmt.SQL, args...)
+		if err != nil {
+			return nil, err
+		}
+		affected, _ := res.RowsAffected()
+		result := &hranaResult{AffectedRowCount: affected, Cols: []hranaColumn{}, Rows: [][]hranaValue{}}
+		if id, err := res.LastInsertId(); err == nil {
+			text := strconv.FormatInt(id, 10)
+			result.LastInsertRowID = &text
+		}
+		return result, nil
+	}
+
+	rows, err := conn.QueryContext(ctx, stmt.SQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	result := &hranaResult{Cols: make([]hranaColumn, len(types)), Rows: [][]hranaValue{}}
+	for i, col := range types {
+		result.Cols[i].Name = col.Name()
+		if decl := col.DatabaseTypeName(); decl != "" {
+			result.Cols[i].DeclType = &decl
+		}
+	}
+	for rows.Next() {
+		values := make([]any, len(types))
+		dest := make([]any, len(types))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := make([]hranaValue, len(values))
+		for i, value := range values {
+			row[i] = hranaEncode(value)
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, rows.Err()
+}
+
+func hranaArg(v hranaValue) any {
+	switch v.Type {
+	case "integer":
+		n, _ := strconv.ParseInt(fmt.Sprint(v.Value), 10, 64)
+		return n
+	case "blob":
+		if v.Base64 == nil {
+			return []byte{}
+		}
+		raw, _ := base64.RawStdEncoding.DecodeString(strings.TrimRight(*v.Base64, "="))
+		return raw
+	case "null":
+		return nil
+	default:
+		return v.Value
+	}
+}
+
+func hranaEncode(value any) hranaValue {
+	switch v := value.(type) {
+	case nil:
+		return hranaValue{Type: "null"}
+	case int64:
+		return hranaValue{Type: "integer", Value: strconv.FormatInt(v, 10)}
+	case float64:
+		return hranaValue{Type: "float", Value: v}
+	case []byte:
+		encoded := base64.RawStdEncoding.EncodeToString(v)
+		return hranaValue{Type: "blob", Base64: &encoded}
+	case time.Time:
+		return hranaValue{Type: "text", Value: v.Format("2006-01-02 15:04:05.999999999-07:00")}
+	default:
+		return hranaValue{Type: "text", Value: fmt.Sprint(v)}
+	}
+}
+
+func hranaHolds(cond hranaCondition, results []*hranaResult, errs []*hranaError) bool {
+	switch cond.Type {
+	case "ok":
+		return results[cond.Step] != nil
+	case "error":
+		return errs[cond.Step] != nil
+	case "not":
+		return !hranaHolds(*cond.Cond, results, errs)
+	case "and":
+		for _, c := range cond.Conds {
+			if !hranaHolds(c, results, errs) {
+				return false
+			}
+		}
+		return true
+	case "or":
+		for _, c := range cond.Conds {
+			if hranaHolds(c, results, errs) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+func TestOpenLibSQLSharesOneLedger(t *testing.T) {
+	remote := newHranaServer(t)
+	server := httptest.NewServer(remote)
+	defer server.Close()
+	location := "libsql://" + strings.TrimPrefix(server.URL, "http://") + "?tls=0"
+
+	laptop, err := Open(location, "team-token")
+	if err != nil {
+		t.Fatalf("open libsql backend: %v", err)
+	}
+	defer laptop.Close()
+	ci, err := OpenLibSQL(location, "team-token")
+	if err != nil {
+		t.Fatalf("open second libsql backend: %v", err)
+	}
+	defer ci.Close()
+
+	if version, err := ci.SchemaVersion(); err != nil || version != LatestSchemaVersion {
+		t.Fatalf("expected the remote schema at version %d, got %d, %v", LatestSchemaVersion, version, err)
+	}
+
+	if err := laptop.CompleteCommit(CompletedCommit{CommitHash: "c1", DocCommit: "d1", DocFiles: []string{"README.md"}, DocFile: "README.md", Section: "Usage", Mapped: true}); err != nil {
+		t.Fatalf("complete commit: %v", err)
+	}
+	if err := laptop.MarkCommitFailed("c2", "provider timeout", ErrorClassTransient); err != nil {
+		t.Fatal(err)
+	}
+
+	counts, err := ci.GetStatusCounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts.Success != 1 || counts.Failed != 1 {
+		t.Fatalf("expected the second machine to see both commits, got %+v", counts)
+	}
+	row, ok, err := ci.GetProcessedCommit("c1")
+	if err != nil || !ok {
+		t.Fatalf("expected c1 in the shared ledger, got %v, %v", ok, err)
+	}
+	if row.DocCommit.String != "d1" || time.Since(row.ProcessedAt) > time.Hour {
+		t.Fatalf("unexpected shared row: %+v", row)
+	}
+	if doc, err := ci.GetDocCommitHash("c1"); err != nil || doc != "d1" {
+		t.Fatalf("expected the mapping to be shared, got %q, %v", doc, err)
+	}
+	if retry, err := ci.GetRetryableCommits(); err != nil || len(retry) != 1 || retry[0] != "c2" {
+		t.Fatalf("expected c2 to be retryable from the second machine, got %v, %v", retry, err)
+	}
+
+	for _, auth := range remote.auth {
+		if auth != "Bearer team-token" {
+			t.Fatalf("expected every request to carry the auth token, got %q", auth)
+		}
+	}
+}
+
+func TestOpenLibSQLReportsUnreachableServer(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	location := "libsql://" + strings.TrimPrefix(server.URL, "http://") + "?tls=0"
+	server.Close()
+
+	if _, err := Open(location, ""); err == nil || !strings.Contains(err.Error(), "connect to libsql server") {
+		t.Fatalf("expected a connection error, got %v", err)
+	}
+}
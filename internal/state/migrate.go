@@ -0,0 +1,297 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+type migration struct {
+	version     int
+	description string
+	apply       func(tx *sql.Tx) error
+}
+
+// migrations are applied in order and never edited once released; schema
+// changes are made by appending a new version. Early versions use IF NOT
+// EXISTS because databases created before schema_version existed already
+// contain some of their tables.
+var migrations = []migration{
+	{1, "initial schema", execAll(
+		`CREATE TABLE IF NOT EXISTS processed_commits (
+			commit_hash TEXT PRIMARY KEY,
+			processed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			status TEXT CHECK(status IN ('pending', 'in_progress', 'success', 'failed', 'skipped')),
+			error TEXT,
+			doc_commit_hash TEXT,
+			doc_files_changed TEXT,
+			metadata TEXT
+		);`,
+		`CREATE TABLE IF NOT EXISTS mappings (
+			id INTEGER PRIMARY KEY,
+			code_commit_hash TEXT,
+			doc_file TEXT,
+			section TEXT,
+			FOREIGN KEY(code_commit_hash) REFERENCES processed_commits(commit_hash)
+		);`,
+		`CREATE TABLE IF NOT EXISTS planned_updates (
+			id INTEGER PRIMARY KEY,
+			commit_hash TEXT NOT NULL,
+			doc_file TEXT NOT NULL,
+			section_id TEXT NOT NULL,
+			strategy TEXT NOT NULL,
+			status TEXT NOT NULL,
+			reason TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(commit_hash, doc_file, section_id)
+		);`,
+		`CREATE TABLE IF NOT EXISTS llm_cache (
+			id INTEGER PRIMARY KEY,
+			commit_hash TEXT NOT NULL,
+			doc_file TEXT NOT NULL,
+			section_id TEXT NOT NULL,
+			provider TEXT NOT NULL,
+			model TEXT NOT NULL,
+			prompt_hash TEXT NOT NULL,
+			response_text TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(commit_hash, doc_file, section_id, provider, model, prompt_hash)
+		);`,
+	)},
+	{2, "allow pending and in_progress commit statuses", migrateCommitStatuses},
+	{3, "run history", execAll(
+		`CREATE TABLE IF NOT EXISTS run_events (
+			id INTEGER PRIMARY KEY,
+			run_id TEXT NOT NULL,
+			commit_hash TEXT,
+			level TEXT NOT NULL,
+			component TEXT NOT NULL,
+			message TEXT NOT NULL,
+			metadata TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS runs (
+			id TEXT PRIMARY KEY,
+			started_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			finished_at DATETIME,
+			trigger_type TEXT NOT NULL,
+			status TEXT NOT NULL,
+			dry_run INTEGER NOT NULL DEFAULT 0,
+			processed INTEGER NOT NULL DEFAULT 0,
+			success INTEGER NOT NULL DEFAULT 0,
+			failed INTEGER NOT NULL DEFAULT 0,
+			skipped INTEGER NOT NULL DEFAULT 0
+		);`,
+	)},
+	{4, "revert provenance", execAll(
+		`CREATE TABLE IF NOT EXISTS reverts (
+			id INTEGER PRIMARY KEY,
+			code_commit_hash TEXT NOT NULL,
+			doc_commit_hash TEXT NOT NULL,
+			revert_commit_hash TEXT NOT NULL,
+			run_id TEXT,
+			reverted_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+	)},
+	{5, "section embeddings", execAll(
+		`CREATE TABLE section_embeddings (
+			doc_file TEXT NOT NULL,
+			section TEXT NOT NULL,
+			model TEXT NOT NULL,
+			content_hash TEXT NOT NULL,
+			vector BLOB NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (doc_file, section, model)
+		);`,
+	)},
+	{6, "doc inventory", execAll(
+		`CREATE TABLE doc_inventory (
+			doc_file TEXT NOT NULL,
+			section_path TEXT NOT NULL,
+			section TEXT NOT NULL,
+			level INTEGER NOT NULL,
+			line INTEGER NOT NULL,
+			anchor TEXT NOT NULL,
+			word_count INTEGER NOT NULL,
+			last_commit TEXT,
+			last_modified_at DATETIME,
+			scanned_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (doc_file, section_path)
+		);`,
+	)},
+	{7, "translation mappings", execAll(
+		`ALTER TABLE mappings ADD COLUMN source_doc_file TEXT;`,
+		`ALTER TABLE mappings ADD COLUMN source_section TEXT;`,
+	)},
+	{8, "exemplars", execAll(
+		`CREATE TABLE exemplars (
+			commit_hash TEXT NOT NULL,
+			doc_file TEXT NOT NULL,
+			section TEXT NOT NULL,
+			commit_message TEXT NOT NULL,
+			changed_files TEXT NOT NULL,
+			content TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (commit_hash, doc_file, section)
+		);`,
+	)},
+	{9, "error classes", execAll(
+		`ALTER TABLE processed_commits ADD COLUMN error_class TEXT;`,
+	)},
+	{10, "digests", execAll(
+		`CREATE TABLE digests (
+			id INTEGER PRIMARY KEY,
+			period_start DATETIME NOT NULL,
+			period_end DATETIME NOT NULL,
+			sections INTEGER NOT NULL,
+			sent_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+	)},
+	{11, "content-addressed llm cache lookups", execAll(
+		`CREATE INDEX idx_llm_cache_prompt ON llm_cache (prompt_hash, provider, model);`,
+	)},
+	{12, "prompt log", execAll(
+		`CREATE TABLE prompt_log (
+			id INTEGER PRIMARY KEY,
+			run_id TEXT NOT NULL,
+			commit_hash TEXT NOT NULL,
+			purpose TEXT NOT NULL,
+			doc_file TEXT,
+			section_id TEXT,
+			provider TEXT NOT NULL,
+			model TEXT NOT NULL,
+			prompt_hash TEXT NOT NULL,
+			prompt_text TEXT,
+			status TEXT NOT NULL,
+			error TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE INDEX idx_prompt_log_created ON prompt_log (created_at);`,
+	)},
+	{13, "generated sections", execAll(
+		`CREATE TABLE generated_sections (
+			doc_file TEXT NOT NULL,
+			section TEXT NOT NULL,
+			content_hash TEXT NOT NULL,
+			content TEXT NOT NULL,
+			commit_hash TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (doc_file, section)
+		);`,
+	)},
+	{14, "queued update content", execAll(
+		`ALTER TABLE planned_updates ADD COLUMN content TEXT;`,
+	)},
+	{15, "planned update diff previews", execAll(
+		`ALTER TABLE planned_updates ADD COLUMN diff_preview TEXT;`,
+	)},
+}
+
+// LatestSchemaVersion is the newest state schema this build knows how to use.
+var LatestSchemaVersion = migrations[len(migrations)-1].version
+
+func (s *Store) migrate() error {
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if current > LatestSchemaVersion {
+		return fmt.Errorf("state database schema version %d is newer than this git-doc supports (%d); upgrade git-doc or point state.db_path at a new database", current, LatestSchemaVersion)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := s.applyMigration(m); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.description, err)
+		}
+	}
+	return nil
+}
+
+// applyMigration runs one migration in its own transaction. The version is
+// re-read inside the transaction so two processes opening the database at
+// once do not both apply it.
+func (s *Store) applyMigration(m migration) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var current int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&current); err != nil {
+		return err
+	}
+	if current >= m.version {
+		return nil
+	}
+
+	if err := m.apply(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_version (version, description) VALUES (?, ?)`, m.version, m.description); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SchemaVersion returns the newest migration applied to the database.
+func (s *Store) SchemaVersion() (int, error) {
+	var version int
+	if err := s.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+func execAll(stmts ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, stmt := range stmts {
+			if _, err := tx.Exec(stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// migrateCommitStatuses rebuilds processed_commits tables created before the
+// pending and in_progress statuses existed, since SQLite cannot alter a CHECK
+// constraint in place.
+func migrateCommitStatuses(tx *sql.Tx) error {
+	var tableSQL string
+	if err := tx.QueryRow(`SELECT sql FROM sqlite_master WHERE type='table' AND name='processed_commits'`).Scan(&tableSQL); err != nil {
+		return err
+	}
+	if strings.Contains(tableSQL, "'pending'") && strings.Contains(tableSQL, "'in_progress'") {
+		return nil
+	}
+
+	return execAll(
+		`CREATE TABLE processed_commits_new (
+			commit_hash TEXT PRIMARY KEY,
+			processed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			status TEXT CHECK(status IN ('pending', 'in_progress', 'success', 'failed', 'skipped')),
+			error TEXT,
+			doc_commit_hash TEXT,
+			doc_files_changed TEXT,
+			metadata TEXT
+		);`,
+		`INSERT INTO processed_commits_new (commit_hash, processed_at, status, error, doc_commit_hash, doc_files_changed, metadata)
+		 SELECT commit_hash, processed_at, status, error, doc_commit_hash, doc_files_changed, metadata
+		 FROM processed_commits;`,
+		`DROP TABLE processed_commits;`,
+		`ALTER TABLE processed_commits_new RENAME TO processed_commits;`,
+	)(tx)
+}
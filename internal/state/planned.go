@@ -0,0 +1,116 @@
+package state
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Planned update statuses that wait for git-doc approve or reject.
+const (
+	PlannedAwaitingApproval = "awaiting_approval"
+	PlannedDryRun           = "dry_run"
+)
+
+// PlannedUpdate is a planned_updates row. Content is the final section body
+// an update wrote, or would write when it is queued for approval or came from
+// a dry run, and DiffPreview the change it makes to the section. Both are
+// encrypted like cached responses and empty under another key.
+type PlannedUpdate struct {
+	ID          int64
+	CommitHash  string
+	DocFile     string
+	Section     string
+	Strategy    string
+	Status      string
+	Reason      string
+	Content     string
+	DiffPreview string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// PutPlannedContent attaches the final section body and its diff preview to
+// a planned update.
+func (s *Store) PutPlannedContent(commitHash, docFile, sectionID, content, diffPreview string) error {
+	sealed, err := s.encrypt(content)
+	if err != nil {
+		return err
+	}
+	preview, err := s.encrypt(diffPreview)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`
+		UPDATE planned_updates SET content = ?, diff_preview = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE commit_hash = ? AND doc_file = ? AND section_id = ?
+	`, sealed, preview, commitHash, docFile, sectionID)
+	return err
+}
+
+// ListApprovals returns the planned updates awaiting approval or left by dry
+// runs that still have content to apply, oldest first.
+func (s *Store) ListApprovals() ([]PlannedUpdate, error) {
+	rows, err := s.db.Query(plannedUpdateColumns+`
+		WHERE status IN (?, ?) AND content IS NOT NULL
+		ORDER BY id ASC
+	`, PlannedAwaitingApproval, PlannedDryRun)
+	if err != nil {
+		return nil, err
+	}
+	return s.scanPlannedUpdates(rows)
+}
+
+// GetPlannedUpdates returns the planned updates of one code commit.
+func (s *Store) GetPlannedUpdates(commitHash string) ([]PlannedUpdate, error) {
+	rows, err := s.db.Query(plannedUpdateColumns+` WHERE commit_hash = ? ORDER BY id ASC`, commitHash)
+	if err != nil {
+		return nil, err
+	}
+	return s.scanPlannedUpdates(rows)
+}
+
+func (s *Store) scanPlannedUpdates(rows *sql.Rows) ([]PlannedUpdate, error) {
+	defer rows.Close()
+	var out []PlannedUpdate
+	for rows.Next() {
+		p, err := s.scanPlannedUpdate(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) GetPlannedUpdate(id int64) (PlannedUpdate, bool, error) {
+	p, err := s.scanPlannedUpdate(s.db.QueryRow(plannedUpdateColumns+` WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return PlannedUpdate{}, false, nil
+	}
+	if err != nil {
+		return PlannedUpdate{}, false, err
+	}
+	return p, true, nil
+}
+
+func (s *Store) SetPlannedUpdateStatus(id int64, status, reason string) error {
+	_, err := s.db.Exec(`
+		UPDATE planned_updates SET status = ?, reason = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, status, nullIfEmpty(reason), id)
+	return err
+}
+
+const plannedUpdateColumns = `
+	SELECT id, commit_hash, doc_file, section_id, strategy, status, COALESCE(reason, ''), COALESCE(content, ''), COALESCE(diff_preview, ''), created_at, updated_at
+	FROM planned_updates`
+
+func (s *Store) scanPlannedUpdate(row rowScanner) (PlannedUpdate, error) {
+	var p PlannedUpdate
+	if err := row.Scan(&p.ID, &p.CommitHash, &p.DocFile, &p.Section, &p.Strategy, &p.Status, &p.Reason, &p.Content, &p.DiffPreview, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return PlannedUpdate{}, err
+	}
+	p.Content, _ = s.decrypt(p.Content)
+	p.DiffPreview, _ = s.decrypt(p.DiffPreview)
+	return p, nil
+}
@@ -0,0 +1,88 @@
+package state
+
+import (
+	"time"
+)
+
+// PromptLogEntry is one prompt sent to an LLM provider. Purpose is
+// "generate" for doc sections or "plan" for target planning; Status is "ok"
+// or "error".
+type PromptLogEntry struct {
+	ID         int64
+	RunID      string
+	CommitHash string
+	Purpose    string
+	DocFile    string
+	SectionID  string
+	Provider   string
+	Model      string
+	PromptHash string
+	Prompt     string
+	Status     string
+	Error      string
+	CreatedAt  time.Time
+}
+
+// LogPrompt records a prompt sent to a provider. The prompt text is
+// encrypted like cached responses; an empty Prompt records only its hash.
+func (s *Store) LogPrompt(entry PromptLogEntry) error {
+	prompt := entry.Prompt
+	if entry.PromptHash == "" {
+		entry.PromptHash = hashPrompt(prompt)
+	}
+	var err error
+	if prompt != "" {
+		if prompt, err = s.encrypt(prompt); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO prompt_log (run_id, commit_hash, purpose, doc_file, section_id, provider, model, prompt_hash, prompt_text, status, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, entry.RunID, entry.CommitHash, entry.Purpose, nullIfEmpty(entry.DocFile), nullIfEmpty(entry.SectionID),
+		entry.Provider, entry.Model, entry.PromptHash, nullIfEmpty(prompt), entry.Status, nullIfEmpty(entry.Error))
+	return err
+}
+
+// PurgePromptLog clears the prompt text of logged prompts, keeping their
+// hashes and metadata for provenance.
+func (s *Store) PurgePromptLog() (int64, error) {
+	res, err := s.db.Exec(`UPDATE prompt_log SET prompt_text = NULL WHERE prompt_text IS NOT NULL`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ListPromptLog returns the prompts logged at or after since, oldest first.
+// Prompts sealed with another key read as EncryptedPlaceholder.
+func (s *Store) ListPromptLog(since time.Time) ([]PromptLogEntry, error) {
+	rows, err := s.db.Query(`
+		SELECT id, run_id, commit_hash, purpose, COALESCE(doc_file, ''), COALESCE(section_id, ''), provider, model,
+			prompt_hash, COALESCE(prompt_text, ''), status, COALESCE(error, ''), created_at
+		FROM prompt_log
+		WHERE created_at >= ?
+		ORDER BY created_at ASC, id ASC
+	`, formatTimestamp(since))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []PromptLogEntry
+	for rows.Next() {
+		var e PromptLogEntry
+		if err := rows.Scan(&e.ID, &e.RunID, &e.CommitHash, &e.Purpose, &e.DocFile, &e.SectionID, &e.Provider, &e.Model,
+			&e.PromptHash, &e.Prompt, &e.Status, &e.Error, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if prompt, err := s.decrypt(e.Prompt); err != nil {
+			e.Prompt = EncryptedPlaceholder
+		} else {
+			e.Prompt = prompt
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,150 @@
+package state
+
+import (
+	"strings"
+	"time"
+)
+
+// Contribution is a code commit mapped to a doc section, with the runs that
+// processed it and the provider/model pairs that generated the section.
+// Runs and models pruned from the state are missing.
+type Contribution struct {
+	CommitHash  string
+	DocCommit   string
+	DocFile     string
+	Section     string
+	Status      string
+	ProcessedAt time.Time
+	RevertedBy  string
+	Runs        []string
+	Models      []string
+}
+
+// ListContributions returns the code commits mapped to docFile, or only to
+// section of it (case-insensitive) when section is set, oldest first.
+func (s *Store) ListContributions(docFile, section string) ([]Contribution, error) {
+	query := `
+		SELECT p.commit_hash, COALESCE(p.doc_commit_hash, ''), m.doc_file, m.section, p.status, p.processed_at,
+			COALESCE((SELECT revert_commit_hash FROM reverts WHERE reverts.code_commit_hash = p.commit_hash ORDER BY reverts.id DESC LIMIT 1), '')
+		FROM mappings m
+		JOIN processed_commits p ON p.commit_hash = m.code_commit_hash
+		WHERE m.doc_file = ?`
+	args := []any{docFile}
+	if section = strings.TrimSpace(section); section != "" {
+		query += " AND lower(m.section) = lower(?)"
+		args = append(args, section)
+	}
+	rows, err := s.db.Query(query+" ORDER BY p.processed_at ASC, m.id ASC", args...)
+	if err != nil {
+		return nil, err
+	}
+	var out []Contribution
+	for rows.Next() {
+		var c Contribution
+		if err := rows.Scan(&c.CommitHash, &c.DocCommit, &c.DocFile, &c.Section, &c.Status, &c.ProcessedAt, &c.RevertedBy); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range out {
+		if out[i].Runs, err = s.commitRuns(out[i].CommitHash); err != nil {
+			return nil, err
+		}
+		if out[i].Models, err = s.sectionModels(out[i]); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// commitRuns returns the runs that logged events for a commit, oldest
+// first.
+func (s *Store) commitRuns(commitHash string) ([]string, error) {
+	return s.queryStrings(`
+		SELECT run_id
+		FROM run_events
+		WHERE commit_hash = ?
+		GROUP BY run_id
+		ORDER BY MIN(id) ASC
+	`, commitHash)
+}
+
+// sectionModels returns the "provider/model" pairs that generated the
+// section for the commit: prompts sent for it and cached responses used for
+// it. A commit summarized in a batch is generated under the batch's last
+// commit, so it falls back to the prompts its runs sent for the section.
+func (s *Store) sectionModels(c Contribution) ([]string, error) {
+	models, err := s.queryStrings(`
+		SELECT provider || '/' || model FROM prompt_log
+		WHERE commit_hash = ? AND doc_file = ? AND lower(section_id) = lower(?) AND purpose = 'generate' AND status = 'ok'
+		UNION
+		SELECT provider || '/' || model FROM llm_cache
+		WHERE commit_hash = ? AND doc_file = ? AND lower(section_id) = lower(?)
+		ORDER BY 1
+	`, c.CommitHash, c.DocFile, c.Section, c.CommitHash, c.DocFile, c.Section)
+	if err != nil || len(models) > 0 || len(c.Runs) == 0 {
+		return models, err
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(c.Runs)), ", ")
+	args := []any{c.DocFile, c.Section}
+	for _, run := range c.Runs {
+		args = append(args, run)
+	}
+	return s.queryStrings(`
+		SELECT DISTINCT provider || '/' || model FROM prompt_log
+		WHERE doc_file = ? AND lower(section_id) = lower(?) AND purpose = 'generate' AND status = 'ok' AND run_id IN (`+placeholders+`)
+		ORDER BY 1
+	`, args...)
+}
+
+func (s *Store) queryStrings(query string, args ...any) ([]string, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		out = append(out, value)
+	}
+	return out, rows.Err()
+}
+
+// DocCommitSources maps each doc commit git-doc made to the code commits it
+// documented, oldest first. A batched update documents several commits in
+// one doc commit; with git.amend_original the doc commit is the amended
+// code commit itself.
+func (s *Store) DocCommitSources() (map[string][]string, error) {
+	rows, err := s.db.Query(`
+		SELECT doc_commit_hash, commit_hash
+		FROM processed_commits
+		WHERE COALESCE(doc_commit_hash, '') != ''
+		ORDER BY processed_at ASC, commit_hash ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string][]string{}
+	for rows.Next() {
+		var docCommit, commit string
+		if err := rows.Scan(&docCommit, &commit); err != nil {
+			return nil, err
+		}
+		out[docCommit] = append(out[docCommit], commit)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,189 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SharedCacheFile is the database a shared cache directory holds.
+const SharedCacheFile = "responses.db"
+
+const sharedCacheVersion = 1
+
+// SharedCache is an LLM response cache shared by every repository that
+// points cache.dir at the same directory, so forks and repositories split
+// out of a monorepo reuse each other's responses. Entries are keyed only by
+// a namespace (provider, model and endpoint) and the prompt hash, never by
+// commit or repository, so any two caches can be merged by copying rows and
+// concurrent writers cannot conflict.
+type SharedCache struct {
+	db *sql.DB
+}
+
+func OpenSharedCache(dir string) (*SharedCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create shared cache dir: %w", err)
+	}
+
+	dsn := filepath.Join(dir, SharedCacheFile) + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(" + fmt.Sprint(busyTimeout.Milliseconds()) + ")"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open shared cache: %w", err)
+	}
+
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open shared cache: %w", err)
+	}
+	if version > sharedCacheVersion {
+		db.Close()
+		return nil, fmt.Errorf("shared cache %s has schema version %d, newer than this git-doc supports (%d)", dir, version, sharedCacheVersion)
+	}
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS responses (
+			namespace TEXT NOT NULL,
+			prompt_hash TEXT NOT NULL,
+			response TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (namespace, prompt_hash)
+		) WITHOUT ROWID;
+		PRAGMA user_version = 1;
+	`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate shared cache: %w", err)
+	}
+	return &SharedCache{db: db}, nil
+}
+
+// Get returns the response stored for prompt in namespace when it was
+// created at or after since.
+func (c *SharedCache) Get(namespace, prompt string, since time.Time) (string, bool, error) {
+	var response string
+	err := c.db.QueryRow(`
+		SELECT response FROM responses
+		WHERE namespace = ? AND prompt_hash = ? AND created_at >= ?
+	`, namespace, hashPrompt(prompt), formatTimestamp(since)).Scan(&response)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return response, true, nil
+}
+
+// Put stores a response, replacing an older one for the same prompt.
+func (c *SharedCache) Put(namespace, prompt, response string) error {
+	_, err := c.db.Exec(`
+		INSERT INTO responses (namespace, prompt_hash, response) VALUES (?, ?, ?)
+		ON CONFLICT(namespace, prompt_hash) DO UPDATE SET
+			response = excluded.response,
+			created_at = CURRENT_TIMESTAMP
+	`, namespace, hashPrompt(prompt), response)
+	return err
+}
+
+// Stats groups the shared cache by namespace. Provider and Model are read
+// from the namespace, so one model served from two endpoints appears twice.
+func (c *SharedCache) Stats() ([]CacheStat, error) {
+	rows, err := c.db.Query(`
+		SELECT namespace, COUNT(*), COALESCE(SUM(LENGTH(response)), 0), MIN(created_at), MAX(created_at)
+		FROM responses
+		GROUP BY namespace
+		ORDER BY namespace
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []CacheStat
+	for rows.Next() {
+		var stat CacheStat
+		var namespace, oldest, newest string
+		if err := rows.Scan(&namespace, &stat.Entries, &stat.Bytes, &oldest, &newest); err != nil {
+			return nil, err
+		}
+		stat.Provider, stat.Model = splitNamespace(namespace)
+		stat.Oldest, stat.Newest = parseTimestamp(oldest), parseTimestamp(newest)
+		out = append(out, stat)
+	}
+	return out, rows.Err()
+}
+
+// Clear deletes the entries whose namespace matches the filter's provider
+// and model and that were created before filter.Before. The shared cache
+// knows no commits, so CommitHash must be empty; use ClearPrompts instead.
+func (c *SharedCache) Clear(filter CacheFilter) (int64, error) {
+	if filter.CommitHash != "" {
+		return 0, fmt.Errorf("the shared cache cannot be cleared by commit")
+	}
+
+	rows, err := c.db.Query(`SELECT DISTINCT namespace FROM responses`)
+	if err != nil {
+		return 0, err
+	}
+	var namespaces []any
+	for rows.Next() {
+		var namespace string
+		if err := rows.Scan(&namespace); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		provider, model := splitNamespace(namespace)
+		if (filter.Provider == "" || filter.Provider == provider) && (filter.Model == "" || filter.Model == model) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(namespaces) == 0 {
+		return 0, err
+	}
+
+	query := `DELETE FROM responses WHERE namespace IN (?` + strings.Repeat(`, ?`, len(namespaces)-1) + `)`
+	args := namespaces
+	if !filter.Before.IsZero() {
+		query += ` AND created_at < ?`
+		args = append(args, formatTimestamp(filter.Before))
+	}
+	res, err := c.db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ClearPrompts deletes the entries for the given prompt hashes in every
+// namespace.
+func (c *SharedCache) ClearPrompts(promptHashes []string) (int64, error) {
+	var removed int64
+	for _, hash := range promptHashes {
+		res, err := c.db.Exec(`DELETE FROM responses WHERE prompt_hash = ?`, hash)
+		if err != nil {
+			return removed, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return removed, err
+		}
+		removed += n
+	}
+	return removed, nil
+}
+
+func splitNamespace(namespace string) (string, string) {
+	parts := strings.SplitN(namespace, "|", 3)
+	if len(parts) < 2 {
+		return namespace, ""
+	}
+	return parts[0], parts[1]
+}
+
+func (c *SharedCache) Close() error {
+	return c.db.Close()
+}
@@ -0,0 +1,194 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const SnapshotVersion = 1
+
+// Snapshot is a portable copy of the processed-commit ledger. Caches, runs
+// and run events are machine-local and are not included.
+type Snapshot struct {
+	Version          int               `json:"version"`
+	ExportedAt       time.Time         `json:"exported_at"`
+	ProcessedCommits []SnapshotCommit  `json:"processed_commits"`
+	Mappings         []SnapshotMapping `json:"mappings"`
+	Reverts          []SnapshotRevert  `json:"reverts"`
+}
+
+type SnapshotCommit struct {
+	CommitHash      string    `json:"commit_hash"`
+	ProcessedAt     time.Time `json:"processed_at"`
+	Status          string    `json:"status"`
+	Error           string    `json:"error,omitempty"`
+	ErrorClass      string    `json:"error_class,omitempty"`
+	DocCommitHash   string    `json:"doc_commit_hash,omitempty"`
+	DocFilesChanged string    `json:"doc_files_changed,omitempty"`
+}
+
+type SnapshotMapping struct {
+	CodeCommitHash string `json:"code_commit_hash"`
+	DocFile        string `json:"doc_file"`
+	Section        string `json:"section"`
+}
+
+type SnapshotRevert struct {
+	CodeCommitHash   string    `json:"code_commit_hash"`
+	DocCommitHash    string    `json:"doc_commit_hash"`
+	RevertCommitHash string    `json:"revert_commit_hash"`
+	RevertedAt       time.Time `json:"reverted_at"`
+}
+
+type ImportResult struct {
+	Commits  int64
+	Mappings int64
+	Reverts  int64
+}
+
+// Export returns the ledger of settled commits; pending and in-progress rows
+// belong to a run on this machine and are left out.
+func (s *Store) Export() (Snapshot, error) {
+	snapshot := Snapshot{
+		Version:          SnapshotVersion,
+		ExportedAt:       time.Now().UTC(),
+		ProcessedCommits: []SnapshotCommit{},
+		Mappings:         []SnapshotMapping{},
+		Reverts:          []SnapshotRevert{},
+	}
+
+	rows, err := s.db.Query(`
+		SELECT commit_hash, processed_at, status, COALESCE(error, ''), COALESCE(error_class, ''), COALESCE(doc_commit_hash, ''), COALESCE(doc_files_changed, '')
+		FROM processed_commits
+		WHERE status IN ('success', 'failed', 'skipped')
+		ORDER BY processed_at ASC, commit_hash ASC
+	`)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	for rows.Next() {
+		var c SnapshotCommit
+		if err := rows.Scan(&c.CommitHash, &c.ProcessedAt, &c.Status, &c.Error, &c.ErrorClass, &c.DocCommitHash, &c.DocFilesChanged); err != nil {
+			rows.Close()
+			return Snapshot{}, err
+		}
+		snapshot.ProcessedCommits = append(snapshot.ProcessedCommits, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return Snapshot{}, err
+	}
+
+	rows, err = s.db.Query(`
+		SELECT m.code_commit_hash, m.doc_file, m.section
+		FROM mappings m
+		JOIN processed_commits p ON p.commit_hash = m.code_commit_hash
+		WHERE p.status IN ('success', 'failed', 'skipped')
+		ORDER BY m.id ASC
+	`)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	for rows.Next() {
+		var m SnapshotMapping
+		if err := rows.Scan(&m.CodeCommitHash, &m.DocFile, &m.Section); err != nil {
+			rows.Close()
+			return Snapshot{}, err
+		}
+		snapshot.Mappings = append(snapshot.Mappings, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return Snapshot{}, err
+	}
+
+	rows, err = s.db.Query(`SELECT code_commit_hash, doc_commit_hash, revert_commit_hash, reverted_at FROM reverts ORDER BY id ASC`)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r SnapshotRevert
+		if err := rows.Scan(&r.CodeCommitHash, &r.DocCommitHash, &r.RevertCommitHash, &r.RevertedAt); err != nil {
+			return Snapshot{}, err
+		}
+		snapshot.Reverts = append(snapshot.Reverts, r)
+	}
+	return snapshot, rows.Err()
+}
+
+// Import merges a snapshot into the store. Commits already recorded locally
+// keep their row unless overwrite is set; mappings and reverts are added when
+// not already present.
+func (s *Store) Import(snapshot Snapshot, overwrite bool) (ImportResult, error) {
+	if snapshot.Version < 1 || snapshot.Version > SnapshotVersion {
+		return ImportResult{}, fmt.Errorf("unsupported snapshot version %d (this git-doc supports version %d)", snapshot.Version, SnapshotVersion)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return ImportResult{}, err
+	}
+	defer tx.Rollback()
+
+	commitStmt := `INSERT OR IGNORE INTO processed_commits (commit_hash, processed_at, status, error, error_class, doc_commit_hash, doc_files_changed) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	if overwrite {
+		commitStmt = `INSERT OR REPLACE INTO processed_commits (commit_hash, processed_at, status, error, error_class, doc_commit_hash, doc_files_changed) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	}
+
+	var result ImportResult
+	for _, c := range snapshot.ProcessedCommits {
+		switch c.Status {
+		case "success", "failed", "skipped":
+		default:
+			return ImportResult{}, fmt.Errorf("commit %s has unsupported status %q", c.CommitHash, c.Status)
+		}
+		res, err := tx.Exec(commitStmt, c.CommitHash, formatTimestamp(c.ProcessedAt), c.Status, nullIfEmpty(c.Error), nullIfEmpty(c.ErrorClass), nullIfEmpty(c.DocCommitHash), nullIfEmpty(c.DocFilesChanged))
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("import commit %s: %w", c.CommitHash, err)
+		}
+		if err := addAffected(res, &result.Commits); err != nil {
+			return ImportResult{}, err
+		}
+	}
+
+	for _, m := range snapshot.Mappings {
+		res, err := tx.Exec(`
+			INSERT INTO mappings (code_commit_hash, doc_file, section)
+			SELECT ?, ?, ?
+			WHERE NOT EXISTS (SELECT 1 FROM mappings WHERE code_commit_hash = ? AND doc_file = ? AND section = ?)
+		`, m.CodeCommitHash, m.DocFile, m.Section, m.CodeCommitHash, m.DocFile, m.Section)
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("import mapping for %s: %w", m.CodeCommitHash, err)
+		}
+		if err := addAffected(res, &result.Mappings); err != nil {
+			return ImportResult{}, err
+		}
+	}
+
+	for _, r := range snapshot.Reverts {
+		res, err := tx.Exec(`
+			INSERT INTO reverts (code_commit_hash, doc_commit_hash, revert_commit_hash, reverted_at)
+			SELECT ?, ?, ?, ?
+			WHERE NOT EXISTS (SELECT 1 FROM reverts WHERE code_commit_hash = ? AND doc_commit_hash = ? AND revert_commit_hash = ?)
+		`, r.CodeCommitHash, r.DocCommitHash, r.RevertCommitHash, formatTimestamp(r.RevertedAt), r.CodeCommitHash, r.DocCommitHash, r.RevertCommitHash)
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("import revert for %s: %w", r.CodeCommitHash, err)
+		}
+		if err := addAffected(res, &result.Reverts); err != nil {
+			return ImportResult{}, err
+		}
+	}
+
+	return result, tx.Commit()
+}
+
+func addAffected(res sql.Result, total *int64) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	*total += n
+	return nil
+}
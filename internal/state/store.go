@@ -1,6 +1,7 @@
 package state
 
 import (
+	"crypto/cipher"
 	"crypto/sha256"
 	"database/sql"
 	"encoding/json"
@@ -13,8 +14,11 @@ import (
 	_ "modernc.org/sqlite"
 )
 
+const busyTimeout = 10 * time.Second
+
 type Store struct {
-	db *sql.DB
+	db   *sql.DB
+	aead cipher.AEAD
 }
 
 type ProcessedCommitRow struct {
@@ -22,16 +26,79 @@ type ProcessedCommitRow struct {
 	ProcessedAt time.Time
 	Status      string
 	Error       sql.NullString
+	ErrorClass  sql.NullString
 	DocCommit   sql.NullString
+	RevertedBy  sql.NullString
 }
 
+// Failed commits are classified so retries skip failures that would only
+// fail again until the repo or config changes.
+const (
+	ErrorClassTransient = "transient"
+	ErrorClassPermanent = "permanent"
+)
+
 type StatusCounts struct {
 	Pending    int `json:"pending"`
 	InProgress int `json:"in_progress"`
 	Success    int `json:"success"`
 	Failed     int `json:"failed"`
-	Skipped    int `json:"skipped"`
-	Total      int `json:"total"`
+	// FailedPermanent is the part of Failed that retry skips.
+	FailedPermanent int `json:"failed_permanent"`
+	Skipped         int `json:"skipped"`
+	Total           int `json:"total"`
+}
+
+type RunRecord struct {
+	ID         string
+	StartedAt  time.Time
+	FinishedAt sql.NullTime
+	Trigger    string
+	Status     string
+	DryRun     bool
+	Processed  int
+	Success    int
+	Failed     int
+	Skipped    int
+}
+
+type RunCounts struct {
+	Processed int
+	Success   int
+	Failed    int
+	Skipped   int
+}
+
+type RunEvent struct {
+	ID         int64
+	RunID      string
+	CommitHash string
+	Level      string
+	Component  string
+	Message    string
+	Metadata   string
+	CreatedAt  time.Time
+}
+
+// CompletedCommit is the outcome of successfully documenting a commit.
+type CompletedCommit struct {
+	CommitHash string
+	DocCommit  string
+	DocFiles   []string
+	DocFile    string
+	Section    string
+	Strategy   string
+	Reason     string
+	// Mapped records the code-to-doc mapping; dry runs leave it unset.
+	Mapped bool
+	// Translations are the translated sections regenerated from DocFile and
+	// Section in the same doc commit; they are mapped alongside it.
+	Translations []TranslatedSection
+}
+
+type TranslatedSection struct {
+	DocFile string
+	Section string
 }
 
 type LLMCacheEntry struct {
@@ -49,129 +116,29 @@ func New(dbPath string) (*Store, error) {
 		return nil, fmt.Errorf("create state dir: %w", err)
 	}
 
-	db, err := sql.Open("sqlite", dbPath)
+	// WAL lets hook-triggered and manual runs read while the other writes;
+	// busy_timeout waits for a competing writer instead of failing, and
+	// immediate transactions take the write lock up front so they cannot
+	// deadlock upgrading from a read lock.
+	dsn := dbPath + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(" + fmt.Sprint(busyTimeout.Milliseconds()) + ")&_txlock=immediate"
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("open sqlite: %w", err)
 	}
+	return newStore(db)
+}
 
+// newStore migrates db to the latest schema and wraps it in a Store,
+// closing it when the migration fails.
+func newStore(db *sql.DB) (*Store, error) {
 	store := &Store{db: db}
 	if err := store.migrate(); err != nil {
+		db.Close()
 		return nil, err
 	}
-
 	return store, nil
 }
 
-func (s *Store) migrate() error {
-	stmts := []string{
-		`CREATE TABLE IF NOT EXISTS processed_commits (
-			commit_hash TEXT PRIMARY KEY,
-			processed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			status TEXT CHECK(status IN ('pending', 'in_progress', 'success', 'failed', 'skipped')),
-			error TEXT,
-			doc_commit_hash TEXT,
-			doc_files_changed TEXT,
-			metadata TEXT
-		);`,
-		`CREATE TABLE IF NOT EXISTS mappings (
-			id INTEGER PRIMARY KEY,
-			code_commit_hash TEXT,
-			doc_file TEXT,
-			section TEXT,
-			FOREIGN KEY(code_commit_hash) REFERENCES processed_commits(commit_hash)
-		);`,
-		`CREATE TABLE IF NOT EXISTS planned_updates (
-			id INTEGER PRIMARY KEY,
-			commit_hash TEXT NOT NULL,
-			doc_file TEXT NOT NULL,
-			section_id TEXT NOT NULL,
-			strategy TEXT NOT NULL,
-			status TEXT NOT NULL,
-			reason TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			UNIQUE(commit_hash, doc_file, section_id)
-		);`,
-		`CREATE TABLE IF NOT EXISTS llm_cache (
-			id INTEGER PRIMARY KEY,
-			commit_hash TEXT NOT NULL,
-			doc_file TEXT NOT NULL,
-			section_id TEXT NOT NULL,
-			provider TEXT NOT NULL,
-			model TEXT NOT NULL,
-			prompt_hash TEXT NOT NULL,
-			response_text TEXT NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			UNIQUE(commit_hash, doc_file, section_id, provider, model, prompt_hash)
-		);`,
-		`CREATE TABLE IF NOT EXISTS run_events (
-			id INTEGER PRIMARY KEY,
-			run_id TEXT NOT NULL,
-			commit_hash TEXT,
-			level TEXT NOT NULL,
-			component TEXT NOT NULL,
-			message TEXT NOT NULL,
-			metadata TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		);`,
-	}
-
-	for _, stmt := range stmts {
-		if _, err := s.db.Exec(stmt); err != nil {
-			return fmt.Errorf("migration failed: %w", err)
-		}
-	}
-
-	if err := s.ensureProcessedCommitSchema(); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (s *Store) ensureProcessedCommitSchema() error {
-	row := s.db.QueryRow(`SELECT sql FROM sqlite_master WHERE type='table' AND name='processed_commits'`)
-	var tableSQL string
-	if err := row.Scan(&tableSQL); err != nil {
-		return err
-	}
-
-	if strings.Contains(tableSQL, "'pending'") && strings.Contains(tableSQL, "'in_progress'") {
-		return nil
-	}
-
-	tx, err := s.db.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	stmts := []string{
-		`CREATE TABLE processed_commits_new (
-			commit_hash TEXT PRIMARY KEY,
-			processed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			status TEXT CHECK(status IN ('pending', 'in_progress', 'success', 'failed', 'skipped')),
-			error TEXT,
-			doc_commit_hash TEXT,
-			doc_files_changed TEXT,
-			metadata TEXT
-		);`,
-		`INSERT INTO processed_commits_new (commit_hash, processed_at, status, error, doc_commit_hash, doc_files_changed, metadata)
-		 SELECT commit_hash, processed_at, status, error, doc_commit_hash, doc_files_changed, metadata
-		 FROM processed_commits;`,
-		`DROP TABLE processed_commits;`,
-		`ALTER TABLE processed_commits_new RENAME TO processed_commits;`,
-	}
-
-	for _, stmt := range stmts {
-		if _, err := tx.Exec(stmt); err != nil {
-			return err
-		}
-	}
-
-	return tx.Commit()
-}
-
 func (s *Store) GetLastProcessedCommit() (string, error) {
 	row := s.db.QueryRow(`SELECT commit_hash FROM processed_commits WHERE status='success' ORDER BY processed_at DESC LIMIT 1`)
 	var hash string
@@ -184,7 +151,22 @@ func (s *Store) GetLastProcessedCommit() (string, error) {
 	return hash, nil
 }
 
+// execer is satisfied by *sql.DB and *sql.Tx so single statements can run on
+// their own or as part of a transaction.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
 func (s *Store) MarkCommitProcessed(commitHash, status, errText, docCommit string, filesChanged []string) error {
+	return markCommitProcessed(s.db, commitHash, status, errText, "", docCommit, filesChanged)
+}
+
+// MarkCommitFailed marks a commit failed with one of the ErrorClass values.
+func (s *Store) MarkCommitFailed(commitHash, errText, errorClass string) error {
+	return markCommitProcessed(s.db, commitHash, "failed", errText, errorClass, "", nil)
+}
+
+func markCommitProcessed(db execer, commitHash, status, errText, errorClass, docCommit string, filesChanged []string) error {
 	filesJSON := "[]"
 	if filesChanged != nil {
 		b, err := json.Marshal(filesChanged)
@@ -194,16 +176,17 @@ func (s *Store) MarkCommitProcessed(commitHash, status, errText, docCommit strin
 		filesJSON = string(b)
 	}
 
-	_, err := s.db.Exec(`
-	INSERT INTO processed_commits (commit_hash, status, error, doc_commit_hash, doc_files_changed)
-	VALUES (?, ?, ?, ?, ?)
+	_, err := db.Exec(`
+	INSERT INTO processed_commits (commit_hash, status, error, error_class, doc_commit_hash, doc_files_changed)
+	VALUES (?, ?, ?, ?, ?, ?)
 	ON CONFLICT(commit_hash) DO UPDATE SET
 		processed_at = CURRENT_TIMESTAMP,
 		status = excluded.status,
 		error = excluded.error,
+		error_class = excluded.error_class,
 		doc_commit_hash = excluded.doc_commit_hash,
 		doc_files_changed = excluded.doc_files_changed
-	`, commitHash, status, nullIfEmpty(errText), nullIfEmpty(docCommit), filesJSON)
+	`, commitHash, status, nullIfEmpty(errText), nullIfEmpty(errorClass), nullIfEmpty(docCommit), filesJSON)
 	if err != nil {
 		return fmt.Errorf("mark commit processed: %w", err)
 	}
@@ -211,6 +194,35 @@ func (s *Store) MarkCommitProcessed(commitHash, status, errText, docCommit strin
 	return nil
 }
 
+// CompleteCommit marks a commit successful, records its mapping and marks its
+// planned update applied in one transaction, so a concurrent run never sees a
+// successful commit without its mapping.
+func (s *Store) CompleteCommit(c CompletedCommit) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := markCommitProcessed(tx, c.CommitHash, "success", "", "", c.DocCommit, c.DocFiles); err != nil {
+		return err
+	}
+	if c.Mapped {
+		if err := storeMapping(tx, c.CommitHash, c.DocFile, c.Section); err != nil {
+			return fmt.Errorf("store mapping: %w", err)
+		}
+		for _, t := range c.Translations {
+			if _, err := tx.Exec(`INSERT INTO mappings (code_commit_hash, doc_file, section, source_doc_file, source_section) VALUES (?, ?, ?, ?, ?)`, c.CommitHash, t.DocFile, t.Section, c.DocFile, c.Section); err != nil {
+				return fmt.Errorf("store translation mapping: %w", err)
+			}
+		}
+	}
+	if err := upsertPlannedUpdate(tx, c.CommitHash, c.DocFile, c.Section, c.Strategy, "applied", c.Reason); err != nil {
+		return fmt.Errorf("update planned update: %w", err)
+	}
+	return tx.Commit()
+}
+
 func (s *Store) GetFailedCommits() ([]string, error) {
 	rows, err := s.db.Query(`SELECT commit_hash FROM processed_commits WHERE status='failed' ORDER BY processed_at ASC`)
 	if err != nil {
@@ -229,13 +241,15 @@ func (s *Store) GetFailedCommits() ([]string, error) {
 	return out, rows.Err()
 }
 
+// GetRetryableCommits returns in-progress commits and failed ones whose
+// failure was not classified permanent.
 func (s *Store) GetRetryableCommits() ([]string, error) {
 	rows, err := s.db.Query(`
 		SELECT commit_hash
 		FROM processed_commits
-		WHERE status IN ('failed', 'in_progress')
+		WHERE status = 'in_progress' OR (status = 'failed' AND COALESCE(error_class, '') != ?)
 		ORDER BY processed_at ASC
-	`)
+	`, ErrorClassPermanent)
 	if err != nil {
 		return nil, err
 	}
@@ -277,8 +291,35 @@ func (s *Store) GetResumableCommits() ([]string, error) {
 	return out, rows.Err()
 }
 
+func (s *Store) GetCompletedCommits() (map[string]bool, error) {
+	rows, err := s.db.Query(`
+		SELECT commit_hash
+		FROM processed_commits
+		WHERE status IN ('success', 'skipped')
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]bool)
+	for rows.Next() {
+		var hash string
+		if scanErr := rows.Scan(&hash); scanErr != nil {
+			return nil, scanErr
+		}
+		out[hash] = true
+	}
+
+	return out, rows.Err()
+}
+
 func (s *Store) StoreMapping(commitHash, docFile, section string) error {
-	_, err := s.db.Exec(`INSERT INTO mappings (code_commit_hash, doc_file, section) VALUES (?, ?, ?)`, commitHash, docFile, section)
+	return storeMapping(s.db, commitHash, docFile, section)
+}
+
+func storeMapping(db execer, commitHash, docFile, section string) error {
+	_, err := db.Exec(`INSERT INTO mappings (code_commit_hash, doc_file, section) VALUES (?, ?, ?)`, commitHash, docFile, section)
 	return err
 }
 
@@ -294,36 +335,203 @@ func (s *Store) GetDocCommitHash(codeCommitHash string) (string, error) {
 	return hash, nil
 }
 
+// GetProcessedCommit returns the ledger row of commitHash, if any.
+func (s *Store) GetProcessedCommit(commitHash string) (ProcessedCommitRow, bool, error) {
+	row := s.db.QueryRow(`
+		SELECT commit_hash, processed_at, status, COALESCE(error, ''), COALESCE(error_class, ''), COALESCE(doc_commit_hash, '')
+		FROM processed_commits
+		WHERE commit_hash = ?
+	`, commitHash)
+	var out ProcessedCommitRow
+	var errStr string
+	var errClass string
+	var docCommit string
+	if err := row.Scan(&out.CommitHash, &out.ProcessedAt, &out.Status, &errStr, &errClass, &docCommit); err != nil {
+		if err == sql.ErrNoRows {
+			return ProcessedCommitRow{}, false, nil
+		}
+		return ProcessedCommitRow{}, false, err
+	}
+	if errStr != "" {
+		out.Error = sql.NullString{String: errStr, Valid: true}
+	}
+	if errClass != "" {
+		out.ErrorClass = sql.NullString{String: errClass, Valid: true}
+	}
+	if docCommit != "" {
+		out.DocCommit = sql.NullString{String: docCommit, Valid: true}
+	}
+	return out, true, nil
+}
+
+// GetLastSectionUpdate returns the most recent successfully processed code
+// commit mapped to a doc section, or an empty hash when there is none.
+func (s *Store) GetLastSectionUpdate(docFile, section string) (string, time.Time, error) {
+	row := s.db.QueryRow(`
+		SELECT p.commit_hash, p.processed_at
+		FROM mappings m
+		JOIN processed_commits p ON p.commit_hash = m.code_commit_hash
+		WHERE m.doc_file = ? AND m.section = ? AND p.status = 'success'
+		ORDER BY p.processed_at DESC
+		LIMIT 1
+	`, docFile, section)
+	var hash string
+	var at time.Time
+	if err := row.Scan(&hash, &at); err != nil {
+		if err == sql.ErrNoRows {
+			return "", time.Time{}, nil
+		}
+		return "", time.Time{}, err
+	}
+	return hash, at, nil
+}
+
+func (s *Store) RecordRevert(codeCommitHash, docCommitHash, revertCommitHash, runID string) error {
+	_, err := s.db.Exec(`INSERT INTO reverts (code_commit_hash, doc_commit_hash, revert_commit_hash, run_id) VALUES (?, ?, ?, ?)`, codeCommitHash, docCommitHash, revertCommitHash, runID)
+	return err
+}
+
+func (s *Store) GetRevertedCommits() (map[string]bool, error) {
+	rows, err := s.db.Query(`SELECT DISTINCT code_commit_hash FROM reverts`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string]bool{}
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		out[hash] = true
+	}
+	return out, rows.Err()
+}
+
+// GetRunCommits returns the code commits a run logged events for, in the
+// order the run first touched them.
+func (s *Store) GetRunCommits(runID string) ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT commit_hash
+		FROM run_events
+		WHERE run_id = ? AND COALESCE(commit_hash, '') != ''
+		GROUP BY commit_hash
+		ORDER BY MIN(id) ASC
+	`, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		out = append(out, hash)
+	}
+	return out, rows.Err()
+}
+
 func (s *Store) ListRecent(limit int) ([]ProcessedCommitRow, error) {
 	if limit <= 0 {
 		limit = 25
 	}
+	return s.QueryCommits(CommitFilter{Limit: limit})
+}
 
-	rows, err := s.db.Query(`
-		SELECT commit_hash, processed_at, status, COALESCE(error, ''), COALESCE(doc_commit_hash, '')
-		FROM processed_commits
-		ORDER BY processed_at DESC
-		LIMIT ?
-	`, limit)
+// CommitFilter selects processed commits. DocFile matches commits with a
+// planned update or mapping for that doc file, and Grep is a
+// case-insensitive substring of the error text.
+type CommitFilter struct {
+	Status  string
+	Since   time.Time
+	DocFile string
+	Grep    string
+	Limit   int
+	Offset  int
+}
+
+func (f CommitFilter) where() (string, []any) {
+	clauses := make([]string, 0, 4)
+	args := make([]any, 0, 5)
+	if f.Status != "" {
+		clauses = append(clauses, "status = ?")
+		args = append(args, f.Status)
+	}
+	if !f.Since.IsZero() {
+		clauses = append(clauses, "processed_at >= ?")
+		args = append(args, formatTimestamp(f.Since))
+	}
+	if f.DocFile != "" {
+		clauses = append(clauses, `(EXISTS (SELECT 1 FROM planned_updates WHERE planned_updates.commit_hash = processed_commits.commit_hash AND planned_updates.doc_file = ?)
+			OR EXISTS (SELECT 1 FROM mappings WHERE mappings.code_commit_hash = processed_commits.commit_hash AND mappings.doc_file = ?))`)
+		args = append(args, f.DocFile, f.DocFile)
+	}
+	if f.Grep != "" {
+		clauses = append(clauses, `error LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+likeEscaper.Replace(f.Grep)+"%")
+	}
+	if len(clauses) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(clauses, " AND "), args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// CountCommits returns how many processed commits match filter, ignoring
+// its Limit and Offset.
+func (s *Store) CountCommits(filter CommitFilter) (int, error) {
+	where, args := filter.where()
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM processed_commits`+where, args...).Scan(&count)
+	return count, err
+}
+
+// QueryCommits returns the processed commits matching filter, newest first.
+func (s *Store) QueryCommits(filter CommitFilter) ([]ProcessedCommitRow, error) {
+	where, args := filter.where()
+	query := `
+		SELECT commit_hash, processed_at, status, COALESCE(error, ''), COALESCE(error_class, ''), COALESCE(doc_commit_hash, ''),
+			COALESCE((SELECT revert_commit_hash FROM reverts WHERE reverts.code_commit_hash = processed_commits.commit_hash ORDER BY reverts.id DESC LIMIT 1), '')
+		FROM processed_commits` + where + `
+		ORDER BY processed_at DESC, commit_hash`
+	if filter.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, max(filter.Offset, 0))
+	}
+
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	out := make([]ProcessedCommitRow, 0, limit)
+	out := make([]ProcessedCommitRow, 0, max(filter.Limit, 0))
 	for rows.Next() {
 		var row ProcessedCommitRow
 		var errStr string
+		var errClass string
 		var docCommit string
-		if scanErr := rows.Scan(&row.CommitHash, &row.ProcessedAt, &row.Status, &errStr, &docCommit); scanErr != nil {
+		var revertedBy string
+		if scanErr := rows.Scan(&row.CommitHash, &row.ProcessedAt, &row.Status, &errStr, &errClass, &docCommit, &revertedBy); scanErr != nil {
 			return nil, scanErr
 		}
 		if errStr != "" {
 			row.Error = sql.NullString{String: errStr, Valid: true}
 		}
+		if errClass != "" {
+			row.ErrorClass = sql.NullString{String: errClass, Valid: true}
+		}
 		if docCommit != "" {
 			row.DocCommit = sql.NullString{String: docCommit, Valid: true}
 		}
+		if revertedBy != "" {
+			row.RevertedBy = sql.NullString{String: revertedBy, Valid: true}
+		}
 		out = append(out, row)
 	}
 
@@ -332,9 +540,9 @@ func (s *Store) ListRecent(limit int) ([]ProcessedCommitRow, error) {
 
 func (s *Store) GetStatusCounts() (StatusCounts, error) {
 	rows, err := s.db.Query(`
-		SELECT status, COUNT(*)
+		SELECT status, COALESCE(error_class, ''), COUNT(*)
 		FROM processed_commits
-		GROUP BY status
+		GROUP BY status, error_class
 	`)
 	if err != nil {
 		return StatusCounts{}, err
@@ -344,22 +552,26 @@ func (s *Store) GetStatusCounts() (StatusCounts, error) {
 	counts := StatusCounts{}
 	for rows.Next() {
 		var status string
+		var errClass string
 		var count int
-		if scanErr := rows.Scan(&status, &count); scanErr != nil {
+		if scanErr := rows.Scan(&status, &errClass, &count); scanErr != nil {
 			return StatusCounts{}, scanErr
 		}
 
 		switch status {
 		case "pending":
-			counts.Pending = count
+			counts.Pending += count
 		case "in_progress":
-			counts.InProgress = count
+			counts.InProgress += count
 		case "success":
-			counts.Success = count
+			counts.Success += count
 		case "failed":
-			counts.Failed = count
+			counts.Failed += count
+			if errClass == ErrorClassPermanent {
+				counts.FailedPermanent += count
+			}
 		case "skipped":
-			counts.Skipped = count
+			counts.Skipped += count
 		}
 	}
 
@@ -368,7 +580,11 @@ func (s *Store) GetStatusCounts() (StatusCounts, error) {
 }
 
 func (s *Store) UpsertPlannedUpdate(commitHash, docFile, sectionID, strategy, status, reason string) error {
-	_, err := s.db.Exec(`
+	return upsertPlannedUpdate(s.db, commitHash, docFile, sectionID, strategy, status, reason)
+}
+
+func upsertPlannedUpdate(db execer, commitHash, docFile, sectionID, strategy, status, reason string) error {
+	_, err := db.Exec(`
 	INSERT INTO planned_updates (commit_hash, doc_file, section_id, strategy, status, reason)
 	VALUES (?, ?, ?, ?, ?, ?)
 	ON CONFLICT(commit_hash, doc_file, section_id) DO UPDATE SET
@@ -397,20 +613,58 @@ func (s *Store) GetCachedLLMResponse(commitHash, docFile, sectionID, provider, m
 		return "", false, err
 	}
 
+	// An entry sealed with another key is treated as a miss and overwritten.
+	response, err := s.decrypt(response)
+	if err != nil {
+		return "", false, nil
+	}
 	return response, true, nil
 }
 
+// GetCachedResponseByPrompt finds a response cached for any commit with the
+// same prompt, provider and model, created at or after since. It serves
+// cherry-picks, reverts and other commits whose prompts repeat.
+func (s *Store) GetCachedResponseByPrompt(provider, model, prompt string, since time.Time) (string, bool, error) {
+	rows, err := s.db.Query(`
+		SELECT response_text
+		FROM llm_cache
+		WHERE prompt_hash = ? AND provider = ? AND model = ? AND created_at >= ?
+		ORDER BY created_at DESC, id DESC
+	`, hashPrompt(prompt), provider, model, formatTimestamp(since))
+	if err != nil {
+		return "", false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var response string
+		if err := rows.Scan(&response); err != nil {
+			return "", false, err
+		}
+		// Entries sealed with another key are skipped.
+		if response, err = s.decrypt(response); err == nil {
+			return response, true, nil
+		}
+	}
+	return "", false, rows.Err()
+}
+
 func (s *Store) PutCachedLLMResponse(entry LLMCacheEntry) error {
 	if entry.PromptHash == "" {
 		return fmt.Errorf("prompt hash is required for llm cache entry")
 	}
 
-	_, err := s.db.Exec(`
+	response, err := s.encrypt(entry.Response)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
 	INSERT INTO llm_cache (commit_hash, doc_file, section_id, provider, model, prompt_hash, response_text)
 	VALUES (?, ?, ?, ?, ?, ?, ?)
 	ON CONFLICT(commit_hash, doc_file, section_id, provider, model, prompt_hash) DO UPDATE SET
 		response_text = excluded.response_text
-	`, entry.CommitHash, entry.DocFile, entry.SectionID, entry.Provider, entry.Model, entry.PromptHash, entry.Response)
+	`, entry.CommitHash, entry.DocFile, entry.SectionID, entry.Provider, entry.Model, entry.PromptHash, response)
 	return err
 }
 
@@ -421,7 +675,10 @@ func (s *Store) LogRunEvent(runID, commitHash, level, component, message string,
 		if err != nil {
 			return err
 		}
-		metadataJSON = string(b)
+		metadataJSON, err = s.encrypt(string(b))
+		if err != nil {
+			return err
+		}
 	}
 
 	_, err := s.db.Exec(`
@@ -431,6 +688,184 @@ func (s *Store) LogRunEvent(runID, commitHash, level, component, message string,
 	return err
 }
 
+func (s *Store) StartRun(runID, trigger string, dryRun bool) error {
+	_, err := s.db.Exec(`
+	INSERT INTO runs (id, trigger_type, status, dry_run)
+	VALUES (?, ?, 'running', ?)
+	ON CONFLICT(id) DO UPDATE SET
+		trigger_type = excluded.trigger_type,
+		status = excluded.status,
+		dry_run = excluded.dry_run
+	`, runID, trigger, boolToInt(dryRun))
+	if err != nil {
+		return fmt.Errorf("start run: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) FinishRun(runID, status string, counts RunCounts) error {
+	_, err := s.db.Exec(`
+	UPDATE runs
+	SET finished_at = CURRENT_TIMESTAMP, status = ?, processed = ?, success = ?, failed = ?, skipped = ?
+	WHERE id = ?
+	`, status, counts.Processed, counts.Success, counts.Failed, counts.Skipped, runID)
+	if err != nil {
+		return fmt.Errorf("finish run: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) ListRuns(limit int) ([]RunRecord, error) {
+	if limit <= 0 {
+		limit = 25
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, started_at, finished_at, trigger_type, status, dry_run, processed, success, failed, skipped
+		FROM runs
+		ORDER BY started_at DESC, rowid DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]RunRecord, 0, limit)
+	for rows.Next() {
+		run, scanErr := scanRun(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		out = append(out, run)
+	}
+
+	return out, rows.Err()
+}
+
+func (s *Store) GetRun(runID string) (RunRecord, bool, error) {
+	row := s.db.QueryRow(`
+		SELECT id, started_at, finished_at, trigger_type, status, dry_run, processed, success, failed, skipped
+		FROM runs
+		WHERE id = ?
+	`, runID)
+
+	run, err := scanRun(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return RunRecord{}, false, nil
+		}
+		return RunRecord{}, false, err
+	}
+	return run, true, nil
+}
+
+type RunEventFilter struct {
+	RunID      string
+	CommitHash string
+	Level      string
+	Component  string
+	Since      time.Time
+	Limit      int
+}
+
+func (s *Store) ListRunEvents(runID string) ([]RunEvent, error) {
+	return s.QueryRunEvents(RunEventFilter{RunID: runID})
+}
+
+func (s *Store) QueryRunEvents(filter RunEventFilter) ([]RunEvent, error) {
+	clauses := make([]string, 0, 5)
+	args := make([]any, 0, 6)
+
+	if filter.RunID != "" {
+		clauses = append(clauses, "run_id = ?")
+		args = append(args, filter.RunID)
+	}
+	if filter.CommitHash != "" {
+		clauses = append(clauses, "commit_hash LIKE ?")
+		args = append(args, filter.CommitHash+"%")
+	}
+	if filter.Level != "" {
+		clauses = append(clauses, "level = ?")
+		args = append(args, filter.Level)
+	}
+	if filter.Component != "" {
+		clauses = append(clauses, "component = ?")
+		args = append(args, filter.Component)
+	}
+	if !filter.Since.IsZero() {
+		clauses = append(clauses, "created_at >= ?")
+		args = append(args, formatTimestamp(filter.Since))
+	}
+
+	query := `SELECT id, run_id, COALESCE(commit_hash, ''), level, component, message, COALESCE(metadata, ''), created_at FROM run_events`
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	if filter.Limit > 0 {
+		query += " ORDER BY id DESC LIMIT ?"
+		args = append(args, filter.Limit)
+	} else {
+		query += " ORDER BY id ASC"
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []RunEvent
+	for rows.Next() {
+		var event RunEvent
+		if scanErr := rows.Scan(&event.ID, &event.RunID, &event.CommitHash, &event.Level, &event.Component, &event.Message, &event.Metadata, &event.CreatedAt); scanErr != nil {
+			return nil, scanErr
+		}
+		if metadata, err := s.decrypt(event.Metadata); err != nil {
+			event.Metadata = EncryptedPlaceholder
+		} else {
+			event.Metadata = metadata
+		}
+		out = append(out, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if filter.Limit > 0 {
+		for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+			out[i], out[j] = out[j], out[i]
+		}
+	}
+
+	return out, nil
+}
+
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRun(row rowScanner) (RunRecord, error) {
+	var run RunRecord
+	var dryRun int
+	if err := row.Scan(&run.ID, &run.StartedAt, &run.FinishedAt, &run.Trigger, &run.Status, &dryRun, &run.Processed, &run.Success, &run.Failed, &run.Skipped); err != nil {
+		return RunRecord{}, err
+	}
+	run.DryRun = dryRun != 0
+	return run, nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 func hashPrompt(prompt string) string {
 	sum := sha256.Sum256([]byte(prompt))
 	return fmt.Sprintf("%x", sum)
@@ -442,3 +877,187 @@ func nullIfEmpty(s string) any {
 	}
 	return s
 }
+
+// RemapCommit moves state recorded for a rewritten commit (amend, rebase) to
+// its new hash and reports whether anything referenced the old hash. An
+// existing row for the new hash wins over the remapped one.
+func (s *Store) RemapCommit(oldHash, newHash string) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	stmts := []string{
+		`UPDATE OR IGNORE processed_commits SET commit_hash = ? WHERE commit_hash = ?`,
+		`UPDATE processed_commits SET doc_commit_hash = ? WHERE doc_commit_hash = ?`,
+		`UPDATE mappings SET code_commit_hash = ? WHERE code_commit_hash = ?`,
+		`UPDATE OR IGNORE planned_updates SET commit_hash = ? WHERE commit_hash = ?`,
+		`UPDATE OR IGNORE llm_cache SET commit_hash = ? WHERE commit_hash = ?`,
+		`UPDATE reverts SET code_commit_hash = ? WHERE code_commit_hash = ?`,
+		`UPDATE reverts SET doc_commit_hash = ? WHERE doc_commit_hash = ?`,
+		`UPDATE reverts SET revert_commit_hash = ? WHERE revert_commit_hash = ?`,
+	}
+	cleanup := []string{
+		`DELETE FROM processed_commits WHERE commit_hash = ?`,
+		`DELETE FROM planned_updates WHERE commit_hash = ?`,
+		`DELETE FROM llm_cache WHERE commit_hash = ?`,
+	}
+
+	var touched int64
+	for _, stmt := range stmts {
+		res, err := tx.Exec(stmt, newHash, oldHash)
+		if err != nil {
+			return false, fmt.Errorf("remap commit %s: %w", oldHash, err)
+		}
+		n, _ := res.RowsAffected()
+		touched += n
+	}
+	for _, stmt := range cleanup {
+		res, err := tx.Exec(stmt, oldHash)
+		if err != nil {
+			return false, fmt.Errorf("remap commit %s: %w", oldHash, err)
+		}
+		n, _ := res.RowsAffected()
+		touched += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return touched > 0, nil
+}
+
+// PurgeLLMCache deletes every cached LLM response and returns how many rows
+// were removed.
+func (s *Store) PurgeLLMCache() (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM llm_cache`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ScrubRunEventMetadata clears the metadata of run events, which can hold
+// error text and provider output, keeping the events themselves.
+func (s *Store) ScrubRunEventMetadata() (int64, error) {
+	res, err := s.db.Exec(`UPDATE run_events SET metadata = NULL WHERE metadata IS NOT NULL`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Vacuum rebuilds the database file so deleted rows do not linger in free
+// pages.
+func (s *Store) Vacuum() error {
+	_, err := s.db.Exec(`VACUUM`)
+	return err
+}
+
+// IntegrityCheck runs PRAGMA integrity_check and returns the problems it
+// reports, or nil for a healthy database.
+func (s *Store) IntegrityCheck() ([]string, error) {
+	rows, err := s.db.Query(`PRAGMA integrity_check`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	return problems, rows.Err()
+}
+
+type PruneResult struct {
+	RunEvents int64
+	Runs      int64
+	LLMCache  int64
+	PromptLog int64
+}
+
+// Prune deletes run events, finished runs, cached LLM responses and logged
+// prompts created before the cutoff. The processed-commit ledger and mappings are kept.
+func (s *Store) Prune(before time.Time) (PruneResult, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return PruneResult{}, err
+	}
+	defer tx.Rollback()
+
+	cutoff := formatTimestamp(before)
+	var result PruneResult
+	for _, step := range []struct {
+		query string
+		count *int64
+	}{
+		{`DELETE FROM run_events WHERE created_at < ?`, &result.RunEvents},
+		{`DELETE FROM runs WHERE started_at < ? AND status != 'running'`, &result.Runs},
+		{`DELETE FROM llm_cache WHERE created_at < ?`, &result.LLMCache},
+		{`DELETE FROM prompt_log WHERE created_at < ?`, &result.PromptLog},
+	} {
+		res, err := tx.Exec(step.query, cutoff)
+		if err != nil {
+			return PruneResult{}, err
+		}
+		if *step.count, err = res.RowsAffected(); err != nil {
+			return PruneResult{}, err
+		}
+	}
+
+	return result, tx.Commit()
+}
+
+type TableSize struct {
+	Name  string `json:"name"`
+	Rows  int64  `json:"rows"`
+	Bytes int64  `json:"bytes"`
+}
+
+// TableSizes reports row counts and on-disk bytes per table, with index pages
+// counted towards their table.
+func (s *Store) TableSizes() ([]TableSize, error) {
+	rows, err := s.db.Query(`
+		SELECT m.tbl_name, SUM(d.pgsize)
+		FROM dbstat d
+		JOIN sqlite_master m ON m.name = d.name
+		GROUP BY m.tbl_name
+		ORDER BY SUM(d.pgsize) DESC, m.tbl_name ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	var sizes []TableSize
+	for rows.Next() {
+		var size TableSize
+		if err := rows.Scan(&size.Name, &size.Bytes); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		sizes = append(sizes, size)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range sizes {
+		if err := s.db.QueryRow(`SELECT COUNT(*) FROM "` + sizes[i].Name + `"`).Scan(&sizes[i].Rows); err != nil {
+			return nil, err
+		}
+	}
+	return sizes, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
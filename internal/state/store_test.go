@@ -2,8 +2,13 @@ package state
 
 import (
 	"database/sql"
+	"fmt"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestStoreRoundTrip(t *testing.T) {
@@ -121,6 +126,52 @@ func TestGetRetryableCommits(t *testing.T) {
 	}
 }
 
+func TestPermanentFailuresAreNotRetryable(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+
+	if err := store.MarkCommitFailed("p1", "target doc file not found: docs/api.md", ErrorClassPermanent); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.MarkCommitFailed("t1", "openai request failed: overloaded", ErrorClassTransient); err != nil {
+		t.Fatal(err)
+	}
+
+	retryable, err := store.GetRetryableCommits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(retryable) != 1 || retryable[0] != "t1" {
+		t.Fatalf("expected only the transient failure to be retryable, got %v", retryable)
+	}
+
+	counts, err := store.GetStatusCounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts.Failed != 2 || counts.FailedPermanent != 1 {
+		t.Fatalf("unexpected counts: %+v", counts)
+	}
+
+	row, ok, err := store.GetProcessedCommit("p1")
+	if err != nil || !ok {
+		t.Fatalf("get processed commit: ok=%v err=%v", ok, err)
+	}
+	if row.ErrorClass.String != ErrorClassPermanent {
+		t.Fatalf("expected permanent class, got %q", row.ErrorClass.String)
+	}
+
+	if err := store.MarkCommitProcessed("p1", "pending", "", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	row, _, _ = store.GetProcessedCommit("p1")
+	if row.ErrorClass.Valid {
+		t.Fatalf("expected the class to clear once the commit is reprocessed, got %q", row.ErrorClass.String)
+	}
+}
+
 func TestPlannedUpdateCacheAndRunEvents(t *testing.T) {
 	dbPath := filepath.Join(t.TempDir(), "state.db")
 	store, err := New(dbPath)
@@ -166,3 +217,971 @@ func TestPlannedUpdateCacheAndRunEvents(t *testing.T) {
 		t.Fatalf("expected 1 run event, got %d", count)
 	}
 }
+
+func TestRunLifecycleAndEvents(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "state.db")
+	store, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+
+	if err := store.StartRun("run-a", "manual", true); err != nil {
+		t.Fatalf("start run: %v", err)
+	}
+	if err := store.LogRunEvent("run-a", "c1", "info", "orchestrator", "hello", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	run, ok, err := store.GetRun("run-a")
+	if err != nil || !ok {
+		t.Fatalf("get run: ok=%v err=%v", ok, err)
+	}
+	if run.Status != "running" || !run.DryRun || run.Trigger != "manual" || run.FinishedAt.Valid {
+		t.Fatalf("unexpected running run: %+v", run)
+	}
+
+	if err := store.FinishRun("run-a", "finished", RunCounts{Processed: 3, Success: 2, Failed: 1}); err != nil {
+		t.Fatalf("finish run: %v", err)
+	}
+
+	runs, err := store.ListRuns(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Status != "finished" || runs[0].Processed != 3 || runs[0].Failed != 1 || !runs[0].FinishedAt.Valid {
+		t.Fatalf("unexpected runs: %+v", runs)
+	}
+
+	events, err := store.ListRunEvents("run-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].CommitHash != "c1" || events[0].Message != "hello" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	if _, ok, err := store.GetRun("missing"); err != nil || ok {
+		t.Fatalf("expected missing run lookup to return ok=false, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestQueryRunEventsFilters(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "state.db")
+	store, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+
+	_ = store.LogRunEvent("run-1", "abc123", "info", "orchestrator", "started", nil)
+	_ = store.LogRunEvent("run-1", "abc123", "error", "llm", "boom", map[string]any{"error": "x"})
+	_ = store.LogRunEvent("run-2", "def456", "error", "state", "db", nil)
+
+	events, err := store.QueryRunEvents(RunEventFilter{Level: "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 error events, got %d", len(events))
+	}
+
+	events, err = store.QueryRunEvents(RunEventFilter{RunID: "run-1", Component: "llm", CommitHash: "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Message != "boom" {
+		t.Fatalf("unexpected filtered events: %+v", events)
+	}
+
+	events, err = store.QueryRunEvents(RunEventFilter{Since: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no events in the future, got %d", len(events))
+	}
+
+	events, err = store.QueryRunEvents(RunEventFilter{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[1].Message != "db" {
+		t.Fatalf("expected the 2 most recent events in ascending order, got %+v", events)
+	}
+}
+
+func TestRemapCommit(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "state.db")
+	store, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+
+	if err := store.MarkCommitProcessed("old", "success", "", "doc-old", []string{"README.md"}); err != nil {
+		t.Fatalf("mark commit: %v", err)
+	}
+	if err := store.StoreMapping("old", "README.md", "Recent Changes"); err != nil {
+		t.Fatalf("store mapping: %v", err)
+	}
+	if err := store.UpsertPlannedUpdate("old", "README.md", "Recent Changes", "inferred", "applied", ""); err != nil {
+		t.Fatalf("upsert planned update: %v", err)
+	}
+
+	remapped, err := store.RemapCommit("old", "new")
+	if err != nil {
+		t.Fatalf("remap commit: %v", err)
+	}
+	if !remapped {
+		t.Fatalf("expected remap to touch rows")
+	}
+
+	last, err := store.GetLastProcessedCommit()
+	if err != nil {
+		t.Fatalf("get last processed: %v", err)
+	}
+	if last != "new" {
+		t.Fatalf("expected new hash to be last processed, got %q", last)
+	}
+	docCommit, err := store.GetDocCommitHash("new")
+	if err != nil {
+		t.Fatalf("get doc commit hash: %v", err)
+	}
+	if docCommit != "doc-old" {
+		t.Fatalf("expected mapping to follow rewrite, got %q", docCommit)
+	}
+
+	if _, err := store.RemapCommit("doc-old", "doc-new"); err != nil {
+		t.Fatalf("remap doc commit: %v", err)
+	}
+	if docCommit, _ := store.GetDocCommitHash("new"); docCommit != "doc-new" {
+		t.Fatalf("expected rewritten doc commit hash, got %q", docCommit)
+	}
+
+	remapped, err = store.RemapCommit("unknown", "other")
+	if err != nil {
+		t.Fatalf("remap unknown commit: %v", err)
+	}
+	if remapped {
+		t.Fatalf("expected unknown commit not to be remapped")
+	}
+}
+
+func TestRecordRevertShowsInStatus(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "state.db")
+	store, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+
+	if err := store.MarkCommitProcessed("abc", "success", "", "doc123", []string{"README.md"}); err != nil {
+		t.Fatalf("mark commit: %v", err)
+	}
+	if err := store.LogRunEvent("run-1", "abc", "info", "orchestrator", "processed", nil); err != nil {
+		t.Fatalf("log run event: %v", err)
+	}
+	if err := store.RecordRevert("abc", "doc123", "rev456", "run-2"); err != nil {
+		t.Fatalf("record revert: %v", err)
+	}
+
+	rows, err := store.ListRecent(10)
+	if err != nil {
+		t.Fatalf("list recent: %v", err)
+	}
+	if len(rows) != 1 || !rows[0].RevertedBy.Valid || rows[0].RevertedBy.String != "rev456" {
+		t.Fatalf("expected reverted entry in status rows, got %+v", rows)
+	}
+
+	reverted, err := store.GetRevertedCommits()
+	if err != nil {
+		t.Fatalf("get reverted commits: %v", err)
+	}
+	if !reverted["abc"] {
+		t.Fatalf("expected abc to be reverted, got %v", reverted)
+	}
+
+	commits, err := store.GetRunCommits("run-1")
+	if err != nil {
+		t.Fatalf("get run commits: %v", err)
+	}
+	if len(commits) != 1 || commits[0] != "abc" {
+		t.Fatalf("unexpected run commits: %v", commits)
+	}
+}
+
+func TestEncryptedCacheAndEventMetadata(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "state.db")
+	store, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+
+	prompt := "plain-prompt"
+	if err := store.PutCachedLLMResponse(LLMCacheEntry{CommitHash: "c0", DocFile: "README.md", SectionID: "S", Provider: "mock", Model: "m", PromptHash: hashPrompt(prompt), Response: "plain-response"}); err != nil {
+		t.Fatalf("put plaintext cache: %v", err)
+	}
+
+	if err := store.SetEncryptionKey("s3cret"); err != nil {
+		t.Fatalf("set key: %v", err)
+	}
+	if err := store.PutCachedLLMResponse(LLMCacheEntry{CommitHash: "c1", DocFile: "README.md", SectionID: "S", Provider: "mock", Model: "m", PromptHash: hashPrompt(prompt), Response: "secret-response"}); err != nil {
+		t.Fatalf("put encrypted cache: %v", err)
+	}
+	if err := store.LogRunEvent("run-1", "c1", "info", "test", "message", map[string]any{"diff": "+password"}); err != nil {
+		t.Fatalf("log run event: %v", err)
+	}
+
+	var raw string
+	if err := store.db.QueryRow(`SELECT response_text FROM llm_cache WHERE commit_hash = 'c1'`).Scan(&raw); err != nil {
+		t.Fatalf("query raw cache: %v", err)
+	}
+	if raw == "secret-response" || !strings.HasPrefix(raw, encryptedPrefix) {
+		t.Fatalf("expected response to be encrypted at rest, got %q", raw)
+	}
+
+	if resp, hit, err := store.GetCachedLLMResponse("c1", "README.md", "S", "mock", "m", prompt); err != nil || !hit || resp != "secret-response" {
+		t.Fatalf("expected decrypted cache hit, got %q hit=%v err=%v", resp, hit, err)
+	}
+	if resp, hit, err := store.GetCachedLLMResponse("c0", "README.md", "S", "mock", "m", prompt); err != nil || !hit || resp != "plain-response" {
+		t.Fatalf("expected plaintext entries to stay readable, got %q hit=%v err=%v", resp, hit, err)
+	}
+	events, err := store.ListRunEvents("run-1")
+	if err != nil || len(events) != 1 || events[0].Metadata != `{"diff":"+password"}` {
+		t.Fatalf("expected decrypted metadata, got %+v err=%v", events, err)
+	}
+
+	if err := store.SetEncryptionKey("other"); err != nil {
+		t.Fatalf("set key: %v", err)
+	}
+	if _, hit, err := store.GetCachedLLMResponse("c1", "README.md", "S", "mock", "m", prompt); err != nil || hit {
+		t.Fatalf("expected entry sealed with another key to miss, hit=%v err=%v", hit, err)
+	}
+	events, err = store.ListRunEvents("run-1")
+	if err != nil || events[0].Metadata != EncryptedPlaceholder {
+		t.Fatalf("expected placeholder metadata, got %+v err=%v", events, err)
+	}
+
+	if err := store.LogPrompt(PromptLogEntry{RunID: "run-1", CommitHash: "c1", Purpose: "generate", Provider: "mock", Model: "m", Prompt: prompt, Status: "success"}); err != nil {
+		t.Fatalf("log prompt: %v", err)
+	}
+
+	purged, err := store.PurgeLLMCache()
+	if err != nil || purged != 2 {
+		t.Fatalf("expected 2 purged cache rows, got %d err=%v", purged, err)
+	}
+	scrubbed, err := store.ScrubRunEventMetadata()
+	if err != nil || scrubbed != 1 {
+		t.Fatalf("expected 1 scrubbed event, got %d err=%v", scrubbed, err)
+	}
+	prompts, err := store.PurgePromptLog()
+	if err != nil || prompts != 1 {
+		t.Fatalf("expected 1 purged prompt, got %d err=%v", prompts, err)
+	}
+	logged, err := store.ListPromptLog(time.Time{})
+	if err != nil || len(logged) != 1 || logged[0].Prompt != "" || logged[0].PromptHash == "" {
+		t.Fatalf("expected the prompt hash without its text, got %+v err=%v", logged, err)
+	}
+	if err := store.Vacuum(); err != nil {
+		t.Fatalf("vacuum: %v", err)
+	}
+}
+
+func TestPruneAndTableSizes(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "state.db")
+	store, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+
+	for _, runID := range []string{"old-run", "new-run"} {
+		if err := store.StartRun(runID, "manual", false); err != nil {
+			t.Fatalf("start run: %v", err)
+		}
+		if err := store.LogRunEvent(runID, "c1", "info", "test", "event", nil); err != nil {
+			t.Fatalf("log run event: %v", err)
+		}
+	}
+	if err := store.FinishRun("old-run", "finished", RunCounts{}); err != nil {
+		t.Fatalf("finish run: %v", err)
+	}
+	if err := store.PutCachedLLMResponse(LLMCacheEntry{CommitHash: "c1", DocFile: "README.md", SectionID: "S", Provider: "mock", Model: "m", PromptHash: hashPrompt("p"), Response: "r"}); err != nil {
+		t.Fatalf("put cache: %v", err)
+	}
+	if err := store.MarkCommitProcessed("c1", "success", "", "", nil); err != nil {
+		t.Fatalf("mark commit: %v", err)
+	}
+
+	old := formatTimestamp(time.Now().AddDate(0, 0, -60))
+	for _, stmt := range []string{
+		`UPDATE runs SET started_at = ? WHERE id = 'old-run'`,
+		`UPDATE run_events SET created_at = ? WHERE run_id = 'old-run'`,
+		`UPDATE llm_cache SET created_at = ?`,
+	} {
+		if _, err := store.db.Exec(stmt, old); err != nil {
+			t.Fatalf("age rows: %v", err)
+		}
+	}
+
+	result, err := store.Prune(time.Now().AddDate(0, 0, -30))
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if result != (PruneResult{RunEvents: 1, Runs: 1, LLMCache: 1}) {
+		t.Fatalf("unexpected prune result: %+v", result)
+	}
+	if _, found, _ := store.GetRun("new-run"); !found {
+		t.Fatalf("expected recent run to be kept")
+	}
+	if _, found, _ := store.GetProcessedCommit("c1"); !found {
+		t.Fatalf("expected processed commits to be kept")
+	}
+
+	sizes, err := store.TableSizes()
+	if err != nil {
+		t.Fatalf("table sizes: %v", err)
+	}
+	byName := map[string]TableSize{}
+	for _, size := range sizes {
+		byName[size.Name] = size
+	}
+	if byName["run_events"].Rows != 1 || byName["processed_commits"].Rows != 1 || byName["llm_cache"].Bytes == 0 {
+		t.Fatalf("unexpected table sizes: %+v", sizes)
+	}
+}
+
+func TestExportImportSnapshot(t *testing.T) {
+	source, err := New(filepath.Join(t.TempDir(), "source.db"))
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+	if err := source.MarkCommitProcessed("c1", "success", "", "doc1", []string{"README.md"}); err != nil {
+		t.Fatalf("mark commit: %v", err)
+	}
+	if err := source.StoreMapping("c1", "README.md", "Recent Changes"); err != nil {
+		t.Fatalf("store mapping: %v", err)
+	}
+	if err := source.MarkCommitProcessed("c2", "skipped", "", "", nil); err != nil {
+		t.Fatalf("mark commit: %v", err)
+	}
+	if err := source.MarkCommitProcessed("c3", "in_progress", "", "", nil); err != nil {
+		t.Fatalf("mark commit: %v", err)
+	}
+	if err := source.RecordRevert("c1", "doc1", "rev1", "run-1"); err != nil {
+		t.Fatalf("record revert: %v", err)
+	}
+	if err := source.LogRunEvent("run-1", "c1", "info", "test", "local only", nil); err != nil {
+		t.Fatalf("log run event: %v", err)
+	}
+
+	snapshot, err := source.Export()
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if len(snapshot.ProcessedCommits) != 2 || len(snapshot.Mappings) != 1 || len(snapshot.Reverts) != 1 {
+		t.Fatalf("unexpected snapshot contents: %+v", snapshot)
+	}
+
+	target, err := New(filepath.Join(t.TempDir(), "target.db"))
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+	if err := target.MarkCommitProcessed("c2", "failed", "local failure", "", nil); err != nil {
+		t.Fatalf("mark commit: %v", err)
+	}
+
+	result, err := target.Import(snapshot, false)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if result != (ImportResult{Commits: 1, Mappings: 1, Reverts: 1}) {
+		t.Fatalf("unexpected import result: %+v", result)
+	}
+	if last, err := target.GetLastProcessedCommit(); err != nil || last != "c1" {
+		t.Fatalf("expected imported checkpoint c1, got %q err=%v", last, err)
+	}
+	if doc, err := target.GetDocCommitHash("c1"); err != nil || doc != "doc1" {
+		t.Fatalf("expected imported doc commit, got %q err=%v", doc, err)
+	}
+	if row, _, _ := target.GetProcessedCommit("c2"); row.Status != "failed" {
+		t.Fatalf("expected local row to be kept without overwrite, got %q", row.Status)
+	}
+
+	again, err := target.Import(snapshot, true)
+	if err != nil {
+		t.Fatalf("reimport: %v", err)
+	}
+	if again.Mappings != 0 || again.Reverts != 0 {
+		t.Fatalf("expected mappings and reverts not to be duplicated, got %+v", again)
+	}
+	if row, _, _ := target.GetProcessedCommit("c2"); row.Status != "skipped" {
+		t.Fatalf("expected overwrite to replace local row, got %q", row.Status)
+	}
+}
+
+func TestOpenSelectsBackendByLocation(t *testing.T) {
+	dir := t.TempDir()
+	for _, location := range []string{filepath.Join(dir, "plain.db"), "sqlite://" + filepath.Join(dir, "url.db")} {
+		backend, err := Open(location, "")
+		if err != nil {
+			t.Fatalf("open %s: %v", location, err)
+		}
+		if err := backend.MarkCommitProcessed("c1", "success", "", "", nil); err != nil {
+			t.Fatalf("mark commit via %s: %v", location, err)
+		}
+		_ = backend.Close()
+	}
+
+	if _, err := Open("postgres://ci@db.internal/gitdoc", ""); err == nil || !strings.Contains(err.Error(), "not available in this build") {
+		t.Fatalf("expected unavailable backend error, got %v", err)
+	}
+	if _, err := Open("redis://localhost", ""); err == nil || !strings.Contains(err.Error(), "unsupported state backend") {
+		t.Fatalf("expected unsupported backend error, got %v", err)
+	}
+}
+
+func TestConcurrentStoresShareDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "state.db")
+	first, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+	defer first.Close()
+	second, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open second store: %v", err)
+	}
+	defer second.Close()
+
+	var mode string
+	if err := first.db.QueryRow(`PRAGMA journal_mode`).Scan(&mode); err != nil || mode != "wal" {
+		t.Fatalf("expected WAL journal mode, got %q err=%v", mode, err)
+	}
+
+	errs := make(chan error, 2)
+	for i, store := range []*Store{first, second} {
+		go func(i int, store *Store) {
+			for j := 0; j < 25; j++ {
+				hash := fmt.Sprintf("c%d-%d", i, j)
+				if err := store.CompleteCommit(CompletedCommit{CommitHash: hash, DocFile: "README.md", Section: "S", Strategy: "inferred", Mapped: true}); err != nil {
+					errs <- err
+					return
+				}
+				if err := store.LogRunEvent("run", hash, "info", "test", "done", nil); err != nil {
+					errs <- err
+					return
+				}
+			}
+			errs <- nil
+		}(i, store)
+	}
+	for range 2 {
+		if err := <-errs; err != nil {
+			t.Fatalf("concurrent write failed: %v", err)
+		}
+	}
+
+	counts, err := first.GetStatusCounts()
+	if err != nil || counts.Success != 50 {
+		t.Fatalf("expected 50 completed commits, got %+v err=%v", counts, err)
+	}
+	if doc, _, err := second.GetLastSectionUpdate("README.md", "S"); err != nil || doc == "" {
+		t.Fatalf("expected mappings recorded with completed commits, got %q err=%v", doc, err)
+	}
+}
+
+func TestMigrateUpgradesLegacyDatabaseAndRejectsNewerSchema(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "state.db")
+	legacy, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := legacy.Exec(`CREATE TABLE processed_commits (
+		commit_hash TEXT PRIMARY KEY,
+		processed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		status TEXT CHECK(status IN ('success', 'failed', 'skipped')),
+		error TEXT,
+		doc_commit_hash TEXT,
+		doc_files_changed TEXT,
+		metadata TEXT
+	)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := legacy.Exec(`INSERT INTO processed_commits (commit_hash, status) VALUES ('old', 'success')`); err != nil {
+		t.Fatal(err)
+	}
+	legacy.Close()
+
+	store, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("open legacy database: %v", err)
+	}
+	if version, err := store.SchemaVersion(); err != nil || version != LatestSchemaVersion {
+		t.Fatalf("expected schema version %d, got %d err=%v", LatestSchemaVersion, version, err)
+	}
+	if err := store.MarkCommitProcessed("new", "pending", "", "", nil); err != nil {
+		t.Fatalf("expected pending status after migration: %v", err)
+	}
+	if last, err := store.GetLastProcessedCommit(); err != nil || last != "old" {
+		t.Fatalf("expected legacy rows to survive migration, got %q err=%v", last, err)
+	}
+
+	if _, err := store.db.Exec(`INSERT INTO schema_version (version, description) VALUES (?, 'from the future')`, LatestSchemaVersion+1); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	if _, err := New(dbPath); err == nil || !strings.Contains(err.Error(), "newer than this git-doc supports") {
+		t.Fatalf("expected downgrade to be rejected, got %v", err)
+	}
+}
+
+func TestSectionEmbeddingsRoundTrip(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+	defer store.Close()
+
+	first := SectionEmbedding{DocFile: "docs/api.md", Section: "Endpoints", Model: "mock", ContentHash: "h1", Vector: []float32{0.5, -1, 2}}
+	if err := store.PutSectionEmbedding(first); err != nil {
+		t.Fatalf("put embedding: %v", err)
+	}
+	first.ContentHash, first.Vector = "h2", []float32{1, 0}
+	if err := store.PutSectionEmbedding(first); err != nil {
+		t.Fatalf("replace embedding: %v", err)
+	}
+	if err := store.PutSectionEmbedding(SectionEmbedding{DocFile: "README.md", Section: "Usage", Model: "other", ContentHash: "h3", Vector: []float32{1}}); err != nil {
+		t.Fatalf("put embedding: %v", err)
+	}
+
+	got, err := store.GetSectionEmbeddings("mock")
+	if err != nil {
+		t.Fatalf("get embeddings: %v", err)
+	}
+	if len(got) != 1 || got[0].ContentHash != "h2" || len(got[0].Vector) != 2 || got[0].Vector[0] != 1 {
+		t.Fatalf("expected the replaced embedding for the mock model only, got %+v", got)
+	}
+}
+
+func TestReplaceDocInventory(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+	defer store.Close()
+
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.ReplaceDocInventory([]DocSection{
+		{DocFile: "README.md", Path: "Title", Section: "Title", Level: 1, Anchor: "title", Words: 3},
+		{DocFile: "README.md", Path: "Title > Usage", Section: "Usage", Level: 2, Line: 4, Anchor: "usage", Words: 10, LastCommit: "abc", LastModifiedAt: modified},
+	}); err != nil {
+		t.Fatalf("replace inventory: %v", err)
+	}
+	if err := store.ReplaceDocInventory([]DocSection{
+		{DocFile: "README.md", Path: "Title > Usage", Section: "Usage", Level: 2, Line: 4, Anchor: "usage", Words: 12, LastCommit: "def", LastModifiedAt: modified},
+	}); err != nil {
+		t.Fatalf("replace inventory: %v", err)
+	}
+
+	got, err := store.GetDocInventory()
+	if err != nil {
+		t.Fatalf("get inventory: %v", err)
+	}
+	if len(got) != 1 || got[0].Words != 12 || got[0].LastCommit != "def" || !got[0].LastModifiedAt.Equal(modified) || got[0].ScannedAt.IsZero() {
+		t.Fatalf("expected the second scan to replace the first, got %+v", got)
+	}
+}
+
+func TestExemplarsRoundTrip(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+	defer store.Close()
+	if err := store.SetEncryptionKey("exemplar-key"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.PutExemplar(Exemplar{CommitHash: "a1", DocFile: "README.md", Section: "API", Message: "feat: add login", Files: []string{"api/login.go", "api/auth.go"}, Content: "- Added login"}); err != nil {
+		t.Fatalf("put exemplar: %v", err)
+	}
+	if err := store.PutExemplar(Exemplar{CommitHash: "a1", DocFile: "README.md", Section: "API", Message: "feat: add login", Content: "- Added login endpoint"}); err != nil {
+		t.Fatalf("put exemplar: %v", err)
+	}
+
+	got, err := store.GetExemplars(10)
+	if err != nil {
+		t.Fatalf("get exemplars: %v", err)
+	}
+	if len(got) != 1 || got[0].Content != "- Added login endpoint" || got[0].Message != "feat: add login" || len(got[0].Files) != 0 {
+		t.Fatalf("expected the second put to replace the first, got %+v", got)
+	}
+
+	var raw string
+	if err := store.db.QueryRow(`SELECT content FROM exemplars`).Scan(&raw); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(raw, "login") {
+		t.Fatalf("expected exemplar content to be encrypted, got %q", raw)
+	}
+}
+
+func TestGeneratedSectionsTrackHashAndContent(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+	defer store.Close()
+
+	if _, ok, err := store.GetGeneratedSection("README.md", "API"); err != nil || ok {
+		t.Fatalf("expected no generated section yet, got ok=%v err=%v", ok, err)
+	}
+	if err := store.PutGeneratedSection(GeneratedSection{DocFile: "README.md", Section: "API", Content: "- Added login\r\n", CommitHash: "a1"}); err != nil {
+		t.Fatalf("put generated section: %v", err)
+	}
+	if err := store.SetEncryptionKey("generated-key"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.PutGeneratedSection(GeneratedSection{DocFile: "README.md", Section: "API", Content: "- Added login endpoint", CommitHash: "a2"}); err != nil {
+		t.Fatalf("put generated section: %v", err)
+	}
+
+	got, ok, err := store.GetGeneratedSection("README.md", "API")
+	if err != nil || !ok {
+		t.Fatalf("get generated section: ok=%v err=%v", ok, err)
+	}
+	if got.Content != "- Added login endpoint" || got.CommitHash != "a2" || got.ContentHash != SectionHash("- Added login endpoint\n") {
+		t.Fatalf("expected the second put to replace the first, got %+v", got)
+	}
+
+	if err := store.SetEncryptionKey("other-key"); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err = store.GetGeneratedSection("README.md", "API")
+	if err != nil || !ok || got.Content != "" || got.ContentHash != SectionHash("- Added login endpoint") {
+		t.Fatalf("expected the hash without content under another key, got %+v ok=%v err=%v", got, ok, err)
+	}
+}
+
+func TestSectionUpdatesAndDigests(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+	defer store.Close()
+
+	if _, ok, err := store.LastDigest(); err != nil || ok {
+		t.Fatalf("expected no digest yet, got ok=%v err=%v", ok, err)
+	}
+
+	before := time.Now().Add(-time.Minute)
+	if err := store.CompleteCommit(CompletedCommit{CommitHash: "c1", DocCommit: "d1", DocFiles: []string{"README.md"}, DocFile: "README.md", Section: "API", Strategy: "inferred", Mapped: true}); err != nil {
+		t.Fatalf("complete commit: %v", err)
+	}
+	if err := store.MarkCommitFailed("c2", "provider down", ErrorClassTransient); err != nil {
+		t.Fatal(err)
+	}
+	after := time.Now().Add(time.Minute)
+
+	updates, err := store.ListSectionUpdates(before, after)
+	if err != nil {
+		t.Fatalf("list section updates: %v", err)
+	}
+	if len(updates) != 1 || updates[0].CommitHash != "c1" || updates[0].DocCommit != "d1" || updates[0].Section != "API" {
+		t.Fatalf("unexpected section updates: %+v", updates)
+	}
+	if updates, _ := store.ListSectionUpdates(after, after.Add(time.Hour)); len(updates) != 0 {
+		t.Fatalf("expected no updates after the window, got %+v", updates)
+	}
+
+	if err := store.RecordDigest(before, after, 1); err != nil {
+		t.Fatalf("record digest: %v", err)
+	}
+	last, ok, err := store.LastDigest()
+	if err != nil || !ok || !last.Equal(after.UTC().Truncate(time.Second)) {
+		t.Fatalf("expected last digest to end at %v, got %v ok=%v err=%v", after, last, ok, err)
+	}
+}
+
+func TestGetCachedResponseByPromptIgnoresCommit(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+	defer store.Close()
+
+	prompt := "Update docs for this commit.\nDiff:\n+gofmt"
+	if err := store.PutCachedLLMResponse(LLMCacheEntry{CommitHash: "a1", DocFile: "README.md", SectionID: "Usage", Provider: "openai", Model: "gpt-4o-mini", PromptHash: hashPrompt(prompt), Response: "- Formatted sources"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok, err := store.GetCachedResponseByPrompt("openai", "gpt-4o-mini", prompt, time.Now().Add(-time.Hour))
+	if err != nil || !ok || got != "- Formatted sources" {
+		t.Fatalf("expected content cache hit, got %q ok=%v err=%v", got, ok, err)
+	}
+	if _, ok, _ := store.GetCachedResponseByPrompt("openai", "gpt-4o", prompt, time.Now().Add(-time.Hour)); ok {
+		t.Fatalf("expected a different model to miss")
+	}
+	if _, ok, _ := store.GetCachedResponseByPrompt("openai", "gpt-4o-mini", prompt, time.Now().Add(time.Hour)); ok {
+		t.Fatalf("expected entries older than the TTL to miss")
+	}
+}
+
+func TestSharedCacheIsSharedAcrossHandles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	first, err := OpenSharedCache(dir)
+	if err != nil {
+		t.Fatalf("open shared cache: %v", err)
+	}
+	defer first.Close()
+	second, err := OpenSharedCache(dir)
+	if err != nil {
+		t.Fatalf("open shared cache again: %v", err)
+	}
+	defer second.Close()
+
+	prompt := "Update docs for this commit.\nDiff:\n+gofmt"
+	if err := first.Put("openai|gpt-4o-mini|", prompt, "- Formatted sources"); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := second.Get("openai|gpt-4o-mini|", prompt, time.Now().Add(-time.Hour))
+	if err != nil || !ok || got != "- Formatted sources" {
+		t.Fatalf("expected shared cache hit, got %q ok=%v err=%v", got, ok, err)
+	}
+	if _, ok, _ := second.Get("openai|gpt-4o|", prompt, time.Now().Add(-time.Hour)); ok {
+		t.Fatalf("expected a different namespace to miss")
+	}
+
+	if err := second.Put("openai|gpt-4o-mini|", prompt, "- Reformatted sources"); err != nil {
+		t.Fatalf("expected a repeated put to replace the entry: %v", err)
+	}
+	if got, _, _ := first.Get("openai|gpt-4o-mini|", prompt, time.Now().Add(-time.Hour)); got != "- Reformatted sources" {
+		t.Fatalf("expected the replaced response, got %q", got)
+	}
+}
+
+func TestLLMCacheStatsListAndClear(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+	defer store.Close()
+
+	for _, entry := range []LLMCacheEntry{
+		{CommitHash: "aaa111", DocFile: "README.md", SectionID: "Usage", Provider: "openai", Model: "gpt-4o-mini", PromptHash: "p1", Response: "- one"},
+		{CommitHash: "aaa111", DocFile: "README.md", SectionID: "Install", Provider: "openai", Model: "gpt-4o-mini", PromptHash: "p2", Response: "- two"},
+		{CommitHash: "bbb222", DocFile: "README.md", SectionID: "Usage", Provider: "ollama", Model: "llama3", PromptHash: "p3", Response: "- three"},
+	} {
+		if err := store.PutCachedLLMResponse(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := store.LLMCacheStats()
+	if err != nil || len(stats) != 2 || stats[0].Provider != "ollama" || stats[1].Entries != 2 || stats[1].Bytes != 10 || stats[1].Oldest.IsZero() {
+		t.Fatalf("unexpected stats %+v err=%v", stats, err)
+	}
+
+	entries, err := store.ListCachedResponses(CacheFilter{CommitHash: "aaa"})
+	if err != nil || len(entries) != 2 || entries[0].CommitHash != "aaa111" {
+		t.Fatalf("expected two entries for the commit prefix, got %+v err=%v", entries, err)
+	}
+
+	if removed, err := store.ClearLLMCache(CacheFilter{Provider: "openai", Model: "gpt-4o"}); err != nil || removed != 0 {
+		t.Fatalf("expected a different model to clear nothing, removed=%d err=%v", removed, err)
+	}
+	if removed, err := store.ClearLLMCache(CacheFilter{Before: time.Now().Add(-time.Hour)}); err != nil || removed != 0 {
+		t.Fatalf("expected recent entries to survive an age cutoff, removed=%d err=%v", removed, err)
+	}
+	if removed, err := store.ClearLLMCache(CacheFilter{Provider: "openai"}); err != nil || removed != 2 {
+		t.Fatalf("expected the provider's entries to be cleared, removed=%d err=%v", removed, err)
+	}
+	if entries, _ := store.ListCachedResponses(CacheFilter{}); len(entries) != 1 || entries[0].Provider != "ollama" {
+		t.Fatalf("expected only the other provider to remain, got %+v", entries)
+	}
+}
+
+func TestSharedCacheClearByNamespace(t *testing.T) {
+	shared, err := OpenSharedCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("open shared cache: %v", err)
+	}
+	defer shared.Close()
+
+	for _, namespace := range []string{"openai|gpt-4o-mini|", "openai|gpt-4o-mini|https://proxy.example.com", "ollama|llama3|"} {
+		if err := shared.Put(namespace, "prompt", "- response"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if removed, err := shared.Clear(CacheFilter{Model: "gpt-4o-mini"}); err != nil || removed != 2 {
+		t.Fatalf("expected both endpoints of the model to be cleared, removed=%d err=%v", removed, err)
+	}
+	stats, err := shared.Stats()
+	if err != nil || len(stats) != 1 || stats[0].Provider != "ollama" || stats[0].Model != "llama3" {
+		t.Fatalf("unexpected stats %+v err=%v", stats, err)
+	}
+	if removed, err := shared.ClearPrompts([]string{hashPrompt("prompt")}); err != nil || removed != 1 {
+		t.Fatalf("expected the prompt to be cleared, removed=%d err=%v", removed, err)
+	}
+}
+
+func TestPromptLogEncryptsAndPrunes(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+	defer store.Close()
+	if err := store.SetEncryptionKey("audit-key"); err != nil {
+		t.Fatal(err)
+	}
+
+	prompt := "Update docs for this commit.\nDiff:\n+secret()"
+	if err := store.LogPrompt(PromptLogEntry{RunID: "run-1", CommitHash: "abc", Purpose: "generate", DocFile: "README.md", SectionID: "Usage", Provider: "openai", Model: "gpt-4o-mini", Prompt: prompt, Status: "ok"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.LogPrompt(PromptLogEntry{RunID: "run-1", CommitHash: "abc", Purpose: "plan", Provider: "openai", Model: "gpt-4o-mini", PromptHash: "h", Status: "error", Error: "provider down"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var raw string
+	if err := store.db.QueryRow(`SELECT prompt_text FROM prompt_log WHERE purpose = 'generate'`).Scan(&raw); err != nil || strings.Contains(raw, "secret") {
+		t.Fatalf("expected the prompt to be stored encrypted, got %q err=%v", raw, err)
+	}
+
+	entries, err := store.ListPromptLog(time.Now().Add(-time.Hour))
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected two logged prompts, got %+v err=%v", entries, err)
+	}
+	if entries[0].Prompt != prompt || entries[0].PromptHash != hashPrompt(prompt) || entries[0].SectionID != "Usage" {
+		t.Fatalf("unexpected generate entry %+v", entries[0])
+	}
+	if entries[1].Prompt != "" || entries[1].PromptHash != "h" || entries[1].Error != "provider down" {
+		t.Fatalf("unexpected hash-only entry %+v", entries[1])
+	}
+
+	result, err := store.Prune(time.Now().Add(time.Hour))
+	if err != nil || result.PromptLog != 2 {
+		t.Fatalf("expected prune to remove logged prompts, got %+v err=%v", result, err)
+	}
+}
+
+func TestQueryCommitsFilters(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+
+	for _, hash := range []string{"c1", "c2", "c3", "c4"} {
+		if err := store.MarkCommitProcessed(hash, "success", "", "", nil); err != nil {
+			t.Fatalf("mark commit: %v", err)
+		}
+	}
+	if err := store.MarkCommitFailed("c2", "section not found: API_V2", "permanent"); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+	if err := store.MarkCommitFailed("c3", "rate limited (429)", "transient"); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+	if err := store.UpsertPlannedUpdate("c2", "docs/api.md", "Endpoints", "inferred", "failed", ""); err != nil {
+		t.Fatalf("upsert planned update: %v", err)
+	}
+	if err := store.StoreMapping("c4", "docs/api.md", "Endpoints"); err != nil {
+		t.Fatalf("store mapping: %v", err)
+	}
+
+	hashes := func(filter CommitFilter) []string {
+		t.Helper()
+		rows, err := store.QueryCommits(filter)
+		if err != nil {
+			t.Fatalf("query commits %+v: %v", filter, err)
+		}
+		count, err := store.CountCommits(filter)
+		if err != nil {
+			t.Fatalf("count commits %+v: %v", filter, err)
+		}
+		out := make([]string, 0, len(rows))
+		for _, row := range rows {
+			out = append(out, row.CommitHash)
+		}
+		sort.Strings(out)
+		if filter.Limit == 0 && count != len(out) {
+			t.Fatalf("count %d does not match %d rows for %+v", count, len(out), filter)
+		}
+		return out
+	}
+
+	tests := []struct {
+		filter CommitFilter
+		want   []string
+	}{
+		{CommitFilter{Status: "failed"}, []string{"c2", "c3"}},
+		{CommitFilter{DocFile: "docs/api.md"}, []string{"c2", "c4"}},
+		{CommitFilter{Status: "failed", DocFile: "docs/api.md"}, []string{"c2"}},
+		{CommitFilter{Grep: "api_v2"}, []string{"c2"}},
+		{CommitFilter{Grep: "_"}, []string{"c2"}},
+		{CommitFilter{Grep: "%"}, []string{}},
+		{CommitFilter{Since: time.Now().Add(-time.Hour)}, []string{"c1", "c2", "c3", "c4"}},
+		{CommitFilter{Since: time.Now().Add(time.Hour)}, []string{}},
+	}
+	for _, tt := range tests {
+		if got := hashes(tt.filter); !slices.Equal(got, tt.want) {
+			t.Errorf("QueryCommits(%+v) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+
+	var paged []string
+	for offset := 0; offset < 4; offset += 3 {
+		paged = append(paged, hashes(CommitFilter{Limit: 3, Offset: offset})...)
+	}
+	sort.Strings(paged)
+	if !slices.Equal(paged, []string{"c1", "c2", "c3", "c4"}) {
+		t.Fatalf("expected pages to cover every commit once, got %v", paged)
+	}
+}
+
+func TestListContributions(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("failed to create state store: %v", err)
+	}
+
+	for _, hash := range []string{"c1", "c2", "c3"} {
+		if err := store.MarkCommitProcessed(hash, "success", "", "doc-"+hash, nil); err != nil {
+			t.Fatalf("mark commit: %v", err)
+		}
+	}
+	steps := []error{
+		store.StoreMapping("c1", "docs/api.md", "Auth"),
+		store.StoreMapping("c2", "docs/api.md", "Auth"),
+		store.StoreMapping("c3", "docs/api.md", "Endpoints"),
+		store.LogRunEvent("run-1", "c1", "info", "orchestrator", "commit processed", nil),
+		store.LogRunEvent("run-2", "c2", "info", "orchestrator", "commit processed", nil),
+		store.LogRunEvent("run-2", "c3", "info", "orchestrator", "commit processed", nil),
+		store.LogPrompt(PromptLogEntry{RunID: "run-1", CommitHash: "c1", Purpose: "generate", DocFile: "docs/api.md", SectionID: "Auth", Provider: "openai", Model: "gpt-4o-mini", Prompt: "p1", Status: "ok"}),
+		store.LogPrompt(PromptLogEntry{RunID: "run-2", CommitHash: "c3", Purpose: "generate", DocFile: "docs/api.md", SectionID: "Auth", Provider: "anthropic", Model: "claude-haiku", Prompt: "p2", Status: "ok"}),
+		store.PutCachedLLMResponse(LLMCacheEntry{CommitHash: "c1", DocFile: "docs/api.md", SectionID: "Auth", Provider: "ollama", Model: "llama3", PromptHash: "h0", Response: "- cached"}),
+	}
+	for _, err := range steps {
+		if err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+
+	all, err := store.ListContributions("docs/api.md", "")
+	if err != nil || len(all) != 3 {
+		t.Fatalf("expected three contributions to docs/api.md, got %+v err=%v", all, err)
+	}
+
+	auth, err := store.ListContributions("docs/api.md", "auth")
+	if err != nil || len(auth) != 2 {
+		t.Fatalf("expected two contributions to the Auth section, got %+v err=%v", auth, err)
+	}
+	if auth[0].CommitHash != "c1" || auth[0].DocCommit != "doc-c1" || !slices.Equal(auth[0].Runs, []string{"run-1"}) {
+		t.Fatalf("unexpected first contribution: %+v", auth[0])
+	}
+	if !slices.Equal(auth[0].Models, []string{"ollama/llama3", "openai/gpt-4o-mini"}) {
+		t.Fatalf("expected the logged and cached models for c1, got %v", auth[0].Models)
+	}
+	if !slices.Equal(auth[1].Models, []string{"anthropic/claude-haiku"}) {
+		t.Fatalf("expected c2 to fall back to the model its run used for the section, got %v", auth[1].Models)
+	}
+}
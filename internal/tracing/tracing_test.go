@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kowshik24/git-doc/internal/config"
+)
+
+type exportRequest struct {
+	ResourceSpans []struct {
+		Resource struct {
+			Attributes []keyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans []struct {
+			Spans []otlpSpan `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"resourceSpans"`
+}
+
+func TestTracerExportsNestedSpans(t *testing.T) {
+	var got exportRequest
+	var path, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.Path, r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("decode export: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	tracer := New(config.TracingConfig{Enabled: true, Endpoint: server.URL + "/", Headers: map[string]string{"Authorization": "Bearer x"}})
+	ctx, run := tracer.Start(context.Background(), "update_run")
+	_, stage := tracer.Start(ctx, "llm.generate")
+	stage.SetAttr("llm.input_tokens", 42)
+	stage.SetAttr("git_doc.cache_hit", false)
+	stage.End(errors.New("rate limited"))
+	run.End(nil)
+
+	if err := tracer.Flush(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if path != "/v1/traces" || auth != "Bearer x" {
+		t.Fatalf("unexpected request path %q or auth header %q", path, auth)
+	}
+
+	spans := got.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	child, parent := spans[0], spans[1]
+	if child.TraceID != parent.TraceID || child.ParentSpanID != parent.SpanID || parent.ParentSpanID != "" {
+		t.Fatalf("expected llm.generate to be a child of update_run, got %+v and %+v", child, parent)
+	}
+	if child.Status.Code != 2 || child.Status.Message != "rate limited" || parent.Status.Code != 1 {
+		t.Fatalf("unexpected statuses %+v and %+v", child.Status, parent.Status)
+	}
+	if attr := child.Attributes[1]; attr.Key != "llm.input_tokens" || attr.Value.IntValue == nil || *attr.Value.IntValue != "42" {
+		t.Fatalf("expected an integer token attribute, got %+v", child.Attributes)
+	}
+	if service := got.ResourceSpans[0].Resource.Attributes[0]; *service.Value.StringValue != "git-doc" {
+		t.Fatalf("expected service.name git-doc, got %+v", service)
+	}
+
+	if err := tracer.Flush(context.Background()); err != nil {
+		t.Fatalf("second flush: %v", err)
+	}
+}
+
+func TestNilTracerIsNoop(t *testing.T) {
+	tracer := New(config.TracingConfig{Endpoint: "http://localhost:4318"})
+	if tracer != nil {
+		t.Fatalf("expected disabled tracing to return a nil tracer")
+	}
+	ctx, span := tracer.Start(context.Background(), "update_run")
+	span.SetAttr("key", "value")
+	span.End(nil)
+	if ctx == nil || tracer.Flush(ctx) != nil {
+		t.Fatalf("expected nil tracer to be a no-op")
+	}
+}
@@ -0,0 +1,285 @@
+// Package tui renders the live status dashboard shown by git-doc status
+// --watch.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+// Snapshot is what one refresh of the dashboard shows.
+type Snapshot struct {
+	Counts state.StatusCounts
+	// Run is the most recent run, nil when there has been none.
+	Run    *state.RunRecord
+	Recent []state.ProcessedCommitRow
+	Events []state.RunEvent
+	Queued bool
+}
+
+// Dashboard redraws a Snapshot every Interval and handles key presses:
+// j/k or the arrow keys select a commit, enter or i inspects it, r retries
+// it and q quits.
+type Dashboard struct {
+	Load     func() (Snapshot, error)
+	Retry    func(hash string) (string, error)
+	Inspect  func(hash string) ([]string, error)
+	Interval time.Duration
+
+	snapshot Snapshot
+	selected int
+	message  string
+	detail   []string
+	now      func() time.Time
+}
+
+type key int
+
+const (
+	keyNone key = iota
+	keyQuit
+	keyUp
+	keyDown
+	keyInspect
+	keyRetry
+	keyBack
+)
+
+const maxLineWidth = 120
+
+// keyPollInterval is how long readKeys waits for input before checking
+// whether the dashboard has exited.
+const keyPollInterval = 100 * time.Millisecond
+
+// Run draws the dashboard on out until ctx ends or q is pressed. When in is
+// a terminal it is switched to raw mode for single-key input.
+func (d *Dashboard) Run(ctx context.Context, in *os.File, out io.Writer) error {
+	if d.now == nil {
+		d.now = time.Now
+	}
+	if d.Interval <= 0 {
+		d.Interval = 2 * time.Second
+	}
+	if restore, err := makeRaw(in); err == nil {
+		defer restore()
+	}
+	fmt.Fprint(out, "\x1b[?25l")
+	defer fmt.Fprint(out, "\x1b[?25h\n")
+
+	done := make(chan struct{})
+	defer close(done)
+	keys := make(chan key)
+	go readKeys(in, keys, done)
+
+	d.refresh()
+	d.draw(out)
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			d.refresh()
+		case k, ok := <-keys:
+			if !ok || k == keyQuit {
+				return nil
+			}
+			d.handle(k)
+		}
+		d.draw(out)
+	}
+}
+
+func (d *Dashboard) refresh() {
+	snapshot, err := d.Load()
+	if err != nil {
+		d.message = "refresh failed: " + err.Error()
+		return
+	}
+	d.snapshot = snapshot
+	if d.selected >= len(snapshot.Recent) {
+		d.selected = max(len(snapshot.Recent)-1, 0)
+	}
+}
+
+func (d *Dashboard) handle(k key) {
+	switch k {
+	case keyUp:
+		if d.selected > 0 {
+			d.selected--
+		}
+	case keyDown:
+		if d.selected < len(d.snapshot.Recent)-1 {
+			d.selected++
+		}
+	case keyBack:
+		d.detail = nil
+		d.message = ""
+	case keyInspect, keyRetry:
+		if len(d.snapshot.Recent) == 0 {
+			return
+		}
+		hash := d.snapshot.Recent[d.selected].CommitHash
+		if k == keyInspect {
+			lines, err := d.Inspect(hash)
+			if err != nil {
+				d.message = "inspect failed: " + err.Error()
+				return
+			}
+			d.detail = lines
+			d.message = ""
+			return
+		}
+		message, err := d.Retry(hash)
+		if err != nil {
+			message = "retry failed: " + err.Error()
+		}
+		d.message = message
+		d.refresh()
+	}
+}
+
+func (d *Dashboard) draw(out io.Writer) {
+	fmt.Fprint(out, "\x1b[H\x1b[2J"+Render(d.snapshot, d.selected, d.message, d.detail, d.now()))
+}
+
+// Render lays out one frame of the dashboard.
+func Render(s Snapshot, selected int, message string, detail []string, now time.Time) string {
+	var b strings.Builder
+	line := func(format string, args ...any) {
+		text := fmt.Sprintf(format, args...)
+		if len(text) > maxLineWidth {
+			text = text[:maxLineWidth-3] + "..."
+		}
+		b.WriteString(text + "\n")
+	}
+
+	line("git-doc status  %s  (q quit, j/k select, enter inspect, r retry, esc back)", now.Format("15:04:05"))
+	c := s.Counts
+	line("pending=%d in_progress=%d success=%d failed=%d (permanent=%d) skipped=%d total=%d",
+		c.Pending, c.InProgress, c.Success, c.Failed, c.FailedPermanent, c.Skipped, c.Total)
+	if s.Run != nil {
+		run := s.Run
+		if run.Status == "running" {
+			line("run %s (%s) running for %s", run.ID, run.Trigger, now.Sub(run.StartedAt).Round(time.Second))
+		} else {
+			line("last run %s (%s) %s: processed=%d success=%d failed=%d skipped=%d",
+				run.ID, run.Trigger, run.Status, run.Processed, run.Success, run.Failed, run.Skipped)
+		}
+	}
+	for _, row := range s.Recent {
+		if row.Status == "in_progress" {
+			line("processing %s", shortHash(row.CommitHash))
+		}
+	}
+	if s.Queued {
+		line("queued: a hook fired during the run; its commits follow")
+	}
+
+	b.WriteString("\nrecent commits:\n")
+	if len(s.Recent) == 0 {
+		line("  (none)")
+	}
+	for i, row := range s.Recent {
+		cursor := " "
+		if i == selected {
+			cursor = ">"
+		}
+		text := fmt.Sprintf("%s %s %-11s %s", cursor, shortHash(row.CommitHash), row.Status, row.ProcessedAt.Local().Format("2006-01-02 15:04:05"))
+		if row.ErrorClass.Valid {
+			text += " class=" + row.ErrorClass.String
+		}
+		line("%s", text)
+	}
+
+	if len(detail) > 0 {
+		b.WriteString("\n")
+		for _, d := range detail {
+			line("  %s", d)
+		}
+	} else {
+		b.WriteString("\nrecent events:\n")
+		for _, event := range s.Events {
+			line("  %s %-5s %-12s %-7s %s", event.CreatedAt.Local().Format("15:04:05"), event.Level, event.Component, shortHash(event.CommitHash), event.Message)
+		}
+	}
+
+	if message != "" {
+		b.WriteString("\n")
+		line("%s", message)
+	}
+	return b.String()
+}
+
+// readKeys turns raw input into keys until the reader fails or done is
+// closed. It polls for input instead of blocking in Read, so it stops soon
+// after the dashboard exits and leaves later key presses to the shell.
+func readKeys(in *os.File, keys chan<- key, done <-chan struct{}) {
+	defer close(keys)
+	buf := make([]byte, 16)
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		ready, err := waitInput(in, keyPollInterval)
+		if err != nil {
+			return
+		}
+		if !ready {
+			continue
+		}
+		n, err := in.Read(buf)
+		if err != nil {
+			return
+		}
+		if k := parseKey(buf[:n]); k != keyNone {
+			select {
+			case keys <- k:
+			case <-done:
+				return
+			}
+		}
+	}
+}
+
+func parseKey(b []byte) key {
+	switch {
+	case len(b) == 0:
+		return keyNone
+	case string(b) == "\x1b[A" || string(b) == "\x1bOA":
+		return keyUp
+	case string(b) == "\x1b[B" || string(b) == "\x1bOB":
+		return keyDown
+	}
+	switch b[0] {
+	case 'q', 'Q', 3:
+		return keyQuit
+	case 'k':
+		return keyUp
+	case 'j':
+		return keyDown
+	case '\r', '\n', 'i':
+		return keyInspect
+	case 'r':
+		return keyRetry
+	case 0x1b, 'b':
+		return keyBack
+	}
+	return keyNone
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"database/sql"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kowshik24/git-doc/internal/state"
+)
+
+func TestRenderShowsRunProgressAndSelection(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 30, 0, time.UTC)
+	snapshot := Snapshot{
+		Counts: state.StatusCounts{InProgress: 1, Failed: 1, FailedPermanent: 1, Total: 2},
+		Run:    &state.RunRecord{ID: "run-1", Trigger: "backfill", Status: "running", StartedAt: now.Add(-30 * time.Second)},
+		Recent: []state.ProcessedCommitRow{
+			{CommitHash: "aaaaaaaaaa", Status: "in_progress", ProcessedAt: now},
+			{CommitHash: "bbbbbbbbbb", Status: "failed", ProcessedAt: now, ErrorClass: sql.NullString{String: "permanent", Valid: true}},
+		},
+		Events: []state.RunEvent{{CreatedAt: now, Level: "info", Component: "orchestrator", CommitHash: "aaaaaaaaaa", Message: "update loop started"}},
+		Queued: true,
+	}
+
+	out := Render(snapshot, 1, "retrying in the background", nil, now)
+	for _, want := range []string{
+		"failed=1 (permanent=1)",
+		"run run-1 (backfill) running for 30s",
+		"processing aaaaaaa",
+		"queued:",
+		"> bbbbbbb failed",
+		"class=permanent",
+		"update loop started",
+		"retrying in the background",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in dashboard:\n%s", want, out)
+		}
+	}
+
+	out = Render(snapshot, 0, "", []string{"error: target doc file not found"}, now)
+	if !strings.Contains(out, "error: target doc file not found") || strings.Contains(out, "recent events:") {
+		t.Fatalf("expected the detail view to replace events:\n%s", out)
+	}
+}
+
+func TestDashboardKeys(t *testing.T) {
+	var retried string
+	d := &Dashboard{
+		Load: func() (Snapshot, error) {
+			return Snapshot{Recent: []state.ProcessedCommitRow{{CommitHash: "one"}, {CommitHash: "two"}}}, nil
+		},
+		Retry: func(hash string) (string, error) {
+			retried = hash
+			return "queued " + hash, nil
+		},
+		Inspect: func(hash string) ([]string, error) {
+			return []string{"commit " + hash}, nil
+		},
+	}
+	d.refresh()
+
+	for _, input := range []string{"j", "\x1b[B", "r"} {
+		d.handle(parseKey([]byte(input)))
+	}
+	if retried != "two" || d.message != "queued two" {
+		t.Fatalf("expected the second commit to be retried, got %q (%q)", retried, d.message)
+	}
+
+	d.handle(parseKey([]byte("\x1b[A")))
+	d.handle(parseKey([]byte("\r")))
+	if len(d.detail) != 1 || d.detail[0] != "commit one" {
+		t.Fatalf("expected the first commit to be inspected, got %v", d.detail)
+	}
+	d.handle(parseKey([]byte("\x1b")))
+	if d.detail != nil {
+		t.Fatalf("expected esc to close the detail view")
+	}
+	if parseKey([]byte("q")) != keyQuit || parseKey([]byte{3}) != keyQuit {
+		t.Fatalf("expected q and ctrl-c to quit")
+	}
+}
+
+func TestReadKeysStopsWhenDone(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("pipes cannot be polled on windows")
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	keys := make(chan key)
+	done := make(chan struct{})
+	go readKeys(r, keys, done)
+	if _, err := w.Write([]byte("j")); err != nil {
+		t.Fatal(err)
+	}
+	if k := <-keys; k != keyDown {
+		t.Fatalf("expected j to read as down, got %v", k)
+	}
+
+	close(done)
+	select {
+	case _, ok := <-keys:
+		if ok {
+			t.Fatalf("expected no key after done")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected readKeys to stop once done is closed")
+	}
+
+	if _, err := w.Write([]byte("q")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1)
+	if _, err := r.Read(buf); err != nil || buf[0] != 'q' {
+		t.Fatalf("expected the next key press to be left unread, got %q, %v", buf, err)
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && !windows
+
+package tui
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+func makeRaw(f *os.File) (func(), error) {
+	return nil, errors.New("raw terminal input is not supported on this platform")
+}
+
+// waitInput cannot poll here, so readKeys blocks in Read and stops at the
+// next key press after the dashboard exits.
+func waitInput(f *os.File, timeout time.Duration) (bool, error) {
+	return true, nil
+}